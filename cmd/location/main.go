@@ -19,6 +19,8 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/nats"
 	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/observability"
+	"github.com/piresc/nebengjek/internal/pkg/server"
+	"github.com/piresc/nebengjek/internal/utils"
 	"github.com/piresc/nebengjek/services/location/gateway"
 	"github.com/piresc/nebengjek/services/location/handler"
 	"github.com/piresc/nebengjek/services/location/repository"
@@ -39,7 +41,9 @@ func main() {
 		ServiceName: appName,
 		NewRelic:    nrApp,
 		Format:      "json",
+		RedactPII:   configs.Logger.RedactPII,
 	})
+	slogpkg.SetGlobalLogger(slogLogger)
 
 	// Initialize observability tracer
 	tracerFactory := observability.NewTracerFactory()
@@ -61,7 +65,7 @@ func main() {
 	defer redisClient.Close()
 
 	// Initialize JetStream-enabled NATS client
-	natsClient, err := nats.NewClient(configs.NATS.URL)
+	natsClient, err := nats.NewClient(configs.NATS)
 	if err != nil {
 		slogLogger.Error("Failed to connect to NATS with JetStream", slog.Any("error", err))
 		os.Exit(1)
@@ -85,7 +89,23 @@ func main() {
 	locationGW := gateway.NewLocationGW(natsClient)
 
 	// Initialize usecase
-	locationUC := usecase.NewLocationUC(locationRepo, locationGW)
+	locationUC := usecase.NewLocationUC(configs, locationRepo, locationGW)
+
+	// Evict drivers whose beacons stopped from the matching pool
+	sweeperCtx, stopSweeper := context.WithCancel(context.Background())
+	defer stopSweeper()
+	go locationUC.RunPoolSweeper(sweeperCtx,
+		time.Duration(configs.Location.PoolSweepIntervalSecs)*time.Second)
+
+	// Coalesce rapid location updates into one aggregate publish per ride and interval
+	publisherCtx, stopPublisher := context.WithCancel(context.Background())
+	defer stopPublisher()
+	publisherDone := make(chan struct{})
+	go func() {
+		defer close(publisherDone)
+		locationUC.RunLocationPublisher(publisherCtx,
+			time.Duration(configs.Location.PublishIntervalMs)*time.Millisecond)
+	}()
 
 	// Initialize handlers
 	locationHandler := handler.NewHTTPHandler(locationUC, natsClient, configs, nrApp)
@@ -98,6 +118,8 @@ func main() {
 
 	// Initialize Echo server
 	e := echo.New()
+	server.ConfigureTimeouts(e, configs.Server)
+	e.HTTPErrorHandler = utils.HTTPErrorHandler
 
 	// Initialize enhanced health service
 	healthService := health.NewHealthService(slogLogger)
@@ -113,6 +135,7 @@ func main() {
 			"match-service":    configs.APIKey.MatchService,
 			"rides-service":    configs.APIKey.RidesService,
 			"location-service": configs.APIKey.LocationService,
+			"admin":            configs.APIKey.Admin,
 		},
 		ServiceName: appName,
 	})
@@ -131,6 +154,9 @@ func main() {
 	// Register service routes
 	locationHandler.RegisterRoutes(e, MW)
 
+	// Consumers are subscribed and routes registered; /ready now follows the dependency checks
+	healthService.MarkReady()
+
 	// Start server in goroutine
 	go func() {
 		addr := fmt.Sprintf(":%d", configs.Server.Port)
@@ -162,6 +188,11 @@ func main() {
 		slogLogger.Error("Server forced to shutdown", slog.Any("error", err))
 	}
 
+	// Publish coalesced location aggregates while NATS is still connected
+	slogLogger.Info("Flushing pending location aggregates...")
+	stopPublisher()
+	<-publisherDone
+
 	// Close Redis connection
 	slogLogger.Info("Closing Redis connection...")
 	if err := redisClient.Close(); err != nil {
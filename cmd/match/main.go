@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/db"
 	"github.com/piresc/nebengjek/internal/pkg/config"
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/health"
@@ -19,6 +20,8 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/nats"
 	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/observability"
+	"github.com/piresc/nebengjek/internal/pkg/server"
+	"github.com/piresc/nebengjek/internal/utils"
 	"github.com/piresc/nebengjek/services/match/gateway"
 	"github.com/piresc/nebengjek/services/match/handler"
 	"github.com/piresc/nebengjek/services/match/repository"
@@ -39,7 +42,9 @@ func main() {
 		ServiceName: appName,
 		NewRelic:    nrApp,
 		Format:      "json",
+		RedactPII:   configs.Logger.RedactPII,
 	})
+	slogpkg.SetGlobalLogger(slogLogger)
 
 	// Initialize observability tracer
 	tracerFactory := observability.NewTracerFactory()
@@ -60,6 +65,16 @@ func main() {
 	}
 	defer postgresClient.Close()
 
+	// Apply pending schema migrations when enabled
+	if configs.Database.MigrateOnStartup {
+		applied, err := postgresClient.RunMigrations(context.Background(), db.Migrations())
+		if err != nil {
+			slogLogger.Error("Failed to run database migrations", slog.Any("error", err))
+			os.Exit(1)
+		}
+		slogLogger.Info("Database migrations applied", slog.Int("count", len(applied)))
+	}
+
 	// Initialize Redis client
 	redisClient, err := database.NewRedisClient(configs.Redis)
 	if err != nil {
@@ -69,7 +84,7 @@ func main() {
 	defer redisClient.Close()
 
 	// Initialize JetStream-enabled NATS client
-	natsClient, err := nats.NewClient(configs.NATS.URL)
+	natsClient, err := nats.NewClient(configs.NATS)
 	if err != nil {
 		slogLogger.Error("Failed to connect to NATS with JetStream", slog.Any("error", err))
 		os.Exit(1)
@@ -104,8 +119,40 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Start scheduler that releases pre-booked rides into matching when they are due
+	schedulerCtx, stopScheduler := context.WithCancel(context.Background())
+	defer stopScheduler()
+	go matchUC.RunScheduler(schedulerCtx,
+		time.Duration(configs.Match.SchedulerPollSecs)*time.Second,
+		configs.Match.SchedulerBatchSize)
+
+	// Retry pool removals that failed when matches were accepted
+	go matchUC.RunPoolRemovalRetry(schedulerCtx,
+		time.Duration(configs.Match.PoolRemovalRetrySecs)*time.Second)
+
+	// Retry rejecting passengers' other open matches where it kept failing on acceptance
+	go matchUC.RunAutoRejectionRetry(schedulerCtx,
+		time.Duration(configs.Match.AutoRejectionRetrySecs)*time.Second)
+
+	// Present gathered driver acceptances to passengers once their acceptance window closes
+	go matchUC.RunAcceptanceWindows(schedulerCtx, 0)
+
+	// Send proposal refreshes that were throttled once their interval ends
+	go matchUC.RunProposalRefreshes(schedulerCtx, 0)
+
+	// Reject proposals drivers leave unanswered past their expiry
+	go matchUC.RunProposalExpiry(schedulerCtx, 0)
+
+	// Keep the runtime maintenance flag shared by all match instances in sync
+	go matchUC.WatchMaintenanceMode(schedulerCtx, 0)
+
+	// Apply the search radius and region settings from the config file on SIGHUP without a restart
+	go config.WatchReload(schedulerCtx, configPath, matchUC.ReloadConfig)
+
 	// Initialize Echo server
 	e := echo.New()
+	server.ConfigureTimeouts(e, configs.Server)
+	e.HTTPErrorHandler = utils.HTTPErrorHandler
 
 	// Initialize enhanced health service
 	healthService := health.NewHealthService(slogLogger)
@@ -122,6 +169,7 @@ func main() {
 			"match-service":    configs.APIKey.MatchService,
 			"rides-service":    configs.APIKey.RidesService,
 			"location-service": configs.APIKey.LocationService,
+			"admin":            configs.APIKey.Admin,
 		},
 		ServiceName: appName,
 	})
@@ -140,6 +188,9 @@ func main() {
 	// Register service routes
 	handler.RegisterRoutes(e, MW)
 
+	// Consumers are subscribed and routes registered; /ready now follows the dependency checks
+	healthService.MarkReady()
+
 	// Start server in goroutine
 	go func() {
 		addr := fmt.Sprintf(":%d", configs.Server.Port)
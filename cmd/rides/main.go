@@ -11,14 +11,18 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/db"
 	"github.com/piresc/nebengjek/internal/pkg/config"
 	"github.com/piresc/nebengjek/internal/pkg/database"
+	"github.com/piresc/nebengjek/internal/pkg/featureflag"
 	"github.com/piresc/nebengjek/internal/pkg/health"
 	slogpkg "github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/middleware"
 	"github.com/piresc/nebengjek/internal/pkg/nats"
 	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/observability"
+	"github.com/piresc/nebengjek/internal/pkg/server"
+	"github.com/piresc/nebengjek/internal/utils"
 	"github.com/piresc/nebengjek/services/rides/gateway"
 	"github.com/piresc/nebengjek/services/rides/handler"
 	"github.com/piresc/nebengjek/services/rides/repository"
@@ -39,7 +43,9 @@ func main() {
 		ServiceName: appName,
 		NewRelic:    nrApp,
 		Format:      "json",
+		RedactPII:   configs.Logger.RedactPII,
 	})
+	slogpkg.SetGlobalLogger(slogLogger)
 
 	// Initialize observability tracer
 	tracerFactory := observability.NewTracerFactory()
@@ -60,6 +66,16 @@ func main() {
 	}
 	defer postgresClient.Close()
 
+	// Apply pending schema migrations when enabled
+	if configs.Database.MigrateOnStartup {
+		applied, err := postgresClient.RunMigrations(context.Background(), db.Migrations())
+		if err != nil {
+			slogLogger.Error("Failed to run database migrations", slog.Any("error", err))
+			os.Exit(1)
+		}
+		slogLogger.Info("Database migrations applied", slog.Int("count", len(applied)))
+	}
+
 	// Initialize Redis client
 	redisClient, err := database.NewRedisClient(configs.Redis)
 	if err != nil {
@@ -69,7 +85,7 @@ func main() {
 	defer redisClient.Close()
 
 	// Initialize JetStream-enabled NATS client
-	natsClient, err := nats.NewClient(configs.NATS.URL)
+	natsClient, err := nats.NewClient(configs.NATS)
 	if err != nil {
 		slogLogger.Error("Failed to connect to NATS with JetStream", slog.Any("error", err))
 		os.Exit(1)
@@ -87,20 +103,24 @@ func main() {
 		slog.Bool("connected", natsClient.IsConnected()))
 
 	// Initialize repository
-	rideRepo := repository.NewRideRepository(configs, postgresClient.GetDB())
+	rideRepo := repository.NewRideRepository(configs, postgresClient.GetDB(), redisClient)
 
 	// Initialize gateway
-	ridesGW := gateway.NewRideGW(natsClient)
+	locationClient := gateway.NewLocationClient(configs.Services.LocationServiceURL, configs.APIKey.RidesService)
+	ridesGW := gateway.NewRideGW(natsClient, locationClient)
+
+	// Initialize feature flags for behaviour rolled out gradually
+	flags := featureflag.New(redisClient, "rides", time.Duration(configs.Features.CacheTTLSecs)*time.Second)
 
 	// Initialize usecase
-	rideUC, err := usecase.NewRideUC(configs, rideRepo, ridesGW)
+	rideUC, err := usecase.NewRideUC(configs, rideRepo, ridesGW, flags)
 	if err != nil {
 		slogLogger.Error("Failed to initialize ride use case", slog.Any("error", err))
 		os.Exit(1)
 	}
 
 	// Initialize handlers
-	rideHandler := handler.NewHandler(rideUC, natsClient, configs, nrApp)
+	rideHandler := handler.NewHandler(rideUC, natsClient, configs, nrApp, flags)
 
 	// Initialize NATS consumers
 	if err := rideHandler.InitNATSConsumers(); err != nil {
@@ -108,8 +128,23 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Start outbox relay for events that could not be published when they were stored
+	relayCtx, stopRelay := context.WithCancel(context.Background())
+	defer stopRelay()
+	go rideUC.RunOutboxRelay(relayCtx,
+		time.Duration(configs.Rides.OutboxRelayIntervalSecs)*time.Second,
+		configs.Rides.OutboxRelayBatchSize)
+
+	// Complete rides that run past the maximum ride duration so none bills indefinitely
+	go rideUC.RunMaxDurationSweep(relayCtx, 0)
+
+	// Apply pricing and surcharge settings from the config file on SIGHUP without a restart
+	go config.WatchReload(relayCtx, configPath, rideUC.ReloadConfig)
+
 	// Initialize Echo server
 	e := echo.New()
+	server.ConfigureTimeouts(e, configs.Server)
+	e.HTTPErrorHandler = utils.HTTPErrorHandler
 
 	// Initialize enhanced health service
 	healthService := health.NewHealthService(slogLogger)
@@ -144,6 +179,9 @@ func main() {
 	// Register service routes
 	rideHandler.RegisterRoutes(e, MW)
 
+	// Consumers are subscribed and routes registered; /ready now follows the dependency checks
+	healthService.MarkReady()
+
 	// Start server in goroutine
 	go func() {
 		addr := fmt.Sprintf(":%d", configs.Server.Port)
@@ -169,6 +207,9 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Stop outbox relay before closing its dependencies
+	stopRelay()
+
 	// Shutdown HTTP server
 	slogLogger.Info("Shutting down HTTP server...")
 	if err := e.Shutdown(ctx); err != nil {
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/db"
 	"github.com/piresc/nebengjek/internal/pkg/config"
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/health"
@@ -19,10 +20,13 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/nats"
 	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/observability"
+	"github.com/piresc/nebengjek/internal/pkg/server"
+	"github.com/piresc/nebengjek/internal/utils"
 	"github.com/piresc/nebengjek/services/users/gateway"
 	"github.com/piresc/nebengjek/services/users/handler"
 	httpHandler "github.com/piresc/nebengjek/services/users/handler/http"
 	natsHandler "github.com/piresc/nebengjek/services/users/handler/nats"
+	sseHandler "github.com/piresc/nebengjek/services/users/handler/sse"
 	wsHandler "github.com/piresc/nebengjek/services/users/handler/websocket"
 	"github.com/piresc/nebengjek/services/users/repository"
 	"github.com/piresc/nebengjek/services/users/usecase"
@@ -42,7 +46,9 @@ func main() {
 		ServiceName: appName,
 		NewRelic:    nrApp,
 		Format:      "json",
+		RedactPII:   configs.Logger.RedactPII,
 	})
+	slogpkg.SetGlobalLogger(slogLogger)
 
 	// Initialize observability tracer
 	tracerFactory := observability.NewTracerFactory()
@@ -63,6 +69,16 @@ func main() {
 	}
 	defer postgresClient.Close()
 
+	// Apply pending schema migrations when enabled
+	if configs.Database.MigrateOnStartup {
+		applied, err := postgresClient.RunMigrations(context.Background(), db.Migrations())
+		if err != nil {
+			slogLogger.Error("Failed to run database migrations", slog.Any("error", err))
+			os.Exit(1)
+		}
+		slogLogger.Info("Database migrations applied", slog.Int("count", len(applied)))
+	}
+
 	// Initialize Redis client
 	redisClient, err := database.NewRedisClient(configs.Redis)
 	if err != nil {
@@ -72,7 +88,7 @@ func main() {
 	defer redisClient.Close()
 
 	// Initialize JetStream-enabled NATS client
-	natsClient, err := nats.NewClient(configs.NATS.URL)
+	natsClient, err := nats.NewClient(configs.NATS)
 	if err != nil {
 		slogLogger.Error("Failed to connect to NATS with JetStream", slog.Any("error", err))
 		os.Exit(1)
@@ -92,8 +108,15 @@ func main() {
 	// Initialize repository
 	userRepo := repository.NewUserRepo(configs, postgresClient.GetDB(), redisClient)
 
+	// No SMS provider is wired in yet, so login codes can only be issued with the development log sender
+	if !configs.Users.OTPLogSender {
+		slogLogger.Error("No OTP sender configured; set USERS_OTP_LOG_SENDER=true for local development")
+		os.Exit(1)
+	}
+	otpSender := gateway.NewLogOTPSender()
+
 	// Initialize gateway with API key support and tracer
-	userGW := gateway.NewUserGW(natsClient, configs.Services.MatchServiceURL, configs.Services.RidesServiceURL, &configs.APIKey, tracer)
+	userGW := gateway.NewUserGW(natsClient, configs.Services.MatchServiceURL, configs.Services.RidesServiceURL, &configs.APIKey, tracer, otpSender)
 
 	// Initialize usecase
 	userUC := usecase.NewUserUC(userRepo, userGW, configs)
@@ -105,8 +128,12 @@ func main() {
 	// Initialize Echo WebSocket handler (migrated from manual implementation)
 	echoWSHandler := wsHandler.NewEchoWebSocketHandler(userUC)
 
+	// Ride events are also streamed over SSE to clients that do not use WebSockets
+	rideEventHandler := sseHandler.NewRideEventHandler(userUC)
+	echoWSHandler.SetRideEventPublisher(rideEventHandler)
+
 	// Initialize NATS handler with Echo WebSocket handler
-	natsHandler := natsHandler.NewNatsHandler(echoWSHandler, natsClient)
+	natsHandler := natsHandler.NewNatsHandler(echoWSHandler, rideEventHandler, userUC, natsClient)
 
 	// Initialize NATS consumers
 	if err := natsHandler.InitConsumers(); err != nil {
@@ -115,10 +142,12 @@ func main() {
 	}
 
 	// Initialize handlers
-	Handler := handler.NewHandler(userHandler, authHandler, echoWSHandler, natsHandler, configs)
+	Handler := handler.NewHandler(userHandler, authHandler, echoWSHandler, rideEventHandler, natsHandler, configs)
 
 	// Initialize Echo server
 	e := echo.New()
+	server.ConfigureTimeouts(e, configs.Server)
+	e.HTTPErrorHandler = utils.HTTPErrorHandler
 
 	// Initialize enhanced health service
 	healthService := health.NewHealthService(slogLogger)
@@ -135,6 +164,7 @@ func main() {
 			"match-service":    configs.APIKey.MatchService,
 			"rides-service":    configs.APIKey.RidesService,
 			"location-service": configs.APIKey.LocationService,
+			"admin":            configs.APIKey.Admin,
 		},
 		ServiceName: appName,
 	})
@@ -153,6 +183,9 @@ func main() {
 	// Register service routes
 	Handler.RegisterRoutes(e, MW)
 
+	// Consumers are subscribed and routes registered; /ready now follows the dependency checks
+	healthService.MarkReady()
+
 	// Start server in goroutine
 	go func() {
 		addr := fmt.Sprintf(":%d", configs.Server.Port)
@@ -0,0 +1,20 @@
+// Package db holds the SQL migrations that build the shared Postgres schema
+package db
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Migrations returns the migration scripts, applied in file name order
+func Migrations() fs.FS {
+	scripts, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		// The directory is embedded at build time, so this can't fail
+		panic(err)
+	}
+	return scripts
+}
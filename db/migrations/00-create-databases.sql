@@ -1,6 +1,12 @@
 -- Create enum types
-CREATE TYPE match_status AS ENUM ('PENDING', 'ACCEPTED', 'REJECTED', 'DRIVER_CONFIRMED', 'PASSENGER_CONFIRMED');
-CREATE TYPE ride_status AS ENUM ('PENDING', 'PICKUP', 'ONGOING', 'COMPLETED');
+DO $$ BEGIN
+    CREATE TYPE match_status AS ENUM ('PENDING', 'ACCEPTED', 'REJECTED', 'DRIVER_CONFIRMED', 'PASSENGER_CONFIRMED');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
+DO $$ BEGIN
+    CREATE TYPE ride_status AS ENUM ('PENDING', 'PICKUP', 'ONGOING', 'COMPLETED');
+EXCEPTION WHEN duplicate_object THEN NULL;
+END $$;
 
 -- Users table
 CREATE TABLE IF NOT EXISTS users (
@@ -0,0 +1,6 @@
+-- Driver document verification
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS documents text[] NOT NULL DEFAULT '{}';
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS verified boolean NOT NULL DEFAULT false;
+ALTER TABLE drivers ADD COLUMN IF NOT EXISTS verified_at timestamp with time zone NULL;
+
+CREATE INDEX IF NOT EXISTS idx_drivers_verified ON drivers(verified);
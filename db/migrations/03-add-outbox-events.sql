@@ -0,0 +1,16 @@
+-- Transactional outbox for events published by the rides service
+CREATE TABLE IF NOT EXISTS outbox_events (
+    event_id uuid NOT NULL DEFAULT gen_random_uuid(),
+    aggregate_id uuid NOT NULL,
+    subject character varying(100) NOT NULL,
+    payload jsonb NOT NULL,
+    status character varying(20) NOT NULL DEFAULT 'PENDING'::character varying,
+    attempts integer NOT NULL DEFAULT 0,
+    last_error text NULL,
+    created_at timestamp with time zone NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at timestamp with time zone NULL,
+    CONSTRAINT outbox_events_pkey PRIMARY KEY (event_id),
+    CONSTRAINT check_outbox_status CHECK (status IN ('PENDING', 'SENT'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_pending ON outbox_events(created_at) WHERE status = 'PENDING';
@@ -0,0 +1,20 @@
+-- Append-only audit log of payment status transitions
+CREATE TABLE IF NOT EXISTS payment_audit (
+    audit_id uuid NOT NULL DEFAULT gen_random_uuid(),
+    payment_id uuid NOT NULL,
+    ride_id uuid NOT NULL,
+    from_status character varying(20) NULL, -- NULL for the row written when the payment is created
+    to_status character varying(20) NOT NULL,
+    actor character varying(100) NOT NULL,
+    amount integer NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT payment_audit_pkey PRIMARY KEY (audit_id),
+    CONSTRAINT payment_audit_payment_id_fkey FOREIGN KEY (payment_id) REFERENCES payments(payment_id),
+    CONSTRAINT payment_audit_ride_id_fkey FOREIGN KEY (ride_id) REFERENCES rides(ride_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_payment_audit_ride_id ON payment_audit(ride_id, created_at);
+
+-- Audit rows are never changed once written
+CREATE OR REPLACE RULE payment_audit_no_update AS ON UPDATE TO payment_audit DO INSTEAD NOTHING;
+CREATE OR REPLACE RULE payment_audit_no_delete AS ON DELETE TO payment_audit DO INSTEAD NOTHING;
@@ -0,0 +1,4 @@
+-- Pickup point and driver ETA, recomputed as the driver moves towards the passenger
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS pickup_latitude double precision NOT NULL DEFAULT 0;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS pickup_longitude double precision NOT NULL DEFAULT 0;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS pickup_eta_seconds integer NOT NULL DEFAULT 0;
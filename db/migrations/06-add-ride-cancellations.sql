@@ -0,0 +1,20 @@
+-- Rides can be cancelled by either party before the trip starts
+ALTER TYPE ride_status ADD VALUE IF NOT EXISTS 'CANCELLED';
+
+-- Cancellation fees and penalties, one row per cancelled ride
+CREATE TABLE IF NOT EXISTS ride_cancellations (
+    cancellation_id uuid NOT NULL DEFAULT gen_random_uuid(),
+    ride_id uuid NOT NULL,
+    cancelled_by uuid NOT NULL,
+    role character varying(20) NOT NULL,
+    fee integer NOT NULL DEFAULT 0,
+    reason text NOT NULL DEFAULT '',
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT ride_cancellations_pkey PRIMARY KEY (cancellation_id),
+    CONSTRAINT ride_cancellations_ride_id_fkey FOREIGN KEY (ride_id) REFERENCES rides(ride_id),
+    CONSTRAINT ride_cancellations_cancelled_by_fkey FOREIGN KEY (cancelled_by) REFERENCES users(id),
+    CONSTRAINT ride_cancellations_ride_id_unique UNIQUE (ride_id),
+    CONSTRAINT check_ride_cancellation_role CHECK (role IN ('driver', 'passenger'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_ride_cancellations_cancelled_by ON ride_cancellations(cancelled_by, created_at);
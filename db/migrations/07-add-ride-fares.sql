@@ -0,0 +1,10 @@
+-- Fare terms a ride was accepted under, created in the same transaction as the ride
+CREATE TABLE IF NOT EXISTS ride_fares (
+    ride_id uuid NOT NULL,
+    region character varying(64) NOT NULL,
+    rate_per_km double precision NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT ride_fares_pkey PRIMARY KEY (ride_id),
+    CONSTRAINT ride_fares_ride_id_fkey FOREIGN KEY (ride_id) REFERENCES rides(ride_id),
+    CONSTRAINT non_negative_rate_per_km CHECK (rate_per_km >= 0)
+);
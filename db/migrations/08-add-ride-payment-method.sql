@@ -0,0 +1,2 @@
+-- How the passenger pays; cash rides settle at arrival without a QR payment step
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS payment_method character varying(10) NOT NULL DEFAULT 'QRIS';
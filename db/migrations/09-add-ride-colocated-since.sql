@@ -0,0 +1,2 @@
+-- When the driver was first seen at the pickup point, used to start rides automatically
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS colocated_since timestamp with time zone NULL;
@@ -0,0 +1,13 @@
+-- Periods during which a driver's beacon was active, for shift reporting
+CREATE TABLE IF NOT EXISTS driver_online_sessions (
+    session_id uuid NOT NULL DEFAULT gen_random_uuid(),
+    driver_id uuid NOT NULL,
+    started_at timestamp with time zone NOT NULL,
+    ended_at timestamp with time zone NULL,
+    CONSTRAINT driver_online_sessions_pkey PRIMARY KEY (session_id),
+    CONSTRAINT driver_online_sessions_driver_id_fkey FOREIGN KEY (driver_id) REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_driver_online_sessions_driver_started ON driver_online_sessions(driver_id, started_at);
+-- A driver has at most one open session, so repeated online beacons do not stack
+CREATE UNIQUE INDEX IF NOT EXISTS idx_driver_online_sessions_open ON driver_online_sessions(driver_id) WHERE ended_at IS NULL;
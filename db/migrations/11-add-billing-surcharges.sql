@@ -0,0 +1,10 @@
+-- Flat surcharges such as tolls are recorded in the billing ledger alongside distance fares
+ALTER TABLE billing_ledger ADD COLUMN IF NOT EXISTS category character varying(16) NOT NULL DEFAULT 'DISTANCE';
+ALTER TABLE billing_ledger ADD COLUMN IF NOT EXISTS description character varying(64) NOT NULL DEFAULT '';
+
+-- Surcharges carry no distance, so only distance entries must cover one
+ALTER TABLE billing_ledger DROP CONSTRAINT IF EXISTS positive_distance;
+ALTER TABLE billing_ledger DROP CONSTRAINT IF EXISTS distance_entry_positive_distance;
+ALTER TABLE billing_ledger ADD CONSTRAINT distance_entry_positive_distance CHECK (category <> 'DISTANCE' OR distance > 0);
+
+CREATE INDEX IF NOT EXISTS idx_billing_ledger_ride_category ON billing_ledger(ride_id, category);
@@ -0,0 +1,4 @@
+-- A user can never be matched with themselves. The constraint is added NOT VALID so deploying it
+-- doesn't scan matches under an exclusive lock; existing rows are validated by the next migration.
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS matches_distinct_participants;
+ALTER TABLE matches ADD CONSTRAINT matches_distinct_participants CHECK (driver_id <> passenger_id) NOT VALID;
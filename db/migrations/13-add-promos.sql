@@ -0,0 +1,22 @@
+-- Promo codes discount a ride's fare at arrival, by a percentage or a flat amount
+CREATE TABLE IF NOT EXISTS promos (
+    code character varying(32) NOT NULL,
+    discount_type character varying(16) NOT NULL,
+    discount_value integer NOT NULL,
+    max_uses integer NOT NULL DEFAULT 0,
+    used_count integer NOT NULL DEFAULT 0,
+    expires_at timestamp with time zone NULL,
+    created_at timestamp with time zone NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT promos_pkey PRIMARY KEY (code),
+    CONSTRAINT promo_discount_type CHECK (discount_type IN ('PERCENTAGE', 'FLAT')),
+    CONSTRAINT promo_discount_value CHECK (discount_value > 0 AND (discount_type <> 'PERCENTAGE' OR discount_value <= 100)),
+    CONSTRAINT promo_usage_within_limit CHECK (max_uses >= 0 AND (max_uses = 0 OR used_count <= max_uses))
+);
+
+-- Discounts are recorded as negative ledger lines, so only they may carry a negative cost
+ALTER TABLE billing_ledger DROP CONSTRAINT IF EXISTS positive_cost;
+ALTER TABLE billing_ledger DROP CONSTRAINT IF EXISTS cost_sign_by_category;
+ALTER TABLE billing_ledger ADD CONSTRAINT cost_sign_by_category CHECK ((category = 'DISCOUNT' AND cost < 0) OR (category <> 'DISCOUNT' AND cost > 0));
+
+-- A ride takes at most one discount, so a retried arrival cannot redeem a promo twice
+CREATE UNIQUE INDEX IF NOT EXISTS idx_billing_ledger_ride_discount ON billing_ledger(ride_id) WHERE category = 'DISCOUNT';
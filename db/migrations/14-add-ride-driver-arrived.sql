@@ -0,0 +1,3 @@
+-- Drivers report arriving at the pickup point, after which waiting time can be charged
+ALTER TYPE ride_status ADD VALUE IF NOT EXISTS 'DRIVER_ARRIVED';
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS driver_arrived_at timestamp with time zone NULL;
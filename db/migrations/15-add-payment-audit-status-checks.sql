@@ -0,0 +1,5 @@
+-- Audit rows only record payment statuses, the same set allowed by check_payment_status
+ALTER TABLE payment_audit DROP CONSTRAINT IF EXISTS check_payment_audit_from_status;
+ALTER TABLE payment_audit DROP CONSTRAINT IF EXISTS check_payment_audit_to_status;
+ALTER TABLE payment_audit ADD CONSTRAINT check_payment_audit_from_status CHECK (from_status IS NULL OR from_status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'PROCESSED'));
+ALTER TABLE payment_audit ADD CONSTRAINT check_payment_audit_to_status CHECK (to_status IN ('PENDING', 'ACCEPTED', 'REJECTED', 'PROCESSED'));
@@ -0,0 +1,3 @@
+-- When the ride started, used to auto-complete rides that run past the maximum ride duration
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS started_at timestamp with time zone NULL;
+CREATE INDEX IF NOT EXISTS idx_rides_status_started_at ON rides(status, started_at);
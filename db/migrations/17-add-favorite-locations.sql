@@ -0,0 +1,14 @@
+-- Places a passenger saves, such as home or work, to fill in the finder's target location
+CREATE TABLE IF NOT EXISTS favorite_locations (
+    favorite_id uuid NOT NULL DEFAULT gen_random_uuid(),
+    user_id uuid NOT NULL,
+    label character varying(50) NOT NULL,
+    latitude double precision NOT NULL,
+    longitude double precision NOT NULL,
+    created_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT favorite_locations_pkey PRIMARY KEY (favorite_id),
+    CONSTRAINT favorite_locations_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_favorite_locations_user_id ON favorite_locations(user_id);
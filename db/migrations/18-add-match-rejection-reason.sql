@@ -0,0 +1,4 @@
+-- Why the driver rejected a match, aggregated for the admin rejection reasons report
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS rejection_reason character varying(32) NULL;
+ALTER TABLE matches DROP CONSTRAINT IF EXISTS check_match_rejection_reason;
+ALTER TABLE matches ADD CONSTRAINT check_match_rejection_reason CHECK (rejection_reason IS NULL OR rejection_reason IN ('TOO_FAR', 'WRONG_DIRECTION', 'UNDESIRABLE_DESTINATION'));
@@ -0,0 +1,3 @@
+-- Trip estimate quoted on the match proposal, kept to compare against the fare actually charged
+ALTER TABLE ride_fares ADD COLUMN IF NOT EXISTS estimated_fare integer NOT NULL DEFAULT 0;
+ALTER TABLE ride_fares ADD COLUMN IF NOT EXISTS estimated_distance_km double precision NOT NULL DEFAULT 0;
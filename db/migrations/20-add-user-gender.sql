@@ -0,0 +1,8 @@
+-- Gender and the driver gender a passenger asks to be matched with, for markets that offer
+-- gender-preference matching. Empty means not given / no preference
+ALTER TABLE users ADD COLUMN IF NOT EXISTS gender character varying(10) NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS driver_gender_preference character varying(10) NOT NULL DEFAULT '';
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_user_gender;
+ALTER TABLE users ADD CONSTRAINT check_user_gender CHECK (gender IN ('', 'female', 'male'));
+ALTER TABLE users DROP CONSTRAINT IF EXISTS check_user_driver_gender_preference;
+ALTER TABLE users ADD CONSTRAINT check_user_driver_gender_preference CHECK (driver_gender_preference IN ('', 'female', 'male'));
@@ -0,0 +1,3 @@
+-- Completing a ride locks its billing ledger and snapshots the fare the passenger paid
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS ledger_finalized boolean NOT NULL DEFAULT false;
+ALTER TABLE rides ADD COLUMN IF NOT EXISTS final_total integer NOT NULL DEFAULT 0;
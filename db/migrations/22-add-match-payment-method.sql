@@ -0,0 +1,2 @@
+-- How the passenger chose to pay, carried from their search to the ride created from the match
+ALTER TABLE matches ADD COLUMN IF NOT EXISTS payment_method character varying(10) NOT NULL DEFAULT 'QRIS';
@@ -0,0 +1,2 @@
+-- Relays lease the pending events they publish, so concurrent rides instances don't publish the same event
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS claimed_until timestamp with time zone NULL;
@@ -0,0 +1,4 @@
+-- Checks existing matches against matches_distinct_participants. This is kept apart from adding the
+-- constraint because each migration runs in one transaction; on its own, VALIDATE holds only a
+-- SHARE UPDATE EXCLUSIVE lock, so reads and writes on matches continue while it scans.
+ALTER TABLE matches VALIDATE CONSTRAINT matches_distinct_participants;
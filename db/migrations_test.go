@@ -0,0 +1,70 @@
+package db
+
+import (
+	"io/fs"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	createPattern        = regexp.MustCompile(`(?i)CREATE (UNIQUE )?(TABLE|INDEX) (\w+)`)
+	addColumnPattern     = regexp.MustCompile(`(?i)ADD COLUMN (\w+)`)
+	addConstraintPattern = regexp.MustCompile(`(?i)ADD CONSTRAINT (\w+)`)
+	createTypePattern    = regexp.MustCompile(`(?i)CREATE TYPE`)
+)
+
+func TestMigrations_Embedded(t *testing.T) {
+	embedded, err := fs.Glob(Migrations(), "*.sql")
+	require.NoError(t, err)
+
+	entries, err := os.ReadDir("migrations")
+	require.NoError(t, err)
+	var onDisk []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".sql") {
+			onDisk = append(onDisk, entry.Name())
+		}
+	}
+
+	assert.Equal(t, onDisk, embedded)
+}
+
+// Migrations also run against databases created by docker's initdb, so every statement must be
+// safe to run twice
+func TestMigrations_Rerunnable(t *testing.T) {
+	names, err := fs.Glob(Migrations(), "*.sql")
+	require.NoError(t, err)
+
+	for _, name := range names {
+		script, err := fs.ReadFile(Migrations(), name)
+		require.NoError(t, err)
+		sql := string(script)
+
+		for _, match := range createPattern.FindAllStringSubmatch(sql, -1) {
+			if strings.EqualFold(match[3], "IF") {
+				continue
+			}
+			t.Errorf("%s: CREATE %s %s needs IF NOT EXISTS", name, match[2], match[3])
+		}
+		for _, match := range addColumnPattern.FindAllStringSubmatch(sql, -1) {
+			if strings.EqualFold(match[1], "IF") {
+				continue
+			}
+			t.Errorf("%s: ADD COLUMN %s needs IF NOT EXISTS", name, match[1])
+		}
+		for _, match := range addConstraintPattern.FindAllStringSubmatchIndex(sql, -1) {
+			constraint := sql[match[2]:match[3]]
+			if !strings.Contains(sql[:match[0]], "DROP CONSTRAINT IF EXISTS "+constraint) {
+				t.Errorf("%s: ADD CONSTRAINT %s needs a preceding DROP CONSTRAINT IF EXISTS", name, constraint)
+			}
+		}
+		if createTypePattern.MatchString(sql) && !strings.Contains(sql, "duplicate_object") {
+			t.Errorf("%s: CREATE TYPE needs a duplicate_object handler", name)
+		}
+	}
+}
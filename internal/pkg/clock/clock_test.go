@@ -0,0 +1,30 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReal_Now(t *testing.T) {
+	before := time.Now()
+	now := Real{}.Now()
+
+	assert.False(t, now.Before(before))
+	assert.False(t, now.After(time.Now()))
+}
+
+func TestMock(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := NewMock(start)
+
+	assert.Equal(t, start, m.Now())
+
+	m.Advance(30 * time.Second)
+	assert.Equal(t, start.Add(30*time.Second), m.Now())
+
+	later := start.Add(time.Hour)
+	m.Set(later)
+	assert.Equal(t, later, m.Now())
+}
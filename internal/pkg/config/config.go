@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
@@ -39,6 +40,8 @@ func loadConfigFromEnv() *models.Config {
 	configs.Server.GRPCPort = GetEnvAsInt("SERVER_GRPC_PORT", 0)
 	configs.Server.ReadTimeout = GetEnvAsInt("SERVER_READ_TIMEOUT", 0)
 	configs.Server.WriteTimeout = GetEnvAsInt("SERVER_WRITE_TIMEOUT", 0)
+	configs.Server.ReadHeaderTimeout = GetEnvAsInt("SERVER_READ_HEADER_TIMEOUT", 10)
+	configs.Server.IdleTimeout = GetEnvAsInt("SERVER_IDLE_TIMEOUT", 120)
 	configs.Server.ShutdownTimeout = GetEnvAsInt("SERVER_SHUTDOWN_TIMEOUT", 0)
 
 	// Database config
@@ -51,6 +54,7 @@ func loadConfigFromEnv() *models.Config {
 	configs.Database.SSLMode = GetEnv("DB_SSL_MODE", "")
 	configs.Database.MaxConns = GetEnvAsInt("DB_MAX_CONNS", 0)
 	configs.Database.IdleConns = GetEnvAsInt("DB_IDLE_CONNS", 0)
+	configs.Database.MigrateOnStartup = GetEnvAsBool("DB_MIGRATE_ON_STARTUP", false)
 
 	// Redis config
 	configs.Redis.Host = GetEnv("REDIS_HOST", "")
@@ -58,14 +62,30 @@ func loadConfigFromEnv() *models.Config {
 	configs.Redis.Password = GetEnv("REDIS_PASSWORD", "")
 	configs.Redis.DB = GetEnvAsInt("REDIS_DB", 0)
 	configs.Redis.PoolSize = GetEnvAsInt("REDIS_POOL_SIZE", 0)
+	configs.Redis.KeyPrefix = GetEnv("REDIS_KEY_PREFIX", "")
+	configs.Redis.RetryAttempts = GetEnvAsInt("REDIS_RETRY_ATTEMPTS", 3)
+	configs.Redis.RetryBackoffMs = GetEnvAsInt("REDIS_RETRY_BACKOFF_MS", 20)
 
 	// NATS config
 	configs.NATS.URL = GetEnv("NATS_URL", "")
+	configs.NATS.Streams = loadNATSStreamConfigs()
+	configs.NATS.MaxReconnects = GetEnvAsInt("NATS_MAX_RECONNECTS", 0)
+	configs.NATS.ReconnectWaitMs = GetEnvAsInt("NATS_RECONNECT_WAIT_MS", 0)
+	configs.NATS.ReconnectBufferBytes = GetEnvAsInt("NATS_RECONNECT_BUFFER_BYTES", 0)
+	configs.NATS.PublishReconnectWaitMs = GetEnvAsInt("NATS_PUBLISH_RECONNECT_WAIT_MS", 0)
+	configs.NATS.ConsumerMaxConcurrency = GetEnvAsInt("NATS_CONSUMER_MAX_CONCURRENCY", 0)
+
+	// Region overrides for matching radius and pricing
+	configs.Regions = loadRegionConfigs()
+	configs.Surcharges = loadSurchargeConfigs()
 
 	// JWT config
 	configs.JWT.Secret = GetEnv("JWT_SECRET", "")
 	configs.JWT.Expiration = GetEnvAsInt("JWT_EXPIRATION", 0)
 	configs.JWT.Issuer = GetEnv("JWT_ISSUER", "")
+	configs.JWT.KeyID = GetEnv("JWT_KEY_ID", "")
+	configs.JWT.PreviousSecret = GetEnv("JWT_PREVIOUS_SECRET", "")
+	configs.JWT.PreviousKeyID = GetEnv("JWT_PREVIOUS_KEY_ID", "")
 
 	// Services config
 	configs.Services.MatchServiceURL = GetEnv("MATCH_SERVICE_URL", "http://localhost:9993")
@@ -74,14 +94,78 @@ func loadConfigFromEnv() *models.Config {
 
 	// Match config
 	configs.Match.SearchRadiusKm = GetEnvAsFloat("MATCH_SEARCH_RADIUS_KM", 1.0)
+	configs.Match.ActiveRideTTLHours = GetEnvAsInt("MATCH_ACTIVE_RIDE_TTL_HOURS", 24)
+	configs.Match.MaxLocationAgeSecs = GetEnvAsInt("MATCH_MAX_LOCATION_AGE_SECONDS", 60)
+	configs.Match.ProposalDedupSecs = GetEnvAsInt("MATCH_PROPOSAL_DEDUP_SECONDS", 30)
+	configs.Match.ProposalTTLSeconds = GetEnvAsInt("MATCH_PROPOSAL_TTL_SECONDS", 30)
+	configs.Match.SchedulerPollSecs = GetEnvAsInt("MATCH_SCHEDULER_POLL_SECONDS", 15)
+	configs.Match.SchedulerBatchSize = GetEnvAsInt("MATCH_SCHEDULER_BATCH_SIZE", 100)
+	configs.Match.PoolRemovalRetrySecs = GetEnvAsInt("MATCH_POOL_REMOVAL_RETRY_SECONDS", 10)
+	configs.Match.AutoRejectionAttempts = GetEnvAsInt("MATCH_AUTO_REJECTION_ATTEMPTS", 4)
+	configs.Match.AutoRejectionBackoffMs = GetEnvAsInt("MATCH_AUTO_REJECTION_BACKOFF_MS", 200)
+	configs.Match.AutoRejectionRetrySecs = GetEnvAsInt("MATCH_AUTO_REJECTION_RETRY_SECONDS", 30)
+	configs.Match.MaxPendingPerPassenger = GetEnvAsInt("MATCH_MAX_PENDING_PER_PASSENGER", 10)
+	configs.Match.AcceptanceWindowSecs = GetEnvAsInt("MATCH_ACCEPTANCE_WINDOW_SECONDS", 0)
+	configs.Match.MaintenanceMode = GetEnvAsBool("MATCH_MAINTENANCE_MODE", false)
+	configs.Match.CancellationWindowHours = GetEnvAsInt("MATCH_CANCELLATION_WINDOW_HOURS", 24)
+	configs.Match.CancellationRateThreshold = GetEnvAsFloat("MATCH_CANCELLATION_RATE_THRESHOLD", 0.5)
+	configs.Match.CancellationMinAccepted = GetEnvAsInt("MATCH_CANCELLATION_MIN_ACCEPTED", 5)
+	configs.Match.CancellationPenaltyMins = GetEnvAsInt("MATCH_CANCELLATION_PENALTY_MINUTES", 30)
+	configs.Match.FairnessMode = GetEnvAsBool("MATCH_FAIRNESS_MODE", false)
+	configs.Match.FairnessBandKm = GetEnvAsFloat("MATCH_FAIRNESS_BAND_KM", 0.5)
+	configs.Match.FairnessMaxProposals = GetEnvAsInt("MATCH_FAIRNESS_MAX_PROPOSALS", 3)
+	configs.Match.BackToBackRides = GetEnvAsBool("MATCH_BACK_TO_BACK_RIDES", false)
+	configs.Match.BackToBackMaxDropoffKm = GetEnvAsFloat("MATCH_BACK_TO_BACK_MAX_DROPOFF_KM", 1.0)
+	configs.Match.ProposalRefreshSecs = GetEnvAsInt("MATCH_PROPOSAL_REFRESH_SECONDS", 0)
+	configs.Match.AverageSpeedKmh = GetEnvAsFloat("MATCH_AVERAGE_SPEED_KMH", 25.0)
+
+	// Location config
+	configs.Location.AvailabilityTTLMinutes = GetEnvAsInt("LOCATION_AVAILABILITY_TTL_MINUTES", 30)
+	configs.Location.DriverPresenceTTLSecs = GetEnvAsInt("LOCATION_DRIVER_PRESENCE_TTL_SECONDS", 120)
+	configs.Location.PoolSweepIntervalSecs = GetEnvAsInt("LOCATION_POOL_SWEEP_INTERVAL_SECONDS", 30)
+	configs.Location.PublishIntervalMs = GetEnvAsInt("LOCATION_PUBLISH_INTERVAL_MS", 1000)
+	configs.Location.MaxSegmentSpeedKmh = GetEnvAsFloat("LOCATION_MAX_SEGMENT_SPEED_KMH", 150)
+
+	// Users config
+	configs.Users.MaxFavoriteLocations = GetEnvAsInt("USERS_MAX_FAVORITE_LOCATIONS", 10)
+	configs.Users.OTPRequestLimit = GetEnvAsInt("USERS_OTP_REQUEST_LIMIT", 5)
+	configs.Users.OTPRequestWindowSecs = GetEnvAsInt("USERS_OTP_REQUEST_WINDOW_SECONDS", 60)
+	configs.Users.OTPMaxAttempts = GetEnvAsInt("USERS_OTP_MAX_ATTEMPTS", 5)
+	configs.Users.OTPLogSender = GetEnvAsBool("USERS_OTP_LOG_SENDER", false)
+	configs.Users.UserCacheTTLSecs = GetEnvAsInt("USERS_USER_CACHE_TTL_SECONDS", 300)
 
 	// Pricing config
 	configs.Pricing.RatePerKm = GetEnvAsFloat("PRICING_RATE_PER_KM", 3000.0)
 
 	configs.Pricing.AdminFeePercent = GetEnvAsFloat("BILLING_ADMIN_FEE_PERCENT", 5.0)
+	configs.Pricing.AdminFeeRounding = GetEnv("BILLING_ADMIN_FEE_ROUNDING", models.RoundingModeTruncate)
 
 	// Rides config
 	configs.Rides.MinDistanceKm = GetEnvAsFloat("RIDES_MIN_DISTANCE_KM", 1.0)
+	configs.Rides.MaxPickupDistanceMeters = GetEnvAsFloat("RIDES_MAX_PICKUP_DISTANCE_METERS", 100.0)
+	configs.Rides.OutboxRelayIntervalSecs = GetEnvAsInt("RIDES_OUTBOX_RELAY_INTERVAL_SECONDS", 5)
+	configs.Rides.OutboxRelayBatchSize = GetEnvAsInt("RIDES_OUTBOX_RELAY_BATCH_SIZE", 100)
+	configs.Rides.AverageSpeedKmh = GetEnvAsFloat("RIDES_AVERAGE_SPEED_KMH", 25.0)
+	configs.Rides.ETARefreshThresholdSecs = GetEnvAsInt("RIDES_ETA_REFRESH_THRESHOLD_SECONDS", 60)
+	configs.Rides.CancellationGraceSecs = GetEnvAsInt("RIDES_CANCELLATION_GRACE_SECONDS", 120)
+	configs.Rides.DriverCancellationPenalty = GetEnvAsInt("RIDES_DRIVER_CANCELLATION_PENALTY", 5000)
+	configs.Rides.PassengerCancellationFee = GetEnvAsInt("RIDES_PASSENGER_CANCELLATION_FEE", 2000)
+	configs.Rides.AutoStartEnabled = GetEnvAsBool("RIDES_AUTO_START_ENABLED", false)
+	configs.Rides.AutoStartGraceMeters = GetEnvAsFloat("RIDES_AUTO_START_GRACE_METERS", 30.0)
+	configs.Rides.AutoStartGraceSecs = GetEnvAsInt("RIDES_AUTO_START_GRACE_SECONDS", 30)
+	configs.Rides.WaitingGraceSecs = GetEnvAsInt("RIDES_WAITING_GRACE_SECONDS", 180)
+	configs.Rides.WaitingFeePerMinute = GetEnvAsInt("RIDES_WAITING_FEE_PER_MINUTE", 0)
+	configs.Rides.PaymentCacheTTLSecs = GetEnvAsInt("RIDES_PAYMENT_CACHE_TTL_SECONDS", 30)
+	configs.Rides.RideCacheTTLSecs = GetEnvAsInt("RIDES_RIDE_CACHE_TTL_SECONDS", 30)
+	configs.Rides.MaxRideDurationMins = GetEnvAsInt("RIDES_MAX_RIDE_DURATION_MINUTES", 240)
+	configs.Rides.VerifyStartWithServerLocation = GetEnvAsBool("RIDES_VERIFY_START_SERVER_LOCATION", false)
+	configs.Rides.StartLocationMaxAgeSecs = GetEnvAsInt("RIDES_START_LOCATION_MAX_AGE_SECONDS", 60)
+	configs.Rides.PickupCodeEnabled = GetEnvAsBool("RIDES_PICKUP_CODE_ENABLED", false)
+	configs.Rides.PickupCodeTTLSecs = GetEnvAsInt("RIDES_PICKUP_CODE_TTL_SECONDS", 3600)
+	configs.Rides.PickupCodeMaxAttempts = GetEnvAsInt("RIDES_PICKUP_CODE_MAX_ATTEMPTS", 5)
+
+	// Feature flag config
+	configs.Features.CacheTTLSecs = GetEnvAsInt("FEATURE_FLAG_CACHE_TTL_SECONDS", 30)
 
 	// Payment config
 	configs.Payment.QRCodeBaseURL = GetEnv("PAYMENT_QR_CODE_BASE_URL", "https://payment.nebengjek.com/qr")
@@ -102,6 +186,13 @@ func loadConfigFromEnv() *models.Config {
 	configs.APIKey.MatchService = GetEnv("API_KEY_MATCH_SERVICE", "")
 	configs.APIKey.RidesService = GetEnv("API_KEY_RIDES_SERVICE", "")
 	configs.APIKey.LocationService = GetEnv("API_KEY_LOCATION_SERVICE", "")
+	configs.APIKey.Admin = GetEnv("API_KEY_ADMIN", "")
+
+	// CORS config
+	configs.CORS.AllowedOrigins = GetEnvAsSlice("CORS_ALLOWED_ORIGINS", nil)
+	configs.CORS.AllowedMethods = GetEnvAsSlice("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"})
+	configs.CORS.AllowedHeaders = GetEnvAsSlice("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "X-API-Key", "X-Request-ID"})
+	configs.CORS.MaxAgeSeconds = GetEnvAsInt("CORS_MAX_AGE_SECONDS", 600)
 
 	// Logger config
 	configs.Logger.Level = GetEnv("LOG_LEVEL", "info")
@@ -111,10 +202,64 @@ func loadConfigFromEnv() *models.Config {
 	configs.Logger.MaxBackups = GetEnvAsInt("LOG_MAX_BACKUPS", 3)
 	configs.Logger.Compress = GetEnvAsBool("LOG_COMPRESS", true)
 	configs.Logger.Type = GetEnv("LOG_TYPE", "file")
+	configs.Logger.RedactPII = GetEnvAsBool("LOG_REDACT_PII", false)
 
 	return configs
 }
 
+// loadNATSStreamConfigs reads per-stream JetStream limits such as NATS_USER_STREAM_MAX_AGE_MINUTES,
+// only keeping streams that have at least one override set
+func loadNATSStreamConfigs() map[string]models.NATSStreamConfig {
+	streams := make(map[string]models.NATSStreamConfig)
+	for _, name := range []string{"USER_STREAM", "MATCH_STREAM", "RIDE_STREAM", "LOCATION_STREAM"} {
+		streamCfg := models.NATSStreamConfig{
+			MaxAgeMinutes: GetEnvAsInt("NATS_"+name+"_MAX_AGE_MINUTES", 0),
+			MaxBytes:      GetEnvAsInt64("NATS_"+name+"_MAX_BYTES", 0),
+			Retention:     GetEnv("NATS_"+name+"_RETENTION", ""),
+		}
+		if streamCfg != (models.NATSStreamConfig{}) {
+			streams[name] = streamCfg
+		}
+	}
+	return streams
+}
+
+// loadRegionConfigs reads the regions named in REGIONS, e.g. REGIONS=jakarta with
+// REGION_JAKARTA_GEOHASH_PREFIX=qqgu. Regions without a geohash prefix are skipped.
+func loadRegionConfigs() []models.RegionConfig {
+	var regions []models.RegionConfig
+	for _, name := range GetEnvAsSlice("REGIONS", nil) {
+		prefix := "REGION_" + strings.ToUpper(name) + "_"
+		regionCfg := models.RegionConfig{
+			Name:           name,
+			GeohashPrefix:  strings.ToLower(GetEnv(prefix+"GEOHASH_PREFIX", "")),
+			SearchRadiusKm: GetEnvAsFloat(prefix+"SEARCH_RADIUS_KM", 0),
+			RatePerKm:      GetEnvAsFloat(prefix+"RATE_PER_KM", 0),
+		}
+		if regionCfg.GeohashPrefix == "" {
+			logger.Warn("Skipping region without a geohash prefix", logger.String("region", name))
+			continue
+		}
+		regions = append(regions, regionCfg)
+	}
+	return regions
+}
+
+// loadSurchargeConfigs reads the surcharges named in SURCHARGES, e.g. SURCHARGES=toll with
+// SURCHARGE_TOLL_AMOUNT=10000. Surcharges without a positive amount are skipped.
+func loadSurchargeConfigs() []models.SurchargeConfig {
+	var surcharges []models.SurchargeConfig
+	for _, name := range GetEnvAsSlice("SURCHARGES", nil) {
+		amount := GetEnvAsInt("SURCHARGE_"+strings.ToUpper(name)+"_AMOUNT", 0)
+		if amount <= 0 {
+			logger.Warn("Skipping surcharge without a positive amount", logger.String("surcharge", name))
+			continue
+		}
+		surcharges = append(surcharges, models.SurchargeConfig{Name: strings.ToLower(name), Amount: amount})
+	}
+	return surcharges
+}
+
 // Helper functions to get environment variables with different types
 func GetEnv(key, defaultValue string) string {
 	value := os.Getenv(key)
@@ -199,3 +344,23 @@ func GetEnvAsFloat(key string, defaultValue float64) float64 {
 
 	return value
 }
+
+// GetEnvAsSlice reads a comma-separated environment variable, trimming blanks around each entry
+func GetEnvAsSlice(key string, defaultValue []string) []string {
+	valueStr := GetEnv(key, "")
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	var values []string
+	for _, part := range strings.Split(valueStr, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+
+	return values
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/joho/godotenv"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// Reload re-reads the config file, letting its values replace those loaded at startup, and
+// returns the resulting config. A missing or unreadable file leaves the environment as it is.
+func Reload(configPath string) *models.Config {
+	if err := godotenv.Overload(configPath); err != nil {
+		logger.Warn("error reloading config from file",
+			logger.String("config_path", configPath),
+			logger.Err(err))
+	}
+	return loadConfigFromEnv()
+}
+
+// WatchReload calls apply with a freshly reloaded config every time the process receives SIGHUP,
+// until ctx is cancelled
+func WatchReload(ctx context.Context, configPath string, apply func(*models.Config)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			logger.Info("Received SIGHUP, reloading config",
+				logger.String("config_path", configPath))
+			apply(Reload(configPath))
+		}
+	}
+}
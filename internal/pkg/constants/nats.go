@@ -7,17 +7,28 @@ const (
 	SubjectUserFinder = "user.finder"
 
 	// Match Service
-	SubjectMatchFound    = "match.found"
-	SubjectMatchRejected = "match.rejected"
-	SubjectMatchAccepted = "match.accepted"
+	SubjectMatchFound       = "match.found"
+	SubjectMatchRejected    = "match.rejected"
+	SubjectMatchAccepted    = "match.accepted"
+	SubjectMatchNoDrivers   = "match.no_drivers"
+	SubjectMatchAcceptances = "match.acceptances"
+	SubjectMatchProposals   = "match.proposals"
+	SubjectMatchMaintenance = "match.maintenance"
 
 	// Ride events
 	SubjectRidePickup    = "ride.pickup"
+	SubjectRidePickupETA = "ride.pickup_eta"
 	SubjectRideStarted   = "ride.started"
 	SubjectRideArrived   = "ride.arrived"
 	SubjectRideCompleted = "ride.completed"
+	SubjectRideCancelled = "ride.cancelled"
+	SubjectRideBilling   = "ride.billing"
 
 	// Location Service
-	SubjectLocationUpdate    = "location.update"
-	SubjectLocationAggregate = "location.aggregate"
+	SubjectLocationUpdate        = "location.update"
+	SubjectLocationAggregate     = "location.aggregate"
+	SubjectLocationDriverEvicted = "location.driver_evicted"
+
+	// System announcements, published on core NATS so every users service instance receives them
+	SubjectSystemAnnouncement = "system.announcement"
 )
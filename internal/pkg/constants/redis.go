@@ -3,7 +3,10 @@ package constants
 // Redis key formats
 const (
 	// User Service
-	KeyUserOTP = "user:otp:%s" // Format: user:otp:{msisdn}
+	KeyUserOTP         = "user:otp:%s"          // Format: user:otp:{msisdn}
+	KeyUserOTPRequests = "user:otp-requests:%s" // Format: user:otp-requests:{msisdn}, sorted set of request IDs scored by request time
+	KeyUserOTPAttempts = "user:otp-attempts:%s" // Format: user:otp-attempts:{msisdn}, failed verifications of the pending OTP
+	KeyUserRecord      = "user:record:%s"       // Format: user:record:{user_id} -> user JSON, cached for GetUserByID
 
 	// Location Service
 	KeyDriverLocation      = "driver:location:%s"    // Format: driver:location:{driver_id}
@@ -12,6 +15,7 @@ const (
 	KeyPassengerGeo        = "passenger:geo"         // GeoHash set of all passenger locations
 	KeyAvailableDrivers    = "drivers:available"     // Set of available driver IDs
 	KeyAvailablePassengers = "passengers:available"  // Set of available passenger IDs
+	KeyDriverPresence      = "driver:presence:%s"    // Format: driver:presence:{driver_id}; expires when beacons stop
 
 	// Match Service
 	KeyMatchProposal        = "match:proposal:%s"         // Format: match:proposal:{match_id}
@@ -19,13 +23,59 @@ const (
 	KeyDriverMatch          = "driver:match:%s"           // Format: driver:match:{driver_id}
 	KeyPendingMatchPair     = "match:pending:%s:%s"       // Format: match:pending:{driver_id}:{passenger_id}
 	KeyDriverPendingMatches = "driver:pending-matches:%s" // Format: driver:pending-matches:{driver_id}
+	KeyProposalDedup        = "match:proposed:%s:%s"      // Format: match:proposed:{passenger_id}:{driver_id}
+	KeyProposalRefresh      = "match:proposal-refresh:%s" // Format: match:proposal-refresh:{passenger_id}; set while re-sent proposals are throttled
+	KeyProposalRefreshes    = "match:proposal-refreshes"  // Sorted set of passenger IDs scored by unix time a throttled refresh is due
+
+	// Driver cancellation tracking
+	KeyDriverAccepted  = "driver:accepted:%s"  // Format: driver:accepted:{driver_id}; sorted set of match IDs scored by unix time
+	KeyDriverCancelled = "driver:cancelled:%s" // Format: driver:cancelled:{driver_id}; sorted set of ride IDs scored by unix time
+	KeyDriverSuspended = "driver:suspended:%s" // Format: driver:suspended:{driver_id}; expires when the penalty ends
+
+	// Fairness rotation - when each driver last completed a ride
+	KeyDriverLastRide = "driver:last-ride" // Hash of driver ID to the unix time their last ride completed
+
+	// Gender-preference matching - each driver's gender, recorded from their beacons
+	KeyDriverGender = "driver:gender" // Hash of driver ID to the gender on their profile
+
+	// Back-to-back rides - drivers about to finish a ride who asked for their next match early
+	KeyDriverFinishingRide = "driver:finishing-ride:%s" // Format: driver:finishing-ride:{driver_id} -> ride_id being finished
+
+	// Maintenance mode - while set, no new users are added to the matching pools
+	KeyMatchMaintenance = "match:maintenance"
+
+	// Feature flags - runtime switches for gradually rolled out behaviour, namespaced per service
+	KeyFeatureFlag = "featureflag:%s:%s" // Format: featureflag:{service}:{flag} -> "1" or "0"
+
+	// Pool removals that failed when a match was accepted, retried until the user leaves the pool
+	KeyPoolRemovals = "match:pool-removals" // Sorted set of "{role}:{user_id}" scored by unix time queued
+
+	// Auto-rejections of a passenger's other open matches that kept failing, retried in the background
+	KeyAutoRejections = "match:auto-rejections" // Sorted set of "{passenger_id}:{accepted_match_id}" scored by unix time queued
+
+	// Acceptance windows - driver acceptances gathered for the passenger to choose from
+	KeyAcceptanceWindows = "match:acceptance-windows"   // Sorted set of passenger IDs scored by unix time the window closes
+	KeyAcceptanceWindow  = "match:acceptance-window:%s" // Format: match:acceptance-window:{passenger_id}; set while a window is open
+
+	// Scheduled rides - finder events held until their scheduled time
+	KeyScheduledFinders     = "match:scheduled"    // Sorted set of passenger IDs scored by scheduled unix time
+	KeyScheduledFinderEvent = "match:scheduled:%s" // Format: match:scheduled:{passenger_id} -> finder event JSON
 
 	// Ride Service
-	KeyRideLocation = "rides:location:%s" // Format: trip:location:{trip_id}
+	KeyRideLocation           = "rides:location:%s"             // Format: trip:location:{trip_id}
+	KeyRidePayment            = "rides:payment:%s"              // Format: rides:payment:{ride_id} -> payment JSON, cached briefly
+	KeyRidePickupCode         = "rides:pickup-code:%s"          // Format: rides:pickup-code:{ride_id} -> code the driver enters to start the ride
+	KeyRidePickupCodeAttempts = "rides:pickup-code-attempts:%s" // Format: rides:pickup-code-attempts:{ride_id} -> wrong codes entered for the current code
 
 	// Active rides tracking - used by match service to prevent matching during active rides
 	KeyActiveRideDriver    = "active_ride:driver:%s"    // Format: active_ride:driver:{driver_id} -> ride_id
 	KeyActiveRidePassenger = "active_ride:passenger:%s" // Format: active_ride:passenger:{passenger_id} -> ride_id
+
+	// Ride locks - held while a user is being locked into a ride so racing events cannot re-add them to pools
+	KeyRideLockUser = "lock:ride:user:%s" // Format: lock:ride:user:{user_id}
+
+	// Ride chat - recent messages kept so participants can catch up after reconnecting
+	KeyRideChat = "ride:chat:%s" // Format: ride:chat:{ride_id} -> list of recent chat messages
 )
 
 // Redis hash fields
@@ -33,6 +83,7 @@ const (
 	FieldLatitude    = "lat"
 	FieldLongitude   = "lng"
 	FieldTimestamp   = "ts"
+	FieldReceivedAt  = "received_at"
 	FieldStatus      = "status"
 	FieldDriverID    = "driver_id"
 	FieldPassengerID = "passenger_id"
@@ -13,16 +13,30 @@ const (
 	EventLocationUpdate = "location_update"
 
 	// Match events
-	EventMatchConfirm  = "match_confirm"
-	EventMatchRejected = "match_rejected"
+	EventMatchConfirm     = "match_confirm"
+	EventMatchRejected    = "match_rejected"
+	EventMatchNoDrivers   = "match_no_drivers"  // When a passenger's search finds no available drivers
+	EventMatchAcceptances = "match_acceptances" // Drivers who accepted during the acceptance window, for the passenger to choose from
+	EventMatchProposals   = "match_proposals"   // The passenger's pending proposals, re-ranked after their drivers moved
+	EventMatchMaintenance = "match_maintenance" // When a driver's beacon or passenger's search is refused during maintenance
 
 	// Ride events
 	EventRideStarted      = "ride_started"      // When a ride is created
 	EventRidePickup       = "ride_pickup"       // When driver is on the way to pick up passenger
+	EventRidePickupETA    = "ride_pickup_eta"   // When the driver's estimated arrival at pickup changes
 	EventRideArrived      = "ride_arrived"      // When driver indicates arrival
 	EventPaymentRequest   = "payment_request"   // When payment request is generated after arrival
 	EventPaymentProcessed = "payment_processed" // When payment is processed
 	EventRideCompleted    = "ride_completed"    // When ride is completed and payment processed
+	EventRideCancelled    = "ride_cancelled"    // When the driver or passenger cancels the ride
+	EventRideBilling      = "ride_billing"      // When distance travelled is billed to an ongoing ride
+
+	// Chat events
+	EventChatMessage = "chat"         // Message between the driver and passenger of an active ride
+	EventChatHistory = "chat_history" // Recent chat messages of an active ride, requested after reconnecting
+
+	// System events
+	EventAnnouncement = "announcement" // Operator announcement broadcast to connected users
 )
 
 // WebSocket error codes
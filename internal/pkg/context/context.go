@@ -19,6 +19,8 @@ const (
 	TraceIDKey ContextKey = "trace_id"
 	// ServiceNameKey is the key for service name in context
 	ServiceNameKey ContextKey = "service_name"
+	// CorrelationIDKey is the key for the cross-service correlation ID in context
+	CorrelationIDKey ContextKey = "correlation_id"
 )
 
 // WithRequestID adds a request ID to the context
@@ -76,6 +78,23 @@ func GetServiceName(ctx context.Context) string {
 	return ""
 }
 
+// WithCorrelationID adds the correlation ID carried by published events to the context.
+// An empty ID leaves the context unchanged.
+func WithCorrelationID(ctx context.Context, correlationID string) context.Context {
+	if correlationID == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, CorrelationIDKey, correlationID)
+}
+
+// GetCorrelationID retrieves the correlation ID from context, falling back to the request ID
+func GetCorrelationID(ctx context.Context) string {
+	if correlationID, ok := ctx.Value(CorrelationIDKey).(string); ok && correlationID != "" {
+		return correlationID
+	}
+	return GetRequestID(ctx)
+}
+
 // WithTimeout creates a context with timeout for operations
 func WithTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
 	return context.WithTimeout(ctx, timeout)
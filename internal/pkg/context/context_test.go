@@ -118,6 +118,25 @@ func TestGetRequestID(t *testing.T) {
 	}
 }
 
+func TestWithCorrelationID(t *testing.T) {
+	ctx := WithCorrelationID(context.Background(), "corr-123")
+	assert.Equal(t, "corr-123", GetCorrelationID(ctx))
+
+	// An empty ID leaves the context untouched
+	assert.Equal(t, ctx, WithCorrelationID(ctx, ""))
+}
+
+func TestGetCorrelationID(t *testing.T) {
+	// Falls back to the request ID when no correlation ID is set
+	ctx := WithRequestID(context.Background(), "req-123")
+	assert.Equal(t, "req-123", GetCorrelationID(ctx))
+
+	ctx = WithCorrelationID(ctx, "corr-456")
+	assert.Equal(t, "corr-456", GetCorrelationID(ctx))
+
+	assert.Empty(t, GetCorrelationID(context.Background()))
+}
+
 func TestWithUserID(t *testing.T) {
 	userIDs := []string{
 		"user-123",
@@ -1,9 +1,13 @@
 package converter
 
 import (
+	"fmt"
+
 	"github.com/google/uuid"
 )
 
+// StrToUUID converts s to a UUID, yielding uuid.Nil when s is malformed.
+// Use ParseUUID for IDs that come from clients.
 func StrToUUID(s string) uuid.UUID {
 	id, err := uuid.Parse(s)
 	if err != nil {
@@ -12,6 +16,18 @@ func StrToUUID(s string) uuid.UUID {
 	return id
 }
 
+// ParseUUID converts s to a UUID, returning an error when s is malformed or the nil UUID
+func ParseUUID(s string) (uuid.UUID, error) {
+	id, err := uuid.Parse(s)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("invalid UUID %q: %w", s, err)
+	}
+	if id == uuid.Nil {
+		return uuid.Nil, fmt.Errorf("invalid UUID %q: nil UUID", s)
+	}
+	return id, nil
+}
+
 func UUIDToStr(id uuid.UUID) string {
 	return id.String()
 }
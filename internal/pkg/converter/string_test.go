@@ -0,0 +1,29 @@
+package converter
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseUUID(t *testing.T) {
+	valid := uuid.New()
+
+	id, err := ParseUUID(valid.String())
+	require.NoError(t, err)
+	assert.Equal(t, valid, id)
+
+	for _, s := range []string{"", "match-123", uuid.Nil.String()} {
+		t.Run(s, func(t *testing.T) {
+			id, err := ParseUUID(s)
+			assert.Error(t, err)
+			assert.Equal(t, uuid.Nil, id)
+		})
+	}
+}
+
+func TestStrToUUID_MalformedYieldsNil(t *testing.T) {
+	assert.Equal(t, uuid.Nil, StrToUUID("match-123"))
+}
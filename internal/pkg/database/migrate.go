@@ -0,0 +1,138 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+)
+
+// migrationLockID is the Postgres advisory lock held while migrating, so services starting
+// together don't apply the same migration twice
+const migrationLockID = 7243019
+
+// Migration is one versioned schema change
+type Migration struct {
+	Version string // File name without the .sql extension, e.g. "05-add-ride-pickup-eta"
+	SQL     string
+}
+
+// LoadMigrations reads the .sql files at the root of fsys, ordered by file name
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	sort.Strings(names)
+
+	migrations := make([]Migration, 0, len(names))
+	for _, name := range names {
+		script, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		migrations = append(migrations, Migration{
+			Version: strings.TrimSuffix(path.Base(name), ".sql"),
+			SQL:     string(script),
+		})
+	}
+	return migrations, nil
+}
+
+// RunMigrations applies the migrations in fsys that the database has not recorded yet and returns
+// the versions it applied
+func (p *PostgresClient) RunMigrations(ctx context.Context, fsys fs.FS) ([]string, error) {
+	migrations, err := LoadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return Migrate(ctx, p.db.DB, migrations)
+}
+
+// Migrate applies pending migrations in order, each in its own transaction together with its
+// schema_migrations row, and returns the versions it applied. Applied versions are skipped, so
+// running it again is a no-op.
+func Migrate(ctx context.Context, db *sql.DB, migrations []Migration) ([]string, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationLockID); err != nil {
+		return nil, fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockID)
+
+	createQuery := `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version character varying(255) NOT NULL PRIMARY KEY,
+			applied_at timestamp with time zone NOT NULL DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+	if _, err := conn.ExecContext(ctx, createQuery); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	applied, err := appliedVersions(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []string
+	for _, migration := range migrations {
+		if applied[migration.Version] {
+			continue
+		}
+		if err := applyMigration(ctx, conn, migration); err != nil {
+			return versions, err
+		}
+		versions = append(versions, migration.Version)
+	}
+	return versions, nil
+}
+
+// appliedVersions returns the versions recorded in schema_migrations
+func appliedVersions(ctx context.Context, conn *sql.Conn) (map[string]bool, error) {
+	rows, err := conn.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get applied migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[string]bool)
+	for rows.Next() {
+		var version string
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to scan applied migration: %w", err)
+		}
+		applied[version] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating applied migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// applyMigration runs one migration and records it, or leaves no trace if either fails
+func applyMigration(ctx context.Context, conn *sql.Conn, migration Migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %s: %w", migration.Version, err)
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version) VALUES ($1)`, migration.Version); err != nil {
+		return fmt.Errorf("failed to record migration %s: %w", migration.Version, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %s: %w", migration.Version, err)
+	}
+	return nil
+}
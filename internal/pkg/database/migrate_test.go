@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testMigrations = []Migration{
+	{Version: "00-create", SQL: "CREATE TABLE IF NOT EXISTS a (id int)"},
+	{Version: "01-index", SQL: "CREATE INDEX IF NOT EXISTS idx_a ON a(id)"},
+}
+
+func expectMigrationSetup(mock sqlmock.Sqlmock, applied ...string) {
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)).
+		WithArgs(migrationLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("CREATE TABLE IF NOT EXISTS schema_migrations").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	rows := sqlmock.NewRows([]string{"version"})
+	for _, version := range applied {
+		rows.AddRow(version)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version FROM schema_migrations`)).WillReturnRows(rows)
+}
+
+func expectMigrationApplied(mock sqlmock.Sqlmock, migration Migration) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(migration.SQL)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version) VALUES ($1)`)).
+		WithArgs(migration.Version).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+}
+
+func expectMigrationUnlock(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)).
+		WithArgs(migrationLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrate(t *testing.T) {
+	t.Run("applies pending migrations in order", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectMigrationSetup(mock)
+		expectMigrationApplied(mock, testMigrations[0])
+		expectMigrationApplied(mock, testMigrations[1])
+		expectMigrationUnlock(mock)
+
+		applied, err := Migrate(context.Background(), db, testMigrations)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"00-create", "01-index"}, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("skips applied migrations", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectMigrationSetup(mock, "00-create")
+		expectMigrationApplied(mock, testMigrations[1])
+		expectMigrationUnlock(mock)
+
+		applied, err := Migrate(context.Background(), db, testMigrations)
+
+		require.NoError(t, err)
+		assert.Equal(t, []string{"01-index"}, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("is a no-op when everything is applied", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectMigrationSetup(mock, "00-create", "01-index")
+		expectMigrationUnlock(mock)
+
+		applied, err := Migrate(context.Background(), db, testMigrations)
+
+		require.NoError(t, err)
+		assert.Empty(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back and stops on a failed migration", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		expectMigrationSetup(mock)
+		mock.ExpectBegin()
+		mock.ExpectExec(regexp.QuoteMeta(testMigrations[0].SQL)).WillReturnError(errors.New("syntax error"))
+		mock.ExpectRollback()
+		expectMigrationUnlock(mock)
+
+		applied, err := Migrate(context.Background(), db, testMigrations)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to apply migration 00-create")
+		assert.Empty(t, applied)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("lock failure", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)).
+			WithArgs(migrationLockID).
+			WillReturnError(errors.New("connection refused"))
+
+		applied, err := Migrate(context.Background(), db, testMigrations)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to acquire migration lock")
+		assert.Empty(t, applied)
+	})
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"01-index.sql":  {Data: []byte("CREATE INDEX")},
+		"00-create.sql": {Data: []byte("CREATE TABLE")},
+		"README.md":     {Data: []byte("not a migration")},
+	}
+
+	migrations, err := LoadMigrations(fsys)
+
+	require.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: "00-create", SQL: "CREATE TABLE"},
+		{Version: "01-index", SQL: "CREATE INDEX"},
+	}, migrations)
+}
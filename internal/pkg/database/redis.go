@@ -12,6 +12,11 @@ import (
 // RedisClient represents a Redis Client
 type RedisClient struct {
 	Client *redis.Client
+	// Prefix namespaces every key so environments can share one Redis instance, e.g. "staging:"
+	Prefix string
+	// RetryAttempts and RetryBackoff bound retries of transient failures on idempotent operations
+	RetryAttempts int
+	RetryBackoff  time.Duration
 }
 
 // NewRedisClient creates a new Redis Client
@@ -22,6 +27,8 @@ func NewRedisClient(config models.RedisConfig) (*RedisClient, error) {
 		Password: config.Password,
 		DB:       config.DB,
 		PoolSize: config.PoolSize,
+		// withRetry owns retries; leaving the driver's own retries on would multiply the attempts
+		MaxRetries: -1,
 	})
 
 	// Verify connection
@@ -33,7 +40,17 @@ func NewRedisClient(config models.RedisConfig) (*RedisClient, error) {
 		return nil, fmt.Errorf("failed to connect to redis: %w", err)
 	}
 
-	return &RedisClient{Client: Client}, nil
+	return &RedisClient{
+		Client:        Client,
+		Prefix:        config.KeyPrefix,
+		RetryAttempts: config.RetryAttempts,
+		RetryBackoff:  time.Duration(config.RetryBackoffMs) * time.Millisecond,
+	}, nil
+}
+
+// key applies the configured namespace prefix to a key or key pattern
+func (r *RedisClient) key(key string) string {
+	return r.Prefix + key
 }
 
 // GetClient returns the underlying Redis Client
@@ -43,85 +60,187 @@ func (r *RedisClient) GetClient() *redis.Client {
 
 // Set stores a key-value pair with an optional expiration
 func (r *RedisClient) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
-	return r.Client.Set(ctx, key, value, expiration).Err()
+	return r.withRetry(ctx, func() error {
+		return r.Client.Set(ctx, r.key(key), value, expiration).Err()
+	})
 }
 
 // SetNX sets value if key doesn't exist (Set if Not eXists)
 // Returns true if key was set, false if key already exists
 func (r *RedisClient) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
-	return r.Client.SetNX(ctx, key, value, expiration).Result()
+	return r.Client.SetNX(ctx, r.key(key), value, expiration).Result()
 }
 
 // Get retrieves a value by key
 func (r *RedisClient) Get(ctx context.Context, key string) (string, error) {
-	return r.Client.Get(ctx, key).Result()
+	var val string
+	err := r.withRetry(ctx, func() error {
+		var err error
+		val, err = r.Client.Get(ctx, r.key(key)).Result()
+		return err
+	})
+	return val, err
 }
 
 // Delete removes a key
 func (r *RedisClient) Delete(ctx context.Context, key string) error {
-	return r.Client.Del(ctx, key).Err()
+	return r.withRetry(ctx, func() error {
+		return r.Client.Del(ctx, r.key(key)).Err()
+	})
 }
 
 // Exists checks if a key exists
 func (r *RedisClient) Keys(ctx context.Context, key string) error {
-	return r.Client.Keys(ctx, key).Err()
+	return r.Client.Keys(ctx, r.key(key)).Err()
 }
 
 // GeoAdd adds geospatial data to a sorted set
 func (r *RedisClient) GeoAdd(ctx context.Context, key string, longitude, latitude float64, member string) error {
-	return r.Client.GeoAdd(ctx, key, &redis.GeoLocation{
-		Longitude: longitude,
-		Latitude:  latitude,
-		Name:      member,
-	}).Err()
+	return r.withRetry(ctx, func() error {
+		return r.Client.GeoAdd(ctx, r.key(key), &redis.GeoLocation{
+			Longitude: longitude,
+			Latitude:  latitude,
+			Name:      member,
+		}).Err()
+	})
 }
 
 // GeoRadius finds members within a radius from a point
 func (r *RedisClient) GeoRadius(ctx context.Context, key string, longitude, latitude float64, radius float64, unit string) ([]redis.GeoLocation, error) {
-	return r.Client.GeoRadius(ctx, key, longitude, latitude, &redis.GeoRadiusQuery{
-		Radius:    radius,
-		Unit:      unit,
-		WithCoord: true,
-		WithDist:  true,
-		Sort:      "ASC",
-	}).Result()
+	var locations []redis.GeoLocation
+	err := r.withRetry(ctx, func() error {
+		var err error
+		locations, err = r.Client.GeoRadius(ctx, r.key(key), longitude, latitude, &redis.GeoRadiusQuery{
+			Radius:    radius,
+			Unit:      unit,
+			WithCoord: true,
+			WithDist:  true,
+			Sort:      "ASC",
+		}).Result()
+		return err
+	})
+	return locations, err
 }
 
 // SAdd adds members to a set
 // Only adds elements that don't already exist in the set
 func (r *RedisClient) SAdd(ctx context.Context, key string, members ...interface{}) error {
-	return r.Client.SAdd(ctx, key, members...).Err()
+	return r.withRetry(ctx, func() error {
+		return r.Client.SAdd(ctx, r.key(key), members...).Err()
+	})
 }
 
 // SIsMember checks if a value is a member of a set
 func (r *RedisClient) SIsMember(ctx context.Context, key string, member interface{}) (bool, error) {
-	return r.Client.SIsMember(ctx, key, member).Result()
+	var isMember bool
+	err := r.withRetry(ctx, func() error {
+		var err error
+		isMember, err = r.Client.SIsMember(ctx, r.key(key), member).Result()
+		return err
+	})
+	return isMember, err
+}
+
+// SMIsMember checks several values for set membership in one round trip, in the order given
+func (r *RedisClient) SMIsMember(ctx context.Context, key string, members ...interface{}) ([]bool, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+	var isMember []bool
+	err := r.withRetry(ctx, func() error {
+		var err error
+		isMember, err = r.Client.SMIsMember(ctx, r.key(key), members...).Result()
+		return err
+	})
+	return isMember, err
+}
+
+// SCard returns the number of members in a set
+func (r *RedisClient) SCard(ctx context.Context, key string) (int64, error) {
+	return r.Client.SCard(ctx, r.key(key)).Result()
 }
 
 // SRem removes members from a set
 func (r *RedisClient) SRem(ctx context.Context, key string, members ...interface{}) error {
-	return r.Client.SRem(ctx, key, members...).Err()
+	return r.withRetry(ctx, func() error {
+		return r.Client.SRem(ctx, r.key(key), members...).Err()
+	})
+}
+
+// ZRange returns the members of a sorted set between the start and stop ranks, inclusive
+func (r *RedisClient) ZRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	var members []string
+	err := r.withRetry(ctx, func() error {
+		var err error
+		members, err = r.Client.ZRange(ctx, r.key(key), start, stop).Result()
+		return err
+	})
+	return members, err
 }
 
 // ZRem removes members from a sorted set
 func (r *RedisClient) ZRem(ctx context.Context, key string, members ...interface{}) error {
-	return r.Client.ZRem(ctx, key, members...).Err()
+	return r.withRetry(ctx, func() error {
+		return r.Client.ZRem(ctx, r.key(key), members...).Err()
+	})
+}
+
+// ZAdd adds a member to a sorted set with the given score
+func (r *RedisClient) ZAdd(ctx context.Context, key string, score float64, member interface{}) error {
+	return r.withRetry(ctx, func() error {
+		return r.Client.ZAdd(ctx, r.key(key), &redis.Z{Score: score, Member: member}).Err()
+	})
+}
+
+// ZRangeByScore returns up to count members of a sorted set with scores between min and max
+func (r *RedisClient) ZRangeByScore(ctx context.Context, key string, min, max string, count int64) ([]string, error) {
+	var members []string
+	err := r.withRetry(ctx, func() error {
+		var err error
+		members, err = r.Client.ZRangeByScore(ctx, r.key(key), &redis.ZRangeBy{Min: min, Max: max, Count: count}).Result()
+		return err
+	})
+	return members, err
+}
+
+// ZRemRangeByScore removes the members of a sorted set with scores between min and max
+func (r *RedisClient) ZRemRangeByScore(ctx context.Context, key string, min, max string) error {
+	return r.withRetry(ctx, func() error {
+		return r.Client.ZRemRangeByScore(ctx, r.key(key), min, max).Err()
+	})
+}
+
+// ZCount returns the number of members of a sorted set with scores between min and max
+func (r *RedisClient) ZCount(ctx context.Context, key string, min, max string) (int64, error) {
+	var count int64
+	err := r.withRetry(ctx, func() error {
+		var err error
+		count, err = r.Client.ZCount(ctx, r.key(key), min, max).Result()
+		return err
+	})
+	return count, err
+}
+
+// ZRemCount removes members from a sorted set and returns how many were removed.
+// It is not retried: a retry after a lost reply would report the removal as not done.
+func (r *RedisClient) ZRemCount(ctx context.Context, key string, members ...interface{}) (int64, error) {
+	return r.Client.ZRem(ctx, r.key(key), members...).Result()
 }
 
 // HMSet sets multiple hash fields
 func (r *RedisClient) HMSet(ctx context.Context, key string, values map[string]interface{}) error {
-	return r.Client.HMSet(ctx, key, values).Err()
+	return r.Client.HMSet(ctx, r.key(key), values).Err()
 }
 
 // HGetAll gets all fields in a hash
 func (r *RedisClient) HGetAll(ctx context.Context, key string) (map[string]string, error) {
-	return r.Client.HGetAll(ctx, key).Result()
+	return r.Client.HGetAll(ctx, r.key(key)).Result()
 }
 
 // HMGet gets specified fields of a hash
 func (r *RedisClient) HMGet(ctx context.Context, key string, fields ...string) ([]string, error) {
 	// Get values from Redis
-	vals, err := r.Client.HMGet(ctx, key, fields...).Result()
+	vals, err := r.Client.HMGet(ctx, r.key(key), fields...).Result()
 	if err != nil {
 		return nil, err
 	}
@@ -139,9 +258,72 @@ func (r *RedisClient) HMGet(ctx context.Context, key string, fields ...string) (
 	return results, nil
 }
 
+// Incr increments a counter, creating it at 1 when missing, and returns the new value
+func (r *RedisClient) Incr(ctx context.Context, key string) (int64, error) {
+	return r.Client.Incr(ctx, r.key(key)).Result()
+}
+
+// RunScript runs a Lua script atomically against the given keys and returns its result
+func (r *RedisClient) RunScript(ctx context.Context, script *redis.Script, keys []string, args ...interface{}) (interface{}, error) {
+	prefixed := make([]string, len(keys))
+	for i, k := range keys {
+		prefixed[i] = r.key(k)
+	}
+	return script.Run(ctx, r.Client, prefixed, args...).Result()
+}
+
+// Exists reports whether a key exists
+func (r *RedisClient) Exists(ctx context.Context, key string) (bool, error) {
+	count, err := r.Client.Exists(ctx, r.key(key)).Result()
+	return count > 0, err
+}
+
+// ExistsEach reports whether each of the keys exists, checking them all in one pipelined round trip
+func (r *RedisClient) ExistsEach(ctx context.Context, keys ...string) ([]bool, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	cmds := make([]*redis.IntCmd, len(keys))
+	_, err := r.Client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, k := range keys {
+			cmds[i] = pipe.Exists(ctx, r.key(k))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	exists := make([]bool, len(keys))
+	for i, cmd := range cmds {
+		exists[i] = cmd.Val() > 0
+	}
+	return exists, nil
+}
+
+// TTL returns how long until a key expires; it is negative when the key is missing or never expires
+func (r *RedisClient) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.Client.TTL(ctx, r.key(key)).Result()
+}
+
 // Expire sets an expiration on a key
 func (r *RedisClient) Expire(ctx context.Context, key string, expiration time.Duration) error {
-	return r.Client.Expire(ctx, key, expiration).Err()
+	return r.Client.Expire(ctx, r.key(key), expiration).Err()
+}
+
+// RPush appends values to the end of a list
+func (r *RedisClient) RPush(ctx context.Context, key string, values ...interface{}) error {
+	return r.Client.RPush(ctx, r.key(key), values...).Err()
+}
+
+// LTrim trims a list to the given inclusive range
+func (r *RedisClient) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return r.Client.LTrim(ctx, r.key(key), start, stop).Err()
+}
+
+// LRange gets the elements of a list in the given inclusive range
+func (r *RedisClient) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.Client.LRange(ctx, r.key(key), start, stop).Result()
 }
 
 // Close closes the Redis Client
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	// defaultRedisRetryAttempts is used when no retry attempt count is configured
+	defaultRedisRetryAttempts = 3
+	// defaultRedisRetryBackoff is used when no base retry backoff is configured
+	defaultRedisRetryBackoff = 20 * time.Millisecond
+)
+
+// transientReplyPrefixes are server replies that indicate a short-lived condition worth retrying
+var transientReplyPrefixes = []string{"LOADING", "READONLY", "TRYAGAIN", "BUSY", "CLUSTERDOWN", "MASTERDOWN"}
+
+// withRetry runs op, retrying transient failures with jittered exponential backoff.
+// The last error is returned unwrapped so callers can still compare against redis.Nil.
+func (r *RedisClient) withRetry(ctx context.Context, op func() error) error {
+	attempts := r.RetryAttempts
+	if attempts <= 0 {
+		attempts = defaultRedisRetryAttempts
+	}
+	backoff := r.RetryBackoff
+	if backoff <= 0 {
+		backoff = defaultRedisRetryBackoff
+	}
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = op(); err == nil || !isRetryableRedisError(err) {
+			return err
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		// Full jitter keeps concurrent callers from retrying in lockstep
+		delay := time.Duration(rand.Int63n(int64(backoff<<attempt) + 1))
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
+
+// isRetryableRedisError reports whether err is a transient network or server condition.
+// Misses, cancellations and command errors are returned to the caller immediately.
+func isRetryableRedisError(err error) bool {
+	if err == nil || errors.Is(err, redis.Nil) || errors.Is(err, redis.ErrClosed) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	var replyErr redis.Error
+	if errors.As(err, &replyErr) {
+		msg := replyErr.Error()
+		for _, prefix := range transientReplyPrefixes {
+			if strings.HasPrefix(msg, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
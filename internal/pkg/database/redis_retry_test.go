@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connReset simulates a dropped connection, the kind of blip worth retrying
+var connReset = &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}
+
+func newRetryTestClient(t *testing.T) (*RedisClient, redismock.ClientMock) {
+	db, mock := redismock.NewClientMock()
+	return &RedisClient{Client: db, RetryAttempts: 3, RetryBackoff: time.Millisecond}, mock
+}
+
+func TestRedisClient_Retry_SetSucceedsOnSecondAttempt(t *testing.T) {
+	client, mock := newRetryTestClient(t)
+
+	mock.ExpectSet("active_ride:driver:d1", "ride-1", time.Hour).SetErr(connReset)
+	mock.ExpectSet("active_ride:driver:d1", "ride-1", time.Hour).SetVal("OK")
+
+	err := client.Set(context.Background(), "active_ride:driver:d1", "ride-1", time.Hour)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisClient_Retry_GetSucceedsOnSecondAttempt(t *testing.T) {
+	client, mock := newRetryTestClient(t)
+
+	mock.ExpectGet("active_ride:driver:d1").SetErr(connReset)
+	mock.ExpectGet("active_ride:driver:d1").SetVal("ride-1")
+
+	val, err := client.Get(context.Background(), "active_ride:driver:d1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ride-1", val)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisClient_Retry_GeoRadiusSucceedsOnSecondAttempt(t *testing.T) {
+	client, mock := newRetryTestClient(t)
+
+	query := &redis.GeoRadiusQuery{Radius: 5, Unit: "km", WithCoord: true, WithDist: true, Sort: "ASC"}
+	mock.ExpectGeoRadius("driver:geo", 106.8, -6.2, query).SetErr(replyError("LOADING Redis is loading the dataset in memory"))
+	mock.ExpectGeoRadius("driver:geo", 106.8, -6.2, query).SetVal([]redis.GeoLocation{{Name: "d1"}})
+
+	locations, err := client.GeoRadius(context.Background(), "driver:geo", 106.8, -6.2, 5, "km")
+
+	require.NoError(t, err)
+	assert.Len(t, locations, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisClient_Retry_GivesUpAfterMaxAttempts(t *testing.T) {
+	client, mock := newRetryTestClient(t)
+
+	for i := 0; i < 3; i++ {
+		mock.ExpectDel("active_ride:driver:d1").SetErr(connReset)
+	}
+
+	err := client.Delete(context.Background(), "active_ride:driver:d1")
+
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisClient_Retry_NilIsNotRetried(t *testing.T) {
+	client, mock := newRetryTestClient(t)
+
+	// A single expectation: a second attempt would fail the mock
+	mock.ExpectGet("active_ride:driver:d1").RedisNil()
+
+	_, err := client.Get(context.Background(), "active_ride:driver:d1")
+
+	assert.Equal(t, redis.Nil, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisClient_Retry_StopsWhenContextCancelled(t *testing.T) {
+	client, mock := newRetryTestClient(t)
+	client.RetryBackoff = time.Hour
+
+	mock.ExpectSet("k", "v", time.Minute).SetErr(connReset)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	err := client.Set(ctx, "k", "v", time.Minute)
+
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsRetryableRedisError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"miss", redis.Nil, false},
+		{"closed client", redis.ErrClosed, false},
+		{"cancelled", context.Canceled, false},
+		{"deadline", context.DeadlineExceeded, false},
+		{"plain error", errors.New("redis error"), false},
+		{"command error reply", replyError("WRONGTYPE Operation against a key holding the wrong kind of value"), false},
+		{"connection reset", connReset, true},
+		{"connection refused", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"eof", io.EOF, true},
+		{"loading reply", replyError("LOADING Redis is loading the dataset in memory"), true},
+		{"readonly reply", replyError("READONLY You can't write against a read only replica."), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isRetryableRedisError(tt.err))
+		})
+	}
+}
+
+// replyError mimics an error reply from the Redis server
+type replyError string
+
+func (e replyError) Error() string { return string(e) }
+
+func (replyError) RedisError() {}
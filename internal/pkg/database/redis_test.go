@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
 	"github.com/go-redis/redis/v8"
 	"github.com/go-redis/redismock/v8"
 	"github.com/piresc/nebengjek/internal/pkg/models"
@@ -35,6 +36,21 @@ func TestNewRedisClient(t *testing.T) {
 	assert.NotNil(t, client.GetClient())
 }
 
+func TestNewRedisClient_DisablesDriverRetries(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	config := models.RedisConfig{Host: mr.Host(), Port: mr.Server().Addr().Port, RetryAttempts: 3}
+	client, err := NewRedisClient(config)
+	require.NoError(t, err)
+	defer client.Client.Close()
+
+	// Retries happen once, in withRetry, rather than again inside every attempt.
+	// go-redis stores the -1 that disables its retries as 0; left unset it would default to 3.
+	assert.Equal(t, 0, client.Client.Options().MaxRetries)
+}
+
 func TestNewRedisClient_ConnectionError(t *testing.T) {
 	// Test with invalid configuration
 	config := models.RedisConfig{
@@ -572,4 +588,95 @@ func BenchmarkRedisClient_Get(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		_, _ = client.Get(ctx, key)
 	}
-}
\ No newline at end of file
+}
+func TestRedisClient_KeyPrefixIsolation(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	shared := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	prod := &RedisClient{Client: shared, Prefix: "prod:"}
+	staging := &RedisClient{Client: shared, Prefix: "staging:"}
+
+	ctx := context.Background()
+
+	// Same logical key, different namespaces
+	require.NoError(t, prod.Set(ctx, "active_ride:driver:d1", "ride-prod", time.Minute))
+	require.NoError(t, staging.Set(ctx, "active_ride:driver:d1", "ride-staging", time.Minute))
+
+	val, err := prod.Get(ctx, "active_ride:driver:d1")
+	require.NoError(t, err)
+	assert.Equal(t, "ride-prod", val)
+
+	val, err = staging.Get(ctx, "active_ride:driver:d1")
+	require.NoError(t, err)
+	assert.Equal(t, "ride-staging", val)
+
+	assert.True(t, mr.Exists("prod:active_ride:driver:d1"))
+	assert.True(t, mr.Exists("staging:active_ride:driver:d1"))
+	assert.False(t, mr.Exists("active_ride:driver:d1"))
+
+	// Geo pools do not leak across namespaces
+	require.NoError(t, prod.GeoAdd(ctx, "drivers:geo", 106.827153, -6.175392, "d1"))
+	require.NoError(t, prod.SAdd(ctx, "drivers:available", "d1"))
+
+	locations, err := staging.GeoRadius(ctx, "drivers:geo", 106.827153, -6.175392, 5, "km")
+	require.NoError(t, err)
+	assert.Empty(t, locations)
+
+	isMember, err := staging.SIsMember(ctx, "drivers:available", "d1")
+	require.NoError(t, err)
+	assert.False(t, isMember)
+
+	locations, err = prod.GeoRadius(ctx, "drivers:geo", 106.827153, -6.175392, 5, "km")
+	require.NoError(t, err)
+	assert.Len(t, locations, 1)
+
+	// Deleting in one namespace leaves the other untouched
+	require.NoError(t, staging.Delete(ctx, "active_ride:driver:d1"))
+	_, err = staging.Get(ctx, "active_ride:driver:d1")
+	assert.Equal(t, redis.Nil, err)
+
+	val, err = prod.Get(ctx, "active_ride:driver:d1")
+	require.NoError(t, err)
+	assert.Equal(t, "ride-prod", val)
+}
+
+func TestRedisClient_NoPrefixUsesRawKeys(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	client := &RedisClient{Client: db}
+
+	mock.ExpectGet("test:key").SetVal("value")
+
+	val, err := client.Get(context.Background(), "test:key")
+	assert.NoError(t, err)
+	assert.Equal(t, "value", val)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedisClient_SMIsMemberAndExistsEach(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := &RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()}), Prefix: "prod:"}
+	ctx := context.Background()
+
+	require.NoError(t, client.SAdd(ctx, "drivers:available", "d1", "d3"))
+	isMember, err := client.SMIsMember(ctx, "drivers:available", "d1", "d2", "d3")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false, true}, isMember)
+
+	require.NoError(t, client.Set(ctx, "driver:presence:d1", 1, time.Minute))
+	exists, err := client.ExistsEach(ctx, "driver:presence:d1", "driver:presence:d2")
+	require.NoError(t, err)
+	assert.Equal(t, []bool{true, false}, exists)
+
+	// Nothing to check costs no round trip
+	isMember, err = client.SMIsMember(ctx, "drivers:available")
+	require.NoError(t, err)
+	assert.Empty(t, isMember)
+	exists, err = client.ExistsEach(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, exists)
+}
@@ -0,0 +1,145 @@
+package featureflag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+)
+
+// Flags read by the services
+const (
+	// AutoStart lets the rides service start rides from driver location updates
+	AutoStart = "auto_start"
+)
+
+// DefaultCacheTTL is how long a flag value is served from the local cache when no TTL is given
+const DefaultCacheTTL = 30 * time.Second
+
+// Store persists flag values; *database.RedisClient satisfies it
+type Store interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error
+}
+
+// cachedFlag is a flag value together with when it was read from the store
+type cachedFlag struct {
+	enabled   bool
+	set       bool // Whether the flag was set at all, rather than defaulting to off
+	fetchedAt time.Time
+}
+
+// Flags reads a service's feature flags from Redis, caching each value locally for the TTL so
+// hot paths don't hit Redis on every check. A nil *Flags reports every flag as disabled.
+type Flags struct {
+	store   Store
+	service string
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu    sync.Mutex
+	cache map[string]cachedFlag
+}
+
+// New creates the feature flags of a service; a ttl of zero or less uses DefaultCacheTTL
+func New(store Store, service string, ttl time.Duration) *Flags {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &Flags{
+		store:   store,
+		service: service,
+		ttl:     ttl,
+		now:     time.Now,
+		cache:   make(map[string]cachedFlag),
+	}
+}
+
+func (f *Flags) key(flag string) string {
+	return fmt.Sprintf(constants.KeyFeatureFlag, f.service, flag)
+}
+
+// IsEnabled reports whether flag is switched on. Unset flags are off. If Redis can't be read the
+// last known value is kept, so an outage doesn't flip behaviour.
+func (f *Flags) IsEnabled(ctx context.Context, flag string) bool {
+	return f.lookup(ctx, flag).enabled
+}
+
+// EnabledOr reports whether flag is switched on, or fallback while the flag is unset. Features
+// that also have a config setting use it so the flag overrides the config in both directions.
+func (f *Flags) EnabledOr(ctx context.Context, flag string, fallback bool) bool {
+	value := f.lookup(ctx, flag)
+	if !value.set {
+		return fallback
+	}
+	return value.enabled
+}
+
+// lookup returns the flag's value, served from the cache until it is older than the TTL
+func (f *Flags) lookup(ctx context.Context, flag string) cachedFlag {
+	if f == nil {
+		return cachedFlag{}
+	}
+
+	now := f.now()
+	f.mu.Lock()
+	cached, ok := f.cache[flag]
+	f.mu.Unlock()
+	if ok && now.Sub(cached.fetchedAt) < f.ttl {
+		return cached
+	}
+
+	value, err := f.read(ctx, flag)
+	if err != nil {
+		logger.Error("Failed to read feature flag",
+			logger.String("service", f.service),
+			logger.String("flag", flag),
+			logger.ErrorField(err))
+		value = cached
+	}
+	value.fetchedAt = now
+
+	f.mu.Lock()
+	f.cache[flag] = value
+	f.mu.Unlock()
+	return value
+}
+
+// read fetches a flag from the store, treating a missing key as unset and disabled
+func (f *Flags) read(ctx context.Context, flag string) (cachedFlag, error) {
+	value, err := f.store.Get(ctx, f.key(flag))
+	if errors.Is(err, redis.Nil) {
+		return cachedFlag{}, nil
+	}
+	if err != nil {
+		return cachedFlag{}, err
+	}
+	return cachedFlag{enabled: value == "1", set: true}, nil
+}
+
+// Set switches flag on or off. This instance sees the change at once; other instances pick it
+// up once their cached value expires.
+func (f *Flags) Set(ctx context.Context, flag string, enabled bool) error {
+	value := "0"
+	if enabled {
+		value = "1"
+	}
+	if err := f.store.Set(ctx, f.key(flag), value, 0); err != nil {
+		return fmt.Errorf("failed to set feature flag %s: %w", flag, err)
+	}
+
+	f.mu.Lock()
+	f.cache[flag] = cachedFlag{enabled: enabled, set: true, fetchedAt: f.now()}
+	f.mu.Unlock()
+
+	logger.Warn("Feature flag changed",
+		logger.String("service", f.service),
+		logger.String("flag", flag),
+		logger.Bool("enabled", enabled))
+	return nil
+}
@@ -0,0 +1,178 @@
+package featureflag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore keeps flags in memory, counting reads and optionally failing them
+type memoryStore struct {
+	values map[string]string
+	reads  int
+	err    error
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{values: make(map[string]string)}
+}
+
+func (s *memoryStore) Get(ctx context.Context, key string) (string, error) {
+	s.reads++
+	if s.err != nil {
+		return "", s.err
+	}
+	value, ok := s.values[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return value, nil
+}
+
+func (s *memoryStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.values[key] = value.(string)
+	return nil
+}
+
+// newTestFlags returns flags whose clock only moves when advance is called
+func newTestFlags(store Store) (*Flags, func(time.Duration)) {
+	flags := New(store, "rides", time.Minute)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	flags.now = func() time.Time { return now }
+	return flags, func(d time.Duration) { now = now.Add(d) }
+}
+
+func TestIsEnabled_UnsetFlagIsOff(t *testing.T) {
+	flags, _ := newTestFlags(newMemoryStore())
+	assert.False(t, flags.IsEnabled(context.Background(), AutoStart))
+
+	var nilFlags *Flags
+	assert.False(t, nilFlags.IsEnabled(context.Background(), AutoStart))
+}
+
+func TestEnabledOr_UnsetFallsBackAndSetOverrides(t *testing.T) {
+	store := newMemoryStore()
+	flags, advance := newTestFlags(store)
+	ctx := context.Background()
+
+	assert.True(t, flags.EnabledOr(ctx, AutoStart, true))
+	assert.False(t, flags.EnabledOr(ctx, AutoStart, false))
+
+	// Once set, the flag wins over the fallback in both directions
+	store.values["featureflag:rides:auto_start"] = "0"
+	advance(2 * time.Minute)
+	assert.False(t, flags.EnabledOr(ctx, AutoStart, true))
+
+	require.NoError(t, flags.Set(ctx, AutoStart, true))
+	assert.True(t, flags.EnabledOr(ctx, AutoStart, false))
+
+	var nilFlags *Flags
+	assert.True(t, nilFlags.EnabledOr(ctx, AutoStart, true))
+}
+
+func TestSet_AppliesLocallyAndIsNamespacedByService(t *testing.T) {
+	store := newMemoryStore()
+	flags, _ := newTestFlags(store)
+
+	require.NoError(t, flags.Set(context.Background(), AutoStart, true))
+	assert.True(t, flags.IsEnabled(context.Background(), AutoStart))
+	assert.Equal(t, "1", store.values["featureflag:rides:auto_start"])
+
+	// Another service's flag of the same name is separate
+	match := New(store, "match", time.Minute)
+	assert.False(t, match.IsEnabled(context.Background(), AutoStart))
+}
+
+func TestIsEnabled_RefreshesAfterCacheTTL(t *testing.T) {
+	store := newMemoryStore()
+	flags, advance := newTestFlags(store)
+
+	assert.False(t, flags.IsEnabled(context.Background(), AutoStart))
+	assert.Equal(t, 1, store.reads)
+
+	// Another instance switches the flag on; the cached value is served until it expires
+	store.values["featureflag:rides:auto_start"] = "1"
+	advance(30 * time.Second)
+	assert.False(t, flags.IsEnabled(context.Background(), AutoStart))
+	assert.Equal(t, 1, store.reads)
+
+	advance(31 * time.Second)
+	assert.True(t, flags.IsEnabled(context.Background(), AutoStart))
+	assert.Equal(t, 2, store.reads)
+}
+
+func TestIsEnabled_KeepsLastValueWhenRedisFails(t *testing.T) {
+	store := newMemoryStore()
+	flags, advance := newTestFlags(store)
+	require.NoError(t, flags.Set(context.Background(), AutoStart, true))
+
+	store.err = errors.New("connection refused")
+	advance(2 * time.Minute)
+	assert.True(t, flags.IsEnabled(context.Background(), AutoStart))
+
+	// The failed read is cached too, so Redis isn't retried on every check
+	reads := store.reads
+	assert.True(t, flags.IsEnabled(context.Background(), AutoStart))
+	assert.Equal(t, reads, store.reads)
+}
+
+func TestHandler_SetThenGet(t *testing.T) {
+	flags, _ := newTestFlags(newMemoryStore())
+	h := NewHandler(flags)
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/feature-flags/auto_start", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("flag")
+	c.SetParamValues(AutoStart)
+
+	require.NoError(t, h.Set(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/feature-flags/auto_start", nil)
+	rec = httptest.NewRecorder()
+	c = e.NewContext(req, rec)
+	c.SetParamNames("flag")
+	c.SetParamValues(AutoStart)
+
+	require.NoError(t, h.Get(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var resp struct {
+		Data FeatureFlag `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, FeatureFlag{Name: AutoStart, Enabled: true}, resp.Data)
+}
+
+func TestHandler_SetFailure(t *testing.T) {
+	store := newMemoryStore()
+	store.err = errors.New("connection refused")
+	h := NewHandler(New(store, "rides", time.Minute))
+	e := echo.New()
+
+	req := httptest.NewRequest(http.MethodPut, "/admin/feature-flags/auto_start", strings.NewReader(`{"enabled":true}`))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("flag")
+	c.SetParamValues(AutoStart)
+
+	require.NoError(t, h.Set(c))
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+}
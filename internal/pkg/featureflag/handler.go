@@ -0,0 +1,57 @@
+package featureflag
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+// FeatureFlag reports or sets whether a feature flag is switched on
+type FeatureFlag struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Handler exposes a service's feature flags on admin endpoints
+type Handler struct {
+	flags *Flags
+}
+
+// NewHandler creates the admin handler for flags
+func NewHandler(flags *Flags) *Handler {
+	return &Handler{flags: flags}
+}
+
+// Get reports whether the flag named in the path is switched on
+func (h *Handler) Get(c echo.Context) error {
+	name := c.Param("flag")
+	if name == "" {
+		return utils.BadRequestResponse(c, "Flag name is required")
+	}
+
+	enabled := h.flags.IsEnabled(c.Request().Context(), name)
+	return utils.SuccessResponse(c, http.StatusOK, "Feature flag retrieved successfully", FeatureFlag{Name: name, Enabled: enabled})
+}
+
+// Set switches the flag named in the path on or off
+func (h *Handler) Set(c echo.Context) error {
+	name := c.Param("flag")
+	if name == "" {
+		return utils.BadRequestResponse(c, "Flag name is required")
+	}
+
+	var req FeatureFlag
+	if err := c.Bind(&req); err != nil {
+		return utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+	}
+	req.Name = name
+
+	if err := h.flags.Set(c.Request().Context(), name, req.Enabled); err != nil {
+		logger.Error("Failed to set feature flag", logger.String("flag", name), logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to set feature flag")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Feature flag updated successfully", req)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log/slog"
 	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/labstack/echo/v4"
@@ -26,12 +27,13 @@ func NewPostgresHealthChecker(client *database.PostgresClient) *PostgresHealthCh
 	return &PostgresHealthChecker{client: client}
 }
 
-// CheckHealth checks if PostgreSQL is healthy
+// CheckHealth checks if PostgreSQL is healthy by running a trivial query
 func (p *PostgresHealthChecker) CheckHealth(ctx context.Context) error {
 	if p.client == nil {
 		return nil // Skip if no PostgreSQL client
 	}
-	return p.client.GetDB().PingContext(ctx)
+	_, err := p.client.GetDB().ExecContext(ctx, "SELECT 1")
+	return err
 }
 
 // RedisHealthChecker checks Redis connection health
@@ -89,20 +91,53 @@ func (n *NATSHealthChecker) CheckHealth(ctx context.Context) error {
 	return nil
 }
 
+// DefaultDegradedThreshold is the check latency above which a responding dependency is reported as degraded
+const DefaultDegradedThreshold = 500 * time.Millisecond
+
+// Health statuses reported for the service and each dependency
+const (
+	StatusHealthy   = "healthy"
+	StatusDegraded  = "degraded"
+	StatusUnhealthy = "unhealthy"
+)
+
 // HealthService manages health checks for multiple dependencies
 type HealthService struct {
-	checkers map[string]HealthChecker
-	logger   *slog.Logger
+	checkers          map[string]HealthChecker
+	logger            *slog.Logger
+	degradedThreshold time.Duration
+
+	// ready is set once the service has finished starting up, e.g. its consumers are subscribed
+	ready atomic.Bool
 }
 
 // NewHealthService creates a new health service
 func NewHealthService(slogLogger *slog.Logger) *HealthService {
 	return &HealthService{
-		checkers: make(map[string]HealthChecker),
-		logger:   slogLogger,
+		checkers:          make(map[string]HealthChecker),
+		logger:            slogLogger,
+		degradedThreshold: DefaultDegradedThreshold,
 	}
 }
 
+// SetDegradedThreshold overrides the latency above which a dependency is reported as degraded
+func (h *HealthService) SetDegradedThreshold(threshold time.Duration) {
+	if threshold > 0 {
+		h.degradedThreshold = threshold
+	}
+}
+
+// MarkReady records that the service has finished initializing. Until then the readiness probe
+// reports the service as starting, whatever the state of its dependencies.
+func (h *HealthService) MarkReady() {
+	h.ready.Store(true)
+}
+
+// IsReady reports whether MarkReady has been called
+func (h *HealthService) IsReady() bool {
+	return h.ready.Load()
+}
+
 // AddChecker registers a health checker for a dependency
 func (h *HealthService) AddChecker(name string, checker HealthChecker) {
 	h.checkers[name] = checker
@@ -119,36 +154,56 @@ type HealthResponse struct {
 
 // DependencyInfo represents health info for a dependency
 type DependencyInfo struct {
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
 }
 
 // CheckAllHealth performs health checks on all registered dependencies
 func (h *HealthService) CheckAllHealth(ctx context.Context) HealthResponse {
 	response := HealthResponse{
-		Status:       "healthy",
+		Status:       StatusHealthy,
 		Timestamp:    time.Now(),
 		Dependencies: make(map[string]DependencyInfo),
 	}
 
 	for name, checker := range h.checkers {
-		if err := checker.CheckHealth(ctx); err != nil {
+		start := time.Now()
+		err := checker.CheckHealth(ctx)
+		latency := time.Since(start)
+
+		info := DependencyInfo{
+			Status:    StatusHealthy,
+			LatencyMs: float64(latency.Microseconds()) / 1000,
+		}
+
+		switch {
+		case err != nil:
 			if h.logger != nil {
 				h.logger.Error("Health check failed",
 					slog.String("dependency", name),
 					slog.Any("error", err))
 			}
 
-			response.Dependencies[name] = DependencyInfo{
-				Status: "unhealthy",
-				Error:  err.Error(),
+			info.Status = StatusUnhealthy
+			info.Error = err.Error()
+			response.Status = StatusUnhealthy
+		case latency > h.degradedThreshold:
+			if h.logger != nil {
+				h.logger.Warn("Health check slow",
+					slog.String("dependency", name),
+					slog.Duration("latency", latency),
+					slog.Duration("threshold", h.degradedThreshold))
 			}
-			response.Status = "unhealthy"
-		} else {
-			response.Dependencies[name] = DependencyInfo{
-				Status: "healthy",
+
+			info.Status = StatusDegraded
+			// A degraded dependency still serves traffic, so it never masks an unhealthy one
+			if response.Status == StatusHealthy {
+				response.Status = StatusDegraded
 			}
 		}
+
+		response.Dependencies[name] = info
 	}
 
 	return response
@@ -179,22 +234,30 @@ func RegisterEnhancedHealthEndpoints(e *echo.Echo, serviceName, version string,
 		response.Version = version
 
 		statusCode := http.StatusOK
-		if response.Status == "unhealthy" {
+		if response.Status == StatusUnhealthy {
 			statusCode = http.StatusServiceUnavailable
 		}
 
 		return c.JSON(statusCode, response)
 	})
 
-	// Readiness probe (for Kubernetes)
-	healthGroup.GET("/ready", func(c echo.Context) error {
+	// Readiness probe (for Kubernetes), also served at /ready. Unlike /health it fails until
+	// initialization completes and while any dependency check fails.
+	ready := func(c echo.Context) error {
+		if !healthService.IsReady() {
+			return c.JSON(http.StatusServiceUnavailable, map[string]interface{}{
+				"status":  "starting",
+				"service": serviceName,
+			})
+		}
+
 		ctx, cancel := context.WithTimeout(c.Request().Context(), 3*time.Second)
 		defer cancel()
 
 		response := healthService.CheckAllHealth(ctx)
 		response.Service = serviceName
 
-		if response.Status == "unhealthy" {
+		if response.Status == StatusUnhealthy {
 			return c.JSON(http.StatusServiceUnavailable, response)
 		}
 
@@ -202,7 +265,9 @@ func RegisterEnhancedHealthEndpoints(e *echo.Echo, serviceName, version string,
 			"status":  "ready",
 			"service": serviceName,
 		})
-	})
+	}
+	healthGroup.GET("/ready", ready)
+	e.GET("/ready", ready)
 
 	// Liveness probe (for Kubernetes)
 	healthGroup.GET("/live", func(c echo.Context) error {
@@ -0,0 +1,142 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockChecker is a health checker with a configurable delay and result
+type mockChecker struct {
+	delay time.Duration
+	err   error
+}
+
+func (m *mockChecker) CheckHealth(ctx context.Context) error {
+	time.Sleep(m.delay)
+	return m.err
+}
+
+func TestCheckAllHealth_ReportsLatency(t *testing.T) {
+	service := NewHealthService(nil)
+	service.AddChecker("redis", &mockChecker{delay: 5 * time.Millisecond})
+
+	response := service.CheckAllHealth(context.Background())
+
+	assert.Equal(t, StatusHealthy, response.Status)
+	assert.Equal(t, StatusHealthy, response.Dependencies["redis"].Status)
+	assert.GreaterOrEqual(t, response.Dependencies["redis"].LatencyMs, 5.0)
+}
+
+func TestCheckAllHealth_SlowCheckerDegraded(t *testing.T) {
+	service := NewHealthService(nil)
+	service.SetDegradedThreshold(10 * time.Millisecond)
+	service.AddChecker("postgres", &mockChecker{delay: 30 * time.Millisecond})
+	service.AddChecker("redis", &mockChecker{})
+
+	response := service.CheckAllHealth(context.Background())
+
+	assert.Equal(t, StatusDegraded, response.Status)
+	assert.Equal(t, StatusDegraded, response.Dependencies["postgres"].Status)
+	assert.Empty(t, response.Dependencies["postgres"].Error)
+	assert.GreaterOrEqual(t, response.Dependencies["postgres"].LatencyMs, 30.0)
+	assert.Equal(t, StatusHealthy, response.Dependencies["redis"].Status)
+}
+
+func TestCheckAllHealth_UnhealthyOutranksDegraded(t *testing.T) {
+	service := NewHealthService(nil)
+	service.SetDegradedThreshold(10 * time.Millisecond)
+	service.AddChecker("postgres", &mockChecker{delay: 30 * time.Millisecond})
+	service.AddChecker("nats", &mockChecker{err: errors.New("NATS not connected")})
+
+	response := service.CheckAllHealth(context.Background())
+
+	assert.Equal(t, StatusUnhealthy, response.Status)
+	assert.Equal(t, StatusDegraded, response.Dependencies["postgres"].Status)
+	assert.Equal(t, StatusUnhealthy, response.Dependencies["nats"].Status)
+	assert.Equal(t, "NATS not connected", response.Dependencies["nats"].Error)
+}
+
+func TestSetDegradedThreshold_IgnoresNonPositive(t *testing.T) {
+	service := NewHealthService(nil)
+	service.SetDegradedThreshold(0)
+
+	assert.Equal(t, DefaultDegradedThreshold, service.degradedThreshold)
+}
+
+func TestDetailedHealthEndpoint_DegradedStillOK(t *testing.T) {
+	service := NewHealthService(nil)
+	service.SetDegradedThreshold(time.Millisecond)
+	service.AddChecker("redis", &mockChecker{delay: 10 * time.Millisecond})
+
+	e := echo.New()
+	RegisterEnhancedHealthEndpoints(e, "test-service", "1.0.0", service)
+
+	req := httptest.NewRequest(http.MethodGet, "/health/detailed", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, StatusDegraded, response.Status)
+	assert.Equal(t, StatusDegraded, response.Dependencies["redis"].Status)
+	assert.Greater(t, response.Dependencies["redis"].LatencyMs, 0.0)
+}
+
+func TestReadyEndpoint_NotReadyUntilInitialized(t *testing.T) {
+	service := NewHealthService(nil)
+	service.AddChecker("redis", &mockChecker{})
+
+	e := echo.New()
+	RegisterEnhancedHealthEndpoints(e, "test-service", "1.0.0", service)
+
+	for _, path := range []string{"/ready", "/health/ready"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code, path)
+		assert.Contains(t, rec.Body.String(), `"starting"`, path)
+	}
+
+	// Liveness doesn't wait for initialization
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	service.MarkReady()
+	assert.True(t, service.IsReady())
+
+	for _, path := range []string{"/ready", "/health/ready"} {
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		assert.Equal(t, http.StatusOK, rec.Code, path)
+		assert.Contains(t, rec.Body.String(), `"ready"`, path)
+	}
+}
+
+func TestReadyEndpoint_FailingDependencyNotReady(t *testing.T) {
+	service := NewHealthService(nil)
+	service.AddChecker("postgres", &mockChecker{err: errors.New("connection refused")})
+	service.MarkReady()
+
+	e := echo.New()
+	RegisterEnhancedHealthEndpoints(e, "test-service", "1.0.0", service)
+
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+
+	var response HealthResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, StatusUnhealthy, response.Dependencies["postgres"].Status)
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
@@ -8,6 +10,9 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
+// ErrUnknownKeyID is returned when a token names a key ID that is not in the configured keyset
+var ErrUnknownKeyID = errors.New("unknown signing key id")
+
 // Claims represents standard JWT claims plus custom fields
 type Claims struct {
 	UserID uuid.UUID `json:"user_id"`
@@ -33,6 +38,9 @@ func GenerateToken(userID uuid.UUID, msisdn, role string, cfg *models.Config) (s
 
 	// Create token
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	if cfg.JWT.KeyID != "" {
+		token.Header["kid"] = cfg.JWT.KeyID
+	}
 
 	// Sign token with configured secret
 	tokenString, err := token.SignedString([]byte(cfg.JWT.Secret))
@@ -60,3 +68,55 @@ func ValidateToken(tokenString string, secret string) (*jwt.MapClaims, error) {
 
 	return nil, err
 }
+
+// signingKey pairs a secret with the key ID it is published under
+type signingKey struct {
+	id     string
+	secret string
+}
+
+// verificationKeys returns the secrets accepted for validation, primary first
+func verificationKeys(cfg models.JWTConfig) []signingKey {
+	keys := []signingKey{{id: cfg.KeyID, secret: cfg.Secret}}
+	if cfg.PreviousSecret != "" {
+		keys = append(keys, signingKey{id: cfg.PreviousKeyID, secret: cfg.PreviousSecret})
+	}
+	return keys
+}
+
+// ParseToken validates a JWT token against the configured keyset so tokens signed with the
+// previous secret keep working during a rotation. Tokens with a kid header are checked only
+// against that key; tokens without one are tried against each secret in turn.
+func ParseToken(tokenString string, cfg models.JWTConfig) (*jwt.Token, error) {
+	keys := verificationKeys(cfg)
+
+	var lastErr error
+	for _, candidate := range keys {
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			kid, ok := token.Header["kid"].(string)
+			if !ok || kid == "" {
+				return []byte(candidate.secret), nil
+			}
+			for _, key := range keys {
+				if key.id != "" && key.id == kid {
+					return []byte(key.secret), nil
+				}
+			}
+			return nil, ErrUnknownKeyID
+		})
+		if err == nil && token.Valid {
+			return token, nil
+		}
+		lastErr = err
+
+		// Only a signature mismatch on a token without a kid is worth retrying with the next secret
+		if token == nil || token.Header["kid"] != nil || !errors.Is(err, jwt.ErrSignatureInvalid) {
+			break
+		}
+	}
+
+	return nil, lastErr
+}
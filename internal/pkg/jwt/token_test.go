@@ -358,6 +358,117 @@ func TestGenerateToken_DifferentConfigurations(t *testing.T) {
 	}
 }
 
+func rotationConfig() models.JWTConfig {
+	return models.JWTConfig{
+		Secret:         "new-secret-key-for-jwt-signing",
+		Expiration:     60,
+		Issuer:         "nebengjek-test",
+		KeyID:          "2025-02",
+		PreviousSecret: "test-secret-key-for-jwt-signing",
+		PreviousKeyID:  "2025-01",
+	}
+}
+
+func TestGenerateToken_SetsKeyID(t *testing.T) {
+	cfg := &models.Config{JWT: rotationConfig()}
+
+	tokenString, _, err := GenerateToken(uuid.New(), "+6281234567890", "driver", cfg)
+	require.NoError(t, err)
+
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-02", token.Header["kid"])
+}
+
+func TestParseToken_KeyRotation(t *testing.T) {
+	userID := uuid.New()
+	signWith := func(jwtCfg models.JWTConfig) string {
+		tokenString, _, err := GenerateToken(userID, "+6281234567890", "driver", &models.Config{JWT: jwtCfg})
+		require.NoError(t, err)
+		return tokenString
+	}
+
+	tests := []struct {
+		name        string
+		token       string
+		expectedErr error
+		expectError bool
+	}{
+		{
+			name:  "Token signed with current key",
+			token: signWith(rotationConfig()),
+		},
+		{
+			name: "Token signed with previous key",
+			token: signWith(models.JWTConfig{
+				Secret: "test-secret-key-for-jwt-signing", Expiration: 60, KeyID: "2025-01",
+			}),
+		},
+		{
+			name: "Legacy token without kid signed with previous secret",
+			token: signWith(models.JWTConfig{
+				Secret: "test-secret-key-for-jwt-signing", Expiration: 60,
+			}),
+		},
+		{
+			name: "Unknown key ID",
+			token: signWith(models.JWTConfig{
+				Secret: "test-secret-key-for-jwt-signing", Expiration: 60, KeyID: "2024-12",
+			}),
+			expectedErr: ErrUnknownKeyID,
+			expectError: true,
+		},
+		{
+			name: "Known key ID with wrong secret",
+			token: signWith(models.JWTConfig{
+				Secret: "some-other-secret", Expiration: 60, KeyID: "2025-01",
+			}),
+			expectedErr: jwt.ErrSignatureInvalid,
+			expectError: true,
+		},
+		{
+			name: "Legacy token signed with unknown secret",
+			token: signWith(models.JWTConfig{
+				Secret: "some-other-secret", Expiration: 60,
+			}),
+			expectedErr: jwt.ErrSignatureInvalid,
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := ParseToken(tt.token, rotationConfig())
+
+			if tt.expectError {
+				assert.Error(t, err)
+				assert.ErrorIs(t, err, tt.expectedErr)
+				assert.Nil(t, token)
+				return
+			}
+
+			require.NoError(t, err)
+			require.True(t, token.Valid)
+			claims, ok := token.Claims.(jwt.MapClaims)
+			require.True(t, ok)
+			assert.Equal(t, userID.String(), claims["user_id"])
+		})
+	}
+}
+
+func TestParseToken_PreviousKeyRetired(t *testing.T) {
+	tokenString, _, err := GenerateToken(uuid.New(), "+6281234567890", "driver", getTestConfig())
+	require.NoError(t, err)
+
+	// Once the previous secret is dropped, old tokens no longer validate
+	cfg := rotationConfig()
+	cfg.PreviousSecret = ""
+	cfg.PreviousKeyID = ""
+
+	_, err = ParseToken(tokenString, cfg)
+	assert.ErrorIs(t, err, jwt.ErrSignatureInvalid)
+}
+
 func BenchmarkGenerateToken(b *testing.B) {
 	config := getTestConfig()
 	userID := uuid.New()
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// KeyMSISDN is the designated field for phone numbers, masked when redaction is enabled
+	KeyMSISDN = "msisdn"
+	// KeyFullName is the designated field for user names, masked when redaction is enabled
+	KeyFullName = "full_name"
+
+	// msisdnVisibleDigits is how many trailing digits of a masked MSISDN stay readable
+	msisdnVisibleDigits = 3
+)
+
+// MSISDN constructs the designated phone number field
+func MSISDN(val string) Field {
+	return slog.String(KeyMSISDN, val)
+}
+
+// FullName constructs the designated user name field
+func FullName(val string) Field {
+	return slog.String(KeyFullName, val)
+}
+
+// MaskMSISDN hides all but the last three digits of a phone number, e.g. +628123456789 becomes **********789
+func MaskMSISDN(msisdn string) string {
+	length := utf8.RuneCountInString(msisdn)
+	if length <= msisdnVisibleDigits {
+		return strings.Repeat("*", length)
+	}
+	runes := []rune(msisdn)
+	return strings.Repeat("*", length-msisdnVisibleDigits) + string(runes[length-msisdnVisibleDigits:])
+}
+
+// MaskName keeps only the first letter of each word of a name, e.g. Budi Santoso becomes B*** S******
+func MaskName(name string) string {
+	words := strings.Fields(name)
+	for i, word := range words {
+		first, size := utf8.DecodeRuneInString(word)
+		words[i] = string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	}
+	return strings.Join(words, " ")
+}
+
+// redactAttr masks the designated PII fields, including those nested in groups
+func redactAttr(attr slog.Attr) slog.Attr {
+	switch {
+	case attr.Value.Kind() == slog.KindGroup:
+		group := attr.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, a := range group {
+			redacted[i] = redactAttr(a)
+		}
+		return slog.Attr{Key: attr.Key, Value: slog.GroupValue(redacted...)}
+	case attr.Key == KeyMSISDN:
+		return slog.String(attr.Key, MaskMSISDN(attr.Value.String()))
+	case attr.Key == KeyFullName:
+		return slog.String(attr.Key, MaskName(attr.Value.String()))
+	}
+	return attr
+}
+
+// RedactingHandler masks designated PII fields before any other handler, including log
+// forwarders, sees them
+type RedactingHandler struct {
+	handler slog.Handler
+}
+
+// NewRedactingHandler wraps handler so the designated PII fields are masked
+func NewRedactingHandler(handler slog.Handler) *RedactingHandler {
+	return &RedactingHandler{handler: handler}
+}
+
+// Handle implements slog.Handler interface
+func (h *RedactingHandler) Handle(ctx context.Context, record slog.Record) error {
+	redacted := slog.NewRecord(record.Time, record.Level, record.Message, record.PC)
+	record.Attrs(func(attr slog.Attr) bool {
+		redacted.AddAttrs(redactAttr(attr))
+		return true
+	})
+	return h.handler.Handle(ctx, redacted)
+}
+
+// WithAttrs implements slog.Handler interface
+func (h *RedactingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, attr := range attrs {
+		redacted[i] = redactAttr(attr)
+	}
+	return &RedactingHandler{handler: h.handler.WithAttrs(redacted)}
+}
+
+// WithGroup implements slog.Handler interface
+func (h *RedactingHandler) WithGroup(name string) slog.Handler {
+	return &RedactingHandler{handler: h.handler.WithGroup(name)}
+}
+
+// Enabled implements slog.Handler interface
+func (h *RedactingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
@@ -0,0 +1,77 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// logLine logs one line with PII fields through a logger built from config and returns it decoded
+func logLine(t *testing.T, redact bool) map[string]interface{} {
+	var buf bytes.Buffer
+	log := NewSlogLogger(SlogConfig{
+		Level:       slog.LevelInfo,
+		ServiceName: "users-service",
+		Format:      "json",
+		RedactPII:   redact,
+		Output:      &buf,
+	})
+
+	log.With(FullName("Budi Santoso")).Info("Generated OTP",
+		MSISDN("+628123456789"),
+		slog.Group("user", MSISDN("+628111222333")),
+		String("user_id", "user-1"))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	return line
+}
+
+func TestRedaction_MasksDesignatedFields(t *testing.T) {
+	line := logLine(t, true)
+
+	assert.Equal(t, "**********789", line[KeyMSISDN])
+	assert.Equal(t, "B*** S******", line[KeyFullName])
+	assert.Equal(t, "**********333", line["user"].(map[string]interface{})[KeyMSISDN])
+
+	// Other fields are untouched
+	assert.Equal(t, "user-1", line["user_id"])
+	assert.Equal(t, "users-service", line["service"])
+	assert.Equal(t, "Generated OTP", line["msg"])
+}
+
+func TestRedaction_DisabledPassesThrough(t *testing.T) {
+	line := logLine(t, false)
+
+	assert.Equal(t, "+628123456789", line[KeyMSISDN])
+	assert.Equal(t, "Budi Santoso", line[KeyFullName])
+	assert.Equal(t, "+628111222333", line["user"].(map[string]interface{})[KeyMSISDN])
+}
+
+func TestMaskMSISDN(t *testing.T) {
+	tests := map[string]string{
+		"+628123456789": "**********789",
+		"08123":         "**123",
+		"123":           "***",
+		"":              "",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, MaskMSISDN(input), input)
+	}
+}
+
+func TestMaskName(t *testing.T) {
+	tests := map[string]string{
+		"Budi Santoso":       "B*** S******",
+		"  Siti   Nurhaliza": "S*** N********",
+		"Ã":                  "Ã",
+		"":                   "",
+	}
+	for input, expected := range tests {
+		assert.Equal(t, expected, MaskName(input), input)
+	}
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"io"
 	"log/slog"
 	"os"
 
@@ -14,7 +15,9 @@ type SlogConfig struct {
 	Level       slog.Level
 	ServiceName string
 	NewRelic    *newrelic.Application
-	Format      string // "json" or "text"
+	Format      string    // "json" or "text"
+	RedactPII   bool      // Mask MSISDN and name fields for strict compliance environments
+	Output      io.Writer // Defaults to stdout
 }
 
 // NewSlogLogger creates a new slog logger with New Relic integration
@@ -27,11 +30,16 @@ func NewSlogLogger(config SlogConfig) *slog.Logger {
 		AddSource: true,
 	}
 
+	output := config.Output
+	if output == nil {
+		output = os.Stdout
+	}
+
 	switch config.Format {
 	case "json":
-		handler = slog.NewJSONHandler(os.Stdout, opts)
+		handler = slog.NewJSONHandler(output, opts)
 	default:
-		handler = slog.NewTextHandler(os.Stdout, opts)
+		handler = slog.NewTextHandler(output, opts)
 	}
 
 	// Wrap with New Relic handler if available
@@ -46,6 +54,11 @@ func NewSlogLogger(config SlogConfig) *slog.Logger {
 		}
 	}
 
+	// Mask PII before it reaches the console or is forwarded
+	if config.RedactPII {
+		handler = NewRedactingHandler(handler)
+	}
+
 	// Add service name to all logs
 	if config.ServiceName != "" {
 		handler = handler.WithAttrs([]slog.Attr{
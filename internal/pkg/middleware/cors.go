@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// CORS returns middleware that applies the configured cross-origin policy.
+// Requests without an Origin header are same-origin or non-browser calls and pass through untouched,
+// as do WebSocket upgrades which browsers do not subject to CORS.
+// An empty allowed origins list disables the middleware entirely.
+func CORS(cfg models.CORSConfig) echo.MiddlewareFunc {
+	allowMethods := strings.Join(cfg.AllowedMethods, ",")
+	allowHeaders := strings.Join(cfg.AllowedHeaders, ",")
+	maxAge := ""
+	if cfg.MaxAgeSeconds > 0 {
+		maxAge = strconv.Itoa(cfg.MaxAgeSeconds)
+	}
+
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		if len(cfg.AllowedOrigins) == 0 {
+			return next
+		}
+
+		return func(c echo.Context) error {
+			req := c.Request()
+			origin := req.Header.Get(echo.HeaderOrigin)
+			if origin == "" || strings.EqualFold(req.Header.Get(echo.HeaderUpgrade), "websocket") {
+				return next(c)
+			}
+
+			res := c.Response()
+			res.Header().Add(echo.HeaderVary, echo.HeaderOrigin)
+
+			allowOrigin, ok := matchOrigin(cfg.AllowedOrigins, origin)
+			if !ok {
+				return echo.NewHTTPError(http.StatusForbidden, "Origin not allowed")
+			}
+			res.Header().Set(echo.HeaderAccessControlAllowOrigin, allowOrigin)
+
+			// Anything other than a preflight is a simple or actual request
+			if req.Method != http.MethodOptions || req.Header.Get(echo.HeaderAccessControlRequestMethod) == "" {
+				return next(c)
+			}
+
+			res.Header().Add(echo.HeaderVary, echo.HeaderAccessControlRequestMethod)
+			res.Header().Add(echo.HeaderVary, echo.HeaderAccessControlRequestHeaders)
+			if allowMethods != "" {
+				res.Header().Set(echo.HeaderAccessControlAllowMethods, allowMethods)
+			}
+			if allowHeaders != "" {
+				res.Header().Set(echo.HeaderAccessControlAllowHeaders, allowHeaders)
+			}
+			if maxAge != "" {
+				res.Header().Set(echo.HeaderAccessControlMaxAge, maxAge)
+			}
+			return c.NoContent(http.StatusNoContent)
+		}
+	}
+}
+
+// matchOrigin reports whether origin is allowed and the value to echo back in Access-Control-Allow-Origin
+func matchOrigin(allowed []string, origin string) (string, bool) {
+	for _, o := range allowed {
+		if o == "*" {
+			return "*", true
+		}
+		if strings.EqualFold(o, origin) {
+			return origin, true
+		}
+	}
+	return "", false
+}
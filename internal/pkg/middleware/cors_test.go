@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newCORSTestServer(cfg models.CORSConfig) *echo.Echo {
+	e := echo.New()
+	e.Use(CORS(cfg))
+	e.GET("/users/:id", func(c echo.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return e
+}
+
+func testCORSConfig() models.CORSConfig {
+	return models.CORSConfig{
+		AllowedOrigins: []string{"https://app.nebengjek.id"},
+		AllowedMethods: []string{"GET", "POST", "OPTIONS"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAgeSeconds:  600,
+	}
+}
+
+func TestCORS_AllowedOriginPassesThrough(t *testing.T) {
+	e := newCORSTestServer(testCORSConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://app.nebengjek.id")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+	assert.Equal(t, "https://app.nebengjek.id", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Contains(t, rec.Header().Values(echo.HeaderVary), echo.HeaderOrigin)
+}
+
+func TestCORS_DisallowedOriginRejected(t *testing.T) {
+	e := newCORSTestServer(testCORSConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://evil.example.com")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func TestCORS_Preflight(t *testing.T) {
+	e := newCORSTestServer(testCORSConfig())
+
+	req := httptest.NewRequest(http.MethodOptions, "/users/123", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://app.nebengjek.id")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodPost)
+	req.Header.Set(echo.HeaderAccessControlRequestHeaders, "Authorization")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.nebengjek.id", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+	assert.Equal(t, "GET,POST,OPTIONS", rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+	assert.Equal(t, "Authorization,Content-Type", rec.Header().Get(echo.HeaderAccessControlAllowHeaders))
+	assert.Equal(t, "600", rec.Header().Get(echo.HeaderAccessControlMaxAge))
+}
+
+func TestCORS_PreflightFromDisallowedOrigin(t *testing.T) {
+	e := newCORSTestServer(testCORSConfig())
+
+	req := httptest.NewRequest(http.MethodOptions, "/users/123", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://evil.example.com")
+	req.Header.Set(echo.HeaderAccessControlRequestMethod, http.MethodGet)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusForbidden, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowMethods))
+}
+
+func TestCORS_NoOriginHeader(t *testing.T) {
+	e := newCORSTestServer(testCORSConfig())
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func TestCORS_WildcardOrigin(t *testing.T) {
+	cfg := testCORSConfig()
+	cfg.AllowedOrigins = []string{"*"}
+	e := newCORSTestServer(cfg)
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://anything.example.com")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "*", rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
+
+func TestCORS_DisabledWithoutOrigins(t *testing.T) {
+	e := newCORSTestServer(models.CORSConfig{})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	req.Header.Set(echo.HeaderOrigin, "https://app.nebengjek.id")
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Empty(t, rec.Header().Get(echo.HeaderAccessControlAllowOrigin))
+}
@@ -13,7 +13,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	pkgcontext "github.com/piresc/nebengjek/internal/pkg/context"
 	"github.com/piresc/nebengjek/internal/pkg/observability"
+	"github.com/piresc/nebengjek/internal/utils"
 )
 
 // Config holds configuration for the middleware
@@ -123,6 +126,8 @@ func (m *Middleware) Handler() echo.MiddlewareFunc {
 			// 2. Add to context for easy access
 			ctx := context.WithValue(c.Request().Context(), "request_id", requestID)
 			ctx = context.WithValue(ctx, "service_name", m.config.ServiceName)
+			// Events published while serving the request carry its ID for cross-service log correlation
+			ctx = pkgcontext.WithCorrelationID(ctx, requestID)
 			c.SetRequest(c.Request().WithContext(ctx))
 
 			// 3. Setup APM transaction (if tracer is enabled)
@@ -132,9 +137,12 @@ func (m *Middleware) Handler() echo.MiddlewareFunc {
 				defer txn.End()
 				txn.SetWebRequest(c.Request())
 
-				// Add transaction context - this ensures New Relic context is available for logging
-				ctx = txn.GetContext()
-				c.SetRequest(c.Request().WithContext(ctx))
+				// Attach the New Relic transaction for logging while keeping the request's own context values.
+				// No-op transactions carry none, so the request context is left as is.
+				if nrTxn := newrelic.FromContext(txn.GetContext()); nrTxn != nil {
+					ctx = newrelic.NewContext(ctx, nrTxn)
+					c.SetRequest(c.Request().WithContext(ctx))
+				}
 
 				// Store transaction in Echo context for easy access
 				c.Set("nr_txn", txn)
@@ -264,13 +272,9 @@ func (m *Middleware) handlePanic(c echo.Context, r interface{}, requestID string
 		txn.NoticeError(fmt.Errorf("panic: %v", r))
 	}
 
-	// Send simple error response
+	// Send the standard error envelope, which carries the request ID for support lookups
 	if !c.Response().Committed {
-		c.JSON(http.StatusInternalServerError, map[string]interface{}{
-			"error":      "Internal Server Error",
-			"message":    "An unexpected error occurred",
-			"request_id": requestID,
-		})
+		utils.InternalServerErrorResponse(c, "An unexpected error occurred")
 	}
 }
 
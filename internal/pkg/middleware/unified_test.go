@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/observability"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_WithoutNewRelic(t *testing.T) {
+	tests := []struct {
+		name   string
+		tracer observability.Tracer
+	}{
+		{name: "no tracer", tracer: nil},
+		{name: "no-op tracer", tracer: observability.NewNoOpTracer()},
+		{name: "tracer without application", tracer: observability.NewNewRelicTracer(nil)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := NewMiddleware(Config{Tracer: tt.tracer, ServiceName: "test-service"})
+
+			e := echo.New()
+			e.Use(m.Handler())
+			e.GET("/ping", func(c echo.Context) error {
+				// The request context keeps its values when no New Relic transaction is attached
+				ctx := c.Request().Context()
+				assert.Equal(t, "req-1", ctx.Value("request_id"))
+				assert.Equal(t, "test-service", ctx.Value("service_name"))
+				return c.String(http.StatusOK, "pong")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/ping", nil)
+			req.Header.Set("X-Request-ID", "req-1")
+			rec := httptest.NewRecorder()
+
+			assert.NotPanics(t, func() { e.ServeHTTP(rec, req) })
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "pong", rec.Body.String())
+		})
+	}
+}
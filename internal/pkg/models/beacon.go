@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // BeaconRequest represents a request to toggle driver's beacon (availability)
@@ -10,6 +12,8 @@ type BeaconRequest struct {
 	IsActive  bool    `json:"is_active"`
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
+	// Set by a driver about to finish a ride who wants their next match before it completes
+	AcceptingNext bool `json:"accepting_next,omitempty"`
 }
 
 // BeaconResponse represents a response to a beacon toggle request
@@ -23,4 +27,40 @@ type BeaconEvent struct {
 	IsActive  bool      `json:"is_active"`
 	Location  Location  `json:"location"`
 	Timestamp time.Time `json:"timestamp"`
+	// The driver is finishing a ride and accepts being matched for the next one
+	AcceptingNext bool `json:"accepting_next,omitempty"`
+	// The driver's gender from their profile, for passengers with a driver gender preference
+	Gender string `json:"gender,omitempty"`
+}
+
+// DriverOnlineSession is a period during which a driver's beacon was active.
+// EndedAt is nil while the driver is still online.
+type DriverOnlineSession struct {
+	SessionID uuid.UUID  `json:"session_id" db:"session_id"`
+	DriverID  uuid.UUID  `json:"driver_id" db:"driver_id"`
+	StartedAt time.Time  `json:"started_at" db:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty" db:"ended_at"`
+}
+
+// DriverOnlineSummary is a driver's total online time on a single day, for shift reporting
+type DriverOnlineSummary struct {
+	DriverID      string `json:"driver_id"`
+	Date          string `json:"date"` // YYYY-MM-DD
+	OnlineSeconds int64  `json:"online_seconds"`
+	Sessions      int    `json:"sessions"` // Sessions overlapping the day, including one still open
+	// Omitted when the match service could not be reached
+	Cancellation *DriverCancellationStats `json:"cancellation,omitempty"`
+}
+
+// UserPresence is where a user currently stands with the platform, for support tooling
+type UserPresence struct {
+	UserID string `json:"user_id"`
+	// Connected reports a live WebSocket on the users service instance that answered
+	Connected     bool   `json:"connected"`
+	ConnectedRole string `json:"connected_role,omitempty"`
+	// In the available driver pool (beacon on) or the passenger pool (finder on)
+	OnlineAsDriver    bool   `json:"online_as_driver"`
+	OnlineAsPassenger bool   `json:"online_as_passenger"`
+	ActiveRideID      string `json:"active_ride_id,omitempty"`
+	ActiveRideRole    string `json:"active_ride_role,omitempty"`
 }
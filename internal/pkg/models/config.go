@@ -1,22 +1,41 @@
 package models
 
+import "math"
+
 // Config represents application configuration
 type Config struct {
-	App      AppConfig
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	NATS     NATSConfig
-	JWT      JWTConfig
-	APIKey   APIKeyConfig
-	Pricing  PricingConfig
-	Payment  PaymentConfig
-	Services ServicesConfig
-	Match    MatchConfig
-	Location LocationConfig
-	Rides    RidesConfig
-	NewRelic NewRelicConfig
-	Logger   LoggerConfig
+	App        AppConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	NATS       NATSConfig
+	JWT        JWTConfig
+	APIKey     APIKeyConfig
+	CORS       CORSConfig
+	Pricing    PricingConfig
+	Payment    PaymentConfig
+	Services   ServicesConfig
+	Users      UsersConfig
+	Match      MatchConfig
+	Location   LocationConfig
+	Rides      RidesConfig
+	Regions    []RegionConfig
+	Surcharges []SurchargeConfig
+	NewRelic   NewRelicConfig
+	Features   FeatureFlagConfig
+	Logger     LoggerConfig
+}
+
+// WithTunables returns a copy of c that takes the settings safe to change while a service runs from
+// reloaded: the search radius, pricing, regions and surcharges. Structural settings such as ports
+// and connection details keep their current values.
+func (c *Config) WithTunables(reloaded *Config) *Config {
+	next := *c
+	next.Match.SearchRadiusKm = reloaded.Match.SearchRadiusKm
+	next.Pricing = reloaded.Pricing
+	next.Regions = reloaded.Regions
+	next.Surcharges = reloaded.Surcharges
+	return &next
 }
 
 // ServicesConfig contains URLs for other microservices
@@ -36,12 +55,16 @@ type AppConfig struct {
 
 // ServerConfig contains HTTP/gRPC server configuration
 type ServerConfig struct {
-	Host            string
-	Port            int
-	GRPCPort        int
-	ReadTimeout     int
-	WriteTimeout    int
-	ShutdownTimeout int
+	Host         string
+	Port         int
+	GRPCPort     int
+	ReadTimeout  int
+	WriteTimeout int
+	// Timeouts in seconds for reading request headers and for keep-alive connections waiting
+	// for their next request
+	ReadHeaderTimeout int
+	IdleTimeout       int
+	ShutdownTimeout   int
 }
 
 // DatabaseConfig contains database connection configuration
@@ -55,27 +78,49 @@ type DatabaseConfig struct {
 	SSLMode   string
 	MaxConns  int
 	IdleConns int
+	// Apply pending db/migrations at startup, tracked in schema_migrations
+	MigrateOnStartup bool
 }
 
 // RedisConfig contains Redis connection configuration
 type RedisConfig struct {
-	Host     string
-	Port     int
-	Password string
-	DB       int
-	PoolSize int
+	Host      string
+	Port      int
+	Password  string
+	DB        int
+	PoolSize  int
+	KeyPrefix string // Namespace applied to every key, e.g. "prod:" or "staging:"
+	// Transient failures of idempotent operations are retried with jittered exponential backoff
+	RetryAttempts  int
+	RetryBackoffMs int
 }
 
 // NATSConfig contains NATS connection configuration
 type NATSConfig struct {
-	URL string
+	URL                    string
+	Streams                map[string]NATSStreamConfig // Keyed by stream name, e.g. USER_STREAM
+	MaxReconnects          int                         // Reconnect attempts before giving up; zero uses the default, negative is unlimited
+	ReconnectWaitMs        int                         // Delay between reconnect attempts
+	ReconnectBufferBytes   int                         // Outgoing data buffered while reconnecting
+	PublishReconnectWaitMs int                         // How long a publish waits for a dropped connection to come back
+	ConsumerMaxConcurrency int                         // Messages handled at once by consumers that don't rely on delivery order
+}
+
+// NATSStreamConfig overrides JetStream stream limits; zero values keep the built-in defaults
+type NATSStreamConfig struct {
+	MaxAgeMinutes int    `json:"max_age_minutes"`
+	MaxBytes      int64  `json:"max_bytes"`
+	Retention     string `json:"retention"` // One of limits, interest or workqueue
 }
 
 // JWTConfig contains JWT authentication configuration
 type JWTConfig struct {
-	Secret     string
-	Expiration int // in minutes
-	Issuer     string
+	Secret         string
+	Expiration     int // in minutes
+	Issuer         string
+	KeyID          string // kid stamped on newly issued tokens
+	PreviousSecret string // still accepted for validation during a secret rotation
+	PreviousKeyID  string
 }
 
 // APIKeyConfig contains API key authentication configuration
@@ -84,13 +129,58 @@ type APIKeyConfig struct {
 	MatchService    string
 	RidesService    string
 	LocationService string
+	Admin           string
+}
+
+// CORSConfig contains cross-origin request settings for browser-facing services
+type CORSConfig struct {
+	AllowedOrigins []string `json:"allowed_origins"` // Empty disables CORS handling, "*" allows any origin
+	AllowedMethods []string `json:"allowed_methods"`
+	AllowedHeaders []string `json:"allowed_headers"`
+	MaxAgeSeconds  int      `json:"max_age_seconds"` // How long browsers may cache preflight results
 }
 
 type PricingConfig struct {
-	RatePerKm       float64 `json:"rate_per_km"`
-	AdminFeePercent float64 `json:"admin_fee_percent"`
+	RatePerKm        float64 `json:"rate_per_km"`
+	AdminFeePercent  float64 `json:"admin_fee_percent"`
+	AdminFeeRounding string  `json:"admin_fee_rounding"` // One of the RoundingMode* values, defaults to truncate
+}
+
+// SplitFare divides cost into the admin fee and driver payout using the configured rounding
+// mode. The payout is derived from the rounded fee so the two always sum to cost.
+func (p PricingConfig) SplitFare(cost int) (int, int) {
+	adminFeePercent := p.AdminFeePercent / 100.0 // Convert percentage to decimal
+	// Drop float noise (e.g. 1100 * 0.05 = 55.00000000000001) before rounding
+	rawFee := math.Round(float64(cost)*adminFeePercent*1e6) / 1e6
+
+	var adminFee int
+	switch p.AdminFeeRounding {
+	case RoundingModeRoundHalfUp:
+		adminFee = int(math.Floor(rawFee + 0.5))
+	case RoundingModeCeil:
+		adminFee = int(math.Ceil(rawFee))
+	default:
+		adminFee = int(rawFee)
+	}
+
+	// Keep the fee within the cost so the payout never goes negative
+	if adminFee < 0 {
+		adminFee = 0
+	}
+	if adminFee > cost {
+		adminFee = cost
+	}
+
+	return adminFee, cost - adminFee
 }
 
+// Rounding modes for the admin fee
+const (
+	RoundingModeTruncate    = "truncate"
+	RoundingModeRoundHalfUp = "round-half-up"
+	RoundingModeCeil        = "ceil"
+)
+
 // PaymentConfig contains payment service configuration
 type PaymentConfig struct {
 	QRCodeBaseURL string `json:"qr_code_base_url"`
@@ -102,16 +192,113 @@ type PaymentConfig struct {
 type MatchConfig struct {
 	SearchRadiusKm     float64 `json:"search_radius_km"`      // Radius in kilometers for matching users
 	ActiveRideTTLHours int     `json:"active_ride_ttl_hours"` // TTL in hours for active ride tracking
+	MaxLocationAgeSecs int     `json:"max_location_age_secs"` // Beacon/finder locations older than this are ignored
+	ProposalDedupSecs  int     `json:"proposal_dedup_secs"`   // Window in seconds during which a driver is not re-proposed to the same passenger
+	SchedulerPollSecs  int     `json:"scheduler_poll_secs"`   // How often scheduled rides are checked for release
+	SchedulerBatchSize int     `json:"scheduler_batch_size"`  // Maximum scheduled rides released per run
+	// Proposals the driver hasn't answered within this time are rejected, and driver apps count down to it
+	ProposalTTLSeconds int `json:"proposal_ttl_seconds"` // How long a driver has to answer a match proposal
+	// Matched users whose pool removal failed on acceptance are retried until they leave the pool
+	PoolRemovalRetrySecs int `json:"pool_removal_retry_secs"` // How often failed pool removals are retried
+	// Rejecting a passenger's other open matches once one is accepted is retried with jittered
+	// exponential backoff, then queued for a background worker that keeps retrying
+	AutoRejectionAttempts  int `json:"auto_rejection_attempts"`   // Attempts before the auto-rejection is queued
+	AutoRejectionBackoffMs int `json:"auto_rejection_backoff_ms"` // Base backoff between attempts, doubled each retry
+	AutoRejectionRetrySecs int `json:"auto_rejection_retry_secs"` // How often queued auto-rejections are retried
+	// Zero keeps first-wins: the first match both sides confirm is accepted. Otherwise driver
+	// acceptances are gathered for this long and the passenger picks among them.
+	AcceptanceWindowSecs int `json:"acceptance_window_secs"` // How long driver acceptances are collected before the passenger chooses
+	// Zero leaves the number of open proposals per passenger unbounded
+	MaxPendingPerPassenger int `json:"max_pending_per_passenger"` // Maximum unanswered matches a passenger may hold at once
+	// Maintenance can also be switched on at runtime through the admin endpoint
+	MaintenanceMode bool `json:"maintenance_mode"` // Stops new matching while active rides carry on
+	// Drivers who cancel too many of their accepted rides are left out of matching for a while
+	CancellationWindowHours   int     `json:"cancellation_window_hours"`    // Rolling window over which the cancellation rate is measured
+	CancellationRateThreshold float64 `json:"cancellation_rate_threshold"`  // Rate above which a driver is suspended, e.g. 0.5
+	CancellationMinAccepted   int     `json:"cancellation_min_accepted"`    // Accepted rides needed in the window before the rate counts
+	CancellationPenaltyMins   int     `json:"cancellation_penalty_minutes"` // How long a suspended driver is left out of matching
+	// Fairness mode proposes comparably close drivers in order of how long they have waited for a ride
+	FairnessMode         bool    `json:"fairness_mode"`          // Prefer the driver whose last ride completed longest ago
+	FairnessBandKm       float64 `json:"fairness_band_km"`       // Width of the distance bands within which drivers count as comparably close
+	FairnessMaxProposals int     `json:"fairness_max_proposals"` // Drivers proposed per search in fairness mode, so the rest don't compete for the ride
+	// Drivers finishing a ride can opt in through their beacon to be matched for the next one early
+	BackToBackRides        bool    `json:"back_to_back_rides"`          // Let drivers about to finish a ride receive their next match
+	BackToBackMaxDropoffKm float64 `json:"back_to_back_max_dropoff_km"` // How close to the drop-off a driver must be to opt in
+	// Passengers are re-sent their pending proposals, re-ranked, as the drivers' beacons move; zero turns this off
+	ProposalRefreshSecs int     `json:"proposal_refresh_secs"` // Minimum time between re-sent proposal lists for one passenger
+	AverageSpeedKmh     float64 `json:"average_speed_kmh"`     // Assumed driver speed for the pickup ETAs of re-sent proposals
+}
+
+// RegionConfig overrides matching and pricing for pickups within an area; zero values use the global settings
+type RegionConfig struct {
+	Name           string  `json:"name"`
+	GeohashPrefix  string  `json:"geohash_prefix"`   // Pickups whose geohash starts with this prefix belong to the region
+	SearchRadiusKm float64 `json:"search_radius_km"` // Radius in kilometers for matching users
+	RatePerKm      float64 `json:"rate_per_km"`      // Fare charged per kilometer travelled
+}
+
+// SurchargeConfig is an approved flat surcharge a driver may add to an ongoing ride
+type SurchargeConfig struct {
+	Name   string `json:"name"`
+	Amount int    `json:"amount"` // Flat amount added to the fare
+}
+
+// UsersConfig contains users service specific configuration
+type UsersConfig struct {
+	MaxFavoriteLocations int  `json:"max_favorite_locations"`  // Most favorite locations a user can save
+	OTPRequestLimit      int  `json:"otp_request_limit"`       // Most OTPs a phone number can request within the window
+	OTPRequestWindowSecs int  `json:"otp_request_window_secs"` // Rolling window in seconds for the OTP request limit
+	OTPMaxAttempts       int  `json:"otp_max_attempts"`        // Wrong codes accepted before the pending OTP is invalidated
+	OTPLogSender         bool `json:"otp_log_sender"`          // Development only: skip SMS delivery and just log that an OTP was issued
+	UserCacheTTLSecs     int  `json:"user_cache_ttl_secs"`     // How long user records looked up by ID stay cached
 }
 
 // LocationConfig contains location service specific configuration
 type LocationConfig struct {
 	AvailabilityTTLMinutes int `json:"availability_ttl_minutes"` // TTL in minutes for user availability in pools
+	DriverPresenceTTLSecs  int `json:"driver_presence_ttl_secs"` // Drivers without a beacon for this long leave the pool
+	PoolSweepIntervalSecs  int `json:"pool_sweep_interval_secs"` // How often silent drivers are swept from the geo index
+	PublishIntervalMs      int `json:"publish_interval_ms"`      // Location aggregates per ride are coalesced into one publish per interval; 0 publishes every update
+	// Billed distance between two updates is clamped to what a vehicle could cover at this speed
+	MaxSegmentSpeedKmh float64 `json:"max_segment_speed_kmh"`
 }
 
 // RidesConfig contains rides service specific configuration
 type RidesConfig struct {
-	MinDistanceKm float64 `json:"min_distance_km"` // Minimum distance in kilometers for billing
+	MinDistanceKm           float64 `json:"min_distance_km"`            // Minimum distance in kilometers for billing
+	MaxPickupDistanceMeters float64 `json:"max_pickup_distance_meters"` // Maximum driver-passenger distance in meters to start a ride
+	OutboxRelayIntervalSecs int     `json:"outbox_relay_interval_secs"` // How often pending outbox events are republished
+	OutboxRelayBatchSize    int     `json:"outbox_relay_batch_size"`    // Maximum outbox events relayed per run
+	AverageSpeedKmh         float64 `json:"average_speed_kmh"`          // Assumed driver speed for pickup ETA estimates
+	ETARefreshThresholdSecs int     `json:"eta_refresh_threshold_secs"` // Minimum ETA change pushed to the passenger
+	// Cancellations after the grace window are charged to whoever cancels; a zero amount charges nothing
+	CancellationGraceSecs     int `json:"cancellation_grace_secs"`     // Seconds after acceptance during which cancelling is free
+	DriverCancellationPenalty int `json:"driver_cancellation_penalty"` // Penalty recorded against a driver who cancels late
+	PassengerCancellationFee  int `json:"passenger_cancellation_fee"`  // Fee charged to a passenger who cancels late
+	// Rides awaiting pickup start on their own once the driver stays at the pickup point for the grace time
+	AutoStartEnabled     bool    `json:"auto_start_enabled"`      // Start rides from location updates without an explicit request
+	AutoStartGraceMeters float64 `json:"auto_start_grace_meters"` // Driver-pickup distance in meters treated as co-located
+	AutoStartGraceSecs   int     `json:"auto_start_grace_secs"`   // How long the driver must stay co-located before the ride starts
+	// Waiting at the pickup point past the grace time is charged per started minute; a zero fee charges nothing
+	WaitingGraceSecs    int `json:"waiting_grace_secs"`     // Free waiting time after the driver arrives
+	WaitingFeePerMinute int `json:"waiting_fee_per_minute"` // Charged for each started minute of waiting past the grace time
+	PaymentCacheTTLSecs int `json:"payment_cache_ttl_secs"` // How long payment records are cached for retried payment attempts
+	RideCacheTTLSecs    int `json:"ride_cache_ttl_secs"`    // How long ongoing rides are kept in memory between location aggregates
+	// Ongoing rides are completed automatically with their billed fare once they run this long
+	MaxRideDurationMins int `json:"max_ride_duration_mins"` // Longest a ride may stay ongoing before it is auto-completed
+	// Starting a ride can check the driver's position against the one the location service last
+	// recorded for the ride rather than trusting the location in the request
+	VerifyStartWithServerLocation bool `json:"verify_start_with_server_location"` // Use the location service's position of the driver to start rides
+	StartLocationMaxAgeSecs       int  `json:"start_location_max_age_secs"`       // Oldest recorded position accepted to start a ride
+	// The passenger is sent a pickup code when the ride is created, which the driver must enter to start it
+	PickupCodeEnabled     bool `json:"pickup_code_enabled"`      // Require the passenger's pickup code to start a ride
+	PickupCodeTTLSecs     int  `json:"pickup_code_ttl_secs"`     // How long a pickup code stays valid
+	PickupCodeMaxAttempts int  `json:"pickup_code_max_attempts"` // Wrong codes accepted before the code is invalidated
+}
+
+// FeatureFlagConfig contains feature flag configuration
+type FeatureFlagConfig struct {
+	CacheTTLSecs int `json:"cache_ttl_secs"` // How long flag values are cached before Redis is read again
 }
 
 // NewRelicConfig contains New Relic monitoring configuration
@@ -134,4 +321,5 @@ type LoggerConfig struct {
 	MaxBackups int    `json:"max_backups" mapstructure:"max_backups"` // Max number of backup files
 	Compress   bool   `json:"compress" mapstructure:"compress"`       // Compress rotated files
 	Type       string `json:"type" mapstructure:"type"`               // logger type: file, console, hybrid, newrelic
+	RedactPII  bool   `json:"redact_pii" mapstructure:"redact_pii"`   // Mask MSISDN and name fields in logs
 }
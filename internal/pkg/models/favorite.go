@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxFavoriteLabelLength is the longest label a favorite location can carry
+const maxFavoriteLabelLength = 50
+
+// FavoriteLocation is a place a passenger saved, such as home or work
+type FavoriteLocation struct {
+	ID        uuid.UUID `json:"id" db:"favorite_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Label     string    `json:"label" db:"label"`
+	Latitude  float64   `json:"latitude" db:"latitude"`
+	Longitude float64   `json:"longitude" db:"longitude"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// Location returns the coordinates of the favorite
+func (f *FavoriteLocation) Location() Location {
+	return Location{Latitude: f.Latitude, Longitude: f.Longitude}
+}
+
+// FavoriteLocationRequest creates or replaces a favorite location
+type FavoriteLocationRequest struct {
+	Label     string  `json:"label"`
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
+
+// Validate requires a short label and valid coordinates, leaving the label sanitized
+func (r *FavoriteLocationRequest) Validate() error {
+	label, err := SanitizeText("label", r.Label, maxFavoriteLabelLength)
+	if err != nil {
+		return err
+	}
+	if label == "" {
+		return requiredField("label")
+	}
+	r.Label = label
+	return validateCoordinates("location", r.Latitude, r.Longitude)
+}
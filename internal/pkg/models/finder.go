@@ -10,6 +10,14 @@ type FinderRequest struct {
 	IsActive       bool     `json:"is_active"`
 	Location       Location `json:"location"`
 	TargetLocation Location `json:"target_location"`
+	// TargetFavoriteID fills in the target location from one of the passenger's favorite locations
+	TargetFavoriteID string `json:"target_favorite_id,omitempty"`
+	// ScheduledAt pre-books the ride; matching starts at this time instead of immediately
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+	// DriverGender only matches drivers of this gender for this search, overriding the profile preference
+	DriverGender string `json:"driver_gender,omitempty"`
+	// PaymentMethod is how the passenger will pay for the ride; QRIS when empty
+	PaymentMethod PaymentMethod `json:"payment_method,omitempty"`
 }
 
 // FinderResponse represents a response to a finder toggle request
@@ -19,9 +27,13 @@ type FinderResponse struct {
 
 // FinderEvent represents a passenger's finder status change event for NATS
 type FinderEvent struct {
-	UserID         string    `json:"user_id"`
-	IsActive       bool      `json:"is_active"`
-	Location       Location  `json:"location"`
-	TargetLocation Location  `json:"target_location"`
-	Timestamp      time.Time `json:"timestamp"`
+	UserID         string        `json:"user_id"`
+	IsActive       bool          `json:"is_active"`
+	Location       Location      `json:"location"`
+	TargetLocation Location      `json:"target_location"`
+	Timestamp      time.Time     `json:"timestamp"`
+	ScheduledAt    *time.Time    `json:"scheduled_at,omitempty"`   // Set for pre-booked rides
+	CorrelationID  string        `json:"correlation_id,omitempty"` // Ties consumer logs back to the originating request
+	DriverGender   string        `json:"driver_gender,omitempty"`  // Only drivers of this gender are matched; empty matches any driver
+	PaymentMethod  PaymentMethod `json:"payment_method,omitempty"` // Carried to the match proposals and the ride; QRIS when empty
 }
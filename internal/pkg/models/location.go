@@ -17,3 +17,51 @@ type LocationAggregate struct {
 	Latitude  float64 `json:"latitude"`
 	Longitude float64 `json:"longitude"`
 }
+
+// DriverPool is a snapshot of the available drivers around a point, ordered by distance
+type DriverPool struct {
+	Location Location      `json:"location"`
+	RadiusKm float64       `json:"radius_km"`
+	Count    int           `json:"count"`
+	Drivers  []*NearbyUser `json:"drivers"`
+}
+
+// DriverPoolSize is the total number of drivers currently in the available pool
+type DriverPoolSize struct {
+	Count int64 `json:"count"`
+}
+
+// BoundingBox is the rectangle between two corners of a map view
+type BoundingBox struct {
+	MinLatitude  float64 `json:"min_latitude"`
+	MinLongitude float64 `json:"min_longitude"`
+	MaxLatitude  float64 `json:"max_latitude"`
+	MaxLongitude float64 `json:"max_longitude"`
+}
+
+// Contains reports whether a point lies within the box, edges included
+func (b BoundingBox) Contains(latitude, longitude float64) bool {
+	return latitude >= b.MinLatitude && latitude <= b.MaxLatitude &&
+		longitude >= b.MinLongitude && longitude <= b.MaxLongitude
+}
+
+// HeatmapCell is the number of available drivers within one geohash cell
+type HeatmapCell struct {
+	Geohash string `json:"geohash"`
+	Count   int    `json:"count"`
+}
+
+// DriverHeatmap aggregates the available-driver pool into geohash cells without identifying drivers.
+// Cells are ordered by count, busiest first; Truncated is set when quieter cells were left out.
+type DriverHeatmap struct {
+	Bounds    BoundingBox   `json:"bounds"`
+	Precision int           `json:"precision"`
+	Cells     []HeatmapCell `json:"cells"`
+	Truncated bool          `json:"truncated"`
+}
+
+// DriverEviction is a driver removed from the available pool after their beacons stopped
+type DriverEviction struct {
+	DriverID   string    `json:"driver_id"`
+	LastSeenAt time.Time `json:"last_seen_at"` // When the driver's last beacon or location update arrived
+}
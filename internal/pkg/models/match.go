@@ -1,6 +1,8 @@
 package models
 
 import (
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -17,37 +19,93 @@ const (
 	MatchStatusRejected           MatchStatus = "REJECTED"
 )
 
+// MatchStatuses lists every match status. It must stay in sync with the match_status enum in
+// db/migrations, which a test checks.
+var MatchStatuses = []MatchStatus{
+	MatchStatusPending,
+	MatchStatusDriverConfirmed,
+	MatchStatusPassengerConfirmed,
+	MatchStatusAccepted,
+	MatchStatusRejected,
+}
+
+// OpenMatchStatuses are the statuses of matches still awaiting confirmation
+var OpenMatchStatuses = []MatchStatus{
+	MatchStatusPending,
+	MatchStatusDriverConfirmed,
+	MatchStatusPassengerConfirmed,
+}
+
 // Match represents a ride-sharing match between a driver and a passenger
 type Match struct {
-	ID                 uuid.UUID   `json:"match_id" db:"id"`
-	DriverID           uuid.UUID   `json:"driver_id" db:"driver_id"`
-	PassengerID        uuid.UUID   `json:"passenger_id" db:"passenger_id"`
-	DriverLocation     Location    `json:"driver_location" db:"driver_location"`
-	PassengerLocation  Location    `json:"passenger_location" db:"passenger_location"`
-	TargetLocation     Location    `json:"target_location" db:"target_location"`
-	Status             MatchStatus `json:"status" db:"status"`
-	DriverConfirmed    bool        `json:"driver_confirmed" db:"driver_confirmed"`
-	PassengerConfirmed bool        `json:"passenger_confirmed" db:"passenger_confirmed"`
-	CreatedAt          time.Time   `json:"created_at" db:"created_at"`
-	UpdatedAt          time.Time   `json:"updated_at" db:"updated_at"`
+	ID                 uuid.UUID     `json:"match_id" db:"id"`
+	DriverID           uuid.UUID     `json:"driver_id" db:"driver_id"`
+	PassengerID        uuid.UUID     `json:"passenger_id" db:"passenger_id"`
+	DriverLocation     Location      `json:"driver_location" db:"driver_location"`
+	PassengerLocation  Location      `json:"passenger_location" db:"passenger_location"`
+	TargetLocation     Location      `json:"target_location" db:"target_location"`
+	Status             MatchStatus   `json:"status" db:"status"`
+	DriverConfirmed    bool          `json:"driver_confirmed" db:"driver_confirmed"`
+	PassengerConfirmed bool          `json:"passenger_confirmed" db:"passenger_confirmed"`
+	PaymentMethod      PaymentMethod `json:"payment_method" db:"payment_method"`
+	CreatedAt          time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt          time.Time     `json:"updated_at" db:"updated_at"`
+}
+
+// MatchDebugView is the full confirmation state of a match together with what the match and
+// location services currently hold for its users, for working out why a match won't accept
+type MatchDebugView struct {
+	MatchID            string                `json:"match_id"`
+	Status             MatchStatus           `json:"status"`
+	DriverConfirmed    bool                  `json:"driver_confirmed"`
+	PassengerConfirmed bool                  `json:"passenger_confirmed"`
+	CreatedAt          time.Time             `json:"created_at"`
+	UpdatedAt          time.Time             `json:"updated_at"`
+	Driver             MatchParticipantState `json:"driver"`
+	Passenger          MatchParticipantState `json:"passenger"`
+}
+
+// MatchParticipantState is what the match and location services hold for one user of a match
+type MatchParticipantState struct {
+	UserID       string    `json:"user_id"`
+	InPool       bool      `json:"in_pool"`                  // Whether the location service has them in the available pool
+	Location     *Location `json:"location,omitempty"`       // Their pool position, if in the pool
+	ActiveRideID string    `json:"active_ride_id,omitempty"` // The ride the match service tracks them on, if any
+	RideLocked   bool      `json:"ride_locked"`              // Whether they are being locked into a ride
+	Errors       []string  `json:"errors,omitempty"`         // Lookups that failed, so the state above may be incomplete
+}
+
+// MatchHistory is a page of a user's matches, newest first
+type MatchHistory struct {
+	Matches []*Match `json:"matches"`
+	Limit   int      `json:"limit"`
+	Offset  int      `json:"offset"`
+}
+
+// ActiveRide is the ride a user is currently on in the given role; RideID is empty when there is none
+type ActiveRide struct {
+	UserID string `json:"user_id"`
+	Role   string `json:"role"`
+	RideID string `json:"ride_id"`
 }
 
 // MatchDTO is used for database operations to flatten the nested Location structs
 type MatchDTO struct {
-	ID                 uuid.UUID   `db:"id"`
-	DriverID           uuid.UUID   `db:"driver_id"`
-	PassengerID        uuid.UUID   `db:"passenger_id"`
-	DriverLongitude    float64     `db:"driver_longitude"`
-	DriverLatitude     float64     `db:"driver_latitude"`
-	PassengerLongitude float64     `db:"passenger_longitude"`
-	PassengerLatitude  float64     `db:"passenger_latitude"`
-	TargetLongitude    float64     `db:"target_longitude"`
-	TargetLatitude     float64     `db:"target_latitude"`
-	Status             MatchStatus `db:"status"`
-	DriverConfirmed    bool        `db:"driver_confirmed"`
-	PassengerConfirmed bool        `db:"passenger_confirmed"`
-	CreatedAt          time.Time   `db:"created_at"`
-	UpdatedAt          time.Time   `db:"updated_at"`
+	ID                 uuid.UUID     `db:"id"`
+	DriverID           uuid.UUID     `db:"driver_id"`
+	PassengerID        uuid.UUID     `db:"passenger_id"`
+	DriverLongitude    float64       `db:"driver_longitude"`
+	DriverLatitude     float64       `db:"driver_latitude"`
+	PassengerLongitude float64       `db:"passenger_longitude"`
+	PassengerLatitude  float64       `db:"passenger_latitude"`
+	TargetLongitude    float64       `db:"target_longitude"`
+	TargetLatitude     float64       `db:"target_latitude"`
+	Status             MatchStatus   `db:"status"`
+	DriverConfirmed    bool          `db:"driver_confirmed"`
+	PassengerConfirmed bool          `db:"passenger_confirmed"`
+	PaymentMethod      PaymentMethod `db:"payment_method"`
+	CreatedAt          time.Time     `db:"created_at"`
+	UpdatedAt          time.Time     `db:"updated_at"`
 }
 
 // ToDTO converts a Match to a MatchDTO
@@ -65,6 +123,7 @@ func (m *Match) ToDTO() *MatchDTO {
 		Status:             m.Status,
 		DriverConfirmed:    m.DriverConfirmed,
 		PassengerConfirmed: m.PassengerConfirmed,
+		PaymentMethod:      m.PaymentMethod,
 		CreatedAt:          m.CreatedAt,
 		UpdatedAt:          m.UpdatedAt,
 	}
@@ -94,27 +153,158 @@ func (dto *MatchDTO) ToMatch() *Match {
 		Status:             dto.Status,
 		DriverConfirmed:    dto.DriverConfirmed,
 		PassengerConfirmed: dto.PassengerConfirmed,
+		PaymentMethod:      dto.PaymentMethod,
 		CreatedAt:          dto.CreatedAt,
 		UpdatedAt:          dto.UpdatedAt,
 	}
 }
 
 type MatchProposal struct {
-	ID             string      `json:"match_id"`
-	PassengerID    string      `json:"passenger_id"`
-	DriverID       string      `json:"driver_id"`
-	UserLocation   Location    `json:"location"`
-	DriverLocation Location    `json:"driver_location"`
-	TargetLocation Location    `json:"target_location"`
-	MatchStatus    MatchStatus `json:"match_status"`
+	ID             string        `json:"match_id"`
+	PassengerID    string        `json:"passenger_id"`
+	DriverID       string        `json:"driver_id"`
+	UserLocation   Location      `json:"location"`
+	DriverLocation Location      `json:"driver_location"`
+	TargetLocation Location      `json:"target_location"`
+	MatchStatus    MatchStatus   `json:"match_status"`
+	PaymentMethod  PaymentMethod `json:"payment_method,omitempty"` // Defaults to QRIS when empty
+	CorrelationID  string        `json:"correlation_id,omitempty"` // Ties consumer logs back to the originating request
+	// Navigation to the pickup, set on accepted matches so the driver app can start directions immediately
+	Navigation *PickupNavigation `json:"navigation,omitempty"`
+	// Trip preview so the driver can judge the ride before accepting; omitted when the destination is unknown
+	EstimatedDistanceKm float64 `json:"estimated_distance_km,omitempty"` // Straight-line pickup to destination distance
+	EstimatedFare       int     `json:"estimated_fare,omitempty"`        // Fare for that distance at the pickup region's rate
+	EstimatedEarnings   int     `json:"estimated_earnings,omitempty"`    // Driver's share of the fare after the admin fee
+	// Set on the options of a DriverAcceptancesEvent and PendingProposalsEvent so the passenger can compare drivers
+	PickupDistanceKm float64 `json:"pickup_distance_km,omitempty"` // Straight-line driver to pickup distance
+	PickupETASeconds int     `json:"pickup_eta_seconds,omitempty"` // Estimated time for the driver to reach the pickup
+	// Set on new proposals so the driver app can count down; unanswered proposals are rejected at this time
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// PickupNavigation is a ready-to-open navigation target for the driver's way to the pickup
+type PickupNavigation struct {
+	Coordinates string `json:"coordinates"` // "latitude,longitude" to six decimal places
+	DeepLink    string `json:"deep_link"`   // Universal maps link that opens driving directions
+}
+
+// NewPickupNavigation builds the navigation target for a pickup location
+func NewPickupNavigation(pickup Location) *PickupNavigation {
+	coordinates := strconv.FormatFloat(pickup.Latitude, 'f', 6, 64) + "," +
+		strconv.FormatFloat(pickup.Longitude, 'f', 6, 64)
+	return &PickupNavigation{
+		Coordinates: coordinates,
+		DeepLink:    fmt.Sprintf("https://www.google.com/maps/dir/?api=1&destination=%s&travelmode=driving", coordinates),
+	}
+}
+
+// DriverCancellationStats is how often a driver cancelled rides they accepted over the rolling window
+type DriverCancellationStats struct {
+	DriverID         string     `json:"driver_id"`
+	WindowHours      int        `json:"window_hours"`
+	Accepted         int        `json:"accepted"`
+	Cancelled        int        `json:"cancelled"`
+	CancellationRate float64    `json:"cancellation_rate"`         // Cancelled over accepted, from 0 to 1
+	SuspendedUntil   *time.Time `json:"suspended_until,omitempty"` // Set while the driver is left out of matching
+}
+
+// NoDriversFoundEvent is published when a passenger's search produces no match proposals
+type NoDriversFoundEvent struct {
+	PassengerID      string    `json:"passenger_id"`
+	SearchRadiusKm   float64   `json:"search_radius_km"`
+	DriversAttempted int       `json:"drivers_attempted"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// MatchMaintenanceEvent is published when a driver going online or a passenger's search is
+// refused because matching is in maintenance
+type MatchMaintenanceEvent struct {
+	UserID    string    `json:"user_id"`
+	Role      string    `json:"role"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DriverAcceptancesEvent is published when a passenger's acceptance window closes. It lists the
+// matches whose drivers accepted, nearest driver first, for the passenger to choose one.
+type DriverAcceptancesEvent struct {
+	PassengerID string          `json:"passenger_id"`
+	Options     []MatchProposal `json:"options"`
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
+// PendingProposalsEvent re-sends a passenger's unanswered proposals after their drivers moved,
+// nearest driver first, so the passenger sees current distances and ETAs
+type PendingProposalsEvent struct {
+	PassengerID string          `json:"passenger_id"`
+	Proposals   []MatchProposal `json:"proposals"`
+	Timestamp   time.Time       `json:"timestamp"`
 }
 
 // MatchConfirmRequest is the request structure for confirming a match
 type MatchConfirmRequest struct {
-	ID     string `json:"match_id"`
-	UserID string `json:"user_id"`
-	Role   string `json:"role"`
-	Status string `json:"status"`
+	ID     string               `json:"match_id"`
+	UserID string               `json:"user_id"`
+	Role   string               `json:"role"`
+	Status string               `json:"status"`
+	Reason MatchRejectionReason `json:"reason,omitempty"` // Optional, only when rejecting
+}
+
+// MatchRejectionReason is why a driver turned down a match proposal
+type MatchRejectionReason string
+
+const (
+	RejectionReasonTooFar                 MatchRejectionReason = "TOO_FAR"
+	RejectionReasonWrongDirection         MatchRejectionReason = "WRONG_DIRECTION"
+	RejectionReasonUndesirableDestination MatchRejectionReason = "UNDESIRABLE_DESTINATION"
+)
+
+// MatchRejectionReasons lists every rejection reason. It must stay in sync with the
+// check_match_rejection_reason constraint in db/migrations, which a test checks.
+var MatchRejectionReasons = []MatchRejectionReason{
+	RejectionReasonTooFar,
+	RejectionReasonWrongDirection,
+	RejectionReasonUndesirableDestination,
+}
+
+// IsValid reports whether the reason is a known reason code
+func (r MatchRejectionReason) IsValid() bool {
+	for _, reason := range MatchRejectionReasons {
+		if r == reason {
+			return true
+		}
+	}
+	return false
+}
+
+// RejectionReasonCount is how often drivers gave one rejection reason
+type RejectionReasonCount struct {
+	Reason MatchRejectionReason `json:"reason"`
+	Count  int                  `json:"count"`
+}
+
+// MatchRejectionStats aggregates the reasons drivers gave for rejecting matches since a point in time
+type MatchRejectionStats struct {
+	Since   time.Time              `json:"since"`
+	Total   int                    `json:"total"`
+	Reasons []RejectionReasonCount `json:"reasons"` // Every known reason, most frequent first
+}
+
+// PoolRemoval is a matched user whose removal from the available pool failed and is retried
+type PoolRemoval struct {
+	Role   string // "driver" or "passenger"
+	UserID string
+}
+
+// AutoRejection is an accepted match whose passenger's other open matches still need rejecting
+type AutoRejection struct {
+	PassengerID string
+	MatchID     string    // The accepted match, which is left alone
+	QueuedAt    time.Time // Entries older than the proposal TTL are dropped, since the proposals have expired by then
+}
+
+// MaintenanceMode reports or sets whether the match service is refusing new matches
+type MaintenanceMode struct {
+	Enabled bool `json:"enabled"`
 }
 
 // NearbyUser represents a user with their current location and distance
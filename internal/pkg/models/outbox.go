@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutboxStatus represents the delivery status of an outbox event
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "PENDING"
+	OutboxStatusSent    OutboxStatus = "SENT"
+)
+
+// OutboxEvent is an event stored alongside a state change and relayed to NATS afterwards
+type OutboxEvent struct {
+	EventID     uuid.UUID    `json:"event_id" db:"event_id"`
+	AggregateID uuid.UUID    `json:"aggregate_id" db:"aggregate_id"`
+	Subject     string       `json:"subject" db:"subject"`
+	Payload     []byte       `json:"payload" db:"payload"`
+	Status      OutboxStatus `json:"status" db:"status"`
+	Attempts    int          `json:"attempts" db:"attempts"`
+	LastError   string       `json:"last_error" db:"last_error"`
+	CreatedAt   time.Time    `json:"created_at" db:"created_at"`
+	SentAt      *time.Time   `json:"sent_at" db:"sent_at"`
+}
@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"github.com/google/uuid"
 )
 
 // PaymentStatus represents the status of a payment
@@ -14,12 +16,41 @@ const (
 	PaymentStatusProcessed PaymentStatus = "PROCESSED"
 )
 
+// PaymentStatuses lists every payment status. It must stay in sync with the payment status check
+// constraints in db/migrations, which a test checks.
+var PaymentStatuses = []PaymentStatus{
+	PaymentStatusPending,
+	PaymentStatusAccepted,
+	PaymentStatusRejected,
+	PaymentStatusProcessed,
+}
+
+// PaymentMethod is how the passenger pays for a ride
+type PaymentMethod string
+
+const (
+	// PaymentMethodQRIS is paid by the passenger scanning a QR code after arrival
+	PaymentMethodQRIS PaymentMethod = "QRIS"
+	// PaymentMethodCash is handed to the driver and settled as soon as the ride arrives
+	PaymentMethodCash PaymentMethod = "CASH"
+)
+
+// ValidatePaymentMethod accepts an empty value, meaning the default QRIS, or one of the known methods
+func ValidatePaymentMethod(field string, value PaymentMethod) error {
+	if value == "" || value == PaymentMethodQRIS || value == PaymentMethodCash {
+		return nil
+	}
+	return &FieldError{Field: field, Message: "must be QRIS or CASH"}
+}
+
 // PaymentRequest represents a request to process payment for a completed ride
 type PaymentRequest struct {
-	RideID      string `json:"ride_id"`
-	PassengerID string `json:"passenger_id"`
-	TotalCost   int    `json:"total_cost"`
-	QRCodeURL   string `json:"qr_code_url"` // URL to QR code image for payment processing
+	RideID        string        `json:"ride_id"`
+	PassengerID   string        `json:"passenger_id"`
+	TotalCost     int           `json:"total_cost"`
+	QRCodeURL     string        `json:"qr_code_url"` // URL to QR code image for payment processing, empty for cash rides
+	PaymentMethod PaymentMethod `json:"payment_method"`
+	Breakdown     FareBreakdown `json:"breakdown"`
 }
 
 // PaymentResponse represents the response to a payment request
@@ -36,3 +67,16 @@ type PaymentProccessRequest struct {
 	TotalCost int           `json:"total_cost"`
 	Status    PaymentStatus `json:"status"`
 }
+
+// PaymentAudit is an append-only record of a single payment status transition.
+// FromStatus is empty for the row written when the payment is created.
+type PaymentAudit struct {
+	AuditID    uuid.UUID     `json:"audit_id" db:"audit_id"`
+	PaymentID  uuid.UUID     `json:"payment_id" db:"payment_id"`
+	RideID     uuid.UUID     `json:"ride_id" db:"ride_id"`
+	FromStatus PaymentStatus `json:"from_status,omitempty" db:"from_status"`
+	ToStatus   PaymentStatus `json:"to_status" db:"to_status"`
+	Actor      string        `json:"actor" db:"actor"` // Who caused the transition, e.g. "passenger:{user_id}"
+	Amount     int           `json:"amount" db:"amount"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+}
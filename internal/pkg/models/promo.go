@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// PromoType is how a promo code discounts a fare
+type PromoType string
+
+const (
+	PromoTypePercentage PromoType = "PERCENTAGE" // Value is a percentage of the fare, 1-100
+	PromoTypeFlat       PromoType = "FLAT"       // Value is a fixed amount, capped at the fare
+)
+
+// Promo is a discount code a passenger can apply when their ride arrives
+type Promo struct {
+	Code      string     `json:"code" db:"code"`
+	Type      PromoType  `json:"discount_type" db:"discount_type"`
+	Value     int        `json:"discount_value" db:"discount_value"`
+	MaxUses   int        `json:"max_uses" db:"max_uses"` // 0 means unlimited
+	UsedCount int        `json:"used_count" db:"used_count"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty" db:"expires_at"` // Nil never expires
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
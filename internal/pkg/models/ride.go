@@ -10,53 +10,148 @@ import (
 type RideStatus string
 
 const (
-	RideStatusPending      RideStatus = "PENDING"
-	RideStatusDriverPickup RideStatus = "PICKUP"
-	RideStatusOngoing      RideStatus = "ONGOING"
-	RideStatusCompleted    RideStatus = "COMPLETED"
+	RideStatusPending       RideStatus = "PENDING"
+	RideStatusDriverPickup  RideStatus = "PICKUP"
+	RideStatusDriverArrived RideStatus = "DRIVER_ARRIVED" // Driver is at the pickup point waiting for the passenger
+	RideStatusOngoing       RideStatus = "ONGOING"
+	RideStatusCompleted     RideStatus = "COMPLETED"
+	RideStatusCancelled     RideStatus = "CANCELLED"
+)
+
+// RideStatuses lists every ride status. It must stay in sync with the ride_status enum in
+// db/migrations, which a test checks.
+var RideStatuses = []RideStatus{
+	RideStatusPending,
+	RideStatusDriverPickup,
+	RideStatusDriverArrived,
+	RideStatusOngoing,
+	RideStatusCompleted,
+	RideStatusCancelled,
+}
+
+// Parties that can cancel a ride
+const (
+	CancelledByDriver    = "driver"
+	CancelledByPassenger = "passenger"
 )
 
 // Ride represents a ride record
 type Ride struct {
-	RideID      uuid.UUID  `json:"ride_id" db:"ride_id"`
-	MatchID     uuid.UUID  `json:"match_id" db:"match_id"`
-	DriverID    uuid.UUID  `json:"driver_id" db:"driver_id"`
-	PassengerID uuid.UUID  `json:"passenger_id" db:"passenger_id"`
-	Status      RideStatus `json:"status" db:"status"`
-	TotalCost   int        `json:"total_cost" db:"total_cost"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	RideID           uuid.UUID     `json:"ride_id" db:"ride_id"`
+	MatchID          uuid.UUID     `json:"match_id" db:"match_id"`
+	DriverID         uuid.UUID     `json:"driver_id" db:"driver_id"`
+	PassengerID      uuid.UUID     `json:"passenger_id" db:"passenger_id"`
+	Status           RideStatus    `json:"status" db:"status"`
+	TotalCost        int           `json:"total_cost" db:"total_cost"`
+	PickupLatitude   float64       `json:"pickup_latitude" db:"pickup_latitude"`
+	PickupLongitude  float64       `json:"pickup_longitude" db:"pickup_longitude"`
+	PickupETASeconds int           `json:"pickup_eta_seconds" db:"pickup_eta_seconds"` // Estimated driver arrival at the pickup point
+	PaymentMethod    PaymentMethod `json:"payment_method" db:"payment_method"`
+	ColocatedSince   *time.Time    `json:"colocated_since,omitempty" db:"colocated_since"`     // When the driver was first seen at the pickup point
+	DriverArrivedAt  *time.Time    `json:"driver_arrived_at,omitempty" db:"driver_arrived_at"` // When the driver reported arriving at the pickup point
+	StartedAt        *time.Time    `json:"started_at,omitempty" db:"started_at"`               // When the ride moved to ongoing
+	LedgerFinalized  bool          `json:"ledger_finalized" db:"ledger_finalized"`             // Set once the fare is charged; no billing entries are accepted afterwards
+	FinalTotal       int           `json:"final_total,omitempty" db:"final_total"`             // Fare the passenger paid, snapshotted on completion
+	CreatedAt        time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt        time.Time     `json:"updated_at" db:"updated_at"`
 }
 
 type RideResp struct {
-	RideID      string    `json:"ride_id"`
-	MatchID     string    `json:"match_id"`
-	DriverID    string    `json:"driver_id"`
-	PassengerID string    `json:"passenger_id"`
-	Status      string    `json:"status"`
-	TotalCost   int       `json:"total_cost"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	RideID             string    `json:"ride_id"`
+	MatchID            string    `json:"match_id"`
+	DriverID           string    `json:"driver_id"`
+	PassengerID        string    `json:"passenger_id"`
+	Status             string    `json:"status"`
+	TotalCost          int       `json:"total_cost"`
+	PickupETASeconds   int       `json:"pickup_eta_seconds,omitempty"`
+	CreatedAt          time.Time `json:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at"`
+	CorrelationID      string    `json:"correlation_id,omitempty"`       // Ties consumer logs back to the originating request
+	PickupCodeRequired bool      `json:"pickup_code_required,omitempty"` // The driver needs a pickup code from the passenger to start
+	PickupCode         string    `json:"pickup_code,omitempty"`          // Only set in the passenger's own notification, never in ride events
 }
 
+// BillingCategory distinguishes distance-based fares from flat surcharges in the billing ledger
+type BillingCategory string
+
+const (
+	BillingCategoryDistance  BillingCategory = "DISTANCE"
+	BillingCategorySurcharge BillingCategory = "SURCHARGE"
+	BillingCategoryDiscount  BillingCategory = "DISCOUNT" // Promo discounts, recorded with a negative cost
+)
+
 // BillingLedger represents an entry in the billing ledger
 type BillingLedger struct {
-	EntryID   uuid.UUID `json:"entry_id" db:"entry_id"`
-	RideID    uuid.UUID `json:"ride_id" db:"ride_id"`
-	Distance  float64   `json:"distance" db:"distance"`
-	Cost      int       `json:"cost" db:"cost"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	EntryID     uuid.UUID       `json:"entry_id" db:"entry_id"`
+	RideID      uuid.UUID       `json:"ride_id" db:"ride_id"`
+	Category    BillingCategory `json:"category" db:"category"`
+	Description string          `json:"description,omitempty" db:"description"` // Surcharge type, e.g. "toll", or promo code
+	Distance    float64         `json:"distance" db:"distance"`
+	Cost        int             `json:"cost" db:"cost"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+}
+
+// SurchargeRequest asks to add an approved flat surcharge to an ongoing ride
+type SurchargeRequest struct {
+	RideID   string `json:"ride_id"`
+	DriverID string `json:"driver_id"`
+	Type     string `json:"type"` // One of the configured surcharge names, e.g. "toll"
+}
+
+// SurchargeTypeWaiting describes the surcharge line for time the driver spent waiting at pickup
+const SurchargeTypeWaiting = "waiting"
+
+// SurchargeItem is a flat surcharge line on a fare breakdown
+type SurchargeItem struct {
+	Type   string `json:"type"`
+	Amount int    `json:"amount"`
+}
+
+// FareBreakdown splits a fare into its distance-based part, flat surcharges and any promo discount
+type FareBreakdown struct {
+	DistanceCost  int             `json:"distance_cost"`  // Distance fare after the driver's adjustment
+	SurchargeCost int             `json:"surcharge_cost"` // Surcharges are passed on in full
+	Surcharges    []SurchargeItem `json:"surcharges,omitempty"`
+	PromoCode     string          `json:"promo_code,omitempty"`
+	Discount      int             `json:"discount,omitempty"` // Taken off the fare before the admin fee
+	Estimate      *FareEstimate   `json:"estimate,omitempty"` // Set when the ride was accepted with a trip estimate
+}
+
+// FareEstimate compares the fare quoted when the match was proposed with the fare actually charged.
+// The delta is decomposed so that Delta = DistanceDelta + WaitingFee + OtherSurcharges - Discount.
+type FareEstimate struct {
+	EstimatedFare       int     `json:"estimated_fare"`
+	EstimatedDistanceKm float64 `json:"estimated_distance_km"`
+	ActualFare          int     `json:"actual_fare"`
+	Delta               int     `json:"delta"`            // Actual minus estimated fare
+	DistanceDelta       int     `json:"distance_delta"`   // Distance fare beyond the estimate, after the driver's adjustment
+	WaitingFee          int     `json:"waiting_fee"`      // Charged for waiting at the pickup point
+	OtherSurcharges     int     `json:"other_surcharges"` // Tolls and other flat surcharges
+	Discount            int     `json:"discount"`         // Promo discount, which lowers the actual fare
+}
+
+// RideFare records the region and per-kilometer rate a ride was accepted under, with the trip
+// estimate the match proposal quoted
+type RideFare struct {
+	RideID              uuid.UUID `json:"ride_id" db:"ride_id"`
+	Region              string    `json:"region" db:"region"`
+	RatePerKm           float64   `json:"rate_per_km" db:"rate_per_km"`
+	EstimatedFare       int       `json:"estimated_fare" db:"estimated_fare"`               // Zero when no destination was known
+	EstimatedDistanceKm float64   `json:"estimated_distance_km" db:"estimated_distance_km"` // Straight-line pickup to destination distance
+	CreatedAt           time.Time `json:"created_at" db:"created_at"`
 }
 
 // RideCompleteEvent represents an event to complete a ride with adjustment
 type RideCompleteEvent struct {
 	RideID           string  `json:"ride_id"`
 	AdjustmentFactor float64 `json:"adjustment_factor"`
+	PromoCode        string  `json:"promo_code,omitempty"`
 }
 
 type RideArrivalReq struct {
 	RideID           string  `json:"ride_id"`
 	AdjustmentFactor float64 `json:"adjustment_factor"`
+	PromoCode        string  `json:"promo_code,omitempty"`
 }
 
 // Payment represents a payment record
@@ -71,8 +166,9 @@ type Payment struct {
 }
 
 type RideComplete struct {
-	Ride    Ride    `json:"ride"`
-	Payment Payment `json:"payment"`
+	Ride      Ride           `json:"ride"`
+	Payment   Payment        `json:"payment"`
+	Breakdown *FareBreakdown `json:"breakdown,omitempty"`
 }
 
 // RideStartTripEvent represents an event to start trip after driver picks up passenger
@@ -83,11 +179,31 @@ type RideStartTripEvent struct {
 	Timestamp         time.Time `json:"timestamp"`
 }
 
-// RideStartTripRequest represents a request to start a trip via HTTP
+// DriverArrivedRequest reports that the driver reached the pickup point and is waiting for the passenger
+type DriverArrivedRequest struct {
+	RideID         string    `json:"ride_id"`
+	DriverID       string    `json:"driver_id"`
+	DriverLocation *Location `json:"driver_location"`
+}
+
+// RideStartRequest represents a request to start a trip via HTTP
 type RideStartRequest struct {
 	RideID            string    `json:"ride_id"`
 	DriverLocation    *Location `json:"driver_location"`
 	PassengerLocation *Location `json:"passenger_location"`
+	PickupCode        string    `json:"pickup_code,omitempty"` // Required when pickup codes are enabled
+}
+
+// PickupCodeRequest asks for the pickup code of a ride on behalf of its passenger
+type PickupCodeRequest struct {
+	RideID      string `json:"ride_id"`
+	PassengerID string `json:"passenger_id"`
+}
+
+// PickupCodeResponse carries the code the passenger reads out to the driver to start the ride
+type PickupCodeResponse struct {
+	RideID     string `json:"ride_id"`
+	PickupCode string `json:"pickup_code"`
 }
 
 // RidePickupEvent represents an event when a driver is on their way to pick up a passenger
@@ -99,9 +215,68 @@ type RidePickupEvent struct {
 	Timestamp      time.Time `json:"timestamp"`
 }
 
+// RidePickupETAEvent carries a refreshed estimate of when the driver reaches the pickup point
+type RidePickupETAEvent struct {
+	RideID           string    `json:"ride_id"`
+	DriverID         string    `json:"driver_id"`
+	PassengerID      string    `json:"passenger_id"`
+	PickupETASeconds int       `json:"pickup_eta_seconds"`
+	DriverLocation   Location  `json:"driver_location"`
+	Timestamp        time.Time `json:"timestamp"`
+}
+
+// RideBillingUpdate carries a billing entry added to an ongoing ride and the ride's running total
+type RideBillingUpdate struct {
+	RideID      string    `json:"ride_id"`
+	DriverID    string    `json:"driver_id"`
+	PassengerID string    `json:"passenger_id"`
+	Distance    float64   `json:"distance"`
+	Cost        int       `json:"cost"`
+	TotalCost   int       `json:"total_cost"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
 type RideArrival struct {
 	RideID           string  `json:"ride_id"`
 	DriverID         string  `json:"driver_id"`
 	PassengerID      string  `json:"passenger_id"`
 	AdjustmentFactor float64 `json:"adjustment_factor"`
+	PromoCode        string  `json:"promo_code,omitempty"`
+}
+
+// maxCancelReasonLength is the longest reason a driver or passenger can give for cancelling a ride
+const maxCancelReasonLength = 255
+
+// RideCancelRequest is a request by the driver or passenger to cancel a ride before the trip starts
+type RideCancelRequest struct {
+	RideID string `json:"ride_id"`
+	UserID string `json:"user_id"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// Validate sanitizes the optional cancellation reason, rejecting overly long ones
+func (r *RideCancelRequest) Validate() error {
+	reason, err := SanitizeText("reason", r.Reason, maxCancelReasonLength)
+	if err != nil {
+		return err
+	}
+	r.Reason = reason
+	return nil
+}
+
+// RideCancellation records who cancelled a ride and the fee or penalty charged to them
+type RideCancellation struct {
+	CancellationID uuid.UUID `json:"cancellation_id" db:"cancellation_id"`
+	RideID         uuid.UUID `json:"ride_id" db:"ride_id"`
+	CancelledBy    uuid.UUID `json:"cancelled_by" db:"cancelled_by"`
+	Role           string    `json:"role" db:"role"` // One of the CancelledBy* values
+	Fee            int       `json:"fee" db:"fee"`   // Zero when cancelled within the grace window
+	Reason         string    `json:"reason,omitempty" db:"reason"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+// RideCancelled is published when a ride is cancelled so both users can be released
+type RideCancelled struct {
+	Ride         Ride             `json:"ride"`
+	Cancellation RideCancellation `json:"cancellation"`
 }
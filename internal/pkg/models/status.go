@@ -0,0 +1,13 @@
+package models
+
+import "strings"
+
+// SQLValueList renders statuses as a quoted SQL list such as 'PENDING', 'ACCEPTED' for IN clauses.
+// The values are package constants, never user input, so quoting them is safe.
+func SQLValueList[T ~string](statuses []T) string {
+	quoted := make([]string, len(statuses))
+	for i, status := range statuses {
+		quoted[i] = "'" + string(status) + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const migrationsDir = "../../../db/migrations"
+
+var (
+	createEnumPattern = regexp.MustCompile(`CREATE TYPE (\w+) AS ENUM \(([^)]*)\)`)
+	addEnumPattern    = regexp.MustCompile(`ALTER TYPE (\w+) ADD VALUE IF NOT EXISTS '(\w+)'`)
+	checkInPattern    = regexp.MustCompile(`CONSTRAINT (\w+) CHECK \([^\n]*?IN \(([^)]*)\)\)`)
+	quotedPattern     = regexp.MustCompile(`'(\w+)'`)
+)
+
+// readMigrations returns the migration scripts in the order they are applied
+func readMigrations(t *testing.T) []string {
+	paths, err := filepath.Glob(filepath.Join(migrationsDir, "*.sql"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	sort.Strings(paths)
+
+	scripts := make([]string, len(paths))
+	for i, path := range paths {
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		scripts[i] = string(content)
+	}
+	return scripts
+}
+
+// quotedValues extracts the quoted values of an SQL list
+func quotedValues(list string) []string {
+	var values []string
+	for _, match := range quotedPattern.FindAllStringSubmatch(list, -1) {
+		values = append(values, match[1])
+	}
+	return values
+}
+
+// migratedEnums collects each enum type's values across all migrations
+func migratedEnums(t *testing.T) map[string][]string {
+	enums := make(map[string][]string)
+	for _, script := range readMigrations(t) {
+		for _, match := range createEnumPattern.FindAllStringSubmatch(script, -1) {
+			enums[match[1]] = quotedValues(match[2])
+		}
+		for _, match := range addEnumPattern.FindAllStringSubmatch(script, -1) {
+			enums[match[1]] = append(enums[match[1]], match[2])
+		}
+	}
+	return enums
+}
+
+// migratedChecks collects the allowed values of each IN check constraint, keeping the latest definition
+func migratedChecks(t *testing.T) map[string][]string {
+	checks := make(map[string][]string)
+	for _, script := range readMigrations(t) {
+		for _, match := range checkInPattern.FindAllStringSubmatch(script, -1) {
+			checks[match[1]] = quotedValues(match[2])
+		}
+	}
+	return checks
+}
+
+func stringsOf[T ~string](values []T) []string {
+	out := make([]string, len(values))
+	for i, v := range values {
+		out[i] = string(v)
+	}
+	return out
+}
+
+func TestStatuses_MatchMigratedEnums(t *testing.T) {
+	enums := migratedEnums(t)
+
+	assert.ElementsMatch(t, enums["match_status"], stringsOf(MatchStatuses),
+		"MatchStatuses and the match_status enum differ; add a migration or update the list")
+	assert.ElementsMatch(t, enums["ride_status"], stringsOf(RideStatuses),
+		"RideStatuses and the ride_status enum differ; add a migration or update the list")
+}
+
+func TestStatuses_MatchPaymentCheckConstraints(t *testing.T) {
+	checks := migratedChecks(t)
+
+	for _, name := range []string{"check_payment_status", "check_payment_audit_from_status", "check_payment_audit_to_status"} {
+		require.Contains(t, checks, name)
+		assert.ElementsMatch(t, checks[name], stringsOf(PaymentStatuses),
+			"PaymentStatuses and %s differ; add a migration or update the list", name)
+	}
+}
+
+func TestMatchRejectionReasons_MatchCheckConstraint(t *testing.T) {
+	checks := migratedChecks(t)
+
+	require.Contains(t, checks, "check_match_rejection_reason")
+	assert.ElementsMatch(t, checks["check_match_rejection_reason"], stringsOf(MatchRejectionReasons),
+		"MatchRejectionReasons and check_match_rejection_reason differ; add a migration or update the list")
+}
+
+func TestOpenMatchStatuses_AreMatchStatuses(t *testing.T) {
+	for _, status := range OpenMatchStatuses {
+		assert.Contains(t, MatchStatuses, status)
+	}
+}
+
+func TestSQLValueList(t *testing.T) {
+	assert.Equal(t, "'PENDING', 'DRIVER_CONFIRMED', 'PASSENGER_CONFIRMED'", SQLValueList(OpenMatchStatuses))
+	assert.Equal(t, "", SQLValueList([]RideStatus{}))
+}
@@ -4,8 +4,26 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// maxFullNameLength is the longest full name a user can register with
+const maxFullNameLength = 100
+
+// Genders a user can give on their profile, also the values of a driver gender preference
+const (
+	GenderFemale = "female"
+	GenderMale   = "male"
+)
+
+// ValidateGender accepts an empty value, meaning not given, or one of the known genders
+func ValidateGender(field, value string) error {
+	if value == "" || value == GenderFemale || value == GenderMale {
+		return nil
+	}
+	return &FieldError{Field: field, Message: "must be female or male"}
+}
+
 // User represents a user in the system (either driver or customer)
 type User struct {
 	ID         uuid.UUID `json:"id" bson:"_id" db:"id"`
@@ -17,13 +35,52 @@ type User struct {
 	IsActive   bool      `json:"is_active" bson:"is_active" db:"is_active"`
 	DriverInfo *Driver   `json:"driver_info,omitempty" bson:"driver_info,omitempty"`
 	Rating     float64   `json:"rating,omitempty" bson:"rating,omitempty" db:"rating"`
+	// Optional, for markets with gender-preference matching. A passenger's preference limits their
+	// matches to drivers of that gender
+	Gender                 string `json:"gender,omitempty" bson:"gender,omitempty" db:"gender"`
+	DriverGenderPreference string `json:"driver_gender_preference,omitempty" bson:"driver_gender_preference,omitempty" db:"driver_gender_preference"`
+}
+
+// SanitizeFullName strips control characters from the user's full name and rejects overly long ones
+func (u *User) SanitizeFullName() error {
+	name, err := SanitizeText("fullname", u.FullName, maxFullNameLength)
+	if err != nil {
+		return err
+	}
+	u.FullName = name
+	return nil
 }
 
 // Driver represents additional information for users who are drivers
 type Driver struct {
-	UserID       uuid.UUID `json:"user_id" bson:"user_id" db:"user_id"`
-	VehicleType  string    `json:"vehicle_type" bson:"vehicle_type" db:"vehicle_type"`
-	VehiclePlate string    `json:"vehicle_plate" bson:"vehicle_plate" db:"vehicle_plate"`
+	UserID       uuid.UUID      `json:"user_id" bson:"user_id" db:"user_id"`
+	VehicleType  string         `json:"vehicle_type" bson:"vehicle_type" db:"vehicle_type"`
+	VehiclePlate string         `json:"vehicle_plate" bson:"vehicle_plate" db:"vehicle_plate"`
+	Documents    pq.StringArray `json:"documents,omitempty" bson:"documents,omitempty" db:"documents"`
+	Verified     bool           `json:"verified" bson:"verified" db:"verified"`
+	VerifiedAt   *time.Time     `json:"verified_at,omitempty" bson:"verified_at,omitempty" db:"verified_at"`
+}
+
+// UserFilter narrows an admin listing of users; zero values leave a field unfiltered
+type UserFilter struct {
+	Role     string
+	IsActive *bool
+	Search   string // Case-insensitive match on full name or MSISDN
+	Limit    int
+	Offset   int
+}
+
+// UserList is a page of users together with the total number of users matching the filter
+type UserList struct {
+	Users  []*User `json:"users"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
+}
+
+// DriverVerificationRequest represents an admin decision on a driver's submitted documents
+type DriverVerificationRequest struct {
+	Verified bool `json:"verified"`
 }
 
 // Location represents a geographical location with latitude and longitude
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FieldError reports a request field that is missing or carries an invalid value
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+// Error joins the field name and message, e.g. "ride_id is required"
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s %s", e.Field, e.Message)
+}
+
+// requiredField reports a missing required field
+func requiredField(field string) *FieldError {
+	return &FieldError{Field: field, Message: "is required"}
+}
+
+// validateCoordinates checks that a required location is present and within valid ranges
+func validateCoordinates(field string, latitude, longitude float64) error {
+	if latitude == 0 && longitude == 0 {
+		return requiredField(field)
+	}
+	if latitude < -90 || latitude > 90 {
+		return &FieldError{Field: field, Message: "latitude must be between -90 and 90"}
+	}
+	if longitude < -180 || longitude > 180 {
+		return &FieldError{Field: field, Message: "longitude must be between -180 and 180"}
+	}
+	return nil
+}
+
+// SanitizeText cleans a user-supplied free-text field before it is stored, logged or shown. Tabs
+// and line breaks become spaces, other control and invisible formatting characters are stripped,
+// and surrounding whitespace is trimmed. The cleaned text is rejected when it runs longer than
+// maxLength characters.
+func SanitizeText(field, value string, maxLength int) (string, error) {
+	cleaned := strings.TrimSpace(strings.Map(func(r rune) rune {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			return ' '
+		case unicode.IsControl(r) || unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, value))
+
+	if utf8.RuneCountInString(cleaned) > maxLength {
+		return "", &FieldError{Field: field, Message: fmt.Sprintf("must be at most %d characters", maxLength)}
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,83 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizeText(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		expected string
+	}{
+		{name: "plain text is kept", value: "Budi Santoso", expected: "Budi Santoso"},
+		{name: "surrounding whitespace is trimmed", value: "  Budi  ", expected: "Budi"},
+		{name: "line breaks and tabs become spaces", value: "Budi\r\nSantoso\tJr", expected: "Budi  Santoso Jr"},
+		{name: "control characters are stripped", value: "Bu\x00di\x1b[31m", expected: "Budi[31m"},
+		{name: "invisible formatting characters are stripped", value: "Budi‮osotnaS​", expected: "BudiosotnaS"},
+		{name: "non-latin text is kept", value: "Ñoño 李", expected: "Ñoño 李"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cleaned, err := SanitizeText("fullname", tt.value, 100)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, cleaned)
+		})
+	}
+}
+
+func TestSanitizeText_TooLong(t *testing.T) {
+	_, err := SanitizeText("fullname", strings.Repeat("a", 11), 10)
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "fullname", fieldErr.Field)
+	assert.Equal(t, "must be at most 10 characters", fieldErr.Message)
+}
+
+func TestSanitizeText_CountsCharactersNotBytes(t *testing.T) {
+	// Ten multi-byte characters fit a ten character limit
+	cleaned, err := SanitizeText("label", strings.Repeat("é", 10), 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, strings.Repeat("é", 10), cleaned)
+}
+
+func TestSanitizeText_StrippedCharactersDontCount(t *testing.T) {
+	cleaned, err := SanitizeText("label", "Home\x00\x00\x00", 4)
+
+	require.NoError(t, err)
+	assert.Equal(t, "Home", cleaned)
+}
+
+func TestMatchConfirmRequest_ValidateReason(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     MatchConfirmRequest
+		wantErr string
+	}{
+		{name: "No reason", req: MatchConfirmRequest{Status: string(MatchStatusRejected)}},
+		{name: "Known reason when rejecting", req: MatchConfirmRequest{Status: string(MatchStatusRejected), Reason: RejectionReasonWrongDirection}},
+		{name: "Unknown reason", req: MatchConfirmRequest{Status: string(MatchStatusRejected), Reason: "TOO_HOT"}, wantErr: "reason must be one of TOO_FAR, WRONG_DIRECTION, UNDESIRABLE_DESTINATION"},
+		{name: "Reason when accepting", req: MatchConfirmRequest{Status: string(MatchStatusAccepted), Reason: RejectionReasonTooFar}, wantErr: "reason is only allowed when rejecting"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.ValidateReason()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var fieldErr *FieldError
+			assert.ErrorAs(t, err, &fieldErr)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
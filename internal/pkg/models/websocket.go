@@ -2,6 +2,10 @@ package models
 
 import (
 	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -25,3 +29,173 @@ type WSErrorMessage struct {
 	Code    string `json:"code"`
 	Message string `json:"message"`
 }
+
+// ChatMessage is a message exchanged between the driver and passenger of an active ride
+type ChatMessage struct {
+	RideID      string    `json:"ride_id"`
+	SenderID    string    `json:"sender_id"`
+	RecipientID string    `json:"recipient_id"`
+	Message     string    `json:"message"`
+	SentAt      time.Time `json:"sent_at"`
+}
+
+// ChatHistoryRequest asks for the recent chat messages of an active ride
+type ChatHistoryRequest struct {
+	RideID string `json:"ride_id"`
+}
+
+// maxAnnouncementLength caps the text of a system announcement
+const maxAnnouncementLength = 1000
+
+// AnnouncementRoles are the user roles an announcement can be limited to
+var AnnouncementRoles = []string{"driver", "passenger"}
+
+// AnnouncementRequest is an operator's request to broadcast an announcement. Without roles it
+// goes to every connected user.
+type AnnouncementRequest struct {
+	Message string   `json:"message"`
+	Roles   []string `json:"roles,omitempty"`
+}
+
+// Announcement is a system announcement sent to connected users
+type Announcement struct {
+	ID        string    `json:"id"`
+	Message   string    `json:"message"`
+	Roles     []string  `json:"roles,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Validate sanitizes the message, which is required, and accepts only known roles
+func (r *AnnouncementRequest) Validate() error {
+	message, err := SanitizeText("message", r.Message, maxAnnouncementLength)
+	if err != nil {
+		return err
+	}
+	if message == "" {
+		return requiredField("message")
+	}
+	r.Message = message
+	for _, role := range r.Roles {
+		if !slices.Contains(AnnouncementRoles, role) {
+			return &FieldError{Field: "roles", Message: "must be one of " + strings.Join(AnnouncementRoles, ", ")}
+		}
+	}
+	return nil
+}
+
+// WSCommand is an inbound WebSocket command payload, selected by the message event
+type WSCommand interface {
+	// Validate rejects payloads that are missing required fields or carry invalid values
+	Validate() error
+}
+
+// Validate requires the MSISDN and, when going active, the driver's location
+func (r *BeaconRequest) Validate() error {
+	if r.MSISDN == "" {
+		return requiredField("msisdn")
+	}
+	if r.IsActive {
+		return validateCoordinates("location", r.Latitude, r.Longitude)
+	}
+	return nil
+}
+
+// Validate requires the MSISDN and, when starting a search, both pickup and target locations.
+// A target favorite stands in for the target location, which is filled in from it later.
+func (r *FinderRequest) Validate() error {
+	if r.MSISDN == "" {
+		return requiredField("msisdn")
+	}
+	if !r.IsActive {
+		return nil
+	}
+	if err := validateCoordinates("location", r.Location.Latitude, r.Location.Longitude); err != nil {
+		return err
+	}
+	if r.ScheduledAt != nil && !r.ScheduledAt.After(time.Now()) {
+		return &FieldError{Field: "scheduled_at", Message: "must be in the future"}
+	}
+	if err := ValidateGender("driver_gender", r.DriverGender); err != nil {
+		return err
+	}
+	if r.TargetFavoriteID != "" {
+		return nil
+	}
+	return validateCoordinates("target_location", r.TargetLocation.Latitude, r.TargetLocation.Longitude)
+}
+
+// Validate requires the match ID and an accept or reject decision, with a known reason if one is given
+func (r *MatchConfirmRequest) Validate() error {
+	if r.ID == "" {
+		return requiredField("match_id")
+	}
+	if r.Status != string(MatchStatusAccepted) && r.Status != string(MatchStatusRejected) {
+		return fmt.Errorf("invalid match status: %s", r.Status)
+	}
+	return r.ValidateReason()
+}
+
+// ValidateReason checks that a reason is only given when rejecting and is a known reason code
+func (r *MatchConfirmRequest) ValidateReason() error {
+	if r.Reason == "" {
+		return nil
+	}
+	if r.Status != string(MatchStatusRejected) {
+		return &FieldError{Field: "reason", Message: "is only allowed when rejecting"}
+	}
+	if !r.Reason.IsValid() {
+		reasons := make([]string, len(MatchRejectionReasons))
+		for i, reason := range MatchRejectionReasons {
+			reasons[i] = string(reason)
+		}
+		return &FieldError{Field: "reason", Message: "must be one of " + strings.Join(reasons, ", ")}
+	}
+	return nil
+}
+
+// Validate requires the ride ID and the current location
+func (r *LocationUpdate) Validate() error {
+	if r.RideID == "" {
+		return requiredField("ride_id")
+	}
+	return validateCoordinates("location", r.Location.Latitude, r.Location.Longitude)
+}
+
+// Validate requires the ride ID and both participants' locations
+func (r *RideStartRequest) Validate() error {
+	if r.RideID == "" {
+		return requiredField("ride_id")
+	}
+	if r.DriverLocation == nil {
+		return requiredField("driver_location")
+	}
+	if err := validateCoordinates("driver_location", r.DriverLocation.Latitude, r.DriverLocation.Longitude); err != nil {
+		return err
+	}
+	if r.PassengerLocation == nil {
+		return requiredField("passenger_location")
+	}
+	return validateCoordinates("passenger_location", r.PassengerLocation.Latitude, r.PassengerLocation.Longitude)
+}
+
+// Validate requires the ride ID and an adjustment factor between 0 and 1
+func (r *RideArrivalReq) Validate() error {
+	if r.RideID == "" {
+		return requiredField("ride_id")
+	}
+	if r.AdjustmentFactor < 0 || r.AdjustmentFactor > 1 {
+		return &FieldError{Field: "adjustment_factor", Message: "must be between 0 and 1"}
+	}
+	return nil
+}
+
+// Validate requires the ride ID and an accept or reject decision
+func (r *PaymentProccessRequest) Validate() error {
+	if r.RideID == "" {
+		return requiredField("ride_id")
+	}
+	if r.Status != PaymentStatusAccepted && r.Status != PaymentStatusRejected {
+		return fmt.Errorf("invalid payment status: %s", r.Status)
+	}
+	return nil
+}
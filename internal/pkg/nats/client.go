@@ -2,14 +2,33 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
+// ErrAckDeferred is returned by a message handler that settles the message itself once its work is
+// done, so the consumer neither acks nor naks it
+var ErrAckDeferred = errors.New("acknowledgement deferred to the handler")
+
+// Settle acks a message whose processing was deferred, or naks it for redelivery when it failed
+func Settle(msg jetstream.Msg, err error) {
+	if err != nil {
+		if nakErr := msg.Nak(); nakErr != nil {
+			logger.Error("Failed to NAK message", logger.Err(nakErr))
+		}
+		return
+	}
+	if ackErr := msg.Ack(); ackErr != nil {
+		logger.Error("Failed to ACK message", logger.Err(ackErr))
+	}
+}
+
 // StreamConfig defines configuration for a JetStream stream
 type StreamConfig struct {
 	Name      string
@@ -36,6 +55,11 @@ type ConsumerConfig struct {
 	ReplayPolicy  jetstream.ReplayPolicy
 	RateLimitBps  uint64
 	MaxAckPending int
+	// MaxConcurrency bounds how many messages are handled at once; zero or one handles them in order
+	MaxConcurrency int
+	// Unordered marks consumers whose messages may be handled out of delivery order, so the
+	// client's configured consumer concurrency applies when MaxConcurrency is unset
+	Unordered bool
 }
 
 // PublishOptions defines options for publishing messages
@@ -50,23 +74,29 @@ type PublishOptions struct {
 
 // Client represents a JetStream-enabled NATS client
 type Client struct {
-	conn       *nats.Conn
-	js         jetstream.JetStream
-	ctx        context.Context
-	streams    map[string]jetstream.Stream
-	consumers  map[string]jetstream.Consumer
-	cancelFunc context.CancelFunc
+	conn      *nats.Conn
+	js        jetstream.JetStream
+	ctx       context.Context
+	streams   map[string]jetstream.Stream
+	consumers map[string]jetstream.Consumer
+	// concurrency holds how many messages each consumer handles at once, keyed like consumers
+	concurrency map[string]int
+	// unorderedConcurrency is the concurrency of Unordered consumers without their own MaxConcurrency
+	unorderedConcurrency int
+	cancelFunc           context.CancelFunc
+	// reconnectWait bounds how long a publish waits for a dropped connection to come back
+	reconnectWait time.Duration
+	// status overrides conn.Status, letting tests simulate disconnects
+	status func() nats.Status
 }
 
-// NewClient creates a new JetStream-enabled NATS client
-func NewClient(url string) (*Client, error) {
+// NewClient creates a new JetStream-enabled NATS client; cfg.Streams adjusts the
+// limits of the default streams and may be nil
+func NewClient(cfg models.NATSConfig) (*Client, error) {
 	// Connect to NATS server with JetStream options
-	opts := []nats.Option{
-		nats.ReconnectWait(2 * time.Second),
-		nats.MaxReconnects(-1), // Unlimited reconnects
-		nats.PingInterval(30 * time.Second),
+	opts := append(reconnectOptions(cfg),
+		nats.PingInterval(30*time.Second),
 		nats.MaxPingsOutstanding(3),
-		nats.ReconnectBufSize(5 * 1024 * 1024), // 5MB buffer
 		nats.DisconnectErrHandler(func(nc *nats.Conn, err error) {
 			if err != nil {
 				logger.Error("NATS disconnected", logger.Err(err))
@@ -78,9 +108,9 @@ func NewClient(url string) (*Client, error) {
 		nats.ClosedHandler(func(nc *nats.Conn) {
 			logger.Info("NATS connection closed")
 		}),
-	}
+	)
 
-	conn, err := nats.Connect(url, opts...)
+	conn, err := nats.Connect(cfg.URL, opts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to NATS server: %w", err)
 	}
@@ -95,16 +125,19 @@ func NewClient(url string) (*Client, error) {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	client := &Client{
-		conn:       conn,
-		js:         js,
-		ctx:        ctx,
-		streams:    make(map[string]jetstream.Stream),
-		consumers:  make(map[string]jetstream.Consumer),
-		cancelFunc: cancel,
+		conn:                 conn,
+		js:                   js,
+		ctx:                  ctx,
+		streams:              make(map[string]jetstream.Stream),
+		consumers:            make(map[string]jetstream.Consumer),
+		concurrency:          make(map[string]int),
+		unorderedConcurrency: cfg.ConsumerMaxConcurrency,
+		cancelFunc:           cancel,
+		reconnectWait:        publishReconnectWait(cfg),
 	}
 
 	// Initialize default streams for the ride-sharing system
-	if err := client.initializeDefaultStreams(); err != nil {
+	if err := client.initializeDefaultStreams(cfg.Streams); err != nil {
 		client.Close()
 		return nil, fmt.Errorf("failed to initialize default streams: %w", err)
 	}
@@ -113,9 +146,9 @@ func NewClient(url string) (*Client, error) {
 }
 
 // initializeDefaultStreams creates the default streams for the ride-sharing system
-func (c *Client) initializeDefaultStreams() error {
+func (c *Client) initializeDefaultStreams(streamOverrides map[string]models.NATSStreamConfig) error {
 	// Use the centralized stream configurations that support dual consumption
-	defaultStreams := DefaultStreamConfigs()
+	defaultStreams := ApplyStreamOverrides(DefaultStreamConfigs(), streamOverrides)
 
 	for _, streamConfig := range defaultStreams {
 		if err := c.CreateOrUpdateStream(streamConfig); err != nil {
@@ -128,7 +161,25 @@ func (c *Client) initializeDefaultStreams() error {
 
 // CreateOrUpdateStream creates or updates a JetStream stream
 func (c *Client) CreateOrUpdateStream(config StreamConfig) error {
-	streamConfig := jetstream.StreamConfig{
+	stream, err := c.js.CreateOrUpdateStream(c.ctx, toJetStreamConfig(config))
+	if err != nil {
+		return fmt.Errorf("failed to create/update stream: %w", err)
+	}
+
+	c.streams[config.Name] = stream
+	logger.Info("Stream created/updated successfully",
+		logger.String("stream", config.Name),
+		logger.Strings("subjects", config.Subjects),
+		logger.String("max_age", config.MaxAge.String()),
+		logger.Int64("max_bytes", config.MaxBytes),
+		logger.String("retention", config.Retention.String()))
+
+	return nil
+}
+
+// toJetStreamConfig converts a StreamConfig into the JetStream stream configuration
+func toJetStreamConfig(config StreamConfig) jetstream.StreamConfig {
+	return jetstream.StreamConfig{
 		Name:       config.Name,
 		Subjects:   config.Subjects,
 		Retention:  config.Retention,
@@ -141,18 +192,6 @@ func (c *Client) CreateOrUpdateStream(config StreamConfig) error {
 		NoAck:      false,
 		Duplicates: 5 * time.Minute, // Duplicate detection window
 	}
-
-	stream, err := c.js.CreateOrUpdateStream(c.ctx, streamConfig)
-	if err != nil {
-		return fmt.Errorf("failed to create/update stream: %w", err)
-	}
-
-	c.streams[config.Name] = stream
-	logger.Info("Stream created/updated successfully",
-		logger.String("stream", config.Name),
-		logger.Strings("subjects", config.Subjects))
-
-	return nil
 }
 
 // CreateConsumer creates a durable consumer for a stream
@@ -181,12 +220,14 @@ func (c *Client) CreateConsumer(config ConsumerConfig) error {
 
 	consumerKey := fmt.Sprintf("%s:%s", config.StreamName, config.ConsumerName)
 	c.consumers[consumerKey] = consumer
+	c.concurrency[consumerKey] = consumerConcurrency(config, c.unorderedConcurrency)
 
 	logger.Info("Consumer created successfully",
 		logger.String("stream", config.StreamName),
 		logger.String("consumer", config.ConsumerName),
 		logger.String("subject", config.FilterSubject),
-		logger.String("deliver_policy", fmt.Sprintf("%v", config.DeliverPolicy)))
+		logger.String("deliver_policy", fmt.Sprintf("%v", config.DeliverPolicy)),
+		logger.Int("max_concurrency", c.concurrency[consumerKey]))
 
 	return nil
 }
@@ -227,7 +268,9 @@ func (c *Client) Publish(subject string, data []byte) error {
 	return c.PublishWithOptions(opts)
 }
 
-// PublishWithOptions publishes a message with custom options
+// PublishWithOptions publishes a message with custom options. During a brief disconnect it waits
+// for the client to reconnect and retries, returning ErrNotConnected if the connection stays down;
+// set MsgID so a retried message that did reach the stream is deduplicated.
 func (c *Client) PublishWithOptions(opts PublishOptions) error {
 	ctx := c.ctx
 	if opts.Timeout > 0 {
@@ -252,7 +295,12 @@ func (c *Client) PublishWithOptions(opts PublishOptions) error {
 		Header:  opts.Headers,
 	}
 
-	ack, err := c.js.PublishMsg(ctx, msg, pubOpts...)
+	var ack *jetstream.PubAck
+	err := c.publishWithReconnect(ctx, opts.Subject, func(ctx context.Context) error {
+		var err error
+		ack, err = c.js.PublishMsg(ctx, msg, pubOpts...)
+		return err
+	})
 	if err != nil {
 		return fmt.Errorf("failed to publish message to subject %s: %w", opts.Subject, err)
 	}
@@ -313,8 +361,12 @@ func (c *Client) ConsumeMessages(streamName, consumerName string, handler func(j
 	}
 
 	// Create a consume context
-	consumeCtx, err := consumer.Consume(func(msg jetstream.Msg) {
-		if err := handler(msg); err != nil {
+	consumeCtx, err := consumer.Consume(boundedHandler(c.concurrency[consumerKey], func(msg jetstream.Msg) {
+		err := handler(msg)
+		if errors.Is(err, ErrAckDeferred) {
+			return
+		}
+		if err != nil {
 			logger.Error("Error processing message",
 				logger.String("consumer", consumerKey),
 				logger.String("subject", msg.Subject()),
@@ -331,7 +383,7 @@ func (c *Client) ConsumeMessages(streamName, consumerName string, handler func(j
 		if ackErr := msg.Ack(); ackErr != nil {
 			logger.Error("Failed to ACK message", logger.Err(ackErr))
 		}
-	})
+	}))
 
 	if err != nil {
 		return fmt.Errorf("failed to start consuming: %w", err)
@@ -350,6 +402,38 @@ func (c *Client) ConsumeMessages(streamName, consumerName string, handler func(j
 	return nil
 }
 
+// consumerConcurrency returns how many of a consumer's messages are handled at once: its own
+// MaxConcurrency when set, otherwise unordered for consumers that don't rely on delivery order.
+// It never exceeds MaxAckPending, past which the server stops delivering.
+func consumerConcurrency(config ConsumerConfig, unordered int) int {
+	limit := config.MaxConcurrency
+	if limit == 0 && config.Unordered {
+		limit = unordered
+	}
+	if config.MaxAckPending > 0 && limit > config.MaxAckPending {
+		limit = config.MaxAckPending
+	}
+	return limit
+}
+
+// boundedHandler runs process for up to limit messages at once. While every worker is busy the
+// returned callback blocks, so the consumer stops pulling messages until one finishes.
+// A limit of one or less processes messages inline, in delivery order.
+func boundedHandler(limit int, process func(jetstream.Msg)) func(jetstream.Msg) {
+	if limit <= 1 {
+		return process
+	}
+
+	sem := make(chan struct{}, limit)
+	return func(msg jetstream.Msg) {
+		sem <- struct{}{}
+		go func() {
+			defer func() { <-sem }()
+			process(msg)
+		}()
+	}
+}
+
 // Request sends a request and waits for a response (maintained for compatibility)
 func (c *Client) Request(subject string, data []byte) (*nats.Msg, error) {
 	msg, err := c.conn.Request(subject, data, 10*time.Second)
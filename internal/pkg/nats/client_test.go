@@ -0,0 +1,79 @@
+package nats
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedHandler_LimitsConcurrency(t *testing.T) {
+	const limit = 3
+	const messages = 20
+
+	var running, peak int32
+	var wg sync.WaitGroup
+	wg.Add(messages)
+
+	handler := boundedHandler(limit, func(msg jetstream.Msg) {
+		defer wg.Done()
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+	})
+
+	// The consume loop calls the handler serially; it must block rather than start a new worker
+	for i := 0; i < messages; i++ {
+		handler(&mockMsg{})
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(limit), atomic.LoadInt32(&peak))
+}
+
+func TestBoundedHandler_SerialByDefault(t *testing.T) {
+	var order []int
+	for _, limit := range []int{0, 1} {
+		order = order[:0]
+		handler := boundedHandler(limit, func(msg jetstream.Msg) {
+			order = append(order, len(order))
+		})
+
+		for i := 0; i < 3; i++ {
+			handler(&mockMsg{})
+		}
+
+		// Inline processing has finished every message by the time the handler returns
+		assert.Equal(t, []int{0, 1, 2}, order)
+	}
+}
+
+func TestConsumerConcurrency(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    ConsumerConfig
+		unordered int
+		want      int
+	}{
+		{name: "ordered consumer ignores the configured concurrency", config: ConsumerConfig{}, unordered: 8, want: 0},
+		{name: "unordered consumer takes the configured concurrency", config: ConsumerConfig{Unordered: true}, unordered: 8, want: 8},
+		{name: "own concurrency wins", config: ConsumerConfig{Unordered: true, MaxConcurrency: 2}, unordered: 8, want: 2},
+		{name: "capped by max ack pending", config: ConsumerConfig{Unordered: true, MaxAckPending: 4}, unordered: 8, want: 4},
+		{name: "unset configuration stays serial", config: ConsumerConfig{Unordered: true}, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, consumerConcurrency(tt.config, tt.unordered))
+		})
+	}
+}
@@ -2,6 +2,7 @@ package nats
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -26,6 +27,8 @@ type Consumer struct {
 	ctx          context.Context
 	cancelFunc   context.CancelFunc
 	isJetStream  bool
+	// maxConcurrency bounds how many JetStream messages are handled at once
+	maxConcurrency int
 }
 
 // NewConsumer creates a new NATS consumer for a topic/channel (legacy compatibility)
@@ -108,6 +111,7 @@ func NewJetStreamConsumer(client *Client, config ConsumerConfig, handler JetStre
 	}
 
 	// Start consuming messages
+	jsConsumer.maxConcurrency = client.concurrency[consumerKey]
 	if err := jsConsumer.startConsuming(handler); err != nil {
 		cancel()
 		return nil, fmt.Errorf("failed to start consuming: %w", err)
@@ -156,8 +160,12 @@ func (c *Consumer) startConsuming(handler JetStreamMessageHandler) error {
 		return fmt.Errorf("not a JetStream consumer")
 	}
 
-	consumeCtx, err := c.consumer.Consume(func(msg jetstream.Msg) {
-		if err := handler(msg); err != nil {
+	consumeCtx, err := c.consumer.Consume(boundedHandler(c.maxConcurrency, func(msg jetstream.Msg) {
+		err := handler(msg)
+		if errors.Is(err, ErrAckDeferred) {
+			return
+		}
+		if err != nil {
 			logger.Error("Error processing JetStream message",
 				logger.String("subject", msg.Subject()),
 				logger.Err(err))
@@ -173,7 +181,7 @@ func (c *Consumer) startConsuming(handler JetStreamMessageHandler) error {
 		if ackErr := msg.Ack(); ackErr != nil {
 			logger.Error("Failed to ACK message", logger.Err(ackErr))
 		}
-	})
+	}))
 
 	if err != nil {
 		return fmt.Errorf("failed to start consuming: %w", err)
@@ -1,9 +1,12 @@
 package nats
 
 import (
+	"strings"
 	"time"
 
 	"github.com/nats-io/nats.go/jetstream"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
 // StreamConfigBuilder helps build stream configurations
@@ -149,6 +152,18 @@ func (b *ConsumerConfigBuilder) WithMaxAckPending(maxAckPending int) *ConsumerCo
 	return b
 }
 
+// WithMaxConcurrency sets how many messages the consumer handles at once
+func (b *ConsumerConfigBuilder) WithMaxConcurrency(maxConcurrency int) *ConsumerConfigBuilder {
+	b.config.MaxConcurrency = maxConcurrency
+	return b
+}
+
+// WithUnordered marks the consumer's messages as safe to handle out of delivery order
+func (b *ConsumerConfigBuilder) WithUnordered() *ConsumerConfigBuilder {
+	b.config.Unordered = true
+	return b
+}
+
 // Build returns the consumer configuration
 func (b *ConsumerConfigBuilder) Build() ConsumerConfig {
 	return b.config
@@ -167,7 +182,7 @@ func DefaultStreamConfigs() []StreamConfig {
 			Build(),
 
 		NewStreamConfigBuilder("MATCH_STREAM").
-			WithSubjects("match.found", "match.rejected", "match.accepted").
+			WithSubjects("match.found", "match.rejected", "match.accepted", "match.no_drivers", "match.acceptances", "match.proposals", "match.maintenance").
 			WithRetention(jetstream.InterestPolicy). // Use InterestPolicy for dual consumption
 			WithStorage(jetstream.FileStorage).
 			WithMaxAge(1 * time.Hour).
@@ -176,7 +191,7 @@ func DefaultStreamConfigs() []StreamConfig {
 			Build(),
 
 		NewStreamConfigBuilder("RIDE_STREAM").
-			WithSubjects("ride.pickup", "ride.started", "ride.arrived", "ride.completed").
+			WithSubjects("ride.pickup", "ride.pickup_eta", "ride.started", "ride.arrived", "ride.completed", "ride.cancelled", "ride.billing").
 			WithRetention(jetstream.LimitsPolicy).
 			WithStorage(jetstream.FileStorage).
 			WithMaxAge(7 * 24 * time.Hour). // 7 days for audit
@@ -185,7 +200,7 @@ func DefaultStreamConfigs() []StreamConfig {
 			Build(),
 
 		NewStreamConfigBuilder("LOCATION_STREAM").
-			WithSubjects("location.update", "location.aggregate").
+			WithSubjects("location.update", "location.aggregate", "location.driver_evicted").
 			WithRetention(jetstream.InterestPolicy).
 			WithStorage(jetstream.MemoryStorage). // Fast access for location data
 			WithMaxAge(2 * time.Hour).
@@ -195,7 +210,52 @@ func DefaultStreamConfigs() []StreamConfig {
 	}
 }
 
-// DefaultConsumerConfigs returns common consumer configurations with service-specific naming
+// ApplyStreamOverrides applies configured limits to the matching stream configurations.
+// Zero values and unknown retention policies keep the stream's defaults.
+func ApplyStreamOverrides(streams []StreamConfig, overrides map[string]models.NATSStreamConfig) []StreamConfig {
+	for i, stream := range streams {
+		override, ok := overrides[stream.Name]
+		if !ok {
+			continue
+		}
+
+		if override.MaxAgeMinutes > 0 {
+			streams[i].MaxAge = time.Duration(override.MaxAgeMinutes) * time.Minute
+		}
+		if override.MaxBytes > 0 {
+			streams[i].MaxBytes = override.MaxBytes
+		}
+		if override.Retention != "" {
+			if retention, ok := parseRetentionPolicy(override.Retention); ok {
+				streams[i].Retention = retention
+			} else {
+				logger.Warn("Unknown stream retention policy, keeping default",
+					logger.String("stream", stream.Name),
+					logger.String("retention", override.Retention))
+			}
+		}
+	}
+	return streams
+}
+
+// parseRetentionPolicy maps a configured retention name to the JetStream policy
+func parseRetentionPolicy(name string) (jetstream.RetentionPolicy, bool) {
+	switch strings.ToLower(name) {
+	case "limits":
+		return jetstream.LimitsPolicy, true
+	case "interest":
+		return jetstream.InterestPolicy, true
+	case "workqueue":
+		return jetstream.WorkQueuePolicy, true
+	default:
+		return jetstream.LimitsPolicy, false
+	}
+}
+
+// DefaultConsumerConfigs returns common consumer configurations with service-specific naming.
+// Beacon, finder and location update consumers keep handling one message at a time: concurrent
+// handlers would apply a user's toggles and positions out of order. Consumers that only forward
+// independent notifications are unordered, so NATS_CONSUMER_MAX_CONCURRENCY applies to them.
 func DefaultConsumerConfigs() map[string]ConsumerConfig {
 	return map[string]ConsumerConfig{
 		// USER_STREAM consumers - user.beacon (dual consumption: users + match)
@@ -234,6 +294,7 @@ func DefaultConsumerConfigs() map[string]ConsumerConfig {
 			WithDeliverPolicy(jetstream.DeliverAllPolicy).
 			WithAckPolicy(jetstream.AckExplicitPolicy).
 			WithMaxDeliver(5). // Higher retry for critical match events
+			WithUnordered().   // Each proposal is notified on its own
 			Build(),
 
 		// MATCH_STREAM consumers - match.accepted (dual consumption: users + rides)
@@ -259,6 +320,40 @@ func DefaultConsumerConfigs() map[string]ConsumerConfig {
 			WithMaxDeliver(3).
 			Build(),
 
+		// MATCH_STREAM consumers - match.no_drivers (single consumption: users)
+		"match_no_drivers_users": NewConsumerConfigBuilder("MATCH_STREAM", "match_no_drivers_users").
+			WithSubject("match.no_drivers").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy). // Stale "no drivers" notices are useless
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(3).
+			WithUnordered().
+			Build(),
+
+		// MATCH_STREAM consumers - match.maintenance (single consumption: users)
+		"match_maintenance_users": NewConsumerConfigBuilder("MATCH_STREAM", "match_maintenance_users").
+			WithSubject("match.maintenance").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy). // Stale maintenance notices are useless
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(3).
+			WithUnordered().
+			Build(),
+
+		// MATCH_STREAM consumers - match.acceptances (single consumption: users)
+		"match_acceptances_users": NewConsumerConfigBuilder("MATCH_STREAM", "match_acceptances_users").
+			WithSubject("match.acceptances").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy). // Options go stale once the passenger chooses
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(3).
+			Build(),
+
+		// MATCH_STREAM consumers - match.proposals (single consumption: users)
+		"match_proposals_users": NewConsumerConfigBuilder("MATCH_STREAM", "match_proposals_users").
+			WithSubject("match.proposals").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy). // A re-ranked list is superseded by the next one
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(2).
+			Build(),
+
 		// RIDE_STREAM consumers - ride.pickup (dual consumption: users + match)
 		"ride_pickup_users": NewConsumerConfigBuilder("RIDE_STREAM", "ride_pickup_users").
 			WithSubject("ride.pickup").
@@ -274,6 +369,14 @@ func DefaultConsumerConfigs() map[string]ConsumerConfig {
 			WithMaxDeliver(5).
 			Build(),
 
+		// RIDE_STREAM consumers - ride.pickup_eta (single consumption: users)
+		"ride_pickup_eta_users": NewConsumerConfigBuilder("RIDE_STREAM", "ride_pickup_eta_users").
+			WithSubject("ride.pickup_eta").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy). // A stale ETA is superseded by the next one
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(2).
+			Build(),
+
 		// RIDE_STREAM consumers - ride.started (single consumption: users)
 		"ride_started_users": NewConsumerConfigBuilder("RIDE_STREAM", "ride_started_users").
 			WithSubject("ride.started").
@@ -282,6 +385,14 @@ func DefaultConsumerConfigs() map[string]ConsumerConfig {
 			WithMaxDeliver(5).
 			Build(),
 
+		// RIDE_STREAM consumers - ride.arrived (single consumption: users)
+		"ride_arrived_users": NewConsumerConfigBuilder("RIDE_STREAM", "ride_arrived_users").
+			WithSubject("ride.arrived").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy).
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(3).
+			Build(),
+
 		// RIDE_STREAM consumers - ride.completed (dual consumption: users + match)
 		"ride_completed_users": NewConsumerConfigBuilder("RIDE_STREAM", "ride_completed_users").
 			WithSubject("ride.completed").
@@ -297,7 +408,30 @@ func DefaultConsumerConfigs() map[string]ConsumerConfig {
 			WithMaxDeliver(3).
 			Build(),
 
-		// LOCATION_STREAM consumers - location.update (single consumption: location)
+		// RIDE_STREAM consumers - ride.cancelled (dual consumption: users + match)
+		"ride_cancelled_users": NewConsumerConfigBuilder("RIDE_STREAM", "ride_cancelled_users").
+			WithSubject("ride.cancelled").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy).
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(3).
+			Build(),
+
+		"ride_cancelled_match": NewConsumerConfigBuilder("RIDE_STREAM", "ride_cancelled_match").
+			WithSubject("ride.cancelled").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy).
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(3).
+			Build(),
+
+		// RIDE_STREAM consumers - ride.billing (single consumption: users)
+		"ride_billing_users": NewConsumerConfigBuilder("RIDE_STREAM", "ride_billing_users").
+			WithSubject("ride.billing").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy). // A stale total is superseded by the next one
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(2).
+			Build(),
+
+		// LOCATION_STREAM consumers - location.update (location keeps the ride track)
 		"location_update_location": NewConsumerConfigBuilder("LOCATION_STREAM", "location_update_location").
 			WithSubject("location.update").
 			WithDeliverPolicy(jetstream.DeliverNewPolicy). // Only new location updates
@@ -305,6 +439,14 @@ func DefaultConsumerConfigs() map[string]ConsumerConfig {
 			WithMaxDeliver(2). // Fast fail for location updates
 			Build(),
 
+		// LOCATION_STREAM consumers - location.update (match re-ranks proposals of drivers on the move)
+		"location_update_match": NewConsumerConfigBuilder("LOCATION_STREAM", "location_update_match").
+			WithSubject("location.update").
+			WithDeliverPolicy(jetstream.DeliverNewPolicy). // Only the current position matters
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(1). // The next update supersedes a failed one
+			Build(),
+
 		// LOCATION_STREAM consumers - location.aggregate (single consumption: rides)
 		"location_aggregate_rides": NewConsumerConfigBuilder("LOCATION_STREAM", "location_aggregate_rides").
 			WithSubject("location.aggregate").
@@ -312,6 +454,15 @@ func DefaultConsumerConfigs() map[string]ConsumerConfig {
 			WithAckPolicy(jetstream.AckExplicitPolicy).
 			WithMaxDeliver(3).
 			Build(),
+
+		// LOCATION_STREAM consumers - location.driver_evicted (single consumption: users)
+		"location_driver_evicted_users": NewConsumerConfigBuilder("LOCATION_STREAM", "location_driver_evicted_users").
+			WithSubject("location.driver_evicted").
+			WithDeliverPolicy(jetstream.DeliverAllPolicy). // Each eviction closes an online session
+			WithAckPolicy(jetstream.AckExplicitPolicy).
+			WithMaxDeliver(3).
+			WithUnordered().
+			Build(),
 	}
 }
 
@@ -320,11 +471,11 @@ func GetStreamForSubject(subject string) string {
 	switch {
 	case subject == "user.beacon" || subject == "user.finder":
 		return "USER_STREAM"
-	case subject == "match.found" || subject == "match.rejected" || subject == "match.accepted":
+	case subject == "match.found" || subject == "match.rejected" || subject == "match.accepted" || subject == "match.no_drivers" || subject == "match.acceptances" || subject == "match.proposals" || subject == "match.maintenance":
 		return "MATCH_STREAM"
-	case subject == "ride.pickup" || subject == "ride.started" || subject == "ride.arrived" || subject == "ride.completed":
+	case subject == "ride.pickup" || subject == "ride.pickup_eta" || subject == "ride.started" || subject == "ride.arrived" || subject == "ride.completed" || subject == "ride.cancelled":
 		return "RIDE_STREAM"
-	case subject == "location.update" || subject == "location.aggregate":
+	case subject == "location.update" || subject == "location.aggregate" || subject == "location.driver_evicted":
 		return "LOCATION_STREAM"
 	default:
 		return ""
@@ -345,9 +496,17 @@ func CreateDefaultConsumersForService(client *Client, serviceName string) error
 			configs["match_found_users"],
 			configs["match_accepted_users"],
 			configs["match_rejected_users"],
+			configs["match_no_drivers_users"],
+			configs["match_maintenance_users"],
+			configs["match_acceptances_users"],
+			configs["match_proposals_users"],
 			configs["ride_pickup_users"],
+			configs["ride_pickup_eta_users"],
 			configs["ride_started_users"],
 			configs["ride_completed_users"],
+			configs["ride_cancelled_users"],
+			configs["ride_billing_users"],
+			configs["location_driver_evicted_users"],
 		)
 	case "match":
 		relevantConfigs = append(relevantConfigs,
@@ -355,6 +514,8 @@ func CreateDefaultConsumersForService(client *Client, serviceName string) error
 			configs["user_finder_match"],
 			configs["ride_pickup_match"],
 			configs["ride_completed_match"],
+			configs["ride_cancelled_match"],
+			configs["location_update_match"],
 		)
 	case "rides":
 		relevantConfigs = append(relevantConfigs,
@@ -0,0 +1,104 @@
+package nats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// findStream returns the stream configuration with the given name
+func findStream(t *testing.T, streams []StreamConfig, name string) StreamConfig {
+	for _, stream := range streams {
+		if stream.Name == name {
+			return stream
+		}
+	}
+	t.Fatalf("stream %s not found", name)
+	return StreamConfig{}
+}
+
+func TestApplyStreamOverrides(t *testing.T) {
+	t.Run("applies configured retention values", func(t *testing.T) {
+		overrides := map[string]models.NATSStreamConfig{
+			"USER_STREAM": {
+				MaxAgeMinutes: 90,
+				MaxBytes:      10 * 1024 * 1024,
+				Retention:     "limits",
+			},
+		}
+
+		streams := ApplyStreamOverrides(DefaultStreamConfigs(), overrides)
+
+		user := findStream(t, streams, "USER_STREAM")
+		assert.Equal(t, 90*time.Minute, user.MaxAge)
+		assert.Equal(t, int64(10*1024*1024), user.MaxBytes)
+		assert.Equal(t, jetstream.LimitsPolicy, user.Retention)
+
+		// The JetStream config sent on stream creation carries the same values
+		jsCfg := toJetStreamConfig(user)
+		assert.Equal(t, 90*time.Minute, jsCfg.MaxAge)
+		assert.Equal(t, int64(10*1024*1024), jsCfg.MaxBytes)
+		assert.Equal(t, jetstream.LimitsPolicy, jsCfg.Retention)
+	})
+
+	t.Run("keeps defaults when unset", func(t *testing.T) {
+		defaults := DefaultStreamConfigs()
+		streams := ApplyStreamOverrides(DefaultStreamConfigs(), nil)
+
+		require.Len(t, streams, len(defaults))
+		for i := range defaults {
+			assert.Equal(t, defaults[i], streams[i])
+		}
+	})
+
+	t.Run("zero values and unknown retention keep defaults", func(t *testing.T) {
+		overrides := map[string]models.NATSStreamConfig{
+			"RIDE_STREAM": {Retention: "forever"},
+		}
+
+		streams := ApplyStreamOverrides(DefaultStreamConfigs(), overrides)
+
+		ride := findStream(t, streams, "RIDE_STREAM")
+		assert.Equal(t, 7*24*time.Hour, ride.MaxAge)
+		assert.Equal(t, int64(200*1024*1024), ride.MaxBytes)
+		assert.Equal(t, jetstream.LimitsPolicy, ride.Retention)
+	})
+
+	t.Run("only the named stream changes", func(t *testing.T) {
+		overrides := map[string]models.NATSStreamConfig{
+			"MATCH_STREAM": {MaxAgeMinutes: 15, Retention: "workqueue"},
+		}
+
+		streams := ApplyStreamOverrides(DefaultStreamConfigs(), overrides)
+
+		match := findStream(t, streams, "MATCH_STREAM")
+		assert.Equal(t, 15*time.Minute, match.MaxAge)
+		assert.Equal(t, jetstream.WorkQueuePolicy, match.Retention)
+
+		location := findStream(t, streams, "LOCATION_STREAM")
+		assert.Equal(t, 2*time.Hour, location.MaxAge)
+		assert.Equal(t, jetstream.InterestPolicy, location.Retention)
+	})
+}
+
+func TestDefaultConsumerConfigs_MaxConcurrency(t *testing.T) {
+	configs := DefaultConsumerConfigs()
+
+	// Per-user event sequences must be applied in delivery order, whatever is configured
+	for _, name := range []string{"user_beacon_match", "user_finder_match", "location_update_location", "match_accepted_rides"} {
+		assert.LessOrEqual(t, consumerConcurrency(configs[name], 8), 1, name)
+	}
+
+	// Independent notifications are spread over the configured workers
+	for _, name := range []string{"match_found_users", "match_no_drivers_users", "match_maintenance_users", "location_driver_evicted_users"} {
+		assert.Equal(t, 8, consumerConcurrency(configs[name], 8), name)
+	}
+
+	for name, config := range configs {
+		assert.GreaterOrEqual(t, config.MaxAckPending, config.MaxConcurrency, name)
+	}
+}
@@ -0,0 +1,114 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	defaultMaxReconnects         = -1 // Unlimited reconnects
+	defaultReconnectWait         = 2 * time.Second
+	defaultReconnectBufferBytes  = 5 * 1024 * 1024 // 5MB buffer
+	defaultPublishReconnectWait  = 5 * time.Second
+	publishReconnectPollInterval = 50 * time.Millisecond
+	maxPublishReconnectAttempts  = 3
+)
+
+// ErrNotConnected is returned when a publish gives up waiting for a dropped connection to come
+// back; the message was not published and the caller may retry it later
+var ErrNotConnected = errors.New("nats connection unavailable, retry later")
+
+// reconnectOptions builds the connection options that control reconnecting after the server drops
+func reconnectOptions(cfg models.NATSConfig) []nats.Option {
+	maxReconnects := cfg.MaxReconnects
+	if maxReconnects == 0 {
+		maxReconnects = defaultMaxReconnects
+	}
+	wait := defaultReconnectWait
+	if cfg.ReconnectWaitMs > 0 {
+		wait = time.Duration(cfg.ReconnectWaitMs) * time.Millisecond
+	}
+	bufSize := defaultReconnectBufferBytes
+	if cfg.ReconnectBufferBytes > 0 {
+		bufSize = cfg.ReconnectBufferBytes
+	}
+
+	return []nats.Option{
+		nats.MaxReconnects(maxReconnects),
+		nats.ReconnectWait(wait),
+		nats.ReconnectBufSize(bufSize),
+	}
+}
+
+// publishReconnectWait returns how long a publish waits for the connection to come back
+func publishReconnectWait(cfg models.NATSConfig) time.Duration {
+	if cfg.PublishReconnectWaitMs > 0 {
+		return time.Duration(cfg.PublishReconnectWaitMs) * time.Millisecond
+	}
+	return defaultPublishReconnectWait
+}
+
+// connStatus reports the state of the underlying connection
+func (c *Client) connStatus() nats.Status {
+	if c.status != nil {
+		return c.status()
+	}
+	return c.conn.Status()
+}
+
+// awaitConnected blocks while the client is reconnecting, returning ErrNotConnected if the
+// connection does not come back within the publish reconnect wait or before ctx ends
+func (c *Client) awaitConnected(ctx context.Context) error {
+	deadline := time.NewTimer(c.reconnectWait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(publishReconnectPollInterval)
+	defer ticker.Stop()
+
+	for {
+		switch c.connStatus() {
+		case nats.CONNECTED:
+			return nil
+		case nats.CLOSED:
+			return nats.ErrConnectionClosed
+		}
+
+		select {
+		case <-ticker.C:
+		case <-deadline.C:
+			return ErrNotConnected
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %v", ErrNotConnected, ctx.Err())
+		}
+	}
+}
+
+// publishWithReconnect runs publish once the connection is up. A publish that fails because the
+// connection dropped mid-flight is retried after reconnecting; other failures are returned as is.
+func (c *Client) publishWithReconnect(ctx context.Context, subject string, publish func(context.Context) error) error {
+	var err error
+	for attempt := 1; attempt <= maxPublishReconnectAttempts; attempt++ {
+		if waitErr := c.awaitConnected(ctx); waitErr != nil {
+			if err != nil {
+				return fmt.Errorf("%w: %v", waitErr, err)
+			}
+			return waitErr
+		}
+
+		err = publish(ctx)
+		if err == nil || c.connStatus() == nats.CONNECTED {
+			return err
+		}
+
+		logger.Warn("Publish failed while NATS was disconnected, retrying after reconnect",
+			logger.String("subject", subject),
+			logger.Int("attempt", attempt),
+			logger.Err(err))
+	}
+	return fmt.Errorf("%w: %v", ErrNotConnected, err)
+}
@@ -0,0 +1,133 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeConn simulates the connection status seen by the client
+type fakeConn struct {
+	status atomic.Int32
+}
+
+func newFakeConn(status nats.Status) *fakeConn {
+	f := &fakeConn{}
+	f.set(status)
+	return f
+}
+
+func (f *fakeConn) set(status nats.Status) { f.status.Store(int32(status)) }
+func (f *fakeConn) get() nats.Status       { return nats.Status(f.status.Load()) }
+
+func newTestClient(conn *fakeConn, wait time.Duration) *Client {
+	return &Client{ctx: context.Background(), reconnectWait: wait, status: conn.get}
+}
+
+func TestPublishWithReconnect_WaitsForReconnect(t *testing.T) {
+	conn := newFakeConn(nats.RECONNECTING)
+	client := newTestClient(conn, time.Second)
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		conn.set(nats.CONNECTED)
+	}()
+
+	var calls int32
+	err := client.publishWithReconnect(context.Background(), "ride.started", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, nats.CONNECTED, conn.get())
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPublishWithReconnect_RetriesPublishDroppedMidFlight(t *testing.T) {
+	conn := newFakeConn(nats.CONNECTED)
+	client := newTestClient(conn, time.Second)
+
+	var calls int32
+	err := client.publishWithReconnect(context.Background(), "ride.started", func(ctx context.Context) error {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			// The server goes away while the first publish waits for its ack
+			conn.set(nats.RECONNECTING)
+			go func() {
+				time.Sleep(100 * time.Millisecond)
+				conn.set(nats.CONNECTED)
+			}()
+			return nats.ErrDisconnected
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func TestPublishWithReconnect_ReturnsRetryableErrorWhileDisconnected(t *testing.T) {
+	conn := newFakeConn(nats.RECONNECTING)
+	client := newTestClient(conn, 100*time.Millisecond)
+
+	called := false
+	err := client.publishWithReconnect(context.Background(), "ride.started", func(ctx context.Context) error {
+		called = true
+		return nil
+	})
+
+	assert.ErrorIs(t, err, ErrNotConnected)
+	assert.False(t, called)
+}
+
+func TestPublishWithReconnect_DoesNotRetryWhileConnected(t *testing.T) {
+	conn := newFakeConn(nats.CONNECTED)
+	client := newTestClient(conn, time.Second)
+	publishErr := errors.New("no responders")
+
+	var calls int32
+	err := client.publishWithReconnect(context.Background(), "ride.started", func(ctx context.Context) error {
+		atomic.AddInt32(&calls, 1)
+		return publishErr
+	})
+
+	assert.ErrorIs(t, err, publishErr)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+}
+
+func TestPublishWithReconnect_ClosedConnection(t *testing.T) {
+	client := newTestClient(newFakeConn(nats.CLOSED), time.Second)
+
+	err := client.publishWithReconnect(context.Background(), "ride.started", func(ctx context.Context) error {
+		return nil
+	})
+
+	assert.ErrorIs(t, err, nats.ErrConnectionClosed)
+}
+
+func TestReconnectOptions(t *testing.T) {
+	apply := func(cfg models.NATSConfig) nats.Options {
+		opts := nats.GetDefaultOptions()
+		for _, opt := range reconnectOptions(cfg) {
+			require.NoError(t, opt(&opts))
+		}
+		return opts
+	}
+
+	defaults := apply(models.NATSConfig{})
+	assert.Equal(t, -1, defaults.MaxReconnect)
+	assert.Equal(t, 2*time.Second, defaults.ReconnectWait)
+	assert.Equal(t, 5*1024*1024, defaults.ReconnectBufSize)
+
+	custom := apply(models.NATSConfig{MaxReconnects: 10, ReconnectWaitMs: 500, ReconnectBufferBytes: 1024})
+	assert.Equal(t, 10, custom.MaxReconnect)
+	assert.Equal(t, 500*time.Millisecond, custom.ReconnectWait)
+	assert.Equal(t, 1024, custom.ReconnectBufSize)
+}
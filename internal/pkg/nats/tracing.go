@@ -0,0 +1,62 @@
+package nats
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// TraceCarrier propagates distributed trace context; *newrelic.Transaction implements it
+type TraceCarrier interface {
+	InsertDistributedTraceHeaders(hdrs http.Header)
+	AcceptDistributedTraceHeaders(t newrelic.TransportType, hdrs http.Header)
+}
+
+// InjectTraceHeaders writes the carrier's distributed trace headers into a NATS message header
+func InjectTraceHeaders(carrier TraceCarrier, hdr nats.Header) nats.Header {
+	if hdr == nil {
+		hdr = nats.Header{}
+	}
+	if carrier == nil {
+		return hdr
+	}
+
+	carrier.InsertDistributedTraceHeaders(http.Header(hdr))
+	return hdr
+}
+
+// ExtractTraceHeaders continues the trace carried in a NATS message header on the given carrier
+func ExtractTraceHeaders(carrier TraceCarrier, hdr nats.Header) {
+	if carrier == nil || len(hdr) == 0 {
+		return
+	}
+
+	carrier.AcceptDistributedTraceHeaders(newrelic.TransportQueue, http.Header(hdr))
+}
+
+// TraceHeaders returns NATS headers carrying the trace of the New Relic transaction in ctx
+func TraceHeaders(ctx context.Context) nats.Header {
+	txn := newrelic.FromContext(ctx)
+	if txn == nil {
+		return nats.Header{}
+	}
+	return InjectTraceHeaders(txn, nil)
+}
+
+// StartConsumerTransaction starts a transaction for a consumed JetStream message,
+// continuing the publisher's trace when the message carries trace headers.
+// With New Relic disabled the app is nil and so is the returned transaction; the nrpkg helpers and
+// the transaction's own methods all accept a nil transaction.
+func StartConsumerTransaction(app *newrelic.Application, name string, msg jetstream.Msg) *newrelic.Transaction {
+	if app == nil {
+		return nil
+	}
+	txn := app.StartTransaction(name)
+	if txn != nil {
+		ExtractTraceHeaders(txn, msg.Headers())
+	}
+	return txn
+}
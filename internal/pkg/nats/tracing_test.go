@@ -0,0 +1,107 @@
+package nats
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockTraceCarrier records the trace headers it inserts and accepts
+type mockTraceCarrier struct {
+	traceparent   string
+	acceptedType  newrelic.TransportType
+	acceptedHdrs  http.Header
+	acceptedCalls int
+}
+
+func (m *mockTraceCarrier) InsertDistributedTraceHeaders(hdrs http.Header) {
+	hdrs.Set("traceparent", m.traceparent)
+	hdrs.Set("newrelic", "payload")
+}
+
+func (m *mockTraceCarrier) AcceptDistributedTraceHeaders(t newrelic.TransportType, hdrs http.Header) {
+	m.acceptedCalls++
+	m.acceptedType = t
+	m.acceptedHdrs = hdrs
+}
+
+// mockMsg is a JetStream message that only exposes headers
+type mockMsg struct {
+	jetstream.Msg
+	headers nats.Header
+}
+
+func (m *mockMsg) Headers() nats.Header {
+	return m.headers
+}
+
+func TestInjectTraceHeaders(t *testing.T) {
+	t.Run("writes carrier headers on publish", func(t *testing.T) {
+		carrier := &mockTraceCarrier{traceparent: "00-trace-span-01"}
+
+		hdr := InjectTraceHeaders(carrier, nil)
+
+		assert.Equal(t, "00-trace-span-01", http.Header(hdr).Get("traceparent"))
+		assert.Equal(t, "payload", http.Header(hdr).Get("newrelic"))
+	})
+
+	t.Run("keeps existing headers", func(t *testing.T) {
+		carrier := &mockTraceCarrier{traceparent: "00-trace-span-01"}
+		hdr := nats.Header{"X-Request-ID": []string{"req-1"}}
+
+		hdr = InjectTraceHeaders(carrier, hdr)
+
+		assert.Equal(t, "req-1", hdr.Get("X-Request-ID"))
+		assert.Equal(t, "00-trace-span-01", http.Header(hdr).Get("traceparent"))
+	})
+
+	t.Run("nil carrier returns empty header", func(t *testing.T) {
+		hdr := InjectTraceHeaders(nil, nil)
+
+		assert.NotNil(t, hdr)
+		assert.Empty(t, hdr)
+	})
+}
+
+func TestExtractTraceHeaders(t *testing.T) {
+	t.Run("reads headers on consume", func(t *testing.T) {
+		publisher := &mockTraceCarrier{traceparent: "00-trace-span-01"}
+		consumer := &mockTraceCarrier{}
+		msg := &mockMsg{headers: InjectTraceHeaders(publisher, nil)}
+
+		ExtractTraceHeaders(consumer, msg.Headers())
+
+		assert.Equal(t, 1, consumer.acceptedCalls)
+		assert.Equal(t, newrelic.TransportQueue, consumer.acceptedType)
+		assert.Equal(t, "00-trace-span-01", consumer.acceptedHdrs.Get("traceparent"))
+	})
+
+	t.Run("message without headers is ignored", func(t *testing.T) {
+		consumer := &mockTraceCarrier{}
+
+		ExtractTraceHeaders(consumer, nil)
+
+		assert.Equal(t, 0, consumer.acceptedCalls)
+	})
+}
+
+func TestTraceHeaders_NoTransaction(t *testing.T) {
+	hdr := TraceHeaders(context.Background())
+
+	assert.NotNil(t, hdr)
+	assert.Empty(t, hdr)
+}
+
+func TestStartConsumerTransaction_NilApplication(t *testing.T) {
+	msg := &mockMsg{headers: nats.Header{"traceparent": []string{"00-trace-span-01"}}}
+
+	assert.NotPanics(t, func() {
+		txn := StartConsumerTransaction(nil, "NATS.Test", msg)
+		assert.Nil(t, txn)
+	})
+}
@@ -71,14 +71,21 @@ func NewNewRelicTracer(app *newrelic.Application) *NewRelicTracer {
 	return &NewRelicTracer{app: app}
 }
 
-// StartTransaction creates a new New Relic transaction
+// StartTransaction creates a new New Relic transaction.
+// A tracer without an application, such as the nil returned by NewNewRelicTracer(nil), hands out no-op transactions.
 func (t *NewRelicTracer) StartTransaction(name string) Transaction {
+	if t == nil || t.app == nil {
+		return &NoOpTransaction{ctx: context.Background()}
+	}
 	txn := t.app.StartTransaction(name)
 	return &NewRelicTransaction{txn: txn}
 }
 
 // StartSegment creates a new segment within the current transaction
 func (t *NewRelicTracer) StartSegment(ctx context.Context, name string) (context.Context, func()) {
+	if t == nil {
+		return ctx, func() {}
+	}
 	if txn := newrelic.FromContext(ctx); txn != nil {
 		segment := txn.StartSegment(name)
 		return ctx, segment.End
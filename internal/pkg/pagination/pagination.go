@@ -0,0 +1,113 @@
+// Package pagination provides keyset cursors and a standard page envelope for list endpoints.
+package pagination
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultLimit is the page size used when a request does not ask for one
+	DefaultLimit = 20
+	// MaxLimit caps the page size a request may ask for
+	MaxLimit = 100
+)
+
+// ErrInvalidCursor is returned when a cursor is not one produced by Encode
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor is the keyset position of the last row on a page. Rows are ordered newest first by
+// created_at, with id breaking ties so rows created at the same instant are never skipped or repeated.
+type Cursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	ID        uuid.UUID `json:"id"`
+}
+
+// Encode returns the cursor as an opaque URL-safe string
+func (c Cursor) Encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor produced by Encode; an empty string means the first page and yields nil
+func Decode(encoded string) (*Cursor, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.CreatedAt.IsZero() || cursor.ID == uuid.Nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// After reports whether a row sorts after the cursor in newest-first order. It mirrors the
+// repositories' keyset predicate (created_at, id) < (cursor.created_at, cursor.id).
+func (c Cursor) After(createdAt time.Time, id uuid.UUID) bool {
+	if !createdAt.Equal(c.CreatedAt) {
+		return createdAt.Before(c.CreatedAt)
+	}
+	return bytes.Compare(id[:], c.ID[:]) < 0
+}
+
+// Request is the page a caller asks for; a nil cursor asks for the first page
+type Request struct {
+	Limit  int
+	Cursor *Cursor
+}
+
+// NewRequest builds a page request from raw query parameters, clamping the limit to [1, MaxLimit]
+func NewRequest(limit int, cursor string) (Request, error) {
+	decoded, err := Decode(cursor)
+	if err != nil {
+		return Request{}, err
+	}
+	return Request{Limit: normalizeLimit(limit), Cursor: decoded}, nil
+}
+
+// PageLimit returns the request's limit clamped to [1, MaxLimit]
+func (r Request) PageLimit() int {
+	return normalizeLimit(r.Limit)
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
+
+// PageResponse is the standard envelope for a page of results. NextCursor is empty on the last page.
+type PageResponse[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Total      int    `json:"total"`
+}
+
+// NewPage builds a page from rows fetched with one more than limit; the extra row only signals that
+// another page exists and is dropped, and the cursor points at the last row kept.
+func NewPage[T any](rows []T, limit, total int, cursorOf func(T) Cursor) *PageResponse[T] {
+	page := &PageResponse[T]{Items: rows, Total: total}
+	if page.Items == nil {
+		page.Items = []T{}
+	}
+	if len(rows) > limit {
+		page.Items = rows[:limit]
+		page.NextCursor = cursorOf(page.Items[limit-1]).Encode()
+	}
+	return page
+}
@@ -0,0 +1,125 @@
+package pagination
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type row struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+}
+
+func rowCursor(r row) Cursor {
+	return Cursor{CreatedAt: r.CreatedAt, ID: r.ID}
+}
+
+// fetch mimics a keyset-paginated repository query over rows sorted newest first
+func fetch(rows []row, req Request) []row {
+	limit := req.PageLimit()
+	var out []row
+	for _, r := range rows {
+		if req.Cursor != nil && !req.Cursor.After(r.CreatedAt, r.ID) {
+			continue
+		}
+		out = append(out, r)
+		if len(out) == limit+1 {
+			break
+		}
+	}
+	return out
+}
+
+func TestCursor_RoundTrip(t *testing.T) {
+	cursor := Cursor{
+		CreatedAt: time.Date(2026, 1, 2, 8, 30, 15, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	decoded, err := Decode(cursor.Encode())
+	require.NoError(t, err)
+	assert.True(t, cursor.CreatedAt.Equal(decoded.CreatedAt))
+	assert.Equal(t, cursor.ID, decoded.ID)
+}
+
+func TestDecode(t *testing.T) {
+	t.Run("Empty cursor is the first page", func(t *testing.T) {
+		cursor, err := Decode("")
+		assert.NoError(t, err)
+		assert.Nil(t, cursor)
+	})
+
+	for _, encoded := range []string{"not base64!", "bm90IGpzb24", Cursor{}.Encode()} {
+		cursor, err := Decode(encoded)
+		assert.ErrorIs(t, err, ErrInvalidCursor, encoded)
+		assert.Nil(t, cursor)
+	}
+}
+
+func TestNewRequest_ClampsLimit(t *testing.T) {
+	for limit, expected := range map[int]int{0: DefaultLimit, -5: DefaultLimit, 10: 10, 500: MaxLimit} {
+		req, err := NewRequest(limit, "")
+		require.NoError(t, err)
+		assert.Equal(t, expected, req.Limit)
+	}
+
+	_, err := NewRequest(10, "garbage")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
+
+func TestNewPage(t *testing.T) {
+	rows := []row{{ID: uuid.New(), CreatedAt: time.Now()}, {ID: uuid.New(), CreatedAt: time.Now()}}
+
+	last := NewPage(rows, 2, 2, rowCursor)
+	assert.Len(t, last.Items, 2)
+	assert.Empty(t, last.NextCursor)
+
+	more := NewPage(rows, 1, 5, rowCursor)
+	assert.Len(t, more.Items, 1)
+	assert.Equal(t, 5, more.Total)
+	assert.Equal(t, rowCursor(rows[0]).Encode(), more.NextCursor)
+
+	empty := NewPage[row](nil, 10, 0, rowCursor)
+	assert.NotNil(t, empty.Items)
+}
+
+func TestPaging_StableOrderAcrossPages(t *testing.T) {
+	// Several rows share a timestamp so only the id keeps the order stable
+	base := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	var rows []row
+	for i := 0; i < 23; i++ {
+		rows = append(rows, row{ID: uuid.New(), CreatedAt: base.Add(-time.Duration(i/4) * time.Minute)})
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rowCursor(rows[i]).After(rows[j].CreatedAt, rows[j].ID)
+	})
+
+	seen := make(map[uuid.UUID]bool)
+	var walked []row
+	req := Request{Limit: 5}
+	pages := 0
+	for {
+		page := NewPage(fetch(rows, req), req.PageLimit(), len(rows), rowCursor)
+		pages++
+		for _, item := range page.Items {
+			assert.False(t, seen[item.ID], "row returned twice")
+			seen[item.ID] = true
+		}
+		walked = append(walked, page.Items...)
+		if page.NextCursor == "" {
+			break
+		}
+
+		cursor, err := Decode(page.NextCursor)
+		require.NoError(t, err)
+		req.Cursor = cursor
+	}
+
+	assert.Equal(t, 5, pages)
+	assert.Equal(t, rows, walked)
+}
@@ -0,0 +1,55 @@
+// Package region resolves the matching and pricing settings that apply at a location.
+package region
+
+import (
+	"strings"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+const (
+	// DefaultName is reported for locations outside every configured region
+	DefaultName = "default"
+	// geohashPrecision is the longest region prefix that can match
+	geohashPrecision = 12
+)
+
+// Resolve returns the settings for the region whose geohash prefix most specifically matches location.
+// Values a region leaves unset, and locations outside every region, fall back to the global settings.
+func Resolve(cfg *models.Config, location models.Location) models.RegionConfig {
+	resolved := models.RegionConfig{
+		Name:           DefaultName,
+		SearchRadiusKm: cfg.Match.SearchRadiusKm,
+		RatePerKm:      cfg.Pricing.RatePerKm,
+	}
+	if len(cfg.Regions) == 0 {
+		return resolved
+	}
+
+	hash := utils.EncodeGeohash(utils.GeoPoint{Latitude: location.Latitude, Longitude: location.Longitude}, geohashPrecision)
+
+	var best *models.RegionConfig
+	for i := range cfg.Regions {
+		candidate := &cfg.Regions[i]
+		if candidate.GeohashPrefix == "" || !strings.HasPrefix(hash, candidate.GeohashPrefix) {
+			continue
+		}
+		if best == nil || len(candidate.GeohashPrefix) > len(best.GeohashPrefix) {
+			best = candidate
+		}
+	}
+	if best == nil {
+		return resolved
+	}
+
+	resolved.Name = best.Name
+	resolved.GeohashPrefix = best.GeohashPrefix
+	if best.SearchRadiusKm > 0 {
+		resolved.SearchRadiusKm = best.SearchRadiusKm
+	}
+	if best.RatePerKm > 0 {
+		resolved.RatePerKm = best.RatePerKm
+	}
+	return resolved
+}
@@ -0,0 +1,70 @@
+package region
+
+import (
+	"testing"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	// jakartaPickup lies in geohash cell qqguxm
+	jakartaPickup = models.Location{Latitude: -6.2088, Longitude: 106.8456}
+	// bogorPickup lies in geohash cell qqgfqr
+	bogorPickup = models.Location{Latitude: -6.5971, Longitude: 106.8060}
+	// yogyakartaPickup lies outside every test region
+	yogyakartaPickup = models.Location{Latitude: -7.7956, Longitude: 110.3695}
+)
+
+func testConfig() *models.Config {
+	return &models.Config{
+		Match:   models.MatchConfig{SearchRadiusKm: 3.0},
+		Pricing: models.PricingConfig{RatePerKm: 3000},
+		Regions: []models.RegionConfig{
+			{Name: "jakarta", GeohashPrefix: "qqgu", SearchRadiusKm: 1.0, RatePerKm: 3500},
+			{Name: "bogor", GeohashPrefix: "qqgf", SearchRadiusKm: 5.0, RatePerKm: 2500},
+		},
+	}
+}
+
+func TestResolve_DifferentRegions(t *testing.T) {
+	cfg := testConfig()
+
+	jakarta := Resolve(cfg, jakartaPickup)
+	bogor := Resolve(cfg, bogorPickup)
+
+	assert.Equal(t, "jakarta", jakarta.Name)
+	assert.Equal(t, 1.0, jakarta.SearchRadiusKm)
+	assert.Equal(t, 3500.0, jakarta.RatePerKm)
+
+	assert.Equal(t, "bogor", bogor.Name)
+	assert.Equal(t, 5.0, bogor.SearchRadiusKm)
+	assert.Equal(t, 2500.0, bogor.RatePerKm)
+}
+
+func TestResolve_FallsBackToGlobalSettings(t *testing.T) {
+	cfg := testConfig()
+
+	resolved := Resolve(cfg, yogyakartaPickup)
+
+	assert.Equal(t, DefaultName, resolved.Name)
+	assert.Equal(t, 3.0, resolved.SearchRadiusKm)
+	assert.Equal(t, 3000.0, resolved.RatePerKm)
+
+	// Without any regions every location uses the global settings
+	resolved = Resolve(&models.Config{Match: cfg.Match, Pricing: cfg.Pricing}, jakartaPickup)
+	assert.Equal(t, DefaultName, resolved.Name)
+	assert.Equal(t, 3.0, resolved.SearchRadiusKm)
+}
+
+func TestResolve_MostSpecificPrefixWins(t *testing.T) {
+	cfg := testConfig()
+	cfg.Regions = append(cfg.Regions, models.RegionConfig{Name: "central-jakarta", GeohashPrefix: "qqgux", SearchRadiusKm: 0.5})
+
+	resolved := Resolve(cfg, jakartaPickup)
+
+	assert.Equal(t, "central-jakarta", resolved.Name)
+	assert.Equal(t, 0.5, resolved.SearchRadiusKm)
+	// The rate is not overridden, so the global rate applies
+	assert.Equal(t, 3000.0, resolved.RatePerKm)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
 // GracefulServer wraps Echo server with graceful shutdown capabilities
@@ -123,3 +124,13 @@ func (sm *ShutdownManager) Shutdown(ctx context.Context) error {
 	sm.logger.Info("All components shutdown completed")
 	return nil
 }
+
+// ConfigureTimeouts applies the configured server timeouts, in seconds, to the http.Server behind
+// e. A zero value leaves that timeout disabled. Streaming responses such as SSE keep these deadlines
+// and must clear or extend them through http.ResponseController; WebSockets manage their own per frame
+func ConfigureTimeouts(e *echo.Echo, cfg models.ServerConfig) {
+	e.Server.ReadTimeout = time.Duration(cfg.ReadTimeout) * time.Second
+	e.Server.ReadHeaderTimeout = time.Duration(cfg.ReadHeaderTimeout) * time.Second
+	e.Server.WriteTimeout = time.Duration(cfg.WriteTimeout) * time.Second
+	e.Server.IdleTimeout = time.Duration(cfg.IdleTimeout) * time.Second
+}
@@ -3,14 +3,18 @@ package server
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"net"
 	"net/http"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewGracefulServer(t *testing.T) {
@@ -45,6 +49,60 @@ func TestNewGracefulServer(t *testing.T) {
 	}
 }
 
+func TestConfigureTimeouts(t *testing.T) {
+	t.Run("Configured timeouts are set on the http.Server", func(t *testing.T) {
+		e := echo.New()
+		ConfigureTimeouts(e, models.ServerConfig{
+			ReadTimeout:       60,
+			ReadHeaderTimeout: 10,
+			WriteTimeout:      30,
+			IdleTimeout:       120,
+		})
+
+		assert.Equal(t, 60*time.Second, e.Server.ReadTimeout)
+		assert.Equal(t, 10*time.Second, e.Server.ReadHeaderTimeout)
+		assert.Equal(t, 30*time.Second, e.Server.WriteTimeout)
+		assert.Equal(t, 120*time.Second, e.Server.IdleTimeout)
+	})
+
+	t.Run("Zero leaves timeouts disabled", func(t *testing.T) {
+		e := echo.New()
+		ConfigureTimeouts(e, models.ServerConfig{})
+
+		assert.Zero(t, e.Server.ReadTimeout)
+		assert.Zero(t, e.Server.ReadHeaderTimeout)
+		assert.Zero(t, e.Server.WriteTimeout)
+		assert.Zero(t, e.Server.IdleTimeout)
+	})
+
+	t.Run("Slow headers are cut off", func(t *testing.T) {
+		e := echo.New()
+		e.HideBanner = true
+		e.HidePort = true
+		ConfigureTimeouts(e, models.ServerConfig{ReadHeaderTimeout: 1})
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		e.Listener = listener
+		go e.Start("")
+		defer e.Close()
+
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+
+		// Send part of a request and never finish the headers
+		_, err = conn.Write([]byte("GET / HTTP/1.1\r\nHost: localhost\r\n"))
+		require.NoError(t, err)
+
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+		start := time.Now()
+		_, err = io.ReadAll(conn)
+		assert.NoError(t, err, "server should close the connection before the client deadline")
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+}
+
 func TestGracefulServer_Start(t *testing.T) {
 	t.Run("Start server successfully", func(t *testing.T) {
 		e := echo.New()
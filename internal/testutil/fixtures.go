@@ -0,0 +1,70 @@
+// Package testutil provides fixtures shared by service tests.
+package testutil
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+var (
+	// PickupLocation is a default passenger location in central Jakarta
+	PickupLocation = models.Location{Latitude: -6.2088, Longitude: 106.8456}
+	// DriverLocation is a default driver location a little over a kilometer from PickupLocation
+	DriverLocation = models.Location{Latitude: -6.2188, Longitude: 106.8556}
+	// DropoffLocation is a default passenger destination
+	DropoffLocation = models.Location{Latitude: -6.1751, Longitude: 106.8650}
+)
+
+// NewPendingMatch returns a pending match between a driver and passenger at the default locations
+func NewPendingMatch(driverID, passengerID string) *models.Match {
+	now := time.Now()
+	return &models.Match{
+		ID:                uuid.New(),
+		DriverID:          uuid.MustParse(driverID),
+		PassengerID:       uuid.MustParse(passengerID),
+		DriverLocation:    DriverLocation,
+		PassengerLocation: PickupLocation,
+		TargetLocation:    DropoffLocation,
+		Status:            models.MatchStatusPending,
+		CreatedAt:         now,
+		UpdatedAt:         now,
+	}
+}
+
+// NewOngoingRide returns an ongoing ride between a driver and passenger picked up at the default location
+func NewOngoingRide(driverID, passengerID string) *models.Ride {
+	now := time.Now()
+	return &models.Ride{
+		RideID:          uuid.New(),
+		MatchID:         uuid.New(),
+		DriverID:        uuid.MustParse(driverID),
+		PassengerID:     uuid.MustParse(passengerID),
+		Status:          models.RideStatusOngoing,
+		PickupLatitude:  PickupLocation.Latitude,
+		PickupLongitude: PickupLocation.Longitude,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+}
+
+// NewPendingPayment returns the pending payment created for a ride when the driver arrives
+func NewPendingPayment(ride *models.Ride, adjustedCost int) *models.Payment {
+	return &models.Payment{
+		PaymentID:    uuid.New(),
+		RideID:       ride.RideID,
+		AdjustedCost: adjustedCost,
+		Status:       models.PaymentStatusPending,
+		CreatedAt:    time.Now(),
+	}
+}
+
+// NewPaymentRequest returns the payment request sent to a ride's passenger
+func NewPaymentRequest(ride *models.Ride, totalCost int) *models.PaymentRequest {
+	return &models.PaymentRequest{
+		RideID:      ride.RideID.String(),
+		PassengerID: ride.PassengerID.String(),
+		TotalCost:   totalCost,
+	}
+}
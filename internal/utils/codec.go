@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// mimeApplicationXMsgpack is the older msgpack media type some clients still send
+const mimeApplicationXMsgpack = "application/x-msgpack"
+
+// isMsgpack reports whether a media type, with or without parameters, names msgpack
+func isMsgpack(mediaType string) bool {
+	parsed, _, err := mime.ParseMediaType(strings.TrimSpace(mediaType))
+	if err != nil {
+		return false
+	}
+	return parsed == echo.MIMEApplicationMsgpack || parsed == mimeApplicationXMsgpack
+}
+
+// WantsMsgpack reports whether the client listed msgpack in its Accept header. Clients that don't
+// ask for it, including those accepting */*, get JSON.
+func WantsMsgpack(c echo.Context) bool {
+	for _, accepted := range strings.Split(c.Request().Header.Get(echo.HeaderAccept), ",") {
+		if isMsgpack(accepted) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bind decodes the request body into target as msgpack when the Content-Type says so, and
+// otherwise with Echo's default binding. Path parameters are bound either way.
+func Bind(c echo.Context, target interface{}) error {
+	if !isMsgpack(c.Request().Header.Get(echo.HeaderContentType)) {
+		return c.Bind(target)
+	}
+
+	if err := (&echo.DefaultBinder{}).BindPathParams(c, target); err != nil {
+		return err
+	}
+	if c.Request().ContentLength == 0 {
+		return nil
+	}
+
+	decoder := msgpack.NewDecoder(c.Request().Body)
+	decoder.SetCustomStructTag("json")
+	if err := decoder.Decode(target); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, fmt.Sprintf("invalid msgpack body: %v", err)).SetInternal(err)
+	}
+	return nil
+}
+
+// respond writes body as msgpack when the client asked for it and as JSON otherwise. Msgpack
+// uses the same field names as the JSON encoding.
+func respond(c echo.Context, statusCode int, body interface{}) error {
+	if !WantsMsgpack(c) {
+		return c.JSON(statusCode, body)
+	}
+
+	var buf bytes.Buffer
+	encoder := msgpack.NewEncoder(&buf)
+	encoder.SetCustomStructTag("json")
+	if err := encoder.Encode(body); err != nil {
+		return err
+	}
+
+	c.Response().Header().Add(echo.HeaderVary, echo.HeaderAccept)
+	return c.Blob(statusCode, echo.MIMEApplicationMsgpack, buf.Bytes())
+}
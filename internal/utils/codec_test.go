@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type codecPayload struct {
+	RideID   string  `json:"ride_id"`
+	Distance float64 `json:"distance_km"`
+	Note     string  `json:"note,omitempty"`
+}
+
+func TestSuccessResponse_Negotiation(t *testing.T) {
+	tests := []struct {
+		name        string
+		accept      string
+		contentType string
+	}{
+		{name: "No Accept header", accept: "", contentType: echo.MIMEApplicationJSON},
+		{name: "Any type", accept: "*/*", contentType: echo.MIMEApplicationJSON},
+		{name: "JSON", accept: "application/json", contentType: echo.MIMEApplicationJSON},
+		{name: "Msgpack", accept: "application/msgpack", contentType: echo.MIMEApplicationMsgpack},
+		{name: "Legacy msgpack type", accept: "application/x-msgpack", contentType: echo.MIMEApplicationMsgpack},
+		{name: "Msgpack listed with JSON", accept: "application/json;q=0.5, application/msgpack", contentType: echo.MIMEApplicationMsgpack},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set(echo.HeaderAccept, tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := SuccessResponse(c, http.StatusOK, "Ride location retrieved", codecPayload{RideID: "ride-1", Distance: 2.5})
+
+			require.NoError(t, err)
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Contains(t, rec.Header().Get(echo.HeaderContentType), tt.contentType)
+
+			var response struct {
+				Success bool         `json:"success"`
+				Message string       `json:"message"`
+				Data    codecPayload `json:"data"`
+			}
+			if tt.contentType == echo.MIMEApplicationMsgpack {
+				decoder := msgpack.NewDecoder(bytes.NewReader(rec.Body.Bytes()))
+				decoder.SetCustomStructTag("json")
+				require.NoError(t, decoder.Decode(&response))
+			} else {
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			}
+			assert.True(t, response.Success)
+			assert.Equal(t, "Ride location retrieved", response.Message)
+			assert.Equal(t, codecPayload{RideID: "ride-1", Distance: 2.5}, response.Data)
+		})
+	}
+}
+
+func TestErrorResponseHandler_Msgpack(t *testing.T) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationMsgpack)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	require.NoError(t, BadRequestResponse(c, "ride_id is required"))
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationMsgpack, rec.Header().Get(echo.HeaderContentType))
+
+	var response map[string]interface{}
+	require.NoError(t, msgpack.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, false, response["success"])
+	assert.Equal(t, "ride_id is required", response["error"])
+	// Empty optional fields are left out, as in JSON
+	assert.NotContains(t, response, "fields")
+}
+
+func TestBind(t *testing.T) {
+	t.Run("Msgpack body", func(t *testing.T) {
+		var buf bytes.Buffer
+		encoder := msgpack.NewEncoder(&buf)
+		encoder.SetCustomStructTag("json")
+		require.NoError(t, encoder.Encode(codecPayload{RideID: "ride-1", Distance: 1.25}))
+
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", &buf)
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationMsgpack)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		var target codecPayload
+		require.NoError(t, Bind(c, &target))
+		assert.Equal(t, codecPayload{RideID: "ride-1", Distance: 1.25}, target)
+	})
+
+	t.Run("JSON body", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewBufferString(`{"ride_id":"ride-1","distance_km":1.25}`))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		var target codecPayload
+		require.NoError(t, Bind(c, &target))
+		assert.Equal(t, codecPayload{RideID: "ride-1", Distance: 1.25}, target)
+	})
+
+	t.Run("Malformed msgpack body", func(t *testing.T) {
+		e := echo.New()
+		req := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader([]byte{0xc1}))
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationMsgpack)
+		c := e.NewContext(req, httptest.NewRecorder())
+
+		var target codecPayload
+		err := Bind(c, &target)
+
+		var httpErr *echo.HTTPError
+		require.ErrorAs(t, err, &httpErr)
+		assert.Equal(t, http.StatusBadRequest, httpErr.Code)
+	})
+}
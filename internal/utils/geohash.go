@@ -2,8 +2,12 @@ package utils
 
 import (
 	"math"
+	"strings"
 )
 
+// geohashAlphabet is the base32 alphabet used by geohashes
+const geohashAlphabet = "0123456789bcdefghjkmnpqrstuvwxyz"
+
 // GeoPoint represents a geographical point with latitude and longitude
 type GeoPoint struct {
 	Latitude  float64
@@ -30,3 +34,43 @@ func CalculateDistance(point1, point2 GeoPoint) float64 {
 
 	return distance
 }
+
+// EncodeGeohash returns the geohash of point with the given number of characters.
+// Points sharing a prefix lie in the same cell, so prefixes can describe areas of varying size.
+func EncodeGeohash(point GeoPoint, precision int) string {
+	latRange := [2]float64{-90, 90}
+	lngRange := [2]float64{-180, 180}
+
+	var hash strings.Builder
+	bit, ch := 0, 0
+	evenBit := true
+	for hash.Len() < precision {
+		// Bits alternate between longitude and latitude, starting with longitude
+		if evenBit {
+			mid := (lngRange[0] + lngRange[1]) / 2
+			if point.Longitude >= mid {
+				ch = ch<<1 | 1
+				lngRange[0] = mid
+			} else {
+				ch <<= 1
+				lngRange[1] = mid
+			}
+		} else {
+			mid := (latRange[0] + latRange[1]) / 2
+			if point.Latitude >= mid {
+				ch = ch<<1 | 1
+				latRange[0] = mid
+			} else {
+				ch <<= 1
+				latRange[1] = mid
+			}
+		}
+		evenBit = !evenBit
+
+		if bit++; bit == 5 {
+			hash.WriteByte(geohashAlphabet[ch])
+			bit, ch = 0, 0
+		}
+	}
+	return hash.String()
+}
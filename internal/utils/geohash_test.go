@@ -172,4 +172,37 @@ func BenchmarkCalculateDistance(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		CalculateDistance(point1, point2)
 	}
-}
\ No newline at end of file
+}
+func TestEncodeGeohash(t *testing.T) {
+	tests := []struct {
+		name      string
+		point     GeoPoint
+		precision int
+		expected  string
+	}{
+		{
+			name:      "Reference point",
+			point:     GeoPoint{Latitude: 57.64911, Longitude: 10.40744},
+			precision: 11,
+			expected:  "u4pruydqqvj",
+		},
+		{
+			name:      "Central Jakarta",
+			point:     GeoPoint{Latitude: -6.2088, Longitude: 106.8456},
+			precision: 6,
+			expected:  "qqguxm",
+		},
+		{
+			name:      "Zero precision",
+			point:     GeoPoint{Latitude: -6.2088, Longitude: 106.8456},
+			precision: 0,
+			expected:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, EncodeGeohash(tt.point, tt.precision))
+		})
+	}
+}
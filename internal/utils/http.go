@@ -2,50 +2,100 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/labstack/echo/v4"
 )
 
 // Response represents a standard API response
 type Response struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success   bool        `json:"success"`
+	Message   string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 // ErrorResponse represents an error response
 type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    int    `json:"code,omitempty"`
+	Success   bool              `json:"success"`
+	Error     string            `json:"error"`
+	Code      int               `json:"code,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"` // Invalid request fields and what is wrong with each
+	RequestID string            `json:"request_id,omitempty"`
 }
 
-// SuccessResponse sends a success response with data
+// requestID returns the ID the request middleware assigned to the request, if any
+func requestID(c echo.Context) string {
+	return c.Response().Header().Get(echo.HeaderXRequestID)
+}
+
+// SuccessResponse sends a success response with data, as msgpack for clients that ask for it
 func SuccessResponse(c echo.Context, statusCode int, message string, data interface{}) error {
-	return c.JSON(statusCode, Response{
-		Success: true,
-		Message: message,
-		Data:    data,
+	return respond(c, statusCode, Response{
+		Success:   true,
+		Message:   message,
+		Data:      data,
+		RequestID: requestID(c),
 	})
 }
 
 // ErrorResponseHandler sends an error response
 func ErrorResponseHandler(c echo.Context, statusCode int, errorMessage string) error {
-	return c.JSON(statusCode, ErrorResponse{
-		Success: false,
-		Error:   errorMessage,
-		Code:    statusCode,
+	return respond(c, statusCode, ErrorResponse{
+		Success:   false,
+		Error:     errorMessage,
+		Code:      statusCode,
+		RequestID: requestID(c),
 	})
 }
 
+// HTTPErrorHandler renders errors returned from handlers and middleware, such as unknown routes
+// or rejected API keys, in the standard error envelope. Errors that are not echo.HTTPError are
+// reported as a generic 500 so internal details never reach the client.
+func HTTPErrorHandler(err error, c echo.Context) {
+	if c.Response().Committed {
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+	errorMessage := http.StatusText(statusCode)
+
+	var httpErr *echo.HTTPError
+	if errors.As(err, &httpErr) {
+		statusCode = httpErr.Code
+		errorMessage = http.StatusText(statusCode)
+		if message, ok := httpErr.Message.(string); ok && message != "" {
+			errorMessage = message
+		}
+	}
+
+	if c.Request().Method == http.MethodHead {
+		_ = c.NoContent(statusCode)
+		return
+	}
+	_ = ErrorResponseHandler(c, statusCode, errorMessage)
+}
+
 // BadRequestResponse sends a 400 Bad Request response
 func BadRequestResponse(c echo.Context, errorMessage string) error {
 	return ErrorResponseHandler(c, http.StatusBadRequest, errorMessage)
 }
 
+// ValidationErrorResponse sends a 400 Bad Request response naming the invalid request fields
+func ValidationErrorResponse(c echo.Context, fields map[string]string) error {
+	return respond(c, http.StatusBadRequest, ErrorResponse{
+		Success:   false,
+		Error:     "Invalid request",
+		Code:      http.StatusBadRequest,
+		Fields:    fields,
+		RequestID: requestID(c),
+	})
+}
+
 // UnauthorizedResponse sends a 401 Unauthorized response
 func UnauthorizedResponse(c echo.Context, errorMessage string) error {
 	if errorMessage == "" {
@@ -153,3 +203,38 @@ func ParseJSONResponse(respBody []byte, target interface{}) error {
 
 	return nil
 }
+
+const (
+	// DefaultPageLimit is the page size used when no limit is requested
+	DefaultPageLimit = 20
+	// MaxPageLimit caps the page size a client may request
+	MaxPageLimit = 100
+)
+
+// ParsePagination reads the limit and offset query parameters, applying defaults and bounds
+func ParsePagination(c echo.Context) (int, int, error) {
+	limit := DefaultPageLimit
+	offset := 0
+
+	if limitStr := c.QueryParam("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			return 0, 0, fmt.Errorf("invalid limit")
+		}
+		limit = parsed
+	}
+
+	if offsetStr := c.QueryParam("offset"); offsetStr != "" {
+		parsed, err := strconv.Atoi(offsetStr)
+		if err != nil || parsed < 0 {
+			return 0, 0, fmt.Errorf("invalid offset")
+		}
+		offset = parsed
+	}
+
+	if limit > MaxPageLimit {
+		limit = MaxPageLimit
+	}
+
+	return limit, offset, nil
+}
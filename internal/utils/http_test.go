@@ -2,6 +2,7 @@ package utils
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -205,6 +206,63 @@ func TestForbiddenResponse(t *testing.T) {
 	}
 }
 
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectedLimit  int
+		expectedOffset int
+		expectError    bool
+	}{
+		{
+			name:           "Defaults",
+			query:          "",
+			expectedLimit:  DefaultPageLimit,
+			expectedOffset: 0,
+		},
+		{
+			name:           "Explicit values",
+			query:          "?limit=10&offset=30",
+			expectedLimit:  10,
+			expectedOffset: 30,
+		},
+		{
+			name:           "Limit capped",
+			query:          "?limit=1000",
+			expectedLimit:  MaxPageLimit,
+			expectedOffset: 0,
+		},
+		{
+			name:        "Invalid limit",
+			query:       "?limit=0",
+			expectError: true,
+		},
+		{
+			name:        "Invalid offset",
+			query:       "?offset=-1",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			limit, offset, err := ParsePagination(c)
+			if tt.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedLimit, limit)
+			assert.Equal(t, tt.expectedOffset, offset)
+		})
+	}
+}
+
 func TestNotFoundResponse(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -431,4 +489,89 @@ func TestResponseStructs(t *testing.T) {
 		assert.Equal(t, "Test error", resp.Error)
 		assert.Equal(t, 400, resp.Code)
 	})
-}
\ No newline at end of file
+}
+func TestResponseEnvelope_CarriesRequestID(t *testing.T) {
+	e := echo.New()
+
+	t.Run("Success envelope", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		c.Response().Header().Set(echo.HeaderXRequestID, "req-123")
+
+		assert.NoError(t, SuccessResponse(c, http.StatusOK, "Ride found", map[string]string{"ride_id": "ride-1"}))
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, map[string]interface{}{
+			"success":    true,
+			"message":    "Ride found",
+			"data":       map[string]interface{}{"ride_id": "ride-1"},
+			"request_id": "req-123",
+		}, body)
+	})
+
+	t.Run("Error envelope", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+		c.Response().Header().Set(echo.HeaderXRequestID, "req-456")
+
+		assert.NoError(t, NotFoundResponse(c, "Ride not found"))
+
+		var body map[string]interface{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, map[string]interface{}{
+			"success":    false,
+			"error":      "Ride not found",
+			"code":       float64(http.StatusNotFound),
+			"request_id": "req-456",
+		}, body)
+	})
+}
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:          "HTTP error keeps its status and message",
+			err:           echo.NewHTTPError(http.StatusUnauthorized, "Invalid API key"),
+			expectedCode:  http.StatusUnauthorized,
+			expectedError: "Invalid API key",
+		},
+		{
+			name:          "Unknown route",
+			err:           echo.ErrNotFound,
+			expectedCode:  http.StatusNotFound,
+			expectedError: "Not Found",
+		},
+		{
+			name:          "Internal error details are hidden",
+			err:           errors.New("pq: connection refused"),
+			expectedCode:  http.StatusInternalServerError,
+			expectedError: "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			rec := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/", nil), rec)
+			c.Response().Header().Set(echo.HeaderXRequestID, "req-789")
+
+			HTTPErrorHandler(tt.err, c)
+
+			assert.Equal(t, tt.expectedCode, rec.Code)
+
+			var response ErrorResponse
+			assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.False(t, response.Success)
+			assert.Equal(t, tt.expectedError, response.Error)
+			assert.Equal(t, tt.expectedCode, response.Code)
+			assert.Equal(t, "req-789", response.RequestID)
+		})
+	}
+}
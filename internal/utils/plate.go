@@ -0,0 +1,29 @@
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// platePattern matches an Indonesian plate: a 1-2 letter region code, a 1-4 digit number
+// without a leading zero and an optional 1-3 letter suffix, with or without separating spaces
+var platePattern = regexp.MustCompile(`^([A-Z]{1,2}) ?([1-9][0-9]{0,3}) ?([A-Z]{0,3})$`)
+
+// NormalizeVehiclePlate validates an Indonesian vehicle plate and returns it in canonical form,
+// uppercase with single spaces between segments (e.g. "b1234abc" becomes "B 1234 ABC")
+func NormalizeVehiclePlate(plate string) (string, error) {
+	// Collapse runs of whitespace so spacing differences do not produce distinct plates
+	cleaned := strings.ToUpper(strings.Join(strings.Fields(plate), " "))
+
+	parts := platePattern.FindStringSubmatch(cleaned)
+	if parts == nil {
+		return "", fmt.Errorf("invalid vehicle plate format")
+	}
+
+	normalized := parts[1] + " " + parts[2]
+	if parts[3] != "" {
+		normalized += " " + parts[3]
+	}
+	return normalized, nil
+}
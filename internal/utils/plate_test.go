@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeVehiclePlate(t *testing.T) {
+	tests := []struct {
+		name     string
+		plate    string
+		expected string
+	}{
+		{name: "Canonical plate", plate: "B 1234 ABC", expected: "B 1234 ABC"},
+		{name: "Lowercase", plate: "b 1234 abc", expected: "B 1234 ABC"},
+		{name: "Surrounding and repeated spaces", plate: "  b  1234   abc ", expected: "B 1234 ABC"},
+		{name: "No spaces", plate: "B1234ABC", expected: "B 1234 ABC"},
+		{name: "Two letter region", plate: "ad 1 x", expected: "AD 1 X"},
+		{name: "Without suffix", plate: "RI 1", expected: "RI 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizeVehiclePlate(tt.plate)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, normalized)
+		})
+	}
+}
+
+func TestNormalizeVehiclePlate_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		plate string
+	}{
+		{name: "Empty", plate: ""},
+		{name: "Only spaces", plate: "   "},
+		{name: "Missing number", plate: "B ABC"},
+		{name: "Missing region", plate: "1234 ABC"},
+		{name: "Region too long", plate: "ABC 1234 DE"},
+		{name: "Number too long", plate: "B 12345 ABC"},
+		{name: "Leading zero", plate: "B 0123 ABC"},
+		{name: "Suffix too long", plate: "B 1234 ABCD"},
+		{name: "Punctuation", plate: "B-1234-ABC"},
+		{name: "Digits in suffix", plate: "B 1234 AB1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			normalized, err := NormalizeVehiclePlate(tt.plate)
+			assert.Error(t, err)
+			assert.Empty(t, normalized)
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"math/big"
 	"regexp"
 	"strings"
 )
@@ -29,6 +30,19 @@ func GenerateRandomHex(length int) (string, error) {
 	return hex.EncodeToString(bytes), nil
 }
 
+// GenerateRandomDigits generates a random string of decimal digits of the specified length
+func GenerateRandomDigits(length int) (string, error) {
+	digits := make([]byte, length)
+	for i := range digits {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random digit: %w", err)
+		}
+		digits[i] = byte('0' + n.Int64())
+	}
+	return string(digits), nil
+}
+
 // IsValidEmail checks if a string is a valid email address
 func IsValidEmail(email string) bool {
 	// Updated regex to ensure:
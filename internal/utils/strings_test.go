@@ -100,6 +100,18 @@ func TestGenerateRandomHex(t *testing.T) {
 	}
 }
 
+func TestGenerateRandomDigits(t *testing.T) {
+	for _, length := range []int{0, 4, 6, 12} {
+		result, err := GenerateRandomDigits(length)
+
+		assert.NoError(t, err)
+		assert.Len(t, result, length)
+		for _, char := range result {
+			assert.True(t, char >= '0' && char <= '9', "Character should be a decimal digit")
+		}
+	}
+}
+
 func TestGenerateRandomHex_Uniqueness(t *testing.T) {
 	t.Run("Multiple calls produce different results", func(t *testing.T) {
 		length := 16
@@ -10,4 +10,6 @@ import (
 type LocationGW interface {
 	// PublishLocationAggregate publishes a location aggregate event to NATS
 	PublishLocationAggregate(ctx context.Context, aggregate models.LocationAggregate) error
+	// PublishDriverEvicted announces a driver removed from the pool after their beacons stopped
+	PublishDriverEvicted(ctx context.Context, eviction models.DriverEviction) error
 }
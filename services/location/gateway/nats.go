@@ -53,6 +53,7 @@ func (g *locationGW) PublishLocationAggregate(ctx context.Context, aggregate mod
 		Subject: constants.SubjectLocationAggregate,
 		Data:    data,
 		MsgID:   fmt.Sprintf("location-aggregate-%s-%d", aggregate.RideID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 10 * time.Second,
 	}
 
@@ -72,3 +73,25 @@ func (g *locationGW) PublishLocationAggregate(ctx context.Context, aggregate mod
 
 	return nil
 }
+
+// PublishDriverEvicted publishes a driver's eviction from the pool to JetStream, so the users
+// service can close the online session the driver never ended themselves
+func (g *locationGW) PublishDriverEvicted(ctx context.Context, eviction models.DriverEviction) error {
+	data, err := json.Marshal(eviction)
+	if err != nil {
+		return fmt.Errorf("failed to marshal driver eviction: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectLocationDriverEvicted,
+		Data:    data,
+		MsgID:   fmt.Sprintf("driver-evicted-%s-%d", eviction.DriverID, eviction.LastSeenAt.Unix()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 10 * time.Second,
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		return fmt.Errorf("failed to publish driver eviction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+// ListPoolDrivers returns the available drivers the matcher would consider around a point, for debugging
+func (h *LocationHandler) ListPoolDrivers(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Location.ListPoolDrivers")
+
+	location, radius, err := parseNearbyQuery(c)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "admin_list_pool_drivers")
+	nrpkg.AddTransactionAttribute(txn, "search.radius", radius)
+
+	drivers, err := h.locationUC.FindNearbyDrivers(c.Request().Context(), location, radius)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to list pool drivers", logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to list pool drivers")
+	}
+
+	if drivers == nil {
+		drivers = []*models.NearbyUser{}
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Pool drivers retrieved successfully", models.DriverPool{
+		Location: *location,
+		RadiusKm: radius,
+		Count:    len(drivers),
+		Drivers:  drivers,
+	})
+}
+
+// CountPoolDrivers returns the total number of drivers in the available pool
+func (h *LocationHandler) CountPoolDrivers(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Location.CountPoolDrivers")
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "admin_count_pool_drivers")
+
+	count, err := h.locationUC.CountAvailableDrivers(c.Request().Context())
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to count pool drivers", logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to count pool drivers")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Pool size retrieved successfully", models.DriverPoolSize{Count: count})
+}
@@ -0,0 +1,190 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/location/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type driverPoolResponse struct {
+	Success bool              `json:"success"`
+	Data    models.DriverPool `json:"data"`
+}
+
+func TestLocationHandler_ListPoolDrivers(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*mocks.MockLocationUC)
+		expectedStatus int
+		expectedIDs    []string
+	}{
+		{
+			name:  "Returns candidates with distances",
+			query: "lat=-6.175392&lng=106.827153&radius=5",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					FindNearbyDrivers(gomock.Any(), &models.Location{Latitude: -6.175392, Longitude: 106.827153}, float64(5)).
+					Return([]*models.NearbyUser{
+						{ID: "driver-1", Distance: 0.8},
+						{ID: "driver-2", Distance: 2.4},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{"driver-1", "driver-2"},
+		},
+		{
+			name:  "Empty pool",
+			query: "lat=-6.175392&lng=106.827153&radius=5",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					FindNearbyDrivers(gomock.Any(), gomock.Any(), float64(5)).
+					Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedIDs:    []string{},
+		},
+		{
+			name:           "Missing radius",
+			query:          "lat=-6.175392&lng=106.827153",
+			mockSetup:      func(mockUC *mocks.MockLocationUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Usecase error",
+			query: "lat=-6.175392&lng=106.827153&radius=5",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					FindNearbyDrivers(gomock.Any(), gomock.Any(), float64(5)).
+					Return(nil, errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := mocks.NewMockLocationUC(ctrl)
+			tt.mockSetup(mockUC)
+
+			handler := NewLocationHandler(mockUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/pool/drivers?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.ListPoolDrivers(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedStatus != http.StatusOK {
+				return
+			}
+
+			var response driverPoolResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+			assert.True(t, response.Success)
+			assert.Equal(t, len(tt.expectedIDs), response.Data.Count)
+			assert.Equal(t, float64(5), response.Data.RadiusKm)
+			require.NotNil(t, response.Data.Drivers)
+			ids := make([]string, 0, len(response.Data.Drivers))
+			for _, d := range response.Data.Drivers {
+				ids = append(ids, d.ID)
+			}
+			assert.Equal(t, tt.expectedIDs, ids)
+		})
+	}
+}
+
+func TestLocationHandler_ListPoolDrivers_EmptyPoolSerializesEmptyList(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockLocationUC(ctrl)
+	mockUC.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
+	handler := NewLocationHandler(mockUC)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/pool/drivers?lat=-6.1&lng=106.8&radius=1", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	assert.NoError(t, handler.ListPoolDrivers(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"drivers":[]`)
+}
+
+func TestLocationHandler_CountPoolDrivers(t *testing.T) {
+	tests := []struct {
+		name           string
+		mockSetup      func(*mocks.MockLocationUC)
+		expectedStatus int
+		expectedCount  float64
+	}{
+		{
+			name: "Success",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().CountAvailableDrivers(gomock.Any()).Return(int64(7), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  7,
+		},
+		{
+			name: "Empty pool",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().CountAvailableDrivers(gomock.Any()).Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedCount:  0,
+		},
+		{
+			name: "Usecase error",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().CountAvailableDrivers(gomock.Any()).Return(int64(0), errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := mocks.NewMockLocationUC(ctrl)
+			tt.mockSetup(mockUC)
+
+			handler := NewLocationHandler(mockUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/pool/drivers/count", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.CountPoolDrivers(c)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			if tt.expectedStatus == http.StatusOK {
+				var response map[string]interface{}
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+				data := response["data"].(map[string]interface{})
+				assert.Equal(t, tt.expectedCount, data["count"])
+			}
+		})
+	}
+}
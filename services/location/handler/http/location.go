@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
@@ -42,7 +43,7 @@ func (h *LocationHandler) AddAvailableDriver(c echo.Context) error {
 		Location models.Location `json:"location"`
 	}
 
-	if err := c.Bind(&req); err != nil {
+	if err := utils.Bind(c, &req); err != nil {
 		nrpkg.NoticeTransactionError(txn, err)
 		logger.Error("Failed to bind request", logger.ErrorField(err))
 		return utils.BadRequestResponse(c, "invalid request body")
@@ -84,6 +85,42 @@ func (h *LocationHandler) RemoveAvailableDriver(c echo.Context) error {
 	return utils.SuccessResponse(c, http.StatusOK, "Driver removed successfully", map[string]string{"status": "success"})
 }
 
+// UpsertDriver adds a driver to the available pool, or moves them if they are already in it.
+// The driver is identified in the request body rather than the path.
+func (h *LocationHandler) UpsertDriver(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Location.UpsertDriver")
+
+	var req struct {
+		DriverID string          `json:"driver_id"`
+		Location models.Location `json:"location"`
+	}
+
+	if err := utils.Bind(c, &req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to bind request", logger.ErrorField(err))
+		return utils.BadRequestResponse(c, "invalid request body")
+	}
+
+	if req.DriverID == "" {
+		return utils.BadRequestResponse(c, "driver_id is required")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "upsert_driver")
+	nrpkg.AddTransactionAttribute(txn, "driver.id", req.DriverID)
+
+	if err := h.locationUC.AddAvailableDriver(c.Request().Context(), req.DriverID, &req.Location); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to upsert driver",
+			logger.String("driver_id", req.DriverID),
+			logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to upsert driver")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver upserted successfully", map[string]string{"status": "success"})
+}
+
 // AddAvailablePassenger adds a passenger to the available pool
 func (h *LocationHandler) AddAvailablePassenger(c echo.Context) error {
 	// Get transaction from Echo context using centralized package
@@ -102,7 +139,7 @@ func (h *LocationHandler) AddAvailablePassenger(c echo.Context) error {
 		Location models.Location `json:"location"`
 	}
 
-	if err := c.Bind(&req); err != nil {
+	if err := utils.Bind(c, &req); err != nil {
 		nrpkg.NoticeTransactionError(txn, err)
 		logger.Error("Failed to bind request", logger.ErrorField(err))
 		return utils.BadRequestResponse(c, "invalid request body")
@@ -150,37 +187,14 @@ func (h *LocationHandler) FindNearbyDrivers(c echo.Context) error {
 	txn := nrpkg.FromEchoContext(c)
 	nrpkg.SetTransactionName(txn, "Location.FindNearbyDrivers")
 
-	latStr := c.QueryParam("lat")
-	lngStr := c.QueryParam("lng")
-	radiusStr := c.QueryParam("radius")
-
-	if latStr == "" || lngStr == "" || radiusStr == "" {
-		return utils.BadRequestResponse(c, "lat, lng, and radius are required")
-	}
-
-	lat, err := strconv.ParseFloat(latStr, 64)
-	if err != nil {
-		return utils.BadRequestResponse(c, "invalid latitude")
-	}
-
-	lng, err := strconv.ParseFloat(lngStr, 64)
-	if err != nil {
-		return utils.BadRequestResponse(c, "invalid longitude")
-	}
-
-	radius, err := strconv.ParseFloat(radiusStr, 64)
+	location, radius, err := parseNearbyQuery(c)
 	if err != nil {
-		return utils.BadRequestResponse(c, "invalid radius")
-	}
-
-	location := &models.Location{
-		Latitude:  lat,
-		Longitude: lng,
+		return utils.BadRequestResponse(c, err.Error())
 	}
 
 	nrpkg.AddTransactionAttribute(txn, "endpoint", "find_nearby_drivers")
-	nrpkg.AddTransactionAttribute(txn, "location.latitude", lat)
-	nrpkg.AddTransactionAttribute(txn, "location.longitude", lng)
+	nrpkg.AddTransactionAttribute(txn, "location.latitude", location.Latitude)
+	nrpkg.AddTransactionAttribute(txn, "location.longitude", location.Longitude)
 	nrpkg.AddTransactionAttribute(txn, "search.radius", radius)
 
 	drivers, err := h.locationUC.FindNearbyDrivers(c.Request().Context(), location, radius)
@@ -193,6 +207,31 @@ func (h *LocationHandler) FindNearbyDrivers(c echo.Context) error {
 	return utils.SuccessResponse(c, http.StatusOK, "Nearby drivers found", drivers)
 }
 
+// GetDriverHeatmap returns how many available drivers are in each geohash cell of a map view.
+// Only counts are returned, never driver identities or exact positions.
+func (h *LocationHandler) GetDriverHeatmap(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Location.GetDriverHeatmap")
+
+	bounds, precision, err := parseHeatmapQuery(c)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "driver_heatmap")
+	nrpkg.AddTransactionAttribute(txn, "heatmap.precision", precision)
+
+	heatmap, err := h.locationUC.GetDriverHeatmap(c.Request().Context(), bounds, precision)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to build driver heatmap", logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to build driver heatmap")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver heatmap retrieved successfully", heatmap)
+}
+
 // GetDriverLocation gets a driver's location
 func (h *LocationHandler) GetDriverLocation(c echo.Context) error {
 	// Get transaction from Echo context using centralized package
@@ -244,3 +283,110 @@ func (h *LocationHandler) GetPassengerLocation(c echo.Context) error {
 
 	return utils.SuccessResponse(c, http.StatusOK, "Passenger location retrieved", location)
 }
+
+// GetRideLocation gets the driver's latest position reported for a ride
+func (h *LocationHandler) GetRideLocation(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Location.GetRideLocation")
+
+	rideID := c.Param("id")
+	if rideID == "" {
+		return utils.BadRequestResponse(c, "ride_id is required")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "get_ride_location")
+	nrpkg.AddTransactionAttribute(txn, "ride.id", rideID)
+
+	location, err := h.locationUC.GetRideLocation(c.Request().Context(), rideID)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to get ride location",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+		return utils.NotFoundResponse(c, "ride location not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Ride location retrieved", location)
+}
+
+// parseNearbyQuery reads the lat, lng and radius query parameters of a nearby search
+func parseNearbyQuery(c echo.Context) (*models.Location, float64, error) {
+	latStr := c.QueryParam("lat")
+	lngStr := c.QueryParam("lng")
+	radiusStr := c.QueryParam("radius")
+
+	if latStr == "" || lngStr == "" || radiusStr == "" {
+		return nil, 0, errors.New("lat, lng, and radius are required")
+	}
+
+	lat, err := strconv.ParseFloat(latStr, 64)
+	if err != nil {
+		return nil, 0, errors.New("invalid latitude")
+	}
+
+	lng, err := strconv.ParseFloat(lngStr, 64)
+	if err != nil {
+		return nil, 0, errors.New("invalid longitude")
+	}
+
+	radius, err := strconv.ParseFloat(radiusStr, 64)
+	if err != nil {
+		return nil, 0, errors.New("invalid radius")
+	}
+
+	return &models.Location{Latitude: lat, Longitude: lng}, radius, nil
+}
+
+const (
+	// defaultHeatmapPrecision gives cells of roughly 1.2km by 0.6km
+	defaultHeatmapPrecision = 6
+	// minHeatmapPrecision and maxHeatmapPrecision bound the cell size between roughly 40km and 150m
+	minHeatmapPrecision = 4
+	maxHeatmapPrecision = 7
+	// maxHeatmapSpanDegrees caps each side of the bounding box at roughly 110km, about a metro area
+	maxHeatmapSpanDegrees = 1.0
+)
+
+// parseHeatmapQuery reads the bounding box and optional geohash precision of a heatmap request
+func parseHeatmapQuery(c echo.Context) (models.BoundingBox, int, error) {
+	var values [4]float64
+	for i, name := range []string{"min_lat", "min_lng", "max_lat", "max_lng"} {
+		raw := c.QueryParam(name)
+		if raw == "" {
+			return models.BoundingBox{}, 0, errors.New("min_lat, min_lng, max_lat and max_lng are required")
+		}
+		value, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return models.BoundingBox{}, 0, errors.New("invalid " + name)
+		}
+		values[i] = value
+	}
+
+	bounds := models.BoundingBox{
+		MinLatitude:  values[0],
+		MinLongitude: values[1],
+		MaxLatitude:  values[2],
+		MaxLongitude: values[3],
+	}
+	if bounds.MinLatitude < -90 || bounds.MaxLatitude > 90 || bounds.MinLongitude < -180 || bounds.MaxLongitude > 180 {
+		return models.BoundingBox{}, 0, errors.New("bounding box is out of range")
+	}
+	if bounds.MinLatitude >= bounds.MaxLatitude || bounds.MinLongitude >= bounds.MaxLongitude {
+		return models.BoundingBox{}, 0, errors.New("min_lat and min_lng must be less than max_lat and max_lng")
+	}
+	if bounds.MaxLatitude-bounds.MinLatitude > maxHeatmapSpanDegrees || bounds.MaxLongitude-bounds.MinLongitude > maxHeatmapSpanDegrees {
+		return models.BoundingBox{}, 0, errors.New("bounding box may span at most 1 degree of latitude and longitude")
+	}
+
+	precision := defaultHeatmapPrecision
+	if raw := c.QueryParam("precision"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value < minHeatmapPrecision || value > maxHeatmapPrecision {
+			return models.BoundingBox{}, 0, errors.New("precision must be between 4 and 7")
+		}
+		precision = value
+	}
+
+	return bounds, precision, nil
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/piresc/nebengjek/services/location/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/vmihailenco/msgpack/v5"
 )
 
 func TestNewLocationHandler(t *testing.T) {
@@ -206,6 +207,95 @@ func TestLocationHandler_RemoveAvailableDriver(t *testing.T) {
 	}
 }
 
+func TestLocationHandler_UpsertDriver(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestBody    interface{}
+		mockSetup      func(*mocks.MockLocationUC)
+		expectedStatus int
+	}{
+		{
+			name: "Success",
+			requestBody: map[string]interface{}{
+				"driver_id": "driver-123",
+				"location": map[string]interface{}{
+					"latitude":  -6.175392,
+					"longitude": 106.827153,
+				},
+			},
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					AddAvailableDriver(gomock.Any(), "driver-123", &models.Location{Latitude: -6.175392, Longitude: 106.827153}).
+					Return(nil).
+					Times(1)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Missing driver ID",
+			requestBody: map[string]interface{}{
+				"location": map[string]interface{}{
+					"latitude":  -6.175392,
+					"longitude": 106.827153,
+				},
+			},
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				// No expectations - should not call usecase
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:        "Invalid request body",
+			requestBody: "invalid json",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				// No expectations - should not call usecase
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Usecase error",
+			requestBody: map[string]interface{}{
+				"driver_id": "driver-123",
+				"location": map[string]interface{}{
+					"latitude":  -6.175392,
+					"longitude": 106.827153,
+				},
+			},
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					AddAvailableDriver(gomock.Any(), "driver-123", gomock.Any()).
+					Return(errors.New("redis error")).
+					Times(1)
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := mocks.NewMockLocationUC(ctrl)
+			tt.mockSetup(mockUC)
+
+			handler := NewLocationHandler(mockUC)
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(tt.requestBody)
+			req := httptest.NewRequest(http.MethodPost, "/location/driver", bytes.NewBuffer(reqBody))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.UpsertDriver(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
 func TestLocationHandler_AddAvailablePassenger(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -488,4 +578,191 @@ func TestLocationHandler_FindNearbyDrivers(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestLocationHandler_GetDriverHeatmap(t *testing.T) {
+	bounds := models.BoundingBox{MinLatitude: -6.25, MinLongitude: 106.8, MaxLatitude: -6.15, MaxLongitude: 106.88}
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func(*mocks.MockLocationUC)
+		expectedStatus int
+	}{
+		{
+			name:  "Success with default precision",
+			query: "min_lat=-6.25&min_lng=106.8&max_lat=-6.15&max_lng=106.88",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					GetDriverHeatmap(gomock.Any(), bounds, defaultHeatmapPrecision).
+					Return(&models.DriverHeatmap{
+						Bounds:    bounds,
+						Precision: defaultHeatmapPrecision,
+						Cells:     []models.HeatmapCell{{Geohash: "qqguxm", Count: 3}},
+					}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Success with explicit precision",
+			query: "min_lat=-6.25&min_lng=106.8&max_lat=-6.15&max_lng=106.88&precision=5",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					GetDriverHeatmap(gomock.Any(), bounds, 5).
+					Return(&models.DriverHeatmap{Bounds: bounds, Precision: 5, Cells: []models.HeatmapCell{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing bound",
+			query:          "min_lat=-6.25&min_lng=106.8&max_lat=-6.15",
+			mockSetup:      func(mockUC *mocks.MockLocationUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Inverted bounds",
+			query:          "min_lat=-6.15&min_lng=106.8&max_lat=-6.25&max_lng=106.88",
+			mockSetup:      func(mockUC *mocks.MockLocationUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Box too large",
+			query:          "min_lat=-7.0&min_lng=106.0&max_lat=-5.5&max_lng=107.0",
+			mockSetup:      func(mockUC *mocks.MockLocationUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Precision too fine",
+			query:          "min_lat=-6.25&min_lng=106.8&max_lat=-6.15&max_lng=106.88&precision=9",
+			mockSetup:      func(mockUC *mocks.MockLocationUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:  "Usecase error",
+			query: "min_lat=-6.25&min_lng=106.8&max_lat=-6.15&max_lng=106.88",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					GetDriverHeatmap(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil, errors.New("redis error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := mocks.NewMockLocationUC(ctrl)
+			tt.mockSetup(mockUC)
+
+			handler := NewLocationHandler(mockUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/drivers/heatmap?"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			err := handler.GetDriverHeatmap(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestLocationHandler_GetRideLocation(t *testing.T) {
+	tests := []struct {
+		name           string
+		rideID         string
+		mockSetup      func(*mocks.MockLocationUC)
+		expectedStatus int
+	}{
+		{
+			name:   "Success",
+			rideID: "ride-1",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					GetRideLocation(gomock.Any(), "ride-1").
+					Return(&models.Location{Latitude: -6.2, Longitude: 106.8}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing ride ID",
+			rideID:         "",
+			mockSetup:      func(mockUC *mocks.MockLocationUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "No location reported for the ride",
+			rideID: "ride-1",
+			mockSetup: func(mockUC *mocks.MockLocationUC) {
+				mockUC.EXPECT().
+					GetRideLocation(gomock.Any(), "ride-1").
+					Return(nil, errors.New("no location data found for ride ride-1"))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := mocks.NewMockLocationUC(ctrl)
+			tt.mockSetup(mockUC)
+
+			handler := NewLocationHandler(mockUC)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/internal/rides/"+tt.rideID+"/location", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.rideID)
+
+			err := handler.GetRideLocation(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestLocationHandler_AddAvailableDriver_Msgpack(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockLocationUC(ctrl)
+	handler := NewLocationHandler(mockUC)
+
+	var body bytes.Buffer
+	encoder := msgpack.NewEncoder(&body)
+	encoder.SetCustomStructTag("json")
+	assert.NoError(t, encoder.Encode(map[string]interface{}{
+		"location": models.Location{Latitude: -6.175392, Longitude: 106.827153},
+	}))
+
+	mockUC.EXPECT().
+		AddAvailableDriver(gomock.Any(), "driver-123", &models.Location{Latitude: -6.175392, Longitude: 106.827153}).
+		Return(nil)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodPost, "/drivers/driver-123/available", &body)
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationMsgpack)
+	req.Header.Set(echo.HeaderAccept, echo.MIMEApplicationMsgpack)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("driver-123")
+
+	assert.NoError(t, handler.AddAvailableDriver(c))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, echo.MIMEApplicationMsgpack, rec.Header().Get(echo.HeaderContentType))
+
+	var response map[string]interface{}
+	assert.NoError(t, msgpack.Unmarshal(rec.Body.Bytes(), &response))
+	assert.Equal(t, true, response["success"])
+}
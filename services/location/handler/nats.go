@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/nats-io/nats.go"
@@ -20,6 +21,10 @@ type LocationHandler struct {
 	natsClient *natspkg.Client
 	subs       []*nats.Subscription
 	nrApp      *newrelic.Application
+
+	// driverLocks serializes the updates of each driver, whose storing reads the last position
+	// before writing the new one
+	driverLocks *keyedMutex
 }
 
 // NewLocationHandler creates a new location NATS handler
@@ -29,10 +34,11 @@ func NewLocationHandler(
 	nrApp *newrelic.Application,
 ) *LocationHandler {
 	return &LocationHandler{
-		locationUC: locationUC,
-		natsClient: client,
-		subs:       make([]*nats.Subscription, 0),
-		nrApp:      nrApp,
+		locationUC:  locationUC,
+		natsClient:  client,
+		subs:        make([]*nats.Subscription, 0),
+		nrApp:       nrApp,
+		driverLocks: newKeyedMutex(),
 	}
 }
 
@@ -70,8 +76,8 @@ func (h *LocationHandler) InitNATSConsumers() error {
 
 // handleLocationUpdateJS processes location update events from JetStream
 func (h *LocationHandler) handleLocationUpdateJS(msg jetstream.Msg) error {
-	// Create background transaction for NATS message processing
-	txn := h.nrApp.StartTransaction("NATS.Location.HandleLocationUpdate")
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Location.HandleLocationUpdate", msg)
 	defer txn.End()
 
 	// Add message attributes
@@ -85,7 +91,13 @@ func (h *LocationHandler) handleLocationUpdateJS(msg jetstream.Msg) error {
 	logger.InfoCtx(ctx, "Received location update event from JetStream",
 		logger.String("subject", msg.Subject()))
 
-	if err := h.handleLocationUpdate(ctx, msg.Data()); err != nil {
+	// A batched update is only acked once the aggregate carrying it has been published
+	settle := func(err error) { natspkg.Settle(msg, err) }
+	err := h.handleLocationUpdate(ctx, msg.Data(), settle)
+	if errors.Is(err, location.ErrAggregateQueued) {
+		return natspkg.ErrAckDeferred
+	}
+	if err != nil {
 		nrpkg.NoticeTransactionError(txn, err)
 		logger.ErrorCtx(ctx, "Error handling location update event", logger.Err(err))
 		return err // Return error to trigger NAK and retry
@@ -94,8 +106,9 @@ func (h *LocationHandler) handleLocationUpdateJS(msg jetstream.Msg) error {
 	return nil // Success - message will be ACKed automatically
 }
 
-// handleLocationUpdate processes location update events
-func (h *LocationHandler) handleLocationUpdate(ctx context.Context, msg []byte) error {
+// handleLocationUpdate processes location update events. settle is passed on to the use case for
+// updates whose aggregate is queued for a later publish.
+func (h *LocationHandler) handleLocationUpdate(ctx context.Context, msg []byte, settle func(error)) error {
 	var update models.LocationUpdate
 	if err := json.Unmarshal(msg, &update); err != nil {
 		logger.ErrorCtx(ctx, "Failed to unmarshal location update", logger.Err(err))
@@ -114,8 +127,20 @@ func (h *LocationHandler) handleLocationUpdate(ctx context.Context, msg []byte)
 		logger.Float64("latitude", update.Location.Latitude),
 		logger.Float64("longitude", update.Location.Longitude))
 
+	// Overlapping updates from the same driver are stored one at a time; updates without a driver
+	// are keyed by their ride
+	key := update.DriverID
+	if key == "" {
+		key = update.RideID
+	}
+	unlock := h.driverLocks.Lock(key)
+	defer unlock()
+
 	// Store location update
-	err := h.locationUC.StoreLocation(ctx, update)
+	err := h.locationUC.StoreLocation(ctx, update, settle)
+	if errors.Is(err, location.ErrAggregateQueued) {
+		return err
+	}
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to store location update",
 			logger.String("ride_id", update.RideID),
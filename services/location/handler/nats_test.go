@@ -4,16 +4,22 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
+	"github.com/piresc/nebengjek/services/location"
 	"github.com/piresc/nebengjek/services/location/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Test the LocationHandler constructor
@@ -64,7 +70,7 @@ func TestLocationHandler_handleLocationUpdate(t *testing.T) {
 			}(),
 			expectError: false,
 			setupMock: func(m *mocks.MockLocationUC) {
-				m.EXPECT().StoreLocation(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().StoreLocation(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 		},
 		{
@@ -91,7 +97,7 @@ func TestLocationHandler_handleLocationUpdate(t *testing.T) {
 			}(),
 			expectError: true,
 			setupMock: func(m *mocks.MockLocationUC) {
-				m.EXPECT().StoreLocation(gomock.Any(), gomock.Any()).Return(errors.New("usecase error")).Times(1)
+				m.EXPECT().StoreLocation(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("usecase error")).Times(1)
 			},
 		},
 	}
@@ -110,7 +116,7 @@ func TestLocationHandler_handleLocationUpdate(t *testing.T) {
 			handler := NewLocationHandler(mockLocationUC, mockNATSClient, mockNRApp)
 
 			// Act
-			err := handler.handleLocationUpdate(context.Background(), tt.eventData)
+			err := handler.handleLocationUpdate(context.Background(), tt.eventData, nil)
 
 			// Assert
 			if tt.expectError {
@@ -121,3 +127,146 @@ func TestLocationHandler_handleLocationUpdate(t *testing.T) {
 		})
 	}
 }
+
+func locationUpdateData(t *testing.T, rideID, driverID string) []byte {
+	data, err := json.Marshal(models.LocationUpdate{
+		RideID:   rideID,
+		DriverID: driverID,
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: time.Now()},
+	})
+	require.NoError(t, err)
+	return data
+}
+
+func TestLocationHandler_handleLocationUpdate_SerializesPerDriver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocationUC := mocks.NewMockLocationUC(ctrl)
+	handler := NewLocationHandler(mockLocationUC, &natspkg.Client{}, &newrelic.Application{})
+
+	const updates = 20
+	var inFlight, maxInFlight atomic.Int32
+	mockLocationUC.EXPECT().
+		StoreLocation(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ models.LocationUpdate, _ func(error)) error {
+			current := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				seen := maxInFlight.Load()
+				if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return nil
+		}).
+		Times(updates)
+
+	// Overlapping updates from one driver's app
+	rideID, driverID := uuid.New().String(), uuid.New().String()
+	var wg sync.WaitGroup
+	for i := 0; i < updates; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, handler.handleLocationUpdate(context.Background(), locationUpdateData(t, rideID, driverID), nil))
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), maxInFlight.Load())
+	// Released locks are dropped rather than kept for every driver
+	assert.Zero(t, handler.driverLocks.size())
+}
+
+func TestLocationHandler_handleLocationUpdate_DriversProceedInParallel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockLocationUC := mocks.NewMockLocationUC(ctrl)
+	handler := NewLocationHandler(mockLocationUC, &natspkg.Client{}, &newrelic.Application{})
+
+	firstDriver, secondDriver := uuid.New().String(), uuid.New().String()
+	secondStarted := make(chan struct{})
+
+	// The first driver's update only finishes once the second driver's update is being stored,
+	// which could never happen if they shared a lock
+	mockLocationUC.EXPECT().
+		StoreLocation(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, update models.LocationUpdate, _ func(error)) error {
+			if update.DriverID == secondDriver {
+				close(secondStarted)
+				return nil
+			}
+			select {
+			case <-secondStarted:
+				return nil
+			case <-time.After(2 * time.Second):
+				return errors.New("second driver was blocked")
+			}
+		}).
+		Times(2)
+
+	firstDone := make(chan error, 1)
+	go func() {
+		firstDone <- handler.handleLocationUpdate(context.Background(), locationUpdateData(t, uuid.New().String(), firstDriver), nil)
+	}()
+
+	assert.NoError(t, handler.handleLocationUpdate(context.Background(), locationUpdateData(t, uuid.New().String(), secondDriver), nil))
+	assert.NoError(t, <-firstDone)
+}
+
+// settleMsg is a JetStream message that records how it was acknowledged
+type settleMsg struct {
+	jetstream.Msg
+	data  []byte
+	acked bool
+	naked bool
+}
+
+func (m *settleMsg) Subject() string      { return "location.update" }
+func (m *settleMsg) Data() []byte         { return m.data }
+func (m *settleMsg) Headers() nats.Header { return nats.Header{} }
+func (m *settleMsg) Ack() error           { m.acked = true; return nil }
+func (m *settleMsg) Nak() error           { m.naked = true; return nil }
+
+func TestLocationHandler_handleLocationUpdateJS_AcksOncePublished(t *testing.T) {
+	for _, tt := range []struct {
+		name       string
+		publishErr error
+	}{
+		{name: "aggregate published"},
+		{name: "aggregate lost on shutdown", publishErr: errors.New("nats down")},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockLocationUC := mocks.NewMockLocationUC(ctrl)
+			handler := NewLocationHandler(mockLocationUC, &natspkg.Client{}, nil)
+
+			var settle func(error)
+			mockLocationUC.EXPECT().
+				StoreLocation(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, _ models.LocationUpdate, s func(error)) error {
+					settle = s
+					return location.ErrAggregateQueued
+				})
+
+			msg := &settleMsg{data: locationUpdateData(t, uuid.New().String(), uuid.New().String())}
+
+			// The consumer leaves a queued update unacknowledged
+			err := handler.handleLocationUpdateJS(msg)
+			assert.ErrorIs(t, err, natspkg.ErrAckDeferred)
+			require.NotNil(t, settle)
+			assert.False(t, msg.acked)
+			assert.False(t, msg.naked)
+
+			// It is acked once its aggregate is published, or redelivered when that fails
+			settle(tt.publishErr)
+			assert.Equal(t, tt.publishErr == nil, msg.acked)
+			assert.Equal(t, tt.publishErr != nil, msg.naked)
+		})
+	}
+}
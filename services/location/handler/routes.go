@@ -36,16 +36,37 @@ func (h *HTTPHandler) RegisterRoutes(e *echo.Echo, Middleware *middleware.Middle
 	// Internal routes for service-to-service communication (API key required)
 	internal := e.Group("/internal", Middleware.APIKeyHandler("match-service"))
 
-	// Driver routes
+	// Driver pool writes stay with the match service, which owns who is available
 	internal.POST("/drivers/:id/available", h.locationHTTP.AddAvailableDriver)
 	internal.DELETE("/drivers/:id/available", h.locationHTTP.RemoveAvailableDriver)
-	internal.GET("/drivers/:id/location", h.locationHTTP.GetDriverLocation)
-	internal.GET("/drivers/nearby", h.locationHTTP.FindNearbyDrivers)
+
+	// Driver pool reads, open to every service and to admin tools so the pool can be queried over
+	// HTTP, not only through the match service
+	poolReaders := Middleware.APIKeyHandler("match-service", "user-service", "rides-service", "admin")
+	drivers := e.Group("/internal/drivers", poolReaders)
+	drivers.GET("/:id/location", h.locationHTTP.GetDriverLocation)
+	drivers.GET("/nearby", h.locationHTTP.FindNearbyDrivers)
+	drivers.GET("/heatmap", h.locationHTTP.GetDriverHeatmap)
+
+	// On-demand nearest-driver queries and pool maintenance
+	locationGroup := e.Group("/location")
+	locationGroup.GET("/nearby-drivers", h.locationHTTP.FindNearbyDrivers, poolReaders)
+	locationGroup.POST("/driver", h.locationHTTP.UpsertDriver, Middleware.APIKeyHandler("match-service"))
+	locationGroup.DELETE("/driver/:id", h.locationHTTP.RemoveAvailableDriver, Middleware.APIKeyHandler("match-service"))
 
 	// Passenger routes
 	internal.POST("/passengers/:id/available", h.locationHTTP.AddAvailablePassenger)
 	internal.DELETE("/passengers/:id/available", h.locationHTTP.RemoveAvailablePassenger)
 	internal.GET("/passengers/:id/location", h.locationHTTP.GetPassengerLocation)
+
+	// Ride routes, used by the rides service to check where the driver really is
+	rides := e.Group("/internal/rides", Middleware.APIKeyHandler("rides-service", "admin"))
+	rides.GET("/:id/location", h.locationHTTP.GetRideLocation)
+
+	// Admin routes for inspecting the matching pool (admin API key required)
+	admin := e.Group("/admin", Middleware.APIKeyHandler("admin"))
+	admin.GET("/pool/drivers", h.locationHTTP.ListPoolDrivers)
+	admin.GET("/pool/drivers/count", h.locationHTTP.CountPoolDrivers)
 }
 
 // InitNATSConsumers initializes all NATS consumers
@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/middleware"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/location/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestServer registers the location routes with one API key per known service
+func newTestServer(t *testing.T) (*echo.Echo, *mocks.MockLocationUC) {
+	ctrl := gomock.NewController(t)
+	mockUC := mocks.NewMockLocationUC(ctrl)
+
+	e := echo.New()
+	mw := middleware.NewMiddleware(middleware.Config{APIKeys: map[string]string{
+		"match-service": "match-key",
+		"user-service":  "user-key",
+		"rides-service": "rides-key",
+		"admin":         "admin-key",
+	}})
+	NewHTTPHandler(mockUC, nil, &models.Config{}, nil).RegisterRoutes(e, mw)
+	return e, mockUC
+}
+
+func serve(e *echo.Echo, method, path, apiKey string) int {
+	return serveBody(e, method, path, apiKey, "")
+}
+
+func serveBody(e *echo.Echo, method, path, apiKey, body string) int {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if apiKey != "" {
+		req.Header.Set("X-API-Key", apiKey)
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestRegisterRoutes_DriverPoolReadsOpenToAllServices(t *testing.T) {
+	for _, key := range []string{"match-key", "user-key", "rides-key", "admin-key"} {
+		t.Run(key, func(t *testing.T) {
+			e, mockUC := newTestServer(t)
+			mockUC.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), 2.0).Return([]*models.NearbyUser{}, nil).Times(2)
+
+			assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/internal/drivers/nearby?lat=-6.2&lng=106.8&radius=2", key))
+			assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/location/nearby-drivers?lat=-6.2&lng=106.8&radius=2", key))
+		})
+	}
+}
+
+func TestRegisterRoutes_DriverPoolWritesStayMatchOnly(t *testing.T) {
+	body := `{"driver_id":"driver-1","location":{"latitude":-6.2,"longitude":106.8}}`
+	for _, key := range []string{"user-key", "rides-key", "admin-key"} {
+		t.Run(key, func(t *testing.T) {
+			e, _ := newTestServer(t)
+
+			assert.Equal(t, http.StatusUnauthorized, serveBody(e, http.MethodPost, "/internal/drivers/driver-1/available", key, body))
+			assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodDelete, "/internal/drivers/driver-1/available", key))
+			assert.Equal(t, http.StatusUnauthorized, serveBody(e, http.MethodPost, "/location/driver", key, body))
+			assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodDelete, "/location/driver/driver-1", key))
+		})
+	}
+
+	t.Run("match-key", func(t *testing.T) {
+		e, mockUC := newTestServer(t)
+		mockUC.EXPECT().AddAvailableDriver(gomock.Any(), "driver-1", &models.Location{Latitude: -6.2, Longitude: 106.8}).Return(nil).Times(2)
+		mockUC.EXPECT().RemoveAvailableDriver(gomock.Any(), "driver-1").Return(nil).Times(2)
+
+		assert.Equal(t, http.StatusOK, serveBody(e, http.MethodPost, "/internal/drivers/driver-1/available", "match-key", body))
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodDelete, "/internal/drivers/driver-1/available", "match-key"))
+		assert.Equal(t, http.StatusOK, serveBody(e, http.MethodPost, "/location/driver", "match-key", body))
+		assert.Equal(t, http.StatusOK, serve(e, http.MethodDelete, "/location/driver/driver-1", "match-key"))
+	})
+}
+
+func TestRegisterRoutes_DriverPoolRequiresValidKey(t *testing.T) {
+	e, _ := newTestServer(t)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodGet, "/internal/drivers/nearby?lat=-6.2&lng=106.8&radius=2", ""))
+	assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodGet, "/internal/drivers/nearby?lat=-6.2&lng=106.8&radius=2", "wrong-key"))
+	assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodGet, "/location/nearby-drivers?lat=-6.2&lng=106.8&radius=2", ""))
+}
+
+func TestRegisterRoutes_PassengerPoolStaysMatchOnly(t *testing.T) {
+	e, mockUC := newTestServer(t)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodDelete, "/internal/passengers/passenger-1/available", "user-key"))
+
+	mockUC.EXPECT().RemoveAvailablePassenger(gomock.Any(), "passenger-1").Return(nil)
+	assert.Equal(t, http.StatusOK, serve(e, http.MethodDelete, "/internal/passengers/passenger-1/available", "match-key"))
+}
+
+func TestRegisterRoutes_RideLocationOpenToRides(t *testing.T) {
+	e, mockUC := newTestServer(t)
+
+	assert.Equal(t, http.StatusUnauthorized, serve(e, http.MethodGet, "/internal/rides/ride-1/location", "user-key"))
+
+	mockUC.EXPECT().GetRideLocation(gomock.Any(), "ride-1").Return(&models.Location{Latitude: -6.2, Longitude: 106.8}, nil)
+	assert.Equal(t, http.StatusOK, serve(e, http.MethodGet, "/internal/rides/ride-1/location", "rides-key"))
+}
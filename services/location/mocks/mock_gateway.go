@@ -35,6 +35,20 @@ func (m *MockLocationGW) EXPECT() *MockLocationGWMockRecorder {
 	return m.recorder
 }
 
+// PublishDriverEvicted mocks base method.
+func (m *MockLocationGW) PublishDriverEvicted(ctx context.Context, eviction models.DriverEviction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishDriverEvicted", ctx, eviction)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishDriverEvicted indicates an expected call of PublishDriverEvicted.
+func (mr *MockLocationGWMockRecorder) PublishDriverEvicted(ctx, eviction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDriverEvicted", reflect.TypeOf((*MockLocationGW)(nil).PublishDriverEvicted), ctx, eviction)
+}
+
 // PublishLocationAggregate mocks base method.
 func (m *MockLocationGW) PublishLocationAggregate(ctx context.Context, aggregate models.LocationAggregate) error {
 	m.ctrl.T.Helper()
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/piresc/nebengjek/internal/pkg/models"
@@ -63,6 +64,51 @@ func (mr *MockLocationRepoMockRecorder) AddAvailablePassenger(arg0, arg1, arg2 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAvailablePassenger", reflect.TypeOf((*MockLocationRepo)(nil).AddAvailablePassenger), arg0, arg1, arg2)
 }
 
+// CountAvailableDrivers mocks base method.
+func (m *MockLocationRepo) CountAvailableDrivers(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAvailableDrivers", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAvailableDrivers indicates an expected call of CountAvailableDrivers.
+func (mr *MockLocationRepoMockRecorder) CountAvailableDrivers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAvailableDrivers", reflect.TypeOf((*MockLocationRepo)(nil).CountAvailableDrivers), arg0)
+}
+
+// CountDriversByCell mocks base method.
+func (m *MockLocationRepo) CountDriversByCell(arg0 context.Context, arg1 models.BoundingBox, arg2 int) (map[string]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDriversByCell", arg0, arg1, arg2)
+	ret0, _ := ret[0].(map[string]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDriversByCell indicates an expected call of CountDriversByCell.
+func (mr *MockLocationRepoMockRecorder) CountDriversByCell(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDriversByCell", reflect.TypeOf((*MockLocationRepo)(nil).CountDriversByCell), arg0, arg1, arg2)
+}
+
+// EvictStaleDrivers mocks base method.
+func (m *MockLocationRepo) EvictStaleDrivers(arg0 context.Context) ([]models.DriverEviction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictStaleDrivers", arg0)
+	ret0, _ := ret[0].([]models.DriverEviction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EvictStaleDrivers indicates an expected call of EvictStaleDrivers.
+func (mr *MockLocationRepoMockRecorder) EvictStaleDrivers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictStaleDrivers", reflect.TypeOf((*MockLocationRepo)(nil).EvictStaleDrivers), arg0)
+}
+
 // FindNearbyDrivers mocks base method.
 func (m *MockLocationRepo) FindNearbyDrivers(arg0 context.Context, arg1 *models.Location, arg2 float64) ([]*models.NearbyUser, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +169,20 @@ func (mr *MockLocationRepoMockRecorder) GetPassengerLocation(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPassengerLocation", reflect.TypeOf((*MockLocationRepo)(nil).GetPassengerLocation), arg0, arg1)
 }
 
+// RefreshDriverPresence mocks base method.
+func (m *MockLocationRepo) RefreshDriverPresence(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshDriverPresence", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshDriverPresence indicates an expected call of RefreshDriverPresence.
+func (mr *MockLocationRepoMockRecorder) RefreshDriverPresence(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshDriverPresence", reflect.TypeOf((*MockLocationRepo)(nil).RefreshDriverPresence), arg0, arg1)
+}
+
 // RemoveAvailableDriver mocks base method.
 func (m *MockLocationRepo) RemoveAvailableDriver(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -152,15 +212,15 @@ func (mr *MockLocationRepoMockRecorder) RemoveAvailablePassenger(arg0, arg1 inte
 }
 
 // StoreLocation mocks base method.
-func (m *MockLocationRepo) StoreLocation(arg0 context.Context, arg1 string, arg2 models.Location) error {
+func (m *MockLocationRepo) StoreLocation(arg0 context.Context, arg1 string, arg2 models.Location, arg3 time.Time) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StoreLocation", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "StoreLocation", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StoreLocation indicates an expected call of StoreLocation.
-func (mr *MockLocationRepoMockRecorder) StoreLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockLocationRepoMockRecorder) StoreLocation(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreLocation", reflect.TypeOf((*MockLocationRepo)(nil).StoreLocation), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreLocation", reflect.TypeOf((*MockLocationRepo)(nil).StoreLocation), arg0, arg1, arg2, arg3)
 }
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/piresc/nebengjek/internal/pkg/models"
@@ -63,6 +64,36 @@ func (mr *MockLocationUCMockRecorder) AddAvailablePassenger(arg0, arg1, arg2 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddAvailablePassenger", reflect.TypeOf((*MockLocationUC)(nil).AddAvailablePassenger), arg0, arg1, arg2)
 }
 
+// CountAvailableDrivers mocks base method.
+func (m *MockLocationUC) CountAvailableDrivers(arg0 context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountAvailableDrivers", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountAvailableDrivers indicates an expected call of CountAvailableDrivers.
+func (mr *MockLocationUCMockRecorder) CountAvailableDrivers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountAvailableDrivers", reflect.TypeOf((*MockLocationUC)(nil).CountAvailableDrivers), arg0)
+}
+
+// EvictStaleDrivers mocks base method.
+func (m *MockLocationUC) EvictStaleDrivers(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EvictStaleDrivers", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EvictStaleDrivers indicates an expected call of EvictStaleDrivers.
+func (mr *MockLocationUCMockRecorder) EvictStaleDrivers(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EvictStaleDrivers", reflect.TypeOf((*MockLocationUC)(nil).EvictStaleDrivers), arg0)
+}
+
 // FindNearbyDrivers mocks base method.
 func (m *MockLocationUC) FindNearbyDrivers(arg0 context.Context, arg1 *models.Location, arg2 float64) ([]*models.NearbyUser, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +109,36 @@ func (mr *MockLocationUCMockRecorder) FindNearbyDrivers(arg0, arg1, arg2 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindNearbyDrivers", reflect.TypeOf((*MockLocationUC)(nil).FindNearbyDrivers), arg0, arg1, arg2)
 }
 
+// FlushLocationAggregates mocks base method.
+func (m *MockLocationUC) FlushLocationAggregates(arg0 context.Context) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FlushLocationAggregates", arg0)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FlushLocationAggregates indicates an expected call of FlushLocationAggregates.
+func (mr *MockLocationUCMockRecorder) FlushLocationAggregates(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FlushLocationAggregates", reflect.TypeOf((*MockLocationUC)(nil).FlushLocationAggregates), arg0)
+}
+
+// GetDriverHeatmap mocks base method.
+func (m *MockLocationUC) GetDriverHeatmap(arg0 context.Context, arg1 models.BoundingBox, arg2 int) (*models.DriverHeatmap, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverHeatmap", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.DriverHeatmap)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverHeatmap indicates an expected call of GetDriverHeatmap.
+func (mr *MockLocationUCMockRecorder) GetDriverHeatmap(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverHeatmap", reflect.TypeOf((*MockLocationUC)(nil).GetDriverHeatmap), arg0, arg1, arg2)
+}
+
 // GetDriverLocation mocks base method.
 func (m *MockLocationUC) GetDriverLocation(arg0 context.Context, arg1 string) (models.Location, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +169,21 @@ func (mr *MockLocationUCMockRecorder) GetPassengerLocation(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPassengerLocation", reflect.TypeOf((*MockLocationUC)(nil).GetPassengerLocation), arg0, arg1)
 }
 
+// GetRideLocation mocks base method.
+func (m *MockLocationUC) GetRideLocation(arg0 context.Context, arg1 string) (*models.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRideLocation", arg0, arg1)
+	ret0, _ := ret[0].(*models.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRideLocation indicates an expected call of GetRideLocation.
+func (mr *MockLocationUCMockRecorder) GetRideLocation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRideLocation", reflect.TypeOf((*MockLocationUC)(nil).GetRideLocation), arg0, arg1)
+}
+
 // RemoveAvailableDriver mocks base method.
 func (m *MockLocationUC) RemoveAvailableDriver(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -136,16 +212,40 @@ func (mr *MockLocationUCMockRecorder) RemoveAvailablePassenger(arg0, arg1 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAvailablePassenger", reflect.TypeOf((*MockLocationUC)(nil).RemoveAvailablePassenger), arg0, arg1)
 }
 
+// RunLocationPublisher mocks base method.
+func (m *MockLocationUC) RunLocationPublisher(arg0 context.Context, arg1 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunLocationPublisher", arg0, arg1)
+}
+
+// RunLocationPublisher indicates an expected call of RunLocationPublisher.
+func (mr *MockLocationUCMockRecorder) RunLocationPublisher(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunLocationPublisher", reflect.TypeOf((*MockLocationUC)(nil).RunLocationPublisher), arg0, arg1)
+}
+
+// RunPoolSweeper mocks base method.
+func (m *MockLocationUC) RunPoolSweeper(arg0 context.Context, arg1 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunPoolSweeper", arg0, arg1)
+}
+
+// RunPoolSweeper indicates an expected call of RunPoolSweeper.
+func (mr *MockLocationUCMockRecorder) RunPoolSweeper(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunPoolSweeper", reflect.TypeOf((*MockLocationUC)(nil).RunPoolSweeper), arg0, arg1)
+}
+
 // StoreLocation mocks base method.
-func (m *MockLocationUC) StoreLocation(arg0 context.Context, arg1 models.LocationUpdate) error {
+func (m *MockLocationUC) StoreLocation(arg0 context.Context, arg1 models.LocationUpdate, arg2 func(error)) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "StoreLocation", arg0, arg1)
+	ret := m.ctrl.Call(m, "StoreLocation", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // StoreLocation indicates an expected call of StoreLocation.
-func (mr *MockLocationUCMockRecorder) StoreLocation(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockLocationUCMockRecorder) StoreLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreLocation", reflect.TypeOf((*MockLocationUC)(nil).StoreLocation), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreLocation", reflect.TypeOf((*MockLocationUC)(nil).StoreLocation), arg0, arg1, arg2)
 }
@@ -2,6 +2,7 @@ package location
 
 import (
 	"context"
+	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
@@ -10,10 +11,10 @@ import (
 
 // LocationRepo defines the interface for location data access operations
 type LocationRepo interface {
-	// StoreLocation stores a location update in Redis for a ride
-	StoreLocation(ctx context.Context, rideID string, location models.Location) error
+	// StoreLocation stores a location update in Redis for a ride along with when it was received
+	StoreLocation(ctx context.Context, rideID string, location models.Location, receivedAt time.Time) error
 
-	// GetLastLocation gets the last stored location for a ride
+	// GetLastLocation gets the last stored location for a ride, timestamped with when it was received
 	GetLastLocation(ctx context.Context, rideID string) (*models.Location, error)
 
 	// Geo-related methods moved from match service
@@ -32,7 +33,19 @@ type LocationRepo interface {
 	// FindNearbyDrivers finds available drivers within the specified radius
 	FindNearbyDrivers(ctx context.Context, location *models.Location, radiusKm float64) ([]*models.NearbyUser, error)
 
-	// GetDriverLocation retrieves a driver's last known location
+	// RefreshDriverPresence extends the presence of a driver still in the available pool
+	RefreshDriverPresence(ctx context.Context, driverID string) error
+
+	// EvictStaleDrivers removes drivers whose presence has expired from the pool, returning who was evicted
+	EvictStaleDrivers(ctx context.Context) ([]models.DriverEviction, error)
+
+	// CountAvailableDrivers returns the number of drivers in the available pool
+	CountAvailableDrivers(ctx context.Context) (int64, error)
+
+	// CountDriversByCell counts the available drivers within bounds per geohash cell of the given precision
+	CountDriversByCell(ctx context.Context, bounds models.BoundingBox, precision int) (map[string]int, error)
+
+	// GetDriverLocation retrieves a driver's last known location while they are available
 	GetDriverLocation(ctx context.Context, driverID string) (models.Location, error)
 
 	// GetPassengerLocation retrieves a passenger's last known location
@@ -2,13 +2,16 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
 	"github.com/piresc/nebengjek/services/location"
 )
 
@@ -16,11 +19,15 @@ const (
 	// LocationTTL is how long we keep location data in Redis
 	// We keep it for 24 hours to allow for trip history analysis
 	LocationTTL = 24 * time.Hour
+
+	// defaultDriverPresenceTTL is used when no driver presence TTL is configured
+	defaultDriverPresenceTTL = 2 * time.Minute
 )
 
 type locationRepo struct {
-	redisClient     *database.RedisClient
-	availabilityTTL time.Duration
+	redisClient       *database.RedisClient
+	availabilityTTL   time.Duration
+	driverPresenceTTL time.Duration
 }
 
 // NewLocationRepository creates a new location repository
@@ -31,37 +38,52 @@ func NewLocationRepository(redisClient *database.RedisClient, config *models.Con
 		ttlMinutes = config.Location.AvailabilityTTLMinutes
 	}
 
+	driverPresenceTTL := defaultDriverPresenceTTL
+	if config != nil && config.Location.DriverPresenceTTLSecs > 0 {
+		driverPresenceTTL = time.Duration(config.Location.DriverPresenceTTLSecs) * time.Second
+	}
+
 	return &locationRepo{
-		redisClient:     redisClient,
-		availabilityTTL: time.Duration(ttlMinutes) * time.Minute,
+		redisClient:       redisClient,
+		availabilityTTL:   time.Duration(ttlMinutes) * time.Minute,
+		driverPresenceTTL: driverPresenceTTL,
 	}
 }
 
-// StoreLocation stores a location update in Redis for a ride
-func (r *locationRepo) StoreLocation(ctx context.Context, rideID string, location models.Location) error {
-	// Store in ride location hash
+// storeLocationScript writes a ride location unless the stored one is newer. Comparing and writing
+// in one script keeps an out-of-order update from overwriting a newer position between the two.
+var storeLocationScript = redis.NewScript(`
+local stored = tonumber(redis.call('HGET', KEYS[1], ARGV[5]))
+if stored and stored > tonumber(ARGV[6]) then
+	return 0
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[2], ARGV[3], ARGV[4], ARGV[5], ARGV[6], ARGV[7], ARGV[8])
+redis.call('EXPIRE', KEYS[1], ARGV[9])
+return 1
+`)
+
+// StoreLocation stores a location update in Redis for a ride along with when the service received
+// it. An update whose timestamp is older than the stored one is rejected with location.ErrStaleLocation.
+func (r *locationRepo) StoreLocation(ctx context.Context, rideID string, loc models.Location, receivedAt time.Time) error {
 	locationKey := fmt.Sprintf(constants.KeyRideLocation, rideID)
-	locationData := map[string]interface{}{
-		constants.FieldLatitude:  strconv.FormatFloat(location.Latitude, 'f', -1, 64),
-		constants.FieldLongitude: strconv.FormatFloat(location.Longitude, 'f', -1, 64),
-		constants.FieldTimestamp: strconv.FormatInt(location.Timestamp.Unix(), 10),
-	}
-
-	err := r.redisClient.HMSet(ctx, locationKey, locationData)
+	stored, err := r.redisClient.RunScript(ctx, storeLocationScript, []string{locationKey},
+		constants.FieldLatitude, strconv.FormatFloat(loc.Latitude, 'f', -1, 64),
+		constants.FieldLongitude, strconv.FormatFloat(loc.Longitude, 'f', -1, 64),
+		constants.FieldTimestamp, strconv.FormatInt(loc.Timestamp.Unix(), 10),
+		constants.FieldReceivedAt, strconv.FormatInt(receivedAt.Unix(), 10),
+		int64(LocationTTL/time.Second))
 	if err != nil {
 		return fmt.Errorf("failed to store location update: %w", err)
 	}
-
-	// Set TTL using Expire
-	err = r.redisClient.Expire(ctx, locationKey, LocationTTL)
-	if err != nil {
-		return fmt.Errorf("failed to set location TTL: %w", err)
+	if n, _ := stored.(int64); n == 0 {
+		return fmt.Errorf("%w: ride %s", location.ErrStaleLocation, rideID)
 	}
 
 	return nil
 }
 
-// GetLastLocation gets the last stored location for a ride
+// GetLastLocation gets the last stored location for a ride. Its timestamp is when the location
+// service received it, since the device's own clock can't be trusted for timing.
 func (r *locationRepo) GetLastLocation(ctx context.Context, rideID string) (*models.Location, error) {
 	locationKey := fmt.Sprintf(constants.KeyRideLocation, rideID)
 
@@ -70,6 +92,7 @@ func (r *locationRepo) GetLastLocation(ctx context.Context, rideID string) (*mod
 		constants.FieldLatitude,
 		constants.FieldLongitude,
 		constants.FieldTimestamp,
+		constants.FieldReceivedAt,
 	}
 
 	values, err := r.redisClient.HMGet(ctx, locationKey, fields...)
@@ -86,7 +109,7 @@ func (r *locationRepo) GetLastLocation(ctx context.Context, rideID string) (*mod
 		}
 	}
 
-	if !hasValue || len(values) != 3 {
+	if !hasValue || len(values) != 4 {
 		return nil, fmt.Errorf("no location data found for ride %s", rideID)
 	}
 
@@ -102,8 +125,13 @@ func (r *locationRepo) GetLastLocation(ctx context.Context, rideID string) (*mod
 		return nil, fmt.Errorf("invalid longitude: %w", err)
 	}
 
-	// Parse timestamp
-	ts, err := strconv.ParseInt(values[2], 10, 64)
+	// Parse timestamp, preferring the receive time; locations stored before it was recorded only
+	// carry the device timestamp
+	tsValue := values[3]
+	if tsValue == "" {
+		tsValue = values[2]
+	}
+	ts, err := strconv.ParseInt(tsValue, 10, 64)
 	if err != nil {
 		return nil, fmt.Errorf("invalid timestamp: %w", err)
 	}
@@ -177,7 +205,8 @@ func (r *locationRepo) removeFromRedisGeo(ctx context.Context, geoKey, available
 	return nil
 }
 
-// AddAvailableDriver adds a driver to the available drivers geo set
+// AddAvailableDriver adds a driver to the available drivers geo set and refreshes their presence,
+// which lapses if no further beacon arrives within the presence TTL
 func (r *locationRepo) AddAvailableDriver(ctx context.Context, driverID string, location *models.Location) error {
 	err := r.addToRedisGeo(ctx,
 		constants.KeyDriverGeo,
@@ -190,16 +219,106 @@ func (r *locationRepo) AddAvailableDriver(ctx context.Context, driverID string,
 		return err
 	}
 
+	presenceKey := fmt.Sprintf(constants.KeyDriverPresence, driverID)
+	if err := r.redisClient.Set(ctx, presenceKey, time.Now().Unix(), r.driverPresenceTTL); err != nil {
+		return fmt.Errorf("failed to refresh driver presence: %w", err)
+	}
+
+	return nil
+}
+
+// refreshPresenceScript extends a pooled driver's presence and last-seen time. Drivers no longer in
+// the available set are left alone, so a ride location update can't bring a driver back.
+var refreshPresenceScript = redis.NewScript(`
+if redis.call('SISMEMBER', KEYS[1], ARGV[1]) == 0 then
+	return 0
+end
+redis.call('SET', KEYS[2], ARGV[2], 'EX', ARGV[3])
+if redis.call('EXISTS', KEYS[3]) == 1 then
+	redis.call('HSET', KEYS[3], ARGV[4], ARGV[2])
+end
+return 1
+`)
+
+// RefreshDriverPresence keeps a pooled driver from being evicted while they send location updates
+// other than beacons, such as a driver on a ride who accepts their next match
+func (r *locationRepo) RefreshDriverPresence(ctx context.Context, driverID string) error {
+	keys := []string{
+		constants.KeyAvailableDrivers,
+		fmt.Sprintf(constants.KeyDriverPresence, driverID),
+		fmt.Sprintf(constants.KeyDriverLocation, driverID),
+	}
+	if _, err := r.redisClient.RunScript(ctx, refreshPresenceScript, keys,
+		driverID, time.Now().Unix(), int64(r.driverPresenceTTL/time.Second), constants.FieldTimestamp); err != nil {
+		return fmt.Errorf("failed to refresh driver presence: %w", err)
+	}
 	return nil
 }
 
 // RemoveAvailableDriver removes a driver from the available drivers sets
 func (r *locationRepo) RemoveAvailableDriver(ctx context.Context, driverID string) error {
-	return r.removeFromRedisGeo(ctx,
+	if err := r.removeFromRedisGeo(ctx,
 		constants.KeyDriverGeo,
 		constants.KeyAvailableDrivers,
 		constants.KeyDriverLocation,
-		driverID)
+		driverID); err != nil {
+		return err
+	}
+
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyDriverPresence, driverID)); err != nil {
+		return fmt.Errorf("failed to remove driver presence: %w", err)
+	}
+	return nil
+}
+
+// evictDriverScript removes a driver from the pool unless their presence is live. Checking and
+// removing in one script keeps a beacon that lands mid-sweep from being evicted along with the
+// stale presence it just replaced. It returns false if the driver is present, otherwise when the
+// driver was last seen, or 0 if that is unknown.
+var evictDriverScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return false
+end
+local seen = redis.call('HGET', KEYS[4], ARGV[2])
+redis.call('ZREM', KEYS[2], ARGV[1])
+redis.call('SREM', KEYS[3], ARGV[1])
+redis.call('DEL', KEYS[4])
+return tonumber(seen) or 0
+`)
+
+// EvictStaleDrivers removes drivers whose presence has expired from the geo index and
+// available set, returning who was evicted and when they were last seen
+func (r *locationRepo) EvictStaleDrivers(ctx context.Context) ([]models.DriverEviction, error) {
+	members, err := r.redisClient.ZRange(ctx, constants.KeyDriverGeo, 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list driver geo index: %w", err)
+	}
+
+	var evicted []models.DriverEviction
+	for _, driverID := range members {
+		keys := []string{
+			fmt.Sprintf(constants.KeyDriverPresence, driverID),
+			constants.KeyDriverGeo,
+			constants.KeyAvailableDrivers,
+			fmt.Sprintf(constants.KeyDriverLocation, driverID),
+		}
+		result, err := r.redisClient.RunScript(ctx, evictDriverScript, keys, driverID, constants.FieldTimestamp)
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return evicted, fmt.Errorf("failed to evict driver: %w", err)
+		}
+
+		// Without a recorded beacon the driver went silent at least a presence TTL ago
+		lastSeen := time.Now().Add(-r.driverPresenceTTL)
+		if seen, _ := result.(int64); seen > 0 {
+			lastSeen = time.Unix(seen, 0)
+		}
+		evicted = append(evicted, models.DriverEviction{DriverID: driverID, LastSeenAt: lastSeen})
+	}
+
+	return evicted, nil
 }
 
 // AddAvailablePassenger adds a passenger to the Redis geospatial index
@@ -235,14 +354,18 @@ func (r *locationRepo) findNearbyUsers(ctx context.Context, geoKey, availableKey
 		return nil, fmt.Errorf("failed to find nearby users: %w", err)
 	}
 
-	nearbyUsers := make([]*models.NearbyUser, 0, len(results))
-	for _, result := range results {
-		isMember, err := r.redisClient.SIsMember(ctx, availableKey, result.Name)
-		if err != nil {
-			return nil, fmt.Errorf("failed to check user availability: %w", err)
-		}
+	names := make([]interface{}, len(results))
+	for i, result := range results {
+		names[i] = result.Name
+	}
+	available, err := r.redisClient.SMIsMember(ctx, availableKey, names...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user availability: %w", err)
+	}
 
-		if isMember {
+	nearbyUsers := make([]*models.NearbyUser, 0, len(results))
+	for i, result := range results {
+		if available[i] {
 			nearbyUsers = append(nearbyUsers, &models.NearbyUser{
 				ID: result.Name,
 				Location: models.Location{
@@ -258,18 +381,95 @@ func (r *locationRepo) findNearbyUsers(ctx context.Context, geoKey, availableKey
 	return nearbyUsers, nil
 }
 
-// FindNearbyDrivers finds available drivers within the specified radius
+// FindNearbyDrivers finds available drivers within the specified radius. Drivers whose presence
+// has lapsed are left out even before the sweeper evicts them.
 func (r *locationRepo) FindNearbyDrivers(ctx context.Context, location *models.Location, radiusKm float64) ([]*models.NearbyUser, error) {
 	nearbyUsers, err := r.findNearbyUsers(ctx, constants.KeyDriverGeo, constants.KeyAvailableDrivers, location, radiusKm)
 	if err != nil {
 		return nil, err
 	}
 
-	return nearbyUsers, nil
+	return r.filterPresentDrivers(ctx, nearbyUsers)
+}
+
+// filterPresentDrivers drops drivers whose presence has lapsed, checking them all in one round trip
+func (r *locationRepo) filterPresentDrivers(ctx context.Context, drivers []*models.NearbyUser) ([]*models.NearbyUser, error) {
+	keys := make([]string, len(drivers))
+	for i, driver := range drivers {
+		keys[i] = fmt.Sprintf(constants.KeyDriverPresence, driver.ID)
+	}
+	exists, err := r.redisClient.ExistsEach(ctx, keys...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check driver presence: %w", err)
+	}
+
+	present := drivers[:0]
+	for i, driver := range drivers {
+		if exists[i] {
+			present = append(present, driver)
+		}
+	}
+	return present, nil
+}
+
+// CountAvailableDrivers returns the number of drivers in the available pool
+func (r *locationRepo) CountAvailableDrivers(ctx context.Context) (int64, error) {
+	count, err := r.redisClient.SCard(ctx, constants.KeyAvailableDrivers)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count available drivers: %w", err)
+	}
+	return count, nil
 }
 
-// GetDriverLocation retrieves a driver's last known location
+// CountDriversByCell counts the available drivers within bounds per geohash cell of the given precision.
+// The pool is searched within the circle enclosing the box and then trimmed to the box itself. As with
+// FindNearbyDrivers, drivers whose presence has lapsed are not counted.
+func (r *locationRepo) CountDriversByCell(ctx context.Context, bounds models.BoundingBox, precision int) (map[string]int, error) {
+	center := utils.GeoPoint{
+		Latitude:  (bounds.MinLatitude + bounds.MaxLatitude) / 2,
+		Longitude: (bounds.MinLongitude + bounds.MaxLongitude) / 2,
+	}
+	corner := utils.GeoPoint{Latitude: bounds.MaxLatitude, Longitude: bounds.MaxLongitude}
+	// A little slack keeps drivers on the box corners inside the search circle
+	radiusKm := utils.CalculateDistance(center, corner) * 1.01
+
+	nearby, err := r.findNearbyUsers(ctx, constants.KeyDriverGeo, constants.KeyAvailableDrivers,
+		&models.Location{Latitude: center.Latitude, Longitude: center.Longitude}, radiusKm)
+	if err != nil {
+		return nil, err
+	}
+
+	inBounds := nearby[:0]
+	for _, driver := range nearby {
+		if bounds.Contains(driver.Location.Latitude, driver.Location.Longitude) {
+			inBounds = append(inBounds, driver)
+		}
+	}
+	drivers, err := r.filterPresentDrivers(ctx, inBounds)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, driver := range drivers {
+		point := utils.GeoPoint{Latitude: driver.Location.Latitude, Longitude: driver.Location.Longitude}
+		counts[utils.EncodeGeohash(point, precision)]++
+	}
+	return counts, nil
+}
+
+// GetDriverLocation retrieves a driver's last known location. The position is only shared while the
+// driver is in the available pool, so it stops being served once their ride completes and until
+// they beacon as available again.
 func (r *locationRepo) GetDriverLocation(ctx context.Context, driverID string) (models.Location, error) {
+	available, err := r.redisClient.SIsMember(ctx, constants.KeyAvailableDrivers, driverID)
+	if err != nil {
+		return models.Location{}, fmt.Errorf("failed to check driver availability: %w", err)
+	}
+	if !available {
+		return models.Location{}, fmt.Errorf("no location data found for driver %s", driverID)
+	}
+
 	// Try to get from the Redis location key
 	locationKey := fmt.Sprintf(constants.KeyDriverLocation, driverID)
 	fields, err := r.redisClient.HGetAll(ctx, locationKey)
@@ -11,6 +11,7 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/location"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -48,7 +49,7 @@ func TestStoreLocation(t *testing.T) {
 	}
 
 	// Act - Call the method being tested
-	err := repo.StoreLocation(ctx, rideID, location)
+	err := repo.StoreLocation(ctx, rideID, location, time.Now())
 
 	// Assert the results
 	assert.NoError(t, err)
@@ -74,6 +75,23 @@ func TestStoreLocation(t *testing.T) {
 	}
 }
 
+func TestGetLastLocation_ReturnsReceiveTime(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{Client: client}, &models.Config{})
+	ctx := context.Background()
+	receivedAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// The device clock is an hour behind; the stored location is timed by when it arrived
+	loc := models.Location{Latitude: -6.2, Longitude: 106.8, Timestamp: receivedAt.Add(-time.Hour)}
+	require.NoError(t, repo.StoreLocation(ctx, "ride-123", loc, receivedAt))
+
+	stored, err := repo.GetLastLocation(ctx, "ride-123")
+	require.NoError(t, err)
+	assert.Equal(t, receivedAt.Unix(), stored.Timestamp.Unix())
+}
+
 func TestStoreLocation_RedisError(t *testing.T) {
 	// Setup miniredis
 	mr, client := setupMiniredis(t)
@@ -99,13 +117,41 @@ func TestStoreLocation_RedisError(t *testing.T) {
 	mr.Close()
 
 	// Act - Call the method being tested
-	err := repo.StoreLocation(ctx, rideID, location)
+	err := repo.StoreLocation(ctx, rideID, location, time.Now())
 
 	// Assert the results
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to store location")
 }
 
+func TestStoreLocation_RejectsOlderUpdate(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{Client: client}, &models.Config{})
+	ctx := context.Background()
+	now := time.Now()
+
+	newer := models.Location{Latitude: -6.2, Longitude: 106.8, Timestamp: now}
+	require.NoError(t, repo.StoreLocation(ctx, "ride-123", newer, time.Now()))
+
+	older := models.Location{Latitude: -6.1, Longitude: 106.7, Timestamp: now.Add(-10 * time.Second)}
+	err := repo.StoreLocation(ctx, "ride-123", older, time.Now())
+	assert.ErrorIs(t, err, location.ErrStaleLocation)
+
+	stored, err := repo.GetLastLocation(ctx, "ride-123")
+	require.NoError(t, err)
+	assert.Equal(t, newer.Latitude, stored.Latitude, "the newer position must not be overwritten")
+	assert.Equal(t, now.Unix(), stored.Timestamp.Unix())
+
+	later := models.Location{Latitude: -6.3, Longitude: 106.9, Timestamp: now.Add(5 * time.Second)}
+	require.NoError(t, repo.StoreLocation(ctx, "ride-123", later, time.Now()))
+	stored, err = repo.GetLastLocation(ctx, "ride-123")
+	require.NoError(t, err)
+	assert.Equal(t, later.Latitude, stored.Latitude)
+	assert.Greater(t, mr.TTL(fmt.Sprintf(constants.KeyRideLocation, "ride-123")), time.Duration(0))
+}
+
 func TestGetLastLocation(t *testing.T) {
 	// Setup miniredis
 	mr, client := setupMiniredis(t)
@@ -280,3 +326,282 @@ func TestGetLastLocation_RedisError(t *testing.T) {
 	assert.Nil(t, location)
 	assert.Contains(t, err.Error(), "failed to get location data")
 }
+
+func TestCountAvailableDrivers(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{})
+
+	ctx := context.Background()
+
+	count, err := repo.CountAvailableDrivers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-1", location))
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-2", location))
+
+	count, err = repo.CountAvailableDrivers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	require.NoError(t, repo.RemoveAvailableDriver(ctx, "driver-1"))
+
+	count, err = repo.CountAvailableDrivers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
+
+func TestRemoveAvailableUsers_Idempotent(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{})
+
+	ctx := context.Background()
+	location := &models.Location{Latitude: -6.2088, Longitude: 106.8456}
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-1", location))
+	require.NoError(t, repo.AddAvailablePassenger(ctx, "passenger-1", location))
+
+	// Redelivered pickup events remove the same users again
+	for i := 0; i < 2; i++ {
+		require.NoError(t, repo.RemoveAvailableDriver(ctx, "driver-1"))
+		require.NoError(t, repo.RemoveAvailablePassenger(ctx, "passenger-1"))
+	}
+
+	count, err := repo.CountAvailableDrivers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), count)
+}
+
+func TestCountDriversByCell(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{})
+
+	ctx := context.Background()
+
+	// Three drivers in cell qqguxm, two in qqguzg and one outside the box in qquh0t
+	drivers := map[string]*models.Location{
+		"driver-1": {Latitude: -6.2088, Longitude: 106.8456},
+		"driver-2": {Latitude: -6.2090, Longitude: 106.8460},
+		"driver-3": {Latitude: -6.2085, Longitude: 106.8450},
+		"driver-4": {Latitude: -6.1751, Longitude: 106.8650},
+		"driver-5": {Latitude: -6.1755, Longitude: 106.8655},
+		"driver-6": {Latitude: -6.3000, Longitude: 106.9000},
+	}
+	for id, location := range drivers {
+		require.NoError(t, repo.AddAvailableDriver(ctx, id, location))
+	}
+
+	// Drivers no longer available are not counted
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-7", &models.Location{Latitude: -6.2089, Longitude: 106.8457}))
+	require.NoError(t, repo.RemoveAvailableDriver(ctx, "driver-7"))
+
+	// Nor are drivers whose presence has lapsed
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-8", &models.Location{Latitude: -6.2087, Longitude: 106.8455}))
+	mr.Del("driver:presence:driver-8")
+
+	bounds := models.BoundingBox{MinLatitude: -6.25, MinLongitude: 106.80, MaxLatitude: -6.15, MaxLongitude: 106.88}
+	counts, err := repo.CountDriversByCell(ctx, bounds, 6)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"qqguxm": 3, "qqguzg": 2}, counts)
+
+	// Coarser cells merge neighbouring counts
+	counts, err = repo.CountDriversByCell(ctx, bounds, 4)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"qqgu": 5}, counts)
+}
+
+func TestFindNearbyDrivers_KeyPrefixIsolatesPools(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	prodRepo := NewLocationRepository(&database.RedisClient{Client: client, Prefix: "prod:"}, &models.Config{})
+	stagingRepo := NewLocationRepository(&database.RedisClient{Client: client, Prefix: "staging:"}, &models.Config{})
+
+	ctx := context.Background()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	require.NoError(t, prodRepo.AddAvailableDriver(ctx, "driver-1", location))
+
+	drivers, err := stagingRepo.FindNearbyDrivers(ctx, location, 5)
+	require.NoError(t, err)
+	assert.Empty(t, drivers)
+
+	drivers, err = prodRepo.FindNearbyDrivers(ctx, location, 5)
+	require.NoError(t, err)
+	require.Len(t, drivers, 1)
+	assert.Equal(t, "driver-1", drivers[0].ID)
+
+	assert.True(t, mr.Exists("prod:"+constants.KeyDriverGeo))
+	assert.False(t, mr.Exists("staging:"+constants.KeyDriverGeo))
+}
+
+func TestEvictStaleDrivers(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{Location: models.LocationConfig{DriverPresenceTTLSecs: 60}})
+
+	ctx := context.Background()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	require.NoError(t, repo.AddAvailableDriver(ctx, "silent-driver", location))
+	require.NoError(t, repo.AddAvailableDriver(ctx, "active-driver", location))
+
+	// The active driver keeps sending beacons while the silent one stops
+	mr.FastForward(40 * time.Second)
+	require.NoError(t, repo.AddAvailableDriver(ctx, "active-driver", location))
+	mr.FastForward(30 * time.Second)
+
+	// Lapsed drivers are no longer proposed even before the sweep runs
+	drivers, err := repo.FindNearbyDrivers(ctx, location, 5)
+	require.NoError(t, err)
+	require.Len(t, drivers, 1)
+	assert.Equal(t, "active-driver", drivers[0].ID)
+
+	evicted, err := repo.EvictStaleDrivers(ctx)
+	require.NoError(t, err)
+	require.Len(t, evicted, 1)
+	assert.Equal(t, "silent-driver", evicted[0].DriverID)
+	assert.WithinDuration(t, time.Now(), evicted[0].LastSeenAt, 5*time.Second)
+
+	members, err := mr.ZMembers(constants.KeyDriverGeo)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"active-driver"}, members)
+	count, err := repo.CountAvailableDrivers(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.False(t, mr.Exists(fmt.Sprintf(constants.KeyDriverLocation, "silent-driver")))
+
+	// A driver who keeps refreshing is never evicted
+	for i := 0; i < 3; i++ {
+		mr.FastForward(45 * time.Second)
+		require.NoError(t, repo.AddAvailableDriver(ctx, "active-driver", location))
+	}
+	evicted, err = repo.EvictStaleDrivers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+}
+
+func TestEvictStaleDrivers_LeavesDriversWithRecentBeacon(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{Location: models.LocationConfig{DriverPresenceTTLSecs: 60}})
+
+	ctx := context.Background()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-1", location))
+	mr.FastForward(70 * time.Second)
+
+	// A beacon arriving after the presence lapsed but before the sweep keeps the driver pooled
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-1", location))
+
+	evicted, err := repo.EvictStaleDrivers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+	assert.True(t, mr.Exists(fmt.Sprintf(constants.KeyDriverLocation, "driver-1")))
+}
+
+func TestRefreshDriverPresence(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{Location: models.LocationConfig{DriverPresenceTTLSecs: 60}})
+
+	ctx := context.Background()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	require.NoError(t, repo.AddAvailableDriver(ctx, "pooled-driver", location))
+
+	// Ride location updates keep a pooled driver present without beacons
+	for i := 0; i < 3; i++ {
+		mr.FastForward(45 * time.Second)
+		require.NoError(t, repo.RefreshDriverPresence(ctx, "pooled-driver"))
+	}
+	evicted, err := repo.EvictStaleDrivers(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, evicted)
+
+	// A driver outside the pool isn't given a presence
+	require.NoError(t, repo.RefreshDriverPresence(ctx, "unpooled-driver"))
+	assert.False(t, mr.Exists(fmt.Sprintf(constants.KeyDriverPresence, "unpooled-driver")))
+}
+
+func TestDriverLocation_HiddenAfterRideCompletion(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{})
+
+	ctx := context.Background()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-1", location))
+
+	drivers, err := repo.FindNearbyDrivers(ctx, location, 5)
+	require.NoError(t, err)
+	require.Len(t, drivers, 1)
+	_, err = repo.GetDriverLocation(ctx, "driver-1")
+	require.NoError(t, err)
+
+	// The match service clears the driver's position when their ride completes
+	require.NoError(t, repo.RemoveAvailableDriver(ctx, "driver-1"))
+
+	drivers, err = repo.FindNearbyDrivers(ctx, location, 5)
+	require.NoError(t, err)
+	assert.Empty(t, drivers)
+	_, err = repo.GetDriverLocation(ctx, "driver-1")
+	assert.Error(t, err)
+
+	// Sharing resumes once the driver beacons as available again
+	require.NoError(t, repo.AddAvailableDriver(ctx, "driver-1", location))
+
+	drivers, err = repo.FindNearbyDrivers(ctx, location, 5)
+	require.NoError(t, err)
+	require.Len(t, drivers, 1)
+	assert.Equal(t, "driver-1", drivers[0].ID)
+	got, err := repo.GetDriverLocation(ctx, "driver-1")
+	require.NoError(t, err)
+	assert.InDelta(t, location.Latitude, got.Latitude, 0.0001)
+}
+
+func TestGetDriverLocation_RequiresAvailability(t *testing.T) {
+	mr, client := setupMiniredis(t)
+	defer mr.Close()
+
+	repo := NewLocationRepository(&database.RedisClient{
+		Client: client,
+	}, &models.Config{})
+
+	ctx := context.Background()
+
+	// A lingering position of a driver outside the available pool is not served
+	mr.HSet(fmt.Sprintf(constants.KeyDriverLocation, "driver-1"),
+		constants.FieldLatitude, "-6.175392",
+		constants.FieldLongitude, "106.827153",
+		constants.FieldTimestamp, "1700000000")
+
+	_, err := repo.GetDriverLocation(ctx, "driver-1")
+	assert.Error(t, err)
+
+	mr.SetError("connection refused")
+	_, err = repo.GetDriverLocation(ctx, "driver-1")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to check driver availability")
+}
@@ -2,15 +2,24 @@ package location
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
+// ErrStaleLocation is returned when a ride location is older than the one already stored
+var ErrStaleLocation = errors.New("location is older than the stored location")
+
+// ErrAggregateQueued is returned by StoreLocation when the update was stored and its aggregate
+// queued for the next batch; the update's settle callback reports the outcome of that publish
+var ErrAggregateQueued = errors.New("location aggregate queued for the next batch")
+
 //go:generate mockgen -destination=mocks/mock_usecase.go -package=mocks github.com/piresc/nebengjek/services/location LocationUC
 
 // LocationUseCase defines the interface for location business logic
 type LocationUC interface {
-	StoreLocation(ctx context.Context, location models.LocationUpdate) error
+	StoreLocation(ctx context.Context, location models.LocationUpdate, settle func(error)) error
 
 	// Geo-related methods
 	AddAvailableDriver(ctx context.Context, driverID string, location *models.Location) error
@@ -18,6 +27,13 @@ type LocationUC interface {
 	AddAvailablePassenger(ctx context.Context, passengerID string, location *models.Location) error
 	RemoveAvailablePassenger(ctx context.Context, passengerID string) error
 	FindNearbyDrivers(ctx context.Context, location *models.Location, radiusKm float64) ([]*models.NearbyUser, error)
+	CountAvailableDrivers(ctx context.Context) (int64, error)
+	EvictStaleDrivers(ctx context.Context) (int, error)
+	RunPoolSweeper(ctx context.Context, interval time.Duration)
+	FlushLocationAggregates(ctx context.Context) (int, error)
+	RunLocationPublisher(ctx context.Context, interval time.Duration)
+	GetDriverHeatmap(ctx context.Context, bounds models.BoundingBox, precision int) (*models.DriverHeatmap, error)
 	GetDriverLocation(ctx context.Context, driverID string) (models.Location, error)
 	GetPassengerLocation(ctx context.Context, passengerID string) (models.Location, error)
+	GetRideLocation(ctx context.Context, rideID string) (*models.Location, error)
 }
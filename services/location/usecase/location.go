@@ -2,41 +2,80 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
 
+	"github.com/piresc/nebengjek/internal/pkg/clock"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/piresc/nebengjek/internal/utils"
 	"github.com/piresc/nebengjek/services/location"
 )
 
+// maxHeatmapCells caps the number of cells returned in a driver heatmap
+const maxHeatmapCells = 500
+
 type locationUC struct {
+	cfg          *models.Config
 	locationRepo location.LocationRepo
 	locationGW   location.LocationGW
+	clock        clock.Clock
+
+	// batching is set while RunLocationPublisher coalesces aggregates into pending, keyed by ride ID
+	batching  atomic.Bool
+	pendingMu sync.Mutex
+	pending   map[string]*pendingAggregate
 }
 
 // NewLocationUC creates a new location use case instance
 func NewLocationUC(
+	cfg *models.Config,
 	locationRepo location.LocationRepo,
 	locationGW location.LocationGW,
 ) location.LocationUC {
 	return &locationUC{
+		cfg:          cfg,
 		locationRepo: locationRepo,
 		locationGW:   locationGW,
+		clock:        clock.Real{},
+		pending:      make(map[string]*pendingAggregate),
 	}
 }
 
-// StoreLocation stores a location update and publishes aggregated data
-func (uc *locationUC) StoreLocation(ctx context.Context, update models.LocationUpdate) error {
+// StoreLocation stores a location update and publishes aggregated data. While the publisher
+// batches aggregates and settle is set, the aggregate is queued and location.ErrAggregateQueued is
+// returned; settle is then called once the batch carrying it is published.
+func (uc *locationUC) StoreLocation(ctx context.Context, update models.LocationUpdate, settle func(error)) error {
+	// Timing is taken from when the update arrives; the device timestamp only orders updates, so
+	// one without a timestamp or from the future is dropped rather than blocking later updates
+	receivedAt := uc.clock.Now()
+	if update.Location.Timestamp.IsZero() || update.Location.Timestamp.After(receivedAt.Add(maxClockSkew)) {
+		logger.Warn("Dropped location update with an invalid timestamp",
+			logger.String("ride_id", update.RideID),
+			logger.String("driver_id", update.DriverID),
+			logger.Any("timestamp", update.Location.Timestamp))
+		return nil
+	}
 
-	// Processing location update for ride
+	// A driver finishing a ride may still be in the pool for their next match, and their ride
+	// updates stand in for beacons
+	if update.DriverID != "" {
+		if err := uc.locationRepo.RefreshDriverPresence(ctx, update.DriverID); err != nil {
+			logger.Warn("Failed to refresh driver presence",
+				logger.String("driver_id", update.DriverID),
+				logger.ErrorField(err))
+		}
+	}
 
 	// Get last location to calculate distance
 	lastLocation, err := uc.locationRepo.GetLastLocation(ctx, update.RideID)
 	if err != nil {
 		// If no previous location found, store this as first location
-		// No previous location found for ride, storing initial location
-		err = uc.locationRepo.StoreLocation(ctx, update.RideID, update.Location)
-		if err != nil {
+		err = uc.locationRepo.StoreLocation(ctx, update.RideID, update.Location, receivedAt)
+		if err != nil && !errors.Is(err, location.ErrStaleLocation) {
 			return fmt.Errorf("failed to store initial location: %w", err)
 		}
 		return nil
@@ -51,10 +90,16 @@ func (uc *locationUC) StoreLocation(ctx context.Context, update models.LocationU
 		Latitude:  update.Location.Latitude,
 		Longitude: update.Location.Longitude,
 	}
-	distance := utils.CalculateDistance(lastPoint, currentPoint)
-
-	// Store new location
-	err = uc.locationRepo.StoreLocation(ctx, update.RideID, update.Location)
+	distance := uc.clampSegment(update, receivedAt, lastLocation, utils.CalculateDistance(lastPoint, currentPoint))
+
+	// Store new location; an update that arrived out of order is dropped without adding distance
+	err = uc.locationRepo.StoreLocation(ctx, update.RideID, update.Location, receivedAt)
+	if errors.Is(err, location.ErrStaleLocation) {
+		logger.Warn("Dropped out-of-order location update",
+			logger.String("ride_id", update.RideID),
+			logger.String("driver_id", update.DriverID))
+		return nil
+	}
 	if err != nil {
 		return fmt.Errorf("failed to store location: %w", err)
 	}
@@ -66,6 +111,14 @@ func (uc *locationUC) StoreLocation(ctx context.Context, update models.LocationU
 		Longitude: update.Location.Longitude,
 	}
 
+	// While the publisher runs, the aggregate goes out with the ride's next batch
+	if uc.queueAggregate(aggregate, settle) {
+		if settle == nil {
+			return nil
+		}
+		return location.ErrAggregateQueued
+	}
+
 	err = uc.locationGW.PublishLocationAggregate(ctx, aggregate)
 	if err != nil {
 		return fmt.Errorf("failed to publish location aggregate: %w", err)
@@ -99,6 +152,42 @@ func (uc *locationUC) FindNearbyDrivers(ctx context.Context, location *models.Lo
 	return uc.locationRepo.FindNearbyDrivers(ctx, location, radiusKm)
 }
 
+// CountAvailableDrivers returns the number of drivers in the available pool
+func (uc *locationUC) CountAvailableDrivers(ctx context.Context) (int64, error) {
+	return uc.locationRepo.CountAvailableDrivers(ctx)
+}
+
+// GetDriverHeatmap returns the number of available drivers per geohash cell within bounds.
+// Only the busiest maxHeatmapCells cells are returned so a wide view stays cheap to render.
+func (uc *locationUC) GetDriverHeatmap(ctx context.Context, bounds models.BoundingBox, precision int) (*models.DriverHeatmap, error) {
+	counts, err := uc.locationRepo.CountDriversByCell(ctx, bounds, precision)
+	if err != nil {
+		return nil, err
+	}
+
+	cells := make([]models.HeatmapCell, 0, len(counts))
+	for geohash, count := range counts {
+		cells = append(cells, models.HeatmapCell{Geohash: geohash, Count: count})
+	}
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].Count != cells[j].Count {
+			return cells[i].Count > cells[j].Count
+		}
+		return cells[i].Geohash < cells[j].Geohash
+	})
+
+	heatmap := &models.DriverHeatmap{
+		Bounds:    bounds,
+		Precision: precision,
+		Cells:     cells,
+	}
+	if len(cells) > maxHeatmapCells {
+		heatmap.Cells = cells[:maxHeatmapCells]
+		heatmap.Truncated = true
+	}
+	return heatmap, nil
+}
+
 // GetDriverLocation retrieves a driver's last known location
 func (uc *locationUC) GetDriverLocation(ctx context.Context, driverID string) (models.Location, error) {
 	return uc.locationRepo.GetDriverLocation(ctx, driverID)
@@ -108,3 +197,8 @@ func (uc *locationUC) GetDriverLocation(ctx context.Context, driverID string) (m
 func (uc *locationUC) GetPassengerLocation(ctx context.Context, passengerID string) (models.Location, error) {
 	return uc.locationRepo.GetPassengerLocation(ctx, passengerID)
 }
+
+// GetRideLocation retrieves the driver's latest position reported for a ride
+func (uc *locationUC) GetRideLocation(ctx context.Context, rideID string) (*models.Location, error) {
+	return uc.locationRepo.GetLastLocation(ctx, rideID)
+}
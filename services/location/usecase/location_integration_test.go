@@ -22,7 +22,8 @@ func TestLocationUC_CompleteLocationTracking_Success(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	// Test data
 	rideID := uuid.New().String()
@@ -46,11 +47,11 @@ func TestLocationUC_CompleteLocationTracking_Success(t *testing.T) {
 		Return(nil, errors.New("no previous location"))
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, initialLocation).
+		StoreLocation(gomock.Any(), rideID, initialLocation, gomock.Any()).
 		Return(nil)
 
 	// Act - Step 1: Initial location
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert - Step 1
 	assert.NoError(t, err)
@@ -75,7 +76,7 @@ func TestLocationUC_CompleteLocationTracking_Success(t *testing.T) {
 
 	// Calculate distance moved (should be significant)
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, newLocation).
+		StoreLocation(gomock.Any(), rideID, newLocation, gomock.Any()).
 		Return(nil)
 
 	// Expect location aggregate to be published
@@ -84,7 +85,7 @@ func TestLocationUC_CompleteLocationTracking_Success(t *testing.T) {
 		Return(nil)
 
 	// Act - Step 2: Movement
-	err = uc.StoreLocation(context.Background(), newLocationUpdate)
+	err = uc.StoreLocation(context.Background(), newLocationUpdate, nil)
 
 	// Assert - Step 2
 	assert.NoError(t, err)
@@ -98,7 +99,7 @@ func TestLocationUC_AddRemoveAvailableDriver_Success(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
 
 	driverID := uuid.New().String()
 	location := &models.Location{
@@ -138,7 +139,7 @@ func TestLocationUC_FindNearbyDrivers_Success(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
 
 	// Search parameters
 	location := &models.Location{
@@ -199,7 +200,7 @@ func TestLocationUC_GetDriverLocation_Success(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
 
 	driverID := uuid.New().String()
 	expectedLocation := models.Location{
@@ -229,7 +230,7 @@ func TestLocationUC_GetPassengerLocation_Success(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
 
 	passengerID := uuid.New().String()
 	expectedLocation := models.Location{
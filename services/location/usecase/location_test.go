@@ -3,11 +3,14 @@ package usecase
 import (
 	"context"
 	"errors"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/clock"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/location"
 	"github.com/piresc/nebengjek/services/location/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -20,7 +23,8 @@ func TestStoreLocation_Success(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	rideID := "ride-123"
 	timestamp := time.Now()
@@ -48,7 +52,7 @@ func TestStoreLocation_Success(t *testing.T) {
 		Return(lastLocation, nil)
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(nil)
 
 	// Mock gateway call for location aggregate
@@ -63,7 +67,7 @@ func TestStoreLocation_Success(t *testing.T) {
 		})
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -77,7 +81,8 @@ func TestStoreLocation_FirstLocation(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	rideID := "ride-123"
 	locationUpdate := models.LocationUpdate{
@@ -97,11 +102,11 @@ func TestStoreLocation_FirstLocation(t *testing.T) {
 		Return(nil, errors.New("no location data found"))
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(nil)
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -116,7 +121,8 @@ func TestStoreLocation_GetLastLocationError(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	rideID := "ride-123"
 	locationUpdate := models.LocationUpdate{
@@ -138,11 +144,11 @@ func TestStoreLocation_GetLastLocationError(t *testing.T) {
 
 	// Expect StoreLocation to be called as a fallback
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(nil)
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -157,7 +163,8 @@ func TestStoreLocation_StoreLocationError(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	rideID := "ride-123"
 	locationUpdate := models.LocationUpdate{
@@ -178,17 +185,123 @@ func TestStoreLocation_StoreLocationError(t *testing.T) {
 		Return(nil, errors.New("no location data found"))
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(expectedError)
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to store initial location")
 }
 
+func TestStoreLocation_RefreshesDriverPresence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+
+	update := models.LocationUpdate{
+		RideID:   "ride-123",
+		DriverID: "driver-456",
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: time.Now()},
+	}
+
+	// A driver accepting their next match sends ride updates instead of beacons
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), "driver-456").Return(errors.New("redis down"))
+	mockRepo.EXPECT().GetLastLocation(gomock.Any(), "ride-123").Return(nil, errors.New("no location data found"))
+	mockRepo.EXPECT().StoreLocation(gomock.Any(), "ride-123", update.Location, gomock.Any()).Return(nil)
+
+	// A failed refresh doesn't lose the ride location
+	assert.NoError(t, uc.StoreLocation(context.Background(), update, nil))
+}
+
+func TestStoreLocation_StaleUpdateDropped(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	now := time.Now()
+	update := models.LocationUpdate{
+		RideID:   "ride-123",
+		DriverID: "driver-456",
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: now.Add(-time.Minute)},
+	}
+
+	mockRepo.EXPECT().
+		GetLastLocation(gomock.Any(), "ride-123").
+		Return(&models.Location{Latitude: -6.17, Longitude: 106.82, Timestamp: now}, nil)
+	mockRepo.EXPECT().
+		StoreLocation(gomock.Any(), "ride-123", update.Location, gomock.Any()).
+		Return(fmt.Errorf("%w: ride ride-123", location.ErrStaleLocation))
+	// No aggregate is published for an out-of-order update
+
+	err := uc.StoreLocation(context.Background(), update, nil)
+	assert.NoError(t, err)
+}
+
+func TestStoreLocation_InvalidTimestampDropped(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		timestamp time.Time
+	}{
+		{name: "zero timestamp", timestamp: time.Time{}},
+		{name: "future timestamp", timestamp: now.Add(time.Hour)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// The repository and gateway must not be touched
+			mockRepo := mocks.NewMockLocationRepo(ctrl)
+			mockGW := mocks.NewMockLocationGW(ctrl)
+			uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+			uc.(*locationUC).clock = clock.NewMock(now)
+
+			err := uc.StoreLocation(context.Background(), models.LocationUpdate{
+				RideID:   "ride-123",
+				DriverID: "driver-456",
+				Location: models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: tt.timestamp},
+			}, nil)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestStoreLocation_StoresReceiveTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	uc.(*locationUC).clock = clock.NewMock(now)
+
+	update := models.LocationUpdate{
+		RideID:   "ride-123",
+		DriverID: "driver-456",
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: now.Add(-2 * time.Second)},
+	}
+	mockRepo.EXPECT().GetLastLocation(gomock.Any(), "ride-123").Return(nil, errors.New("no location data found"))
+	mockRepo.EXPECT().StoreLocation(gomock.Any(), "ride-123", update.Location, now).Return(nil)
+
+	assert.NoError(t, uc.StoreLocation(context.Background(), update, nil))
+}
+
 func TestStoreLocation_PublishError(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -197,7 +310,8 @@ func TestStoreLocation_PublishError(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	rideID := "ride-123"
 	timestamp := time.Now()
@@ -224,7 +338,7 @@ func TestStoreLocation_PublishError(t *testing.T) {
 		Return(lastLocation, nil)
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(nil)
 
 	// Mock gateway call - error
@@ -234,7 +348,7 @@ func TestStoreLocation_PublishError(t *testing.T) {
 		Return(expectedError)
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -249,7 +363,8 @@ func TestStoreLocation_SecondaryStoreLocationError(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	rideID := "ride-123"
 	timestamp := time.Now()
@@ -278,11 +393,11 @@ func TestStoreLocation_SecondaryStoreLocationError(t *testing.T) {
 		Return(lastLocation, nil)
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(expectedError)
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.Error(t, err)
@@ -297,7 +412,8 @@ func TestStoreLocation_ZeroDistance(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	rideID := "ride-123"
 	timestamp := time.Now()
@@ -325,7 +441,7 @@ func TestStoreLocation_ZeroDistance(t *testing.T) {
 		Return(lastLocation, nil)
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(nil)
 
 	// Mock gateway call for location aggregate
@@ -340,7 +456,7 @@ func TestStoreLocation_ZeroDistance(t *testing.T) {
 		})
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.NoError(t, err)
@@ -354,7 +470,8 @@ func TestStoreLocation_InvalidRideID(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
 	locationUpdate := models.LocationUpdate{
 		RideID:   "", // Empty ride ID
@@ -375,11 +492,11 @@ func TestStoreLocation_InvalidRideID(t *testing.T) {
 
 	// Current implementation will try to store the location anyway
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), "", locationUpdate.Location).
+		StoreLocation(gomock.Any(), "", locationUpdate.Location, gomock.Any()).
 		Return(nil)
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.NoError(t, err) // Current implementation doesn't validate ride ID
@@ -393,10 +510,13 @@ func TestStoreLocation_MaxDistanceCase(t *testing.T) {
 	mockRepo := mocks.NewMockLocationRepo(ctrl)
 	mockGW := mocks.NewMockLocationGW(ctrl)
 
-	uc := NewLocationUC(mockRepo, mockGW)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
 
-	rideID := "ride-123"
 	timestamp := time.Now()
+	uc.(*locationUC).clock = clock.NewMock(timestamp)
+
+	rideID := "ride-123"
 	locationUpdate := models.LocationUpdate{
 		RideID:   rideID,
 		DriverID: "driver-456",
@@ -421,7 +541,7 @@ func TestStoreLocation_MaxDistanceCase(t *testing.T) {
 		Return(lastLocation, nil)
 
 	mockRepo.EXPECT().
-		StoreLocation(gomock.Any(), rideID, locationUpdate.Location).
+		StoreLocation(gomock.Any(), rideID, locationUpdate.Location, gomock.Any()).
 		Return(nil)
 
 	// Mock gateway call for location aggregate - use explicit matching instead of comparing
@@ -431,15 +551,81 @@ func TestStoreLocation_MaxDistanceCase(t *testing.T) {
 			assert.Equal(t, rideID, aggregate.RideID)
 			assert.Equal(t, locationUpdate.Location.Latitude, aggregate.Latitude)
 			assert.Equal(t, locationUpdate.Location.Longitude, aggregate.Longitude)
-			// Jakarta to New York should be around 16,000-18,000 km
-			assert.NotZero(t, aggregate.Distance)
-			assert.Greater(t, aggregate.Distance, 10000.0) // Should be very large distance (>10,000 km)
+			// Jakarta to New York in a minute is implausible, so the segment is clamped to what
+			// the default maximum speed covers in that time
+			assert.InDelta(t, defaultMaxSegmentSpeedKmh*61/3600, aggregate.Distance, 1e-9)
 			return nil
 		})
 
 	// Act
-	err := uc.StoreLocation(context.Background(), locationUpdate)
+	err := uc.StoreLocation(context.Background(), locationUpdate, nil)
 
 	// Assert
 	assert.NoError(t, err)
 }
+
+func TestGetDriverHeatmap_OrdersCellsByCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+
+	bounds := models.BoundingBox{MinLatitude: -6.25, MinLongitude: 106.80, MaxLatitude: -6.15, MaxLongitude: 106.88}
+	mockRepo.EXPECT().
+		CountDriversByCell(gomock.Any(), bounds, 6).
+		Return(map[string]int{"qqguzg": 2, "qqguxm": 3, "qqguxk": 2}, nil)
+
+	heatmap, err := uc.GetDriverHeatmap(context.Background(), bounds, 6)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 6, heatmap.Precision)
+	assert.False(t, heatmap.Truncated)
+	assert.Equal(t, []models.HeatmapCell{
+		{Geohash: "qqguxm", Count: 3},
+		{Geohash: "qqguxk", Count: 2},
+		{Geohash: "qqguzg", Count: 2},
+	}, heatmap.Cells)
+}
+
+func TestGetDriverHeatmap_CapsCellCount(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+
+	counts := make(map[string]int)
+	for i := 0; i < maxHeatmapCells+10; i++ {
+		counts[fmt.Sprintf("cell-%04d", i)] = 1
+	}
+	counts["busiest"] = 50
+
+	mockRepo.EXPECT().CountDriversByCell(gomock.Any(), gomock.Any(), 5).Return(counts, nil)
+
+	heatmap, err := uc.GetDriverHeatmap(context.Background(), models.BoundingBox{}, 5)
+
+	assert.NoError(t, err)
+	assert.True(t, heatmap.Truncated)
+	assert.Len(t, heatmap.Cells, maxHeatmapCells)
+	// The busiest cells are the ones kept
+	assert.Equal(t, models.HeatmapCell{Geohash: "busiest", Count: 50}, heatmap.Cells[0])
+}
+
+func TestGetDriverHeatmap_RepositoryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+
+	mockRepo.EXPECT().CountDriversByCell(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, errors.New("redis down"))
+
+	heatmap, err := uc.GetDriverHeatmap(context.Background(), models.BoundingBox{}, 6)
+
+	assert.Error(t, err)
+	assert.Nil(t, heatmap)
+}
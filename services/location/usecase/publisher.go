@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// pendingAggregate is a ride's coalesced aggregate waiting for the next flush, with the settle
+// callbacks of the updates it carries
+type pendingAggregate struct {
+	aggregate models.LocationAggregate
+	settles   []func(error)
+}
+
+// mergeAggregates coalesces a newer aggregate for the same ride into an older one. Distances are
+// summed so billing still sees the whole route, while the position is the latest one.
+func mergeAggregates(older, newer models.LocationAggregate) models.LocationAggregate {
+	newer.Distance += older.Distance
+	return newer
+}
+
+// settleAll reports the outcome of publishing an aggregate to every update it carries
+func settleAll(settles []func(error), err error) {
+	for _, settle := range settles {
+		settle(err)
+	}
+}
+
+// queueAggregate adds aggregate to the ride's pending batch, reporting false when batching is off
+// and the caller should publish it straight away. A non-nil settle is called once the batch is published.
+func (uc *locationUC) queueAggregate(aggregate models.LocationAggregate, settle func(error)) bool {
+	uc.pendingMu.Lock()
+	defer uc.pendingMu.Unlock()
+
+	if !uc.batching.Load() {
+		return false
+	}
+	entry, ok := uc.pending[aggregate.RideID]
+	if !ok {
+		entry = &pendingAggregate{aggregate: aggregate}
+		uc.pending[aggregate.RideID] = entry
+	} else {
+		entry.aggregate = mergeAggregates(entry.aggregate, aggregate)
+	}
+	if settle != nil {
+		entry.settles = append(entry.settles, settle)
+	}
+	return true
+}
+
+// FlushLocationAggregates publishes one coalesced aggregate for every ride with pending updates,
+// returning how many were published. The updates carried by a published aggregate are settled,
+// while aggregates that fail to publish are kept, with their updates, for the next flush.
+func (uc *locationUC) FlushLocationAggregates(ctx context.Context) (int, error) {
+	uc.pendingMu.Lock()
+	batch := uc.pending
+	uc.pending = make(map[string]*pendingAggregate, len(batch))
+	uc.pendingMu.Unlock()
+
+	published := 0
+	var errs []error
+	for rideID, entry := range batch {
+		if err := uc.locationGW.PublishLocationAggregate(ctx, entry.aggregate); err != nil {
+			errs = append(errs, err)
+
+			// Updates queued since the swap are newer than the failed aggregate
+			uc.pendingMu.Lock()
+			if newer, ok := uc.pending[rideID]; ok {
+				entry.aggregate = mergeAggregates(entry.aggregate, newer.aggregate)
+				entry.settles = append(entry.settles, newer.settles...)
+			}
+			uc.pending[rideID] = entry
+			uc.pendingMu.Unlock()
+			continue
+		}
+		settleAll(entry.settles, nil)
+		published++
+	}
+	return published, errors.Join(errs...)
+}
+
+// failPendingAggregates settles every update still pending with err, so their messages are
+// redelivered rather than lost with the batch
+func (uc *locationUC) failPendingAggregates(err error) {
+	uc.pendingMu.Lock()
+	batch := uc.pending
+	uc.pending = make(map[string]*pendingAggregate)
+	uc.pendingMu.Unlock()
+
+	for _, entry := range batch {
+		settleAll(entry.settles, err)
+	}
+}
+
+// RunLocationPublisher coalesces location aggregates so each ride publishes at most once per
+// interval, until ctx is cancelled. Pending aggregates are flushed before it returns. With a
+// non-positive interval it returns straight away and every update is published as it arrives.
+func (uc *locationUC) RunLocationPublisher(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	uc.batching.Store(true)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			uc.pendingMu.Lock()
+			uc.batching.Store(false)
+			uc.pendingMu.Unlock()
+
+			// ctx is already cancelled, so the last batch is published without it
+			if _, err := uc.FlushLocationAggregates(context.Background()); err != nil {
+				logger.Error("Failed to publish pending location aggregates on shutdown", logger.ErrorField(err))
+				uc.failPendingAggregates(err)
+			}
+			logger.Info("Location publisher stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.FlushLocationAggregates(ctx); err != nil {
+				logger.Error("Failed to publish location aggregates", logger.ErrorField(err))
+			}
+		}
+	}
+}
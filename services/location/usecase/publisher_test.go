@@ -0,0 +1,173 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/clock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/location"
+	"github.com/piresc/nebengjek/services/location/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreLocation_RapidUpdatesCoalesced(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW).(*locationUC)
+	mockRepo.EXPECT().RefreshDriverPresence(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	uc.batching.Store(true)
+
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clk := clock.NewMock(start)
+	uc.clock = clk
+
+	rideID := "ride-123"
+	route := []models.Location{
+		{Latitude: -6.175000, Longitude: 106.827000, Timestamp: start},
+		{Latitude: -6.176000, Longitude: 106.828000, Timestamp: start.Add(30 * time.Second)},
+		{Latitude: -6.177000, Longitude: 106.829000, Timestamp: start.Add(60 * time.Second)},
+		{Latitude: -6.178000, Longitude: 106.830000, Timestamp: start.Add(90 * time.Second)},
+	}
+	for i := 1; i < len(route); i++ {
+		mockRepo.EXPECT().GetLastLocation(gomock.Any(), rideID).Return(&route[i-1], nil)
+		mockRepo.EXPECT().StoreLocation(gomock.Any(), rideID, route[i], gomock.Any()).Return(nil)
+	}
+
+	// Nothing is published, or settled, while the updates arrive
+	var settled []error
+	settle := func(err error) { settled = append(settled, err) }
+	for _, loc := range route[1:] {
+		clk.Set(loc.Timestamp)
+		err := uc.StoreLocation(context.Background(), models.LocationUpdate{RideID: rideID, DriverID: "driver-456", Location: loc}, settle)
+		assert.ErrorIs(t, err, location.ErrAggregateQueued)
+	}
+	assert.Empty(t, settled)
+
+	// One publish with the latest position and the distance of every update
+	mockGW.EXPECT().
+		PublishLocationAggregate(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, aggregate models.LocationAggregate) error {
+			assert.Equal(t, rideID, aggregate.RideID)
+			assert.Equal(t, route[3].Latitude, aggregate.Latitude)
+			assert.Equal(t, route[3].Longitude, aggregate.Longitude)
+			assert.InDelta(t, 0.47, aggregate.Distance, 0.01)
+			return nil
+		})
+
+	published, err := uc.FlushLocationAggregates(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+
+	// Every update carried by the aggregate is settled once it is published
+	assert.Equal(t, []error{nil, nil, nil}, settled)
+
+	// The batch is cleared once published
+	published, err = uc.FlushLocationAggregates(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, published)
+}
+
+func TestFlushLocationAggregates_KeepsFailedPublish(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mocks.NewMockLocationRepo(ctrl), mockGW).(*locationUC)
+	uc.batching.Store(true)
+
+	settled := 0
+	settle := func(err error) {
+		assert.NoError(t, err)
+		settled++
+	}
+	assert.True(t, uc.queueAggregate(models.LocationAggregate{RideID: "ride-1", Distance: 0.2, Latitude: -6.1, Longitude: 106.8}, settle))
+
+	// The update stays unacknowledged while its aggregate is unpublished
+	mockGW.EXPECT().PublishLocationAggregate(gomock.Any(), gomock.Any()).Return(errors.New("nats down"))
+	published, err := uc.FlushLocationAggregates(context.Background())
+	assert.Error(t, err)
+	assert.Zero(t, published)
+	assert.Zero(t, settled)
+
+	// A newer update is merged with the failed aggregate so no distance is lost
+	assert.True(t, uc.queueAggregate(models.LocationAggregate{RideID: "ride-1", Distance: 0.3, Latitude: -6.2, Longitude: 106.9}, settle))
+	mockGW.EXPECT().
+		PublishLocationAggregate(gomock.Any(), models.LocationAggregate{RideID: "ride-1", Distance: 0.5, Latitude: -6.2, Longitude: 106.9}).
+		Return(nil)
+
+	published, err = uc.FlushLocationAggregates(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, published)
+	assert.Equal(t, 2, settled)
+}
+
+func TestRunLocationPublisher_FlushesOnCancel(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mocks.NewMockLocationRepo(ctrl), mockGW).(*locationUC)
+	uc.pending["ride-1"] = &pendingAggregate{aggregate: models.LocationAggregate{RideID: "ride-1", Distance: 0.2}}
+
+	mockGW.EXPECT().PublishLocationAggregate(gomock.Any(), uc.pending["ride-1"].aggregate).Return(nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		uc.RunLocationPublisher(ctx, time.Hour)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("publisher did not stop after cancellation")
+	}
+
+	// Updates after shutdown are published directly
+	assert.False(t, uc.queueAggregate(models.LocationAggregate{RideID: "ride-1"}, nil))
+}
+
+func TestRunLocationPublisher_DisabledWithoutInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := NewLocationUC(&models.Config{}, mocks.NewMockLocationRepo(ctrl), mocks.NewMockLocationGW(ctrl)).(*locationUC)
+
+	uc.RunLocationPublisher(context.Background(), 0)
+	assert.False(t, uc.queueAggregate(models.LocationAggregate{RideID: "ride-1"}, nil))
+}
+
+func TestRunLocationPublisher_FailedShutdownFlushRedeliversUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mocks.NewMockLocationRepo(ctrl), mockGW).(*locationUC)
+
+	var settled error
+	uc.pending["ride-1"] = &pendingAggregate{
+		aggregate: models.LocationAggregate{RideID: "ride-1", Distance: 0.2},
+		settles:   []func(error){func(err error) { settled = err }},
+	}
+
+	mockGW.EXPECT().PublishLocationAggregate(gomock.Any(), gomock.Any()).Return(errors.New("nats down"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	uc.RunLocationPublisher(ctx, time.Hour)
+
+	// The update is settled with the failure so its message is redelivered after the restart
+	assert.Error(t, settled)
+	assert.Empty(t, uc.pending)
+}
@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// defaultMaxSegmentSpeedKmh is used when no maximum segment speed is configured
+const defaultMaxSegmentSpeedKmh = 150.0
+
+// maxClockSkew is how far ahead of the server a device timestamp may be before the update is dropped
+const maxClockSkew = 5 * time.Second
+
+// maxSegmentSpeedKmh returns the configured plausible speed cap, falling back to the default
+func (uc *locationUC) maxSegmentSpeedKmh() float64 {
+	if uc.cfg != nil && uc.cfg.Location.MaxSegmentSpeedKmh > 0 {
+		return uc.cfg.Location.MaxSegmentSpeedKmh
+	}
+	return defaultMaxSegmentSpeedKmh
+}
+
+// clampSegment caps the distance travelled between two updates of a ride at what the vehicle could
+// plausibly cover at the maximum speed in the time between them, so a GPS spike can't inflate the
+// fare. The time between them is measured with the server's receive times, since a device clock
+// could otherwise stretch it. Segments whose timing is unknown are passed through unchanged.
+func (uc *locationUC) clampSegment(update models.LocationUpdate, receivedAt time.Time, last *models.Location, distanceKm float64) float64 {
+	if receivedAt.IsZero() || last.Timestamp.IsZero() {
+		return distanceKm
+	}
+
+	// Stored locations keep whole seconds, so allow one more second of travel
+	elapsed := receivedAt.Sub(last.Timestamp) + time.Second
+	if elapsed < time.Second {
+		elapsed = time.Second
+	}
+
+	maxKm := uc.maxSegmentSpeedKmh() * elapsed.Hours()
+	if distanceKm <= maxKm {
+		return distanceKm
+	}
+
+	logger.Warn("Clamped implausible location segment",
+		logger.String("ride_id", update.RideID),
+		logger.String("driver_id", update.DriverID),
+		logger.Float64("distance_km", distanceKm),
+		logger.Float64("clamped_km", maxKm),
+		logger.Float64("elapsed_secs", elapsed.Seconds()))
+	return maxKm
+}
@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"testing"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClampSegment(t *testing.T) {
+	uc := &locationUC{cfg: &models.Config{Location: models.LocationConfig{MaxSegmentSpeedKmh: 120}}}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	update := models.LocationUpdate{RideID: "ride-1", Location: models.Location{Timestamp: now}}
+	last := &models.Location{Timestamp: now.Add(-29 * time.Second)}
+
+	// 120 km/h over 29s plus a second of timestamp rounding allows 1km
+	assert.Equal(t, 0.4, uc.clampSegment(update, now, last, 0.4), "plausible segments accumulate fully")
+	assert.InDelta(t, 1.0, uc.clampSegment(update, now, last, 50), 1e-9, "spikes are clamped")
+
+	// Updates without timing can't be bounded
+	assert.Equal(t, 50.0, uc.clampSegment(update, now, &models.Location{}, 50))
+
+	// Receive times that went backwards still allow a second of travel
+	assert.InDelta(t, 120.0/3600, uc.clampSegment(update, now.Add(-time.Minute), last, 50), 1e-9)
+}
+
+func TestClampSegment_IgnoresDeviceTimestamp(t *testing.T) {
+	uc := &locationUC{cfg: &models.Config{Location: models.LocationConfig{MaxSegmentSpeedKmh: 120}}}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	last := &models.Location{Timestamp: now.Add(-29 * time.Second)}
+
+	// A device claiming an hour passed can't stretch the allowance beyond the 30s actually elapsed
+	update := models.LocationUpdate{RideID: "ride-1", Location: models.Location{Timestamp: now.Add(time.Hour)}}
+	assert.InDelta(t, 1.0, uc.clampSegment(update, now, last, 50), 1e-9)
+}
+
+func TestClampSegment_DefaultSpeed(t *testing.T) {
+	uc := &locationUC{cfg: &models.Config{}}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	update := models.LocationUpdate{Location: models.Location{Timestamp: now}}
+	last := &models.Location{Timestamp: now.Add(-59 * time.Second)}
+
+	assert.InDelta(t, defaultMaxSegmentSpeedKmh/60, uc.clampSegment(update, now, last, 50), 1e-9)
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+)
+
+// defaultPoolSweepInterval is used when no pool sweep interval is configured
+const defaultPoolSweepInterval = 30 * time.Second
+
+// EvictStaleDrivers removes drivers who stopped sending beacons from the matching pool,
+// returning how many were evicted. Each eviction is published so the driver's online session is
+// closed as of when they were last seen.
+func (uc *locationUC) EvictStaleDrivers(ctx context.Context) (int, error) {
+	evicted, err := uc.locationRepo.EvictStaleDrivers(ctx)
+	for _, eviction := range evicted {
+		logger.Info("Evicted silent driver from pool",
+			logger.String("driver_id", eviction.DriverID),
+			logger.Any("last_seen_at", eviction.LastSeenAt))
+
+		if err := uc.locationGW.PublishDriverEvicted(ctx, eviction); err != nil {
+			logger.Error("Failed to publish driver eviction",
+				logger.String("driver_id", eviction.DriverID),
+				logger.ErrorField(err))
+		}
+	}
+	return len(evicted), err
+}
+
+// RunPoolSweeper periodically evicts silent drivers until ctx is cancelled
+func (uc *locationUC) RunPoolSweeper(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPoolSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Driver pool sweeper stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.EvictStaleDrivers(ctx); err != nil {
+				logger.Error("Driver pool sweep failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/location/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvictStaleDrivers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+
+	lastSeen := time.Now().Add(-3 * time.Minute)
+	evictions := []models.DriverEviction{
+		{DriverID: "driver-1", LastSeenAt: lastSeen},
+		{DriverID: "driver-2", LastSeenAt: lastSeen},
+	}
+	mockRepo.EXPECT().EvictStaleDrivers(gomock.Any()).Return(evictions, nil)
+
+	// Every eviction is announced so the driver's online session gets closed
+	mockGW.EXPECT().PublishDriverEvicted(gomock.Any(), evictions[0]).Return(nil)
+	mockGW.EXPECT().PublishDriverEvicted(gomock.Any(), evictions[1]).Return(errors.New("nats down"))
+
+	evicted, err := uc.EvictStaleDrivers(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, evicted)
+}
+
+func TestEvictStaleDrivers_PartialFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	mockGW := mocks.NewMockLocationGW(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mockGW)
+
+	// Drivers evicted before the failure are still reported
+	eviction := models.DriverEviction{DriverID: "driver-1", LastSeenAt: time.Now()}
+	mockRepo.EXPECT().EvictStaleDrivers(gomock.Any()).Return([]models.DriverEviction{eviction}, errors.New("redis down"))
+	mockGW.EXPECT().PublishDriverEvicted(gomock.Any(), eviction).Return(nil)
+
+	evicted, err := uc.EvictStaleDrivers(context.Background())
+	assert.Error(t, err)
+	assert.Equal(t, 1, evicted)
+}
+
+func TestRunPoolSweeper_SweepsUntilCancelled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockLocationRepo(ctrl)
+	uc := NewLocationUC(&models.Config{}, mockRepo, mocks.NewMockLocationGW(ctrl))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	swept := make(chan struct{})
+	mockRepo.EXPECT().EvictStaleDrivers(gomock.Any()).DoAndReturn(func(context.Context) ([]models.DriverEviction, error) {
+		cancel()
+		close(swept)
+		return nil, nil
+	})
+
+	done := make(chan struct{})
+	go func() {
+		uc.RunPoolSweeper(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+
+	<-swept
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("sweeper did not stop after cancellation")
+	}
+}
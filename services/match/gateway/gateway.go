@@ -23,6 +23,26 @@ func (g *MatchGW) PublishMatchAccepted(ctx context.Context, matchProp models.Mat
 	return g.natsGateway.PublishMatchAccepted(ctx, matchProp)
 }
 
+// PublishMatchMaintenance forwards to the NATS gateway implementation
+func (g *MatchGW) PublishMatchMaintenance(ctx context.Context, event models.MatchMaintenanceEvent) error {
+	return g.natsGateway.PublishMatchMaintenance(ctx, event)
+}
+
+// PublishNoDriversFound forwards to the NATS gateway implementation
+func (g *MatchGW) PublishNoDriversFound(ctx context.Context, event models.NoDriversFoundEvent) error {
+	return g.natsGateway.PublishNoDriversFound(ctx, event)
+}
+
+// PublishDriverAcceptances forwards to the NATS gateway implementation
+func (g *MatchGW) PublishDriverAcceptances(ctx context.Context, event models.DriverAcceptancesEvent) error {
+	return g.natsGateway.PublishDriverAcceptances(ctx, event)
+}
+
+// PublishPendingProposals forwards to the NATS gateway implementation
+func (g *MatchGW) PublishPendingProposals(ctx context.Context, event models.PendingProposalsEvent) error {
+	return g.natsGateway.PublishPendingProposals(ctx, event)
+}
+
 // HTTP Gateway delegation methods
 
 // AddAvailableDriver forwards to the HTTP gateway implementation
@@ -127,6 +127,27 @@ func TestLocationClient_RemoveAvailableDriver_ServerError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to remove available driver")
 }
 
+func TestLocationClient_Remove_NotFoundIsError(t *testing.T) {
+	// Removing a user already gone from the pool succeeds, so a 404 means the request went astray
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	config := &models.APIKeyConfig{
+		MatchService: "test-api-key",
+	}
+	gateway := NewHTTPGateway(server.URL, config, nil, nil)
+
+	err := gateway.locationClient.RemoveAvailableDriver(context.Background(), "driver-123")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+
+	err = gateway.locationClient.RemoveAvailablePassenger(context.Background(), "passenger-123")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "404")
+}
+
 func TestLocationClient_FindNearbyDrivers_Success(t *testing.T) {
 	// Create a test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
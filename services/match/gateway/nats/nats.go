@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/constants"
+	pkgcontext "github.com/piresc/nebengjek/internal/pkg/context"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
@@ -24,8 +25,17 @@ func NewNATSGateway(client *natspkg.Client) *NATSGateway {
 	}
 }
 
+// stampCorrelationID carries the correlation ID of the request being handled on a proposal
+// that does not already have one
+func stampCorrelationID(ctx context.Context, matchProp *models.MatchProposal) {
+	if matchProp.CorrelationID == "" {
+		matchProp.CorrelationID = pkgcontext.GetCorrelationID(ctx)
+	}
+}
+
 // PublishMatchFound publishes a match found event to JetStream with delivery guarantees
 func (g *NATSGateway) PublishMatchFound(ctx context.Context, matchProp models.MatchProposal) error {
+	stampCorrelationID(ctx, &matchProp)
 	data, err := json.Marshal(matchProp)
 	if err != nil {
 		return fmt.Errorf("failed to marshal match proposal: %w", err)
@@ -36,6 +46,7 @@ func (g *NATSGateway) PublishMatchFound(ctx context.Context, matchProp models.Ma
 		Subject: constants.SubjectMatchFound,
 		Data:    data,
 		MsgID:   fmt.Sprintf("match-found-%s-%d", matchProp.ID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 10 * time.Second,
 	}
 
@@ -58,6 +69,7 @@ func (g *NATSGateway) PublishMatchFound(ctx context.Context, matchProp models.Ma
 
 // PublishMatchRejected publishes a match rejected event to JetStream with delivery guarantees
 func (g *NATSGateway) PublishMatchRejected(ctx context.Context, matchProp models.MatchProposal) error {
+	stampCorrelationID(ctx, &matchProp)
 	data, err := json.Marshal(matchProp)
 	if err != nil {
 		return fmt.Errorf("failed to marshal match proposal: %w", err)
@@ -68,6 +80,7 @@ func (g *NATSGateway) PublishMatchRejected(ctx context.Context, matchProp models
 		Subject: constants.SubjectMatchRejected,
 		Data:    data,
 		MsgID:   fmt.Sprintf("match-rejected-%s-%d", matchProp.ID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 10 * time.Second,
 	}
 
@@ -95,6 +108,7 @@ func (g *NATSGateway) PublishMatchAccepted(ctx context.Context, matchProp models
 		logger.String("driver_id", matchProp.DriverID),
 		logger.String("passenger_id", matchProp.PassengerID))
 
+	stampCorrelationID(ctx, &matchProp)
 	data, err := json.Marshal(matchProp)
 	if err != nil {
 		logger.ErrorCtx(ctx, "Failed to marshal match proposal for JetStream",
@@ -108,6 +122,7 @@ func (g *NATSGateway) PublishMatchAccepted(ctx context.Context, matchProp models
 		Subject: constants.SubjectMatchAccepted,
 		Data:    data,
 		MsgID:   fmt.Sprintf("match-accepted-%s-%d", matchProp.ID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 15 * time.Second, // Longer timeout for critical match acceptance
 	}
 
@@ -136,3 +151,120 @@ func (g *NATSGateway) PublishMatchAccepted(ctx context.Context, matchProp models
 
 	return nil
 }
+
+// PublishNoDriversFound publishes a no drivers found event to JetStream
+func (g *NATSGateway) PublishNoDriversFound(ctx context.Context, event models.NoDriversFoundEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal no drivers found event: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectMatchNoDrivers,
+		Data:    data,
+		MsgID:   fmt.Sprintf("match-no-drivers-%s-%d", event.PassengerID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 10 * time.Second,
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish no drivers found event to JetStream",
+			logger.String("passenger_id", event.PassengerID),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish no drivers found event: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Successfully published no drivers found event to JetStream",
+		logger.String("passenger_id", event.PassengerID),
+		logger.Float64("search_radius_km", event.SearchRadiusKm),
+		logger.Int("drivers_attempted", event.DriversAttempted))
+
+	return nil
+}
+
+// PublishMatchMaintenance publishes a maintenance refusal for a user to JetStream
+func (g *NATSGateway) PublishMatchMaintenance(ctx context.Context, event models.MatchMaintenanceEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal match maintenance event: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectMatchMaintenance,
+		Data:    data,
+		MsgID:   fmt.Sprintf("match-maintenance-%s-%d", event.UserID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 10 * time.Second,
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish match maintenance event to JetStream",
+			logger.String("user_id", event.UserID),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish match maintenance event: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Successfully published match maintenance event to JetStream",
+		logger.String("user_id", event.UserID),
+		logger.String("role", event.Role))
+
+	return nil
+}
+
+// PublishDriverAcceptances publishes the drivers who accepted during a passenger's acceptance window
+func (g *NATSGateway) PublishDriverAcceptances(ctx context.Context, event models.DriverAcceptancesEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal driver acceptances event: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectMatchAcceptances,
+		Data:    data,
+		MsgID:   fmt.Sprintf("match-acceptances-%s-%d", event.PassengerID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 10 * time.Second,
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish driver acceptances event to JetStream",
+			logger.String("passenger_id", event.PassengerID),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish driver acceptances event: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Successfully published driver acceptances event to JetStream",
+		logger.String("passenger_id", event.PassengerID),
+		logger.Int("options", len(event.Options)))
+
+	return nil
+}
+
+// PublishPendingProposals publishes a passenger's pending proposals, re-ranked after their drivers moved
+func (g *NATSGateway) PublishPendingProposals(ctx context.Context, event models.PendingProposalsEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal pending proposals event: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectMatchProposals,
+		Data:    data,
+		MsgID:   fmt.Sprintf("match-proposals-%s-%d", event.PassengerID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 10 * time.Second,
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish pending proposals event to JetStream",
+			logger.String("passenger_id", event.PassengerID),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish pending proposals event: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Successfully published pending proposals event to JetStream",
+		logger.String("passenger_id", event.PassengerID),
+		logger.Int("proposals", len(event.Proposals)))
+
+	return nil
+}
@@ -14,6 +14,10 @@ type MatchGW interface {
 	PublishMatchFound(ctx context.Context, matchProp models.MatchProposal) error
 	PublishMatchRejected(ctx context.Context, matchProp models.MatchProposal) error
 	PublishMatchAccepted(ctx context.Context, matchProp models.MatchProposal) error
+	PublishNoDriversFound(ctx context.Context, event models.NoDriversFoundEvent) error
+	PublishMatchMaintenance(ctx context.Context, event models.MatchMaintenanceEvent) error
+	PublishDriverAcceptances(ctx context.Context, event models.DriverAcceptancesEvent) error
+	PublishPendingProposals(ctx context.Context, event models.PendingProposalsEvent) error
 
 	// HTTP Gateway operations (Location service)
 	AddAvailableDriver(ctx context.Context, driverID string, location *models.Location) error
@@ -0,0 +1,118 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
+	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/match"
+)
+
+// GetMaintenanceMode reports whether the match service is refusing new matches
+func (h *MatchHandler) GetMaintenanceMode(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Match.GetMaintenanceMode")
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "admin_get_maintenance_mode")
+
+	enabled := h.matchUC.IsMaintenanceMode(c.Request().Context())
+	return utils.SuccessResponse(c, http.StatusOK, "Maintenance mode retrieved successfully", models.MaintenanceMode{Enabled: enabled})
+}
+
+// SetMaintenanceMode switches maintenance mode on or off. While it is on no new matches are made,
+// but rides already under way carry on.
+func (h *MatchHandler) SetMaintenanceMode(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Match.SetMaintenanceMode")
+
+	var req models.MaintenanceMode
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "admin_set_maintenance_mode")
+	nrpkg.AddTransactionAttribute(txn, "maintenance.enabled", req.Enabled)
+
+	if err := h.matchUC.SetMaintenanceMode(c.Request().Context(), req.Enabled); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to set maintenance mode", logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to set maintenance mode")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Maintenance mode updated successfully", req)
+}
+
+// defaultRejectionReasonDays is how far back the rejection reasons report looks when no range is given
+const defaultRejectionReasonDays = 30
+
+// maxRejectionReasonDays caps how far back the rejection reasons report can look
+const maxRejectionReasonDays = 365
+
+// GetRejectionReasonStats reports how often drivers gave each reason for rejecting a match over the last days
+func (h *MatchHandler) GetRejectionReasonStats(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Match.GetRejectionReasonStats")
+
+	days := defaultRejectionReasonDays
+	if raw := c.QueryParam("days"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1 || parsed > maxRejectionReasonDays {
+			return utils.BadRequestResponse(c, "days must be between 1 and "+strconv.Itoa(maxRejectionReasonDays))
+		}
+		days = parsed
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "admin_rejection_reason_stats")
+	nrpkg.AddTransactionAttribute(txn, "rejection_reasons.days", days)
+
+	since := time.Now().AddDate(0, 0, -days)
+	stats, err := h.matchUC.GetRejectionReasonStats(c.Request().Context(), since)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to get rejection reason stats", logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to get rejection reason stats")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Rejection reason stats retrieved successfully", stats)
+}
+
+// GetMatchDebug returns a match's full confirmation state, including whether each user is in the
+// available pool or on an active ride, for working out why it won't accept
+func (h *MatchHandler) GetMatchDebug(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Match.GetMatchDebug")
+
+	matchID := c.Param("matchID")
+	if _, err := uuid.Parse(matchID); err != nil {
+		return utils.BadRequestResponse(c, "invalid match ID")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "debug_match")
+	nrpkg.AddTransactionAttribute(txn, "match.id", matchID)
+
+	view, err := h.matchUC.GetMatchDebug(c.Request().Context(), matchID)
+	if err != nil {
+		if errors.Is(err, match.ErrMatchNotFound) {
+			return utils.NotFoundResponse(c, "match not found")
+		}
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.Error("Failed to get match debug view",
+			logger.String("match_id", matchID),
+			logger.ErrorField(err))
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "failed to get match debug view")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Match debug view retrieved successfully", view)
+}
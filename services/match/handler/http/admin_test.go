@@ -0,0 +1,275 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type maintenanceModeResponse struct {
+	Success bool                   `json:"success"`
+	Data    models.MaintenanceMode `json:"data"`
+}
+
+func TestMatchHandler_GetMaintenanceMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().IsMaintenanceMode(gomock.Any()).Return(true)
+	handler := NewMatchHandler(mockMatchUC)
+
+	e := echo.New()
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/maintenance", nil), recorder)
+
+	require.NoError(t, handler.GetMaintenanceMode(c))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp maintenanceModeResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.True(t, resp.Data.Enabled)
+}
+
+func TestMatchHandler_SetMaintenanceMode(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func(*mocks.MockMatchUC)
+		expectedStatus int
+	}{
+		{
+			name: "Enables maintenance",
+			body: `{"enabled":true}`,
+			mockSetup: func(mockUC *mocks.MockMatchUC) {
+				mockUC.EXPECT().SetMaintenanceMode(gomock.Any(), true).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Disables maintenance",
+			body: `{"enabled":false}`,
+			mockSetup: func(mockUC *mocks.MockMatchUC) {
+				mockUC.EXPECT().SetMaintenanceMode(gomock.Any(), false).Return(nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Rejects malformed body",
+			body:           `{"enabled":`,
+			mockSetup:      func(*mocks.MockMatchUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Store failure",
+			body: `{"enabled":true}`,
+			mockSetup: func(mockUC *mocks.MockMatchUC) {
+				mockUC.EXPECT().SetMaintenanceMode(gomock.Any(), true).Return(errors.New("redis down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMatchUC := mocks.NewMockMatchUC(ctrl)
+			tt.mockSetup(mockMatchUC)
+			handler := NewMatchHandler(mockMatchUC)
+
+			e := echo.New()
+			request := httptest.NewRequest(http.MethodPut, "/admin/maintenance", strings.NewReader(tt.body))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+
+			require.NoError(t, handler.SetMaintenanceMode(c))
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+		})
+	}
+}
+
+type rejectionStatsResponse struct {
+	Success bool                       `json:"success"`
+	Data    models.MatchRejectionStats `json:"data"`
+}
+
+func TestMatchHandler_GetRejectionReasonStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	stats := &models.MatchRejectionStats{
+		Total: 4,
+		Reasons: []models.RejectionReasonCount{
+			{Reason: models.RejectionReasonTooFar, Count: 4},
+			{Reason: models.RejectionReasonWrongDirection, Count: 0},
+			{Reason: models.RejectionReasonUndesirableDestination, Count: 0},
+		},
+	}
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().
+		GetRejectionReasonStats(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, since time.Time) (*models.MatchRejectionStats, error) {
+			assert.WithinDuration(t, time.Now().AddDate(0, 0, -7), since, time.Minute)
+			return stats, nil
+		})
+	handler := NewMatchHandler(mockMatchUC)
+
+	e := echo.New()
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/rejection-reasons?days=7", nil), recorder)
+
+	require.NoError(t, handler.GetRejectionReasonStats(c))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp rejectionStatsResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.Equal(t, 4, resp.Data.Total)
+	assert.Equal(t, stats.Reasons, resp.Data.Reasons)
+}
+
+func TestMatchHandler_GetRejectionReasonStats_InvalidDays(t *testing.T) {
+	for _, days := range []string{"0", "366", "week"} {
+		ctrl := gomock.NewController(t)
+		handler := NewMatchHandler(mocks.NewMockMatchUC(ctrl))
+
+		e := echo.New()
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/rejection-reasons?days="+days, nil), recorder)
+
+		require.NoError(t, handler.GetRejectionReasonStats(c))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code, "days=%s", days)
+		ctrl.Finish()
+	}
+}
+
+func TestMatchHandler_GetRejectionReasonStats_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().GetRejectionReasonStats(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+	handler := NewMatchHandler(mockMatchUC)
+
+	e := echo.New()
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/admin/rejection-reasons", nil), recorder)
+
+	require.NoError(t, handler.GetRejectionReasonStats(c))
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+type matchDebugResponse struct {
+	Success bool                  `json:"success"`
+	Data    models.MatchDebugView `json:"data"`
+}
+
+func TestMatchHandler_GetMatchDebug(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	matchID := uuid.NewString()
+	view := &models.MatchDebugView{
+		MatchID:            matchID,
+		Status:             models.MatchStatusDriverConfirmed,
+		DriverConfirmed:    true,
+		PassengerConfirmed: false,
+		CreatedAt:          time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:          time.Date(2024, 1, 1, 12, 0, 5, 0, time.UTC),
+		Driver: models.MatchParticipantState{
+			UserID:       uuid.NewString(),
+			ActiveRideID: uuid.NewString(),
+			Errors:       []string{"pool location: HTTP error 404: 404 Not Found"},
+		},
+		Passenger: models.MatchParticipantState{
+			UserID:   uuid.NewString(),
+			InPool:   true,
+			Location: &models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		},
+	}
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().GetMatchDebug(gomock.Any(), matchID).Return(view, nil)
+	handler := NewMatchHandler(mockMatchUC)
+
+	e := echo.New()
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(httptest.NewRequest(http.MethodGet, "/debug/match/"+matchID, nil), recorder)
+	c.SetParamNames("matchID")
+	c.SetParamValues(matchID)
+
+	require.NoError(t, handler.GetMatchDebug(c))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var resp matchDebugResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	assert.True(t, resp.Success)
+	assert.Equal(t, *view, resp.Data)
+}
+
+func TestMatchHandler_GetMatchDebug_Errors(t *testing.T) {
+	tests := []struct {
+		name           string
+		matchID        string
+		mockSetup      func(*mocks.MockMatchUC)
+		expectedStatus int
+	}{
+		{
+			name:           "Invalid match ID",
+			matchID:        "not-a-uuid",
+			mockSetup:      func(mockUC *mocks.MockMatchUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:    "Unknown match",
+			matchID: uuid.NewString(),
+			mockSetup: func(mockUC *mocks.MockMatchUC) {
+				mockUC.EXPECT().GetMatchDebug(gomock.Any(), gomock.Any()).Return(nil, match.ErrMatchNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:    "Match lookup fails",
+			matchID: uuid.NewString(),
+			mockSetup: func(mockUC *mocks.MockMatchUC) {
+				mockUC.EXPECT().GetMatchDebug(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMatchUC := mocks.NewMockMatchUC(ctrl)
+			tt.mockSetup(mockMatchUC)
+			handler := NewMatchHandler(mockMatchUC)
+
+			e := echo.New()
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(httptest.NewRequest(http.MethodGet, "/debug/match/"+tt.matchID, nil), recorder)
+			c.SetParamNames("matchID")
+			c.SetParamValues(tt.matchID)
+
+			require.NoError(t, handler.GetMatchDebug(c))
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+		})
+	}
+}
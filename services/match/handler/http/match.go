@@ -1,9 +1,11 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/converter"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
 	"github.com/piresc/nebengjek/internal/utils"
@@ -32,6 +34,9 @@ func (h *MatchHandler) ConfirmMatch(c echo.Context) error {
 	if matchID == "" {
 		return utils.BadRequestResponse(c, "Match ID is required")
 	}
+	if _, err := converter.ParseUUID(matchID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid match ID")
+	}
 
 	var req models.MatchConfirmRequest
 	if err := c.Bind(&req); err != nil {
@@ -44,10 +49,16 @@ func (h *MatchHandler) ConfirmMatch(c echo.Context) error {
 	if req.UserID == "" {
 		return utils.BadRequestResponse(c, "User ID is required")
 	}
+	if _, err := converter.ParseUUID(req.UserID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
 
 	if req.Status != string(models.MatchStatusAccepted) && req.Status != string(models.MatchStatusRejected) {
 		return utils.BadRequestResponse(c, "Status must be either ACCEPTED or REJECTED")
 	}
+	if err := req.ValidateReason(); err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
 
 	// Add transaction attributes for better tracing
 	nrpkg.AddTransactionAttribute(txn, "endpoint", "confirm_match")
@@ -58,8 +69,97 @@ func (h *MatchHandler) ConfirmMatch(c echo.Context) error {
 	result, err := h.matchUC.ConfirmMatchStatus(c.Request().Context(), &req)
 	if err != nil {
 		nrpkg.NoticeTransactionError(txn, err)
+		if errors.Is(err, match.ErrConfirmationConflict) {
+			return utils.ErrorResponseHandler(c, http.StatusConflict, err.Error())
+		}
 		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to confirm match: "+err.Error())
 	}
 
 	return utils.SuccessResponse(c, http.StatusOK, "Match confirmation processed successfully", result)
 }
+
+// GetDriverMatches handles retrieval of a driver's match history
+func (h *MatchHandler) GetDriverMatches(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Match.GetDriverMatches")
+
+	driverID := c.Param("driverID")
+	if driverID == "" {
+		return utils.BadRequestResponse(c, "Driver ID is required")
+	}
+	if _, err := converter.ParseUUID(driverID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid driver ID")
+	}
+
+	limit, offset, err := utils.ParsePagination(c)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "driver_matches")
+	nrpkg.AddTransactionAttribute(txn, "driver.id", driverID)
+
+	history, err := h.matchUC.GetDriverMatches(c.Request().Context(), driverID, limit, offset)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to get driver matches: "+err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver matches retrieved successfully", history)
+}
+
+// GetUserActiveRide handles lookup of the ride a user is currently on as driver or passenger
+func (h *MatchHandler) GetUserActiveRide(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Match.GetUserActiveRide")
+
+	userID := c.Param("userID")
+	if _, err := converter.ParseUUID(userID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+
+	role := c.QueryParam("role")
+	if role != "driver" && role != "passenger" {
+		return utils.BadRequestResponse(c, "Role must be either driver or passenger")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "user_active_ride")
+	nrpkg.AddTransactionAttribute(txn, "user.id", userID)
+
+	rideID, err := h.matchUC.GetActiveRideID(c.Request().Context(), userID, role == "driver")
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to get active ride: "+err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Active ride retrieved successfully",
+		models.ActiveRide{UserID: userID, Role: role, RideID: rideID})
+}
+
+// GetDriverCancellationStats handles retrieval of a driver's cancellation rate and any suspension
+func (h *MatchHandler) GetDriverCancellationStats(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Match.GetDriverCancellationStats")
+
+	driverID := c.Param("driverID")
+	if driverID == "" {
+		return utils.BadRequestResponse(c, "Driver ID is required")
+	}
+	if _, err := converter.ParseUUID(driverID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid driver ID")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "driver_cancellation_stats")
+	nrpkg.AddTransactionAttribute(txn, "driver.id", driverID)
+
+	stats, err := h.matchUC.GetDriverCancellationStats(c.Request().Context(), driverID)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to get driver cancellation stats: "+err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver cancellation stats retrieved successfully", stats)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -12,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
 	"github.com/piresc/nebengjek/services/match/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -118,6 +120,43 @@ func TestMatchHandler_ConfirmMatch_Success_Rejected(t *testing.T) {
 	assert.Equal(t, http.StatusOK, recorder.Code)
 }
 
+func TestMatchHandler_ConfirmMatch_InvalidReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		status models.MatchStatus
+		reason string
+	}{
+		{name: "Unknown reason", status: models.MatchStatusRejected, reason: "TOO_HOT"},
+		{name: "Reason when accepting", status: models.MatchStatusAccepted, reason: string(models.RejectionReasonTooFar)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// The usecase is never reached
+			handler := NewMatchHandler(mocks.NewMockMatchUC(ctrl))
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(map[string]interface{}{
+				"user_id": uuid.New().String(),
+				"status":  string(tt.status),
+				"reason":  tt.reason,
+			})
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("matchID")
+			c.SetParamValues(uuid.New().String())
+
+			assert.NoError(t, handler.ConfirmMatch(c))
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		})
+	}
+}
+
 func TestMatchHandler_ConfirmMatch_MissingMatchID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -169,6 +208,67 @@ func TestMatchHandler_ConfirmMatch_InvalidRequestBody(t *testing.T) {
 	assert.Contains(t, response["error"], "Invalid request body")
 }
 
+func TestMatchHandler_ConfirmMatch_MalformedIDs(t *testing.T) {
+	testCases := []struct {
+		name          string
+		matchID       string
+		userID        string
+		expectedError string
+	}{
+		{
+			name:          "Malformed match ID",
+			matchID:       "match-123",
+			userID:        uuid.New().String(),
+			expectedError: "Invalid match ID",
+		},
+		{
+			name:          "Nil match ID",
+			matchID:       uuid.Nil.String(),
+			userID:        uuid.New().String(),
+			expectedError: "Invalid match ID",
+		},
+		{
+			name:          "Malformed user ID",
+			matchID:       uuid.New().String(),
+			userID:        "user-123",
+			expectedError: "Invalid user ID",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// The usecase must not be reached with an ID that would silently become uuid.Nil
+			mockMatchUC := mocks.NewMockMatchUC(ctrl)
+			handler := NewMatchHandler(mockMatchUC)
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(map[string]interface{}{
+				"user_id": tc.userID,
+				"status":  string(models.MatchStatusAccepted),
+			})
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("matchID")
+			c.SetParamValues(tc.matchID)
+
+			err := handler.ConfirmMatch(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+			var response map[string]interface{}
+			err = json.Unmarshal(recorder.Body.Bytes(), &response)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedError, response["error"])
+		})
+	}
+}
+
 func TestMatchHandler_ConfirmMatch_MissingUserID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -300,4 +400,194 @@ func TestMatchHandler_ConfirmMatch_UseCaseError(t *testing.T) {
 	err = json.Unmarshal(recorder.Body.Bytes(), &response)
 	assert.NoError(t, err)
 	assert.Contains(t, response["error"], "Failed to confirm match")
-}
\ No newline at end of file
+}
+func TestMatchHandler_ConfirmMatch_Conflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	handler := NewMatchHandler(mockMatchUC)
+
+	matchID := uuid.New().String()
+	userID := uuid.New().String()
+	req := models.MatchConfirmRequest{
+		ID:     matchID,
+		UserID: userID,
+		Status: string(models.MatchStatusRejected),
+	}
+
+	mockMatchUC.EXPECT().
+		ConfirmMatchStatus(gomock.Any(), &req).
+		Return(models.MatchProposal{}, fmt.Errorf("%w: cannot reject an accepted match", match.ErrConfirmationConflict))
+
+	e := echo.New()
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"user_id": userID,
+		"status":  string(models.MatchStatusRejected),
+	})
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("matchID")
+	c.SetParamValues(matchID)
+
+	err := handler.ConfirmMatch(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestMatchHandler_GetDriverMatches_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	handler := NewMatchHandler(mockMatchUC)
+
+	driverID := uuid.New().String()
+	expected := &models.MatchHistory{
+		Matches: []*models.Match{{ID: uuid.New(), Status: models.MatchStatusAccepted}},
+		Limit:   5,
+		Offset:  10,
+	}
+
+	mockMatchUC.EXPECT().
+		GetDriverMatches(gomock.Any(), driverID, 5, 10).
+		Return(expected, nil).
+		Times(1)
+
+	e := echo.New()
+	request := httptest.NewRequest(http.MethodGet, "/?limit=5&offset=10", nil)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("driverID")
+	c.SetParamValues(driverID)
+
+	err := handler.GetDriverMatches(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Driver matches retrieved successfully", response["message"])
+}
+
+func TestMatchHandler_GetDriverMatches_InvalidPagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	handler := NewMatchHandler(mockMatchUC)
+
+	e := echo.New()
+	request := httptest.NewRequest(http.MethodGet, "/?limit=abc", nil)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("driverID")
+	c.SetParamValues(uuid.New().String())
+
+	err := handler.GetDriverMatches(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestMatchHandler_GetDriverMatches_MalformedDriverID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	handler := NewMatchHandler(mockMatchUC)
+
+	e := echo.New()
+	request := httptest.NewRequest(http.MethodGet, "/", nil)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("driverID")
+	c.SetParamValues("driver-123")
+
+	err := handler.GetDriverMatches(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestMatchHandler_GetUserActiveRide(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		userID         string
+		role           string
+		setupMock      func(*mocks.MockMatchUC)
+		expectedStatus int
+	}{
+		{
+			name:   "Driver on a ride",
+			userID: userID,
+			role:   "driver",
+			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().GetActiveRideID(gomock.Any(), userID, true).Return("ride-1", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Passenger without a ride",
+			userID: userID,
+			role:   "passenger",
+			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().GetActiveRideID(gomock.Any(), userID, false).Return("", nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Malformed user ID",
+			userID:         "user-123",
+			role:           "driver",
+			setupMock:      func(m *mocks.MockMatchUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown role",
+			userID:         userID,
+			role:           "admin",
+			setupMock:      func(m *mocks.MockMatchUC) {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Lookup failure",
+			userID: userID,
+			role:   "driver",
+			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().GetActiveRideID(gomock.Any(), userID, true).Return("", errors.New("redis down"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockMatchUC := mocks.NewMockMatchUC(ctrl)
+			tt.setupMock(mockMatchUC)
+			handler := NewMatchHandler(mockMatchUC)
+
+			e := echo.New()
+			request := httptest.NewRequest(http.MethodGet, "/?role="+tt.role, nil)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("userID")
+			c.SetParamValues(tt.userID)
+
+			err := handler.GetUserActiveRide(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, recorder.Code)
+		})
+	}
+}
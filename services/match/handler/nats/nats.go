@@ -3,11 +3,14 @@ package nats
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	pkgcontext "github.com/piresc/nebengjek/internal/pkg/context"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
@@ -118,6 +121,46 @@ func (h *MatchHandler) InitNATSConsumers() error {
 		return fmt.Errorf("failed to start consuming ride completed events: %w", err)
 	}
 
+	// Create ride cancelled consumer - RECREATE to ensure DeliverNewPolicy is applied
+	rideCancelledConfig := consumerConfigs["ride_cancelled_match"]
+	logger.Info("Recreating ride cancelled consumer for match service with DeliverNewPolicy",
+		logger.String("stream", rideCancelledConfig.StreamName),
+		logger.String("consumer", rideCancelledConfig.ConsumerName),
+		logger.String("deliver_policy", "DeliverNewPolicy"))
+
+	if err := h.natsClient.RecreateConsumer(rideCancelledConfig); err != nil {
+		logger.Error("Failed to recreate ride cancelled consumer for match service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to recreate ride cancelled consumer: %w", err)
+	}
+
+	// Start consuming ride cancelled events
+	if err := h.natsClient.ConsumeMessages("RIDE_STREAM", "ride_cancelled_match", h.handleRideCancelledJS); err != nil {
+		logger.Error("Failed to start consuming ride cancelled events for match service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming ride cancelled events: %w", err)
+	}
+
+	// Create location update consumer - RECREATE to ensure DeliverNewPolicy is applied
+	locationUpdateConfig := consumerConfigs["location_update_match"]
+	logger.Info("Recreating location update consumer for match service with DeliverNewPolicy",
+		logger.String("stream", locationUpdateConfig.StreamName),
+		logger.String("consumer", locationUpdateConfig.ConsumerName),
+		logger.String("deliver_policy", "DeliverNewPolicy"))
+
+	if err := h.natsClient.RecreateConsumer(locationUpdateConfig); err != nil {
+		logger.Error("Failed to recreate location update consumer for match service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to recreate location update consumer: %w", err)
+	}
+
+	// Start consuming location updates
+	if err := h.natsClient.ConsumeMessages("LOCATION_STREAM", "location_update_match", h.handleLocationUpdateJS); err != nil {
+		logger.Error("Failed to start consuming location updates for match service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming location updates: %w", err)
+	}
+
 	logger.Info("Successfully initialized JetStream consumers for match service")
 	return nil
 }
@@ -126,8 +169,8 @@ func (h *MatchHandler) InitNATSConsumers() error {
 
 // handleBeaconEventJS processes beacon events from JetStream
 func (h *MatchHandler) handleBeaconEventJS(msg jetstream.Msg) error {
-	// Create background transaction for NATS message processing
-	txn := h.nrApp.StartTransaction("NATS.Match.HandleBeaconEvent")
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Match.HandleBeaconEvent", msg)
 	defer txn.End()
 
 	// Add message attributes
@@ -142,6 +185,11 @@ func (h *MatchHandler) handleBeaconEventJS(msg jetstream.Msg) error {
 		logger.String("subject", msg.Subject()))
 
 	if err := h.handleBeaconEvent(ctx, msg.Data()); err != nil {
+		if errors.Is(err, match.ErrMaintenanceMode) {
+			// The user has been told; redelivery would be refused again, so they re-announce themselves once maintenance ends
+			logger.WarnCtx(ctx, "Dropping beacon event during maintenance")
+			return nil
+		}
 		nrpkg.NoticeTransactionError(txn, err)
 		logger.ErrorCtx(ctx, "Error handling beacon event", logger.Err(err))
 		return err // Return error to trigger NAK and retry
@@ -152,8 +200,8 @@ func (h *MatchHandler) handleBeaconEventJS(msg jetstream.Msg) error {
 
 // handleFinderEventJS processes finder events from JetStream
 func (h *MatchHandler) handleFinderEventJS(msg jetstream.Msg) error {
-	// Create background transaction for NATS message processing
-	txn := h.nrApp.StartTransaction("NATS.Match.HandleFinderEvent")
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Match.HandleFinderEvent", msg)
 	defer txn.End()
 
 	// Add message attributes
@@ -168,6 +216,11 @@ func (h *MatchHandler) handleFinderEventJS(msg jetstream.Msg) error {
 		logger.String("subject", msg.Subject()))
 
 	if err := h.handleFinderEvent(ctx, msg.Data()); err != nil {
+		if errors.Is(err, match.ErrMaintenanceMode) {
+			// The user has been told; redelivery would be refused again, so they re-announce themselves once maintenance ends
+			logger.WarnCtx(ctx, "Dropping finder event during maintenance")
+			return nil
+		}
 		nrpkg.NoticeTransactionError(txn, err)
 		logger.ErrorCtx(ctx, "Error handling finder event", logger.Err(err))
 		return err // Return error to trigger NAK and retry
@@ -178,8 +231,8 @@ func (h *MatchHandler) handleFinderEventJS(msg jetstream.Msg) error {
 
 // handleRidePickupJS processes ride pickup events from JetStream
 func (h *MatchHandler) handleRidePickupJS(msg jetstream.Msg) error {
-	// Create background transaction for NATS message processing
-	txn := h.nrApp.StartTransaction("NATS.Match.HandleRidePickup")
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Match.HandleRidePickup", msg)
 	defer txn.End()
 
 	// Add message attributes
@@ -204,8 +257,8 @@ func (h *MatchHandler) handleRidePickupJS(msg jetstream.Msg) error {
 
 // handleRideCompletedJS processes ride completed events from JetStream
 func (h *MatchHandler) handleRideCompletedJS(msg jetstream.Msg) error {
-	// Create background transaction for NATS message processing
-	txn := h.nrApp.StartTransaction("NATS.Match.HandleRideCompleted")
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Match.HandleRideCompleted", msg)
 	defer txn.End()
 
 	// Add message attributes
@@ -228,6 +281,55 @@ func (h *MatchHandler) handleRideCompletedJS(msg jetstream.Msg) error {
 	return nil // Success - message will be ACKed automatically
 }
 
+// handleRideCancelledJS processes ride cancelled events from JetStream
+func (h *MatchHandler) handleRideCancelledJS(msg jetstream.Msg) error {
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Match.HandleRideCancelled", msg)
+	defer txn.End()
+
+	// Add message attributes
+	nrpkg.AddTransactionAttribute(txn, "message.subject", msg.Subject())
+	nrpkg.AddTransactionAttribute(txn, "message.size", len(msg.Data()))
+	nrpkg.AddTransactionAttribute(txn, "service", "match")
+
+	// Create context with transaction
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	logger.InfoCtx(ctx, "Received ride cancelled event from JetStream",
+		logger.String("subject", msg.Subject()))
+
+	if err := h.handleRideCancelled(ctx, msg.Data()); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.ErrorCtx(ctx, "Error handling ride cancelled event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
+// handleLocationUpdateJS processes driver location updates from JetStream
+func (h *MatchHandler) handleLocationUpdateJS(msg jetstream.Msg) error {
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Match.HandleLocationUpdate", msg)
+	defer txn.End()
+
+	// Add message attributes
+	nrpkg.AddTransactionAttribute(txn, "message.subject", msg.Subject())
+	nrpkg.AddTransactionAttribute(txn, "message.size", len(msg.Data()))
+	nrpkg.AddTransactionAttribute(txn, "service", "match")
+
+	// Create context with transaction
+	ctx := newrelic.NewContext(context.Background(), txn)
+
+	if err := h.handleLocationUpdate(ctx, msg.Data()); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		logger.ErrorCtx(ctx, "Error handling location update", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
 // handleBeaconEvent processes beacon events from the user service
 func (h *MatchHandler) handleBeaconEvent(ctx context.Context, msg []byte) error {
 	var event models.BeaconEvent
@@ -268,13 +370,17 @@ func (h *MatchHandler) handleFinderEvent(ctx context.Context, msg []byte) error
 		nrpkg.AddTransactionAttribute(txn, "target.longitude", event.TargetLocation.Longitude)
 	}
 
+	// Proposals published while handling the search carry the same correlation ID
+	ctx = pkgcontext.WithCorrelationID(ctx, event.CorrelationID)
+
 	logger.InfoCtx(ctx, "Received finder event",
 		logger.String("user_id", event.UserID),
 		logger.Bool("is_active", event.IsActive),
 		logger.Float64("location_lat", event.Location.Latitude),
 		logger.Float64("location_lng", event.Location.Longitude),
 		logger.Float64("target_lat", event.TargetLocation.Latitude),
-		logger.Float64("target_lng", event.TargetLocation.Longitude))
+		logger.Float64("target_lng", event.TargetLocation.Longitude),
+		logger.String("correlation_id", event.CorrelationID))
 
 	// Forward the event to usecase for processing
 	return h.matchUC.HandleFinderEvent(ctx, event)
@@ -300,6 +406,14 @@ func (h *MatchHandler) handleRidePickup(ctx context.Context, msg []byte) error {
 		logger.String("driver_id", ridePickup.DriverID),
 		logger.String("passenger_id", ridePickup.PassengerID))
 
+	// Hold the ride lock so racing beacon/finder events cannot re-add the users to pools
+	if err := h.matchUC.LockUsersForRide(ctx, ridePickup.DriverID, ridePickup.PassengerID); err != nil {
+		logger.WarnCtx(ctx, "Failed to acquire ride locks",
+			logger.String("ride_id", ridePickup.RideID),
+			logger.Err(err))
+		// Continue even if this fails - don't block ride flow
+	}
+
 	// Store active ride information in Redis
 	if err := h.matchUC.SetActiveRide(ctx, ridePickup.DriverID, ridePickup.PassengerID, ridePickup.RideID); err != nil {
 		logger.WarnCtx(ctx, "Failed to set active ride",
@@ -347,13 +461,145 @@ func (h *MatchHandler) handleRideCompleted(ctx context.Context, msg []byte) erro
 		logger.String("driver_id", rideComplete.Ride.DriverID.String()),
 		logger.String("passenger_id", rideComplete.Ride.PassengerID.String()))
 
+	// Both cleanup steps are idempotent, so a redelivery after a partial failure is safe
+	if err := h.releaseRideUsers(ctx, rideComplete.Ride); err != nil {
+		return err
+	}
+
+	// The driver's wait for their next ride starts now
+	if err := h.matchUC.RecordDriverRideCompleted(ctx, rideComplete.Ride.DriverID.String(), time.Now()); err != nil {
+		logger.WarnCtx(ctx, "Failed to record driver ride completion",
+			logger.String("ride_id", rideComplete.Ride.RideID.String()),
+			logger.Err(err))
+	}
+	return nil
+}
+
+// handleRideCancelled processes ride cancelled events so both users can be matched again
+func (h *MatchHandler) handleRideCancelled(ctx context.Context, msg []byte) error {
+	var rideCancelled models.RideCancelled
+	if err := json.Unmarshal(msg, &rideCancelled); err != nil {
+		logger.ErrorCtx(ctx, "Failed to unmarshal ride cancelled event", logger.Err(err))
+		return err
+	}
+
+	// Add business attributes to transaction
+	if txn := nrpkg.FromContext(ctx); txn != nil {
+		nrpkg.AddTransactionAttribute(txn, "ride.id", rideCancelled.Ride.RideID.String())
+		nrpkg.AddTransactionAttribute(txn, "driver.id", rideCancelled.Ride.DriverID.String())
+		nrpkg.AddTransactionAttribute(txn, "passenger.id", rideCancelled.Ride.PassengerID.String())
+		nrpkg.AddTransactionAttribute(txn, "cancelled.by", rideCancelled.Cancellation.Role)
+	}
+
+	logger.InfoCtx(ctx, "Received ride cancelled event",
+		logger.String("ride_id", rideCancelled.Ride.RideID.String()),
+		logger.String("driver_id", rideCancelled.Ride.DriverID.String()),
+		logger.String("passenger_id", rideCancelled.Ride.PassengerID.String()),
+		logger.String("cancelled_by", rideCancelled.Cancellation.Role))
+
+	if err := h.releaseRideUsers(ctx, rideCancelled.Ride); err != nil {
+		return err
+	}
+
+	// Only cancellations by the driver count towards their cancellation rate
+	if rideCancelled.Cancellation.Role == models.CancelledByDriver {
+		if err := h.matchUC.HandleDriverCancellation(ctx, rideCancelled.Ride.DriverID.String(), rideCancelled.Ride.RideID.String()); err != nil {
+			logger.WarnCtx(ctx, "Failed to track driver cancellation",
+				logger.String("ride_id", rideCancelled.Ride.RideID.String()),
+				logger.String("driver_id", rideCancelled.Ride.DriverID.String()),
+				logger.Err(err))
+		}
+	}
+	return nil
+}
+
+// handleLocationUpdate re-ranks the proposals of a driver whose position changed
+func (h *MatchHandler) handleLocationUpdate(ctx context.Context, msg []byte) error {
+	var update models.LocationUpdate
+	if err := json.Unmarshal(msg, &update); err != nil {
+		logger.ErrorCtx(ctx, "Failed to unmarshal location update", logger.Err(err))
+		return err
+	}
+
+	if txn := nrpkg.FromContext(ctx); txn != nil {
+		nrpkg.AddTransactionAttribute(txn, "driver.id", update.DriverID)
+		nrpkg.AddTransactionAttribute(txn, "ride.id", update.RideID)
+	}
+
+	h.matchUC.HandleDriverLocationUpdate(ctx, update)
+	return nil
+}
+
+// releaseRideUsers clears a finished ride's tracking so its driver and passenger can rejoin the pools.
+// It reverses what the pickup handler set up, the active ride keys and ride locks, and stops sharing
+// the driver's position; pool membership and position come back with the users' next beacon and
+// finder events. A driver who opted in to back-to-back rides stays in the pool awaiting their next
+// match, and one already picked up for it stays tracked and locked for it. Every step is attempted, and any failure is returned so
+// the event is redelivered rather than leaving a user locked out of matching.
+func (h *MatchHandler) releaseRideUsers(ctx context.Context, ride models.Ride) error {
+	driverID := ride.DriverID.String()
+	hasNextRide, err := h.matchUC.DriverHasNextRide(ctx, driverID, ride.RideID.String())
+	if err != nil {
+		logger.WarnCtx(ctx, "Failed to check driver's next ride",
+			logger.String("ride_id", ride.RideID.String()),
+			logger.Err(err))
+		return fmt.Errorf("failed to check driver's next ride: %w", err)
+	}
+	if hasNextRide {
+		logger.InfoCtx(ctx, "Driver is already on their next ride, releasing only the passenger",
+			logger.String("ride_id", ride.RideID.String()),
+			logger.String("driver_id", driverID))
+		driverID = ""
+	}
+
+	var errs []error
+
 	// Remove active ride information from Redis
-	if err := h.matchUC.RemoveActiveRide(ctx, rideComplete.Ride.DriverID.String(), rideComplete.Ride.PassengerID.String()); err != nil {
+	if err := h.matchUC.RemoveActiveRide(ctx, driverID, ride.PassengerID.String()); err != nil {
 		logger.WarnCtx(ctx, "Failed to remove active ride",
-			logger.String("ride_id", rideComplete.Ride.RideID.String()),
+			logger.String("ride_id", ride.RideID.String()),
+			logger.Err(err))
+		// Continue so the locks are still released
+		errs = append(errs, fmt.Errorf("failed to remove active ride: %w", err))
+	}
+
+	// Release the ride locks so users can rejoin the pools
+	if err := h.matchUC.ReleaseRideLocks(ctx, driverID, ride.PassengerID.String()); err != nil {
+		logger.WarnCtx(ctx, "Failed to release ride locks",
+			logger.String("ride_id", ride.RideID.String()),
 			logger.Err(err))
-		// Continue even if this fails
+		errs = append(errs, fmt.Errorf("failed to release ride locks: %w", err))
 	}
 
+	// The driver's last position may linger in the pool, so passengers could still see them until
+	// they beacon as available again. A driver who asked for their next match is left in the pool.
+	if driverID != "" {
+		if awaitsNextRide, err := h.matchUC.DriverAwaitsNextRide(ctx, driverID, ride.RideID.String()); err != nil {
+			logger.WarnCtx(ctx, "Failed to check back-to-back opt-in",
+				logger.String("ride_id", ride.RideID.String()),
+				logger.Err(err))
+			errs = append(errs, fmt.Errorf("failed to check back-to-back opt-in: %w", err))
+		} else if awaitsNextRide {
+			logger.InfoCtx(ctx, "Driver awaits their next ride, keeping them in the pool",
+				logger.String("ride_id", ride.RideID.String()),
+				logger.String("driver_id", driverID))
+		} else if err := h.matchUC.RemoveDriverFromPool(ctx, driverID); err != nil {
+			logger.WarnCtx(ctx, "Failed to clear driver location",
+				logger.String("ride_id", ride.RideID.String()),
+				logger.Err(err))
+			errs = append(errs, fmt.Errorf("failed to clear driver location: %w", err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	// Only dropped once the cleanup succeeded, so a redelivery still knows the driver moved on
+	if err := h.matchUC.ClearBackToBackOptIn(ctx, ride.DriverID.String(), ride.RideID.String()); err != nil {
+		logger.WarnCtx(ctx, "Failed to clear back-to-back opt-in",
+			logger.String("ride_id", ride.RideID.String()),
+			logger.Err(err))
+	}
 	return nil
 }
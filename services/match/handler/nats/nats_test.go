@@ -1,18 +1,33 @@
 package nats
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
 	"time"
 
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	pkgcontext "github.com/piresc/nebengjek/internal/pkg/context"
+	"github.com/piresc/nebengjek/internal/pkg/database"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
+	"github.com/piresc/nebengjek/services/match/gateway"
 	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/piresc/nebengjek/services/match/repository"
+	"github.com/piresc/nebengjek/services/match/usecase"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -216,6 +231,89 @@ func TestMatchHandler_handleFinderEvent(t *testing.T) {
 	}
 }
 
+func TestMatchHandler_handleFinderEvent_CorrelationID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	var logs bytes.Buffer
+	previous := logger.GetGlobalLogger()
+	logger.SetGlobalLogger(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer logger.SetGlobalLogger(previous)
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().
+		HandleFinderEvent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ models.FinderEvent) error {
+			// Proposals published from the usecase pick the ID up from the context
+			assert.Equal(t, "corr-123", pkgcontext.GetCorrelationID(ctx))
+			return nil
+		})
+
+	handler := NewMatchHandler(mockMatchUC, &natspkg.Client{}, nil)
+
+	data, _ := json.Marshal(models.FinderEvent{
+		UserID:        uuid.New().String(),
+		IsActive:      true,
+		CorrelationID: "corr-123",
+	})
+
+	err := handler.handleFinderEvent(context.Background(), data)
+
+	assert.NoError(t, err)
+	assert.Contains(t, logs.String(), `"correlation_id":"corr-123"`)
+}
+
+// stubMsg is a JetStream message carrying only a subject, payload and headers
+type stubMsg struct {
+	jetstream.Msg
+	subject string
+	data    []byte
+	headers nats.Header
+}
+
+func (m *stubMsg) Subject() string      { return m.subject }
+func (m *stubMsg) Data() []byte         { return m.data }
+func (m *stubMsg) Headers() nats.Header { return m.headers }
+
+// Test JetStream handlers run with New Relic disabled
+func TestMatchHandler_JetStreamWithoutNewRelic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	handler := NewMatchHandler(mockMatchUC, &natspkg.Client{}, nil)
+
+	event := models.FinderEvent{
+		UserID:         uuid.New().String(),
+		IsActive:       true,
+		Location:       models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		TargetLocation: models.Location{Latitude: -6.185392, Longitude: 106.837153},
+		Timestamp:      time.Now(),
+	}
+	data, _ := json.Marshal(event)
+	msg := &stubMsg{
+		subject: "user.finder",
+		data:    data,
+		headers: nats.Header{"traceparent": []string{"00-trace-span-01"}},
+	}
+
+	t.Run("successful event", func(t *testing.T) {
+		mockMatchUC.EXPECT().HandleFinderEvent(gomock.Any(), gomock.Any()).Return(nil)
+
+		assert.NotPanics(t, func() {
+			assert.NoError(t, handler.handleFinderEventJS(msg))
+		})
+	})
+
+	t.Run("failed event is reported", func(t *testing.T) {
+		mockMatchUC.EXPECT().HandleFinderEvent(gomock.Any(), gomock.Any()).Return(errors.New("processing error"))
+
+		assert.NotPanics(t, func() {
+			assert.Error(t, handler.handleFinderEventJS(msg))
+		})
+	})
+}
+
 // Test ride pickup handler logic directly
 func TestMatchHandler_handleRidePickup(t *testing.T) {
 	tests := []struct {
@@ -241,6 +339,7 @@ func TestMatchHandler_handleRidePickup(t *testing.T) {
 			}(),
 			expectError: false,
 			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().LockUsersForRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().SetActiveRide(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().RemovePassengerFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
@@ -269,6 +368,7 @@ func TestMatchHandler_handleRidePickup(t *testing.T) {
 			}(),
 			expectError: false,
 			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().LockUsersForRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().SetActiveRide(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(errors.New("driver removal failed")).Times(1)
 				m.EXPECT().RemovePassengerFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
@@ -291,6 +391,7 @@ func TestMatchHandler_handleRidePickup(t *testing.T) {
 			}(),
 			expectError: false,
 			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().LockUsersForRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().SetActiveRide(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 				m.EXPECT().RemovePassengerFromPool(gomock.Any(), gomock.Any()).Return(errors.New("passenger removal failed")).Times(1)
@@ -362,7 +463,13 @@ func TestMatchHandler_handleRideCompleted(t *testing.T) {
 			}(),
 			expectError: false,
 			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().DriverHasNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
 				m.EXPECT().RemoveActiveRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().ReleaseRideLocks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().DriverAwaitsNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().ClearBackToBackOptIn(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().RecordDriverRideCompleted(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 		},
 		{
@@ -372,7 +479,7 @@ func TestMatchHandler_handleRideCompleted(t *testing.T) {
 			setupMock:   func(m *mocks.MockMatchUC) {},
 		},
 		{
-			name: "driver release fails but continues",
+			name: "active ride removal fails and the event is redelivered",
 			eventData: func() []byte {
 				driverID := uuid.New()
 				passengerID := uuid.New()
@@ -399,13 +506,17 @@ func TestMatchHandler_handleRideCompleted(t *testing.T) {
 				data, _ := json.Marshal(rideComplete)
 				return data
 			}(),
-			expectError: false,
+			expectError: true,
 			setupMock: func(m *mocks.MockMatchUC) {
-				m.EXPECT().RemoveActiveRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().DriverHasNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveActiveRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("redis down")).Times(1)
+				m.EXPECT().ReleaseRideLocks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().DriverAwaitsNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 			},
 		},
 		{
-			name: "passenger release fails but continues",
+			name: "ride lock release fails and the event is redelivered",
 			eventData: func() []byte {
 				driverID := uuid.New()
 				passengerID := uuid.New()
@@ -432,9 +543,59 @@ func TestMatchHandler_handleRideCompleted(t *testing.T) {
 				data, _ := json.Marshal(rideComplete)
 				return data
 			}(),
+			expectError: true,
+			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().DriverHasNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveActiveRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().ReleaseRideLocks(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("redis down")).Times(1)
+				m.EXPECT().DriverAwaitsNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			},
+		},
+		{
+			name: "clearing the driver's location fails and the event is redelivered",
+			eventData: func() []byte {
+				data, _ := json.Marshal(models.RideComplete{
+					Ride: models.Ride{
+						RideID:      uuid.New(),
+						DriverID:    uuid.New(),
+						PassengerID: uuid.New(),
+						Status:      models.RideStatusCompleted,
+					},
+				})
+				return data
+			}(),
+			expectError: true,
+			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().DriverHasNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveActiveRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().ReleaseRideLocks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().DriverAwaitsNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(errors.New("location service down")).Times(1)
+			},
+		},
+		{
+			name: "recording the driver's last ride fails but continues",
+			eventData: func() []byte {
+				data, _ := json.Marshal(models.RideComplete{
+					Ride: models.Ride{
+						RideID:      uuid.New(),
+						DriverID:    uuid.New(),
+						PassengerID: uuid.New(),
+						Status:      models.RideStatusCompleted,
+					},
+				})
+				return data
+			}(),
 			expectError: false,
 			setupMock: func(m *mocks.MockMatchUC) {
+				m.EXPECT().DriverHasNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
 				m.EXPECT().RemoveActiveRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().ReleaseRideLocks(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().DriverAwaitsNextRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().ClearBackToBackOptIn(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+				m.EXPECT().RecordDriverRideCompleted(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("redis down")).Times(1)
 			},
 		},
 	}
@@ -464,3 +625,244 @@ func TestMatchHandler_handleRideCompleted(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchHandler_handleRideCompleted_ReleasesBothUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ride := models.Ride{
+		RideID:      uuid.New(),
+		DriverID:    uuid.New(),
+		PassengerID: uuid.New(),
+		Status:      models.RideStatusCompleted,
+	}
+	eventData, _ := json.Marshal(models.RideComplete{Ride: ride})
+
+	// Everything the pickup handler set up for the ride is reversed for both users, and the
+	// driver's position is no longer shared
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().DriverHasNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(false, nil)
+	mockMatchUC.EXPECT().RemoveActiveRide(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(nil)
+	mockMatchUC.EXPECT().ReleaseRideLocks(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(nil)
+	mockMatchUC.EXPECT().DriverAwaitsNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(false, nil)
+	mockMatchUC.EXPECT().RemoveDriverFromPool(gomock.Any(), ride.DriverID.String()).Return(nil)
+	mockMatchUC.EXPECT().ClearBackToBackOptIn(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(nil)
+	mockMatchUC.EXPECT().RecordDriverRideCompleted(gomock.Any(), ride.DriverID.String(), gomock.Any()).Return(nil)
+
+	handler := NewMatchHandler(mockMatchUC, &natspkg.Client{}, &newrelic.Application{})
+
+	assert.NoError(t, handler.handleRideCompleted(context.Background(), eventData))
+}
+
+func TestMatchHandler_handleRideCompleted_DriverOnNextRideStaysLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ride := models.Ride{
+		RideID:      uuid.New(),
+		DriverID:    uuid.New(),
+		PassengerID: uuid.New(),
+		Status:      models.RideStatusCompleted,
+	}
+	eventData, _ := json.Marshal(models.RideComplete{Ride: ride})
+
+	// The driver opted in to back-to-back rides and was picked up for the next one, so only the
+	// passenger is released
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().DriverHasNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(true, nil)
+	mockMatchUC.EXPECT().RemoveActiveRide(gomock.Any(), "", ride.PassengerID.String()).Return(nil)
+	mockMatchUC.EXPECT().ReleaseRideLocks(gomock.Any(), "", ride.PassengerID.String()).Return(nil)
+	mockMatchUC.EXPECT().ClearBackToBackOptIn(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(nil)
+	mockMatchUC.EXPECT().RecordDriverRideCompleted(gomock.Any(), ride.DriverID.String(), gomock.Any()).Return(nil)
+
+	handler := NewMatchHandler(mockMatchUC, &natspkg.Client{}, &newrelic.Application{})
+
+	assert.NoError(t, handler.handleRideCompleted(context.Background(), eventData))
+}
+
+func TestMatchHandler_handleRideCompleted_DriverAwaitingNextRideStaysInPool(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ride := models.Ride{
+		RideID:      uuid.New(),
+		DriverID:    uuid.New(),
+		PassengerID: uuid.New(),
+		Status:      models.RideStatusCompleted,
+	}
+	eventData, _ := json.Marshal(models.RideComplete{Ride: ride})
+
+	// The driver opted in to back-to-back rides and was added to the pool near the drop-off, so
+	// releasing the finished ride must not take them out of it again
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().DriverHasNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(false, nil)
+	mockMatchUC.EXPECT().RemoveActiveRide(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(nil)
+	mockMatchUC.EXPECT().ReleaseRideLocks(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(nil)
+	mockMatchUC.EXPECT().DriverAwaitsNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(true, nil)
+	mockMatchUC.EXPECT().RemoveDriverFromPool(gomock.Any(), gomock.Any()).Times(0)
+	mockMatchUC.EXPECT().ClearBackToBackOptIn(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(nil)
+	mockMatchUC.EXPECT().RecordDriverRideCompleted(gomock.Any(), ride.DriverID.String(), gomock.Any()).Return(nil)
+
+	handler := NewMatchHandler(mockMatchUC, &natspkg.Client{}, &newrelic.Application{})
+
+	assert.NoError(t, handler.handleRideCompleted(context.Background(), eventData))
+}
+
+func TestMatchHandler_handleRideCancelled(t *testing.T) {
+	tests := []struct {
+		name        string
+		eventData   []byte
+		expectError bool
+		setupMock   func(*mocks.MockMatchUC, models.Ride)
+	}{
+		{
+			name:        "driver cancellation releases both users",
+			expectError: false,
+			setupMock: func(m *mocks.MockMatchUC, ride models.Ride) {
+				m.EXPECT().DriverHasNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveActiveRide(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(nil).Times(1)
+				m.EXPECT().ReleaseRideLocks(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(nil).Times(1)
+				m.EXPECT().DriverAwaitsNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveDriverFromPool(gomock.Any(), ride.DriverID.String()).Return(nil).Times(1)
+				m.EXPECT().ClearBackToBackOptIn(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(nil).Times(1)
+				m.EXPECT().HandleDriverCancellation(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(nil).Times(1)
+			},
+		},
+		{
+			name:        "failed cleanup is redelivered before the cancellation is counted",
+			expectError: true,
+			setupMock: func(m *mocks.MockMatchUC, ride models.Ride) {
+				m.EXPECT().DriverHasNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveActiveRide(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(errors.New("redis down")).Times(1)
+				m.EXPECT().ReleaseRideLocks(gomock.Any(), ride.DriverID.String(), ride.PassengerID.String()).Return(nil).Times(1)
+				m.EXPECT().DriverAwaitsNextRide(gomock.Any(), ride.DriverID.String(), ride.RideID.String()).Return(false, nil).Times(1)
+				m.EXPECT().RemoveDriverFromPool(gomock.Any(), ride.DriverID.String()).Return(nil).Times(1)
+			},
+		},
+		{
+			name:        "invalid JSON data",
+			eventData:   []byte("invalid json"),
+			expectError: true,
+			setupMock:   func(m *mocks.MockMatchUC, ride models.Ride) {},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ride := models.Ride{
+				RideID:      uuid.New(),
+				DriverID:    uuid.New(),
+				PassengerID: uuid.New(),
+				Status:      models.RideStatusCancelled,
+			}
+			// Cases without raw data publish a driver cancellation of ride
+			eventData := tt.eventData
+			if eventData == nil {
+				eventData, _ = json.Marshal(models.RideCancelled{
+					Ride: ride,
+					Cancellation: models.RideCancellation{
+						RideID:      ride.RideID,
+						CancelledBy: ride.DriverID,
+						Role:        models.CancelledByDriver,
+						Fee:         5000,
+					},
+				})
+			}
+
+			mockMatchUC := mocks.NewMockMatchUC(ctrl)
+			tt.setupMock(mockMatchUC, ride)
+
+			mockNATSClient := &natspkg.Client{}
+			mockNRApp := &newrelic.Application{}
+			handler := NewMatchHandler(mockMatchUC, mockNATSClient, mockNRApp)
+
+			// Act
+			err := handler.handleRideCancelled(context.Background(), eventData)
+
+			// Assert
+			if tt.expectError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMatchHandler_handleRidePickup_Redelivery(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisClient := &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}
+
+	// Like the location service, removing users already dropped from the pool succeeds again
+	removals := make(map[string]int)
+	var mu sync.Mutex
+	location := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		removals[r.URL.Path]++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer location.Close()
+
+	cfg := &models.Config{}
+	matchRepo := repository.NewMatchRepository(cfg, nil, redisClient)
+	matchGW := gateway.NewMatchGW(nil, location.URL, &models.APIKeyConfig{}, nil, nil)
+	handler := NewMatchHandler(usecase.NewMatchUC(cfg, matchRepo, matchGW), &natspkg.Client{}, nil)
+
+	var logs bytes.Buffer
+	previous := logger.GetGlobalLogger()
+	logger.SetGlobalLogger(slog.New(slog.NewJSONHandler(&logs, nil)))
+	defer logger.SetGlobalLogger(previous)
+
+	ridePickup := models.RideResp{
+		RideID:      uuid.New().String(),
+		DriverID:    uuid.New().String(),
+		PassengerID: uuid.New().String(),
+	}
+	data, _ := json.Marshal(ridePickup)
+
+	// JetStream delivers at least once, so the same pickup can arrive twice
+	assert.NoError(t, handler.handleRidePickup(context.Background(), data))
+	assert.NoError(t, handler.handleRidePickup(context.Background(), data))
+
+	assert.NotContains(t, logs.String(), `"level":"WARN"`)
+	assert.NotContains(t, logs.String(), `"level":"ERROR"`)
+
+	rideID, err := matchRepo.GetActiveRideByDriver(context.Background(), ridePickup.DriverID)
+	assert.NoError(t, err)
+	assert.Equal(t, ridePickup.RideID, rideID)
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, 2, removals["/internal/drivers/"+ridePickup.DriverID+"/available"])
+	assert.Equal(t, 2, removals["/internal/passengers/"+ridePickup.PassengerID+"/available"])
+}
+
+func TestMatchHandler_handleLocationUpdate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	update := models.LocationUpdate{
+		RideID:   uuid.New().String(),
+		DriverID: uuid.New().String(),
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153},
+	}
+	eventData, _ := json.Marshal(update)
+
+	mockMatchUC := mocks.NewMockMatchUC(ctrl)
+	mockMatchUC.EXPECT().
+		HandleDriverLocationUpdate(gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, got models.LocationUpdate) {
+			assert.Equal(t, update.DriverID, got.DriverID)
+			assert.Equal(t, update.Location.Latitude, got.Location.Latitude)
+		})
+
+	handler := NewMatchHandler(mockMatchUC, &natspkg.Client{}, &newrelic.Application{})
+
+	assert.NoError(t, handler.handleLocationUpdate(context.Background(), eventData))
+	assert.Error(t, handler.handleLocationUpdate(context.Background(), []byte("invalid json")))
+}
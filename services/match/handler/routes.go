@@ -36,6 +36,25 @@ func (h *Handler) RegisterRoutes(e *echo.Echo, Middleware *middleware.Middleware
 	// Internal match endpoints
 	internalMatchGroup := internal.Group("/matches")
 	internalMatchGroup.POST("/:matchID/confirm", h.matchHTTP.ConfirmMatch)
+
+	// Internal driver endpoints
+	internalDriverGroup := internal.Group("/drivers")
+	internalDriverGroup.GET("/:driverID/matches", h.matchHTTP.GetDriverMatches)
+	internalDriverGroup.GET("/:driverID/cancellation-stats", h.matchHTTP.GetDriverCancellationStats)
+
+	// Internal user endpoints
+	internalUserGroup := internal.Group("/users")
+	internalUserGroup.GET("/:userID/active-ride", h.matchHTTP.GetUserActiveRide)
+
+	// Admin routes for pausing matching during deploys and incidents (admin API key required)
+	admin := e.Group("/admin", Middleware.APIKeyHandler("admin"))
+	admin.GET("/maintenance", h.matchHTTP.GetMaintenanceMode)
+	admin.PUT("/maintenance", h.matchHTTP.SetMaintenanceMode)
+	admin.GET("/rejection-reasons", h.matchHTTP.GetRejectionReasonStats)
+
+	// Debug routes for inspecting live matching state (admin API key required)
+	debug := e.Group("/debug", Middleware.APIKeyHandler("admin"))
+	debug.GET("/match/:matchID", h.matchHTTP.GetMatchDebug)
 }
 
 // InitNATSConsumers initializes all NATS consumers
@@ -108,6 +108,20 @@ func (mr *MockMatchGWMockRecorder) GetPassengerLocation(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPassengerLocation", reflect.TypeOf((*MockMatchGW)(nil).GetPassengerLocation), arg0, arg1)
 }
 
+// PublishDriverAcceptances mocks base method.
+func (m *MockMatchGW) PublishDriverAcceptances(arg0 context.Context, arg1 models.DriverAcceptancesEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishDriverAcceptances", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishDriverAcceptances indicates an expected call of PublishDriverAcceptances.
+func (mr *MockMatchGWMockRecorder) PublishDriverAcceptances(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishDriverAcceptances", reflect.TypeOf((*MockMatchGW)(nil).PublishDriverAcceptances), arg0, arg1)
+}
+
 // PublishMatchAccepted mocks base method.
 func (m *MockMatchGW) PublishMatchAccepted(arg0 context.Context, arg1 models.MatchProposal) error {
 	m.ctrl.T.Helper()
@@ -136,6 +150,20 @@ func (mr *MockMatchGWMockRecorder) PublishMatchFound(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishMatchFound", reflect.TypeOf((*MockMatchGW)(nil).PublishMatchFound), arg0, arg1)
 }
 
+// PublishMatchMaintenance mocks base method.
+func (m *MockMatchGW) PublishMatchMaintenance(arg0 context.Context, arg1 models.MatchMaintenanceEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishMatchMaintenance", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishMatchMaintenance indicates an expected call of PublishMatchMaintenance.
+func (mr *MockMatchGWMockRecorder) PublishMatchMaintenance(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishMatchMaintenance", reflect.TypeOf((*MockMatchGW)(nil).PublishMatchMaintenance), arg0, arg1)
+}
+
 // PublishMatchRejected mocks base method.
 func (m *MockMatchGW) PublishMatchRejected(arg0 context.Context, arg1 models.MatchProposal) error {
 	m.ctrl.T.Helper()
@@ -150,6 +178,34 @@ func (mr *MockMatchGWMockRecorder) PublishMatchRejected(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishMatchRejected", reflect.TypeOf((*MockMatchGW)(nil).PublishMatchRejected), arg0, arg1)
 }
 
+// PublishNoDriversFound mocks base method.
+func (m *MockMatchGW) PublishNoDriversFound(arg0 context.Context, arg1 models.NoDriversFoundEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishNoDriversFound", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishNoDriversFound indicates an expected call of PublishNoDriversFound.
+func (mr *MockMatchGWMockRecorder) PublishNoDriversFound(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishNoDriversFound", reflect.TypeOf((*MockMatchGW)(nil).PublishNoDriversFound), arg0, arg1)
+}
+
+// PublishPendingProposals mocks base method.
+func (m *MockMatchGW) PublishPendingProposals(arg0 context.Context, arg1 models.PendingProposalsEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPendingProposals", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishPendingProposals indicates an expected call of PublishPendingProposals.
+func (mr *MockMatchGWMockRecorder) PublishPendingProposals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPendingProposals", reflect.TypeOf((*MockMatchGW)(nil).PublishPendingProposals), arg0, arg1)
+}
+
 // RemoveAvailableDriver mocks base method.
 func (m *MockMatchGW) RemoveAvailableDriver(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
@@ -36,18 +37,179 @@ func (m *MockMatchRepo) EXPECT() *MockMatchRepoMockRecorder {
 	return m.recorder
 }
 
+// AcquireRideLock mocks base method.
+func (m *MockMatchRepo) AcquireRideLock(arg0 context.Context, arg1 string, arg2 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AcquireRideLock", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AcquireRideLock indicates an expected call of AcquireRideLock.
+func (mr *MockMatchRepoMockRecorder) AcquireRideLock(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireRideLock", reflect.TypeOf((*MockMatchRepo)(nil).AcquireRideLock), arg0, arg1, arg2)
+}
+
 // BatchUpdateMatchStatus mocks base method.
-func (m *MockMatchRepo) BatchUpdateMatchStatus(arg0 context.Context, arg1 []string, arg2 models.MatchStatus) error {
+func (m *MockMatchRepo) BatchUpdateMatchStatus(arg0 context.Context, arg1 []string, arg2 models.MatchStatus, arg3 []models.MatchStatus) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BatchUpdateMatchStatus", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BatchUpdateMatchStatus indicates an expected call of BatchUpdateMatchStatus.
+func (mr *MockMatchRepoMockRecorder) BatchUpdateMatchStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpdateMatchStatus", reflect.TypeOf((*MockMatchRepo)(nil).BatchUpdateMatchStatus), arg0, arg1, arg2, arg3)
+}
+
+// CancelScheduledFinderEvent mocks base method.
+func (m *MockMatchRepo) CancelScheduledFinderEvent(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "BatchUpdateMatchStatus", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "CancelScheduledFinderEvent", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// BatchUpdateMatchStatus indicates an expected call of BatchUpdateMatchStatus.
-func (mr *MockMatchRepoMockRecorder) BatchUpdateMatchStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+// CancelScheduledFinderEvent indicates an expected call of CancelScheduledFinderEvent.
+func (mr *MockMatchRepoMockRecorder) CancelScheduledFinderEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledFinderEvent", reflect.TypeOf((*MockMatchRepo)(nil).CancelScheduledFinderEvent), arg0, arg1)
+}
+
+// ClaimDueAcceptanceWindows mocks base method.
+func (m *MockMatchRepo) ClaimDueAcceptanceWindows(arg0 context.Context, arg1 time.Time, arg2 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueAcceptanceWindows", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueAcceptanceWindows indicates an expected call of ClaimDueAcceptanceWindows.
+func (mr *MockMatchRepoMockRecorder) ClaimDueAcceptanceWindows(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueAcceptanceWindows", reflect.TypeOf((*MockMatchRepo)(nil).ClaimDueAcceptanceWindows), arg0, arg1, arg2)
+}
+
+// ClaimDueProposalRefreshes mocks base method.
+func (m *MockMatchRepo) ClaimDueProposalRefreshes(arg0 context.Context, arg1 time.Time, arg2 int) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueProposalRefreshes", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueProposalRefreshes indicates an expected call of ClaimDueProposalRefreshes.
+func (mr *MockMatchRepoMockRecorder) ClaimDueProposalRefreshes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueProposalRefreshes", reflect.TypeOf((*MockMatchRepo)(nil).ClaimDueProposalRefreshes), arg0, arg1, arg2)
+}
+
+// ClaimDueScheduledFinderEvents mocks base method.
+func (m *MockMatchRepo) ClaimDueScheduledFinderEvents(arg0 context.Context, arg1 time.Time, arg2 int) ([]models.FinderEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimDueScheduledFinderEvents", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]models.FinderEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimDueScheduledFinderEvents indicates an expected call of ClaimDueScheduledFinderEvents.
+func (mr *MockMatchRepoMockRecorder) ClaimDueScheduledFinderEvents(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimDueScheduledFinderEvents", reflect.TypeOf((*MockMatchRepo)(nil).ClaimDueScheduledFinderEvents), arg0, arg1, arg2)
+}
+
+// ClaimMatchProposal mocks base method.
+func (m *MockMatchRepo) ClaimMatchProposal(arg0 context.Context, arg1, arg2 string, arg3 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimMatchProposal", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimMatchProposal indicates an expected call of ClaimMatchProposal.
+func (mr *MockMatchRepoMockRecorder) ClaimMatchProposal(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimMatchProposal", reflect.TypeOf((*MockMatchRepo)(nil).ClaimMatchProposal), arg0, arg1, arg2, arg3)
+}
+
+// ReleaseMatchProposal mocks base method.
+func (m *MockMatchRepo) ReleaseMatchProposal(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseMatchProposal", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseMatchProposal indicates an expected call of ReleaseMatchProposal.
+func (mr *MockMatchRepoMockRecorder) ReleaseMatchProposal(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BatchUpdateMatchStatus", reflect.TypeOf((*MockMatchRepo)(nil).BatchUpdateMatchStatus), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseMatchProposal", reflect.TypeOf((*MockMatchRepo)(nil).ReleaseMatchProposal), arg0, arg1, arg2)
+}
+
+// ClaimProposalRefresh mocks base method.
+func (m *MockMatchRepo) ClaimProposalRefresh(arg0 context.Context, arg1 string, arg2 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimProposalRefresh", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimProposalRefresh indicates an expected call of ClaimProposalRefresh.
+func (mr *MockMatchRepoMockRecorder) ClaimProposalRefresh(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimProposalRefresh", reflect.TypeOf((*MockMatchRepo)(nil).ClaimProposalRefresh), arg0, arg1, arg2)
+}
+
+// ClearDriverFinishingRide mocks base method.
+func (m *MockMatchRepo) ClearDriverFinishingRide(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearDriverFinishingRide", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearDriverFinishingRide indicates an expected call of ClearDriverFinishingRide.
+func (mr *MockMatchRepoMockRecorder) ClearDriverFinishingRide(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearDriverFinishingRide", reflect.TypeOf((*MockMatchRepo)(nil).ClearDriverFinishingRide), arg0, arg1)
+}
+
+// CompleteAutoRejection mocks base method.
+func (m *MockMatchRepo) CompleteAutoRejection(arg0 context.Context, arg1 models.AutoRejection) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteAutoRejection", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompleteAutoRejection indicates an expected call of CompleteAutoRejection.
+func (mr *MockMatchRepoMockRecorder) CompleteAutoRejection(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteAutoRejection", reflect.TypeOf((*MockMatchRepo)(nil).CompleteAutoRejection), arg0, arg1)
+}
+
+// CompletePoolRemoval mocks base method.
+func (m *MockMatchRepo) CompletePoolRemoval(arg0 context.Context, arg1 models.PoolRemoval) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompletePoolRemoval", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CompletePoolRemoval indicates an expected call of CompletePoolRemoval.
+func (mr *MockMatchRepoMockRecorder) CompletePoolRemoval(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompletePoolRemoval", reflect.TypeOf((*MockMatchRepo)(nil).CompletePoolRemoval), arg0, arg1)
 }
 
 // ConfirmMatchByUser mocks base method.
@@ -65,6 +227,52 @@ func (mr *MockMatchRepoMockRecorder) ConfirmMatchByUser(arg0, arg1, arg2, arg3 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMatchByUser", reflect.TypeOf((*MockMatchRepo)(nil).ConfirmMatchByUser), arg0, arg1, arg2, arg3)
 }
 
+// CountDriverOutcomes mocks base method.
+func (m *MockMatchRepo) CountDriverOutcomes(arg0 context.Context, arg1 string, arg2 time.Time) (int, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDriverOutcomes", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// CountDriverOutcomes indicates an expected call of CountDriverOutcomes.
+func (mr *MockMatchRepoMockRecorder) CountDriverOutcomes(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDriverOutcomes", reflect.TypeOf((*MockMatchRepo)(nil).CountDriverOutcomes), arg0, arg1, arg2)
+}
+
+// CountPendingMatchesByPassenger mocks base method.
+func (m *MockMatchRepo) CountPendingMatchesByPassenger(arg0 context.Context, arg1 uuid.UUID) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountPendingMatchesByPassenger", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountPendingMatchesByPassenger indicates an expected call of CountPendingMatchesByPassenger.
+func (mr *MockMatchRepoMockRecorder) CountPendingMatchesByPassenger(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountPendingMatchesByPassenger", reflect.TypeOf((*MockMatchRepo)(nil).CountPendingMatchesByPassenger), arg0, arg1)
+}
+
+// CountRejectionReasons mocks base method.
+func (m *MockMatchRepo) CountRejectionReasons(arg0 context.Context, arg1 time.Time) (map[models.MatchRejectionReason]int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountRejectionReasons", arg0, arg1)
+	ret0, _ := ret[0].(map[models.MatchRejectionReason]int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountRejectionReasons indicates an expected call of CountRejectionReasons.
+func (mr *MockMatchRepoMockRecorder) CountRejectionReasons(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountRejectionReasons", reflect.TypeOf((*MockMatchRepo)(nil).CountRejectionReasons), arg0, arg1)
+}
+
 // CreateMatch mocks base method.
 func (m *MockMatchRepo) CreateMatch(arg0 context.Context, arg1 *models.Match) (*models.Match, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +288,20 @@ func (mr *MockMatchRepoMockRecorder) CreateMatch(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateMatch", reflect.TypeOf((*MockMatchRepo)(nil).CreateMatch), arg0, arg1)
 }
 
+// DropAutoRejectionsBefore mocks base method.
+func (m *MockMatchRepo) DropAutoRejectionsBefore(arg0 context.Context, arg1 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DropAutoRejectionsBefore", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DropAutoRejectionsBefore indicates an expected call of DropAutoRejectionsBefore.
+func (mr *MockMatchRepoMockRecorder) DropAutoRejectionsBefore(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DropAutoRejectionsBefore", reflect.TypeOf((*MockMatchRepo)(nil).DropAutoRejectionsBefore), arg0, arg1)
+}
+
 // GetActiveRideByDriver mocks base method.
 func (m *MockMatchRepo) GetActiveRideByDriver(arg0 context.Context, arg1 string) (string, error) {
 	m.ctrl.T.Helper()
@@ -110,6 +332,81 @@ func (mr *MockMatchRepoMockRecorder) GetActiveRideByPassenger(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRideByPassenger", reflect.TypeOf((*MockMatchRepo)(nil).GetActiveRideByPassenger), arg0, arg1)
 }
 
+// GetDriverFinishingRide mocks base method.
+func (m *MockMatchRepo) GetDriverFinishingRide(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverFinishingRide", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverFinishingRide indicates an expected call of GetDriverFinishingRide.
+func (mr *MockMatchRepoMockRecorder) GetDriverFinishingRide(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverFinishingRide", reflect.TypeOf((*MockMatchRepo)(nil).GetDriverFinishingRide), arg0, arg1)
+}
+
+// GetDriverGenders mocks base method.
+func (m *MockMatchRepo) GetDriverGenders(arg0 context.Context, arg1 []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverGenders", arg0, arg1)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverGenders indicates an expected call of GetDriverGenders.
+func (mr *MockMatchRepoMockRecorder) GetDriverGenders(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverGenders", reflect.TypeOf((*MockMatchRepo)(nil).GetDriverGenders), arg0, arg1)
+}
+
+// GetDriverSuspension mocks base method.
+func (m *MockMatchRepo) GetDriverSuspension(arg0 context.Context, arg1 string) (time.Duration, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverSuspension", arg0, arg1)
+	ret0, _ := ret[0].(time.Duration)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverSuspension indicates an expected call of GetDriverSuspension.
+func (mr *MockMatchRepoMockRecorder) GetDriverSuspension(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverSuspension", reflect.TypeOf((*MockMatchRepo)(nil).GetDriverSuspension), arg0, arg1)
+}
+
+// GetDriversLastRideAt mocks base method.
+func (m *MockMatchRepo) GetDriversLastRideAt(arg0 context.Context, arg1 []string) (map[string]time.Time, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriversLastRideAt", arg0, arg1)
+	ret0, _ := ret[0].(map[string]time.Time)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriversLastRideAt indicates an expected call of GetDriversLastRideAt.
+func (mr *MockMatchRepoMockRecorder) GetDriversLastRideAt(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriversLastRideAt", reflect.TypeOf((*MockMatchRepo)(nil).GetDriversLastRideAt), arg0, arg1)
+}
+
+// GetMaintenanceMode mocks base method.
+func (m *MockMatchRepo) GetMaintenanceMode(arg0 context.Context) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMaintenanceMode", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaintenanceMode indicates an expected call of GetMaintenanceMode.
+func (mr *MockMatchRepoMockRecorder) GetMaintenanceMode(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenanceMode", reflect.TypeOf((*MockMatchRepo)(nil).GetMaintenanceMode), arg0)
+}
+
 // GetMatch mocks base method.
 func (m *MockMatchRepo) GetMatch(arg0 context.Context, arg1 string) (*models.Match, error) {
 	m.ctrl.T.Helper()
@@ -125,19 +422,236 @@ func (mr *MockMatchRepoMockRecorder) GetMatch(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatch", reflect.TypeOf((*MockMatchRepo)(nil).GetMatch), arg0, arg1)
 }
 
-// ListMatchesByPassenger mocks base method.
-func (m *MockMatchRepo) ListMatchesByPassenger(arg0 context.Context, arg1 uuid.UUID) ([]*models.Match, error) {
+// IsInMatchingPool mocks base method.
+func (m *MockMatchRepo) IsInMatchingPool(arg0 context.Context, arg1 string, arg2 bool) (bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "ListMatchesByPassenger", arg0, arg1)
+	ret := m.ctrl.Call(m, "IsInMatchingPool", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsInMatchingPool indicates an expected call of IsInMatchingPool.
+func (mr *MockMatchRepoMockRecorder) IsInMatchingPool(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsInMatchingPool", reflect.TypeOf((*MockMatchRepo)(nil).IsInMatchingPool), arg0, arg1, arg2)
+}
+
+// IsRideLocked mocks base method.
+func (m *MockMatchRepo) IsRideLocked(arg0 context.Context, arg1 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRideLocked", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRideLocked indicates an expected call of IsRideLocked.
+func (mr *MockMatchRepoMockRecorder) IsRideLocked(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRideLocked", reflect.TypeOf((*MockMatchRepo)(nil).IsRideLocked), arg0, arg1)
+}
+
+// ListAutoRejections mocks base method.
+func (m *MockMatchRepo) ListAutoRejections(arg0 context.Context, arg1 int) ([]models.AutoRejection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAutoRejections", arg0, arg1)
+	ret0, _ := ret[0].([]models.AutoRejection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAutoRejections indicates an expected call of ListAutoRejections.
+func (mr *MockMatchRepoMockRecorder) ListAutoRejections(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAutoRejections", reflect.TypeOf((*MockMatchRepo)(nil).ListAutoRejections), arg0, arg1)
+}
+
+// ListExpiredProposals mocks base method.
+func (m *MockMatchRepo) ListExpiredProposals(arg0 context.Context, arg1 time.Time, arg2 int) ([]*models.Match, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListExpiredProposals", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]*models.Match)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// ListMatchesByPassenger indicates an expected call of ListMatchesByPassenger.
-func (mr *MockMatchRepoMockRecorder) ListMatchesByPassenger(arg0, arg1 interface{}) *gomock.Call {
+// ListExpiredProposals indicates an expected call of ListExpiredProposals.
+func (mr *MockMatchRepoMockRecorder) ListExpiredProposals(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListExpiredProposals", reflect.TypeOf((*MockMatchRepo)(nil).ListExpiredProposals), arg0, arg1, arg2)
+}
+
+// ListMatchesByDriver mocks base method.
+func (m *MockMatchRepo) ListMatchesByDriver(arg0 context.Context, arg1 uuid.UUID, arg2, arg3 int) ([]*models.Match, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListMatchesByDriver", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*models.Match)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListMatchesByDriver indicates an expected call of ListMatchesByDriver.
+func (mr *MockMatchRepoMockRecorder) ListMatchesByDriver(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMatchesByDriver", reflect.TypeOf((*MockMatchRepo)(nil).ListMatchesByDriver), arg0, arg1, arg2, arg3)
+}
+
+// ListOpenMatchesByPassenger mocks base method.
+func (m *MockMatchRepo) ListOpenMatchesByPassenger(arg0 context.Context, arg1 uuid.UUID) ([]*models.Match, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOpenMatchesByPassenger", arg0, arg1)
+	ret0, _ := ret[0].([]*models.Match)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOpenMatchesByPassenger indicates an expected call of ListOpenMatchesByPassenger.
+func (mr *MockMatchRepoMockRecorder) ListOpenMatchesByPassenger(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOpenMatchesByPassenger", reflect.TypeOf((*MockMatchRepo)(nil).ListOpenMatchesByPassenger), arg0, arg1)
+}
+
+// ListPoolRemovals mocks base method.
+func (m *MockMatchRepo) ListPoolRemovals(arg0 context.Context, arg1 int) ([]models.PoolRemoval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListPoolRemovals", arg0, arg1)
+	ret0, _ := ret[0].([]models.PoolRemoval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListPoolRemovals indicates an expected call of ListPoolRemovals.
+func (mr *MockMatchRepoMockRecorder) ListPoolRemovals(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListPoolRemovals", reflect.TypeOf((*MockMatchRepo)(nil).ListPoolRemovals), arg0, arg1)
+}
+
+// MarkDriverFinishingRide mocks base method.
+func (m *MockMatchRepo) MarkDriverFinishingRide(arg0 context.Context, arg1, arg2 string, arg3 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDriverFinishingRide", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDriverFinishingRide indicates an expected call of MarkDriverFinishingRide.
+func (mr *MockMatchRepoMockRecorder) MarkDriverFinishingRide(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDriverFinishingRide", reflect.TypeOf((*MockMatchRepo)(nil).MarkDriverFinishingRide), arg0, arg1, arg2, arg3)
+}
+
+// OpenAcceptanceWindow mocks base method.
+func (m *MockMatchRepo) OpenAcceptanceWindow(arg0 context.Context, arg1 string, arg2 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "OpenAcceptanceWindow", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// OpenAcceptanceWindow indicates an expected call of OpenAcceptanceWindow.
+func (mr *MockMatchRepoMockRecorder) OpenAcceptanceWindow(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "OpenAcceptanceWindow", reflect.TypeOf((*MockMatchRepo)(nil).OpenAcceptanceWindow), arg0, arg1, arg2)
+}
+
+// QueueAutoRejection mocks base method.
+func (m *MockMatchRepo) QueueAutoRejection(arg0 context.Context, arg1 models.AutoRejection) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueueAutoRejection", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QueueAutoRejection indicates an expected call of QueueAutoRejection.
+func (mr *MockMatchRepoMockRecorder) QueueAutoRejection(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueueAutoRejection", reflect.TypeOf((*MockMatchRepo)(nil).QueueAutoRejection), arg0, arg1)
+}
+
+// QueuePoolRemoval mocks base method.
+func (m *MockMatchRepo) QueuePoolRemoval(arg0 context.Context, arg1 models.PoolRemoval) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QueuePoolRemoval", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// QueuePoolRemoval indicates an expected call of QueuePoolRemoval.
+func (mr *MockMatchRepoMockRecorder) QueuePoolRemoval(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QueuePoolRemoval", reflect.TypeOf((*MockMatchRepo)(nil).QueuePoolRemoval), arg0, arg1)
+}
+
+// RecordDriverAccepted mocks base method.
+func (m *MockMatchRepo) RecordDriverAccepted(arg0 context.Context, arg1, arg2 string, arg3 time.Time, arg4 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDriverAccepted", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDriverAccepted indicates an expected call of RecordDriverAccepted.
+func (mr *MockMatchRepoMockRecorder) RecordDriverAccepted(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDriverAccepted", reflect.TypeOf((*MockMatchRepo)(nil).RecordDriverAccepted), arg0, arg1, arg2, arg3, arg4)
+}
+
+// RecordDriverCancelled mocks base method.
+func (m *MockMatchRepo) RecordDriverCancelled(arg0 context.Context, arg1, arg2 string, arg3 time.Time, arg4 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDriverCancelled", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDriverCancelled indicates an expected call of RecordDriverCancelled.
+func (mr *MockMatchRepoMockRecorder) RecordDriverCancelled(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDriverCancelled", reflect.TypeOf((*MockMatchRepo)(nil).RecordDriverCancelled), arg0, arg1, arg2, arg3, arg4)
+}
+
+// RecordDriverRideCompleted mocks base method.
+func (m *MockMatchRepo) RecordDriverRideCompleted(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDriverRideCompleted", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDriverRideCompleted indicates an expected call of RecordDriverRideCompleted.
+func (mr *MockMatchRepoMockRecorder) RecordDriverRideCompleted(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListMatchesByPassenger", reflect.TypeOf((*MockMatchRepo)(nil).ListMatchesByPassenger), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDriverRideCompleted", reflect.TypeOf((*MockMatchRepo)(nil).RecordDriverRideCompleted), arg0, arg1, arg2)
+}
+
+// RecordRejectionReason mocks base method.
+func (m *MockMatchRepo) RecordRejectionReason(arg0 context.Context, arg1 string, arg2 models.MatchRejectionReason) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordRejectionReason", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordRejectionReason indicates an expected call of RecordRejectionReason.
+func (mr *MockMatchRepoMockRecorder) RecordRejectionReason(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordRejectionReason", reflect.TypeOf((*MockMatchRepo)(nil).RecordRejectionReason), arg0, arg1, arg2)
+}
+
+// ReleaseRideLock mocks base method.
+func (m *MockMatchRepo) ReleaseRideLock(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRideLock", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRideLock indicates an expected call of ReleaseRideLock.
+func (mr *MockMatchRepoMockRecorder) ReleaseRideLock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRideLock", reflect.TypeOf((*MockMatchRepo)(nil).ReleaseRideLock), arg0, arg1)
 }
 
 // RemoveActiveRide mocks base method.
@@ -154,6 +668,34 @@ func (mr *MockMatchRepoMockRecorder) RemoveActiveRide(arg0, arg1, arg2 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveActiveRide", reflect.TypeOf((*MockMatchRepo)(nil).RemoveActiveRide), arg0, arg1, arg2)
 }
 
+// ScheduleFinderEvent mocks base method.
+func (m *MockMatchRepo) ScheduleFinderEvent(arg0 context.Context, arg1 models.FinderEvent, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleFinderEvent", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleFinderEvent indicates an expected call of ScheduleFinderEvent.
+func (mr *MockMatchRepoMockRecorder) ScheduleFinderEvent(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleFinderEvent", reflect.TypeOf((*MockMatchRepo)(nil).ScheduleFinderEvent), arg0, arg1, arg2)
+}
+
+// ScheduleProposalRefresh mocks base method.
+func (m *MockMatchRepo) ScheduleProposalRefresh(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ScheduleProposalRefresh", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ScheduleProposalRefresh indicates an expected call of ScheduleProposalRefresh.
+func (mr *MockMatchRepoMockRecorder) ScheduleProposalRefresh(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ScheduleProposalRefresh", reflect.TypeOf((*MockMatchRepo)(nil).ScheduleProposalRefresh), arg0, arg1, arg2)
+}
+
 // SetActiveRide mocks base method.
 func (m *MockMatchRepo) SetActiveRide(arg0 context.Context, arg1, arg2, arg3 string) error {
 	m.ctrl.T.Helper()
@@ -168,6 +710,48 @@ func (mr *MockMatchRepoMockRecorder) SetActiveRide(arg0, arg1, arg2, arg3 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActiveRide", reflect.TypeOf((*MockMatchRepo)(nil).SetActiveRide), arg0, arg1, arg2, arg3)
 }
 
+// SetDriverGender mocks base method.
+func (m *MockMatchRepo) SetDriverGender(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetDriverGender", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetDriverGender indicates an expected call of SetDriverGender.
+func (mr *MockMatchRepoMockRecorder) SetDriverGender(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetDriverGender", reflect.TypeOf((*MockMatchRepo)(nil).SetDriverGender), arg0, arg1, arg2)
+}
+
+// SetMaintenanceMode mocks base method.
+func (m *MockMatchRepo) SetMaintenanceMode(arg0 context.Context, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMaintenanceMode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMaintenanceMode indicates an expected call of SetMaintenanceMode.
+func (mr *MockMatchRepoMockRecorder) SetMaintenanceMode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaintenanceMode", reflect.TypeOf((*MockMatchRepo)(nil).SetMaintenanceMode), arg0, arg1)
+}
+
+// SuspendDriver mocks base method.
+func (m *MockMatchRepo) SuspendDriver(arg0 context.Context, arg1 string, arg2 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SuspendDriver", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SuspendDriver indicates an expected call of SuspendDriver.
+func (mr *MockMatchRepoMockRecorder) SuspendDriver(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SuspendDriver", reflect.TypeOf((*MockMatchRepo)(nil).SuspendDriver), arg0, arg1, arg2)
+}
+
 // UpdateMatchStatus mocks base method.
 func (m *MockMatchRepo) UpdateMatchStatus(arg0 context.Context, arg1 string, arg2 models.MatchStatus) error {
 	m.ctrl.T.Helper()
@@ -181,3 +765,33 @@ func (mr *MockMatchRepoMockRecorder) UpdateMatchStatus(arg0, arg1, arg2 interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateMatchStatus", reflect.TypeOf((*MockMatchRepo)(nil).UpdateMatchStatus), arg0, arg1, arg2)
 }
+
+// UpdatePendingMatchesDriverLocation mocks base method.
+func (m *MockMatchRepo) UpdatePendingMatchesDriverLocation(arg0 context.Context, arg1 uuid.UUID, arg2 models.Location) ([]*models.Match, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePendingMatchesDriverLocation", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Match)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePendingMatchesDriverLocation indicates an expected call of UpdatePendingMatchesDriverLocation.
+func (mr *MockMatchRepoMockRecorder) UpdatePendingMatchesDriverLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingMatchesDriverLocation", reflect.TypeOf((*MockMatchRepo)(nil).UpdatePendingMatchesDriverLocation), arg0, arg1, arg2)
+}
+
+// UpdatePendingMatchesPassengerLocation mocks base method.
+func (m *MockMatchRepo) UpdatePendingMatchesPassengerLocation(arg0 context.Context, arg1 uuid.UUID, arg2 models.Location) ([]*models.Match, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePendingMatchesPassengerLocation", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Match)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdatePendingMatchesPassengerLocation indicates an expected call of UpdatePendingMatchesPassengerLocation.
+func (mr *MockMatchRepoMockRecorder) UpdatePendingMatchesPassengerLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePendingMatchesPassengerLocation", reflect.TypeOf((*MockMatchRepo)(nil).UpdatePendingMatchesPassengerLocation), arg0, arg1, arg2)
+}
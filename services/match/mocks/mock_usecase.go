@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/piresc/nebengjek/internal/pkg/models"
@@ -35,6 +36,20 @@ func (m *MockMatchUC) EXPECT() *MockMatchUCMockRecorder {
 	return m.recorder
 }
 
+// ClearBackToBackOptIn mocks base method.
+func (m *MockMatchUC) ClearBackToBackOptIn(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClearBackToBackOptIn", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ClearBackToBackOptIn indicates an expected call of ClearBackToBackOptIn.
+func (mr *MockMatchUCMockRecorder) ClearBackToBackOptIn(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClearBackToBackOptIn", reflect.TypeOf((*MockMatchUC)(nil).ClearBackToBackOptIn), arg0, arg1, arg2)
+}
+
 // ConfirmMatchStatus mocks base method.
 func (m *MockMatchUC) ConfirmMatchStatus(arg0 context.Context, arg1 *models.MatchConfirmRequest) (models.MatchProposal, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +65,81 @@ func (mr *MockMatchUCMockRecorder) ConfirmMatchStatus(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMatchStatus", reflect.TypeOf((*MockMatchUC)(nil).ConfirmMatchStatus), arg0, arg1)
 }
 
+// DriverAwaitsNextRide mocks base method.
+func (m *MockMatchUC) DriverAwaitsNextRide(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DriverAwaitsNextRide", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DriverAwaitsNextRide indicates an expected call of DriverAwaitsNextRide.
+func (mr *MockMatchUCMockRecorder) DriverAwaitsNextRide(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DriverAwaitsNextRide", reflect.TypeOf((*MockMatchUC)(nil).DriverAwaitsNextRide), arg0, arg1, arg2)
+}
+
+// DriverHasNextRide mocks base method.
+func (m *MockMatchUC) DriverHasNextRide(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DriverHasNextRide", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DriverHasNextRide indicates an expected call of DriverHasNextRide.
+func (mr *MockMatchUCMockRecorder) DriverHasNextRide(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DriverHasNextRide", reflect.TypeOf((*MockMatchUC)(nil).DriverHasNextRide), arg0, arg1, arg2)
+}
+
+// GetActiveRideID mocks base method.
+func (m *MockMatchUC) GetActiveRideID(arg0 context.Context, arg1 string, arg2 bool) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveRideID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveRideID indicates an expected call of GetActiveRideID.
+func (mr *MockMatchUCMockRecorder) GetActiveRideID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRideID", reflect.TypeOf((*MockMatchUC)(nil).GetActiveRideID), arg0, arg1, arg2)
+}
+
+// GetDriverCancellationStats mocks base method.
+func (m *MockMatchUC) GetDriverCancellationStats(arg0 context.Context, arg1 string) (*models.DriverCancellationStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverCancellationStats", arg0, arg1)
+	ret0, _ := ret[0].(*models.DriverCancellationStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverCancellationStats indicates an expected call of GetDriverCancellationStats.
+func (mr *MockMatchUCMockRecorder) GetDriverCancellationStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverCancellationStats", reflect.TypeOf((*MockMatchUC)(nil).GetDriverCancellationStats), arg0, arg1)
+}
+
+// GetDriverMatches mocks base method.
+func (m *MockMatchUC) GetDriverMatches(arg0 context.Context, arg1 string, arg2, arg3 int) (*models.MatchHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverMatches", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.MatchHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverMatches indicates an expected call of GetDriverMatches.
+func (mr *MockMatchUCMockRecorder) GetDriverMatches(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverMatches", reflect.TypeOf((*MockMatchUC)(nil).GetDriverMatches), arg0, arg1, arg2, arg3)
+}
+
 // GetMatch mocks base method.
 func (m *MockMatchUC) GetMatch(arg0 context.Context, arg1 string) (*models.Match, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +155,21 @@ func (mr *MockMatchUCMockRecorder) GetMatch(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatch", reflect.TypeOf((*MockMatchUC)(nil).GetMatch), arg0, arg1)
 }
 
+// GetMatchDebug mocks base method.
+func (m *MockMatchUC) GetMatchDebug(arg0 context.Context, arg1 string) (*models.MatchDebugView, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetMatchDebug", arg0, arg1)
+	ret0, _ := ret[0].(*models.MatchDebugView)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMatchDebug indicates an expected call of GetMatchDebug.
+func (mr *MockMatchUCMockRecorder) GetMatchDebug(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMatchDebug", reflect.TypeOf((*MockMatchUC)(nil).GetMatchDebug), arg0, arg1)
+}
+
 // GetPendingMatch mocks base method.
 func (m *MockMatchUC) GetPendingMatch(arg0 context.Context, arg1 string) (*models.Match, error) {
 	m.ctrl.T.Helper()
@@ -80,6 +185,21 @@ func (mr *MockMatchUCMockRecorder) GetPendingMatch(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPendingMatch", reflect.TypeOf((*MockMatchUC)(nil).GetPendingMatch), arg0, arg1)
 }
 
+// GetRejectionReasonStats mocks base method.
+func (m *MockMatchUC) GetRejectionReasonStats(arg0 context.Context, arg1 time.Time) (*models.MatchRejectionStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRejectionReasonStats", arg0, arg1)
+	ret0, _ := ret[0].(*models.MatchRejectionStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRejectionReasonStats indicates an expected call of GetRejectionReasonStats.
+func (mr *MockMatchUCMockRecorder) GetRejectionReasonStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRejectionReasonStats", reflect.TypeOf((*MockMatchUC)(nil).GetRejectionReasonStats), arg0, arg1)
+}
+
 // HandleBeaconEvent mocks base method.
 func (m *MockMatchUC) HandleBeaconEvent(arg0 context.Context, arg1 models.BeaconEvent) error {
 	m.ctrl.T.Helper()
@@ -94,6 +214,32 @@ func (mr *MockMatchUCMockRecorder) HandleBeaconEvent(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleBeaconEvent", reflect.TypeOf((*MockMatchUC)(nil).HandleBeaconEvent), arg0, arg1)
 }
 
+// HandleDriverCancellation mocks base method.
+func (m *MockMatchUC) HandleDriverCancellation(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HandleDriverCancellation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// HandleDriverCancellation indicates an expected call of HandleDriverCancellation.
+func (mr *MockMatchUCMockRecorder) HandleDriverCancellation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleDriverCancellation", reflect.TypeOf((*MockMatchUC)(nil).HandleDriverCancellation), arg0, arg1, arg2)
+}
+
+// HandleDriverLocationUpdate mocks base method.
+func (m *MockMatchUC) HandleDriverLocationUpdate(arg0 context.Context, arg1 models.LocationUpdate) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "HandleDriverLocationUpdate", arg0, arg1)
+}
+
+// HandleDriverLocationUpdate indicates an expected call of HandleDriverLocationUpdate.
+func (mr *MockMatchUCMockRecorder) HandleDriverLocationUpdate(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HandleDriverLocationUpdate", reflect.TypeOf((*MockMatchUC)(nil).HandleDriverLocationUpdate), arg0, arg1)
+}
+
 // HandleFinderEvent mocks base method.
 func (m *MockMatchUC) HandleFinderEvent(arg0 context.Context, arg1 models.FinderEvent) error {
 	m.ctrl.T.Helper()
@@ -123,6 +269,104 @@ func (mr *MockMatchUCMockRecorder) HasActiveRide(arg0, arg1, arg2 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasActiveRide", reflect.TypeOf((*MockMatchUC)(nil).HasActiveRide), arg0, arg1, arg2)
 }
 
+// IsMaintenanceMode mocks base method.
+func (m *MockMatchUC) IsMaintenanceMode(arg0 context.Context) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsMaintenanceMode", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsMaintenanceMode indicates an expected call of IsMaintenanceMode.
+func (mr *MockMatchUCMockRecorder) IsMaintenanceMode(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsMaintenanceMode", reflect.TypeOf((*MockMatchUC)(nil).IsMaintenanceMode), arg0)
+}
+
+// LockUsersForRide mocks base method.
+func (m *MockMatchUC) LockUsersForRide(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockUsersForRide", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LockUsersForRide indicates an expected call of LockUsersForRide.
+func (mr *MockMatchUCMockRecorder) LockUsersForRide(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockUsersForRide", reflect.TypeOf((*MockMatchUC)(nil).LockUsersForRide), arg0, arg1, arg2)
+}
+
+// RecordDriverRideCompleted mocks base method.
+func (m *MockMatchUC) RecordDriverRideCompleted(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordDriverRideCompleted", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordDriverRideCompleted indicates an expected call of RecordDriverRideCompleted.
+func (mr *MockMatchUCMockRecorder) RecordDriverRideCompleted(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordDriverRideCompleted", reflect.TypeOf((*MockMatchUC)(nil).RecordDriverRideCompleted), arg0, arg1, arg2)
+}
+
+// RefreshDueProposals mocks base method.
+func (m *MockMatchUC) RefreshDueProposals(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshDueProposals", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RefreshDueProposals indicates an expected call of RefreshDueProposals.
+func (mr *MockMatchUCMockRecorder) RefreshDueProposals(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshDueProposals", reflect.TypeOf((*MockMatchUC)(nil).RefreshDueProposals), arg0, arg1, arg2)
+}
+
+// ReleaseDueScheduledFinders mocks base method.
+func (m *MockMatchUC) ReleaseDueScheduledFinders(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseDueScheduledFinders", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseDueScheduledFinders indicates an expected call of ReleaseDueScheduledFinders.
+func (mr *MockMatchUCMockRecorder) ReleaseDueScheduledFinders(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseDueScheduledFinders", reflect.TypeOf((*MockMatchUC)(nil).ReleaseDueScheduledFinders), arg0, arg1, arg2)
+}
+
+// ReleaseRideLocks mocks base method.
+func (m *MockMatchUC) ReleaseRideLocks(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseRideLocks", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ReleaseRideLocks indicates an expected call of ReleaseRideLocks.
+func (mr *MockMatchUCMockRecorder) ReleaseRideLocks(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseRideLocks", reflect.TypeOf((*MockMatchUC)(nil).ReleaseRideLocks), arg0, arg1, arg2)
+}
+
+// ReloadConfig mocks base method.
+func (m *MockMatchUC) ReloadConfig(arg0 *models.Config) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReloadConfig", arg0)
+}
+
+// ReloadConfig indicates an expected call of ReloadConfig.
+func (mr *MockMatchUCMockRecorder) ReloadConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadConfig", reflect.TypeOf((*MockMatchUC)(nil).ReloadConfig), arg0)
+}
+
 // RemoveActiveRide mocks base method.
 func (m *MockMatchUC) RemoveActiveRide(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -165,6 +409,30 @@ func (mr *MockMatchUCMockRecorder) RemovePassengerFromPool(arg0, arg1 interface{
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePassengerFromPool", reflect.TypeOf((*MockMatchUC)(nil).RemovePassengerFromPool), arg0, arg1)
 }
 
+// RunProposalRefreshes mocks base method.
+func (m *MockMatchUC) RunProposalRefreshes(arg0 context.Context, arg1 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunProposalRefreshes", arg0, arg1)
+}
+
+// RunProposalRefreshes indicates an expected call of RunProposalRefreshes.
+func (mr *MockMatchUCMockRecorder) RunProposalRefreshes(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunProposalRefreshes", reflect.TypeOf((*MockMatchUC)(nil).RunProposalRefreshes), arg0, arg1)
+}
+
+// RunScheduler mocks base method.
+func (m *MockMatchUC) RunScheduler(arg0 context.Context, arg1 time.Duration, arg2 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunScheduler", arg0, arg1, arg2)
+}
+
+// RunScheduler indicates an expected call of RunScheduler.
+func (mr *MockMatchUCMockRecorder) RunScheduler(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunScheduler", reflect.TypeOf((*MockMatchUC)(nil).RunScheduler), arg0, arg1, arg2)
+}
+
 // SetActiveRide mocks base method.
 func (m *MockMatchUC) SetActiveRide(arg0 context.Context, arg1, arg2, arg3 string) error {
 	m.ctrl.T.Helper()
@@ -178,3 +446,29 @@ func (mr *MockMatchUCMockRecorder) SetActiveRide(arg0, arg1, arg2, arg3 interfac
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetActiveRide", reflect.TypeOf((*MockMatchUC)(nil).SetActiveRide), arg0, arg1, arg2, arg3)
 }
+
+// SetMaintenanceMode mocks base method.
+func (m *MockMatchUC) SetMaintenanceMode(arg0 context.Context, arg1 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetMaintenanceMode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetMaintenanceMode indicates an expected call of SetMaintenanceMode.
+func (mr *MockMatchUCMockRecorder) SetMaintenanceMode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetMaintenanceMode", reflect.TypeOf((*MockMatchUC)(nil).SetMaintenanceMode), arg0, arg1)
+}
+
+// WatchMaintenanceMode mocks base method.
+func (m *MockMatchUC) WatchMaintenanceMode(arg0 context.Context, arg1 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "WatchMaintenanceMode", arg0, arg1)
+}
+
+// WatchMaintenanceMode indicates an expected call of WatchMaintenanceMode.
+func (mr *MockMatchUCMockRecorder) WatchMaintenanceMode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WatchMaintenanceMode", reflect.TypeOf((*MockMatchUC)(nil).WatchMaintenanceMode), arg0, arg1)
+}
@@ -2,6 +2,7 @@ package match
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/piresc/nebengjek/internal/pkg/models"
@@ -15,14 +16,80 @@ type MatchRepo interface {
 	CreateMatch(ctx context.Context, match *models.Match) (*models.Match, error)
 	GetMatch(ctx context.Context, matchID string) (*models.Match, error)
 	UpdateMatchStatus(ctx context.Context, matchID string, status models.MatchStatus) error
-	ListMatchesByPassenger(ctx context.Context, passengerID uuid.UUID) ([]*models.Match, error)
+	ListOpenMatchesByPassenger(ctx context.Context, passengerID uuid.UUID) ([]*models.Match, error)
+	ListMatchesByDriver(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]*models.Match, error)
 	ConfirmMatchByUser(ctx context.Context, matchID string, userID string, isDriver bool) (*models.Match, error)
+	UpdatePendingMatchesPassengerLocation(ctx context.Context, passengerID uuid.UUID, location models.Location) ([]*models.Match, error)
+	UpdatePendingMatchesDriverLocation(ctx context.Context, driverID uuid.UUID, location models.Location) ([]*models.Match, error)
+	CountPendingMatchesByPassenger(ctx context.Context, passengerID uuid.UUID) (int, error)
+	ListExpiredProposals(ctx context.Context, createdBefore time.Time, limit int) ([]*models.Match, error)
 
-	BatchUpdateMatchStatus(ctx context.Context, matchIDs []string, status models.MatchStatus) error
+	BatchUpdateMatchStatus(ctx context.Context, matchIDs []string, status models.MatchStatus, from []models.MatchStatus) ([]string, error)
+
+	// Driver rejection reasons
+	RecordRejectionReason(ctx context.Context, matchID string, reason models.MatchRejectionReason) error
+	CountRejectionReasons(ctx context.Context, since time.Time) (map[models.MatchRejectionReason]int, error)
 
 	// Active ride tracking operations
 	SetActiveRide(ctx context.Context, driverID, passengerID, rideID string) error
 	RemoveActiveRide(ctx context.Context, driverID, passengerID string) error
 	GetActiveRideByDriver(ctx context.Context, driverID string) (string, error)
 	GetActiveRideByPassenger(ctx context.Context, passengerID string) (string, error)
+
+	// Ride lock operations
+	AcquireRideLock(ctx context.Context, userID string, ttl time.Duration) (bool, error)
+	ReleaseRideLock(ctx context.Context, userID string) error
+	IsRideLocked(ctx context.Context, userID string) (bool, error)
+	IsInMatchingPool(ctx context.Context, userID string, isDriver bool) (bool, error)
+
+	// Proposal deduplication
+	ClaimMatchProposal(ctx context.Context, passengerID, driverID string, window time.Duration) (bool, error)
+	ReleaseMatchProposal(ctx context.Context, passengerID, driverID string) error
+	ClaimProposalRefresh(ctx context.Context, passengerID string, interval time.Duration) (bool, error)
+	ScheduleProposalRefresh(ctx context.Context, passengerID string, now time.Time) error
+	ClaimDueProposalRefreshes(ctx context.Context, now time.Time, limit int) ([]string, error)
+
+	// Driver cancellation tracking
+	RecordDriverAccepted(ctx context.Context, driverID, matchID string, at time.Time, window time.Duration) error
+	RecordDriverCancelled(ctx context.Context, driverID, rideID string, at time.Time, window time.Duration) error
+	CountDriverOutcomes(ctx context.Context, driverID string, since time.Time) (accepted, cancelled int, err error)
+	SuspendDriver(ctx context.Context, driverID string, penalty time.Duration) error
+	GetDriverSuspension(ctx context.Context, driverID string) (time.Duration, error)
+
+	// Fairness rotation
+	RecordDriverRideCompleted(ctx context.Context, driverID string, at time.Time) error
+	GetDriversLastRideAt(ctx context.Context, driverIDs []string) (map[string]time.Time, error)
+
+	// Gender-preference matching
+	SetDriverGender(ctx context.Context, driverID, gender string) error
+	GetDriverGenders(ctx context.Context, driverIDs []string) (map[string]string, error)
+
+	// Back-to-back rides
+	MarkDriverFinishingRide(ctx context.Context, driverID, rideID string, ttl time.Duration) error
+	GetDriverFinishingRide(ctx context.Context, driverID string) (string, error)
+	ClearDriverFinishingRide(ctx context.Context, driverID string) error
+
+	// Maintenance mode flag
+	GetMaintenanceMode(ctx context.Context) (bool, error)
+	SetMaintenanceMode(ctx context.Context, enabled bool) error
+
+	// Scheduled ride operations
+	ScheduleFinderEvent(ctx context.Context, event models.FinderEvent, at time.Time) error
+	CancelScheduledFinderEvent(ctx context.Context, passengerID string) error
+	ClaimDueScheduledFinderEvents(ctx context.Context, now time.Time, limit int) ([]models.FinderEvent, error)
+
+	// Driver acceptances gathered for the passenger to choose from
+	OpenAcceptanceWindow(ctx context.Context, passengerID string, closesAt time.Time) (bool, error)
+	ClaimDueAcceptanceWindows(ctx context.Context, now time.Time, limit int) ([]string, error)
+
+	// Pool removals that failed on acceptance and are retried
+	QueuePoolRemoval(ctx context.Context, removal models.PoolRemoval) error
+	ListPoolRemovals(ctx context.Context, limit int) ([]models.PoolRemoval, error)
+	CompletePoolRemoval(ctx context.Context, removal models.PoolRemoval) error
+
+	// Auto-rejection reconcile queue
+	QueueAutoRejection(ctx context.Context, rejection models.AutoRejection) error
+	ListAutoRejections(ctx context.Context, limit int) ([]models.AutoRejection, error)
+	CompleteAutoRejection(ctx context.Context, rejection models.AutoRejection) error
+	DropAutoRejectionsBefore(ctx context.Context, queuedBefore time.Time) error
 }
@@ -2,7 +2,11 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -11,6 +15,7 @@ import (
 	"github.com/lib/pq"
 	_ "github.com/newrelic/go-agent/v3/integrations/nrpq"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/piresc/nebengjek/internal/pkg/clock"
 	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
@@ -22,6 +27,9 @@ type MatchRepo struct {
 	cfg         *models.Config
 	db          *sqlx.DB
 	redisClient *database.RedisClient
+
+	// clock tells the time for timestamps and lock values, so tests can control it
+	clock clock.Clock
 }
 
 // NewMatchRepository creates a new match repository
@@ -34,6 +42,7 @@ func NewMatchRepository(
 		cfg:         cfg,
 		db:          db,
 		redisClient: redisClient,
+		clock:       clock.Real{},
 	}
 }
 
@@ -71,6 +80,9 @@ func (r *MatchRepo) checkExistingPendingMatch(ctx context.Context, driverID, pas
 // insertMatch inserts a new match into the database
 func (r *MatchRepo) insertMatch(ctx context.Context, match *models.Match) error {
 	dto := match.ToDTO()
+	if dto.PaymentMethod == "" {
+		dto.PaymentMethod = models.PaymentMethodQRIS
+	}
 
 	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
@@ -82,14 +94,14 @@ func (r *MatchRepo) insertMatch(ctx context.Context, match *models.Match) error
 		INSERT INTO matches (
 			id, driver_id, passenger_id, 
 			driver_location, passenger_location, target_location,
-			status, driver_confirmed, passenger_confirmed,
+			status, driver_confirmed, passenger_confirmed, payment_method,
 			created_at, updated_at
 		) VALUES (
 			:id, :driver_id, :passenger_id,
 			point(:driver_longitude, :driver_latitude), 
 			point(:passenger_longitude, :passenger_latitude),
 			point(:target_longitude, :target_latitude),
-			:status, :driver_confirmed, :passenger_confirmed,
+			:status, :driver_confirmed, :passenger_confirmed, :payment_method,
 			:created_at, :updated_at
 		)
 	`
@@ -111,7 +123,7 @@ func (r *MatchRepo) CreateMatch(ctx context.Context, match *models.Match) (*mode
 
 	// Set up new match
 	match.ID = uuid.New()
-	now := time.Now()
+	now := r.clock.Now()
 	if match.CreatedAt.IsZero() {
 		match.CreatedAt = now
 	}
@@ -142,7 +154,7 @@ func (r *MatchRepo) GetMatch(ctx context.Context, matchID string) (*models.Match
 			(passenger_location[1])::float8 as passenger_latitude,
 			(target_location[0])::float8 as target_longitude,
 			(target_location[1])::float8 as target_latitude,
-			status, driver_confirmed, passenger_confirmed,
+			status, driver_confirmed, passenger_confirmed, payment_method,
 			created_at, updated_at
 		FROM matches
 		WHERE id = $1
@@ -154,7 +166,7 @@ func (r *MatchRepo) GetMatch(ctx context.Context, matchID string) (*models.Match
 		&dto.DriverLongitude, &dto.DriverLatitude,
 		&dto.PassengerLongitude, &dto.PassengerLatitude,
 		&dto.TargetLongitude, &dto.TargetLatitude,
-		&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed,
+		&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed, &dto.PaymentMethod,
 		&dto.CreatedAt, &dto.UpdatedAt,
 	)
 
@@ -200,7 +212,7 @@ func (r *MatchRepo) UpdateMatchStatus(ctx context.Context, matchID string, statu
 
 	// Update the match status
 	updateQuery := `UPDATE matches SET status = $1, updated_at = $2 WHERE id = $3`
-	result, err := tx.ExecContext(ctx, updateQuery, status, time.Now(), matchID)
+	result, err := tx.ExecContext(ctx, updateQuery, status, r.clock.Now(), matchID)
 	if err != nil {
 		return fmt.Errorf("failed to update match status: %w", err)
 	}
@@ -290,7 +302,7 @@ func (r *MatchRepo) ConfirmMatchByUser(ctx context.Context, matchID string, user
 			(passenger_location[1])::float8 as passenger_latitude,
 			(target_location[0])::float8 as target_longitude,
 			(target_location[1])::float8 as target_latitude,
-			status, driver_confirmed, passenger_confirmed,
+			status, driver_confirmed, passenger_confirmed, payment_method,
 			created_at, updated_at
 		FROM matches
 		WHERE id = $1
@@ -303,7 +315,7 @@ func (r *MatchRepo) ConfirmMatchByUser(ctx context.Context, matchID string, user
 		&dto.DriverLongitude, &dto.DriverLatitude,
 		&dto.PassengerLongitude, &dto.PassengerLatitude,
 		&dto.TargetLongitude, &dto.TargetLatitude,
-		&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed,
+		&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed, &dto.PaymentMethod,
 		&dto.CreatedAt, &dto.UpdatedAt,
 	)
 	if err != nil {
@@ -331,7 +343,7 @@ func (r *MatchRepo) ConfirmMatchByUser(ctx context.Context, matchID string, user
 
 	// Update match in database
 	match.Status = newStatus
-	match.UpdatedAt = time.Now()
+	match.UpdatedAt = r.clock.Now()
 	updatedDTO := match.ToDTO()
 
 	updateQuery := `
@@ -363,8 +375,9 @@ func (r *MatchRepo) ConfirmMatchByUser(ctx context.Context, matchID string, user
 	return match, nil
 }
 
-// ListMatchesByPassenger retrieves all matches for a passenger
-func (r *MatchRepo) ListMatchesByPassenger(ctx context.Context, passengerID uuid.UUID) ([]*models.Match, error) {
+// ListOpenMatchesByPassenger returns the passenger's matches still awaiting confirmation, newest
+// first. A passenger only has a handful of them open at a time, so they are read in one query.
+func (r *MatchRepo) ListOpenMatchesByPassenger(ctx context.Context, passengerID uuid.UUID) ([]*models.Match, error) {
 	query := `
         SELECT 
             id, driver_id, passenger_id,
@@ -377,11 +390,282 @@ func (r *MatchRepo) ListMatchesByPassenger(ctx context.Context, passengerID uuid
             status, driver_confirmed, passenger_confirmed,
             created_at, updated_at
         FROM matches
-        WHERE passenger_id = $1
-        ORDER BY created_at DESC
+        WHERE passenger_id = $1 AND status IN (` + models.SQLValueList(models.OpenMatchStatuses) + `)
+        ORDER BY created_at DESC, id DESC
     `
 
 	rows, err := r.db.QueryContext(ctx, query, passengerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list open matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*models.Match
+	for rows.Next() {
+		var dto models.MatchDTO
+		err := rows.Scan(
+			&dto.ID, &dto.DriverID, &dto.PassengerID,
+			&dto.DriverLongitude, &dto.DriverLatitude,
+			&dto.PassengerLongitude, &dto.PassengerLatitude,
+			&dto.TargetLongitude, &dto.TargetLatitude,
+			&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed,
+			&dto.CreatedAt, &dto.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+
+		matches = append(matches, dto.ToMatch())
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// UpdatePendingMatchesPassengerLocation moves the passenger's position on every match that has
+// not yet been accepted or rejected, returning the updated matches
+func (r *MatchRepo) UpdatePendingMatchesPassengerLocation(ctx context.Context, passengerID uuid.UUID, location models.Location) ([]*models.Match, error) {
+	query := `
+		UPDATE matches
+		SET passenger_location = point($1, $2),
+			updated_at = NOW()
+		WHERE passenger_id = $3 AND status IN ($4, $5, $6)
+		RETURNING
+			id, driver_id, passenger_id,
+			(driver_location[0])::float8 as driver_longitude,
+			(driver_location[1])::float8 as driver_latitude,
+			(passenger_location[0])::float8 as passenger_longitude,
+			(passenger_location[1])::float8 as passenger_latitude,
+			(target_location[0])::float8 as target_longitude,
+			(target_location[1])::float8 as target_latitude,
+			status, driver_confirmed, passenger_confirmed,
+			created_at, updated_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, location.Longitude, location.Latitude, passengerID,
+		models.MatchStatusPending, models.MatchStatusDriverConfirmed, models.MatchStatusPassengerConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update passenger location on pending matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*models.Match
+	for rows.Next() {
+		var dto models.MatchDTO
+		err := rows.Scan(
+			&dto.ID, &dto.DriverID, &dto.PassengerID,
+			&dto.DriverLongitude, &dto.DriverLatitude,
+			&dto.PassengerLongitude, &dto.PassengerLatitude,
+			&dto.TargetLongitude, &dto.TargetLatitude,
+			&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed,
+			&dto.CreatedAt, &dto.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+
+		matches = append(matches, dto.ToMatch())
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// UpdatePendingMatchesDriverLocation moves the driver's position on every match that has not yet
+// been accepted or rejected, returning the updated matches
+func (r *MatchRepo) UpdatePendingMatchesDriverLocation(ctx context.Context, driverID uuid.UUID, location models.Location) ([]*models.Match, error) {
+	query := `
+		UPDATE matches
+		SET driver_location = point($1, $2),
+			updated_at = NOW()
+		WHERE driver_id = $3 AND status IN ($4, $5, $6)
+		RETURNING
+			id, driver_id, passenger_id,
+			(driver_location[0])::float8 as driver_longitude,
+			(driver_location[1])::float8 as driver_latitude,
+			(passenger_location[0])::float8 as passenger_longitude,
+			(passenger_location[1])::float8 as passenger_latitude,
+			(target_location[0])::float8 as target_longitude,
+			(target_location[1])::float8 as target_latitude,
+			status, driver_confirmed, passenger_confirmed,
+			created_at, updated_at
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, location.Longitude, location.Latitude, driverID,
+		models.MatchStatusPending, models.MatchStatusDriverConfirmed, models.MatchStatusPassengerConfirmed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update driver location on pending matches: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*models.Match
+	for rows.Next() {
+		var dto models.MatchDTO
+		err := rows.Scan(
+			&dto.ID, &dto.DriverID, &dto.PassengerID,
+			&dto.DriverLongitude, &dto.DriverLatitude,
+			&dto.PassengerLongitude, &dto.PassengerLatitude,
+			&dto.TargetLongitude, &dto.TargetLatitude,
+			&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed,
+			&dto.CreatedAt, &dto.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+
+		matches = append(matches, dto.ToMatch())
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// ListExpiredProposals returns up to limit pending matches that nobody answered and were proposed
+// at or before createdBefore, oldest first
+func (r *MatchRepo) ListExpiredProposals(ctx context.Context, createdBefore time.Time, limit int) ([]*models.Match, error) {
+	query := `
+		SELECT
+			id, driver_id, passenger_id,
+			(driver_location[0])::float8 as driver_longitude,
+			(driver_location[1])::float8 as driver_latitude,
+			(passenger_location[0])::float8 as passenger_longitude,
+			(passenger_location[1])::float8 as passenger_latitude,
+			(target_location[0])::float8 as target_longitude,
+			(target_location[1])::float8 as target_latitude,
+			status, driver_confirmed, passenger_confirmed,
+			created_at, updated_at
+		FROM matches
+		WHERE status = $1 AND created_at <= $2
+		ORDER BY created_at
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, models.MatchStatusPending, createdBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list expired proposals: %w", err)
+	}
+	defer rows.Close()
+
+	var matches []*models.Match
+	for rows.Next() {
+		var dto models.MatchDTO
+		err := rows.Scan(
+			&dto.ID, &dto.DriverID, &dto.PassengerID,
+			&dto.DriverLongitude, &dto.DriverLatitude,
+			&dto.PassengerLongitude, &dto.PassengerLatitude,
+			&dto.TargetLongitude, &dto.TargetLatitude,
+			&dto.Status, &dto.DriverConfirmed, &dto.PassengerConfirmed,
+			&dto.CreatedAt, &dto.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan match: %w", err)
+		}
+
+		matches = append(matches, dto.ToMatch())
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating matches: %w", err)
+	}
+
+	return matches, nil
+}
+
+// CountPendingMatchesByPassenger counts the passenger's matches still awaiting confirmation
+func (r *MatchRepo) CountPendingMatchesByPassenger(ctx context.Context, passengerID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM matches
+		WHERE passenger_id = $1 AND status IN ($2, $3, $4)
+	`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, passengerID,
+		models.MatchStatusPending, models.MatchStatusDriverConfirmed, models.MatchStatusPassengerConfirmed).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending matches: %w", err)
+	}
+
+	return count, nil
+}
+
+// RecordRejectionReason stores why the driver rejected a match
+func (r *MatchRepo) RecordRejectionReason(ctx context.Context, matchID string, reason models.MatchRejectionReason) error {
+	query := `UPDATE matches SET rejection_reason = $1 WHERE id = $2`
+	result, err := r.db.ExecContext(ctx, query, reason, matchID)
+	if err != nil {
+		return fmt.Errorf("failed to record rejection reason: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("match not found: %s", matchID)
+	}
+	return nil
+}
+
+// CountRejectionReasons counts the rejection reasons drivers gave on matches rejected since the given time
+func (r *MatchRepo) CountRejectionReasons(ctx context.Context, since time.Time) (map[models.MatchRejectionReason]int, error) {
+	query := `
+		SELECT rejection_reason, COUNT(*)
+		FROM matches
+		WHERE rejection_reason IS NOT NULL AND updated_at >= $1
+		GROUP BY rejection_reason
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rejection reasons: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[models.MatchRejectionReason]int)
+	for rows.Next() {
+		var reason models.MatchRejectionReason
+		var count int
+		if err := rows.Scan(&reason, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan rejection reason count: %w", err)
+		}
+		counts[reason] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rejection reasons: %w", err)
+	}
+
+	return counts, nil
+}
+
+// ListMatchesByDriver retrieves a page of matches proposed to a driver, newest first
+func (r *MatchRepo) ListMatchesByDriver(ctx context.Context, driverID uuid.UUID, limit, offset int) ([]*models.Match, error) {
+	query := `
+        SELECT 
+            id, driver_id, passenger_id,
+            (driver_location[0])::float8 as driver_longitude,
+            (driver_location[1])::float8 as driver_latitude,
+            (passenger_location[0])::float8 as passenger_longitude,
+            (passenger_location[1])::float8 as passenger_latitude,
+            (target_location[0])::float8 as target_longitude,
+            (target_location[1])::float8 as target_latitude,
+            status, driver_confirmed, passenger_confirmed,
+            created_at, updated_at
+        FROM matches
+        WHERE driver_id = $1
+        ORDER BY created_at DESC
+        LIMIT $2 OFFSET $3
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, driverID, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list matches: %w", err)
 	}
@@ -412,10 +696,11 @@ func (r *MatchRepo) ListMatchesByPassenger(ctx context.Context, passengerID uuid
 	return matches, nil
 }
 
-// BatchUpdateMatchStatus updates the status of multiple matches
-func (r *MatchRepo) BatchUpdateMatchStatus(ctx context.Context, matchIDs []string, status models.MatchStatus) error {
-	if len(matchIDs) == 0 {
-		return nil
+// BatchUpdateMatchStatus updates the status of those matches currently in one of the from statuses
+// and returns the IDs of the matches that actually transitioned; other matches are left untouched and omitted
+func (r *MatchRepo) BatchUpdateMatchStatus(ctx context.Context, matchIDs []string, status models.MatchStatus, from []models.MatchStatus) ([]string, error) {
+	if len(matchIDs) == 0 || len(from) == 0 {
+		return nil, nil
 	}
 
 	// Convert string IDs to UUIDs for the query
@@ -423,39 +708,52 @@ func (r *MatchRepo) BatchUpdateMatchStatus(ctx context.Context, matchIDs []strin
 	for i, id := range matchIDs {
 		parsedUUID, err := uuid.Parse(id)
 		if err != nil {
-			return fmt.Errorf("invalid match ID format: %s", id)
+			return nil, fmt.Errorf("invalid match ID format: %s", id)
 		}
 		uuidIDs[i] = parsedUUID
 	}
 
-	// Use SQL IN clause for efficient batch update
+	// Use SQL IN clause for efficient batch update; only matches still in one of the from statuses change
 	query := `
 		UPDATE matches 
 		SET status = $1, updated_at = $2 
-		WHERE id = ANY($3) AND status IN ('PENDING', 'DRIVER_CONFIRMED', 'PASSENGER_CONFIRMED')
+		WHERE id = ANY($3) AND status IN (` + models.SQLValueList(from) + `)
+		RETURNING id
 	`
 
-	result, err := r.db.ExecContext(ctx, query, status, time.Now(), pq.Array(uuidIDs))
-	if err != nil {
-		return fmt.Errorf("failed to batch update match statuses: %w", err)
+	var updatedIDs []uuid.UUID
+	if err := r.db.SelectContext(ctx, &updatedIDs, query, status, r.clock.Now(), pq.Array(uuidIDs)); err != nil {
+		return nil, fmt.Errorf("failed to batch update match statuses: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
+	updated := make([]string, len(updatedIDs))
+	for i, id := range updatedIDs {
+		updated[i] = id.String()
 	}
 
 	logger.Info("Batch updated matches",
-		logger.Int64("rows_affected", rowsAffected),
+		logger.Int("requested", len(matchIDs)),
+		logger.Int("rows_affected", len(updated)),
 		logger.String("status", string(status)))
-	return nil
+	return updated, nil
 }
 
-// SetActiveRide stores active ride information for both driver and passenger
+// SetActiveRide stores active ride information for both driver and passenger.
+// It is a no-op when both already point at the ride, as happens when a pickup event is redelivered.
 func (r *MatchRepo) SetActiveRide(ctx context.Context, driverID, passengerID, rideID string) error {
 	txn := newrelic.FromContext(ctx)
 	redisCtx := newrelic.NewContext(ctx, txn)
 
+	driverRideID, driverErr := r.GetActiveRideByDriver(redisCtx, driverID)
+	passengerRideID, passengerErr := r.GetActiveRideByPassenger(redisCtx, passengerID)
+	if driverErr == nil && passengerErr == nil && driverRideID == rideID && passengerRideID == rideID {
+		logger.Debug("Active ride already set",
+			logger.String("ride_id", rideID),
+			logger.String("driver_id", driverID),
+			logger.String("passenger_id", passengerID))
+		return nil
+	}
+
 	// Get TTL from config, default to 24 hours if not configured
 	ttlHours := 24
 	if r.cfg != nil && r.cfg.Match.ActiveRideTTLHours > 0 {
@@ -483,18 +781,23 @@ func (r *MatchRepo) SetActiveRide(ctx context.Context, driverID, passengerID, ri
 	return nil
 }
 
-// RemoveActiveRide removes active ride information for both driver and passenger
+// RemoveActiveRide removes active ride information for both driver and passenger. An empty driver
+// ID leaves the driver's active ride in place, as for a driver already on their next ride.
 func (r *MatchRepo) RemoveActiveRide(ctx context.Context, driverID, passengerID string) error {
 	txn := newrelic.FromContext(ctx)
 	redisCtx := newrelic.NewContext(ctx, txn)
 
 	// Remove active ride for driver
-	driverKey := fmt.Sprintf(constants.KeyActiveRideDriver, driverID)
-	if err := r.redisClient.Delete(redisCtx, driverKey); err != nil {
-		logger.Warn("Failed to remove active ride for driver",
-			logger.String("driver_id", driverID),
-			logger.ErrorField(err))
-		// Continue with passenger cleanup even if driver cleanup fails
+	var errs []error
+	if driverID != "" {
+		driverKey := fmt.Sprintf(constants.KeyActiveRideDriver, driverID)
+		if err := r.redisClient.Delete(redisCtx, driverKey); err != nil {
+			logger.Warn("Failed to remove active ride for driver",
+				logger.String("driver_id", driverID),
+				logger.ErrorField(err))
+			// Continue with passenger cleanup even if driver cleanup fails
+			errs = append(errs, fmt.Errorf("failed to remove active ride for driver: %w", err))
+		}
 	}
 
 	// Remove active ride for passenger
@@ -503,7 +806,12 @@ func (r *MatchRepo) RemoveActiveRide(ctx context.Context, driverID, passengerID
 		logger.Warn("Failed to remove active ride for passenger",
 			logger.String("passenger_id", passengerID),
 			logger.ErrorField(err))
-		// Don't return error for cleanup operations
+		errs = append(errs, fmt.Errorf("failed to remove active ride for passenger: %w", err))
+	}
+
+	// Callers retry the whole cleanup, which is safe as removing a missing key succeeds
+	if len(errs) > 0 {
+		return errors.Join(errs...)
 	}
 
 	logger.Info("Removed active ride",
@@ -547,3 +855,628 @@ func (r *MatchRepo) GetActiveRideByPassenger(ctx context.Context, passengerID st
 	}
 	return rideID, nil
 }
+
+// AcquireRideLock takes the ride lock for a user, returning false if it is already held
+func (r *MatchRepo) AcquireRideLock(ctx context.Context, userID string, ttl time.Duration) (bool, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	lockKey := fmt.Sprintf(constants.KeyRideLockUser, userID)
+	acquired, err := r.redisClient.SetNX(redisCtx, lockKey, r.clock.Now().Unix(), ttl)
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire ride lock: %w", err)
+	}
+	return acquired, nil
+}
+
+// ClaimMatchProposal records that a driver is being proposed to a passenger, returning false
+// if the same pair was already proposed within the dedup window
+func (r *MatchRepo) ClaimMatchProposal(ctx context.Context, passengerID, driverID string, window time.Duration) (bool, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	dedupKey := fmt.Sprintf(constants.KeyProposalDedup, passengerID, driverID)
+	claimed, err := r.redisClient.SetNX(redisCtx, dedupKey, r.clock.Now().Unix(), window)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim match proposal: %w", err)
+	}
+	return claimed, nil
+}
+
+// ReleaseMatchProposal drops a driver's proposal claim for a passenger, so a proposal that was
+// never created does not suppress the driver for the rest of the window
+func (r *MatchRepo) ReleaseMatchProposal(ctx context.Context, passengerID, driverID string) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	dedupKey := fmt.Sprintf(constants.KeyProposalDedup, passengerID, driverID)
+	if err := r.redisClient.Delete(redisCtx, dedupKey); err != nil {
+		return fmt.Errorf("failed to release match proposal claim: %w", err)
+	}
+	return nil
+}
+
+// ClaimProposalRefresh records that a passenger's pending proposals are being re-sent, returning
+// false if they were already re-sent within the interval
+func (r *MatchRepo) ClaimProposalRefresh(ctx context.Context, passengerID string, interval time.Duration) (bool, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	key := fmt.Sprintf(constants.KeyProposalRefresh, passengerID)
+	claimed, err := r.redisClient.SetNX(redisCtx, key, r.clock.Now().Unix(), interval)
+	if err != nil {
+		return false, fmt.Errorf("failed to claim proposal refresh: %w", err)
+	}
+	return claimed, nil
+}
+
+// ScheduleProposalRefresh queues a throttled refresh of a passenger's proposals for when their
+// refresh claim expires. Scheduling it again within the same interval keeps a single entry.
+func (r *MatchRepo) ScheduleProposalRefresh(ctx context.Context, passengerID string, now time.Time) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	key := fmt.Sprintf(constants.KeyProposalRefresh, passengerID)
+	remaining, err := r.redisClient.TTL(redisCtx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read proposal refresh claim: %w", err)
+	}
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	dueAt := now.Add(remaining)
+	if err := r.redisClient.ZAdd(redisCtx, constants.KeyProposalRefreshes, float64(dueAt.Unix()), passengerID); err != nil {
+		return fmt.Errorf("failed to schedule proposal refresh: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueProposalRefreshes removes and returns up to limit passengers whose throttled proposal
+// refresh is due at or before now. Removing an entry is the claim, so each is refreshed once.
+func (r *MatchRepo) ClaimDueProposalRefreshes(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	passengerIDs, err := r.redisClient.ZRangeByScore(redisCtx, constants.KeyProposalRefreshes,
+		"-inf", strconv.FormatInt(now.Unix(), 10), int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due proposal refreshes: %w", err)
+	}
+
+	claimed := make([]string, 0, len(passengerIDs))
+	for _, passengerID := range passengerIDs {
+		removed, err := r.redisClient.ZRemCount(redisCtx, constants.KeyProposalRefreshes, passengerID)
+		if err != nil {
+			return claimed, fmt.Errorf("failed to claim proposal refresh: %w", err)
+		}
+		if removed == 0 {
+			// Another instance claimed it first
+			continue
+		}
+		claimed = append(claimed, passengerID)
+	}
+	return claimed, nil
+}
+
+// ReleaseRideLock releases the ride lock for a user
+func (r *MatchRepo) ReleaseRideLock(ctx context.Context, userID string) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	lockKey := fmt.Sprintf(constants.KeyRideLockUser, userID)
+	if err := r.redisClient.Delete(redisCtx, lockKey); err != nil {
+		return fmt.Errorf("failed to release ride lock: %w", err)
+	}
+	return nil
+}
+
+// IsRideLocked checks whether a user is currently held by a ride lock
+func (r *MatchRepo) IsRideLocked(ctx context.Context, userID string) (bool, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	lockKey := fmt.Sprintf(constants.KeyRideLockUser, userID)
+	_, err := r.redisClient.Get(redisCtx, lockKey)
+	if err != nil {
+		// A missing key means the user is not locked
+		if err == redis.Nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to check ride lock: %w", err)
+	}
+	return true, nil
+}
+
+// IsInMatchingPool reports whether the user is in the driver or passenger available pool kept by
+// the location service
+func (r *MatchRepo) IsInMatchingPool(ctx context.Context, userID string, isDriver bool) (bool, error) {
+	key := constants.KeyAvailablePassengers
+	if isDriver {
+		key = constants.KeyAvailableDrivers
+	}
+
+	inPool, err := r.redisClient.SIsMember(ctx, key, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check pool membership: %w", err)
+	}
+	return inPool, nil
+}
+
+// recordDriverOutcome adds an accepted match or cancelled ride to a driver's rolling window,
+// dropping entries older than the window. IDs are the members, so redelivered events count once.
+func (r *MatchRepo) recordDriverOutcome(ctx context.Context, key, id string, at time.Time, window time.Duration) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.ZAdd(redisCtx, key, float64(at.Unix()), id); err != nil {
+		return err
+	}
+	cutoff := strconv.FormatInt(at.Add(-window).Unix(), 10)
+	if err := r.redisClient.ZRemRangeByScore(redisCtx, key, "-inf", "("+cutoff); err != nil {
+		return err
+	}
+	// Drivers who stop driving don't leave their history behind
+	return r.redisClient.Expire(redisCtx, key, window)
+}
+
+// RecordDriverAccepted counts a match the driver accepted towards their cancellation rate
+func (r *MatchRepo) RecordDriverAccepted(ctx context.Context, driverID, matchID string, at time.Time, window time.Duration) error {
+	key := fmt.Sprintf(constants.KeyDriverAccepted, driverID)
+	if err := r.recordDriverOutcome(ctx, key, matchID, at, window); err != nil {
+		return fmt.Errorf("failed to record driver acceptance: %w", err)
+	}
+	return nil
+}
+
+// RecordDriverCancelled counts a ride the driver cancelled towards their cancellation rate
+func (r *MatchRepo) RecordDriverCancelled(ctx context.Context, driverID, rideID string, at time.Time, window time.Duration) error {
+	key := fmt.Sprintf(constants.KeyDriverCancelled, driverID)
+	if err := r.recordDriverOutcome(ctx, key, rideID, at, window); err != nil {
+		return fmt.Errorf("failed to record driver cancellation: %w", err)
+	}
+	return nil
+}
+
+// CountDriverOutcomes returns how many matches a driver accepted and rides they cancelled since the given time
+func (r *MatchRepo) CountDriverOutcomes(ctx context.Context, driverID string, since time.Time) (int, int, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	min := strconv.FormatInt(since.Unix(), 10)
+	accepted, err := r.redisClient.ZCount(redisCtx, fmt.Sprintf(constants.KeyDriverAccepted, driverID), min, "+inf")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count driver acceptances: %w", err)
+	}
+	cancelled, err := r.redisClient.ZCount(redisCtx, fmt.Sprintf(constants.KeyDriverCancelled, driverID), min, "+inf")
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to count driver cancellations: %w", err)
+	}
+	return int(accepted), int(cancelled), nil
+}
+
+// SuspendDriver leaves a driver out of matching until the penalty expires
+func (r *MatchRepo) SuspendDriver(ctx context.Context, driverID string, penalty time.Duration) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	key := fmt.Sprintf(constants.KeyDriverSuspended, driverID)
+	if err := r.redisClient.Set(redisCtx, key, r.clock.Now().Unix(), penalty); err != nil {
+		return fmt.Errorf("failed to suspend driver: %w", err)
+	}
+	return nil
+}
+
+// GetDriverSuspension returns how long a driver's suspension has left, or zero if they are not suspended
+func (r *MatchRepo) GetDriverSuspension(ctx context.Context, driverID string) (time.Duration, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	remaining, err := r.redisClient.TTL(redisCtx, fmt.Sprintf(constants.KeyDriverSuspended, driverID))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get driver suspension: %w", err)
+	}
+	if remaining < 0 {
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// RecordDriverRideCompleted notes when a driver last completed a ride for the fairness rotation
+func (r *MatchRepo) RecordDriverRideCompleted(ctx context.Context, driverID string, at time.Time) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.HMSet(redisCtx, constants.KeyDriverLastRide, map[string]interface{}{driverID: at.Unix()}); err != nil {
+		return fmt.Errorf("failed to record driver ride completion: %w", err)
+	}
+	return nil
+}
+
+// GetDriversLastRideAt returns when each of the drivers last completed a ride. Drivers with no
+// completed ride on record are left out of the result.
+func (r *MatchRepo) GetDriversLastRideAt(ctx context.Context, driverIDs []string) (map[string]time.Time, error) {
+	lastRides := make(map[string]time.Time, len(driverIDs))
+	if len(driverIDs) == 0 {
+		return lastRides, nil
+	}
+
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	values, err := r.redisClient.HMGet(redisCtx, constants.KeyDriverLastRide, driverIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver last rides: %w", err)
+	}
+
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		unix, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid last ride time for driver %s: %w", driverIDs[i], err)
+		}
+		lastRides[driverIDs[i]] = time.Unix(unix, 0)
+	}
+	return lastRides, nil
+}
+
+// SetDriverGender records the gender on the driver's profile for gender-preference matching
+func (r *MatchRepo) SetDriverGender(ctx context.Context, driverID, gender string) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.HMSet(redisCtx, constants.KeyDriverGender, map[string]interface{}{driverID: gender}); err != nil {
+		return fmt.Errorf("failed to record driver gender: %w", err)
+	}
+	return nil
+}
+
+// GetDriverGenders returns the recorded gender of each of the drivers. Drivers with no gender on
+// record are left out of the result.
+func (r *MatchRepo) GetDriverGenders(ctx context.Context, driverIDs []string) (map[string]string, error) {
+	genders := make(map[string]string, len(driverIDs))
+	if len(driverIDs) == 0 {
+		return genders, nil
+	}
+
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	values, err := r.redisClient.HMGet(redisCtx, constants.KeyDriverGender, driverIDs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver genders: %w", err)
+	}
+
+	for i, value := range values {
+		if value != "" {
+			genders[driverIDs[i]] = value
+		}
+	}
+	return genders, nil
+}
+
+// MarkDriverFinishingRide records that a driver about to finish a ride asked for their next match early
+func (r *MatchRepo) MarkDriverFinishingRide(ctx context.Context, driverID, rideID string, ttl time.Duration) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.Set(redisCtx, fmt.Sprintf(constants.KeyDriverFinishingRide, driverID), rideID, ttl); err != nil {
+		return fmt.Errorf("failed to mark driver finishing ride: %w", err)
+	}
+	return nil
+}
+
+// GetDriverFinishingRide returns the ride a driver opted in to back-to-back matching from, or an
+// empty string when they haven't
+func (r *MatchRepo) GetDriverFinishingRide(ctx context.Context, driverID string) (string, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	rideID, err := r.redisClient.Get(redisCtx, fmt.Sprintf(constants.KeyDriverFinishingRide, driverID))
+	if err != nil {
+		if err == redis.Nil {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get driver finishing ride: %w", err)
+	}
+	return rideID, nil
+}
+
+// ClearDriverFinishingRide removes a driver's back-to-back opt-in once their ride has finished
+func (r *MatchRepo) ClearDriverFinishingRide(ctx context.Context, driverID string) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.Delete(redisCtx, fmt.Sprintf(constants.KeyDriverFinishingRide, driverID)); err != nil {
+		return fmt.Errorf("failed to clear driver finishing ride: %w", err)
+	}
+	return nil
+}
+
+// GetMaintenanceMode reports whether maintenance mode has been switched on at runtime
+func (r *MatchRepo) GetMaintenanceMode(ctx context.Context) (bool, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	_, err := r.redisClient.Get(redisCtx, constants.KeyMatchMaintenance)
+	if err != nil {
+		// A missing key means maintenance is off
+		if err == redis.Nil {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get maintenance mode: %w", err)
+	}
+	return true, nil
+}
+
+// SetMaintenanceMode switches runtime maintenance mode on or off for every match instance
+func (r *MatchRepo) SetMaintenanceMode(ctx context.Context, enabled bool) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if !enabled {
+		if err := r.redisClient.Delete(redisCtx, constants.KeyMatchMaintenance); err != nil {
+			return fmt.Errorf("failed to disable maintenance mode: %w", err)
+		}
+		return nil
+	}
+
+	if err := r.redisClient.Set(redisCtx, constants.KeyMatchMaintenance, r.clock.Now().Unix(), 0); err != nil {
+		return fmt.Errorf("failed to enable maintenance mode: %w", err)
+	}
+	return nil
+}
+
+// ScheduleFinderEvent holds a passenger's finder event until its scheduled time,
+// replacing any ride the passenger had already scheduled
+func (r *MatchRepo) ScheduleFinderEvent(ctx context.Context, event models.FinderEvent, at time.Time) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal scheduled finder event: %w", err)
+	}
+
+	eventKey := fmt.Sprintf(constants.KeyScheduledFinderEvent, event.UserID)
+	if err := r.redisClient.Set(redisCtx, eventKey, payload, 0); err != nil {
+		return fmt.Errorf("failed to store scheduled finder event: %w", err)
+	}
+
+	if err := r.redisClient.ZAdd(redisCtx, constants.KeyScheduledFinders, float64(at.Unix()), event.UserID); err != nil {
+		return fmt.Errorf("failed to schedule finder event: %w", err)
+	}
+	return nil
+}
+
+// CancelScheduledFinderEvent drops a passenger's scheduled ride, if any
+func (r *MatchRepo) CancelScheduledFinderEvent(ctx context.Context, passengerID string) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if _, err := r.redisClient.ZRemCount(redisCtx, constants.KeyScheduledFinders, passengerID); err != nil {
+		return fmt.Errorf("failed to cancel scheduled finder event: %w", err)
+	}
+
+	eventKey := fmt.Sprintf(constants.KeyScheduledFinderEvent, passengerID)
+	if err := r.redisClient.Delete(redisCtx, eventKey); err != nil {
+		return fmt.Errorf("failed to delete scheduled finder event: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueScheduledFinderEvents removes and returns up to limit finder events scheduled at or before now.
+// Removing an entry from the schedule is the claim, so each event is returned to only one caller.
+func (r *MatchRepo) ClaimDueScheduledFinderEvents(ctx context.Context, now time.Time, limit int) ([]models.FinderEvent, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	passengerIDs, err := r.redisClient.ZRangeByScore(redisCtx, constants.KeyScheduledFinders,
+		"-inf", strconv.FormatInt(now.Unix(), 10), int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due scheduled finder events: %w", err)
+	}
+
+	events := make([]models.FinderEvent, 0, len(passengerIDs))
+	for _, passengerID := range passengerIDs {
+		removed, err := r.redisClient.ZRemCount(redisCtx, constants.KeyScheduledFinders, passengerID)
+		if err != nil {
+			return events, fmt.Errorf("failed to claim scheduled finder event: %w", err)
+		}
+		if removed == 0 {
+			// Another instance claimed it first
+			continue
+		}
+
+		eventKey := fmt.Sprintf(constants.KeyScheduledFinderEvent, passengerID)
+		payload, err := r.redisClient.Get(redisCtx, eventKey)
+		if err != nil {
+			logger.Warn("Scheduled finder event payload missing",
+				logger.String("passenger_id", passengerID),
+				logger.ErrorField(err))
+			continue
+		}
+
+		if err := r.redisClient.Delete(redisCtx, eventKey); err != nil {
+			logger.Warn("Failed to delete scheduled finder event payload",
+				logger.String("passenger_id", passengerID),
+				logger.ErrorField(err))
+		}
+
+		var event models.FinderEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			logger.Error("Failed to unmarshal scheduled finder event",
+				logger.String("passenger_id", passengerID),
+				logger.ErrorField(err))
+			continue
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// OpenAcceptanceWindow starts gathering driver acceptances for a passenger until closesAt. It reports
+// false when a window is already open, so later acceptances join it rather than extending it.
+func (r *MatchRepo) OpenAcceptanceWindow(ctx context.Context, passengerID string, closesAt time.Time) (bool, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	windowKey := fmt.Sprintf(constants.KeyAcceptanceWindow, passengerID)
+	opened, err := r.redisClient.SetNX(redisCtx, windowKey, closesAt.Unix(), closesAt.Sub(r.clock.Now()))
+	if err != nil {
+		return false, fmt.Errorf("failed to open acceptance window: %w", err)
+	}
+	if !opened {
+		return false, nil
+	}
+
+	if err := r.redisClient.ZAdd(redisCtx, constants.KeyAcceptanceWindows, float64(closesAt.Unix()), passengerID); err != nil {
+		return false, fmt.Errorf("failed to schedule acceptance window close: %w", err)
+	}
+	return true, nil
+}
+
+// ClaimDueAcceptanceWindows removes and returns up to limit passengers whose acceptance window closed
+// at or before now. Removing an entry is the claim, so each window is closed by only one caller.
+func (r *MatchRepo) ClaimDueAcceptanceWindows(ctx context.Context, now time.Time, limit int) ([]string, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	passengerIDs, err := r.redisClient.ZRangeByScore(redisCtx, constants.KeyAcceptanceWindows,
+		"-inf", strconv.FormatInt(now.Unix(), 10), int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due acceptance windows: %w", err)
+	}
+
+	claimed := make([]string, 0, len(passengerIDs))
+	for _, passengerID := range passengerIDs {
+		removed, err := r.redisClient.ZRemCount(redisCtx, constants.KeyAcceptanceWindows, passengerID)
+		if err != nil {
+			return claimed, fmt.Errorf("failed to claim acceptance window: %w", err)
+		}
+		if removed == 0 {
+			// Another instance claimed it first
+			continue
+		}
+		claimed = append(claimed, passengerID)
+	}
+	return claimed, nil
+}
+
+// poolRemovalMember encodes a pool removal as its member in the retry set
+func poolRemovalMember(removal models.PoolRemoval) string {
+	return removal.Role + ":" + removal.UserID
+}
+
+// QueuePoolRemoval records a matched user whose removal from the available pool must be retried.
+// Queueing the same user again keeps a single entry.
+func (r *MatchRepo) QueuePoolRemoval(ctx context.Context, removal models.PoolRemoval) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.ZAdd(redisCtx, constants.KeyPoolRemovals, float64(r.clock.Now().Unix()), poolRemovalMember(removal)); err != nil {
+		return fmt.Errorf("failed to queue pool removal: %w", err)
+	}
+	return nil
+}
+
+// ListPoolRemovals returns up to limit queued pool removals, oldest first
+func (r *MatchRepo) ListPoolRemovals(ctx context.Context, limit int) ([]models.PoolRemoval, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	members, err := r.redisClient.ZRangeByScore(redisCtx, constants.KeyPoolRemovals, "-inf", "+inf", int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pool removals: %w", err)
+	}
+
+	removals := make([]models.PoolRemoval, 0, len(members))
+	for _, member := range members {
+		role, userID, ok := strings.Cut(member, ":")
+		if !ok {
+			logger.Warn("Skipping malformed pool removal", logger.String("member", member))
+			continue
+		}
+		removals = append(removals, models.PoolRemoval{Role: role, UserID: userID})
+	}
+	return removals, nil
+}
+
+// CompletePoolRemoval drops a pool removal from the retry queue once it has succeeded
+func (r *MatchRepo) CompletePoolRemoval(ctx context.Context, removal models.PoolRemoval) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.ZRem(redisCtx, constants.KeyPoolRemovals, poolRemovalMember(removal)); err != nil {
+		return fmt.Errorf("failed to complete pool removal: %w", err)
+	}
+	return nil
+}
+
+// autoRejectionMember encodes a queued auto-rejection as its member in the reconcile set
+func autoRejectionMember(rejection models.AutoRejection) string {
+	return rejection.PassengerID + ":" + rejection.MatchID
+}
+
+// QueueAutoRejection records an accepted match whose passenger's other open matches must still be
+// rejected, scored by when it was queued. Queueing the same match again keeps a single entry.
+func (r *MatchRepo) QueueAutoRejection(ctx context.Context, rejection models.AutoRejection) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	queuedAt := rejection.QueuedAt
+	if queuedAt.IsZero() {
+		queuedAt = r.clock.Now()
+	}
+	if err := r.redisClient.ZAdd(redisCtx, constants.KeyAutoRejections, float64(queuedAt.Unix()), autoRejectionMember(rejection)); err != nil {
+		return fmt.Errorf("failed to queue auto-rejection: %w", err)
+	}
+	return nil
+}
+
+// ListAutoRejections returns up to limit queued auto-rejections, oldest first
+func (r *MatchRepo) ListAutoRejections(ctx context.Context, limit int) ([]models.AutoRejection, error) {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	members, err := r.redisClient.ZRangeByScore(redisCtx, constants.KeyAutoRejections, "-inf", "+inf", int64(limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list auto-rejections: %w", err)
+	}
+
+	rejections := make([]models.AutoRejection, 0, len(members))
+	for _, member := range members {
+		passengerID, matchID, ok := strings.Cut(member, ":")
+		if !ok {
+			logger.Warn("Skipping malformed auto-rejection", logger.String("member", member))
+			continue
+		}
+		rejections = append(rejections, models.AutoRejection{PassengerID: passengerID, MatchID: matchID})
+	}
+	return rejections, nil
+}
+
+// DropAutoRejectionsBefore removes auto-rejections queued before queuedBefore from the reconcile queue
+func (r *MatchRepo) DropAutoRejectionsBefore(ctx context.Context, queuedBefore time.Time) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	maxScore := "(" + strconv.FormatInt(queuedBefore.Unix(), 10)
+	if err := r.redisClient.ZRemRangeByScore(redisCtx, constants.KeyAutoRejections, "-inf", maxScore); err != nil {
+		return fmt.Errorf("failed to drop expired auto-rejections: %w", err)
+	}
+	return nil
+}
+
+// CompleteAutoRejection drops an auto-rejection from the reconcile queue once it has succeeded
+func (r *MatchRepo) CompleteAutoRejection(ctx context.Context, rejection models.AutoRejection) error {
+	txn := newrelic.FromContext(ctx)
+	redisCtx := newrelic.NewContext(ctx, txn)
+
+	if err := r.redisClient.ZRem(redisCtx, constants.KeyAutoRejections, autoRejectionMember(rejection)); err != nil {
+		return fmt.Errorf("failed to complete auto-rejection: %w", err)
+	}
+	return nil
+}
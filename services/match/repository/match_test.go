@@ -12,9 +12,12 @@ import (
 	"github.com/go-redis/redis/v8"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/piresc/nebengjek/internal/pkg/clock"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
@@ -84,10 +87,11 @@ func TestCreateMatch_Success(t *testing.T) {
 			targetLoc.Longitude,
 			targetLoc.Latitude,
 			models.MatchStatusPending,
-			false,            // driver_confirmed
-			false,            // passenger_confirmed
-			sqlmock.AnyArg(), // created_at
-			sqlmock.AnyArg(), // updated_at
+			false,                    // driver_confirmed
+			false,                    // passenger_confirmed
+			models.PaymentMethodQRIS, // payment_method defaults to QRIS
+			sqlmock.AnyArg(),         // created_at
+			sqlmock.AnyArg(),         // updated_at
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -136,7 +140,7 @@ func TestGetMatch_Success(t *testing.T) {
 		"driver_longitude", "driver_latitude",
 		"passenger_longitude", "passenger_latitude",
 		"target_longitude", "target_latitude",
-		"status", "driver_confirmed", "passenger_confirmed",
+		"status", "driver_confirmed", "passenger_confirmed", "payment_method",
 		"created_at", "updated_at"}).
 		AddRow(
 			matchID, driverID, passengerID,
@@ -144,6 +148,7 @@ func TestGetMatch_Success(t *testing.T) {
 			passengerLongitude, passengerLatitude,
 			106.837153, -6.185392, // target location
 			models.MatchStatusPending, false, false, // confirmation flags
+			models.PaymentMethodCash,
 			now, now) // Use time.Time objects here
 
 	mock.ExpectQuery(regexp.QuoteMeta(`
@@ -155,7 +160,7 @@ func TestGetMatch_Success(t *testing.T) {
 			(passenger_location[1])::float8 as passenger_latitude,
 			(target_location[0])::float8 as target_longitude,
 			(target_location[1])::float8 as target_latitude,
-			status, driver_confirmed, passenger_confirmed,
+			status, driver_confirmed, passenger_confirmed, payment_method,
 			created_at, updated_at
 		FROM matches
 		WHERE id = $1
@@ -174,6 +179,7 @@ func TestGetMatch_Success(t *testing.T) {
 	assert.Equal(t, driverID, match.DriverID)
 	assert.Equal(t, passengerID, match.PassengerID)
 	assert.Equal(t, models.MatchStatusPending, match.Status)
+	assert.Equal(t, models.PaymentMethodCash, match.PaymentMethod)
 	assert.Equal(t, driverLongitude, match.DriverLocation.Longitude)
 	assert.Equal(t, driverLatitude, match.DriverLocation.Latitude)
 	assert.Equal(t, passengerLongitude, match.PassengerLocation.Longitude)
@@ -244,8 +250,8 @@ func TestUpdateMatchStatus_Success(t *testing.T) {
 // AddAvailablePassenger, RemoveAvailablePassenger) have been moved to the location service.
 // These tests are no longer needed in the match repository.
 
-// TestListMatchesByPassenger tests listing all matches for a passenger
-func TestListMatchesByPassenger_Success(t *testing.T) {
+// TestListOpenMatchesByPassenger tests listing the matches a passenger still has open
+func TestListOpenMatchesByPassenger_Success(t *testing.T) {
 	// Arrange
 	db, mock := setupMockDB(t)
 	redisClient, miniRedis := setupMockRedis(t)
@@ -256,7 +262,6 @@ func TestListMatchesByPassenger_Success(t *testing.T) {
 	passengerID := uuid.New()
 	now := time.Now()
 
-	// Setup mock data for SQL query
 	matchRows := sqlmock.NewRows([]string{
 		"id", "driver_id", "passenger_id",
 		"driver_longitude", "driver_latitude",
@@ -265,19 +270,16 @@ func TestListMatchesByPassenger_Success(t *testing.T) {
 		"status", "driver_confirmed", "passenger_confirmed",
 		"created_at", "updated_at"})
 
-	// Add 3 matches for the passenger
 	matchID1 := uuid.New()
 	matchID2 := uuid.New()
-	matchID3 := uuid.New()
 	driverID1 := uuid.New()
 	driverID2 := uuid.New()
-	driverID3 := uuid.New()
 
 	matchRows.AddRow(
 		matchID1, driverID1, passengerID,
 		106.827153, -6.175392, 106.837153, -6.185392,
 		106.847153, -6.195392, // target location
-		models.MatchStatusAccepted, false, true, // confirmation flags
+		models.MatchStatusDriverConfirmed, true, false, // confirmation flags
 		now, now)
 
 	matchRows.AddRow(
@@ -287,8 +289,221 @@ func TestListMatchesByPassenger_Success(t *testing.T) {
 		models.MatchStatusPending, false, false, // confirmation flags
 		now, now)
 
+	// Finished matches are filtered out in SQL, and no count is taken
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE passenger_id = $1 AND status IN ('PENDING', 'DRIVER_CONFIRMED', 'PASSENGER_CONFIRMED')
+        ORDER BY created_at DESC, id DESC`)).
+		WithArgs(passengerID).
+		WillReturnRows(matchRows)
+
+	// Act
+	matches, err := repo.ListOpenMatchesByPassenger(context.Background(), passengerID)
+
+	// Assert
+	assert.NoError(t, err)
+	require.Len(t, matches, 2)
+	assert.Equal(t, matchID1, matches[0].ID)
+	assert.Equal(t, matchID2, matches[1].ID)
+	assert.Equal(t, driverID1, matches[0].DriverID)
+	assert.Equal(t, driverID2, matches[1].DriverID)
+	assert.Equal(t, models.MatchStatusDriverConfirmed, matches[0].Status)
+	assert.Equal(t, models.MatchStatusPending, matches[1].Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePendingMatchesPassengerLocation_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	passengerID := uuid.New()
+	matchID := uuid.New()
+	driverID := uuid.New()
+	now := time.Now()
+	location := models.Location{Latitude: -6.178090, Longitude: 106.827153}
+
+	rows := sqlmock.NewRows([]string{
+		"id", "driver_id", "passenger_id",
+		"driver_longitude", "driver_latitude",
+		"passenger_longitude", "passenger_latitude",
+		"target_longitude", "target_latitude",
+		"status", "driver_confirmed", "passenger_confirmed",
+		"created_at", "updated_at"}).
+		AddRow(matchID, driverID, passengerID,
+			106.827153, -6.175392, location.Longitude, location.Latitude,
+			106.847153, -6.195392,
+			models.MatchStatusDriverConfirmed, true, false,
+			now, now)
+
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE matches")).
+		WithArgs(location.Longitude, location.Latitude, passengerID,
+			models.MatchStatusPending, models.MatchStatusDriverConfirmed, models.MatchStatusPassengerConfirmed).
+		WillReturnRows(rows)
+
+	matches, err := repo.UpdatePendingMatchesPassengerLocation(context.Background(), passengerID, location)
+
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, matchID, matches[0].ID)
+	assert.Equal(t, location.Latitude, matches[0].PassengerLocation.Latitude)
+	assert.Equal(t, location.Longitude, matches[0].PassengerLocation.Longitude)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePendingMatchesDriverLocation_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	passengerID := uuid.New()
+	matchID := uuid.New()
+	driverID := uuid.New()
+	now := time.Now()
+	location := models.Location{Latitude: -6.176500, Longitude: 106.827153}
+
+	rows := sqlmock.NewRows([]string{
+		"id", "driver_id", "passenger_id",
+		"driver_longitude", "driver_latitude",
+		"passenger_longitude", "passenger_latitude",
+		"target_longitude", "target_latitude",
+		"status", "driver_confirmed", "passenger_confirmed",
+		"created_at", "updated_at"}).
+		AddRow(matchID, driverID, passengerID,
+			location.Longitude, location.Latitude, 106.827153, -6.178090,
+			106.847153, -6.195392,
+			models.MatchStatusPending, false, false,
+			now, now)
+
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE matches")).
+		WithArgs(location.Longitude, location.Latitude, driverID,
+			models.MatchStatusPending, models.MatchStatusDriverConfirmed, models.MatchStatusPassengerConfirmed).
+		WillReturnRows(rows)
+
+	matches, err := repo.UpdatePendingMatchesDriverLocation(context.Background(), driverID, location)
+
+	assert.NoError(t, err)
+	assert.Len(t, matches, 1)
+	assert.Equal(t, matchID, matches[0].ID)
+	assert.Equal(t, location.Latitude, matches[0].DriverLocation.Latitude)
+	assert.Equal(t, location.Longitude, matches[0].DriverLocation.Longitude)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCountPendingMatchesByPassenger(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	passengerID := uuid.New()
+
+	// Accepted and rejected matches are excluded by the status filter
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*)")).
+		WithArgs(passengerID,
+			models.MatchStatusPending, models.MatchStatusDriverConfirmed, models.MatchStatusPassengerConfirmed).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	count, err := repo.CountPendingMatchesByPassenger(context.Background(), passengerID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordRejectionReason(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	matchID := uuid.New().String()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE matches SET rejection_reason = $1 WHERE id = $2")).
+		WithArgs(models.RejectionReasonTooFar, matchID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.RecordRejectionReason(context.Background(), matchID, models.RejectionReasonTooFar)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordRejectionReason_NotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE matches SET rejection_reason")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.RecordRejectionReason(context.Background(), uuid.New().String(), models.RejectionReasonTooFar)
+
+	assert.ErrorContains(t, err, "match not found")
+}
+
+func TestCountRejectionReasons(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	since := time.Now().Add(-24 * time.Hour)
+	mock.ExpectQuery(`SELECT rejection_reason, COUNT\(\*\)\s+FROM matches\s+WHERE rejection_reason IS NOT NULL AND updated_at >= \$1\s+GROUP BY rejection_reason`).
+		WithArgs(since).
+		WillReturnRows(sqlmock.NewRows([]string{"rejection_reason", "count"}).
+			AddRow("TOO_FAR", 4).
+			AddRow("WRONG_DIRECTION", 1))
+
+	counts, err := repo.CountRejectionReasons(context.Background(), since)
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[models.MatchRejectionReason]int{
+		models.RejectionReasonTooFar:         4,
+		models.RejectionReasonWrongDirection: 1,
+	}, counts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// TestListMatchesByDriver tests listing a page of matches for a driver
+func TestListMatchesByDriver_Success(t *testing.T) {
+	// Arrange
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	driverID := uuid.New()
+	now := time.Now()
+	limit, offset := 2, 0
+
+	matchRows := sqlmock.NewRows([]string{
+		"id", "driver_id", "passenger_id",
+		"driver_longitude", "driver_latitude",
+		"passenger_longitude", "passenger_latitude",
+		"target_longitude", "target_latitude",
+		"status", "driver_confirmed", "passenger_confirmed",
+		"created_at", "updated_at"})
+
+	matchID1 := uuid.New()
+	matchID2 := uuid.New()
+
+	matchRows.AddRow(
+		matchID1, driverID, uuid.New(),
+		106.827153, -6.175392, 106.837153, -6.185392,
+		106.847153, -6.195392, // target location
+		models.MatchStatusAccepted, true, true, // confirmation flags
+		now, now)
+
 	matchRows.AddRow(
-		matchID3, driverID3, passengerID,
+		matchID2, driverID, uuid.New(),
 		106.827153, -6.175392, 106.837153, -6.185392,
 		106.847153, -6.195392, // target location
 		models.MatchStatusRejected, false, false, // confirmation flags
@@ -306,26 +521,23 @@ func TestListMatchesByPassenger_Success(t *testing.T) {
             status, driver_confirmed, passenger_confirmed,
             created_at, updated_at
         FROM matches
-        WHERE passenger_id = $1
+        WHERE driver_id = $1
         ORDER BY created_at DESC
-    `)).WithArgs(passengerID).WillReturnRows(matchRows)
+        LIMIT $2 OFFSET $3
+    `)).WithArgs(driverID, limit, offset).WillReturnRows(matchRows)
 
 	// Act
 	ctx := context.Background()
-	matches, err := repo.ListMatchesByPassenger(ctx, passengerID)
+	matches, err := repo.ListMatchesByDriver(ctx, driverID, limit, offset)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.Len(t, matches, 3, "Should return 3 matches")
+	assert.Len(t, matches, 2, "Should return 2 matches")
 	assert.Equal(t, matchID1, matches[0].ID)
 	assert.Equal(t, matchID2, matches[1].ID)
-	assert.Equal(t, matchID3, matches[2].ID)
-	assert.Equal(t, driverID1, matches[0].DriverID)
-	assert.Equal(t, driverID2, matches[1].DriverID)
-	assert.Equal(t, driverID3, matches[2].DriverID)
+	assert.Equal(t, driverID, matches[0].DriverID)
 	assert.Equal(t, models.MatchStatusAccepted, matches[0].Status)
-	assert.Equal(t, models.MatchStatusPending, matches[1].Status)
-	assert.Equal(t, models.MatchStatusRejected, matches[2].Status)
+	assert.Equal(t, models.MatchStatusRejected, matches[1].Status)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -359,7 +571,7 @@ func TestGetMatch_NotFound(t *testing.T) {
 			(passenger_location[1])::float8 as passenger_latitude,
 			(target_location[0])::float8 as target_longitude,
 			(target_location[1])::float8 as target_latitude,
-			status, driver_confirmed, passenger_confirmed,
+			status, driver_confirmed, passenger_confirmed, payment_method,
 			created_at, updated_at
 		FROM matches
 		WHERE id = $1
@@ -521,8 +733,8 @@ func TestUpdateMatchStatus_NoRowsAffected(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-// TestListMatchesByPassenger_RowError tests error handling during row scanning
-func TestListMatchesByPassenger_RowError(t *testing.T) {
+// TestListOpenMatchesByPassenger_RowError tests error handling during row scanning
+func TestListOpenMatchesByPassenger_RowError(t *testing.T) {
 	// Arrange
 	db, mock := setupMockDB(t)
 	redisClient, miniRedis := setupMockRedis(t)
@@ -549,25 +761,13 @@ func TestListMatchesByPassenger_RowError(t *testing.T) {
 			"not-a-time", "not-a-time").
 		RowError(0, fmt.Errorf("scan error"))
 
-	mock.ExpectQuery(regexp.QuoteMeta(`
-        SELECT 
-            id, driver_id, passenger_id,
-            (driver_location[0])::float8 as driver_longitude,
-            (driver_location[1])::float8 as driver_latitude,
-            (passenger_location[0])::float8 as passenger_longitude,
-            (passenger_location[1])::float8 as passenger_latitude,
-            (target_location[0])::float8 as target_longitude,
-            (target_location[1])::float8 as target_latitude,
-            status, driver_confirmed, passenger_confirmed,
-            created_at, updated_at
-        FROM matches
-        WHERE passenger_id = $1
-        ORDER BY created_at DESC
-    `)).WithArgs(passengerID).WillReturnRows(rows)
+	mock.ExpectQuery(`FROM matches\s+WHERE passenger_id = \$1 AND status IN`).
+		WithArgs(passengerID).
+		WillReturnRows(rows)
 
 	// Act
 	ctx := context.Background()
-	matches, err := repo.ListMatchesByPassenger(ctx, passengerID)
+	matches, err := repo.ListOpenMatchesByPassenger(ctx, passengerID)
 
 	// Assert
 	assert.Error(t, err)
@@ -575,3 +775,688 @@ func TestListMatchesByPassenger_RowError(t *testing.T) {
 	assert.Contains(t, err.Error(), "error iterating matches")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+// TestRideLock_AcquireAndRelease tests that a held ride lock blocks re-acquisition until released
+func TestRideLock_AcquireAndRelease(t *testing.T) {
+	// Arrange
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	// Act & Assert - first acquire succeeds
+	acquired, err := repo.AcquireRideLock(ctx, userID, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	locked, err := repo.IsRideLocked(ctx, userID)
+	assert.NoError(t, err)
+	assert.True(t, locked)
+
+	// Second acquire fails while the lock is held
+	acquired, err = repo.AcquireRideLock(ctx, userID, time.Minute)
+	assert.NoError(t, err)
+	assert.False(t, acquired)
+
+	// Release frees the lock
+	assert.NoError(t, repo.ReleaseRideLock(ctx, userID))
+	locked, err = repo.IsRideLocked(ctx, userID)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+}
+
+// TestIsInMatchingPool tests that pool membership is read from the role's available set
+func TestIsInMatchingPool(t *testing.T) {
+	// Arrange
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+	userID := uuid.New().String()
+	_, err := miniRedis.SAdd(constants.KeyAvailableDrivers, userID)
+	require.NoError(t, err)
+
+	// Act & Assert - only the driver set holds the user
+	inPool, err := repo.IsInMatchingPool(ctx, userID, true)
+	assert.NoError(t, err)
+	assert.True(t, inPool)
+
+	inPool, err = repo.IsInMatchingPool(ctx, userID, false)
+	assert.NoError(t, err)
+	assert.False(t, inPool)
+}
+
+// TestRideLock_Expires tests that a ride lock expires after its TTL
+func TestRideLock_Expires(t *testing.T) {
+	// Arrange
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+	userID := uuid.New().String()
+
+	acquired, err := repo.AcquireRideLock(ctx, userID, 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, acquired)
+
+	// Act
+	miniRedis.FastForward(31 * time.Second)
+
+	// Assert
+	locked, err := repo.IsRideLocked(ctx, userID)
+	assert.NoError(t, err)
+	assert.False(t, locked)
+}
+
+func TestBatchUpdateMatchStatus_ReturnsTransitionedIDs(t *testing.T) {
+	// Arrange
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	pendingID := uuid.New()
+	confirmedID := uuid.New()
+	acceptedID := uuid.New() // already terminal, filtered out by the status guard
+
+	// Only the pending and driver-confirmed matches satisfy the status guard and come back
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE id = ANY($3) AND status IN ('PENDING', 'DRIVER_CONFIRMED', 'PASSENGER_CONFIRMED')
+		RETURNING id`)).
+		WithArgs(models.MatchStatusRejected, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).
+			AddRow(pendingID).
+			AddRow(confirmedID))
+
+	// Act
+	updated, err := repo.BatchUpdateMatchStatus(context.Background(),
+		[]string{pendingID.String(), confirmedID.String(), acceptedID.String()}, models.MatchStatusRejected, models.OpenMatchStatuses)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []string{pendingID.String(), confirmedID.String()}, updated)
+	assert.NotContains(t, updated, acceptedID.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchUpdateMatchStatus_NoneEligible(t *testing.T) {
+	// Arrange
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE matches")).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	// Act
+	updated, err := repo.BatchUpdateMatchStatus(context.Background(), []string{uuid.New().String()}, models.MatchStatusRejected, models.OpenMatchStatuses)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, updated)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBatchUpdateMatchStatus_InvalidID(t *testing.T) {
+	// Arrange
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	// Act
+	updated, err := repo.BatchUpdateMatchStatus(context.Background(), []string{"not-a-uuid"}, models.MatchStatusRejected, models.OpenMatchStatuses)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, updated)
+}
+
+func TestSetActiveRide_KeyPrefixIsolatesEnvironments(t *testing.T) {
+	db, _ := setupMockDB(t)
+	mr, err := miniredis.Run()
+	assert.NoError(t, err)
+	defer mr.Close()
+
+	shared := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	prodRepo := NewMatchRepository(&models.Config{}, db, &database.RedisClient{Client: shared, Prefix: "prod:"})
+	stagingRepo := NewMatchRepository(&models.Config{}, db, &database.RedisClient{Client: shared, Prefix: "staging:"})
+
+	ctx := context.Background()
+	assert.NoError(t, prodRepo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-prod"))
+
+	// Staging shares the Redis instance but must not see production's active ride
+	rideID, err := stagingRepo.GetActiveRideByDriver(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Empty(t, rideID)
+
+	rideID, err = stagingRepo.GetActiveRideByPassenger(ctx, "passenger-1")
+	assert.NoError(t, err)
+	assert.Empty(t, rideID)
+
+	assert.NoError(t, stagingRepo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-staging"))
+
+	rideID, err = prodRepo.GetActiveRideByDriver(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ride-prod", rideID)
+
+	rideID, err = stagingRepo.GetActiveRideByDriver(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ride-staging", rideID)
+}
+
+func TestSetActiveRide_Redelivery(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-1"))
+	miniRedis.FastForward(time.Hour)
+
+	// A redelivered pickup event leaves the keys and their expiry untouched
+	assert.NoError(t, repo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-1"))
+	assert.Equal(t, 23*time.Hour, miniRedis.TTL("active_ride:driver:driver-1"))
+	assert.Equal(t, 23*time.Hour, miniRedis.TTL("active_ride:passenger:passenger-1"))
+
+	// A different ride still replaces the previous one
+	assert.NoError(t, repo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-2"))
+	rideID, err := repo.GetActiveRideByDriver(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ride-2", rideID)
+	assert.Equal(t, 24*time.Hour, miniRedis.TTL("active_ride:driver:driver-1"))
+
+	// Only one side matching is repaired rather than skipped
+	miniRedis.Del("active_ride:passenger:passenger-1")
+	assert.NoError(t, repo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-2"))
+	rideID, err = repo.GetActiveRideByPassenger(ctx, "passenger-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ride-2", rideID)
+}
+
+func TestClaimMatchProposal_DedupWindow(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	claimed, err := repo.ClaimMatchProposal(ctx, "passenger-1", "driver-1", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A repeat within the window is suppressed
+	claimed, err = repo.ClaimMatchProposal(ctx, "passenger-1", "driver-1", 30*time.Second)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Other pairs are independent
+	claimed, err = repo.ClaimMatchProposal(ctx, "passenger-1", "driver-2", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Once the window elapses the driver can be proposed again
+	miniRedis.FastForward(31 * time.Second)
+	claimed, err = repo.ClaimMatchProposal(ctx, "passenger-1", "driver-1", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestReleaseMatchProposal(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	claimed, err := repo.ClaimMatchProposal(ctx, "passenger-1", "driver-1", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// A released claim lets the driver be proposed again within the window
+	assert.NoError(t, repo.ReleaseMatchProposal(ctx, "passenger-1", "driver-1"))
+	claimed, err = repo.ClaimMatchProposal(ctx, "passenger-1", "driver-1", 30*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestClaimProposalRefresh_Throttle(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	claimed, err := repo.ClaimProposalRefresh(ctx, "passenger-1", 10*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Another refresh within the interval is dropped
+	claimed, err = repo.ClaimProposalRefresh(ctx, "passenger-1", 10*time.Second)
+	assert.NoError(t, err)
+	assert.False(t, claimed)
+
+	// Each passenger is throttled separately
+	claimed, err = repo.ClaimProposalRefresh(ctx, "passenger-2", 10*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+
+	miniRedis.FastForward(11 * time.Second)
+	claimed, err = repo.ClaimProposalRefresh(ctx, "passenger-1", 10*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, claimed)
+}
+
+func TestScheduleProposalRefresh_DueWhenClaimExpires(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+	now := time.Now()
+
+	claimed, err := repo.ClaimProposalRefresh(ctx, "passenger-1", 10*time.Second)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	// Throttled twice within the interval, the refresh is queued once for when the claim expires
+	require.NoError(t, repo.ScheduleProposalRefresh(ctx, "passenger-1", now))
+	require.NoError(t, repo.ScheduleProposalRefresh(ctx, "passenger-1", now))
+
+	due, err := repo.ClaimDueProposalRefreshes(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+
+	due, err = repo.ClaimDueProposalRefreshes(ctx, now.Add(10*time.Second), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"passenger-1"}, due)
+
+	// Claiming removed it
+	due, err = repo.ClaimDueProposalRefreshes(ctx, now.Add(10*time.Second), 10)
+	require.NoError(t, err)
+	assert.Empty(t, due)
+}
+
+func TestMaintenanceMode_Toggle(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	enabled, err := repo.GetMaintenanceMode(ctx)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	assert.NoError(t, repo.SetMaintenanceMode(ctx, true))
+	enabled, err = repo.GetMaintenanceMode(ctx)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+
+	assert.NoError(t, repo.SetMaintenanceMode(ctx, false))
+	enabled, err = repo.GetMaintenanceMode(ctx)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+}
+
+func TestClaimDueScheduledFinderEvents_ReleasedAtScheduledTime(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	scheduledAt := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	event := models.FinderEvent{
+		UserID:      "passenger-1",
+		IsActive:    true,
+		Location:    models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		ScheduledAt: &scheduledAt,
+	}
+	assert.NoError(t, repo.ScheduleFinderEvent(ctx, event, scheduledAt))
+
+	// Nothing is due before the scheduled time
+	due, err := repo.ClaimDueScheduledFinderEvents(ctx, scheduledAt.Add(-time.Second), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	// At the scheduled time the event is returned once
+	due, err = repo.ClaimDueScheduledFinderEvents(ctx, scheduledAt, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, due, 1) {
+		assert.Equal(t, "passenger-1", due[0].UserID)
+		assert.Equal(t, event.Location, due[0].Location)
+	}
+
+	due, err = repo.ClaimDueScheduledFinderEvents(ctx, scheduledAt.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+	assert.False(t, miniRedis.Exists("match:scheduled:passenger-1"))
+}
+
+func TestCancelScheduledFinderEvent(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	scheduledAt := time.Now().Add(time.Hour)
+	event := models.FinderEvent{UserID: "passenger-1", IsActive: true, ScheduledAt: &scheduledAt}
+	assert.NoError(t, repo.ScheduleFinderEvent(ctx, event, scheduledAt))
+
+	assert.NoError(t, repo.CancelScheduledFinderEvent(ctx, "passenger-1"))
+
+	due, err := repo.ClaimDueScheduledFinderEvents(ctx, scheduledAt.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Empty(t, due)
+
+	// Cancelling when nothing is scheduled is not an error
+	assert.NoError(t, repo.CancelScheduledFinderEvent(ctx, "passenger-2"))
+}
+
+func TestCountDriverOutcomes_RollingWindow(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+	now := time.Now()
+	window := 24 * time.Hour
+
+	assert.NoError(t, repo.RecordDriverAccepted(ctx, "driver-1", "match-old", now.Add(-30*time.Hour), window))
+	assert.NoError(t, repo.RecordDriverAccepted(ctx, "driver-1", "match-1", now.Add(-time.Hour), window))
+	assert.NoError(t, repo.RecordDriverAccepted(ctx, "driver-1", "match-2", now, window))
+	assert.NoError(t, repo.RecordDriverCancelled(ctx, "driver-1", "ride-1", now, window))
+
+	// Outcomes older than the window are not counted
+	accepted, cancelled, err := repo.CountDriverOutcomes(ctx, "driver-1", now.Add(-window))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, accepted)
+	assert.Equal(t, 1, cancelled)
+}
+
+func TestSuspendDriver_ReinstatedAfterPenalty(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	remaining, err := repo.GetDriverSuspension(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Zero(t, remaining)
+
+	assert.NoError(t, repo.SuspendDriver(ctx, "driver-1", 30*time.Minute))
+	remaining, err = repo.GetDriverSuspension(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Greater(t, remaining, time.Duration(0))
+
+	miniRedis.FastForward(30 * time.Minute)
+	remaining, err = repo.GetDriverSuspension(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Zero(t, remaining)
+}
+
+func TestRemoveActiveRide(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-1"))
+	assert.NoError(t, repo.RemoveActiveRide(ctx, "driver-1", "passenger-1"))
+	assert.False(t, miniRedis.Exists(fmt.Sprintf(constants.KeyActiveRideDriver, "driver-1")))
+	assert.False(t, miniRedis.Exists(fmt.Sprintf(constants.KeyActiveRidePassenger, "passenger-1")))
+
+	// Removing again succeeds, so a redelivered cleanup is harmless
+	assert.NoError(t, repo.RemoveActiveRide(ctx, "driver-1", "passenger-1"))
+
+	// A failed removal is reported so the caller can retry
+	miniRedis.SetError("connection lost")
+	assert.Error(t, repo.RemoveActiveRide(ctx, "driver-1", "passenger-1"))
+}
+
+func TestRemoveActiveRide_KeepsDriverOnNextRide(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	assert.NoError(t, repo.SetActiveRide(ctx, "driver-1", "passenger-1", "ride-2"))
+
+	// Without a driver ID only the passenger is released
+	assert.NoError(t, repo.RemoveActiveRide(ctx, "", "passenger-1"))
+	rideID, err := repo.GetActiveRideByDriver(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ride-2", rideID)
+	assert.False(t, miniRedis.Exists(fmt.Sprintf(constants.KeyActiveRidePassenger, "passenger-1")))
+}
+
+func TestDriverFinishingRide_MarkGetClear(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	// Drivers who haven't opted in have no finishing ride
+	rideID, err := repo.GetDriverFinishingRide(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Empty(t, rideID)
+
+	assert.NoError(t, repo.MarkDriverFinishingRide(ctx, "driver-1", "ride-1", 30*time.Minute))
+	rideID, err = repo.GetDriverFinishingRide(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "ride-1", rideID)
+	assert.Equal(t, 30*time.Minute, miniRedis.TTL(fmt.Sprintf(constants.KeyDriverFinishingRide, "driver-1")))
+
+	assert.NoError(t, repo.ClearDriverFinishingRide(ctx, "driver-1"))
+	rideID, err = repo.GetDriverFinishingRide(ctx, "driver-1")
+	assert.NoError(t, err)
+	assert.Empty(t, rideID)
+}
+
+func TestDriverLastRide_RecordAndGet(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	completedAt := time.Unix(1760000000, 0)
+	assert.NoError(t, repo.RecordDriverRideCompleted(ctx, "driver-1", completedAt))
+
+	// Drivers without a completed ride are left out
+	lastRides, err := repo.GetDriversLastRideAt(ctx, []string{"driver-1", "driver-2"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"driver-1": completedAt}, lastRides)
+
+	lastRides, err = repo.GetDriversLastRideAt(ctx, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, lastRides)
+}
+
+func TestPoolRemovals_QueueListComplete(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	driver := models.PoolRemoval{Role: "driver", UserID: "driver-1"}
+	passenger := models.PoolRemoval{Role: "passenger", UserID: "passenger-1"}
+	require.NoError(t, repo.QueuePoolRemoval(ctx, driver))
+	require.NoError(t, repo.QueuePoolRemoval(ctx, passenger))
+	// Queueing a user twice keeps one entry
+	require.NoError(t, repo.QueuePoolRemoval(ctx, driver))
+
+	removals, err := repo.ListPoolRemovals(ctx, 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []models.PoolRemoval{driver, passenger}, removals)
+
+	require.NoError(t, repo.CompletePoolRemoval(ctx, driver))
+
+	removals, err = repo.ListPoolRemovals(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.PoolRemoval{passenger}, removals)
+}
+
+func TestAutoRejections_QueueListComplete(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	first := models.AutoRejection{PassengerID: uuid.NewString(), MatchID: uuid.NewString()}
+	second := models.AutoRejection{PassengerID: uuid.NewString(), MatchID: uuid.NewString()}
+	require.NoError(t, repo.QueueAutoRejection(ctx, first))
+	require.NoError(t, repo.QueueAutoRejection(ctx, second))
+	// Queueing a match twice keeps one entry
+	require.NoError(t, repo.QueueAutoRejection(ctx, first))
+
+	rejections, err := repo.ListAutoRejections(ctx, 10)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []models.AutoRejection{first, second}, rejections)
+
+	require.NoError(t, repo.CompleteAutoRejection(ctx, first))
+
+	rejections, err = repo.ListAutoRejections(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.AutoRejection{second}, rejections)
+}
+
+func TestDropAutoRejectionsBefore(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	now := time.Now()
+	expired := models.AutoRejection{PassengerID: uuid.NewString(), MatchID: uuid.NewString(), QueuedAt: now.Add(-10 * time.Minute)}
+	recent := models.AutoRejection{PassengerID: uuid.NewString(), MatchID: uuid.NewString(), QueuedAt: now}
+	require.NoError(t, repo.QueueAutoRejection(ctx, expired))
+	require.NoError(t, repo.QueueAutoRejection(ctx, recent))
+
+	require.NoError(t, repo.DropAutoRejectionsBefore(ctx, now.Add(-time.Minute)))
+
+	rejections, err := repo.ListAutoRejections(ctx, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []models.AutoRejection{{PassengerID: recent.PassengerID, MatchID: recent.MatchID}}, rejections)
+}
+
+func TestAcceptanceWindows_OpenOnceAndClaimWhenDue(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+	now := time.Now()
+
+	opened, err := repo.OpenAcceptanceWindow(ctx, "passenger-1", now.Add(10*time.Second))
+	require.NoError(t, err)
+	assert.True(t, opened)
+
+	// A later acceptance joins the open window instead of extending it
+	opened, err = repo.OpenAcceptanceWindow(ctx, "passenger-1", now.Add(20*time.Second))
+	require.NoError(t, err)
+	assert.False(t, opened)
+
+	claimed, err := repo.ClaimDueAcceptanceWindows(ctx, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	claimed, err = repo.ClaimDueAcceptanceWindows(ctx, now.Add(10*time.Second), 10)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"passenger-1"}, claimed)
+
+	// Each window is claimed once
+	claimed, err = repo.ClaimDueAcceptanceWindows(ctx, now.Add(time.Minute), 10)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+}
+
+func TestOpenAcceptanceWindow_ExpiresByRepoClock(t *testing.T) {
+	db, _ := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+	start := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+	repo.clock = clock.NewMock(start)
+
+	// The window lives until closesAt as seen by the repository clock, not the wall clock
+	opened, err := repo.OpenAcceptanceWindow(context.Background(), "passenger-1", start.Add(15*time.Second))
+	require.NoError(t, err)
+	assert.True(t, opened)
+	assert.Equal(t, 15*time.Second, miniRedis.TTL(fmt.Sprintf(constants.KeyAcceptanceWindow, "passenger-1")))
+}
+
+func TestListExpiredProposals(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, miniRedis := setupMockRedis(t)
+	defer miniRedis.Close()
+
+	repo := NewMatchRepository(&models.Config{}, db, redisClient)
+
+	cutoff := time.Now().Add(-30 * time.Second)
+	matchID := uuid.New()
+	createdAt := cutoff.Add(-time.Second)
+
+	rows := sqlmock.NewRows([]string{
+		"id", "driver_id", "passenger_id",
+		"driver_longitude", "driver_latitude",
+		"passenger_longitude", "passenger_latitude",
+		"target_longitude", "target_latitude",
+		"status", "driver_confirmed", "passenger_confirmed",
+		"created_at", "updated_at"}).
+		AddRow(matchID, uuid.New(), uuid.New(),
+			106.827153, -6.175392, 106.837153, -6.185392, 106.847153, -6.195392,
+			models.MatchStatusPending, false, false, createdAt, createdAt)
+
+	// Only proposals nobody answered can lapse
+	mock.ExpectQuery(regexp.QuoteMeta("WHERE status = $1 AND created_at <= $2")).
+		WithArgs(models.MatchStatusPending, cutoff, 100).
+		WillReturnRows(rows)
+
+	matches, err := repo.ListExpiredProposals(context.Background(), cutoff, 100)
+
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, matchID, matches[0].ID)
+	assert.Equal(t, createdAt, matches[0].CreatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,19 +2,39 @@ package match
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
+// ErrPendingMatchLimit is returned when a passenger already holds the maximum number of unanswered matches
+var ErrPendingMatchLimit = errors.New("passenger has reached the maximum number of pending matches")
+
+// ErrSelfMatch is returned when a match would pair a user with themselves
+var ErrSelfMatch = errors.New("driver and passenger must be different users")
+
+// ErrMaintenanceMode is returned when new matching is refused because the system is in maintenance
+var ErrMaintenanceMode = errors.New("system in maintenance")
+
+// ErrConfirmationConflict is returned when a user's confirmation contradicts a decision already made
+// on the match, such as rejecting a match they accepted
+var ErrConfirmationConflict = errors.New("match confirmation conflicts with its current status")
+
+// ErrMatchNotFound is returned when no match has the requested ID
+var ErrMatchNotFound = errors.New("match not found")
+
 //go:generate mockgen -destination=mocks/mock_usecase.go -package=mocks github.com/piresc/nebengjek/services/match MatchUC
 
 // MatchUC defines the interface for match business logic
 type MatchUC interface {
 	HandleBeaconEvent(ctx context.Context, event models.BeaconEvent) error
 	HandleFinderEvent(ctx context.Context, event models.FinderEvent) error
+	HandleDriverLocationUpdate(ctx context.Context, update models.LocationUpdate)
 	ConfirmMatchStatus(ctx context.Context, req *models.MatchConfirmRequest) (models.MatchProposal, error)
 	GetMatch(ctx context.Context, matchID string) (*models.Match, error)
 	GetPendingMatch(ctx context.Context, matchID string) (*models.Match, error)
+	GetDriverMatches(ctx context.Context, driverID string, limit, offset int) (*models.MatchHistory, error)
 	RemoveDriverFromPool(ctx context.Context, driverID string) error
 	RemovePassengerFromPool(ctx context.Context, passengerID string) error
 
@@ -22,4 +42,39 @@ type MatchUC interface {
 	SetActiveRide(ctx context.Context, driverID, passengerID, rideID string) error
 	RemoveActiveRide(ctx context.Context, driverID, passengerID string) error
 	HasActiveRide(ctx context.Context, userID string, isDriver bool) (bool, error)
+	GetActiveRideID(ctx context.Context, userID string, isDriver bool) (string, error)
+	LockUsersForRide(ctx context.Context, driverID, passengerID string) error
+	ReleaseRideLocks(ctx context.Context, driverID, passengerID string) error
+
+	// Driver cancellation tracking
+	HandleDriverCancellation(ctx context.Context, driverID, rideID string) error
+	GetDriverCancellationStats(ctx context.Context, driverID string) (*models.DriverCancellationStats, error)
+	GetRejectionReasonStats(ctx context.Context, since time.Time) (*models.MatchRejectionStats, error)
+
+	// Debugging
+	GetMatchDebug(ctx context.Context, matchID string) (*models.MatchDebugView, error)
+
+	// Re-sent proposals
+	RefreshDueProposals(ctx context.Context, now time.Time, limit int) (int, error)
+	RunProposalRefreshes(ctx context.Context, interval time.Duration)
+
+	// Fairness rotation
+	RecordDriverRideCompleted(ctx context.Context, driverID string, at time.Time) error
+
+	// Back-to-back rides
+	DriverHasNextRide(ctx context.Context, driverID, finishedRideID string) (bool, error)
+	DriverAwaitsNextRide(ctx context.Context, driverID, finishedRideID string) (bool, error)
+	ClearBackToBackOptIn(ctx context.Context, driverID, finishedRideID string) error
+
+	// Maintenance mode
+	IsMaintenanceMode(ctx context.Context) bool
+	SetMaintenanceMode(ctx context.Context, enabled bool) error
+	WatchMaintenanceMode(ctx context.Context, interval time.Duration)
+
+	// Scheduled rides
+	ReleaseDueScheduledFinders(ctx context.Context, now time.Time, limit int) (int, error)
+	RunScheduler(ctx context.Context, interval time.Duration, batchSize int)
+
+	// Runtime config reload
+	ReloadConfig(reloaded *models.Config)
 }
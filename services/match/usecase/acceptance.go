@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
+)
+
+const (
+	// acceptanceWindowPollInterval is how often closed acceptance windows are picked up when none is given
+	acceptanceWindowPollInterval = time.Second
+	// acceptanceWindowBatchSize is the most acceptance windows closed per run
+	acceptanceWindowBatchSize = 100
+)
+
+// acceptanceWindow returns how long driver acceptances are gathered before the passenger chooses.
+// Zero keeps first-wins, where the first match both sides confirm is accepted.
+func (uc *MatchUC) acceptanceWindow() time.Duration {
+	return time.Duration(uc.config().Match.AcceptanceWindowSecs) * time.Second
+}
+
+// checkPassengerChoice refuses a passenger's acceptance of a match whose driver hasn't accepted yet
+// while acceptance windows are on, since the passenger chooses among the drivers who accepted
+func (uc *MatchUC) checkPassengerChoice(m *models.Match, isDriver bool) error {
+	if isDriver || uc.acceptanceWindow() <= 0 || m.DriverConfirmed {
+		return nil
+	}
+	return fmt.Errorf("%w: the driver has not accepted this match yet", match.ErrConfirmationConflict)
+}
+
+// openAcceptanceWindow starts gathering driver acceptances for the passenger of a match its driver
+// accepted. Acceptances arriving while a window is open join it.
+func (uc *MatchUC) openAcceptanceWindow(ctx context.Context, m *models.Match) {
+	closesAt := uc.clock.Now().Add(uc.acceptanceWindow())
+	opened, err := uc.matchRepo.OpenAcceptanceWindow(ctx, m.PassengerID.String(), closesAt)
+	if err != nil {
+		logger.Error("Failed to open acceptance window",
+			logger.String("match_id", m.ID.String()),
+			logger.String("passenger_id", m.PassengerID.String()),
+			logger.ErrorField(err))
+		return
+	}
+
+	if opened {
+		logger.Info("Opened acceptance window",
+			logger.String("passenger_id", m.PassengerID.String()),
+			logger.String("closes_at", closesAt.Format(time.RFC3339)))
+	}
+}
+
+// driverAcceptanceOptions returns the passenger's matches whose drivers accepted, nearest driver first
+func (uc *MatchUC) driverAcceptanceOptions(matches []*models.Match) []models.MatchProposal {
+	options := make([]models.MatchProposal, 0, len(matches))
+	for _, m := range matches {
+		if m.Status != models.MatchStatusDriverConfirmed {
+			continue
+		}
+
+		option := uc.buildMatchProposal(m)
+		option.PickupDistanceKm = pickupDistanceKm(m)
+		options = append(options, option)
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].PickupDistanceKm < options[j].PickupDistanceKm
+	})
+	return options
+}
+
+// presentDriverAcceptances publishes the drivers who accepted a passenger's request for the
+// passenger to choose from. Nothing is published once the passenger has already chosen.
+func (uc *MatchUC) presentDriverAcceptances(ctx context.Context, passengerID string, now time.Time) error {
+	passengerUUID, err := uuid.Parse(passengerID)
+	if err != nil {
+		return fmt.Errorf("invalid passenger ID: %w", err)
+	}
+
+	matches, err := uc.matchRepo.ListOpenMatchesByPassenger(ctx, passengerUUID)
+	if err != nil {
+		return fmt.Errorf("failed to list passenger matches: %w", err)
+	}
+
+	options := uc.driverAcceptanceOptions(matches)
+	if len(options) == 0 {
+		logger.Info("No driver acceptances left to present",
+			logger.String("passenger_id", passengerID))
+		return nil
+	}
+
+	return uc.matchGW.PublishDriverAcceptances(ctx, models.DriverAcceptancesEvent{
+		PassengerID: passengerID,
+		Options:     options,
+		Timestamp:   now,
+	})
+}
+
+// CloseDueAcceptanceWindows presents the gathered driver acceptances of up to limit passengers whose
+// acceptance window closed at or before now, returning how many windows were closed
+func (uc *MatchUC) CloseDueAcceptanceWindows(ctx context.Context, now time.Time, limit int) (int, error) {
+	passengerIDs, err := uc.matchRepo.ClaimDueAcceptanceWindows(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, passengerID := range passengerIDs {
+		if err := uc.presentDriverAcceptances(ctx, passengerID, now); err != nil {
+			logger.Error("Failed to present driver acceptances",
+				logger.String("passenger_id", passengerID),
+				logger.ErrorField(err))
+		}
+	}
+	return len(passengerIDs), nil
+}
+
+// RunAcceptanceWindows periodically closes due acceptance windows until ctx is cancelled
+func (uc *MatchUC) RunAcceptanceWindows(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = acceptanceWindowPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Acceptance window closer stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.CloseDueAcceptanceWindows(ctx, uc.clock.Now(), acceptanceWindowBatchSize); err != nil {
+				logger.Error("Closing acceptance windows failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// windowConfig gathers driver acceptances for 15 seconds
+func windowConfig() *models.Config {
+	return &models.Config{Match: models.MatchConfig{AcceptanceWindowSecs: 15}}
+}
+
+// driverAtKm returns a pending match whose driver is roughly km kilometers north of the passenger
+func driverAtKm(passengerID uuid.UUID, km float64, status models.MatchStatus) *models.Match {
+	pickup := models.Location{Latitude: -6.2, Longitude: 106.8}
+	return &models.Match{
+		ID:                uuid.New(),
+		DriverID:          uuid.New(),
+		PassengerID:       passengerID,
+		PassengerLocation: pickup,
+		DriverLocation:    models.Location{Latitude: pickup.Latitude + km/111.2, Longitude: pickup.Longitude},
+		DriverConfirmed:   status == models.MatchStatusDriverConfirmed,
+		Status:            status,
+	}
+}
+
+func TestConfirmMatchStatus_DriverAcceptanceOpensWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(windowConfig(), mockRepo, mockGW)
+
+	pending := driverAtKm(uuid.New(), 1, models.MatchStatusPending)
+	driverID := pending.DriverID.String()
+	confirmed := *pending
+	confirmed.DriverConfirmed = true
+	confirmed.Status = models.MatchStatusDriverConfirmed
+
+	mockRepo.EXPECT().GetMatch(gomock.Any(), pending.ID.String()).Return(pending, nil)
+	mockRepo.EXPECT().ConfirmMatchByUser(gomock.Any(), pending.ID.String(), driverID, true).Return(&confirmed, nil)
+
+	before := time.Now()
+	mockRepo.EXPECT().
+		OpenAcceptanceWindow(gomock.Any(), pending.PassengerID.String(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, closesAt time.Time) (bool, error) {
+			assert.WithinDuration(t, before.Add(15*time.Second), closesAt, time.Second)
+			return true, nil
+		})
+
+	proposal, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     pending.ID.String(),
+		UserID: driverID,
+		Status: string(models.MatchStatusAccepted),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusDriverConfirmed, proposal.MatchStatus)
+}
+
+func TestConfirmMatchStatus_PassengerCannotAcceptBeforeDriverDuringWindow(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(windowConfig(), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	pending := driverAtKm(uuid.New(), 1, models.MatchStatusPending)
+	mockRepo.EXPECT().GetMatch(gomock.Any(), pending.ID.String()).Return(pending, nil)
+
+	_, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     pending.ID.String(),
+		UserID: pending.PassengerID.String(),
+		Status: string(models.MatchStatusAccepted),
+	})
+
+	assert.True(t, errors.Is(err, match.ErrConfirmationConflict))
+}
+
+func TestCheckPassengerChoice_FirstWinsByDefault(t *testing.T) {
+	uc := NewMatchUC(&models.Config{}, nil, nil)
+	pending := driverAtKm(uuid.New(), 1, models.MatchStatusPending)
+
+	assert.NoError(t, uc.checkPassengerChoice(pending, false))
+}
+
+func TestCloseDueAcceptanceWindows_PresentsAcceptancesNearestFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(windowConfig(), mockRepo, mockGW)
+
+	passengerID := uuid.New()
+	far := driverAtKm(passengerID, 3, models.MatchStatusDriverConfirmed)
+	near := driverAtKm(passengerID, 1, models.MatchStatusDriverConfirmed)
+	unanswered := driverAtKm(passengerID, 0.5, models.MatchStatusPending)
+	now := time.Now()
+
+	mockRepo.EXPECT().
+		ClaimDueAcceptanceWindows(gomock.Any(), now, 10).
+		Return([]string{passengerID.String()}, nil)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return([]*models.Match{far, unanswered, near}, nil)
+
+	mockGW.EXPECT().
+		PublishDriverAcceptances(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event models.DriverAcceptancesEvent) error {
+			assert.Equal(t, passengerID.String(), event.PassengerID)
+			require.Len(t, event.Options, 2)
+			assert.Equal(t, near.ID.String(), event.Options[0].ID)
+			assert.Equal(t, far.ID.String(), event.Options[1].ID)
+			assert.InDelta(t, 1.0, event.Options[0].PickupDistanceKm, 0.01)
+			return nil
+		})
+
+	closed, err := uc.CloseDueAcceptanceWindows(context.Background(), now, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, closed)
+}
+
+func TestCloseDueAcceptanceWindows_SkipsPassengerWhoAlreadyChose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(windowConfig(), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	passengerID := uuid.New()
+	chosen := driverAtKm(passengerID, 1, models.MatchStatusAccepted)
+
+	mockRepo.EXPECT().
+		ClaimDueAcceptanceWindows(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]string{passengerID.String()}, nil)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return([]*models.Match{chosen}, nil)
+
+	// Nothing is published
+	closed, err := uc.CloseDueAcceptanceWindows(context.Background(), time.Now(), 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, closed)
+}
+
+func TestConfirmMatchStatus_PassengerChoiceRejectsOtherAcceptances(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(windowConfig(), mockRepo, mockGW)
+
+	passengerID := uuid.New()
+	chosen := driverAtKm(passengerID, 3, models.MatchStatusDriverConfirmed)
+	other := driverAtKm(passengerID, 1, models.MatchStatusDriverConfirmed)
+	accepted := *chosen
+	accepted.PassengerConfirmed = true
+	accepted.Status = models.MatchStatusAccepted
+
+	mockRepo.EXPECT().GetMatch(gomock.Any(), chosen.ID.String()).Return(chosen, nil)
+	mockRepo.EXPECT().
+		ConfirmMatchByUser(gomock.Any(), chosen.ID.String(), passengerID.String(), false).
+		Return(&accepted, nil)
+	mockGW.EXPECT().RemoveAvailableDriver(gomock.Any(), chosen.DriverID.String()).Return(nil)
+	mockGW.EXPECT().RemoveAvailablePassenger(gomock.Any(), passengerID.String()).Return(nil)
+	mockRepo.EXPECT().RecordDriverAccepted(gomock.Any(), chosen.DriverID.String(), chosen.ID.String(), gomock.Any(), gomock.Any()).Return(nil)
+
+	mockGW.EXPECT().
+		PublishMatchAccepted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			assert.Equal(t, chosen.DriverID.String(), mp.DriverID)
+			return nil
+		})
+
+	// The driver the passenger passed over is rejected in the background
+	rejected := make(chan string, 1)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return([]*models.Match{&accepted, other}, nil)
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{other.ID.String()}, models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return([]string{other.ID.String()}, nil)
+	mockGW.EXPECT().
+		PublishMatchRejected(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			rejected <- mp.DriverID
+			return nil
+		})
+
+	proposal, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     chosen.ID.String(),
+		UserID: passengerID.String(),
+		Status: string(models.MatchStatusAccepted),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusAccepted, proposal.MatchStatus)
+
+	select {
+	case driverID := <-rejected:
+		assert.Equal(t, other.DriverID.String(), driverID)
+	case <-time.After(time.Second):
+		t.Fatal("other acceptance was not rejected")
+	}
+}
@@ -0,0 +1,183 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	// defaultAutoRejectionAttempts is used when no auto-rejection attempt count is configured
+	defaultAutoRejectionAttempts = 4
+	// defaultAutoRejectionBackoff is used when no base auto-rejection backoff is configured
+	defaultAutoRejectionBackoff = 200 * time.Millisecond
+	// defaultAutoRejectionRetryInterval is used when no auto-rejection retry interval is configured
+	defaultAutoRejectionRetryInterval = 30 * time.Second
+	// autoRejectionBatchSize is the most queued auto-rejections retried per run
+	autoRejectionBatchSize = 100
+
+	// metricAutoRejectionExhausted counts auto-rejections that still failed after every retry
+	metricAutoRejectionExhausted = "Custom/Match/AutoRejection/Exhausted"
+)
+
+// autoRejectionAttempts returns how often an auto-rejection is tried before it is queued
+func (uc *MatchUC) autoRejectionAttempts() int {
+	if attempts := uc.config().Match.AutoRejectionAttempts; attempts > 0 {
+		return attempts
+	}
+	return defaultAutoRejectionAttempts
+}
+
+// autoRejectionBackoff returns the base delay between auto-rejection attempts
+func (uc *MatchUC) autoRejectionBackoff() time.Duration {
+	if ms := uc.config().Match.AutoRejectionBackoffMs; ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+	return defaultAutoRejectionBackoff
+}
+
+// autoRejectWithRetry rejects the passenger's other open matches once match is accepted, retrying
+// failures with jittered exponential backoff. If every attempt fails the match is queued for the
+// reconcile worker, so stale pending matches are still cleaned up eventually.
+func (uc *MatchUC) autoRejectWithRetry(ctx context.Context, match *models.Match, app *newrelic.Application) {
+	attempts := uc.autoRejectionAttempts()
+	backoff := uc.autoRejectionBackoff()
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		if err = uc.handleAutoRejectionForAcceptedMatch(ctx, match); err == nil {
+			return
+		}
+		if attempt == attempts-1 {
+			break
+		}
+
+		logger.Warn("Auto-rejection failed, retrying",
+			logger.String("match_id", match.ID.String()),
+			logger.Int("attempt", attempt+1),
+			logger.ErrorField(err))
+
+		// Full jitter keeps instances recovering from the same outage from retrying in lockstep
+		delay := time.Duration(rand.Int63n(int64(backoff<<attempt) + 1))
+		select {
+		case <-ctx.Done():
+		case <-time.After(delay):
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	logger.Error("Critical: Failed to handle auto-rejection for match, queueing for reconcile",
+		logger.String("match_id", match.ID.String()),
+		logger.String("passenger_id", match.PassengerID.String()),
+		logger.ErrorField(err))
+	app.RecordCustomMetric(metricAutoRejectionExhausted, 1)
+
+	// The retries may have used up ctx, but the queue entry must still be written
+	rejection := models.AutoRejection{
+		PassengerID: match.PassengerID.String(),
+		MatchID:     match.ID.String(),
+		QueuedAt:    uc.clock.Now(),
+	}
+	if err := uc.matchRepo.QueueAutoRejection(context.WithoutCancel(ctx), rejection); err != nil {
+		logger.Error("Failed to queue auto-rejection",
+			logger.String("match_id", rejection.MatchID),
+			logger.String("passenger_id", rejection.PassengerID),
+			logger.ErrorField(err))
+	}
+}
+
+// RetryAutoRejections retries queued auto-rejections and returns how many succeeded. Those that
+// fail again stay queued for the next run. Entries queued longer than the proposal TTL are dropped,
+// since the proposals they would reject have expired by then.
+func (uc *MatchUC) RetryAutoRejections(ctx context.Context) (int, error) {
+	if err := uc.matchRepo.DropAutoRejectionsBefore(ctx, uc.clock.Now().Add(-uc.proposalTTL())); err != nil {
+		return 0, err
+	}
+
+	rejections, err := uc.matchRepo.ListAutoRejections(ctx, autoRejectionBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, rejection := range rejections {
+		if _, err := uuid.Parse(rejection.MatchID); err != nil {
+			logger.Warn("Skipping auto-rejection with invalid match ID",
+				logger.String("match_id", rejection.MatchID),
+				logger.ErrorField(err))
+			continue
+		}
+
+		// The stored match carries the acceptance time, which bounds the matches that get rejected
+		accepted, err := uc.matchRepo.GetMatch(ctx, rejection.MatchID)
+		if err != nil {
+			logger.Warn("Auto-rejection retry failed to load accepted match",
+				logger.String("match_id", rejection.MatchID),
+				logger.ErrorField(err))
+			continue
+		}
+		if accepted.Status != models.MatchStatusAccepted {
+			logger.Info("Dropping auto-rejection for match that is no longer accepted",
+				logger.String("match_id", rejection.MatchID),
+				logger.String("status", string(accepted.Status)))
+			if err := uc.matchRepo.CompleteAutoRejection(ctx, rejection); err != nil {
+				logger.Error("Failed to complete auto-rejection",
+					logger.String("match_id", rejection.MatchID),
+					logger.String("passenger_id", rejection.PassengerID),
+					logger.ErrorField(err))
+			}
+			continue
+		}
+
+		if err := uc.handleAutoRejectionForAcceptedMatch(ctx, accepted); err != nil {
+			logger.Warn("Auto-rejection retry failed",
+				logger.String("match_id", rejection.MatchID),
+				logger.String("passenger_id", rejection.PassengerID),
+				logger.ErrorField(err))
+			continue
+		}
+
+		if err := uc.matchRepo.CompleteAutoRejection(ctx, rejection); err != nil {
+			logger.Error("Failed to complete auto-rejection",
+				logger.String("match_id", rejection.MatchID),
+				logger.String("passenger_id", rejection.PassengerID),
+				logger.ErrorField(err))
+			continue
+		}
+		completed++
+	}
+
+	if completed > 0 {
+		logger.Info("Retried auto-rejections", logger.Int("completed", completed))
+	}
+	return completed, nil
+}
+
+// RunAutoRejectionRetry periodically retries queued auto-rejections until ctx is cancelled
+func (uc *MatchUC) RunAutoRejectionRetry(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultAutoRejectionRetryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Auto-rejection retry stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.RetryAutoRejections(ctx); err != nil {
+				logger.Error("Auto-rejection retry run failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/clock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// autoRejectionConfig retries quickly so tests don't wait on the backoff
+func autoRejectionConfig() *models.Config {
+	return &models.Config{Match: models.MatchConfig{AutoRejectionAttempts: 3, AutoRejectionBackoffMs: 1}}
+}
+
+func TestAutoRejectWithRetry_RetriesThenSucceeds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(autoRejectionConfig(), mockRepo, mockGW)
+
+	passengerID := uuid.New()
+	accepted := &models.Match{ID: uuid.New(), PassengerID: passengerID, Status: models.MatchStatusAccepted}
+	pending := &models.Match{ID: uuid.New(), PassengerID: passengerID, Status: models.MatchStatusPending}
+
+	// The first attempt hits a database outage, the second goes through
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+			Return(nil, errors.New("connection refused")),
+		mockRepo.EXPECT().
+			ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+			Return([]*models.Match{accepted, pending}, nil),
+	)
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{pending.ID.String()}, models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return([]string{pending.ID.String()}, nil)
+	mockGW.EXPECT().PublishMatchRejected(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Nothing is queued for the reconcile worker
+	mockRepo.EXPECT().QueueAutoRejection(gomock.Any(), gomock.Any()).Times(0)
+
+	uc.autoRejectWithRetry(context.Background(), accepted, nil)
+}
+
+func TestAutoRejectWithRetry_QueuesWhenRetriesRunOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(autoRejectionConfig(), mockRepo, mockGW)
+	mockClock := clock.NewMock(time.Now())
+	uc.clock = mockClock
+
+	accepted := &models.Match{ID: uuid.New(), PassengerID: uuid.New(), Status: models.MatchStatusAccepted}
+
+	// Every configured attempt fails
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), accepted.PassengerID).
+		Return(nil, errors.New("connection refused")).
+		Times(3)
+
+	mockRepo.EXPECT().
+		QueueAutoRejection(gomock.Any(), models.AutoRejection{
+			PassengerID: accepted.PassengerID.String(),
+			MatchID:     accepted.ID.String(),
+			QueuedAt:    mockClock.Now(),
+		}).
+		Return(nil)
+
+	uc.autoRejectWithRetry(context.Background(), accepted, nil)
+}
+
+func TestAutoRejectWithRetry_QueuesWhenContextEnds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(autoRejectionConfig(), mockRepo, mockGW)
+
+	accepted := &models.Match{ID: uuid.New(), PassengerID: uuid.New(), Status: models.MatchStatusAccepted}
+
+	// The background timeout has passed, so the match is queued without further attempts
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	mockRepo.EXPECT().ListOpenMatchesByPassenger(gomock.Any(), gomock.Any()).Times(0)
+	mockRepo.EXPECT().
+		QueueAutoRejection(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ models.AutoRejection) error {
+			// The queue write isn't cut short by the cancelled context
+			assert.NoError(t, ctx.Err())
+			return nil
+		})
+
+	uc.autoRejectWithRetry(ctx, accepted, nil)
+}
+
+func TestRetryAutoRejections_CompletesOnlySuccessfulRetries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	recovered := models.AutoRejection{PassengerID: uuid.NewString(), MatchID: uuid.NewString()}
+	stillFailing := models.AutoRejection{PassengerID: uuid.NewString(), MatchID: uuid.NewString()}
+	malformed := models.AutoRejection{PassengerID: uuid.NewString(), MatchID: "not-a-uuid"}
+
+	mockRepo.EXPECT().DropAutoRejectionsBefore(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().
+		ListAutoRejections(gomock.Any(), autoRejectionBatchSize).
+		Return([]models.AutoRejection{recovered, stillFailing, malformed}, nil)
+
+	recoveredPassenger := uuid.MustParse(recovered.PassengerID)
+	mockRepo.EXPECT().GetMatch(gomock.Any(), recovered.MatchID).Return(&models.Match{
+		ID: uuid.MustParse(recovered.MatchID), PassengerID: recoveredPassenger, Status: models.MatchStatusAccepted,
+	}, nil)
+	pending := &models.Match{ID: uuid.New(), PassengerID: recoveredPassenger, Status: models.MatchStatusPending}
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), recoveredPassenger).
+		Return([]*models.Match{pending}, nil)
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{pending.ID.String()}, models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return([]string{pending.ID.String()}, nil)
+	mockGW.EXPECT().PublishMatchRejected(gomock.Any(), gomock.Any()).Return(nil)
+
+	stillFailingPassenger := uuid.MustParse(stillFailing.PassengerID)
+	mockRepo.EXPECT().GetMatch(gomock.Any(), stillFailing.MatchID).Return(&models.Match{
+		ID: uuid.MustParse(stillFailing.MatchID), PassengerID: stillFailingPassenger, Status: models.MatchStatusAccepted,
+	}, nil)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), stillFailingPassenger).
+		Return(nil, errors.New("connection refused"))
+
+	// Only the retry that went through leaves the queue
+	mockRepo.EXPECT().CompleteAutoRejection(gomock.Any(), recovered).Return(nil)
+
+	completed, err := uc.RetryAutoRejections(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+}
+
+func TestRetryAutoRejections_DropsEntriesPastProposalTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{Match: models.MatchConfig{ProposalTTLSeconds: 60}}, mockRepo, mockGW)
+	mockClock := clock.NewMock(time.Now())
+	uc.clock = mockClock
+
+	// Entries queued over a minute ago only point at proposals that have expired since
+	mockRepo.EXPECT().DropAutoRejectionsBefore(gomock.Any(), mockClock.Now().Add(-time.Minute)).Return(nil)
+	mockRepo.EXPECT().ListAutoRejections(gomock.Any(), autoRejectionBatchSize).Return(nil, nil)
+
+	completed, err := uc.RetryAutoRejections(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, completed)
+}
+
+func TestRetryAutoRejections_LeavesMatchesProposedAfterAcceptance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	acceptedAt := time.Now().Add(-time.Minute)
+	passengerID := uuid.New()
+	accepted := &models.Match{ID: uuid.New(), PassengerID: passengerID, Status: models.MatchStatusAccepted, UpdatedAt: acceptedAt}
+	stale := &models.Match{ID: uuid.New(), PassengerID: passengerID, Status: models.MatchStatusPending, CreatedAt: acceptedAt.Add(-time.Second)}
+	// The passenger has since finished the ride and started a new search
+	fresh := &models.Match{ID: uuid.New(), PassengerID: passengerID, Status: models.MatchStatusPending, CreatedAt: acceptedAt.Add(30 * time.Second)}
+
+	rejection := models.AutoRejection{PassengerID: passengerID.String(), MatchID: accepted.ID.String()}
+	mockRepo.EXPECT().DropAutoRejectionsBefore(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().ListAutoRejections(gomock.Any(), autoRejectionBatchSize).Return([]models.AutoRejection{rejection}, nil)
+	mockRepo.EXPECT().GetMatch(gomock.Any(), accepted.ID.String()).Return(accepted, nil)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return([]*models.Match{accepted, stale, fresh}, nil)
+
+	// Only the match proposed before the acceptance is rejected
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{stale.ID.String()}, models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return([]string{stale.ID.String()}, nil)
+	mockGW.EXPECT().PublishMatchRejected(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().CompleteAutoRejection(gomock.Any(), rejection).Return(nil)
+
+	completed, err := uc.RetryAutoRejections(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+}
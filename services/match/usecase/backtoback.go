@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+const (
+	// backToBackOptInTTL bounds how long a back-to-back opt-in is kept should the ride's completion never arrive
+	backToBackOptInTTL = 30 * time.Minute
+	// defaultBackToBackMaxDropoffKm is used when no back-to-back drop-off distance is configured
+	defaultBackToBackMaxDropoffKm = 1.0
+	// backToBackMatchLookback is how many of the driver's latest matches are searched for the ride's
+	backToBackMatchLookback = 10
+)
+
+// backToBackMaxDropoffKm returns how close to their drop-off a driver must be to opt in
+func (uc *MatchUC) backToBackMaxDropoffKm() float64 {
+	if km := uc.config().Match.BackToBackMaxDropoffKm; km > 0 {
+		return km
+	}
+	return defaultBackToBackMaxDropoffKm
+}
+
+// isNearDropoff reports whether the driver is within the back-to-back distance of the drop-off of
+// the match they are serving
+func (uc *MatchUC) isNearDropoff(ctx context.Context, driverID string, location *models.Location) (bool, error) {
+	driverUUID, err := uuid.Parse(driverID)
+	if err != nil {
+		return false, fmt.Errorf("invalid driver ID: %w", err)
+	}
+
+	matches, err := uc.matchRepo.ListMatchesByDriver(ctx, driverUUID, backToBackMatchLookback, 0)
+	if err != nil {
+		return false, fmt.Errorf("failed to list driver matches: %w", err)
+	}
+
+	// Matches are newest first, so the first accepted one is the ride being finished
+	for _, m := range matches {
+		if m.Status != models.MatchStatusAccepted {
+			continue
+		}
+		distanceKm := utils.CalculateDistance(
+			utils.GeoPoint{Latitude: location.Latitude, Longitude: location.Longitude},
+			utils.GeoPoint{Latitude: m.TargetLocation.Latitude, Longitude: m.TargetLocation.Longitude},
+		)
+		return distanceKm <= uc.backToBackMaxDropoffKm(), nil
+	}
+	return false, nil
+}
+
+// hasBackToBackOptIn reports whether the driver opted in to their next match while finishing a ride
+func (uc *MatchUC) hasBackToBackOptIn(ctx context.Context, driverID string) bool {
+	finishing, err := uc.matchRepo.GetDriverFinishingRide(ctx, driverID)
+	if err != nil {
+		logger.Error("Failed to check back-to-back opt-in",
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+		return false
+	}
+	return finishing != ""
+}
+
+// acceptNextRide puts a driver who is close to the drop-off of their ride back into the available
+// pool so they can be matched for their next ride before this one completes. The driver's ride lock
+// is bypassed for the opt-in; it is only released once the ride actually completes.
+func (uc *MatchUC) acceptNextRide(ctx context.Context, driverID, rideID string, location *models.Location) error {
+	// A driver already picked up for their next ride has nothing left to opt in for
+	finishing, err := uc.matchRepo.GetDriverFinishingRide(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	if finishing != "" && finishing != rideID {
+		logger.Debug("Driver already has their next ride, skipping back-to-back opt-in",
+			logger.String("driver_id", driverID),
+			logger.String("ride_id", rideID))
+		return nil
+	}
+
+	// Opting in is only for the end of a ride, not for drivers who just picked their passenger up
+	near, err := uc.isNearDropoff(ctx, driverID, location)
+	if err != nil {
+		return err
+	}
+	if !near {
+		logger.Debug("Driver is not near their drop-off yet, skipping back-to-back opt-in",
+			logger.String("driver_id", driverID),
+			logger.String("ride_id", rideID))
+		return nil
+	}
+
+	if err := uc.matchRepo.MarkDriverFinishingRide(ctx, driverID, rideID, backToBackOptInTTL); err != nil {
+		return err
+	}
+
+	if err := uc.addDriverToPool(ctx, driverID, location); err != nil {
+		return err
+	}
+
+	logger.Info("Driver finishing a ride accepts their next match",
+		logger.String("driver_id", driverID),
+		logger.String("ride_id", rideID))
+	return nil
+}
+
+// DriverHasNextRide reports whether a driver who opted in to back-to-back rides while finishing
+// finishedRideID has already been picked up for their next ride. Such a driver stays tracked and
+// locked for the next ride when the finished one is cleaned up.
+func (uc *MatchUC) DriverHasNextRide(ctx context.Context, driverID, finishedRideID string) (bool, error) {
+	finishing, err := uc.matchRepo.GetDriverFinishingRide(ctx, driverID)
+	if err != nil {
+		return false, err
+	}
+	if finishing != finishedRideID {
+		return false, nil
+	}
+
+	activeRideID, err := uc.matchRepo.GetActiveRideByDriver(ctx, driverID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check active ride: %w", err)
+	}
+	return activeRideID != "" && activeRideID != finishedRideID, nil
+}
+
+// DriverAwaitsNextRide reports whether the driver opted in to back-to-back rides while finishing
+// finishedRideID. Such a driver is in the pool for their next match and stays there when the
+// finished ride is cleaned up.
+func (uc *MatchUC) DriverAwaitsNextRide(ctx context.Context, driverID, finishedRideID string) (bool, error) {
+	finishing, err := uc.matchRepo.GetDriverFinishingRide(ctx, driverID)
+	if err != nil {
+		return false, err
+	}
+	return finishing == finishedRideID, nil
+}
+
+// ClearBackToBackOptIn removes a driver's back-to-back opt-in once finishedRideID has been cleaned up.
+// An opt-in made from another ride is left alone.
+func (uc *MatchUC) ClearBackToBackOptIn(ctx context.Context, driverID, finishedRideID string) error {
+	finishing, err := uc.matchRepo.GetDriverFinishingRide(ctx, driverID)
+	if err != nil {
+		return err
+	}
+	if finishing != finishedRideID {
+		return nil
+	}
+	return uc.matchRepo.ClearDriverFinishingRide(ctx, driverID)
+}
@@ -0,0 +1,282 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// finishingBeacon is a beacon from a driver on a ride, optionally asking for their next match early
+func finishingBeacon(driverID string, acceptingNext bool) models.BeaconEvent {
+	return models.BeaconEvent{
+		UserID:        driverID,
+		IsActive:      true,
+		Location:      models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: time.Now()},
+		Timestamp:     time.Now(),
+		AcceptingNext: acceptingNext,
+	}
+}
+
+func backToBackConfig(enabled bool) *models.Config {
+	return &models.Config{Match: models.MatchConfig{SearchRadiusKm: 5.0, BackToBackRides: enabled}}
+}
+
+// servingMatch is the accepted match a driver is serving, dropping the passenger off at dropoff
+func servingMatch(driverID string, dropoff models.Location) *models.Match {
+	return &models.Match{
+		ID:             uuid.New(),
+		DriverID:       uuid.MustParse(driverID),
+		Status:         models.MatchStatusAccepted,
+		TargetLocation: dropoff,
+	}
+}
+
+func TestHandleBeaconEvent_BackToBack_OptedInDriverJoinsPoolBeforeCompletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(backToBackConfig(true), mockRepo, mockGW)
+
+	driverID, rideID := uuid.New().String(), uuid.New().String()
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return(rideID, nil)
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return("", nil)
+
+	// The drop-off is a few hundred meters ahead; a newer proposal doesn't hide the ride's match
+	pending := &models.Match{ID: uuid.New(), Status: models.MatchStatusPending}
+	serving := servingMatch(driverID, models.Location{Latitude: -6.178, Longitude: 106.827153})
+	mockRepo.EXPECT().
+		ListMatchesByDriver(gomock.Any(), uuid.MustParse(driverID), backToBackMatchLookback, 0).
+		Return([]*models.Match{pending, serving}, nil)
+	mockRepo.EXPECT().MarkDriverFinishingRide(gomock.Any(), driverID, rideID, backToBackOptInTTL).Return(nil)
+
+	// The driver is still locked into the ride, yet becomes available for the next match
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), driverID).Return(true, nil)
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(rideID, nil)
+	mockGW.EXPECT().AddAvailableDriver(gomock.Any(), driverID, gomock.Any()).Return(nil)
+
+	require.NoError(t, uc.HandleBeaconEvent(context.Background(), finishingBeacon(driverID, true)))
+}
+
+func TestHandleBeaconEvent_BackToBack_RecordsGender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(backToBackConfig(true), mockRepo, mockGW)
+
+	driverID, rideID := uuid.New().String(), uuid.New().String()
+	beacon := finishingBeacon(driverID, true)
+	beacon.Gender = "female"
+
+	// Gender-preferring passengers may be matched with the driver before the ride ends
+	mockRepo.EXPECT().SetDriverGender(gomock.Any(), driverID, "female").Return(nil)
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return(rideID, nil)
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return("", nil)
+	mockRepo.EXPECT().
+		ListMatchesByDriver(gomock.Any(), uuid.MustParse(driverID), backToBackMatchLookback, 0).
+		Return([]*models.Match{servingMatch(driverID, models.Location{Latitude: -6.178, Longitude: 106.827153})}, nil)
+	mockRepo.EXPECT().MarkDriverFinishingRide(gomock.Any(), driverID, rideID, backToBackOptInTTL).Return(nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), driverID).Return(true, nil)
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(rideID, nil)
+	mockGW.EXPECT().AddAvailableDriver(gomock.Any(), driverID, gomock.Any()).Return(nil)
+
+	require.NoError(t, uc.HandleBeaconEvent(context.Background(), beacon))
+}
+
+func TestHandleBeaconEvent_BackToBack_DriverFarFromDropoffStaysOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(backToBackConfig(true), mockRepo, mockGW)
+
+	// The driver opts in right after pickup, with the drop-off still kilometers away
+	driverID, rideID := uuid.New().String(), uuid.New().String()
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return(rideID, nil)
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return("", nil)
+	mockRepo.EXPECT().
+		ListMatchesByDriver(gomock.Any(), uuid.MustParse(driverID), backToBackMatchLookback, 0).
+		Return([]*models.Match{servingMatch(driverID, models.Location{Latitude: -6.25, Longitude: 106.9})}, nil)
+
+	// Neither the opt-in is recorded nor the driver added to the pool
+	mockRepo.EXPECT().MarkDriverFinishingRide(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+	mockGW.EXPECT().AddAvailableDriver(gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	require.NoError(t, uc.HandleBeaconEvent(context.Background(), finishingBeacon(driverID, true)))
+}
+
+func TestHandleBeaconEvent_BackToBack_DriverNotOptedInStaysOut(t *testing.T) {
+	tests := []struct {
+		name          string
+		enabled       bool
+		acceptingNext bool
+	}{
+		{name: "driver did not opt in", enabled: true, acceptingNext: false},
+		{name: "mode disabled", enabled: false, acceptingNext: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockMatchRepo(ctrl)
+			mockGW := mocks.NewMockMatchGW(ctrl)
+			uc := NewMatchUC(backToBackConfig(tt.enabled), mockRepo, mockGW)
+
+			// Only the active ride is looked up; the driver isn't added to the pool
+			driverID := uuid.New().String()
+			mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return(uuid.New().String(), nil)
+
+			require.NoError(t, uc.HandleBeaconEvent(context.Background(), finishingBeacon(driverID, tt.acceptingNext)))
+		})
+	}
+}
+
+func TestHandleBeaconEvent_BackToBack_DriverWithNextRideStaysOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(backToBackConfig(true), mockRepo, mockGW)
+
+	// The driver opted in while finishing one ride and has since been picked up for the next
+	driverID, finishingRideID, nextRideID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return(nextRideID, nil)
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(finishingRideID, nil)
+
+	require.NoError(t, uc.HandleBeaconEvent(context.Background(), finishingBeacon(driverID, true)))
+}
+
+func TestDriverHasNextRide(t *testing.T) {
+	driverID, finishedRideID, nextRideID := uuid.New().String(), uuid.New().String(), uuid.New().String()
+
+	tests := []struct {
+		name         string
+		finishing    string
+		activeRideID string
+		expected     bool
+	}{
+		{name: "opted-in driver picked up for the next ride", finishing: finishedRideID, activeRideID: nextRideID, expected: true},
+		{name: "opted-in driver not matched yet", finishing: finishedRideID, activeRideID: finishedRideID, expected: false},
+		{name: "opted-in driver already released", finishing: finishedRideID, activeRideID: "", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockMatchRepo(ctrl)
+			uc := NewMatchUC(backToBackConfig(true), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+			mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(tt.finishing, nil)
+			mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return(tt.activeRideID, nil)
+
+			hasNextRide, err := uc.DriverHasNextRide(context.Background(), driverID, finishedRideID)
+
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, hasNextRide)
+		})
+	}
+
+	t.Run("driver who did not opt in", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockMatchRepo(ctrl)
+		uc := NewMatchUC(backToBackConfig(true), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+		mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return("", nil)
+
+		hasNextRide, err := uc.DriverHasNextRide(context.Background(), driverID, finishedRideID)
+
+		require.NoError(t, err)
+		assert.False(t, hasNextRide)
+	})
+
+	t.Run("lookup failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockMatchRepo(ctrl)
+		uc := NewMatchUC(backToBackConfig(true), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+		mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return("", errors.New("redis down"))
+
+		_, err := uc.DriverHasNextRide(context.Background(), driverID, finishedRideID)
+
+		assert.Error(t, err)
+	})
+}
+
+func TestDriverAwaitsNextRide(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(backToBackConfig(true), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	driverID, finishedRideID := uuid.New().String(), uuid.New().String()
+
+	// A driver who opted in from the finished ride is waiting for their next match
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(finishedRideID, nil)
+	awaits, err := uc.DriverAwaitsNextRide(context.Background(), driverID, finishedRideID)
+	require.NoError(t, err)
+	assert.True(t, awaits)
+
+	// An opt-in made from another ride does not keep the driver in the pool
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(uuid.New().String(), nil)
+	awaits, err = uc.DriverAwaitsNextRide(context.Background(), driverID, finishedRideID)
+	require.NoError(t, err)
+	assert.False(t, awaits)
+
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return("", errors.New("redis down"))
+	_, err = uc.DriverAwaitsNextRide(context.Background(), driverID, finishedRideID)
+	assert.Error(t, err)
+}
+
+func TestClearBackToBackOptIn(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(backToBackConfig(true), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	driverID, finishedRideID := uuid.New().String(), uuid.New().String()
+
+	// The opt-in made from the finished ride is removed
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(finishedRideID, nil)
+	mockRepo.EXPECT().ClearDriverFinishingRide(gomock.Any(), driverID).Return(nil)
+	require.NoError(t, uc.ClearBackToBackOptIn(context.Background(), driverID, finishedRideID))
+
+	// An opt-in made from another ride is left alone
+	mockRepo.EXPECT().GetDriverFinishingRide(gomock.Any(), driverID).Return(uuid.New().String(), nil)
+	require.NoError(t, uc.ClearBackToBackOptIn(context.Background(), driverID, finishedRideID))
+}
+
+func TestReleaseRideLocks_KeepsDriverOnNextRideLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(backToBackConfig(true), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	passengerID := uuid.New().String()
+	mockRepo.EXPECT().ReleaseRideLock(gomock.Any(), passengerID).Return(nil)
+
+	require.NoError(t, uc.ReleaseRideLocks(context.Background(), "", passengerID))
+}
@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	// defaultCancellationWindow is used when no cancellation window is configured
+	defaultCancellationWindow = 24 * time.Hour
+	// defaultCancellationRateThreshold is used when no cancellation rate threshold is configured
+	defaultCancellationRateThreshold = 0.5
+	// defaultCancellationPenalty is used when no suspension length is configured
+	defaultCancellationPenalty = 30 * time.Minute
+)
+
+// cancellationWindow returns the rolling window over which a driver's cancellation rate is measured
+func (uc *MatchUC) cancellationWindow() time.Duration {
+	if uc.cfg != nil && uc.cfg.Match.CancellationWindowHours > 0 {
+		return time.Duration(uc.cfg.Match.CancellationWindowHours) * time.Hour
+	}
+	return defaultCancellationWindow
+}
+
+// cancellationRateThreshold returns the cancellation rate above which a driver is suspended
+func (uc *MatchUC) cancellationRateThreshold() float64 {
+	if uc.cfg != nil && uc.cfg.Match.CancellationRateThreshold > 0 {
+		return uc.cfg.Match.CancellationRateThreshold
+	}
+	return defaultCancellationRateThreshold
+}
+
+// cancellationPenalty returns how long a suspended driver is left out of matching
+func (uc *MatchUC) cancellationPenalty() time.Duration {
+	if uc.cfg != nil && uc.cfg.Match.CancellationPenaltyMins > 0 {
+		return time.Duration(uc.cfg.Match.CancellationPenaltyMins) * time.Minute
+	}
+	return defaultCancellationPenalty
+}
+
+// cancellationRate returns the share of accepted rides a driver went on to cancel
+func cancellationRate(accepted, cancelled int) float64 {
+	if accepted <= 0 {
+		return 0
+	}
+	rate := float64(cancelled) / float64(accepted)
+	if rate > 1 {
+		return 1
+	}
+	return rate
+}
+
+// recordDriverAccepted counts an accepted match towards the driver's cancellation rate.
+// Failures are logged so they never hold up the match.
+func (uc *MatchUC) recordDriverAccepted(ctx context.Context, match *models.Match) {
+	driverID := match.DriverID.String()
+	if err := uc.matchRepo.RecordDriverAccepted(ctx, driverID, match.ID.String(), uc.clock.Now(), uc.cancellationWindow()); err != nil {
+		logger.Warn("Failed to record driver acceptance",
+			logger.String("driver_id", driverID),
+			logger.String("match_id", match.ID.String()),
+			logger.ErrorField(err))
+	}
+}
+
+// HandleDriverCancellation counts a ride the driver cancelled and suspends the driver from
+// matching once they have accepted enough rides and cancel more than the threshold allows
+func (uc *MatchUC) HandleDriverCancellation(ctx context.Context, driverID, rideID string) error {
+	now := uc.clock.Now()
+	window := uc.cancellationWindow()
+	if err := uc.matchRepo.RecordDriverCancelled(ctx, driverID, rideID, now, window); err != nil {
+		return err
+	}
+
+	accepted, cancelled, err := uc.matchRepo.CountDriverOutcomes(ctx, driverID, now.Add(-window))
+	if err != nil {
+		return err
+	}
+
+	minAccepted := 0
+	if uc.cfg != nil {
+		minAccepted = uc.cfg.Match.CancellationMinAccepted
+	}
+	rate := cancellationRate(accepted, cancelled)
+	if accepted < minAccepted || rate <= uc.cancellationRateThreshold() {
+		return nil
+	}
+
+	penalty := uc.cancellationPenalty()
+	if err := uc.matchRepo.SuspendDriver(ctx, driverID, penalty); err != nil {
+		return err
+	}
+
+	// Proposals also skip the driver until the penalty expires, so a later beacon re-adding them is harmless
+	if err := uc.matchGW.RemoveAvailableDriver(ctx, driverID); err != nil {
+		logger.Warn("Failed to remove suspended driver from pool",
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+	}
+
+	logger.Warn("Suspended driver for cancelling too many rides",
+		logger.String("driver_id", driverID),
+		logger.Int("accepted", accepted),
+		logger.Int("cancelled", cancelled),
+		logger.Float64("cancellation_rate", rate),
+		logger.Duration("penalty", penalty))
+	return nil
+}
+
+// isDriverSuspended reports whether a driver is serving a cancellation penalty. Errors are
+// logged and treated as not suspended so a Redis hiccup does not empty the matching pool.
+func (uc *MatchUC) isDriverSuspended(ctx context.Context, driverID string) bool {
+	remaining, err := uc.matchRepo.GetDriverSuspension(ctx, driverID)
+	if err != nil {
+		logger.Warn("Failed to check driver suspension",
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+		return false
+	}
+	return remaining > 0
+}
+
+// GetDriverCancellationStats returns a driver's cancellation rate over the rolling window and any suspension
+func (uc *MatchUC) GetDriverCancellationStats(ctx context.Context, driverID string) (*models.DriverCancellationStats, error) {
+	now := uc.clock.Now()
+	window := uc.cancellationWindow()
+
+	accepted, cancelled, err := uc.matchRepo.CountDriverOutcomes(ctx, driverID, now.Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("failed to count driver outcomes: %w", err)
+	}
+
+	remaining, err := uc.matchRepo.GetDriverSuspension(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.DriverCancellationStats{
+		DriverID:         driverID,
+		WindowHours:      int(window / time.Hour),
+		Accepted:         accepted,
+		Cancelled:        cancelled,
+		CancellationRate: cancellationRate(accepted, cancelled),
+	}
+	if remaining > 0 {
+		until := now.Add(remaining)
+		stats.SuspendedUntil = &until
+	}
+	return stats, nil
+}
@@ -0,0 +1,75 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
+)
+
+// GetMatchDebug returns a match's confirmation state alongside each user's pool membership, active
+// ride and ride lock. Lookups that fail are reported in the view rather than failing it, since the
+// view is most needed while something is broken.
+func (uc *MatchUC) GetMatchDebug(ctx context.Context, matchID string) (*models.MatchDebugView, error) {
+	m, err := uc.matchRepo.GetMatch(ctx, matchID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, match.ErrMatchNotFound
+		}
+		return nil, err
+	}
+
+	return &models.MatchDebugView{
+		MatchID:            m.ID.String(),
+		Status:             m.Status,
+		DriverConfirmed:    m.DriverConfirmed,
+		PassengerConfirmed: m.PassengerConfirmed,
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+		Driver:             uc.participantState(ctx, m.DriverID.String(), true),
+		Passenger:          uc.participantState(ctx, m.PassengerID.String(), false),
+	}, nil
+}
+
+// participantState gathers what the match and location services hold for one user of a match. Pool
+// membership is read from the available set itself, since the location service keeps a user's last
+// position after they leave the pool; the position is only looked up for users in it.
+func (uc *MatchUC) participantState(ctx context.Context, userID string, isDriver bool) models.MatchParticipantState {
+	state := models.MatchParticipantState{UserID: userID}
+
+	var err error
+	if state.InPool, err = uc.matchRepo.IsInMatchingPool(ctx, userID, isDriver); err != nil {
+		state.Errors = append(state.Errors, fmt.Sprintf("pool membership: %v", err))
+	}
+	if state.InPool {
+		var location models.Location
+		if isDriver {
+			location, err = uc.matchGW.GetDriverLocation(ctx, userID)
+		} else {
+			location, err = uc.matchGW.GetPassengerLocation(ctx, userID)
+		}
+		if err != nil {
+			state.Errors = append(state.Errors, fmt.Sprintf("pool location: %v", err))
+		} else {
+			state.Location = &location
+		}
+	}
+
+	if isDriver {
+		state.ActiveRideID, err = uc.matchRepo.GetActiveRideByDriver(ctx, userID)
+	} else {
+		state.ActiveRideID, err = uc.matchRepo.GetActiveRideByPassenger(ctx, userID)
+	}
+	if err != nil {
+		state.Errors = append(state.Errors, fmt.Sprintf("active ride: %v", err))
+	}
+
+	if state.RideLocked, err = uc.matchRepo.IsRideLocked(ctx, userID); err != nil {
+		state.Errors = append(state.Errors, fmt.Sprintf("ride lock: %v", err))
+	}
+
+	return state
+}
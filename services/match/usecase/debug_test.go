@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMatchDebug(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	m := &models.Match{
+		ID:              uuid.New(),
+		DriverID:        uuid.New(),
+		PassengerID:     uuid.New(),
+		Status:          models.MatchStatusDriverConfirmed,
+		DriverConfirmed: true,
+		CreatedAt:       time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		UpdatedAt:       time.Date(2024, 1, 1, 12, 0, 5, 0, time.UTC),
+	}
+	driverID, passengerID := m.DriverID.String(), m.PassengerID.String()
+	passengerLocation := models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	rideID := uuid.NewString()
+
+	mockRepo.EXPECT().GetMatch(gomock.Any(), m.ID.String()).Return(m, nil)
+
+	// The driver already left the pool for a ride, the passenger is still waiting in it
+	mockRepo.EXPECT().IsInMatchingPool(gomock.Any(), driverID, true).Return(false, nil)
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return(rideID, nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), driverID).Return(true, nil)
+
+	mockRepo.EXPECT().IsInMatchingPool(gomock.Any(), passengerID, false).Return(true, nil)
+	mockGW.EXPECT().GetPassengerLocation(gomock.Any(), passengerID).Return(passengerLocation, nil)
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), passengerID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), passengerID).Return(false, errors.New("redis down"))
+
+	view, err := uc.GetMatchDebug(context.Background(), m.ID.String())
+	require.NoError(t, err)
+
+	assert.Equal(t, m.ID.String(), view.MatchID)
+	assert.Equal(t, models.MatchStatusDriverConfirmed, view.Status)
+	assert.True(t, view.DriverConfirmed)
+	assert.False(t, view.PassengerConfirmed)
+	assert.Equal(t, m.CreatedAt, view.CreatedAt)
+	assert.Equal(t, m.UpdatedAt, view.UpdatedAt)
+
+	assert.Equal(t, models.MatchParticipantState{
+		UserID:       driverID,
+		ActiveRideID: rideID,
+		RideLocked:   true,
+	}, view.Driver)
+
+	// A failed lookup is reported without hiding the rest of the state
+	assert.Equal(t, models.MatchParticipantState{
+		UserID:   passengerID,
+		InPool:   true,
+		Location: &passengerLocation,
+		Errors:   []string{"ride lock: redis down"},
+	}, view.Passenger)
+}
+
+func TestGetMatchDebug_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	mockRepo.EXPECT().GetMatch(gomock.Any(), gomock.Any()).Return(nil, fmt.Errorf("failed to get match: %w", sql.ErrNoRows))
+
+	_, err := uc.GetMatchDebug(context.Background(), uuid.NewString())
+	assert.ErrorIs(t, err, match.ErrMatchNotFound)
+}
@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+)
+
+// expectMatchAcceptance sets up the calls made when userID's acceptance completes match.
+// The other party must already have confirmed: both users leave the pools, the acceptance is
+// published and the background auto-rejection finds no other pending matches.
+// The confirmed match is updated in place.
+func expectMatchAcceptance(repo *mocks.MockMatchRepo, gw *mocks.MockMatchGW, match *models.Match, userID string) {
+	isDriver := userID == match.DriverID.String()
+	matchID := match.ID.String()
+
+	repo.EXPECT().
+		GetMatch(gomock.Any(), matchID).
+		Return(match, nil)
+
+	repo.EXPECT().
+		ConfirmMatchByUser(gomock.Any(), matchID, userID, isDriver).
+		DoAndReturn(func(_ context.Context, _, _ string, _ bool) (*models.Match, error) {
+			match.DriverConfirmed = true
+			match.PassengerConfirmed = true
+			match.Status = models.MatchStatusAccepted
+			return match, nil
+		})
+
+	gw.EXPECT().RemoveAvailableDriver(gomock.Any(), match.DriverID.String()).Return(nil)
+	gw.EXPECT().RemoveAvailablePassenger(gomock.Any(), match.PassengerID.String()).Return(nil)
+	gw.EXPECT().PublishMatchAccepted(gomock.Any(), gomock.Any()).Return(nil)
+	repo.EXPECT().
+		RecordDriverAccepted(gomock.Any(), match.DriverID.String(), matchID, gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	// Auto-rejection runs asynchronously and may not finish before the test does
+	repo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), match.PassengerID).
+		Return(nil, nil).
+		AnyTimes()
+	repo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), gomock.Any(), models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return(nil, nil).
+		AnyTimes()
+	gw.EXPECT().
+		PublishMatchRejected(gomock.Any(), gomock.Any()).
+		Return(nil).
+		AnyTimes()
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	// defaultProposalTTL is used when no proposal TTL is configured
+	defaultProposalTTL = 30 * time.Second
+	// proposalExpiryPollInterval is how often expired proposals are looked for when no interval is given
+	proposalExpiryPollInterval = 5 * time.Second
+	// proposalExpiryBatchSize is the most expired proposals rejected per run
+	proposalExpiryBatchSize = 100
+)
+
+// proposalTTL returns how long a driver has to answer a proposal, falling back to the default
+func (uc *MatchUC) proposalTTL() time.Duration {
+	if secs := uc.config().Match.ProposalTTLSeconds; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultProposalTTL
+}
+
+// proposalExpiresAt returns when a match proposal lapses. The driver app counts down to it and the
+// expiry reaper rejects the proposal once it has passed, so both use this one deadline.
+func (uc *MatchUC) proposalExpiresAt(m *models.Match) time.Time {
+	return m.CreatedAt.Add(uc.proposalTTL())
+}
+
+// ExpireProposals rejects up to limit pending proposals nobody answered before they expired at now,
+// returning how many lapsed proposals were found. Only matches still pending are rejected, so one
+// answered in the meantime is left to its confirmation; driver and passenger are told of the others
+// through match rejected events.
+func (uc *MatchUC) ExpireProposals(ctx context.Context, now time.Time, limit int) (int, error) {
+	candidates, err := uc.matchRepo.ListExpiredProposals(ctx, now.Add(-uc.proposalTTL()), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	ids := make([]string, 0, len(candidates))
+	events := make([]models.MatchProposal, 0, len(candidates))
+	for _, m := range candidates {
+		if uc.proposalExpiresAt(m).After(now) {
+			continue
+		}
+		ids = append(ids, m.ID.String())
+		events = append(events, uc.createRejectionEvent(m))
+	}
+
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	rejectedIDs, err := uc.matchRepo.BatchUpdateMatchStatus(ctx, ids, models.MatchStatusRejected,
+		[]models.MatchStatus{models.MatchStatusPending})
+	if err != nil {
+		return 0, err
+	}
+	if err := uc.publishRejectionEvents(ctx, transitionedEvents(events, rejectedIDs)); err != nil {
+		return 0, err
+	}
+
+	logger.Info("Expired unanswered match proposals", logger.Int("expired", len(ids)))
+	return len(ids), nil
+}
+
+// RunProposalExpiry periodically rejects expired match proposals until ctx is cancelled
+func (uc *MatchUC) RunProposalExpiry(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = proposalExpiryPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Proposal expiry stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.ExpireProposals(ctx, uc.clock.Now(), proposalExpiryBatchSize); err != nil {
+				logger.Error("Proposal expiry run failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
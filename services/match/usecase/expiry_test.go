@@ -0,0 +1,175 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/clock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proposalTTLConfig gives drivers 20 seconds to answer a proposal
+func proposalTTLConfig() *models.Config {
+	return &models.Config{Match: models.MatchConfig{ProposalTTLSeconds: 20}}
+}
+
+// proposedAt returns an unanswered match proposed at the given time
+func proposedAt(createdAt time.Time) *models.Match {
+	return &models.Match{
+		ID:                uuid.New(),
+		DriverID:          uuid.New(),
+		PassengerID:       uuid.New(),
+		PassengerLocation: models.Location{Latitude: -6.2, Longitude: 106.8},
+		DriverLocation:    models.Location{Latitude: -6.21, Longitude: 106.8},
+		Status:            models.MatchStatusPending,
+		CreatedAt:         createdAt,
+	}
+}
+
+func TestCreateMatch_ProposalCarriesExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(proposalTTLConfig(), mockRepo, mockGW)
+
+	createdAt := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	newMatch := proposedAt(createdAt)
+	mockRepo.EXPECT().CreateMatch(gomock.Any(), newMatch).Return(newMatch, nil)
+
+	mockGW.EXPECT().
+		PublishMatchFound(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			require.NotNil(t, mp.ExpiresAt)
+			assert.Equal(t, createdAt.Add(20*time.Second), *mp.ExpiresAt)
+			return nil
+		})
+
+	require.NoError(t, uc.CreateMatch(context.Background(), newMatch))
+}
+
+func TestCreateMatch_ProposalExpiryDefaultsTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	createdAt := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	newMatch := proposedAt(createdAt)
+	mockRepo.EXPECT().CreateMatch(gomock.Any(), newMatch).Return(newMatch, nil)
+
+	mockGW.EXPECT().
+		PublishMatchFound(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			require.NotNil(t, mp.ExpiresAt)
+			assert.Equal(t, createdAt.Add(defaultProposalTTL), *mp.ExpiresAt)
+			return nil
+		})
+
+	require.NoError(t, uc.CreateMatch(context.Background(), newMatch))
+}
+
+func TestExpireProposals_RejectsProposalsPastTheirExpiry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(proposalTTLConfig(), mockRepo, mockGW)
+
+	now := time.Now()
+	lapsed := proposedAt(now.Add(-30 * time.Second))
+	// Exactly at its expiry counts as lapsed, just as the driver's countdown reaches zero
+	due := proposedAt(now.Add(-20 * time.Second))
+
+	mockRepo.EXPECT().
+		ListExpiredProposals(gomock.Any(), now.Add(-20*time.Second), 10).
+		Return([]*models.Match{lapsed, due}, nil)
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{lapsed.ID.String(), due.ID.String()}, models.MatchStatusRejected, []models.MatchStatus{models.MatchStatusPending}).
+		Return([]string{lapsed.ID.String()}, nil)
+
+	// Only the proposal that actually moved to rejected is announced
+	mockGW.EXPECT().
+		PublishMatchRejected(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			assert.Equal(t, lapsed.ID.String(), mp.ID)
+			assert.Equal(t, models.MatchStatusRejected, mp.MatchStatus)
+			return nil
+		})
+
+	expired, err := uc.ExpireProposals(context.Background(), now, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, expired)
+}
+
+func TestExpireProposals_NothingDue(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(proposalTTLConfig(), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	mockRepo.EXPECT().
+		ListExpiredProposals(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+
+	expired, err := uc.ExpireProposals(context.Background(), time.Now(), 10)
+
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+}
+
+func TestProposalExpiry_FollowsClock(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(proposalTTLConfig(), mockRepo, mockGW)
+
+	start := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	clk := clock.NewMock(start)
+	uc.clock = clk
+
+	location := &models.Location{Latitude: -6.2, Longitude: 106.8}
+	proposal := uc.buildMatch(uuid.New().String(), uuid.New().String(), location, location, nil)
+	proposal.ID = uuid.New()
+
+	assert.Equal(t, start, proposal.CreatedAt)
+	assert.Equal(t, start.Add(20*time.Second), uc.proposalExpiresAt(proposal))
+
+	// A second before the deadline the proposal is still open to the driver
+	clk.Advance(19 * time.Second)
+	mockRepo.EXPECT().
+		ListExpiredProposals(gomock.Any(), start.Add(-time.Second), 10).
+		Return([]*models.Match{proposal}, nil)
+
+	expired, err := uc.ExpireProposals(context.Background(), uc.clock.Now(), 10)
+	require.NoError(t, err)
+	assert.Zero(t, expired)
+
+	// Once the deadline is reached it lapses
+	clk.Advance(time.Second)
+	mockRepo.EXPECT().
+		ListExpiredProposals(gomock.Any(), start, 10).
+		Return([]*models.Match{proposal}, nil)
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{proposal.ID.String()}, models.MatchStatusRejected, []models.MatchStatus{models.MatchStatusPending}).
+		Return([]string{proposal.ID.String()}, nil)
+	mockGW.EXPECT().PublishMatchRejected(gomock.Any(), gomock.Any()).Return(nil)
+
+	expired, err = uc.ExpireProposals(context.Background(), uc.clock.Now(), 10)
+	require.NoError(t, err)
+	assert.Equal(t, 1, expired)
+}
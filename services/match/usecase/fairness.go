@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// defaultFairnessBandKm is used when fairness mode is on but no band width is configured
+const defaultFairnessBandKm = 0.5
+
+// fairnessBandKm returns the width of the distance bands used by fairness mode, falling back to the default
+func (uc *MatchUC) fairnessBandKm() float64 {
+	if band := uc.config().Match.FairnessBandKm; band > 0 {
+		return band
+	}
+	return defaultFairnessBandKm
+}
+
+// defaultFairnessMaxProposals is used when fairness mode is on but no proposal limit is configured
+const defaultFairnessMaxProposals = 3
+
+// fairnessProposalLimit returns how many drivers one search may propose to, or zero for no limit.
+// Without a limit every nearby driver is proposed at once and the fairness order only decides who
+// hears first, so fairness mode offers the ride to the front of the order only.
+func (uc *MatchUC) fairnessProposalLimit() int {
+	if !uc.config().Match.FairnessMode {
+		return 0
+	}
+	if limit := uc.config().Match.FairnessMaxProposals; limit > 0 {
+		return limit
+	}
+	return defaultFairnessMaxProposals
+}
+
+// RecordDriverRideCompleted notes when a driver finished a ride so fairness mode can favour drivers
+// who have waited longer
+func (uc *MatchUC) RecordDriverRideCompleted(ctx context.Context, driverID string, at time.Time) error {
+	return uc.matchRepo.RecordDriverRideCompleted(ctx, driverID, at)
+}
+
+// orderDriversForFairness reorders nearby drivers when fairness mode is on. Drivers are grouped into
+// distance bands, nearest band first, and within a band the driver whose last ride completed longest
+// ago comes first. Drivers with no completed ride on record count as having waited longest. When the
+// last rides can't be read the nearest-first order is kept, so matching carries on.
+func (uc *MatchUC) orderDriversForFairness(ctx context.Context, drivers []*models.NearbyUser) []*models.NearbyUser {
+	if !uc.config().Match.FairnessMode || len(drivers) < 2 {
+		return drivers
+	}
+
+	driverIDs := make([]string, len(drivers))
+	for i, driver := range drivers {
+		driverIDs[i] = driver.ID
+	}
+
+	lastRides, err := uc.matchRepo.GetDriversLastRideAt(ctx, driverIDs)
+	if err != nil {
+		logger.Warn("Failed to get driver last rides, keeping nearest-first order",
+			logger.ErrorField(err))
+		return drivers
+	}
+
+	band := uc.fairnessBandKm()
+	ordered := make([]*models.NearbyUser, len(drivers))
+	copy(ordered, drivers)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		bandI := math.Floor(ordered[i].Distance / band)
+		bandJ := math.Floor(ordered[j].Distance / band)
+		if bandI != bandJ {
+			return bandI < bandJ
+		}
+		return lastRides[ordered[i].ID].Before(lastRides[ordered[j].ID])
+	})
+	return ordered
+}
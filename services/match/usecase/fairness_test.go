@@ -0,0 +1,166 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func nearbyDriverAtKm(km float64) *models.NearbyUser {
+	return &models.NearbyUser{ID: uuid.New().String(), Distance: km}
+}
+
+func nearbyIDs(drivers []*models.NearbyUser) []string {
+	ids := make([]string, len(drivers))
+	for i, driver := range drivers {
+		ids[i] = driver.ID
+	}
+	return ids
+}
+
+func TestCreateMatchesWithNearbyDrivers_FairnessProposesLongestWaitingFirst(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{Match: models.MatchConfig{SearchRadiusKm: 5.0, FairnessMode: true, FairnessBandKm: 0.5}}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	passengerID := uuid.New().String()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	target := &models.Location{Latitude: -6.200000, Longitude: 106.816666}
+
+	// Two equidistant drivers, nearest-first order puts the busier one first
+	now := time.Now()
+	busy := nearbyDriverAtKm(1.2)
+	waiting := nearbyDriverAtKm(1.2)
+	far := nearbyDriverAtKm(3.0)
+	mockGW.EXPECT().
+		FindNearbyDrivers(gomock.Any(), location, 5.0).
+		Return([]*models.NearbyUser{busy, waiting, far}, nil)
+	mockRepo.EXPECT().
+		GetDriversLastRideAt(gomock.Any(), []string{busy.ID, waiting.ID, far.ID}).
+		Return(map[string]time.Time{
+			busy.ID:    now.Add(-5 * time.Minute),
+			waiting.ID: now.Add(-time.Hour),
+		}, nil)
+
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), passengerID, gomock.Any(), gomock.Any()).Return(true, nil).Times(3)
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+
+	var proposed []string
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, m *models.Match) (*models.Match, error) {
+			proposed = append(proposed, m.DriverID.String())
+			m.ID = uuid.New()
+			return m, nil
+		}).Times(3)
+
+	require.NoError(t, uc.createMatchesWithNearbyDrivers(context.Background(), passengerID, location, target, "", ""))
+
+	// The driver who waited longer is proposed first; the farther driver stays last despite never having had a ride
+	assert.Equal(t, []string{waiting.ID, busy.ID, far.ID}, proposed)
+}
+
+func TestOrderDriversForFairness(t *testing.T) {
+	now := time.Now()
+
+	t.Run("off keeps nearest-first order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		uc := NewMatchUC(&models.Config{}, mocks.NewMockMatchRepo(ctrl), mocks.NewMockMatchGW(ctrl))
+		drivers := []*models.NearbyUser{nearbyDriverAtKm(1), nearbyDriverAtKm(1)}
+
+		assert.Equal(t, nearbyIDs(drivers), nearbyIDs(uc.orderDriversForFairness(context.Background(), drivers)))
+	})
+
+	t.Run("driver without a completed ride counts as waiting longest", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockMatchRepo(ctrl)
+		uc := NewMatchUC(&models.Config{Match: models.MatchConfig{FairnessMode: true}}, mockRepo, mocks.NewMockMatchGW(ctrl))
+
+		recent, unknown := nearbyDriverAtKm(0.1), nearbyDriverAtKm(0.3)
+		mockRepo.EXPECT().
+			GetDriversLastRideAt(gomock.Any(), gomock.Any()).
+			Return(map[string]time.Time{recent.ID: now}, nil)
+
+		ordered := uc.orderDriversForFairness(context.Background(), []*models.NearbyUser{recent, unknown})
+
+		assert.Equal(t, []string{unknown.ID, recent.ID}, nearbyIDs(ordered))
+	})
+
+	t.Run("lookup failure keeps nearest-first order", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockMatchRepo(ctrl)
+		uc := NewMatchUC(&models.Config{Match: models.MatchConfig{FairnessMode: true}}, mockRepo, mocks.NewMockMatchGW(ctrl))
+
+		drivers := []*models.NearbyUser{nearbyDriverAtKm(0.1), nearbyDriverAtKm(0.2)}
+		mockRepo.EXPECT().GetDriversLastRideAt(gomock.Any(), gomock.Any()).Return(nil, errors.New("redis down"))
+
+		assert.Equal(t, nearbyIDs(drivers), nearbyIDs(uc.orderDriversForFairness(context.Background(), drivers)))
+	})
+}
+
+func TestCreateMatchesWithNearbyDrivers_FairnessLimitsProposals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{Match: models.MatchConfig{SearchRadiusKm: 5.0, FairnessMode: true, FairnessBandKm: 0.5, FairnessMaxProposals: 2}}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	passengerID := uuid.New().String()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	target := &models.Location{Latitude: -6.200000, Longitude: 106.816666}
+
+	now := time.Now()
+	busy := nearbyDriverAtKm(1.1)
+	waiting := nearbyDriverAtKm(1.2)
+	longestWaiting := nearbyDriverAtKm(1.3)
+	far := nearbyDriverAtKm(3.0)
+	mockGW.EXPECT().
+		FindNearbyDrivers(gomock.Any(), location, 5.0).
+		Return([]*models.NearbyUser{busy, waiting, longestWaiting, far}, nil)
+	mockRepo.EXPECT().
+		GetDriversLastRideAt(gomock.Any(), gomock.Any()).
+		Return(map[string]time.Time{
+			busy.ID:           now.Add(-5 * time.Minute),
+			waiting.ID:        now.Add(-time.Hour),
+			longestWaiting.ID: now.Add(-2 * time.Hour),
+		}, nil)
+
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), passengerID, gomock.Any(), gomock.Any()).Return(true, nil).Times(2)
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	var proposed []string
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, m *models.Match) (*models.Match, error) {
+			proposed = append(proposed, m.DriverID.String())
+			m.ID = uuid.New()
+			return m, nil
+		}).Times(2)
+
+	require.NoError(t, uc.createMatchesWithNearbyDrivers(context.Background(), passengerID, location, target, "", ""))
+
+	// The nearest driver, who finished a ride most recently, is not offered this one
+	assert.Equal(t, []string{longestWaiting.ID, waiting.ID}, proposed)
+}
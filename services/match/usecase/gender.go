@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// recordDriverGender keeps the gender a driver's beacon carries so passengers with a driver gender
+// preference can be matched with them. Drivers who haven't given a gender are not recorded.
+func (uc *MatchUC) recordDriverGender(ctx context.Context, driverID, gender string) {
+	if gender == "" {
+		return
+	}
+	if err := uc.matchRepo.SetDriverGender(ctx, driverID, gender); err != nil {
+		logger.Warn("Failed to record driver gender",
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+	}
+}
+
+// filterDriversByGender keeps only the drivers of the passenger's preferred gender, or every driver
+// when there is no preference. Drivers with no gender on record never satisfy a preference, and
+// when the genders can't be read no driver is kept rather than risk an unwanted match.
+func (uc *MatchUC) filterDriversByGender(ctx context.Context, drivers []*models.NearbyUser, gender string) []*models.NearbyUser {
+	if gender == "" || len(drivers) == 0 {
+		return drivers
+	}
+
+	driverIDs := make([]string, len(drivers))
+	for i, driver := range drivers {
+		driverIDs[i] = driver.ID
+	}
+
+	genders, err := uc.matchRepo.GetDriverGenders(ctx, driverIDs)
+	if err != nil {
+		logger.Error("Failed to get driver genders, no driver can meet the gender preference",
+			logger.String("driver_gender", gender),
+			logger.ErrorField(err))
+		return nil
+	}
+
+	matching := make([]*models.NearbyUser, 0, len(drivers))
+	for _, driver := range drivers {
+		if genders[driver.ID] == gender {
+			matching = append(matching, driver)
+		}
+	}
+	return matching
+}
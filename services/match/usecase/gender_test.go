@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// proposedDrivers runs a search with the given driver gender preference against female, male and
+// unspecified drivers, returning the drivers proposed to the passenger
+func proposedDrivers(t *testing.T, preference string) (proposed []string, female, male, unknown *models.NearbyUser) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{Match: models.MatchConfig{SearchRadiusKm: 5.0}}, mockRepo, mockGW)
+
+	passengerID := uuid.New().String()
+	location := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	target := &models.Location{Latitude: -6.200000, Longitude: 106.816666}
+
+	male = nearbyDriverAtKm(0.5)
+	female = nearbyDriverAtKm(1.0)
+	unknown = nearbyDriverAtKm(1.5)
+	mockGW.EXPECT().
+		FindNearbyDrivers(gomock.Any(), location, 5.0).
+		Return([]*models.NearbyUser{male, female, unknown}, nil)
+	if preference != "" {
+		mockRepo.EXPECT().
+			GetDriverGenders(gomock.Any(), []string{male.ID, female.ID, unknown.ID}).
+			Return(map[string]string{male.ID: models.GenderMale, female.ID: models.GenderFemale}, nil)
+	}
+
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), passengerID, gomock.Any(), gomock.Any()).Return(true, nil).AnyTimes()
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, m *models.Match) (*models.Match, error) {
+			proposed = append(proposed, m.DriverID.String())
+			m.ID = uuid.New()
+			return m, nil
+		}).AnyTimes()
+
+	require.NoError(t, uc.createMatchesWithNearbyDrivers(context.Background(), passengerID, location, target, preference, ""))
+	return proposed, female, male, unknown
+}
+
+func TestCreateMatchesWithNearbyDrivers_FemaleOnlyPreference(t *testing.T) {
+	proposed, female, _, _ := proposedDrivers(t, models.GenderFemale)
+
+	// The nearer male driver and the driver with no gender on record are left out
+	assert.Equal(t, []string{female.ID}, proposed)
+}
+
+func TestCreateMatchesWithNearbyDrivers_NoGenderPreference(t *testing.T) {
+	proposed, female, male, unknown := proposedDrivers(t, "")
+
+	assert.Equal(t, []string{male.ID, female.ID, unknown.ID}, proposed)
+}
+
+func TestFilterDriversByGender_LookupFailureKeepsNoDriver(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	mockRepo.EXPECT().GetDriverGenders(gomock.Any(), gomock.Any()).Return(nil, errors.New("redis down"))
+
+	drivers := []*models.NearbyUser{nearbyDriverAtKm(0.5)}
+	assert.Empty(t, uc.filterDriversByGender(context.Background(), drivers, models.GenderFemale))
+}
+
+func TestHandleBeaconEvent_RecordsDriverGender(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	driverID := uuid.New().String()
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), driverID).Return(false, nil)
+	mockGW.EXPECT().AddAvailableDriver(gomock.Any(), driverID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().SetDriverGender(gomock.Any(), driverID, models.GenderFemale).Return(nil)
+
+	err := uc.HandleBeaconEvent(context.Background(), models.BeaconEvent{
+		UserID:   driverID,
+		IsActive: true,
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: time.Now()},
+		Gender:   models.GenderFemale,
+	})
+
+	assert.NoError(t, err)
+}
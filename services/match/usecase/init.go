@@ -1,6 +1,10 @@
 package usecase
 
 import (
+	"sync/atomic"
+
+	"github.com/piresc/nebengjek/internal/pkg/clock"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/piresc/nebengjek/services/match"
 )
@@ -10,6 +14,15 @@ type MatchUC struct {
 	matchRepo match.MatchRepo
 	matchGW   match.MatchGW
 	cfg       *models.Config
+
+	// clock tells the time for timestamps, deadlines and windows, so tests can control it
+	clock clock.Clock
+
+	// maintenance mirrors the runtime flag in Redis so events don't hit Redis to check it
+	maintenance atomic.Bool
+
+	// reloaded holds the config with tunables applied by the last reload, nil until the first one
+	reloaded atomic.Pointer[models.Config]
 }
 
 // NewMatchUC creates a new match use case
@@ -22,5 +35,24 @@ func NewMatchUC(
 		cfg:       cfg,
 		matchRepo: matchRepo,
 		matchGW:   matchGW,
+		clock:     clock.Real{},
+	}
+}
+
+// config returns the settings in effect, including tunables reloaded at runtime
+func (uc *MatchUC) config() *models.Config {
+	if reloaded := uc.reloaded.Load(); reloaded != nil {
+		return reloaded
 	}
+	return uc.cfg
+}
+
+// ReloadConfig applies the tunable settings of a freshly loaded config, such as the search
+// radius, to subsequent matching
+func (uc *MatchUC) ReloadConfig(reloaded *models.Config) {
+	uc.reloaded.Store(uc.cfg.WithTunables(reloaded))
+
+	logger.Info("Reloaded match settings",
+		logger.Float64("search_radius_km", reloaded.Match.SearchRadiusKm),
+		logger.Int("regions", len(reloaded.Regions)))
 }
@@ -0,0 +1,82 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
+)
+
+// defaultMaintenancePollInterval is how often the runtime maintenance flag is re-read when none is given
+const defaultMaintenancePollInterval = 5 * time.Second
+
+// IsMaintenanceMode reports whether new matching is refused, either by configuration or at runtime.
+// Ride lifecycle operations are unaffected so in-progress rides can finish.
+func (uc *MatchUC) IsMaintenanceMode(ctx context.Context) bool {
+	return uc.cfg.Match.MaintenanceMode || uc.maintenance.Load()
+}
+
+// refuseDuringMaintenance tells the user their beacon or search was refused for maintenance rather
+// than leaving them waiting, and returns ErrMaintenanceMode once they have been told
+func (uc *MatchUC) refuseDuringMaintenance(ctx context.Context, userID, role string) error {
+	event := models.MatchMaintenanceEvent{
+		UserID:    userID,
+		Role:      role,
+		Timestamp: uc.clock.Now(),
+	}
+	if err := uc.matchGW.PublishMatchMaintenance(ctx, event); err != nil {
+		logger.Error("Failed to publish match maintenance event",
+			logger.String("user_id", userID),
+			logger.String("role", role),
+			logger.ErrorField(err))
+		return err
+	}
+	return match.ErrMaintenanceMode
+}
+
+// SetMaintenanceMode switches runtime maintenance mode on or off. Other instances pick up the
+// change on their next poll.
+func (uc *MatchUC) SetMaintenanceMode(ctx context.Context, enabled bool) error {
+	if err := uc.matchRepo.SetMaintenanceMode(ctx, enabled); err != nil {
+		return err
+	}
+	uc.maintenance.Store(enabled)
+
+	logger.Warn("Match maintenance mode changed", logger.Bool("enabled", enabled))
+	return nil
+}
+
+// refreshMaintenanceMode re-reads the runtime flag, keeping the last known value on errors
+func (uc *MatchUC) refreshMaintenanceMode(ctx context.Context) {
+	enabled, err := uc.matchRepo.GetMaintenanceMode(ctx)
+	if err != nil {
+		logger.Error("Failed to read maintenance mode", logger.ErrorField(err))
+		return
+	}
+	if uc.maintenance.Swap(enabled) != enabled {
+		logger.Warn("Match maintenance mode changed", logger.Bool("enabled", enabled))
+	}
+}
+
+// WatchMaintenanceMode keeps the runtime maintenance flag in sync until ctx is cancelled
+func (uc *MatchUC) WatchMaintenanceMode(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultMaintenancePollInterval
+	}
+
+	uc.refreshMaintenanceMode(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			uc.refreshMaintenanceMode(ctx)
+		}
+	}
+}
@@ -2,17 +2,114 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/converter"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/pkg/region"
+	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/match"
 )
 
+// defaultRideLockTTL is used when no active ride TTL is configured
+const defaultRideLockTTL = 24 * time.Hour
+
+// defaultMaxLocationAge is used when no maximum location age is configured
+const defaultMaxLocationAge = 60 * time.Second
+
+// defaultProposalDedupWindow is used when no proposal dedup window is configured
+const defaultProposalDedupWindow = 30 * time.Second
+
+// isLocationStale reports whether a location is too old to match against. Locations without a
+// timestamp of their own are as old as the event carrying them, sent at sentAt; when neither
+// is set the location is treated as fresh.
+func (uc *MatchUC) isLocationStale(location models.Location, sentAt time.Time) bool {
+	recordedAt := location.Timestamp
+	if recordedAt.IsZero() {
+		recordedAt = sentAt
+	}
+	if recordedAt.IsZero() {
+		return false
+	}
+
+	maxAge := defaultMaxLocationAge
+	if uc.cfg != nil && uc.cfg.Match.MaxLocationAgeSecs > 0 {
+		maxAge = time.Duration(uc.cfg.Match.MaxLocationAgeSecs) * time.Second
+	}
+	return uc.clock.Now().Sub(recordedAt) > maxAge
+}
+
+// rideLockTTL returns how long a per-user ride lock lives. Locks are released when the ride
+// completes or is cancelled, so they share the active ride's TTL and only expire on their own
+// if that release is lost, however long the ride runs.
+func (uc *MatchUC) rideLockTTL() time.Duration {
+	if uc.cfg != nil && uc.cfg.Match.ActiveRideTTLHours > 0 {
+		return time.Duration(uc.cfg.Match.ActiveRideTTLHours) * time.Hour
+	}
+	return defaultRideLockTTL
+}
+
+// isRideLocked reports whether a user is held by a ride lock, treating lookup errors as unlocked
+func (uc *MatchUC) isRideLocked(ctx context.Context, userID string) bool {
+	locked, err := uc.matchRepo.IsRideLocked(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to check ride lock",
+			logger.String("user_id", userID),
+			logger.ErrorField(err))
+		// Continue on error to avoid blocking
+		return false
+	}
+	return locked
+}
+
+// proposalDedupWindow returns how long a driver is not re-proposed to the same passenger
+func (uc *MatchUC) proposalDedupWindow() time.Duration {
+	if uc.cfg != nil && uc.cfg.Match.ProposalDedupSecs > 0 {
+		return time.Duration(uc.cfg.Match.ProposalDedupSecs) * time.Second
+	}
+	return defaultProposalDedupWindow
+}
+
+// claimProposal reports whether a driver may be proposed to a passenger, treating lookup errors as claimable
+func (uc *MatchUC) claimProposal(ctx context.Context, passengerID, driverID string) bool {
+	claimed, err := uc.matchRepo.ClaimMatchProposal(ctx, passengerID, driverID, uc.proposalDedupWindow())
+	if err != nil {
+		logger.Error("Failed to claim match proposal",
+			logger.String("passenger_id", passengerID),
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+		// Continue on error to avoid blocking
+		return true
+	}
+	return claimed
+}
+
+// releaseProposal drops a claim whose proposal was never created, so the driver can be proposed to
+// the passenger again on their next search
+func (uc *MatchUC) releaseProposal(ctx context.Context, passengerID, driverID string) {
+	if err := uc.matchRepo.ReleaseMatchProposal(ctx, passengerID, driverID); err != nil {
+		logger.Warn("Failed to release match proposal claim",
+			logger.String("passenger_id", passengerID),
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+	}
+}
+
 // addDriverToPool adds a driver to the available pool without creating matches
 func (uc *MatchUC) addDriverToPool(ctx context.Context, driverID string, location *models.Location) error {
+	// Driver is being locked into a ride, don't re-add them unless they opted in to their next match
+	if uc.isRideLocked(ctx, driverID) && !uc.hasBackToBackOptIn(ctx, driverID) {
+		logger.Info("Driver is ride locked, skipping addition to available pool",
+			logger.String("driver_id", driverID))
+		return nil
+	}
+
 	// Add driver to available pool
 	if err := uc.matchGW.AddAvailableDriver(ctx, driverID, location); err != nil {
 		logger.Error("Failed to add available driver",
@@ -24,27 +121,101 @@ func (uc *MatchUC) addDriverToPool(ctx context.Context, driverID string, locatio
 }
 
 // createMatchesWithNearbyDrivers finds nearby drivers and creates match proposals
-func (uc *MatchUC) createMatchesWithNearbyDrivers(ctx context.Context, passengerID string, passengerLocation, targetLocation *models.Location) error {
-	nearbyDrivers, err := uc.matchGW.FindNearbyDrivers(ctx, passengerLocation, uc.cfg.Match.SearchRadiusKm) // Configurable radius
+func (uc *MatchUC) createMatchesWithNearbyDrivers(ctx context.Context, passengerID string, passengerLocation, targetLocation *models.Location, driverGender string, paymentMethod models.PaymentMethod) error {
+	// Dense cities search a smaller area than rural pickups
+	pickupRegion := region.Resolve(uc.config(), *passengerLocation)
+
+	nearbyDrivers, err := uc.matchGW.FindNearbyDrivers(ctx, passengerLocation, pickupRegion.SearchRadiusKm)
 	if err != nil {
 		logger.Error("Failed to find nearby drivers",
 			logger.String("passenger_id", passengerID),
-			logger.Float64("search_radius_km", uc.cfg.Match.SearchRadiusKm),
+			logger.String("region", pickupRegion.Name),
+			logger.Float64("search_radius_km", pickupRegion.SearchRadiusKm),
 			logger.ErrorField(err))
 		return err
 	}
 
-	// Create match proposals for each nearby driver
+	// Passengers with a driver gender preference are only offered drivers of that gender
+	nearbyDrivers = uc.filterDriversByGender(ctx, nearbyDrivers, driverGender)
+
+	// Fairness mode spreads rides among comparably close drivers
+	nearbyDrivers = uc.orderDriversForFairness(ctx, nearbyDrivers)
+
+	// Create match proposals for each nearby driver, or only the front of the fairness order
+	created, suppressed := 0, 0
+	limitReached := false
+	proposalLimit := uc.fairnessProposalLimit()
 	for _, driver := range nearbyDrivers {
-		match := uc.buildMatch(driver.ID, passengerID, &driver.Location, passengerLocation, targetLocation)
+		// Drivers still holding this passenger's proposal count towards the limit too
+		if proposalLimit > 0 && created+suppressed >= proposalLimit {
+			logger.Debug("Fairness proposal limit reached, not proposing further drivers",
+				logger.String("passenger_id", passengerID),
+				logger.Int("limit", proposalLimit))
+			break
+		}
+
+		// A user who is both an available driver and searching as a passenger must not be proposed to themselves
+		if driver.ID == passengerID {
+			logger.Warn("Skipping nearby driver who is the searching passenger",
+				logger.String("passenger_id", passengerID))
+			continue
+		}
+
+		// Drivers serving a cancellation penalty are not offered new rides until it expires
+		if uc.isDriverSuspended(ctx, driver.ID) {
+			logger.Debug("Skipping suspended driver",
+				logger.String("driver_id", driver.ID),
+				logger.String("passenger_id", passengerID))
+			continue
+		}
+
+		// Repeated finder events must not re-notify a driver before the pending match row exists
+		if !uc.claimProposal(ctx, passengerID, driver.ID) {
+			logger.Debug("Driver already proposed to passenger recently, skipping",
+				logger.String("driver_id", driver.ID),
+				logger.String("passenger_id", passengerID))
+			suppressed++
+			continue
+		}
+
+		driverMatch := uc.buildMatch(driver.ID, passengerID, &driver.Location, passengerLocation, targetLocation)
+		driverMatch.PaymentMethod = paymentMethod
+
+		if err := uc.CreateMatch(ctx, driverMatch); err != nil {
+			uc.releaseProposal(ctx, passengerID, driver.ID)
 
-		if err := uc.CreateMatch(ctx, match); err != nil {
+			// The passenger still has proposals outstanding, so stop without telling them nobody is available
+			if errors.Is(err, match.ErrPendingMatchLimit) {
+				logger.Warn("Passenger reached pending match limit, not proposing further drivers",
+					logger.String("passenger_id", passengerID),
+					logger.ErrorField(err))
+				limitReached = true
+				break
+			}
 			logger.Error("Failed to create match with driver",
 				logger.String("driver_id", driver.ID),
 				logger.String("passenger_id", passengerID),
 				logger.ErrorField(err))
 			continue
 		}
+		created++
+	}
+
+	// Let the passenger know nobody is available rather than leaving them waiting.
+	// Suppressed drivers already hold a proposal for this passenger, so they still count as available.
+	if created == 0 && suppressed == 0 && !limitReached {
+		event := models.NoDriversFoundEvent{
+			PassengerID:      passengerID,
+			SearchRadiusKm:   pickupRegion.SearchRadiusKm,
+			DriversAttempted: len(nearbyDrivers),
+			Timestamp:        uc.clock.Now(),
+		}
+		if err := uc.matchGW.PublishNoDriversFound(ctx, event); err != nil {
+			logger.Error("Failed to publish no drivers found event",
+				logger.String("passenger_id", passengerID),
+				logger.ErrorField(err))
+			return err
+		}
 	}
 
 	return nil
@@ -58,8 +229,8 @@ func (uc *MatchUC) buildMatch(driverID, passengerID string, driverLocation, pass
 		DriverLocation:    *driverLocation,
 		PassengerLocation: *passengerLocation,
 		Status:            models.MatchStatusPending,
-		CreatedAt:         time.Now(),
-		UpdatedAt:         time.Now(),
+		CreatedAt:         uc.clock.Now(),
+		UpdatedAt:         uc.clock.Now(),
 	}
 
 	if targetLocation != nil {
@@ -69,7 +240,58 @@ func (uc *MatchUC) buildMatch(driverID, passengerID string, driverLocation, pass
 	return match
 }
 
+// refreshPendingProposals moves a passenger's pending matches to their latest position and
+// rejects proposals whose driver is no longer within the search radius. Nearer drivers are
+// proposed by the regular search that follows. Errors are logged so matching can continue.
+func (uc *MatchUC) refreshPendingProposals(ctx context.Context, passengerID string, location *models.Location) {
+	matches, err := uc.matchRepo.UpdatePendingMatchesPassengerLocation(ctx, converter.StrToUUID(passengerID), *location)
+	if err != nil {
+		logger.Error("Failed to update passenger location on pending matches",
+			logger.String("passenger_id", passengerID),
+			logger.ErrorField(err))
+		return
+	}
+
+	radiusKm := region.Resolve(uc.config(), *location).SearchRadiusKm
+	if radiusKm <= 0 {
+		return
+	}
+
+	passengerPoint := utils.GeoPoint{Latitude: location.Latitude, Longitude: location.Longitude}
+	rejectionBatch := make([]string, 0)
+	eventBatch := make([]models.MatchProposal, 0)
+	for _, match := range matches {
+		driverPoint := utils.GeoPoint{Latitude: match.DriverLocation.Latitude, Longitude: match.DriverLocation.Longitude}
+		if utils.CalculateDistance(driverPoint, passengerPoint) <= radiusKm {
+			continue
+		}
+		rejectionBatch = append(rejectionBatch, match.ID.String())
+		eventBatch = append(eventBatch, uc.createRejectionEvent(match))
+	}
+
+	if len(rejectionBatch) == 0 {
+		return
+	}
+
+	logger.Info("Withdrawing proposals to drivers now out of range of passenger",
+		logger.String("passenger_id", passengerID),
+		logger.Int("withdrawn", len(rejectionBatch)))
+
+	if err := uc.processRejectionBatch(ctx, rejectionBatch, eventBatch); err != nil {
+		logger.Error("Failed to withdraw out of range proposals",
+			logger.String("passenger_id", passengerID),
+			logger.ErrorField(err))
+	}
+}
+
 func (uc *MatchUC) handleActivePassengerWithTarget(ctx context.Context, event models.FinderEvent, location *models.Location, targetLocation *models.Location) error {
+	// Passenger is being locked into a ride, don't re-add them
+	if uc.isRideLocked(ctx, event.UserID) {
+		logger.Info("Passenger is ride locked, skipping addition to available pool",
+			logger.String("passenger_id", event.UserID))
+		return nil
+	}
+
 	if err := uc.matchGW.AddAvailablePassenger(ctx, event.UserID, location); err != nil {
 		logger.Error("Failed to add available passenger",
 			logger.String("passenger_id", event.UserID),
@@ -77,8 +299,11 @@ func (uc *MatchUC) handleActivePassengerWithTarget(ctx context.Context, event mo
 		return err
 	}
 
+	// Earlier proposals were made against where the passenger used to be
+	uc.refreshPendingProposals(ctx, event.UserID, location)
+
 	// Find nearby drivers to match with
-	return uc.createMatchesWithNearbyDrivers(ctx, event.UserID, location, targetLocation)
+	return uc.createMatchesWithNearbyDrivers(ctx, event.UserID, location, targetLocation, event.DriverGender, event.PaymentMethod)
 }
 
 func (uc *MatchUC) handleInactiveUser(ctx context.Context, userID string, role string) error {
@@ -108,20 +333,46 @@ func (uc *MatchUC) HandleBeaconEvent(ctx context.Context, event models.BeaconEve
 	}
 
 	if event.IsActive {
+		if uc.IsMaintenanceMode(ctx) {
+			return uc.refuseDuringMaintenance(ctx, event.UserID, "driver")
+		}
+
+		// Buffered beacons can arrive long after the driver has moved on
+		if uc.isLocationStale(event.Location, event.Timestamp) {
+			logger.Warn("Skipping stale beacon event",
+				logger.String("driver_id", event.UserID),
+				logger.String("location_timestamp", event.Location.Timestamp.Format(time.RFC3339)),
+				logger.String("event_timestamp", event.Timestamp.Format(time.RFC3339)))
+			return nil
+		}
+
+		// Drivers opting in to back-to-back rides are matched from here too, so keep their gender current
+		uc.recordDriverGender(ctx, event.UserID, event.Gender)
+
 		// Check if driver has an active ride before adding to pool
-		hasActiveRide, err := uc.HasActiveRide(ctx, event.UserID, true) // true = isDriver
+		activeRideID, err := uc.matchRepo.GetActiveRideByDriver(ctx, event.UserID)
 		if err != nil {
 			logger.Error("Failed to check active ride for driver",
 				logger.String("driver_id", event.UserID),
 				logger.ErrorField(err))
 			// Continue with adding to pool on error to avoid blocking
-		} else if hasActiveRide {
+		} else if activeRideID != "" {
+			// Drivers about to finish their ride may opt in to be matched for the next one early
+			if event.AcceptingNext && uc.config().Match.BackToBackRides {
+				return uc.acceptNextRide(ctx, event.UserID, activeRideID, location)
+			}
 			// Driver has active ride, skipping addition to available pool
 			return nil
 		}
 
 		// Beacon events are only for drivers
-		return uc.addDriverToPool(ctx, event.UserID, location)
+		if err := uc.addDriverToPool(ctx, event.UserID, location); err != nil {
+			return err
+		}
+
+		// Passengers weighing this driver's proposals should see where they are now
+		uc.refreshDriverProposals(ctx, event.UserID, location)
+		return nil
 	}
 
 	return uc.handleInactiveUser(ctx, event.UserID, "driver")
@@ -129,6 +380,16 @@ func (uc *MatchUC) HandleBeaconEvent(ctx context.Context, event models.BeaconEve
 
 // HandleFinderEvent processes finder events from NATS for passengers
 func (uc *MatchUC) HandleFinderEvent(ctx context.Context, event models.FinderEvent) error {
+	err := uc.handleFinderEvent(ctx, event)
+	if errors.Is(err, match.ErrMaintenanceMode) {
+		return uc.refuseDuringMaintenance(ctx, event.UserID, "passenger")
+	}
+	return err
+}
+
+// handleFinderEvent processes a finder event, returning ErrMaintenanceMode without telling the
+// passenger when their search is refused
+func (uc *MatchUC) handleFinderEvent(ctx context.Context, event models.FinderEvent) error {
 
 	location := &models.Location{
 		Latitude:  event.Location.Latitude,
@@ -141,6 +402,24 @@ func (uc *MatchUC) HandleFinderEvent(ctx context.Context, event models.FinderEve
 	}
 
 	if event.IsActive {
+		// Pre-booked rides wait in the schedule until they are due
+		if isScheduledForLater(event, uc.clock.Now()) {
+			return uc.scheduleFinderEvent(ctx, event)
+		}
+
+		if uc.IsMaintenanceMode(ctx) {
+			return match.ErrMaintenanceMode
+		}
+
+		// Don't create matches against a position the passenger has likely left
+		if uc.isLocationStale(event.Location, event.Timestamp) {
+			logger.Warn("Skipping stale finder event",
+				logger.String("passenger_id", event.UserID),
+				logger.String("location_timestamp", event.Location.Timestamp.Format(time.RFC3339)),
+				logger.String("event_timestamp", event.Timestamp.Format(time.RFC3339)))
+			return nil
+		}
+
 		// Check if passenger has an active ride before adding to pool
 		hasActiveRide, err := uc.HasActiveRide(ctx, event.UserID, false) // false = isPassenger
 		if err != nil {
@@ -157,19 +436,31 @@ func (uc *MatchUC) HandleFinderEvent(ctx context.Context, event models.FinderEve
 		return uc.handleActivePassengerWithTarget(ctx, event, location, targetLocation)
 	}
 
+	// Turning the finder off also cancels any ride the passenger scheduled
+	uc.cancelScheduledFinderEvent(ctx, event.UserID)
 	return uc.handleInactiveUser(ctx, event.UserID, "passenger")
 }
 
 // CreateMatch creates a new match and publishes a match proposal event
 func (uc *MatchUC) CreateMatch(ctx context.Context, match *models.Match) error {
+	if err := validateMatchParticipants(match); err != nil {
+		return err
+	}
+
+	if err := uc.checkPendingMatchLimit(ctx, match.PassengerID); err != nil {
+		return err
+	}
+
 	// Create match directly in database, which will check for existing pending matches
 	createdMatch, err := uc.matchRepo.CreateMatch(ctx, match)
 	if err != nil {
 		return fmt.Errorf("failed to create match: %w", err)
 	}
 
-	// Create match proposal for notification
+	// Create match proposal for notification, with the deadline the driver app counts down to
 	matchProposal := uc.buildMatchProposal(createdMatch)
+	expiresAt := uc.proposalExpiresAt(createdMatch)
+	matchProposal.ExpiresAt = &expiresAt
 
 	// Publish match proposal event
 	if err := uc.matchGW.PublishMatchFound(ctx, matchProposal); err != nil {
@@ -179,9 +470,35 @@ func (uc *MatchUC) CreateMatch(ctx context.Context, match *models.Match) error {
 	return nil
 }
 
-// buildMatchProposal creates a match proposal from a match object
+// validateMatchParticipants rejects matches that pair a user with themselves, e.g. from bad event data
+func validateMatchParticipants(m *models.Match) error {
+	if m.DriverID == m.PassengerID {
+		return fmt.Errorf("%w: %s", match.ErrSelfMatch, m.DriverID)
+	}
+	return nil
+}
+
+// checkPendingMatchLimit refuses new matches once the passenger holds the configured number of unanswered ones
+func (uc *MatchUC) checkPendingMatchLimit(ctx context.Context, passengerID uuid.UUID) error {
+	limit := uc.cfg.Match.MaxPendingPerPassenger
+	if limit <= 0 {
+		return nil
+	}
+
+	count, err := uc.matchRepo.CountPendingMatchesByPassenger(ctx, passengerID)
+	if err != nil {
+		return fmt.Errorf("failed to count pending matches: %w", err)
+	}
+	if count >= limit {
+		return fmt.Errorf("%w (%d of %d)", match.ErrPendingMatchLimit, count, limit)
+	}
+	return nil
+}
+
+// buildMatchProposal creates a match proposal from a match object, including the trip preview
+// when the destination is known
 func (uc *MatchUC) buildMatchProposal(match *models.Match) models.MatchProposal {
-	return models.MatchProposal{
+	proposal := models.MatchProposal{
 		ID:             match.ID.String(),
 		PassengerID:    converter.UUIDToStr(match.PassengerID),
 		DriverID:       converter.UUIDToStr(match.DriverID),
@@ -189,7 +506,29 @@ func (uc *MatchUC) buildMatchProposal(match *models.Match) models.MatchProposal
 		DriverLocation: match.DriverLocation,
 		TargetLocation: match.TargetLocation,
 		MatchStatus:    match.Status,
+		PaymentMethod:  match.PaymentMethod,
 	}
+
+	if match.TargetLocation.Latitude != 0 || match.TargetLocation.Longitude != 0 {
+		proposal.EstimatedDistanceKm, proposal.EstimatedFare, proposal.EstimatedEarnings =
+			uc.estimateTrip(match.PassengerLocation, match.TargetLocation)
+	}
+	return proposal
+}
+
+// estimateTrip prices the straight-line trip from pickup to target at the pickup region's rate and
+// returns the distance rounded to 10 meters, the fare and the driver's earnings after the admin fee
+func (uc *MatchUC) estimateTrip(pickup, target models.Location) (float64, int, int) {
+	distanceKm := utils.CalculateDistance(
+		utils.GeoPoint{Latitude: pickup.Latitude, Longitude: pickup.Longitude},
+		utils.GeoPoint{Latitude: target.Latitude, Longitude: target.Longitude},
+	)
+	distanceKm = math.Round(distanceKm*100) / 100
+
+	cfg := uc.config()
+	fare := int(math.Round(distanceKm * region.Resolve(cfg, pickup).RatePerKm))
+	_, earnings := cfg.Pricing.SplitFare(fare)
+	return distanceKm, fare, earnings
 }
 
 // updateMatchConfirmation updates match confirmation status based on user type
@@ -207,8 +546,7 @@ func (uc *MatchUC) updateMatchConfirmation(ctx context.Context, match *models.Ma
 			logger.String("match_id", match.ID.String()))
 
 		// Remove users from available pools when fully confirmed
-		uc.matchGW.RemoveAvailableDriver(ctx, match.DriverID.String())
-		uc.matchGW.RemoveAvailablePassenger(ctx, match.PassengerID.String())
+		uc.removeMatchedUsersFromPools(ctx, match)
 	} else if match.DriverConfirmed {
 		match.Status = models.MatchStatusDriverConfirmed
 		// Match confirmed by driver, waiting for passenger
@@ -217,13 +555,17 @@ func (uc *MatchUC) updateMatchConfirmation(ctx context.Context, match *models.Ma
 		// Match confirmed by passenger, waiting for driver
 	}
 
-	match.UpdatedAt = time.Now()
+	match.UpdatedAt = uc.clock.Now()
 	return uc.matchRepo.ConfirmMatchByUser(ctx, match.ID.String(), userID, isDriver)
 }
 
-// handleMatchAcceptance processes match acceptance logic
+// handleMatchAcceptance processes match acceptance logic. With an acceptance window configured, a
+// passenger may only accept a match whose driver already accepted.
 func (uc *MatchUC) handleMatchAcceptance(ctx context.Context, match *models.Match, req *models.MatchConfirmRequest) (models.MatchProposal, error) {
 	isDriver := req.UserID == match.DriverID.String()
+	if err := uc.checkPassengerChoice(match, isDriver); err != nil {
+		return models.MatchProposal{}, err
+	}
 
 	updatedMatch, err := uc.updateMatchConfirmation(ctx, match, req.UserID, isDriver)
 	if err != nil {
@@ -235,8 +577,14 @@ func (uc *MatchUC) handleMatchAcceptance(ctx context.Context, match *models.Matc
 
 	// If match is fully accepted, handle auto-rejection asynchronously
 	if updatedMatch.Status == models.MatchStatusAccepted {
-		uc.startAsyncAutoRejection(updatedMatch)
+		uc.startAsyncAutoRejection(ctx, updatedMatch)
 		uc.PublishMatchAccepted(ctx, updatedMatch)
+		uc.recordDriverAccepted(ctx, updatedMatch)
+	}
+
+	// With an acceptance window the passenger chooses among the drivers who accept within it
+	if updatedMatch.Status == models.MatchStatusDriverConfirmed && uc.acceptanceWindow() > 0 {
+		uc.openAcceptanceWindow(ctx, updatedMatch)
 	}
 
 	responseEvent := uc.buildMatchProposal(updatedMatch)
@@ -246,16 +594,10 @@ func (uc *MatchUC) handleMatchAcceptance(ctx context.Context, match *models.Matc
 }
 
 func (uc *MatchUC) PublishMatchAccepted(ctx context.Context, match *models.Match) {
-	// Create match proposal for accepted match
-	PublishMatchAccepted := models.MatchProposal{
-		ID:             match.ID.String(),
-		PassengerID:    converter.UUIDToStr(match.PassengerID),
-		DriverID:       converter.UUIDToStr(match.DriverID),
-		UserLocation:   match.PassengerLocation,
-		DriverLocation: match.DriverLocation,
-		TargetLocation: match.TargetLocation,
-		MatchStatus:    match.Status,
-	}
+	// Create match proposal for accepted match. The trip estimate travels with it so the ride can
+	// later compare the fare charged with the one quoted.
+	PublishMatchAccepted := uc.buildMatchProposal(match)
+	PublishMatchAccepted.Navigation = models.NewPickupNavigation(match.PassengerLocation)
 
 	if err := uc.matchGW.PublishMatchAccepted(ctx, PublishMatchAccepted); err != nil {
 		logger.Error("Failed to publish match accepted event",
@@ -264,8 +606,12 @@ func (uc *MatchUC) PublishMatchAccepted(ctx context.Context, match *models.Match
 	}
 }
 
-// startAsyncAutoRejection initiates the asynchronous auto-rejection process
-func (uc *MatchUC) startAsyncAutoRejection(match *models.Match) {
+// startAsyncAutoRejection initiates the asynchronous auto-rejection process. Failures are retried
+// and, once the retries run out, queued for the reconcile worker.
+func (uc *MatchUC) startAsyncAutoRejection(ctx context.Context, match *models.Match) {
+	// The request's transaction ends before the retries do, so keep only its application for metrics
+	app := newrelic.FromContext(ctx).Application()
+
 	// Create context with timeout for background operation
 	bgCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 
@@ -273,15 +619,12 @@ func (uc *MatchUC) startAsyncAutoRejection(match *models.Match) {
 	go func() {
 		defer cancel() // Ensure context is cleaned up
 
-		if err := uc.handleAutoRejectionForAcceptedMatch(bgCtx, match); err != nil {
-			logger.Error("Critical: Failed to handle auto-rejection for match",
-				logger.String("match_id", match.ID.String()),
-				logger.ErrorField(err))
-		}
+		uc.autoRejectWithRetry(bgCtx, match, app)
 	}()
 }
 
-// handleAutoRejectionForAcceptedMatch rejects all other pending matches for the same passenger
+// handleAutoRejectionForAcceptedMatch rejects all other pending matches for the same passenger that
+// were created by the time the match was accepted. Later matches belong to a new search and stay open.
 func (uc *MatchUC) handleAutoRejectionForAcceptedMatch(ctx context.Context, acceptedMatch *models.Match) error {
 	// Add timeout check
 	select {
@@ -291,7 +634,7 @@ func (uc *MatchUC) handleAutoRejectionForAcceptedMatch(ctx context.Context, acce
 	}
 
 	// Get all pending matches for this passenger
-	matches, err := uc.matchRepo.ListMatchesByPassenger(ctx, acceptedMatch.PassengerID)
+	matches, err := uc.matchRepo.ListOpenMatchesByPassenger(ctx, acceptedMatch.PassengerID)
 	if err != nil {
 		return fmt.Errorf("failed to list passenger matches: %w", err)
 	}
@@ -313,6 +656,11 @@ func (uc *MatchUC) handleAutoRejectionForAcceptedMatch(ctx context.Context, acce
 			continue
 		}
 
+		// Skip matches proposed after the acceptance
+		if !acceptedMatch.UpdatedAt.IsZero() && otherMatch.CreatedAt.After(acceptedMatch.UpdatedAt) {
+			continue
+		}
+
 		// Only process if the match is still pending
 		if otherMatch.Status == models.MatchStatusPending ||
 			otherMatch.Status == models.MatchStatusDriverConfirmed ||
@@ -342,7 +690,8 @@ func (uc *MatchUC) processRejectionBatch(ctx context.Context, rejectionBatch []s
 	}
 
 	// Attempt batch update
-	if err := uc.matchRepo.BatchUpdateMatchStatus(ctx, rejectionBatch, models.MatchStatusRejected); err != nil {
+	rejectedIDs, err := uc.matchRepo.BatchUpdateMatchStatus(ctx, rejectionBatch, models.MatchStatusRejected, models.OpenMatchStatuses)
+	if err != nil {
 		logger.Warn("Batch update failed, falling back to individual updates",
 			logger.Int("batch_size", len(rejectionBatch)),
 			logger.ErrorField(err))
@@ -350,7 +699,23 @@ func (uc *MatchUC) processRejectionBatch(ctx context.Context, rejectionBatch []s
 	}
 
 	// Batch publish events
-	return uc.publishRejectionEvents(ctx, eventBatch)
+	return uc.publishRejectionEvents(ctx, transitionedEvents(eventBatch, rejectedIDs))
+}
+
+// transitionedEvents keeps the rejection events of the matches that genuinely moved to rejected;
+// the others finished in the meantime
+func transitionedEvents(events []models.MatchProposal, rejectedIDs []string) []models.MatchProposal {
+	rejected := make(map[string]bool, len(rejectedIDs))
+	for _, id := range rejectedIDs {
+		rejected[id] = true
+	}
+	transitioned := make([]models.MatchProposal, 0, len(rejectedIDs))
+	for _, event := range events {
+		if rejected[event.ID] {
+			transitioned = append(transitioned, event)
+		}
+	}
+	return transitioned
 }
 
 // processIndividualRejections handles individual updates when batch update fails
@@ -410,8 +775,8 @@ func (uc *MatchUC) createRejectionEvent(match *models.Match) models.MatchProposa
 	}
 }
 
-// handleMatchRejection processes match rejection logic
-func (uc *MatchUC) handleMatchRejection(ctx context.Context, match *models.Match) (models.MatchProposal, error) {
+// handleMatchRejection processes match rejection logic, keeping the driver's reason for rejecting if given
+func (uc *MatchUC) handleMatchRejection(ctx context.Context, match *models.Match, req *models.MatchConfirmRequest) (models.MatchProposal, error) {
 	matchID := match.ID.String()
 
 	if err := uc.matchRepo.UpdateMatchStatus(ctx, matchID, models.MatchStatusRejected); err != nil {
@@ -420,6 +785,16 @@ func (uc *MatchUC) handleMatchRejection(ctx context.Context, match *models.Match
 			logger.ErrorField(err))
 	}
 
+	// Only drivers' reasons feed matching quality; the rejection itself doesn't depend on storing it
+	if req.Reason != "" && req.UserID == match.DriverID.String() {
+		if err := uc.matchRepo.RecordRejectionReason(ctx, matchID, req.Reason); err != nil {
+			logger.Error("Failed to record match rejection reason",
+				logger.String("match_id", matchID),
+				logger.String("reason", string(req.Reason)),
+				logger.ErrorField(err))
+		}
+	}
+
 	// Get updated match to ensure correct state
 	updatedMatch, err := uc.matchRepo.GetMatch(ctx, matchID)
 	if err != nil {
@@ -441,7 +816,34 @@ func (uc *MatchUC) handleMatchRejection(ctx context.Context, match *models.Match
 	return matchProposal, nil
 }
 
-// ConfirmMatchStatus handles match confirmation from either driver or passenger
+// isRepeatedConfirmation reports whether req repeats a decision the user already made on the match.
+// A request contradicting an earlier decision, such as rejecting a match the user accepted or
+// accepting a rejected one, returns match.ErrConfirmationConflict.
+func isRepeatedConfirmation(m *models.Match, req *models.MatchConfirmRequest) (bool, error) {
+	confirmed := m.PassengerConfirmed
+	if req.UserID == m.DriverID.String() {
+		confirmed = m.DriverConfirmed
+	}
+
+	switch req.Status {
+	case string(models.MatchStatusAccepted):
+		if m.Status == models.MatchStatusRejected {
+			return false, fmt.Errorf("%w: cannot accept a rejected match", match.ErrConfirmationConflict)
+		}
+		return confirmed, nil
+	case string(models.MatchStatusRejected):
+		if m.Status == models.MatchStatusRejected {
+			return true, nil
+		}
+		if confirmed || m.Status == models.MatchStatusAccepted {
+			return false, fmt.Errorf("%w: cannot reject an accepted match", match.ErrConfirmationConflict)
+		}
+	}
+	return false, nil
+}
+
+// ConfirmMatchStatus handles match confirmation from either driver or passenger. Repeating a
+// confirmation succeeds without side effects; contradicting an earlier one is refused.
 func (uc *MatchUC) ConfirmMatchStatus(ctx context.Context, req *models.MatchConfirmRequest) (models.MatchProposal, error) {
 	// Extract transaction from standard context
 	txn := newrelic.FromContext(ctx)
@@ -450,17 +852,36 @@ func (uc *MatchUC) ConfirmMatchStatus(ctx context.Context, req *models.MatchConf
 		defer segment.End()
 	}
 
-	// Get the match from database
-	match, err := uc.matchRepo.GetMatch(ctx, req.ID)
+	// A malformed ID would otherwise become uuid.Nil and act on a match that doesn't exist
+	matchID, err := converter.ParseUUID(req.ID)
+	if err != nil {
+		return models.MatchProposal{}, fmt.Errorf("invalid match ID: %w", err)
+	}
+
+	// Get the match from database; from here on the fetched record's ID is used for every update
+	match, err := uc.matchRepo.GetMatch(ctx, matchID.String())
 	if err != nil {
 		return models.MatchProposal{}, fmt.Errorf("match not found in database: %w", err)
 	}
 
+	// A repeated confirmation, e.g. a double-tapped accept, returns the current state unchanged
+	repeated, err := isRepeatedConfirmation(match, req)
+	if err != nil {
+		return models.MatchProposal{}, err
+	}
+	if repeated {
+		logger.Info("Ignoring repeated match confirmation",
+			logger.String("match_id", match.ID.String()),
+			logger.String("user_id", req.UserID),
+			logger.String("status", req.Status))
+		return uc.buildMatchProposal(match), nil
+	}
+
 	switch req.Status {
 	case string(models.MatchStatusAccepted):
 		return uc.handleMatchAcceptance(ctx, match, req)
 	case string(models.MatchStatusRejected):
-		return uc.handleMatchRejection(ctx, match)
+		return uc.handleMatchRejection(ctx, match, req)
 	default:
 		err := fmt.Errorf("unsupported match status: %s", req.Status)
 		return models.MatchProposal{}, err
@@ -489,6 +910,29 @@ func (uc *MatchUC) GetPendingMatch(ctx context.Context, matchID string) (*models
 	return nil, fmt.Errorf("match is not in pending state")
 }
 
+// GetDriverMatches retrieves a page of a driver's match history
+func (uc *MatchUC) GetDriverMatches(ctx context.Context, driverID string, limit, offset int) (*models.MatchHistory, error) {
+	driverUUID, err := uuid.Parse(driverID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid driver ID: %w", err)
+	}
+
+	matches, err := uc.matchRepo.ListMatchesByDriver(ctx, driverUUID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list driver matches: %w", err)
+	}
+
+	if matches == nil {
+		matches = []*models.Match{}
+	}
+
+	return &models.MatchHistory{
+		Matches: matches,
+		Limit:   limit,
+		Offset:  offset,
+	}, nil
+}
+
 // RemoveDriverFromPool removes a driver from the available pool (locks them)
 func (uc *MatchUC) RemoveDriverFromPool(ctx context.Context, driverID string) error {
 	// Locking driver (removing from available pool)
@@ -531,8 +975,56 @@ func (uc *MatchUC) RemoveActiveRide(ctx context.Context, driverID, passengerID s
 	return uc.matchRepo.RemoveActiveRide(ctx, driverID, passengerID)
 }
 
+// LockUsersForRide takes the ride lock for both driver and passenger
+func (uc *MatchUC) LockUsersForRide(ctx context.Context, driverID, passengerID string) error {
+	ttl := uc.rideLockTTL()
+	for _, userID := range []string{driverID, passengerID} {
+		acquired, err := uc.matchRepo.AcquireRideLock(ctx, userID, ttl)
+		if err != nil {
+			return fmt.Errorf("failed to lock user %s for ride: %w", userID, err)
+		}
+		if !acquired {
+			logger.Info("Ride lock already held",
+				logger.String("user_id", userID))
+		}
+	}
+	return nil
+}
+
+// ReleaseRideLocks releases the ride lock for both driver and passenger. An empty ID leaves that
+// user's lock held.
+func (uc *MatchUC) ReleaseRideLocks(ctx context.Context, driverID, passengerID string) error {
+	var firstErr error
+	for _, userID := range []string{driverID, passengerID} {
+		if userID == "" {
+			continue
+		}
+		if err := uc.matchRepo.ReleaseRideLock(ctx, userID); err != nil {
+			logger.Warn("Failed to release ride lock",
+				logger.String("user_id", userID),
+				logger.ErrorField(err))
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	return firstErr
+}
+
 // HasActiveRide checks if a user (driver or passenger) has an active ride
 func (uc *MatchUC) HasActiveRide(ctx context.Context, userID string, isDriver bool) (bool, error) {
+	rideID, err := uc.GetActiveRideID(ctx, userID, isDriver)
+	if err != nil {
+		return false, err
+	}
+
+	// If rideID is empty, no active ride exists
+	return rideID != "", nil
+}
+
+// GetActiveRideID returns the ride a user is currently on as driver or passenger, or an empty
+// string when there is none
+func (uc *MatchUC) GetActiveRideID(ctx context.Context, userID string, isDriver bool) (string, error) {
 	var rideID string
 	var err error
 
@@ -543,9 +1035,7 @@ func (uc *MatchUC) HasActiveRide(ctx context.Context, userID string, isDriver bo
 	}
 
 	if err != nil {
-		return false, fmt.Errorf("failed to check active ride: %w", err)
+		return "", fmt.Errorf("failed to check active ride: %w", err)
 	}
-
-	// If rideID is empty, no active ride exists
-	return rideID != "", nil
+	return rideID, nil
 }
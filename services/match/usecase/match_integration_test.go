@@ -9,6 +9,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/piresc/nebengjek/internal/pkg/converter"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/testutil"
 	"github.com/piresc/nebengjek/services/match/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -30,6 +31,9 @@ func TestMatchUC_CompleteMatchFlow_Success(t *testing.T) {
 
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
 	// Test data
 	passengerID := uuid.New().String()
 	driverID := uuid.New().String()
@@ -67,15 +71,29 @@ func TestMatchUC_CompleteMatchFlow_Success(t *testing.T) {
 		GetActiveRideByPassenger(gomock.Any(), passengerID).
 		Return("", nil) // No active ride
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), passengerID).
+		Return(false, nil)
+
 	// Mock adding passenger to available pool
 	mockGW.EXPECT().
 		AddAvailablePassenger(gomock.Any(), passengerID, &passengerLocation).
 		Return(nil)
 
+	// No earlier proposals to move to the passenger's new position
+	mockRepo.EXPECT().
+		UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(passengerID), passengerLocation).
+		Return(nil, nil)
+
 	mockGW.EXPECT().
 		FindNearbyDrivers(gomock.Any(), &passengerLocation, cfg.Match.SearchRadiusKm).
 		Return(nearbyDrivers, nil)
 
+	mockRepo.EXPECT().
+		ClaimMatchProposal(gomock.Any(), passengerID, gomock.Any(), gomock.Any()).
+		Return(true, nil)
+
 	mockRepo.EXPECT().
 		CreateMatch(gomock.Any(), gomock.Any()).
 		DoAndReturn(func(ctx context.Context, match *models.Match) (*models.Match, error) {
@@ -102,61 +120,18 @@ func TestMatchUC_CompleteMatchFlow_Success(t *testing.T) {
 		Status: string(models.MatchStatusAccepted),
 	}
 
-	existingMatch := &models.Match{
-		ID:                converter.StrToUUID(matchID),
-		DriverID:          converter.StrToUUID(driverID),
-		PassengerID:       converter.StrToUUID(passengerID),
-		PassengerLocation: passengerLocation,
-		DriverLocation:    driverLocation,
-		Status:            models.MatchStatusPending,
-		PassengerConfirmed: true, // Passenger already confirmed
-		CreatedAt:         time.Now(),
-	}
-
-	mockRepo.EXPECT().
-		GetMatch(gomock.Any(), matchID).
-		Return(existingMatch, nil)
-
-	// Mock ConfirmMatchByUser (called by updateMatchConfirmation)
-	mockRepo.EXPECT().
-		ConfirmMatchByUser(gomock.Any(), matchID, driverID, true).
-		DoAndReturn(func(ctx context.Context, matchID, userID string, isDriver bool) (*models.Match, error) {
-			existingMatch.DriverConfirmed = true
-			existingMatch.Status = models.MatchStatusAccepted
-			return existingMatch, nil
-		})
+	existingMatch := testutil.NewPendingMatch(driverID, passengerID)
+	existingMatch.ID = converter.StrToUUID(matchID)
+	existingMatch.PassengerConfirmed = true // Passenger already confirmed
 
-	// Mock removing users from available pools
-	mockGW.EXPECT().
-		RemoveAvailableDriver(gomock.Any(), driverID).
-		Return(nil)
-
-	mockGW.EXPECT().
-		RemoveAvailablePassenger(gomock.Any(), passengerID).
-		Return(nil)
-
-	mockGW.EXPECT().
-		PublishMatchAccepted(gomock.Any(), gomock.Any()).
-		Return(nil)
-
-	// Mock auto-rejection process (async)
-	mockRepo.EXPECT().
-		ListMatchesByPassenger(gomock.Any(), converter.StrToUUID(passengerID)).
-		Return([]*models.Match{}, nil).AnyTimes() // No other matches to reject
-
-	mockRepo.EXPECT().
-		BatchUpdateMatchStatus(gomock.Any(), gomock.Any(), models.MatchStatusRejected).
-		Return(nil).AnyTimes()
-
-	mockGW.EXPECT().
-		PublishMatchRejected(gomock.Any(), gomock.Any()).
-		Return(nil).AnyTimes()
+	expectMatchAcceptance(mockRepo, mockGW, existingMatch, driverID)
 
 	// Act - Step 2
-	_, err = uc.ConfirmMatchStatus(context.Background(), matchRequest)
+	proposal, err := uc.ConfirmMatchStatus(context.Background(), matchRequest)
 
 	// Assert - Step 2
 	assert.NoError(t, err)
+	assert.Equal(t, models.MatchStatusAccepted, proposal.MatchStatus)
 }
 
 func TestMatchUC_HandleMultipleDriversScenario(t *testing.T) {
@@ -175,6 +150,9 @@ func TestMatchUC_HandleMultipleDriversScenario(t *testing.T) {
 
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
 	passengerID := uuid.New().String()
 	driver1ID := uuid.New().String()
 	driver2ID := uuid.New().String()
@@ -217,15 +195,30 @@ func TestMatchUC_HandleMultipleDriversScenario(t *testing.T) {
 		GetActiveRideByPassenger(gomock.Any(), passengerID).
 		Return("", nil) // No active ride
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), passengerID).
+		Return(false, nil)
+
 	// Mock adding passenger to available pool
 	mockGW.EXPECT().
 		AddAvailablePassenger(gomock.Any(), passengerID, &passengerLocation).
 		Return(nil)
 
+	// No earlier proposals to move to the passenger's new position
+	mockRepo.EXPECT().
+		UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(passengerID), passengerLocation).
+		Return(nil, nil)
+
 	mockGW.EXPECT().
 		FindNearbyDrivers(gomock.Any(), &passengerLocation, cfg.Match.SearchRadiusKm).
 		Return(nearbyDrivers, nil)
 
+	mockRepo.EXPECT().
+		ClaimMatchProposal(gomock.Any(), passengerID, gomock.Any(), gomock.Any()).
+		Times(3).
+		Return(true, nil)
+
 	// Expect 3 matches to be created
 	mockRepo.EXPECT().
 		CreateMatch(gomock.Any(), gomock.Any()).
@@ -436,12 +429,12 @@ func TestMatchUC_HandleConcurrentMatches(t *testing.T) {
 
 	// Mock auto-rejection process (async) for both matches
 	mockRepo.EXPECT().
-		ListMatchesByPassenger(gomock.Any(), gomock.Any()).
-		Return([]*models.Match{}, nil).AnyTimes()
+		ListOpenMatchesByPassenger(gomock.Any(), gomock.Any()).
+		Return(nil, nil).AnyTimes()
 
 	mockRepo.EXPECT().
-		BatchUpdateMatchStatus(gomock.Any(), gomock.Any(), models.MatchStatusRejected).
-		Return(nil).AnyTimes()
+		BatchUpdateMatchStatus(gomock.Any(), gomock.Any(), models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return(nil, nil).AnyTimes()
 
 	mockGW.EXPECT().
 		PublishMatchRejected(gomock.Any(), gomock.Any()).
@@ -472,6 +465,9 @@ func TestMatchUC_HandleLocationBasedMatching(t *testing.T) {
 
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
 	passengerID := uuid.New().String()
 	passengerLocation := models.Location{
 		Latitude:  -6.2088,
@@ -506,15 +502,30 @@ func TestMatchUC_HandleLocationBasedMatching(t *testing.T) {
 		GetActiveRideByPassenger(gomock.Any(), passengerID).
 		Return("", nil) // No active ride
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), passengerID).
+		Return(false, nil)
+
 	// Mock adding passenger to available pool
 	mockGW.EXPECT().
 		AddAvailablePassenger(gomock.Any(), passengerID, &passengerLocation).
 		Return(nil)
 
+	// No earlier proposals to move to the passenger's new position
+	mockRepo.EXPECT().
+		UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(passengerID), passengerLocation).
+		Return(nil, nil)
+
 	mockGW.EXPECT().
 		FindNearbyDrivers(gomock.Any(), &passengerLocation, cfg.Match.SearchRadiusKm).
 		Return(nearbyDrivers, nil)
 
+	mockRepo.EXPECT().
+		ClaimMatchProposal(gomock.Any(), passengerID, gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(true, nil)
+
 	// Expect matches for drivers within radius
 	mockRepo.EXPECT().
 		CreateMatch(gomock.Any(), gomock.Any()).
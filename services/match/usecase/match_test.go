@@ -8,10 +8,12 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
-	"github.com/piresc/nebengjek/internal/pkg/converter"
+	"github.com/piresc/nebengjek/internal/pkg/clock"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
 	"github.com/piresc/nebengjek/services/match/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHandleBeaconEvent_Success_Driver(t *testing.T) {
@@ -47,6 +49,11 @@ func TestHandleBeaconEvent_Success_Driver(t *testing.T) {
 		Return("", nil).
 		Times(1)
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(false, nil)
+
 	// The implementation calls AddAvailableDriver after active ride check
 	mockGW.EXPECT().
 		AddAvailableDriver(gomock.Any(), userID, gomock.Any()).
@@ -102,16 +109,117 @@ func TestHandleFinderEvent_Success_Passenger(t *testing.T) {
 		Return("", nil).
 		Times(1)
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(false, nil)
+
 	// Mock required calls
 	mockGW.EXPECT().
 		AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).
 		Return(nil)
+	mockRepo.EXPECT().
+		UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).
+		Return(nil, nil)
 
 	// Need to mock FindNearbyDrivers as it's called by the handler
 	mockGW.EXPECT().
 		FindNearbyDrivers(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return([]*models.NearbyUser{}, nil) // Return empty array to avoid further processing
 
+	// No drivers nearby, so the passenger is told none are available
+	mockGW.EXPECT().
+		PublishNoDriversFound(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_PassengerMovedWithdrawsOutOfRangeProposals(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm: 5.0,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+		TargetLocation: models.Location{
+			Latitude:  -6.200000,
+			Longitude: 106.816666,
+		},
+		Timestamp: time.Now(),
+	}
+
+	// One proposed driver is still close by, the other is now well beyond the search radius
+	nearMatch := &models.Match{
+		ID:             uuid.New(),
+		DriverID:       uuid.New(),
+		PassengerID:    uuid.MustParse(userID),
+		DriverLocation: models.Location{Latitude: -6.180000, Longitude: 106.830000},
+		Status:         models.MatchStatusPending,
+	}
+	farMatch := &models.Match{
+		ID:             uuid.New(),
+		DriverID:       uuid.New(),
+		PassengerID:    uuid.MustParse(userID),
+		DriverLocation: models.Location{Latitude: -6.300000, Longitude: 106.900000},
+		Status:         models.MatchStatusDriverConfirmed,
+	}
+
+	mockRepo.EXPECT().
+		GetActiveRideByPassenger(gomock.Any(), userID).
+		Return("", nil)
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(false, nil)
+	mockGW.EXPECT().
+		AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).
+		Return(nil)
+	mockRepo.EXPECT().
+		UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), models.Location{
+			Latitude:  event.Location.Latitude,
+			Longitude: event.Location.Longitude,
+		}).
+		Return([]*models.Match{nearMatch, farMatch}, nil)
+
+	// Only the out of range proposal is withdrawn
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{farMatch.ID.String()}, models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return([]string{farMatch.ID.String()}, nil)
+	mockGW.EXPECT().
+		PublishMatchRejected(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			assert.Equal(t, farMatch.ID.String(), mp.ID)
+			assert.Equal(t, models.MatchStatusRejected, mp.MatchStatus)
+			return nil
+		})
+
+	mockGW.EXPECT().
+		FindNearbyDrivers(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]*models.NearbyUser{}, nil)
+	mockGW.EXPECT().
+		PublishNoDriversFound(gomock.Any(), gomock.Any()).
+		Return(nil)
+
 	// Act
 	err := uc.HandleFinderEvent(context.Background(), event)
 
@@ -193,6 +301,11 @@ func TestHandleBeaconEvent_RepositoryError(t *testing.T) {
 		Return("", nil).
 		Times(1)
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(false, nil)
+
 	// Set up expectations
 	mockGW.EXPECT().
 		AddAvailableDriver(gomock.Any(), userID, gomock.Any()).
@@ -328,6 +441,11 @@ func TestHandleBeaconEvent_ActiveRideCheckError(t *testing.T) {
 		Return("", errors.New("redis connection error")).
 		Times(1)
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(false, nil)
+
 	// Should still try to add to pool on error to avoid blocking the system
 	mockGW.EXPECT().
 		AddAvailableDriver(gomock.Any(), userID, gomock.Any()).
@@ -378,15 +496,27 @@ func TestHandleFinderEvent_ActiveRideCheckError(t *testing.T) {
 		Return("", errors.New("redis connection error")).
 		Times(1)
 
+	// Mock ride lock check - user is not locked
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(false, nil)
+
 	// Should still try to add to pool on error to avoid blocking the system
 	mockGW.EXPECT().
 		AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).
 		Return(nil)
+	mockRepo.EXPECT().
+		UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).
+		Return(nil, nil)
 
 	mockGW.EXPECT().
 		FindNearbyDrivers(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return([]*models.NearbyUser{}, nil)
 
+	mockGW.EXPECT().
+		PublishNoDriversFound(gomock.Any(), gomock.Any()).
+		Return(nil)
+
 	// Act
 	err := uc.HandleFinderEvent(context.Background(), event)
 
@@ -409,12 +539,16 @@ func TestConfirmMatchStatus_AcceptSuccess(t *testing.T) {
 
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
-	matchID := "match-123"
+	matchUUID := uuid.New()
+	matchID := matchUUID.String()
 	driverID := uuid.New()
 	passengerID := uuid.New()
 	driverIDStr := driverID.String()
 	passengerIDStr := passengerID.String()
 
+	// Accepted matches count towards the driver's cancellation rate
+	mockRepo.EXPECT().RecordDriverAccepted(gomock.Any(), driverIDStr, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
 	_ = models.MatchProposal{
 		ID:          matchID,
 		DriverID:    driverIDStr,
@@ -426,26 +560,26 @@ func TestConfirmMatchStatus_AcceptSuccess(t *testing.T) {
 	mockRepo.EXPECT().
 		GetMatch(gomock.Any(), matchID).
 		Return(&models.Match{
-			ID:          converter.StrToUUID(matchID),
+			ID:          matchUUID,
 			DriverID:    driverID,
 			PassengerID: passengerID,
 			Status:      models.MatchStatusPending,
 		}, nil)
 
-	// Then it persists the match (note: matchID gets converted to UUID.Nil due to invalid format)
+	// Then it persists the confirmation against the fetched match's real ID
 	mockRepo.EXPECT().
-		ConfirmMatchByUser(gomock.Any(), "00000000-0000-0000-0000-000000000000", driverIDStr, true).
+		ConfirmMatchByUser(gomock.Any(), matchID, driverIDStr, true).
 		Return(&models.Match{
-			ID:          converter.StrToUUID(matchID),
+			ID:          matchUUID,
 			DriverID:    driverID,
 			PassengerID: passengerID,
 			Status:      models.MatchStatusAccepted,
 		}, nil)
 
-	// Mock ListMatchesByPassenger for async auto-rejection
+	// Mock ListOpenMatchesByPassenger for async auto-rejection
 	mockRepo.EXPECT().
-		ListMatchesByPassenger(gomock.Any(), passengerID).
-		Return([]*models.Match{}, nil).AnyTimes()
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return(nil, nil).AnyTimes()
 
 	// When match is accepted, it publishes the accepted event
 	mockGW.EXPECT().
@@ -453,7 +587,7 @@ func TestConfirmMatchStatus_AcceptSuccess(t *testing.T) {
 		Return(nil)
 
 	// The auto-rejection happens asynchronously, so we can't test it synchronously
-	// Removed expectations for: ListMatchesByPassenger, RemoveAvailableDriver, RemoveAvailablePassenger
+	// Removed expectations for: ListOpenMatchesByPassenger, RemoveAvailableDriver, RemoveAvailablePassenger
 
 	// Act
 	req := &models.MatchConfirmRequest{
@@ -468,6 +602,41 @@ func TestConfirmMatchStatus_AcceptSuccess(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPublishMatchAccepted_IncludesPickupNavigation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	match := &models.Match{
+		ID:                uuid.New(),
+		DriverID:          uuid.New(),
+		PassengerID:       uuid.New(),
+		PassengerLocation: models.Location{Latitude: -6.2088, Longitude: 106.8456},
+		DriverLocation:    models.Location{Latitude: -6.21, Longitude: 106.84},
+		Status:            models.MatchStatusAccepted,
+	}
+
+	var published models.MatchProposal
+	mockGW.EXPECT().
+		PublishMatchAccepted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			published = mp
+			return nil
+		})
+
+	uc.PublishMatchAccepted(context.Background(), match)
+
+	// Navigation targets the passenger's pickup, not the driver's position
+	require.NotNil(t, published.Navigation)
+	assert.Equal(t, "-6.208800,106.845600", published.Navigation.Coordinates)
+	assert.Equal(t,
+		"https://www.google.com/maps/dir/?api=1&destination=-6.208800,106.845600&travelmode=driving",
+		published.Navigation.DeepLink)
+}
+
 func TestConfirmMatchStatus_RejectSuccess(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -483,41 +652,44 @@ func TestConfirmMatchStatus_RejectSuccess(t *testing.T) {
 
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
-	matchID := "match-123"
+	matchUUID := uuid.New()
+	matchID := matchUUID.String()
 	driverID := uuid.New()
 	passengerID := uuid.New()
 	driverIDStr := driverID.String()
-	_ = passengerID.String()
 
 	// First GetMatch is called to retrieve the match
 	mockRepo.EXPECT().
 		GetMatch(gomock.Any(), matchID).
 		Return(&models.Match{
-			ID:          converter.StrToUUID(matchID),
+			ID:          matchUUID,
 			DriverID:    driverID,
 			PassengerID: passengerID,
 			Status:      models.MatchStatusPending,
 		}, nil)
 
-	// For rejection, the test should expect status update calls (matchID becomes UUID.Nil)
+	// The rejection is stored against the fetched match's real ID
 	mockRepo.EXPECT().
-		UpdateMatchStatus(gomock.Any(), "00000000-0000-0000-0000-000000000000", models.MatchStatusRejected).
+		UpdateMatchStatus(gomock.Any(), matchID, models.MatchStatusRejected).
 		Return(nil)
 
-	// Then GetMatch is called again to get the updated match (also with UUID.Nil)
+	// Then GetMatch is called again with the same ID to get the updated match
 	mockRepo.EXPECT().
-		GetMatch(gomock.Any(), "00000000-0000-0000-0000-000000000000").
+		GetMatch(gomock.Any(), matchID).
 		Return(&models.Match{
-			ID:          converter.StrToUUID(matchID),
+			ID:          matchUUID,
 			DriverID:    driverID,
 			PassengerID: passengerID,
 			Status:      models.MatchStatusRejected,
 		}, nil)
 
-	// Expect PublishMatchRejected to be called
+	// The rejection event names the real match
 	mockGW.EXPECT().
 		PublishMatchRejected(gomock.Any(), gomock.Any()).
-		Return(nil)
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			assert.Equal(t, matchID, mp.ID)
+			return nil
+		})
 
 	// Act
 	req := &models.MatchConfirmRequest{
@@ -532,6 +704,126 @@ func TestConfirmMatchStatus_RejectSuccess(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestConfirmMatchStatus_RepeatedAcceptIsIdempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	matchID := uuid.New()
+	driverID := uuid.New()
+
+	// The driver already accepted; the passenger has not answered yet
+	mockRepo.EXPECT().
+		GetMatch(gomock.Any(), matchID.String()).
+		Return(&models.Match{
+			ID:              matchID,
+			DriverID:        driverID,
+			PassengerID:     uuid.New(),
+			DriverConfirmed: true,
+			Status:          models.MatchStatusDriverConfirmed,
+		}, nil)
+
+	// No confirmation is written and nothing is published again
+	proposal, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     matchID.String(),
+		UserID: driverID.String(),
+		Status: string(models.MatchStatusAccepted),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, matchID.String(), proposal.ID)
+	assert.Equal(t, models.MatchStatusDriverConfirmed, proposal.MatchStatus)
+}
+
+func TestConfirmMatchStatus_RepeatedRejectIsIdempotent(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	matchID := uuid.New()
+	driverID := uuid.New()
+
+	mockRepo.EXPECT().
+		GetMatch(gomock.Any(), matchID.String()).
+		Return(&models.Match{
+			ID:          matchID,
+			DriverID:    driverID,
+			PassengerID: uuid.New(),
+			Status:      models.MatchStatusRejected,
+		}, nil)
+
+	proposal, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     matchID.String(),
+		UserID: driverID.String(),
+		Status: string(models.MatchStatusRejected),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusRejected, proposal.MatchStatus)
+}
+
+func TestConfirmMatchStatus_ConflictingDecisionsRejected(t *testing.T) {
+	driverID := uuid.New()
+	passengerID := uuid.New()
+
+	tests := []struct {
+		name   string
+		match  models.Match
+		userID string
+		status models.MatchStatus
+	}{
+		{
+			name:   "accept then reject",
+			match:  models.Match{DriverConfirmed: true, Status: models.MatchStatusDriverConfirmed},
+			userID: driverID.String(),
+			status: models.MatchStatusRejected,
+		},
+		{
+			name:   "reject an accepted match",
+			match:  models.Match{DriverConfirmed: true, PassengerConfirmed: true, Status: models.MatchStatusAccepted},
+			userID: passengerID.String(),
+			status: models.MatchStatusRejected,
+		},
+		{
+			name:   "accept a rejected match",
+			match:  models.Match{Status: models.MatchStatusRejected},
+			userID: passengerID.String(),
+			status: models.MatchStatusAccepted,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockMatchRepo(ctrl)
+			mockGW := mocks.NewMockMatchGW(ctrl)
+			uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+			existing := tt.match
+			existing.ID = uuid.New()
+			existing.DriverID = driverID
+			existing.PassengerID = passengerID
+			mockRepo.EXPECT().GetMatch(gomock.Any(), existing.ID.String()).Return(&existing, nil)
+
+			_, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+				ID:     existing.ID.String(),
+				UserID: tt.userID,
+				Status: string(tt.status),
+			})
+
+			assert.ErrorIs(t, err, match.ErrConfirmationConflict)
+		})
+	}
+}
+
 func TestConfirmMatchStatus_GetMatchError(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -548,7 +840,7 @@ func TestConfirmMatchStatus_GetMatchError(t *testing.T) {
 
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
-	matchID := "match-123"
+	matchID := uuid.New().String()
 	driverID := uuid.New().String()
 
 	expectedError := errors.New("database error")
@@ -572,6 +864,25 @@ func TestConfirmMatchStatus_GetMatchError(t *testing.T) {
 	assert.Contains(t, err.Error(), "match not found in database")
 }
 
+func TestConfirmMatchStatus_MalformedMatchID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// A malformed ID must not reach the repository as uuid.Nil
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	for _, matchID := range []string{"match-123", uuid.Nil.String()} {
+		_, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+			ID:     matchID,
+			UserID: uuid.New().String(),
+			Status: string(models.MatchStatusRejected),
+		})
+
+		assert.ErrorContains(t, err, "invalid match ID")
+	}
+}
+
 func TestCreateMatch_DatabaseError(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -612,130 +923,400 @@ func TestCreateMatch_DatabaseError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to create match")
 }
 
-// Test HasActiveRide functionality
-func TestHasActiveRide_DriverHasActiveRide(t *testing.T) {
-	// Arrange
+func TestCreateMatch_PendingMatchLimit(t *testing.T) {
+	tests := []struct {
+		name         string
+		pendingCount int
+		expectCreate bool
+	}{
+		{name: "below limit creates match", pendingCount: 2, expectCreate: true},
+		{name: "at limit refuses match", pendingCount: 3, expectCreate: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockMatchRepo(ctrl)
+			mockGW := mocks.NewMockMatchGW(ctrl)
+			cfg := &models.Config{
+				Match: models.MatchConfig{
+					SearchRadiusKm:         5.0,
+					MaxPendingPerPassenger: 3,
+				},
+			}
+
+			uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+			newMatch := &models.Match{
+				DriverID:    uuid.New(),
+				PassengerID: uuid.New(),
+				Status:      models.MatchStatusPending,
+			}
+
+			mockRepo.EXPECT().
+				CountPendingMatchesByPassenger(gomock.Any(), newMatch.PassengerID).
+				Return(tt.pendingCount, nil)
+
+			if tt.expectCreate {
+				created := *newMatch
+				created.ID = uuid.New()
+				mockRepo.EXPECT().CreateMatch(gomock.Any(), newMatch).Return(&created, nil)
+				mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil)
+			}
+
+			err := uc.CreateMatch(context.Background(), newMatch)
+
+			if tt.expectCreate {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, match.ErrPendingMatchLimit)
+			}
+		})
+	}
+}
+
+func TestCreateMatch_ProposalIncludesTripPreview(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockMatchRepo(ctrl)
 	mockGW := mocks.NewMockMatchGW(ctrl)
-	cfg := &models.Config{}
-
+	cfg := &models.Config{
+		Pricing: models.PricingConfig{RatePerKm: 3000, AdminFeePercent: 5},
+	}
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
-	userID := "driver-123"
-	rideID := "ride-456"
+	newMatch := &models.Match{
+		ID:                uuid.New(),
+		DriverID:          uuid.New(),
+		PassengerID:       uuid.New(),
+		PassengerLocation: models.Location{Latitude: -6.2, Longitude: 106.8},
+		TargetLocation:    models.Location{Latitude: -6.2, Longitude: 106.9},
+		Status:            models.MatchStatusPending,
+	}
+	mockRepo.EXPECT().CreateMatch(gomock.Any(), newMatch).Return(newMatch, nil)
 
-	// Mock repository to return active ride
-	mockRepo.EXPECT().
-		GetActiveRideByDriver(gomock.Any(), userID).
-		Return(rideID, nil).
-		Times(1)
+	var published models.MatchProposal
+	mockGW.EXPECT().
+		PublishMatchFound(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			published = mp
+			return nil
+		})
 
-	// Act
-	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, true) // true = isDriver
+	require.NoError(t, uc.CreateMatch(context.Background(), newMatch))
 
-	// Assert
-	assert.NoError(t, err)
-	assert.True(t, hasActiveRide)
+	// 0.1 degrees of longitude near the equator is about 11.05 km
+	assert.Equal(t, newMatch.TargetLocation, published.TargetLocation)
+	assert.Equal(t, 11.05, published.EstimatedDistanceKm)
+	assert.Equal(t, 33150, published.EstimatedFare)
+	// The 5% admin fee of 1657.5 is truncated to 1657
+	assert.Equal(t, 31493, published.EstimatedEarnings)
 }
 
-func TestHasActiveRide_DriverNoActiveRide(t *testing.T) {
-	// Arrange
+func TestPublishMatchAccepted_CarriesTripEstimate(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
-	mockRepo := mocks.NewMockMatchRepo(ctrl)
 	mockGW := mocks.NewMockMatchGW(ctrl)
-	cfg := &models.Config{}
+	cfg := &models.Config{
+		Pricing: models.PricingConfig{RatePerKm: 3000, AdminFeePercent: 5},
+	}
+	uc := NewMatchUC(cfg, mocks.NewMockMatchRepo(ctrl), mockGW)
+
+	accepted := &models.Match{
+		ID:                uuid.New(),
+		DriverID:          uuid.New(),
+		PassengerID:       uuid.New(),
+		PassengerLocation: models.Location{Latitude: -6.2, Longitude: 106.8},
+		TargetLocation:    models.Location{Latitude: -6.2, Longitude: 106.9},
+		Status:            models.MatchStatusAccepted,
+	}
 
-	uc := NewMatchUC(cfg, mockRepo, mockGW)
+	var published models.MatchProposal
+	mockGW.EXPECT().
+		PublishMatchAccepted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			published = mp
+			return nil
+		})
 
-	userID := "driver-123"
+	uc.PublishMatchAccepted(context.Background(), accepted)
 
-	// Mock repository to return no active ride
-	mockRepo.EXPECT().
-		GetActiveRideByDriver(gomock.Any(), userID).
-		Return("", nil).
-		Times(1)
+	// The rides service stores the quoted estimate with the ride it creates
+	assert.Equal(t, 11.05, published.EstimatedDistanceKm)
+	assert.Equal(t, 33150, published.EstimatedFare)
+	assert.NotNil(t, published.Navigation)
+}
 
-	// Act
-	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, true) // true = isDriver
+func TestBuildMatchProposal_NoDestinationOmitsPreview(t *testing.T) {
+	uc := NewMatchUC(&models.Config{Pricing: models.PricingConfig{RatePerKm: 3000}}, nil, nil)
 
-	// Assert
-	assert.NoError(t, err)
-	assert.False(t, hasActiveRide)
+	proposal := uc.buildMatchProposal(&models.Match{
+		ID:                uuid.New(),
+		PassengerLocation: models.Location{Latitude: -6.2, Longitude: 106.8},
+	})
+
+	assert.Zero(t, proposal.EstimatedDistanceKm)
+	assert.Zero(t, proposal.EstimatedFare)
+	assert.Zero(t, proposal.EstimatedEarnings)
 }
 
-func TestHasActiveRide_PassengerHasActiveRide(t *testing.T) {
+func TestCreateMatch_SelfMatchRejected(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockMatchRepo(ctrl)
 	mockGW := mocks.NewMockMatchGW(ctrl)
-	cfg := &models.Config{}
-
+	cfg := &models.Config{Match: models.MatchConfig{MaxPendingPerPassenger: 3}}
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
-	userID := "passenger-123"
-	rideID := "ride-456"
-
-	// Mock repository to return active ride
-	mockRepo.EXPECT().
-		GetActiveRideByPassenger(gomock.Any(), userID).
-		Return(rideID, nil).
-		Times(1)
+	userID := uuid.New()
+	selfMatch := &models.Match{
+		DriverID:    userID,
+		PassengerID: userID,
+		Status:      models.MatchStatusPending,
+	}
 
-	// Act
-	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, false) // false = isPassenger
+	// Act: nothing is counted, stored or published
+	err := uc.CreateMatch(context.Background(), selfMatch)
 
 	// Assert
-	assert.NoError(t, err)
-	assert.True(t, hasActiveRide)
+	assert.ErrorIs(t, err, match.ErrSelfMatch)
+	assert.Contains(t, err.Error(), userID.String())
 }
 
-func TestHasActiveRide_PassengerNoActiveRide(t *testing.T) {
+func TestHandleFinderEvent_SkipsSelfAsNearbyDriver(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockMatchRepo(ctrl)
 	mockGW := mocks.NewMockMatchGW(ctrl)
-	cfg := &models.Config{}
-
+	cfg := &models.Config{Match: models.MatchConfig{SearchRadiusKm: 5.0}}
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
-	userID := "passenger-123"
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
 
-	// Mock repository to return no active ride
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:         userID,
+		IsActive:       true,
+		Location:       models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		TargetLocation: models.Location{Latitude: -6.200000, Longitude: 106.816666},
+		Timestamp:      time.Now(),
+	}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+
+	// The passenger's own beacon is still in the driver pool
+	otherDriver := &models.NearbyUser{ID: uuid.New().String(), Location: models.Location{Latitude: -6.175400, Longitude: 106.827160}}
+	mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*models.NearbyUser{
+		{ID: userID, Location: event.Location},
+		otherDriver,
+	}, nil)
+
+	// Only the other driver is proposed
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), userID, otherDriver.ID, gomock.Any()).Return(true, nil)
 	mockRepo.EXPECT().
-		GetActiveRideByPassenger(gomock.Any(), userID).
-		Return("", nil).
-		Times(1)
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, m *models.Match) (*models.Match, error) {
+			assert.Equal(t, otherDriver.ID, m.DriverID.String())
+			m.ID = uuid.New()
+			return m, nil
+		})
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil)
 
 	// Act
-	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, false) // false = isPassenger
+	err := uc.HandleFinderEvent(context.Background(), event)
 
 	// Assert
 	assert.NoError(t, err)
-	assert.False(t, hasActiveRide)
 }
 
-func TestSetActiveRide_Success(t *testing.T) {
+func TestHandleFinderEvent_PendingMatchLimitStopsProposals(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
 
 	mockRepo := mocks.NewMockMatchRepo(ctrl)
 	mockGW := mocks.NewMockMatchGW(ctrl)
-	cfg := &models.Config{}
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm:         5.0,
+			MaxPendingPerPassenger: 1,
+		},
+	}
 
 	uc := NewMatchUC(cfg, mockRepo, mockGW)
 
-	rideID := "ride-123"
-	driverID := "driver-456"
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:         userID,
+		IsActive:       true,
+		Location:       models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		TargetLocation: models.Location{Latitude: -6.200000, Longitude: 106.816666},
+		Timestamp:      time.Now(),
+	}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+
+	drivers := []*models.NearbyUser{
+		{ID: uuid.New().String(), Location: models.Location{Latitude: -6.175400, Longitude: 106.827160}},
+		{ID: uuid.New().String(), Location: models.Location{Latitude: -6.175500, Longitude: 106.827200}},
+	}
+	mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), gomock.Any()).Return(drivers, nil)
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), userID, drivers[0].ID, gomock.Any()).Return(true, nil)
+
+	// The passenger already holds an unanswered match, so no further drivers are tried and
+	// no "no drivers" event is sent. The driver's claim is released since nothing was proposed.
+	mockRepo.EXPECT().CountPendingMatchesByPassenger(gomock.Any(), uuid.MustParse(userID)).Return(1, nil)
+	mockRepo.EXPECT().ReleaseMatchProposal(gomock.Any(), userID, drivers[0].ID).Return(nil)
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+// Test HasActiveRide functionality
+func TestHasActiveRide_DriverHasActiveRide(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := "driver-123"
+	rideID := "ride-456"
+
+	// Mock repository to return active ride
+	mockRepo.EXPECT().
+		GetActiveRideByDriver(gomock.Any(), userID).
+		Return(rideID, nil).
+		Times(1)
+
+	// Act
+	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, true) // true = isDriver
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, hasActiveRide)
+}
+
+func TestHasActiveRide_DriverNoActiveRide(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := "driver-123"
+
+	// Mock repository to return no active ride
+	mockRepo.EXPECT().
+		GetActiveRideByDriver(gomock.Any(), userID).
+		Return("", nil).
+		Times(1)
+
+	// Act
+	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, true) // true = isDriver
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, hasActiveRide)
+}
+
+func TestHasActiveRide_PassengerHasActiveRide(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := "passenger-123"
+	rideID := "ride-456"
+
+	// Mock repository to return active ride
+	mockRepo.EXPECT().
+		GetActiveRideByPassenger(gomock.Any(), userID).
+		Return(rideID, nil).
+		Times(1)
+
+	// Act
+	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, false) // false = isPassenger
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, hasActiveRide)
+}
+
+func TestHasActiveRide_PassengerNoActiveRide(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := "passenger-123"
+
+	// Mock repository to return no active ride
+	mockRepo.EXPECT().
+		GetActiveRideByPassenger(gomock.Any(), userID).
+		Return("", nil).
+		Times(1)
+
+	// Act
+	hasActiveRide, err := uc.HasActiveRide(context.Background(), userID, false) // false = isPassenger
+
+	// Assert
+	assert.NoError(t, err)
+	assert.False(t, hasActiveRide)
+}
+
+func TestSetActiveRide_Success(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	rideID := "ride-123"
+	driverID := "driver-456"
 	passengerID := "passenger-789"
 
 	// Mock repository calls
@@ -777,3 +1358,1193 @@ func TestRemoveActiveRide_Success(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 }
+
+func TestHandleBeaconEvent_DriverRideLocked(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.BeaconEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+	}
+
+	// Active ride keys are not written yet, but the ride lock is held
+	mockRepo.EXPECT().
+		GetActiveRideByDriver(gomock.Any(), userID).
+		Return("", nil)
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(true, nil)
+	mockRepo.EXPECT().
+		GetDriverFinishingRide(gomock.Any(), userID).
+		Return("", nil)
+
+	// AddAvailableDriver should NOT be called while the lock is held
+
+	// Act
+	err := uc.HandleBeaconEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_PassengerRideLocked(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+	}
+
+	mockRepo.EXPECT().
+		GetActiveRideByPassenger(gomock.Any(), userID).
+		Return("", nil)
+	mockRepo.EXPECT().
+		IsRideLocked(gomock.Any(), userID).
+		Return(true, nil)
+
+	// AddAvailablePassenger and FindNearbyDrivers should NOT be called while the lock is held
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestLockUsersForRide_Success(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			ActiveRideTTLHours: 6,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	driverID := "driver-456"
+	passengerID := "passenger-789"
+
+	mockRepo.EXPECT().
+		AcquireRideLock(gomock.Any(), driverID, 6*time.Hour).
+		Return(true, nil)
+	mockRepo.EXPECT().
+		AcquireRideLock(gomock.Any(), passengerID, 6*time.Hour).
+		Return(true, nil)
+
+	// Act
+	err := uc.LockUsersForRide(context.Background(), driverID, passengerID)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestLockUsersForRide_DefaultTTL(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	mockRepo.EXPECT().
+		AcquireRideLock(gomock.Any(), gomock.Any(), defaultRideLockTTL).
+		Return(true, nil).
+		Times(2)
+
+	// Act
+	err := uc.LockUsersForRide(context.Background(), "driver-456", "passenger-789")
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestReleaseRideLocks_Success(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	driverID := "driver-456"
+	passengerID := "passenger-789"
+
+	mockRepo.EXPECT().ReleaseRideLock(gomock.Any(), driverID).Return(nil)
+	mockRepo.EXPECT().ReleaseRideLock(gomock.Any(), passengerID).Return(nil)
+
+	// Act
+	err := uc.ReleaseRideLocks(context.Background(), driverID, passengerID)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_NoNearbyDrivers_PublishesNoDriversFound(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm: 3.0,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+	}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+	mockGW.EXPECT().
+		FindNearbyDrivers(gomock.Any(), gomock.Any(), 3.0).
+		Return([]*models.NearbyUser{}, nil)
+
+	mockGW.EXPECT().
+		PublishNoDriversFound(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, e models.NoDriversFoundEvent) error {
+			assert.Equal(t, userID, e.PassengerID)
+			assert.Equal(t, 3.0, e.SearchRadiusKm)
+			assert.Equal(t, 0, e.DriversAttempted)
+			return nil
+		})
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_AllMatchesFail_PublishesNoDriversFound(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm: 5.0,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+	}
+
+	nearbyDrivers := []*models.NearbyUser{
+		{ID: uuid.New().String(), Distance: 1.2},
+		{ID: uuid.New().String(), Distance: 2.4},
+	}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+	mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), 5.0).Return(nearbyDrivers, nil)
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), userID, gomock.Any(), gomock.Any()).Return(true, nil).Times(2)
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("pending match exists")).
+		Times(2)
+	// Failed proposals do not keep the drivers suppressed for the passenger's next search
+	mockRepo.EXPECT().ReleaseMatchProposal(gomock.Any(), userID, nearbyDrivers[0].ID).Return(nil)
+	mockRepo.EXPECT().ReleaseMatchProposal(gomock.Any(), userID, nearbyDrivers[1].ID).Return(nil)
+
+	mockGW.EXPECT().
+		PublishNoDriversFound(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, e models.NoDriversFoundEvent) error {
+			assert.Equal(t, userID, e.PassengerID)
+			assert.Equal(t, 2, e.DriversAttempted)
+			return nil
+		})
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleBeaconEvent_StaleLocationSkipped(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			MaxLocationAgeSecs: 30,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	event := models.BeaconEvent{
+		UserID:   uuid.New().String(),
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+			Timestamp: time.Now().Add(-2 * time.Minute),
+		},
+	}
+
+	// No repository or gateway calls are expected for a stale beacon
+
+	// Act
+	err := uc.HandleBeaconEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestIsLocationStale_UsesClock(t *testing.T) {
+	uc := NewMatchUC(&models.Config{Match: models.MatchConfig{MaxLocationAgeSecs: 30}}, nil, nil)
+	start := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+	clk := clock.NewMock(start)
+	uc.clock = clk
+
+	location := models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: start}
+	assert.False(t, uc.isLocationStale(location, time.Time{}))
+
+	clk.Advance(30 * time.Second)
+	assert.False(t, uc.isLocationStale(location, time.Time{}))
+
+	clk.Advance(time.Second)
+	assert.True(t, uc.isLocationStale(location, time.Time{}))
+}
+
+func TestIsLocationStale_FallsBackToEventTimestamp(t *testing.T) {
+	uc := NewMatchUC(&models.Config{Match: models.MatchConfig{MaxLocationAgeSecs: 30}}, nil, nil)
+	start := time.Date(2030, 1, 1, 8, 0, 0, 0, time.UTC)
+	uc.clock = clock.NewMock(start.Add(time.Minute))
+
+	unstamped := models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	assert.True(t, uc.isLocationStale(unstamped, start))
+	assert.False(t, uc.isLocationStale(unstamped, time.Time{}))
+
+	// The location's own timestamp wins over the event's
+	stamped := models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: start.Add(45 * time.Second)}
+	assert.False(t, uc.isLocationStale(stamped, start))
+}
+
+func TestHandleBeaconEvent_StaleEventAsPublished(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{Match: models.MatchConfig{MaxLocationAgeSecs: 30}}, mockRepo, mockGW)
+
+	// Built the way the users service publishes a beacon that was sent two minutes ago:
+	// the event is timestamped, the location itself is not
+	event := models.BeaconEvent{
+		UserID:   uuid.New().String(),
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+		Timestamp: time.Now().Add(-2 * time.Minute),
+	}
+
+	// No repository or gateway calls are expected for a stale beacon
+	err := uc.HandleBeaconEvent(context.Background(), event)
+
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_StaleLocationSkipped(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+
+	// Default max age applies when not configured
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	event := models.FinderEvent{
+		UserID:   uuid.New().String(),
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+			Timestamp: time.Now().Add(-defaultMaxLocationAge - time.Second),
+		},
+	}
+
+	// No passenger is added and no matches are created for a stale finder event
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleBeaconEvent_FreshLocationWithinConfiguredAge(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			MaxLocationAgeSecs: 300,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.BeaconEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+			Timestamp: time.Now().Add(-2 * time.Minute),
+		},
+	}
+
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailableDriver(gomock.Any(), userID, gomock.Any()).Return(nil)
+
+	// Act
+	err := uc.HandleBeaconEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleAutoRejection_PublishesOnlyTransitionedMatches(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	passengerID := uuid.New()
+	accepted := &models.Match{ID: uuid.New(), PassengerID: passengerID, DriverID: uuid.New(), Status: models.MatchStatusAccepted}
+	stillPending := &models.Match{ID: uuid.New(), PassengerID: passengerID, DriverID: uuid.New(), Status: models.MatchStatusPending}
+	// Listed as pending but accepted by its driver before the batch update ran
+	raced := &models.Match{ID: uuid.New(), PassengerID: passengerID, DriverID: uuid.New(), Status: models.MatchStatusDriverConfirmed}
+
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return([]*models.Match{accepted, stillPending, raced}, nil)
+
+	mockRepo.EXPECT().
+		BatchUpdateMatchStatus(gomock.Any(), []string{stillPending.ID.String(), raced.ID.String()}, models.MatchStatusRejected, models.OpenMatchStatuses).
+		Return([]string{stillPending.ID.String()}, nil)
+
+	mockGW.EXPECT().
+		PublishMatchRejected(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			assert.Equal(t, stillPending.ID.String(), mp.ID)
+			assert.Equal(t, models.MatchStatusRejected, mp.MatchStatus)
+			return nil
+		}).
+		Times(1)
+
+	// Act
+	err := uc.handleAutoRejectionForAcceptedMatch(context.Background(), accepted)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_RepeatedEventsDoNotDoublePropose(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm:    5.0,
+			ProposalDedupSecs: 20,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+	}
+
+	nearbyDrivers := []*models.NearbyUser{
+		{ID: uuid.New().String(), Distance: 1.2},
+		{ID: uuid.New().String(), Distance: 2.4},
+	}
+
+	// Emulate the Redis dedup window: only the first claim for a pair succeeds
+	claimed := map[string]bool{}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil).Times(2)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil).Times(2)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil).Times(2)
+	mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), 5.0).Return(nearbyDrivers, nil).Times(2)
+	mockRepo.EXPECT().
+		ClaimMatchProposal(gomock.Any(), userID, gomock.Any(), 20*time.Second).
+		DoAndReturn(func(_ context.Context, passengerID, driverID string, _ time.Duration) (bool, error) {
+			key := passengerID + ":" + driverID
+			if claimed[key] {
+				return false, nil
+			}
+			claimed[key] = true
+			return true, nil
+		}).
+		Times(4)
+
+	// Each driver is proposed exactly once across both finder events
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, match *models.Match) (*models.Match, error) {
+			match.ID = uuid.New()
+			return match, nil
+		}).
+		Times(2)
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil).Times(2)
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+	assert.NoError(t, err)
+
+	// The retry finds the same drivers; suppressed proposals must not be reported as no drivers found
+	err = uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_CarriesPaymentMethodToProposals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{Match: models.MatchConfig{SearchRadiusKm: 5.0}}, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	driverID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:         userID,
+		IsActive:       true,
+		Location:       models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		TargetLocation: models.Location{Latitude: -6.2, Longitude: 106.816666},
+		PaymentMethod:  models.PaymentMethodCash,
+	}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+	mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), 5.0).
+		Return([]*models.NearbyUser{{ID: driverID, Distance: 1.2}}, nil)
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), driverID).Return(time.Duration(0), nil)
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), userID, driverID, gomock.Any()).Return(true, nil)
+
+	var created *models.Match
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, match *models.Match) (*models.Match, error) {
+			match.ID = uuid.New()
+			created = match
+			return match, nil
+		})
+	var found models.MatchProposal
+	mockGW.EXPECT().
+		PublishMatchFound(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			found = mp
+			return nil
+		})
+
+	require.NoError(t, uc.HandleFinderEvent(context.Background(), event))
+	require.NotNil(t, created)
+	assert.Equal(t, models.PaymentMethodCash, created.PaymentMethod, "the match stores the passenger's choice")
+	assert.Equal(t, models.PaymentMethodCash, found.PaymentMethod)
+
+	// Once accepted, the proposal the rides service creates the ride from carries it too
+	var accepted models.MatchProposal
+	mockGW.EXPECT().
+		PublishMatchAccepted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, mp models.MatchProposal) error {
+			accepted = mp
+			return nil
+		})
+	created.Status = models.MatchStatusAccepted
+	uc.PublishMatchAccepted(context.Background(), created)
+	assert.Equal(t, models.PaymentMethodCash, accepted.PaymentMethod)
+}
+
+func TestHandleFinderEvent_DedupClaimErrorStillProposes(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm: 5.0,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+		},
+	}
+
+	driverID := uuid.New().String()
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+	mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), 5.0).
+		Return([]*models.NearbyUser{{ID: driverID, Distance: 1.0}}, nil)
+	// Default window applies when none is configured
+	mockRepo.EXPECT().
+		ClaimMatchProposal(gomock.Any(), userID, driverID, defaultProposalDedupWindow).
+		Return(false, errors.New("redis unavailable"))
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, match *models.Match) (*models.Match, error) {
+			match.ID = uuid.New()
+			return match, nil
+		})
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_ScheduledRideHeldUntilDue(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	scheduledAt := time.Now().Add(time.Hour)
+	event := models.FinderEvent{
+		UserID:      userID,
+		IsActive:    true,
+		Location:    models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		ScheduledAt: &scheduledAt,
+	}
+
+	// Only the schedule is written; no pool or matching calls are made yet
+	mockRepo.EXPECT().ScheduleFinderEvent(gomock.Any(), event, scheduledAt).Return(nil)
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestHandleFinderEvent_InactiveCancelsScheduledRide(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{UserID: userID, IsActive: false}
+
+	mockRepo.EXPECT().CancelScheduledFinderEvent(gomock.Any(), userID).Return(nil)
+	mockGW.EXPECT().RemoveAvailablePassenger(gomock.Any(), userID).Return(nil)
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestReleaseDueScheduledFinders_MatchesDueRides(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm: 3.0,
+		},
+	}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	// No nearby driver is suspended for cancellations
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), gomock.Any()).Return(time.Duration(0), nil).AnyTimes()
+
+	userID := uuid.New().String()
+	driverID := uuid.New().String()
+	scheduledAt := time.Now().Add(-time.Second)
+	now := time.Now()
+	booked := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{
+			Latitude:  -6.175392,
+			Longitude: 106.827153,
+			// Captured when the ride was booked, long before release
+			Timestamp: now.Add(-2 * time.Hour),
+		},
+		ScheduledAt: &scheduledAt,
+	}
+
+	mockRepo.EXPECT().ClaimDueScheduledFinderEvents(gomock.Any(), now, 10).Return([]models.FinderEvent{booked}, nil)
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+	mockGW.EXPECT().
+		FindNearbyDrivers(gomock.Any(), gomock.Any(), 3.0).
+		Return([]*models.NearbyUser{{ID: driverID, Location: models.Location{Latitude: -6.176, Longitude: 106.828}}}, nil)
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), userID, driverID, gomock.Any()).Return(true, nil)
+	mockRepo.EXPECT().CreateMatch(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, m *models.Match) (*models.Match, error) {
+			m.ID = uuid.New()
+			return m, nil
+		})
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	released, err := uc.ReleaseDueScheduledFinders(context.Background(), now, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+}
+
+func TestReleaseDueScheduledFinders_NothingDue(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	now := time.Now()
+	mockRepo.EXPECT().ClaimDueScheduledFinderEvents(gomock.Any(), now, 100).Return([]models.FinderEvent{}, nil)
+
+	// Act
+	released, err := uc.ReleaseDueScheduledFinders(context.Background(), now, 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+}
+
+func TestReleaseDueScheduledFinders_UnmatchedRideNotifiesPassenger(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm: 3.0,
+		},
+	}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	now := time.Now()
+	booked := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153},
+	}
+
+	mockRepo.EXPECT().ClaimDueScheduledFinderEvents(gomock.Any(), now, 10).Return([]models.FinderEvent{booked}, nil)
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(errors.New("location service down"))
+	mockGW.EXPECT().
+		PublishNoDriversFound(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, e models.NoDriversFoundEvent) error {
+			assert.Equal(t, userID, e.PassengerID)
+			return nil
+		})
+
+	// Act
+	released, err := uc.ReleaseDueScheduledFinders(context.Background(), now, 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+}
+
+func TestReleaseDueScheduledFinders_HeldDuringMaintenance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No rides are claimed, so they stay scheduled until maintenance ends
+	cfg := &models.Config{Match: models.MatchConfig{MaintenanceMode: true}}
+	uc := NewMatchUC(cfg, mocks.NewMockMatchRepo(ctrl), mocks.NewMockMatchGW(ctrl))
+
+	released, err := uc.ReleaseDueScheduledFinders(context.Background(), time.Now(), 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, released)
+}
+
+func TestReleaseDueScheduledFinders_MaintenanceMidBatchRequeues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	now := time.Now()
+	booked := models.FinderEvent{
+		UserID:   userID,
+		IsActive: true,
+		Location: models.Location{Latitude: -6.175392, Longitude: 106.827153},
+	}
+
+	// Maintenance starts after the ride was claimed
+	mockRepo.EXPECT().
+		ClaimDueScheduledFinderEvents(gomock.Any(), now, 10).
+		DoAndReturn(func(context.Context, time.Time, int) ([]models.FinderEvent, error) {
+			uc.maintenance.Store(true)
+			return []models.FinderEvent{booked}, nil
+		})
+
+	// The ride goes back in the schedule and the passenger is not told it went unmatched
+	mockRepo.EXPECT().
+		ScheduleFinderEvent(gomock.Any(), gomock.Any(), now).
+		DoAndReturn(func(_ context.Context, e models.FinderEvent, _ time.Time) error {
+			assert.Equal(t, userID, e.UserID)
+			return nil
+		})
+
+	released, err := uc.ReleaseDueScheduledFinders(context.Background(), now, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, released)
+}
+
+func TestHandleFinderEvent_MaintenanceMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{
+			SearchRadiusKm:  5.0,
+			MaintenanceMode: true,
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	event := models.FinderEvent{
+		UserID:         uuid.New().String(),
+		IsActive:       true,
+		Location:       models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: time.Now()},
+		TargetLocation: models.Location{Latitude: -6.200000, Longitude: 106.816666},
+		Timestamp:      time.Now(),
+	}
+
+	// The passenger is told why their search went nowhere
+	mockGW.EXPECT().
+		PublishMatchMaintenance(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, e models.MatchMaintenanceEvent) error {
+			assert.Equal(t, event.UserID, e.UserID)
+			assert.Equal(t, "passenger", e.Role)
+			return nil
+		})
+
+	// The strict mocks fail the test if the passenger is pooled or any match is created
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	assert.ErrorIs(t, err, match.ErrMaintenanceMode)
+}
+
+func TestHandleFinderEvent_MaintenanceNoticePublishFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{Match: models.MatchConfig{MaintenanceMode: true}}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	event := models.FinderEvent{
+		UserID:    uuid.New().String(),
+		IsActive:  true,
+		Location:  models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		Timestamp: time.Now(),
+	}
+
+	// The event is retried until the passenger has been told
+	mockGW.EXPECT().PublishMatchMaintenance(gomock.Any(), gomock.Any()).Return(errors.New("nats down"))
+
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, match.ErrMaintenanceMode)
+}
+
+func TestHandleBeaconEvent_RuntimeMaintenanceMode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	driverID := uuid.New().String()
+	beacon := models.BeaconEvent{
+		UserID:    driverID,
+		IsActive:  true,
+		Location:  models.Location{Latitude: -6.175392, Longitude: 106.827153, Timestamp: time.Now()},
+		Timestamp: time.Now(),
+	}
+
+	mockRepo.EXPECT().SetMaintenanceMode(gomock.Any(), true).Return(nil)
+	assert.NoError(t, uc.SetMaintenanceMode(context.Background(), true))
+	assert.True(t, uc.IsMaintenanceMode(context.Background()))
+
+	// The driver is told why they weren't put online
+	mockGW.EXPECT().
+		PublishMatchMaintenance(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, e models.MatchMaintenanceEvent) error {
+			assert.Equal(t, driverID, e.UserID)
+			assert.Equal(t, "driver", e.Role)
+			return nil
+		})
+
+	err := uc.HandleBeaconEvent(context.Background(), beacon)
+	assert.ErrorIs(t, err, match.ErrMaintenanceMode)
+
+	// Drivers going offline still leave the pool
+	mockGW.EXPECT().RemoveAvailableDriver(gomock.Any(), driverID).Return(nil)
+	beacon.IsActive = false
+	assert.NoError(t, uc.HandleBeaconEvent(context.Background(), beacon))
+}
+
+func TestWatchMaintenanceMode_PicksUpRuntimeFlag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	// Another instance switched maintenance on
+	mockRepo.EXPECT().GetMaintenanceMode(gomock.Any()).Return(true, nil).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		uc.WatchMaintenanceMode(ctx, time.Hour)
+		close(done)
+	}()
+
+	assert.Eventually(t, func() bool { return uc.IsMaintenanceMode(ctx) }, time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+}
+
+func TestCreateMatchesWithNearbyDrivers_RegionalSearchRadius(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Match: models.MatchConfig{SearchRadiusKm: 3.0},
+		Regions: []models.RegionConfig{
+			{Name: "jakarta", GeohashPrefix: "qqgu", SearchRadiusKm: 1.0},
+			{Name: "bogor", GeohashPrefix: "qqgf", SearchRadiusKm: 5.0},
+		},
+	}
+
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	jakarta := &models.Location{Latitude: -6.2088, Longitude: 106.8456}
+	bogor := &models.Location{Latitude: -6.5971, Longitude: 106.8060}
+	target := &models.Location{Latitude: -6.1751, Longitude: 106.8650}
+
+	// The same search covers a smaller area in the dense city
+	for _, tc := range []struct {
+		location *models.Location
+		radiusKm float64
+	}{
+		{location: jakarta, radiusKm: 1.0},
+		{location: bogor, radiusKm: 5.0},
+	} {
+		passengerID := uuid.New().String()
+		mockGW.EXPECT().
+			FindNearbyDrivers(gomock.Any(), tc.location, tc.radiusKm).
+			Return([]*models.NearbyUser{}, nil)
+		mockGW.EXPECT().
+			PublishNoDriversFound(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, event models.NoDriversFoundEvent) error {
+				assert.Equal(t, tc.radiusKm, event.SearchRadiusKm)
+				return nil
+			})
+
+		err := uc.createMatchesWithNearbyDrivers(context.Background(), passengerID, tc.location, target, "", "")
+		assert.NoError(t, err)
+	}
+}
+
+func TestReloadConfig_UpdatesSearchRadius(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{
+		Server: models.ServerConfig{Port: 9993},
+		Match:  models.MatchConfig{SearchRadiusKm: 5.0, MaxPendingPerPassenger: 3},
+	}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:         userID,
+		IsActive:       true,
+		Location:       models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		TargetLocation: models.Location{Latitude: -6.200000, Longitude: 106.816666},
+		Timestamp:      time.Now(),
+	}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil).Times(2)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil).Times(2)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil).Times(2)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil).Times(2)
+	mockGW.EXPECT().PublishNoDriversFound(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	gomock.InOrder(
+		mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), 5.0).Return([]*models.NearbyUser{}, nil),
+		mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), 8.0).Return([]*models.NearbyUser{}, nil),
+	)
+
+	require.NoError(t, uc.HandleFinderEvent(context.Background(), event))
+
+	// The reload changes the radius but structural and non-tunable settings stay as started
+	uc.ReloadConfig(&models.Config{
+		Server: models.ServerConfig{Port: 1234},
+		Match:  models.MatchConfig{SearchRadiusKm: 8.0},
+	})
+	assert.Equal(t, 9993, uc.config().Server.Port)
+	assert.Equal(t, 3, uc.config().Match.MaxPendingPerPassenger)
+	assert.Equal(t, 5.0, cfg.Match.SearchRadiusKm)
+
+	require.NoError(t, uc.HandleFinderEvent(context.Background(), event))
+}
+
+func TestHandleDriverCancellation_SuspendsAboveThreshold(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{Match: models.MatchConfig{
+		CancellationWindowHours:   24,
+		CancellationRateThreshold: 0.5,
+		CancellationMinAccepted:   4,
+		CancellationPenaltyMins:   30,
+	}}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	driverID := uuid.New().String()
+	rideID := uuid.New().String()
+
+	mockRepo.EXPECT().RecordDriverCancelled(gomock.Any(), driverID, rideID, gomock.Any(), 24*time.Hour).Return(nil)
+	mockRepo.EXPECT().CountDriverOutcomes(gomock.Any(), driverID, gomock.Any()).Return(4, 3, nil)
+	mockRepo.EXPECT().SuspendDriver(gomock.Any(), driverID, 30*time.Minute).Return(nil)
+	mockGW.EXPECT().RemoveAvailableDriver(gomock.Any(), driverID).Return(nil)
+
+	err := uc.HandleDriverCancellation(context.Background(), driverID, rideID)
+	assert.NoError(t, err)
+}
+
+func TestHandleDriverCancellation_NotSuspended(t *testing.T) {
+	tests := []struct {
+		name      string
+		accepted  int
+		cancelled int
+	}{
+		{name: "rate at threshold", accepted: 4, cancelled: 2},
+		{name: "too few accepted rides", accepted: 2, cancelled: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockMatchRepo(ctrl)
+			mockGW := mocks.NewMockMatchGW(ctrl)
+			cfg := &models.Config{Match: models.MatchConfig{CancellationRateThreshold: 0.5, CancellationMinAccepted: 4}}
+			uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+			driverID := uuid.New().String()
+			mockRepo.EXPECT().RecordDriverCancelled(gomock.Any(), driverID, gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+			mockRepo.EXPECT().CountDriverOutcomes(gomock.Any(), driverID, gomock.Any()).Return(tt.accepted, tt.cancelled, nil)
+
+			// SuspendDriver and RemoveAvailableDriver are not expected
+			err := uc.HandleDriverCancellation(context.Background(), driverID, uuid.New().String())
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestHandleFinderEvent_SkipsSuspendedDriver(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	cfg := &models.Config{Match: models.MatchConfig{SearchRadiusKm: 5.0}}
+	uc := NewMatchUC(cfg, mockRepo, mockGW)
+
+	userID := uuid.New().String()
+	event := models.FinderEvent{
+		UserID:         userID,
+		IsActive:       true,
+		Location:       models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		TargetLocation: models.Location{Latitude: -6.200000, Longitude: 106.816666},
+		Timestamp:      time.Now(),
+	}
+
+	mockRepo.EXPECT().GetActiveRideByPassenger(gomock.Any(), userID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), userID).Return(false, nil)
+	mockGW.EXPECT().AddAvailablePassenger(gomock.Any(), userID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().UpdatePendingMatchesPassengerLocation(gomock.Any(), uuid.MustParse(userID), gomock.Any()).Return(nil, nil)
+
+	suspendedDriver := &models.NearbyUser{ID: uuid.New().String(), Location: models.Location{Latitude: -6.175390, Longitude: 106.827150}}
+	otherDriver := &models.NearbyUser{ID: uuid.New().String(), Location: models.Location{Latitude: -6.175400, Longitude: 106.827160}}
+	mockGW.EXPECT().FindNearbyDrivers(gomock.Any(), gomock.Any(), gomock.Any()).Return([]*models.NearbyUser{suspendedDriver, otherDriver}, nil)
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), suspendedDriver.ID).Return(20*time.Minute, nil)
+	mockRepo.EXPECT().GetDriverSuspension(gomock.Any(), otherDriver.ID).Return(time.Duration(0), nil)
+
+	// Only the driver without a penalty is proposed
+	mockRepo.EXPECT().ClaimMatchProposal(gomock.Any(), userID, otherDriver.ID, gomock.Any()).Return(true, nil)
+	mockRepo.EXPECT().
+		CreateMatch(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, m *models.Match) (*models.Match, error) {
+			assert.Equal(t, otherDriver.ID, m.DriverID.String())
+			m.ID = uuid.New()
+			return m, nil
+		})
+	mockGW.EXPECT().PublishMatchFound(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	err := uc.HandleFinderEvent(context.Background(), event)
+
+	// Assert
+	assert.NoError(t, err)
+}
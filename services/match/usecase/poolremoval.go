@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	// defaultPoolRemovalRetryInterval is used when no pool removal retry interval is configured
+	defaultPoolRemovalRetryInterval = 10 * time.Second
+	// poolRemovalBatchSize is the most queued pool removals retried per run
+	poolRemovalBatchSize = 100
+)
+
+// Roles of a queued pool removal
+const (
+	poolRoleDriver    = "driver"
+	poolRolePassenger = "passenger"
+)
+
+// removeMatchedUsersFromPools takes both users of an accepted match out of the available pools.
+// A failed removal doesn't fail the acceptance; it is queued and retried so the matched driver
+// stops receiving proposals once the location service is reachable again.
+func (uc *MatchUC) removeMatchedUsersFromPools(ctx context.Context, match *models.Match) {
+	removals := []models.PoolRemoval{
+		{Role: poolRoleDriver, UserID: match.DriverID.String()},
+		{Role: poolRolePassenger, UserID: match.PassengerID.String()},
+	}
+
+	for _, removal := range removals {
+		if err := uc.removeFromPool(ctx, removal); err != nil {
+			logger.Warn("Failed to remove matched user from pool, queueing retry",
+				logger.String("match_id", match.ID.String()),
+				logger.String("role", removal.Role),
+				logger.String("user_id", removal.UserID),
+				logger.ErrorField(err))
+
+			if err := uc.matchRepo.QueuePoolRemoval(ctx, removal); err != nil {
+				logger.Error("Failed to queue pool removal retry",
+					logger.String("role", removal.Role),
+					logger.String("user_id", removal.UserID),
+					logger.ErrorField(err))
+			}
+		}
+	}
+}
+
+// removeFromPool removes a user from the available pool of their role
+func (uc *MatchUC) removeFromPool(ctx context.Context, removal models.PoolRemoval) error {
+	switch removal.Role {
+	case poolRoleDriver:
+		return uc.matchGW.RemoveAvailableDriver(ctx, removal.UserID)
+	case poolRolePassenger:
+		return uc.matchGW.RemoveAvailablePassenger(ctx, removal.UserID)
+	default:
+		return fmt.Errorf("unknown pool role %q", removal.Role)
+	}
+}
+
+// stillMatched reports whether the user of a queued removal is still held by a ride lock or an
+// active ride. Beacons and finders can't put such a user back in the pool, so the removal is still
+// owed; anyone else may have rejoined the pool since, which the retry must not undo.
+func (uc *MatchUC) stillMatched(ctx context.Context, removal models.PoolRemoval) (bool, error) {
+	locked, err := uc.matchRepo.IsRideLocked(ctx, removal.UserID)
+	if err != nil || locked {
+		return locked, err
+	}
+
+	var rideID string
+	switch removal.Role {
+	case poolRoleDriver:
+		rideID, err = uc.matchRepo.GetActiveRideByDriver(ctx, removal.UserID)
+	case poolRolePassenger:
+		rideID, err = uc.matchRepo.GetActiveRideByPassenger(ctx, removal.UserID)
+	default:
+		return false, fmt.Errorf("unknown pool role %q", removal.Role)
+	}
+	return rideID != "", err
+}
+
+// RetryPoolRemovals retries queued pool removals and returns how many succeeded. Removals that
+// fail again stay queued for the next run. Users whose ride has ended by the time of the retry are
+// left in the pool, since they may have come back online, and their removal is dropped.
+func (uc *MatchUC) RetryPoolRemovals(ctx context.Context) (int, error) {
+	removals, err := uc.matchRepo.ListPoolRemovals(ctx, poolRemovalBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, removal := range removals {
+		held, err := uc.stillMatched(ctx, removal)
+		if err != nil {
+			logger.Warn("Failed to check whether pool removal is still due",
+				logger.String("role", removal.Role),
+				logger.String("user_id", removal.UserID),
+				logger.ErrorField(err))
+			continue
+		}
+
+		if held {
+			if err := uc.removeFromPool(ctx, removal); err != nil {
+				logger.Warn("Pool removal retry failed",
+					logger.String("role", removal.Role),
+					logger.String("user_id", removal.UserID),
+					logger.ErrorField(err))
+				continue
+			}
+		} else {
+			logger.Info("Dropping pool removal of user no longer matched",
+				logger.String("role", removal.Role),
+				logger.String("user_id", removal.UserID))
+		}
+
+		if err := uc.matchRepo.CompletePoolRemoval(ctx, removal); err != nil {
+			logger.Error("Failed to complete pool removal",
+				logger.String("role", removal.Role),
+				logger.String("user_id", removal.UserID),
+				logger.ErrorField(err))
+			continue
+		}
+		if held {
+			removed++
+		}
+	}
+
+	if removed > 0 {
+		logger.Info("Retried pool removals", logger.Int("removed", removed))
+	}
+	return removed, nil
+}
+
+// RunPoolRemovalRetry periodically retries queued pool removals until ctx is cancelled
+func (uc *MatchUC) RunPoolRemovalRetry(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultPoolRemovalRetryInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Pool removal retry stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.RetryPoolRemovals(ctx); err != nil {
+				logger.Error("Pool removal retry run failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
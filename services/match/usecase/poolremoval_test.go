@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateMatchConfirmation_PoolRemovalFailureQueuesRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	pending := &models.Match{
+		ID:                 uuid.New(),
+		DriverID:           uuid.New(),
+		PassengerID:        uuid.New(),
+		PassengerConfirmed: true,
+		Status:             models.MatchStatusPassengerConfirmed,
+	}
+	driverID := pending.DriverID.String()
+	passengerID := pending.PassengerID.String()
+
+	mockGW.EXPECT().RemoveAvailableDriver(gomock.Any(), driverID).Return(errors.New("location service unavailable"))
+	mockGW.EXPECT().RemoveAvailablePassenger(gomock.Any(), passengerID).Return(nil)
+
+	// Only the failed removal is queued for retry
+	mockRepo.EXPECT().
+		QueuePoolRemoval(gomock.Any(), models.PoolRemoval{Role: "driver", UserID: driverID}).
+		Return(nil)
+
+	// The acceptance still completes
+	accepted := *pending
+	accepted.Status = models.MatchStatusAccepted
+	mockRepo.EXPECT().
+		ConfirmMatchByUser(gomock.Any(), pending.ID.String(), driverID, true).
+		Return(&accepted, nil)
+
+	updated, err := uc.updateMatchConfirmation(context.Background(), pending, driverID, true)
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusAccepted, updated.Status)
+}
+
+func TestRetryPoolRemovals_CompletesOnlySuccessfulRemovals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	driver := models.PoolRemoval{Role: "driver", UserID: "driver-1"}
+	passenger := models.PoolRemoval{Role: "passenger", UserID: "passenger-1"}
+
+	mockRepo.EXPECT().ListPoolRemovals(gomock.Any(), poolRemovalBatchSize).
+		Return([]models.PoolRemoval{driver, passenger}, nil)
+
+	// Both users are still on their ride
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), "driver-1").Return(false, nil)
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), "driver-1").Return("ride-1", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), "passenger-1").Return(true, nil)
+
+	mockGW.EXPECT().RemoveAvailableDriver(gomock.Any(), "driver-1").Return(nil)
+	mockRepo.EXPECT().CompletePoolRemoval(gomock.Any(), driver).Return(nil)
+
+	// A removal that fails again stays queued
+	mockGW.EXPECT().RemoveAvailablePassenger(gomock.Any(), "passenger-1").Return(errors.New("timeout"))
+
+	removed, err := uc.RetryPoolRemovals(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+}
+
+func TestRetryPoolRemovals_SkipsUsersNoLongerMatched(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	driver := models.PoolRemoval{Role: "driver", UserID: "driver-1"}
+	passenger := models.PoolRemoval{Role: "passenger", UserID: "passenger-1"}
+
+	mockRepo.EXPECT().ListPoolRemovals(gomock.Any(), poolRemovalBatchSize).
+		Return([]models.PoolRemoval{driver, passenger}, nil)
+
+	// The driver's ride ended and they turned their beacon back on, so they stay in the pool
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), "driver-1").Return(false, nil)
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), "driver-1").Return("", nil)
+	mockGW.EXPECT().RemoveAvailableDriver(gomock.Any(), gomock.Any()).Times(0)
+	mockRepo.EXPECT().CompletePoolRemoval(gomock.Any(), driver).Return(nil)
+
+	// A failed check leaves the removal queued for the next run
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), "passenger-1").Return(false, errors.New("redis down"))
+	mockGW.EXPECT().RemoveAvailablePassenger(gomock.Any(), gomock.Any()).Times(0)
+
+	removed, err := uc.RetryPoolRemovals(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 0, removed)
+}
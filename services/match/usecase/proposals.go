@@ -0,0 +1,200 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+const (
+	// defaultAverageSpeedKmh is used for pickup ETAs when no average driver speed is configured
+	defaultAverageSpeedKmh = 25.0
+	// proposalRefreshPollInterval is how often throttled proposal refreshes are picked up when none is given
+	proposalRefreshPollInterval = time.Second
+	// proposalRefreshBatchSize is the most throttled proposal refreshes sent per run
+	proposalRefreshBatchSize = 100
+)
+
+// proposalRefreshInterval returns the minimum time between re-sent proposal lists for one
+// passenger. Zero turns re-sending off.
+func (uc *MatchUC) proposalRefreshInterval() time.Duration {
+	return time.Duration(uc.config().Match.ProposalRefreshSecs) * time.Second
+}
+
+// pickupDistanceKm returns the straight-line distance from a match's driver to its pickup,
+// rounded to 10 meters
+func pickupDistanceKm(m *models.Match) float64 {
+	km := utils.CalculateDistance(
+		utils.GeoPoint{Latitude: m.DriverLocation.Latitude, Longitude: m.DriverLocation.Longitude},
+		utils.GeoPoint{Latitude: m.PassengerLocation.Latitude, Longitude: m.PassengerLocation.Longitude},
+	)
+	return math.Round(km*100) / 100
+}
+
+// pickupETASeconds estimates how long a driver needs to cover distanceKm at the configured average speed
+func (uc *MatchUC) pickupETASeconds(distanceKm float64) int {
+	speed := uc.config().Match.AverageSpeedKmh
+	if speed <= 0 {
+		speed = defaultAverageSpeedKmh
+	}
+	return int(math.Ceil(distanceKm / speed * time.Hour.Seconds()))
+}
+
+// pendingProposalOptions returns the passenger's unanswered proposals, nearest driver first, with
+// the pickup distance and ETA of each. Once the passenger has accepted one of them there is nothing
+// left to choose between, so none are returned.
+func (uc *MatchUC) pendingProposalOptions(matches []*models.Match) []models.MatchProposal {
+	options := make([]models.MatchProposal, 0, len(matches))
+	for _, m := range matches {
+		switch m.Status {
+		case models.MatchStatusPassengerConfirmed:
+			return nil
+		case models.MatchStatusPending, models.MatchStatusDriverConfirmed:
+			option := uc.buildMatchProposal(m)
+			option.PickupDistanceKm = pickupDistanceKm(m)
+			option.PickupETASeconds = uc.pickupETASeconds(option.PickupDistanceKm)
+			options = append(options, option)
+		}
+	}
+
+	sort.SliceStable(options, func(i, j int) bool {
+		return options[i].PickupDistanceKm < options[j].PickupDistanceKm
+	})
+	return options
+}
+
+// refreshDriverProposals moves a driver's position on their unanswered matches and re-sends the
+// pending proposals of each passenger involved, so passengers compare drivers on where they are
+// now. Errors are logged so the beacon or location update is still handled.
+func (uc *MatchUC) refreshDriverProposals(ctx context.Context, driverID string, location *models.Location) {
+	if uc.proposalRefreshInterval() <= 0 {
+		return
+	}
+
+	driverUUID, err := uuid.Parse(driverID)
+	if err != nil {
+		return
+	}
+	matches, err := uc.matchRepo.UpdatePendingMatchesDriverLocation(ctx, driverUUID, *location)
+	if err != nil {
+		logger.Error("Failed to update driver location on pending matches",
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+		return
+	}
+
+	refreshed := make(map[uuid.UUID]bool, len(matches))
+	for _, m := range matches {
+		if refreshed[m.PassengerID] {
+			continue
+		}
+		refreshed[m.PassengerID] = true
+		uc.presentPendingProposals(ctx, m.PassengerID)
+	}
+}
+
+// HandleDriverLocationUpdate re-ranks the proposals of a driver reporting their position during a
+// ride, such as one finishing a ride who opted in to their next match
+func (uc *MatchUC) HandleDriverLocationUpdate(ctx context.Context, update models.LocationUpdate) {
+	if update.DriverID == "" {
+		return
+	}
+	location := update.Location
+	uc.refreshDriverProposals(ctx, update.DriverID, &location)
+}
+
+// presentPendingProposals re-sends a passenger's pending proposals, at most once per refresh
+// interval. A throttled refresh is deferred until the interval ends rather than dropped, so the
+// last position reported within it still reaches the passenger.
+func (uc *MatchUC) presentPendingProposals(ctx context.Context, passengerID uuid.UUID) {
+	claimed, err := uc.matchRepo.ClaimProposalRefresh(ctx, passengerID.String(), uc.proposalRefreshInterval())
+	if err != nil {
+		logger.Error("Failed to claim proposal refresh",
+			logger.String("passenger_id", passengerID.String()),
+			logger.ErrorField(err))
+		return
+	}
+	if !claimed {
+		if err := uc.matchRepo.ScheduleProposalRefresh(ctx, passengerID.String(), uc.clock.Now()); err != nil {
+			logger.Error("Failed to schedule throttled proposal refresh",
+				logger.String("passenger_id", passengerID.String()),
+				logger.ErrorField(err))
+		}
+		return
+	}
+
+	matches, err := uc.matchRepo.ListOpenMatchesByPassenger(ctx, passengerID)
+	if err != nil {
+		logger.Error("Failed to list passenger matches for proposal refresh",
+			logger.String("passenger_id", passengerID.String()),
+			logger.ErrorField(err))
+		return
+	}
+
+	proposals := uc.pendingProposalOptions(matches)
+	if len(proposals) == 0 {
+		return
+	}
+
+	if err := uc.matchGW.PublishPendingProposals(ctx, models.PendingProposalsEvent{
+		PassengerID: passengerID.String(),
+		Proposals:   proposals,
+		Timestamp:   uc.clock.Now(),
+	}); err != nil {
+		logger.Error("Failed to publish pending proposals",
+			logger.String("passenger_id", passengerID.String()),
+			logger.ErrorField(err))
+	}
+}
+
+// RefreshDueProposals re-sends the pending proposals of up to limit passengers whose throttled
+// refresh is due at or before now, returning how many were picked up
+func (uc *MatchUC) RefreshDueProposals(ctx context.Context, now time.Time, limit int) (int, error) {
+	passengerIDs, err := uc.matchRepo.ClaimDueProposalRefreshes(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, passengerID := range passengerIDs {
+		passengerUUID, err := uuid.Parse(passengerID)
+		if err != nil {
+			logger.Warn("Skipping proposal refresh with invalid passenger ID",
+				logger.String("passenger_id", passengerID))
+			continue
+		}
+		uc.presentPendingProposals(ctx, passengerUUID)
+	}
+	return len(passengerIDs), nil
+}
+
+// RunProposalRefreshes periodically sends due throttled proposal refreshes until ctx is cancelled.
+// Nothing is run while re-sending proposals is turned off.
+func (uc *MatchUC) RunProposalRefreshes(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = proposalRefreshPollInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Proposal refresher stopped")
+			return
+		case <-ticker.C:
+			if uc.proposalRefreshInterval() <= 0 {
+				continue
+			}
+			if _, err := uc.RefreshDueProposals(ctx, uc.clock.Now(), proposalRefreshBatchSize); err != nil {
+				logger.Error("Proposal refresh run failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,230 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// refreshConfig re-sends pending proposals at most every 10 seconds
+func refreshConfig() *models.Config {
+	return &models.Config{Match: models.MatchConfig{SearchRadiusKm: 5, ProposalRefreshSecs: 10}}
+}
+
+func TestHandleBeaconEvent_DriverMovingCloserUpdatesProposals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(refreshConfig(), mockRepo, mockGW)
+
+	passengerID := uuid.New()
+	moving := driverAtKm(passengerID, 3, models.MatchStatusPending)
+	other := driverAtKm(passengerID, 1, models.MatchStatusDriverConfirmed)
+	driverID := moving.DriverID.String()
+
+	before := uc.pendingProposalOptions([]*models.Match{moving, other})
+	require.Len(t, before, 2)
+	assert.Equal(t, other.ID.String(), before[0].ID)
+	assert.Equal(t, 432, before[1].PickupETASeconds) // 3km at 25km/h
+
+	// The driver's beacon puts them 0.5km from the pickup
+	location := models.Location{
+		Latitude:  moving.PassengerLocation.Latitude + 0.5/111.2,
+		Longitude: moving.PassengerLocation.Longitude,
+		Timestamp: time.Now(),
+	}
+	moved := *moving
+	moved.DriverLocation = models.Location{Latitude: location.Latitude, Longitude: location.Longitude}
+
+	mockRepo.EXPECT().GetActiveRideByDriver(gomock.Any(), driverID).Return("", nil)
+	mockRepo.EXPECT().IsRideLocked(gomock.Any(), driverID).Return(false, nil)
+	mockGW.EXPECT().AddAvailableDriver(gomock.Any(), driverID, gomock.Any()).Return(nil)
+	mockRepo.EXPECT().
+		UpdatePendingMatchesDriverLocation(gomock.Any(), moving.DriverID, gomock.Any()).
+		Return([]*models.Match{&moved}, nil)
+	mockRepo.EXPECT().ClaimProposalRefresh(gomock.Any(), passengerID.String(), 10*time.Second).Return(true, nil)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return([]*models.Match{&moved, other}, nil)
+
+	var published models.PendingProposalsEvent
+	mockGW.EXPECT().
+		PublishPendingProposals(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event models.PendingProposalsEvent) error {
+			published = event
+			return nil
+		})
+
+	err := uc.HandleBeaconEvent(context.Background(), models.BeaconEvent{
+		UserID:   driverID,
+		IsActive: true,
+		Location: location,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, passengerID.String(), published.PassengerID)
+	require.Len(t, published.Proposals, 2)
+
+	// The moving driver is now the nearest, with a shorter ETA than before
+	assert.Equal(t, moving.ID.String(), published.Proposals[0].ID)
+	assert.InDelta(t, 0.5, published.Proposals[0].PickupDistanceKm, 0.01)
+	assert.Equal(t, 72, published.Proposals[0].PickupETASeconds)
+	assert.Less(t, published.Proposals[0].PickupETASeconds, before[1].PickupETASeconds)
+
+	// The driver who stayed put keeps their ETA
+	assert.Equal(t, other.ID.String(), published.Proposals[1].ID)
+	assert.Equal(t, 144, published.Proposals[1].PickupETASeconds)
+}
+
+func TestRefreshDriverProposals_OnePerPassenger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(refreshConfig(), mockRepo, mockGW)
+
+	// The driver was proposed to one passenger twice, e.g. by a scheduled and an immediate search
+	passengerID := uuid.New()
+	first := driverAtKm(passengerID, 1, models.MatchStatusPending)
+	second := driverAtKm(passengerID, 1, models.MatchStatusPending)
+	second.DriverID = first.DriverID
+
+	mockRepo.EXPECT().
+		UpdatePendingMatchesDriverLocation(gomock.Any(), first.DriverID, gomock.Any()).
+		Return([]*models.Match{first, second}, nil)
+	mockRepo.EXPECT().ClaimProposalRefresh(gomock.Any(), passengerID.String(), gomock.Any()).Return(true, nil).Times(1)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), passengerID).
+		Return([]*models.Match{first, second}, nil)
+	mockGW.EXPECT().PublishPendingProposals(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	uc.refreshDriverProposals(context.Background(), first.DriverID.String(), &first.DriverLocation)
+}
+
+func TestRefreshDriverProposals_Throttled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(refreshConfig(), mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	pending := driverAtKm(uuid.New(), 1, models.MatchStatusPending)
+
+	// The position is stored, but the passenger was sent their proposals too recently
+	mockRepo.EXPECT().
+		UpdatePendingMatchesDriverLocation(gomock.Any(), pending.DriverID, gomock.Any()).
+		Return([]*models.Match{pending}, nil)
+	mockRepo.EXPECT().ClaimProposalRefresh(gomock.Any(), pending.PassengerID.String(), gomock.Any()).Return(false, nil)
+
+	// The refresh is deferred to the end of the interval rather than dropped
+	mockRepo.EXPECT().ScheduleProposalRefresh(gomock.Any(), pending.PassengerID.String(), gomock.Any()).Return(nil)
+
+	uc.refreshDriverProposals(context.Background(), pending.DriverID.String(), &pending.DriverLocation)
+}
+
+func TestRefreshDueProposals_SendsDeferredRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(refreshConfig(), mockRepo, mockGW)
+
+	pending := driverAtKm(uuid.New(), 1, models.MatchStatusPending)
+	now := time.Now()
+
+	mockRepo.EXPECT().
+		ClaimDueProposalRefreshes(gomock.Any(), now, 10).
+		Return([]string{pending.PassengerID.String(), "not-a-uuid"}, nil)
+	mockRepo.EXPECT().ClaimProposalRefresh(gomock.Any(), pending.PassengerID.String(), 10*time.Second).Return(true, nil)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), pending.PassengerID).
+		Return([]*models.Match{pending}, nil)
+	mockGW.EXPECT().PublishPendingProposals(gomock.Any(), gomock.Any()).Return(nil)
+
+	refreshed, err := uc.RefreshDueProposals(context.Background(), now, 10)
+	require.NoError(t, err)
+	assert.Equal(t, 2, refreshed)
+}
+
+func TestHandleDriverLocationUpdate_RefreshesProposals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(refreshConfig(), mockRepo, mockGW)
+
+	// A driver finishing a ride reports their position through location updates, not beacons
+	pending := driverAtKm(uuid.New(), 1, models.MatchStatusPending)
+	update := models.LocationUpdate{
+		RideID:   uuid.New().String(),
+		DriverID: pending.DriverID.String(),
+		Location: pending.DriverLocation,
+	}
+
+	mockRepo.EXPECT().
+		UpdatePendingMatchesDriverLocation(gomock.Any(), pending.DriverID, pending.DriverLocation).
+		Return([]*models.Match{pending}, nil)
+	mockRepo.EXPECT().ClaimProposalRefresh(gomock.Any(), pending.PassengerID.String(), gomock.Any()).Return(true, nil)
+	mockRepo.EXPECT().
+		ListOpenMatchesByPassenger(gomock.Any(), pending.PassengerID).
+		Return([]*models.Match{pending}, nil)
+	mockGW.EXPECT().PublishPendingProposals(gomock.Any(), gomock.Any()).Return(nil)
+
+	uc.HandleDriverLocationUpdate(context.Background(), update)
+}
+
+func TestRefreshDriverProposals_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// No repository or gateway calls are expected
+	uc := NewMatchUC(&models.Config{}, mocks.NewMockMatchRepo(ctrl), mocks.NewMockMatchGW(ctrl))
+
+	uc.refreshDriverProposals(context.Background(), uuid.New().String(), &models.Location{Latitude: -6.2, Longitude: 106.8})
+}
+
+func TestPendingProposalOptions(t *testing.T) {
+	uc := NewMatchUC(refreshConfig(), nil, nil)
+	passengerID := uuid.New()
+
+	t.Run("Answered matches are left out", func(t *testing.T) {
+		pending := driverAtKm(passengerID, 2, models.MatchStatusPending)
+		rejected := driverAtKm(passengerID, 1, models.MatchStatusRejected)
+		pastRide := driverAtKm(passengerID, 1, models.MatchStatusAccepted)
+
+		options := uc.pendingProposalOptions([]*models.Match{pending, rejected, pastRide})
+
+		require.Len(t, options, 1)
+		assert.Equal(t, pending.ID.String(), options[0].ID)
+	})
+
+	t.Run("Nothing once the passenger accepted a proposal", func(t *testing.T) {
+		pending := driverAtKm(passengerID, 2, models.MatchStatusPending)
+		chosen := driverAtKm(passengerID, 1, models.MatchStatusPassengerConfirmed)
+
+		assert.Empty(t, uc.pendingProposalOptions([]*models.Match{pending, chosen}))
+	})
+
+	t.Run("Configured average speed", func(t *testing.T) {
+		cfg := refreshConfig()
+		cfg.Match.AverageSpeedKmh = 50
+		fast := NewMatchUC(cfg, nil, nil)
+
+		options := fast.pendingProposalOptions([]*models.Match{driverAtKm(passengerID, 1, models.MatchStatusPending)})
+
+		require.Len(t, options, 1)
+		assert.Equal(t, 72, options[0].PickupETASeconds)
+	})
+}
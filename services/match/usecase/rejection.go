@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// GetRejectionReasonStats aggregates the reasons drivers gave for rejecting matches since the given time.
+// Every known reason is listed, most frequent first, so reasons nobody gave show up as zero.
+func (uc *MatchUC) GetRejectionReasonStats(ctx context.Context, since time.Time) (*models.MatchRejectionStats, error) {
+	counts, err := uc.matchRepo.CountRejectionReasons(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count rejection reasons: %w", err)
+	}
+
+	stats := &models.MatchRejectionStats{
+		Since:   since,
+		Reasons: make([]models.RejectionReasonCount, 0, len(models.MatchRejectionReasons)),
+	}
+	for _, reason := range models.MatchRejectionReasons {
+		stats.Reasons = append(stats.Reasons, models.RejectionReasonCount{Reason: reason, Count: counts[reason]})
+		stats.Total += counts[reason]
+	}
+	sort.SliceStable(stats.Reasons, func(i, j int) bool {
+		return stats.Reasons[i].Count > stats.Reasons[j].Count
+	})
+	return stats, nil
+}
@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectRejection sets up the status update, re-read and event of a rejected match
+func expectRejection(mockRepo *mocks.MockMatchRepo, mockGW *mocks.MockMatchGW, m *models.Match) {
+	mockRepo.EXPECT().GetMatch(gomock.Any(), m.ID.String()).Return(m, nil)
+	mockRepo.EXPECT().UpdateMatchStatus(gomock.Any(), m.ID.String(), models.MatchStatusRejected).Return(nil)
+	rejected := *m
+	rejected.Status = models.MatchStatusRejected
+	mockRepo.EXPECT().GetMatch(gomock.Any(), m.ID.String()).Return(&rejected, nil)
+	mockGW.EXPECT().PublishMatchRejected(gomock.Any(), gomock.Any()).Return(nil)
+}
+
+func TestConfirmMatchStatus_RejectStoresDriverReason(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	m := &models.Match{ID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New(), Status: models.MatchStatusPending}
+	expectRejection(mockRepo, mockGW, m)
+	mockRepo.EXPECT().
+		RecordRejectionReason(gomock.Any(), m.ID.String(), models.RejectionReasonWrongDirection).
+		Return(nil)
+
+	_, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     m.ID.String(),
+		UserID: m.DriverID.String(),
+		Status: string(models.MatchStatusRejected),
+		Reason: models.RejectionReasonWrongDirection,
+	})
+
+	require.NoError(t, err)
+}
+
+func TestConfirmMatchStatus_RejectIgnoresPassengerReason(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	// No RecordRejectionReason call is expected
+	m := &models.Match{ID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New(), Status: models.MatchStatusPending}
+	expectRejection(mockRepo, mockGW, m)
+
+	_, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     m.ID.String(),
+		UserID: m.PassengerID.String(),
+		Status: string(models.MatchStatusRejected),
+		Reason: models.RejectionReasonTooFar,
+	})
+
+	require.NoError(t, err)
+}
+
+func TestConfirmMatchStatus_RejectSucceedsWhenReasonNotStored(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	mockGW := mocks.NewMockMatchGW(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mockGW)
+
+	m := &models.Match{ID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New(), Status: models.MatchStatusPending}
+	expectRejection(mockRepo, mockGW, m)
+	mockRepo.EXPECT().
+		RecordRejectionReason(gomock.Any(), m.ID.String(), models.RejectionReasonTooFar).
+		Return(errors.New("db down"))
+
+	proposal, err := uc.ConfirmMatchStatus(context.Background(), &models.MatchConfirmRequest{
+		ID:     m.ID.String(),
+		UserID: m.DriverID.String(),
+		Status: string(models.MatchStatusRejected),
+		Reason: models.RejectionReasonTooFar,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.MatchStatusRejected, proposal.MatchStatus)
+}
+
+func TestGetRejectionReasonStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.EXPECT().
+		CountRejectionReasons(gomock.Any(), since).
+		Return(map[models.MatchRejectionReason]int{
+			models.RejectionReasonTooFar:                 3,
+			models.RejectionReasonUndesirableDestination: 5,
+		}, nil)
+
+	stats, err := uc.GetRejectionReasonStats(context.Background(), since)
+
+	require.NoError(t, err)
+	assert.Equal(t, since, stats.Since)
+	assert.Equal(t, 8, stats.Total)
+	// Every reason is listed, most frequent first
+	assert.Equal(t, []models.RejectionReasonCount{
+		{Reason: models.RejectionReasonUndesirableDestination, Count: 5},
+		{Reason: models.RejectionReasonTooFar, Count: 3},
+		{Reason: models.RejectionReasonWrongDirection, Count: 0},
+	}, stats.Reasons)
+}
+
+func TestGetRejectionReasonStats_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockMatchRepo(ctrl)
+	uc := NewMatchUC(&models.Config{}, mockRepo, mocks.NewMockMatchGW(ctrl))
+
+	mockRepo.EXPECT().CountRejectionReasons(gomock.Any(), gomock.Any()).Return(nil, errors.New("db down"))
+
+	stats, err := uc.GetRejectionReasonStats(context.Background(), time.Now())
+
+	assert.Error(t, err)
+	assert.Nil(t, stats)
+}
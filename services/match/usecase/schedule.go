@@ -0,0 +1,138 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/match"
+)
+
+const (
+	// defaultSchedulerInterval is used when no scheduler poll interval is configured
+	defaultSchedulerInterval = 15 * time.Second
+	// defaultSchedulerBatchSize is used when no scheduler batch size is configured
+	defaultSchedulerBatchSize = 100
+)
+
+// isScheduledForLater reports whether a finder event is a pre-booked ride that is not yet due
+func isScheduledForLater(event models.FinderEvent, now time.Time) bool {
+	return event.ScheduledAt != nil && event.ScheduledAt.After(now)
+}
+
+// scheduleFinderEvent holds a pre-booked ride until its scheduled time
+func (uc *MatchUC) scheduleFinderEvent(ctx context.Context, event models.FinderEvent) error {
+	if err := uc.matchRepo.ScheduleFinderEvent(ctx, event, *event.ScheduledAt); err != nil {
+		logger.Error("Failed to schedule finder event",
+			logger.String("passenger_id", event.UserID),
+			logger.ErrorField(err))
+		return err
+	}
+
+	logger.Info("Scheduled ride for later matching",
+		logger.String("passenger_id", event.UserID),
+		logger.String("scheduled_at", event.ScheduledAt.Format(time.RFC3339)))
+	return nil
+}
+
+// cancelScheduledFinderEvent drops a passenger's scheduled ride, logging rather than failing on errors
+func (uc *MatchUC) cancelScheduledFinderEvent(ctx context.Context, passengerID string) {
+	if err := uc.matchRepo.CancelScheduledFinderEvent(ctx, passengerID); err != nil {
+		logger.Error("Failed to cancel scheduled ride",
+			logger.String("passenger_id", passengerID),
+			logger.ErrorField(err))
+	}
+}
+
+// ReleaseDueScheduledFinders starts matching for up to limit scheduled rides that are due at now
+// and returns how many were released. Passengers whose ride cannot be matched are notified. Due
+// rides stay scheduled during maintenance and are released once it ends.
+func (uc *MatchUC) ReleaseDueScheduledFinders(ctx context.Context, now time.Time, limit int) (int, error) {
+	if limit <= 0 {
+		limit = defaultSchedulerBatchSize
+	}
+
+	if uc.IsMaintenanceMode(ctx) {
+		return 0, nil
+	}
+
+	events, err := uc.matchRepo.ClaimDueScheduledFinderEvents(ctx, now, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		// The stored location was current when the ride was booked; match against it now
+		event.ScheduledAt = nil
+		event.Location.Timestamp = now
+		event.Timestamp = now
+
+		if err := uc.handleFinderEvent(ctx, event); err != nil {
+			// Maintenance started after the rides were claimed; hold this one until it ends
+			if errors.Is(err, match.ErrMaintenanceMode) {
+				uc.requeueScheduledFinder(ctx, event, now)
+				continue
+			}
+			logger.Error("Failed to match scheduled ride",
+				logger.String("passenger_id", event.UserID),
+				logger.ErrorField(err))
+			uc.notifyScheduledRideUnmatched(ctx, event.UserID)
+		}
+	}
+
+	if len(events) > 0 {
+		logger.Info("Released scheduled rides", logger.Int("released", len(events)))
+	}
+	return len(events), nil
+}
+
+// requeueScheduledFinder puts a claimed scheduled ride back in the schedule, due straight away
+func (uc *MatchUC) requeueScheduledFinder(ctx context.Context, event models.FinderEvent, now time.Time) {
+	if err := uc.matchRepo.ScheduleFinderEvent(ctx, event, now); err != nil {
+		logger.Error("Failed to requeue scheduled ride during maintenance",
+			logger.String("passenger_id", event.UserID),
+			logger.ErrorField(err))
+		uc.notifyScheduledRideUnmatched(ctx, event.UserID)
+		return
+	}
+	logger.Info("Requeued scheduled ride until maintenance ends",
+		logger.String("passenger_id", event.UserID))
+}
+
+// notifyScheduledRideUnmatched tells a passenger their scheduled ride could not be matched
+func (uc *MatchUC) notifyScheduledRideUnmatched(ctx context.Context, passengerID string) {
+	event := models.NoDriversFoundEvent{
+		PassengerID:    passengerID,
+		SearchRadiusKm: uc.config().Match.SearchRadiusKm,
+		Timestamp:      uc.clock.Now(),
+	}
+	if err := uc.matchGW.PublishNoDriversFound(ctx, event); err != nil {
+		logger.Error("Failed to notify passenger of unmatched scheduled ride",
+			logger.String("passenger_id", passengerID),
+			logger.ErrorField(err))
+	}
+}
+
+// RunScheduler periodically releases due scheduled rides until ctx is cancelled
+func (uc *MatchUC) RunScheduler(ctx context.Context, interval time.Duration, batchSize int) {
+	if interval <= 0 {
+		interval = defaultSchedulerInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Ride scheduler stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.ReleaseDueScheduledFinders(ctx, uc.clock.Now(), batchSize); err != nil {
+				logger.Error("Ride scheduler run failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
@@ -11,6 +11,12 @@ import (
 //go:generate mockgen -destination=mocks/mock_gateway.go -package=mocks github.com/piresc/nebengjek/services/rides RideGW
 type RideGW interface {
 	PublishRidePickup(ctx context.Context, ride *models.Ride) error
+	PublishRidePickupETA(ctx context.Context, event *models.RidePickupETAEvent) error
 	PublishRideStarted(ctx context.Context, ride *models.Ride) error
 	PublishRideCompleted(ctx context.Context, ride models.RideComplete) error
+	PublishRideCancelled(ctx context.Context, event models.RideCancelled) error
+	PublishPaymentRequest(ctx context.Context, req *models.PaymentRequest) error
+	PublishRideBilling(ctx context.Context, update *models.RideBillingUpdate) error
+	PublishOutboxEvent(ctx context.Context, event *models.OutboxEvent) error
+	GetRideLocation(ctx context.Context, rideID string) (*models.Location, error)
 }
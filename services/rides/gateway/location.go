@@ -0,0 +1,39 @@
+package gateway
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	httpclient "github.com/piresc/nebengjek/internal/pkg/http"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// LocationClient reads positions the location service has on record
+type LocationClient struct {
+	client *httpclient.Client
+}
+
+// NewLocationClient creates a client for the location service, authenticated as the rides service
+func NewLocationClient(locationServiceURL, apiKey string) *LocationClient {
+	return &LocationClient{
+		client: httpclient.NewClient(httpclient.Config{
+			APIKey:  apiKey,
+			BaseURL: locationServiceURL,
+			Timeout: 5 * time.Second,
+		}),
+	}
+}
+
+// GetRideLocation retrieves the driver's latest position reported for a ride
+func (c *LocationClient) GetRideLocation(ctx context.Context, rideID string) (*models.Location, error) {
+	var location models.Location
+	if err := c.client.GetJSON(ctx, fmt.Sprintf("/internal/rides/%s/location", rideID), &location); err != nil {
+		logger.WarnCtx(ctx, "Failed to get ride location",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+		return nil, fmt.Errorf("failed to get ride location: %w", err)
+	}
+	return &location, nil
+}
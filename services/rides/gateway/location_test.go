@@ -0,0 +1,49 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocationClient_GetRideLocation(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/internal/rides/ride-123/location", r.URL.Path)
+		assert.Equal(t, "rides-key", r.Header.Get("X-API-Key"))
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"message": "Ride location retrieved",
+			"data":    map[string]interface{}{"latitude": -6.175392, "longitude": 106.827153},
+		})
+	}))
+	defer server.Close()
+
+	client := NewLocationClient(server.URL, "rides-key")
+	location, err := client.GetRideLocation(context.Background(), "ride-123")
+
+	require.NoError(t, err)
+	assert.Equal(t, -6.175392, location.Latitude)
+	assert.Equal(t, 106.827153, location.Longitude)
+}
+
+func TestLocationClient_GetRideLocation_NotFound(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewLocationClient(server.URL, "rides-key")
+	location, err := client.GetRideLocation(context.Background(), "ride-123")
+
+	assert.Error(t, err)
+	assert.Nil(t, location)
+	assert.Contains(t, err.Error(), "failed to get ride location")
+}
@@ -7,24 +7,32 @@ import (
 	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/constants"
+	pkgcontext "github.com/piresc/nebengjek/internal/pkg/context"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
 	"github.com/piresc/nebengjek/services/rides"
 )
 
-// RideGW handles NATS publishing for ride events
+// RideGW handles NATS publishing for ride events and reads live positions from the location service
 type RideGW struct {
-	natsClient *natspkg.Client
+	natsClient     *natspkg.Client
+	locationClient *LocationClient
 }
 
 // NewRideGW creates a new ride gateway
-func NewRideGW(client *natspkg.Client) rides.RideGW {
+func NewRideGW(client *natspkg.Client, locationClient *LocationClient) rides.RideGW {
 	return &RideGW{
-		natsClient: client,
+		natsClient:     client,
+		locationClient: locationClient,
 	}
 }
 
+// GetRideLocation forwards to the location service client
+func (g *RideGW) GetRideLocation(ctx context.Context, rideID string) (*models.Location, error) {
+	return g.locationClient.GetRideLocation(ctx, rideID)
+}
+
 // PublishRidePickup publishes a ride pickup event to JetStream with delivery guarantees
 func (g *RideGW) PublishRidePickup(ctx context.Context, ride *models.Ride) error {
 	logger.InfoCtx(ctx, "Preparing to publish ride pickup event to JetStream",
@@ -34,14 +42,16 @@ func (g *RideGW) PublishRidePickup(ctx context.Context, ride *models.Ride) error
 		logger.String("status", string(ride.Status)))
 
 	rideResponse := models.RideResp{
-		RideID:      ride.RideID.String(),
-		MatchID:     ride.MatchID.String(),
-		DriverID:    ride.DriverID.String(),
-		PassengerID: ride.PassengerID.String(),
-		Status:      string(ride.Status),
-		TotalCost:   ride.TotalCost,
-		CreatedAt:   ride.CreatedAt,
-		UpdatedAt:   ride.UpdatedAt,
+		RideID:           ride.RideID.String(),
+		MatchID:          ride.MatchID.String(),
+		DriverID:         ride.DriverID.String(),
+		PassengerID:      ride.PassengerID.String(),
+		Status:           string(ride.Status),
+		TotalCost:        ride.TotalCost,
+		PickupETASeconds: ride.PickupETASeconds,
+		CreatedAt:        ride.CreatedAt,
+		UpdatedAt:        ride.UpdatedAt,
+		CorrelationID:    pkgcontext.GetCorrelationID(ctx),
 	}
 
 	data, err := json.Marshal(rideResponse)
@@ -61,6 +71,7 @@ func (g *RideGW) PublishRidePickup(ctx context.Context, ride *models.Ride) error
 		Subject: constants.SubjectRidePickup,
 		Data:    data,
 		MsgID:   fmt.Sprintf("ride-pickup-%s-%d", ride.RideID.String(), time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 15 * time.Second, // Longer timeout for critical ride events
 	}
 
@@ -91,16 +102,43 @@ func (g *RideGW) PublishRidePickup(ctx context.Context, ride *models.Ride) error
 	return nil
 }
 
+// PublishRidePickupETA publishes a refreshed pickup ETA for the passenger
+func (g *RideGW) PublishRidePickupETA(ctx context.Context, event *models.RidePickupETAEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ride pickup ETA event: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectRidePickupETA,
+		Data:    data,
+		MsgID:   fmt.Sprintf("ride-pickup-eta-%s-%d", event.RideID, event.Timestamp.UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 5 * time.Second, // The next driver movement supersedes a lost estimate
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish ride pickup ETA event to JetStream",
+			logger.String("ride_id", event.RideID),
+			logger.Int("pickup_eta_seconds", event.PickupETASeconds),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish ride pickup ETA event: %w", err)
+	}
+
+	return nil
+}
+
 // PublishRideStarted publishes a ride started event to JetStream with delivery guarantees
 func (g *RideGW) PublishRideStarted(ctx context.Context, ride *models.Ride) error {
 	rideResponse := models.RideResp{
-		RideID:      ride.RideID.String(),
-		DriverID:    ride.DriverID.String(),
-		PassengerID: ride.PassengerID.String(),
-		Status:      string(ride.Status),
-		TotalCost:   ride.TotalCost,
-		CreatedAt:   ride.CreatedAt,
-		UpdatedAt:   ride.UpdatedAt,
+		RideID:        ride.RideID.String(),
+		DriverID:      ride.DriverID.String(),
+		PassengerID:   ride.PassengerID.String(),
+		Status:        string(ride.Status),
+		TotalCost:     ride.TotalCost,
+		CreatedAt:     ride.CreatedAt,
+		UpdatedAt:     ride.UpdatedAt,
+		CorrelationID: pkgcontext.GetCorrelationID(ctx),
 	}
 
 	data, err := json.Marshal(rideResponse)
@@ -113,6 +151,7 @@ func (g *RideGW) PublishRideStarted(ctx context.Context, ride *models.Ride) erro
 		Subject: constants.SubjectRideStarted,
 		Data:    data,
 		MsgID:   fmt.Sprintf("ride-started-%s-%d", ride.RideID.String(), time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 15 * time.Second, // Longer timeout for critical ride events
 	}
 
@@ -146,6 +185,7 @@ func (g *RideGW) PublishRideCompleted(ctx context.Context, rideComplete models.R
 		Subject: constants.SubjectRideCompleted,
 		Data:    data,
 		MsgID:   fmt.Sprintf("ride-completed-%s-%d", rideComplete.Ride.RideID.String(), time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 15 * time.Second, // Longer timeout for critical ride events
 	}
 
@@ -167,3 +207,116 @@ func (g *RideGW) PublishRideCompleted(ctx context.Context, rideComplete models.R
 
 	return nil
 }
+
+// PublishRideCancelled publishes a ride cancelled event to JetStream with delivery guarantees
+func (g *RideGW) PublishRideCancelled(ctx context.Context, event models.RideCancelled) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ride cancelled event: %w", err)
+	}
+
+	// Use JetStream publish with options for reliability
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectRideCancelled,
+		Data:    data,
+		MsgID:   fmt.Sprintf("ride-cancelled-%s", event.Ride.RideID.String()), // A ride is cancelled at most once
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 15 * time.Second, // Longer timeout for critical ride events
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish ride cancelled event to JetStream",
+			logger.String("ride_id", event.Ride.RideID.String()),
+			logger.String("cancelled_by", event.Cancellation.Role),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish ride cancelled event: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Successfully published ride cancelled event to JetStream",
+		logger.String("ride_id", event.Ride.RideID.String()),
+		logger.String("driver_id", event.Ride.DriverID.String()),
+		logger.String("passenger_id", event.Ride.PassengerID.String()),
+		logger.String("cancelled_by", event.Cancellation.Role),
+		logger.Int("fee", event.Cancellation.Fee))
+
+	return nil
+}
+
+// PublishPaymentRequest publishes a request for the passenger to pay a ride settled without them,
+// such as one that ran past the maximum ride duration
+func (g *RideGW) PublishPaymentRequest(ctx context.Context, req *models.PaymentRequest) error {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return fmt.Errorf("failed to marshal payment request: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectRideArrived,
+		Data:    data,
+		MsgID:   fmt.Sprintf("ride-arrived-%s", req.RideID), // A ride gets a single payment
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 15 * time.Second, // Longer timeout for critical ride events
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish payment request to JetStream",
+			logger.String("ride_id", req.RideID),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish payment request: %w", err)
+	}
+
+	return nil
+}
+
+// PublishRideBilling publishes a ride's new billing entry and running total for its participants
+func (g *RideGW) PublishRideBilling(ctx context.Context, update *models.RideBillingUpdate) error {
+	data, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ride billing update: %w", err)
+	}
+
+	opts := natspkg.PublishOptions{
+		Subject: constants.SubjectRideBilling,
+		Data:    data,
+		MsgID:   fmt.Sprintf("ride-billing-%s-%d", update.RideID, update.Timestamp.UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 5 * time.Second, // The next billing update carries a newer total
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish ride billing update to JetStream",
+			logger.String("ride_id", update.RideID),
+			logger.Int("total_cost", update.TotalCost),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish ride billing update: %w", err)
+	}
+
+	return nil
+}
+
+// PublishOutboxEvent publishes a stored outbox event to JetStream. The event ID is used as the
+// message ID so JetStream drops duplicates when a relay retries an already delivered event.
+func (g *RideGW) PublishOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	opts := natspkg.PublishOptions{
+		Subject: event.Subject,
+		Data:    event.Payload,
+		MsgID:   fmt.Sprintf("outbox-%s", event.EventID.String()),
+		Headers: natspkg.TraceHeaders(ctx),
+		Timeout: 15 * time.Second,
+	}
+
+	if err := g.natsClient.PublishWithOptions(opts); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish outbox event to JetStream",
+			logger.String("event_id", event.EventID.String()),
+			logger.String("subject", event.Subject),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish outbox event: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Successfully published outbox event to JetStream",
+		logger.String("event_id", event.EventID.String()),
+		logger.String("subject", event.Subject),
+		logger.String("msg_id", opts.MsgID))
+
+	return nil
+}
@@ -1,9 +1,11 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/converter"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
@@ -23,6 +25,15 @@ func NewRidesHandler(rideUC rides.RideUC) *RidesHandler {
 	}
 }
 
+// validationError reports a request that failed validation, naming the offending field when known
+func validationError(c echo.Context, err error) error {
+	var fieldErr *models.FieldError
+	if errors.As(err, &fieldErr) {
+		return utils.ValidationErrorResponse(c, map[string]string{fieldErr.Field: fieldErr.Message})
+	}
+	return utils.BadRequestResponse(c, err.Error())
+}
+
 // StartRide handles the start trip request for a ride
 func (h *RidesHandler) StartRide(c echo.Context) error {
 	// Get transaction from Echo context using centralized package
@@ -33,6 +44,9 @@ func (h *RidesHandler) StartRide(c echo.Context) error {
 	if rideID == "" {
 		return utils.BadRequestResponse(c, "Ride ID is required")
 	}
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
 
 	var req models.RideStartRequest
 	if err := c.Bind(&req); err != nil {
@@ -50,12 +64,11 @@ func (h *RidesHandler) StartRide(c echo.Context) error {
 		logger.Any("driver_location", req.DriverLocation),
 		logger.Any("passenger_location", req.PassengerLocation))
 
-	if req.DriverLocation == nil || req.PassengerLocation == nil {
-		logger.Error("Missing required location data",
+	if err := req.Validate(); err != nil {
+		logger.Error("Invalid start ride request",
 			logger.String("ride_id", rideID),
-			logger.Bool("has_driver_location", req.DriverLocation != nil),
-			logger.Bool("has_passenger_location", req.PassengerLocation != nil))
-		return utils.BadRequestResponse(c, "Driver and passenger locations are required")
+			logger.ErrorField(err))
+		return validationError(c, err)
 	}
 
 	resp, err := h.rideUC.StartRide(c.Request().Context(), req)
@@ -64,6 +77,15 @@ func (h *RidesHandler) StartRide(c echo.Context) error {
 			logger.String("ride_id", rideID),
 			logger.ErrorField(err))
 		nrpkg.NoticeTransactionError(txn, err)
+		if errors.Is(err, rides.ErrInvalidPickupCode) || errors.Is(err, rides.ErrPickupCodeExpired) {
+			return utils.BadRequestResponse(c, err.Error())
+		}
+		if errors.Is(err, rides.ErrPickupCodeLocked) {
+			return utils.ErrorResponseHandler(c, http.StatusTooManyRequests, err.Error())
+		}
+		if errors.Is(err, rides.ErrRideStatusChanged) {
+			return utils.ErrorResponseHandler(c, http.StatusConflict, err.Error())
+		}
 		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to start trip: "+err.Error())
 	}
 
@@ -74,6 +96,100 @@ func (h *RidesHandler) StartRide(c echo.Context) error {
 	return utils.SuccessResponse(c, http.StatusOK, "Trip started successfully", resp)
 }
 
+// GetPickupCode returns a ride's pickup code to its passenger, reissuing it when it expired or was invalidated
+func (h *RidesHandler) GetPickupCode(c echo.Context) error {
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Rides.GetPickupCode")
+
+	rideID := c.Param("rideID")
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
+
+	var req models.PickupCodeRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+	}
+	req.RideID = rideID
+	if _, err := converter.ParseUUID(req.PassengerID); err != nil {
+		return utils.ValidationErrorResponse(c, map[string]string{"passenger_id": "must be a valid UUID"})
+	}
+
+	resp, err := h.rideUC.GetPickupCode(c.Request().Context(), req)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		switch {
+		case errors.Is(err, rides.ErrRideNotFound):
+			return utils.NotFoundResponse(c, "Ride not found")
+		case errors.Is(err, rides.ErrNotRidePassenger):
+			return utils.ForbiddenResponse(c, "Only the ride's passenger can get its pickup code")
+		case errors.Is(err, rides.ErrRideNotAwaitingPickup), errors.Is(err, rides.ErrPickupCodesDisabled):
+			return utils.ErrorResponseHandler(c, http.StatusConflict, err.Error())
+		}
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to get pickup code")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Pickup code retrieved successfully", resp)
+}
+
+// DriverArrived handles the driver reporting arrival at the pickup point
+func (h *RidesHandler) DriverArrived(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Rides.DriverArrived")
+
+	rideID := c.Param("rideID")
+	if rideID == "" {
+		return utils.BadRequestResponse(c, "Ride ID is required")
+	}
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "driver_arrived")
+	nrpkg.AddTransactionAttribute(txn, "ride.id", rideID)
+
+	var req models.DriverArrivedRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+	}
+
+	req.RideID = rideID
+
+	fields := map[string]string{}
+	if req.DriverID == "" {
+		fields["driver_id"] = "is required"
+	} else if _, err := converter.ParseUUID(req.DriverID); err != nil {
+		fields["driver_id"] = "must be a valid UUID"
+	}
+	if req.DriverLocation == nil {
+		fields["driver_location"] = "is required"
+	}
+	if len(fields) > 0 {
+		return utils.ValidationErrorResponse(c, fields)
+	}
+
+	ride, err := h.rideUC.DriverArrived(c.Request().Context(), req)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		switch {
+		case errors.Is(err, rides.ErrRideNotFound):
+			return utils.NotFoundResponse(c, "Ride not found")
+		case errors.Is(err, rides.ErrNotRideDriver):
+			return utils.ForbiddenResponse(c, "Only the ride's driver can report arriving")
+		case errors.Is(err, rides.ErrRideNotAwaitingPickup):
+			return utils.ErrorResponseHandler(c, http.StatusConflict, err.Error())
+		case errors.Is(err, rides.ErrDriverTooFarFromPickup):
+			return utils.ErrorResponseHandler(c, http.StatusUnprocessableEntity, err.Error())
+		}
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to mark driver arrived")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver arrival recorded successfully", ride)
+}
+
 // RideArrived handles the ride arrival notification
 func (h *RidesHandler) RideArrived(c echo.Context) error {
 	// Get transaction from Echo context using centralized package
@@ -84,6 +200,9 @@ func (h *RidesHandler) RideArrived(c echo.Context) error {
 	if rideID == "" {
 		return utils.BadRequestResponse(c, "Ride ID is required")
 	}
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
 
 	nrpkg.AddTransactionAttribute(txn, "endpoint", "ride_arrived")
 	nrpkg.AddTransactionAttribute(txn, "ride.id", rideID)
@@ -94,15 +213,71 @@ func (h *RidesHandler) RideArrived(c echo.Context) error {
 		return utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
 	}
 
+	req.RideID = rideID
+
+	if err := req.Validate(); err != nil {
+		return validationError(c, err)
+	}
+
 	paymentReq, err := h.rideUC.RideArrived(c.Request().Context(), req)
 	if err != nil {
 		nrpkg.NoticeTransactionError(txn, err)
+		if errors.Is(err, rides.ErrPromoNotFound) || errors.Is(err, rides.ErrPromoExpired) || errors.Is(err, rides.ErrPromoExhausted) {
+			return utils.BadRequestResponse(c, err.Error())
+		}
 		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to process ride arrival: "+err.Error())
 	}
 
 	return utils.SuccessResponse(c, http.StatusOK, "Ride arrived successfully", paymentReq)
 }
 
+// AddSurcharge handles the driver adding an approved flat surcharge, such as a toll, to an ongoing ride
+func (h *RidesHandler) AddSurcharge(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Rides.AddSurcharge")
+
+	rideID := c.Param("rideID")
+	if rideID == "" {
+		return utils.BadRequestResponse(c, "Ride ID is required")
+	}
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "add_surcharge")
+	nrpkg.AddTransactionAttribute(txn, "ride.id", rideID)
+
+	var req models.SurchargeRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+	}
+
+	req.RideID = rideID
+
+	fields := map[string]string{}
+	if req.DriverID == "" {
+		fields["driver_id"] = "is required"
+	} else if _, err := converter.ParseUUID(req.DriverID); err != nil {
+		fields["driver_id"] = "must be a valid UUID"
+	}
+	if req.Type == "" {
+		fields["type"] = "is required"
+	}
+	if len(fields) > 0 {
+		return utils.ValidationErrorResponse(c, fields)
+	}
+
+	entry, err := h.rideUC.AddSurcharge(c.Request().Context(), req)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to add surcharge: "+err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Surcharge added successfully", entry)
+}
+
 // ProcessPayment handles the payment processing for a completed ride
 func (h *RidesHandler) ProcessPayment(c echo.Context) error {
 	// Get transaction from Echo context using centralized package
@@ -113,6 +288,9 @@ func (h *RidesHandler) ProcessPayment(c echo.Context) error {
 	if rideID == "" {
 		return utils.BadRequestResponse(c, "Ride ID is required")
 	}
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
 
 	nrpkg.AddTransactionAttribute(txn, "endpoint", "process_payment")
 	nrpkg.AddTransactionAttribute(txn, "ride.id", rideID)
@@ -133,3 +311,55 @@ func (h *RidesHandler) ProcessPayment(c echo.Context) error {
 
 	return utils.SuccessResponse(c, http.StatusOK, "Payment processed successfully", payment)
 }
+
+// CancelRide handles a driver or passenger cancelling a ride before the trip starts
+func (h *RidesHandler) CancelRide(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Rides.CancelRide")
+
+	rideID := c.Param("rideID")
+	if rideID == "" {
+		return utils.BadRequestResponse(c, "Ride ID is required")
+	}
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "endpoint", "cancel_ride")
+	nrpkg.AddTransactionAttribute(txn, "ride.id", rideID)
+
+	var req models.RideCancelRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request body: "+err.Error())
+	}
+
+	req.RideID = rideID
+
+	if req.UserID == "" {
+		return utils.BadRequestResponse(c, "User ID is required")
+	}
+	if _, err := converter.ParseUUID(req.UserID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+	if err := req.Validate(); err != nil {
+		return validationError(c, err)
+	}
+
+	cancellation, err := h.rideUC.CancelRide(c.Request().Context(), req)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		switch {
+		case errors.Is(err, rides.ErrRideNotFound):
+			return utils.NotFoundResponse(c, "Ride not found")
+		case errors.Is(err, rides.ErrNotRideParticipant):
+			return utils.ForbiddenResponse(c, "Only the ride's driver or passenger can cancel it")
+		case errors.Is(err, rides.ErrRideNotCancellable):
+			return utils.ErrorResponseHandler(c, http.StatusConflict, err.Error())
+		}
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to cancel ride: "+err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Ride cancelled successfully", cancellation)
+}
@@ -2,16 +2,21 @@ package http
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/rides"
 	"github.com/piresc/nebengjek/services/rides/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -116,6 +121,32 @@ func TestRidesHandler_StartRide_InvalidRequestBody(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, recorder.Code)
 }
 
+func TestRidesHandler_StartRide_MalformedRideID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	e := echo.New()
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte("{}")))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues("ride-123")
+
+	err := handler.StartRide(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Invalid ride ID", response["error"])
+}
+
 func TestRidesHandler_StartRide_MissingLocations(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -172,6 +203,81 @@ func TestRidesHandler_StartRide_MissingLocations(t *testing.T) {
 	}
 }
 
+func TestRidesHandler_StartRide_ValidationErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	rideID := uuid.New().String()
+	validLocation := map[string]float64{"latitude": -6.175392, "longitude": 106.827153}
+
+	testCases := []struct {
+		name           string
+		body           map[string]interface{}
+		expectedFields map[string]string
+	}{
+		{
+			name:           "Missing driver location",
+			body:           map[string]interface{}{"passenger_location": validLocation},
+			expectedFields: map[string]string{"driver_location": "is required"},
+		},
+		{
+			name:           "Missing passenger location",
+			body:           map[string]interface{}{"driver_location": validLocation},
+			expectedFields: map[string]string{"passenger_location": "is required"},
+		},
+		{
+			name: "Zero driver coordinates",
+			body: map[string]interface{}{
+				"driver_location":    map[string]float64{"latitude": 0, "longitude": 0},
+				"passenger_location": validLocation,
+			},
+			expectedFields: map[string]string{"driver_location": "is required"},
+		},
+		{
+			name: "Driver latitude out of range",
+			body: map[string]interface{}{
+				"driver_location":    map[string]float64{"latitude": -96.2, "longitude": 106.827153},
+				"passenger_location": validLocation,
+			},
+			expectedFields: map[string]string{"driver_location": "latitude must be between -90 and 90"},
+		},
+		{
+			name: "Passenger longitude out of range",
+			body: map[string]interface{}{
+				"driver_location":    validLocation,
+				"passenger_location": map[string]float64{"latitude": -6.175392, "longitude": 206.8},
+			},
+			expectedFields: map[string]string{"passenger_location": "longitude must be between -180 and 180"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			e := echo.New()
+			reqBody, _ := json.Marshal(tc.body)
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("rideID")
+			c.SetParamValues(rideID)
+
+			// The usecase is never reached
+			err := handler.StartRide(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+			var response utils.ErrorResponse
+			assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+			assert.Equal(t, tc.expectedFields, response.Fields)
+		})
+	}
+}
+
 func TestRidesHandler_StartRide_UseCaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -215,6 +321,148 @@ func TestRidesHandler_StartRide_UseCaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 }
 
+func TestRidesHandler_StartRide_PickupCodeRejected(t *testing.T) {
+	for _, rejection := range []error{rides.ErrInvalidPickupCode, rides.ErrPickupCodeExpired} {
+		t.Run(rejection.Error(), func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRideUC := mocks.NewMockRideUC(ctrl)
+			handler := NewRidesHandler(mockRideUC)
+
+			rideID := uuid.New().String()
+			mockRideUC.EXPECT().
+				StartRide(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, req models.RideStartRequest) (*models.Ride, error) {
+					assert.Equal(t, "1234", req.PickupCode)
+					return nil, fmt.Errorf("%w: %s", rejection, rideID)
+				})
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(map[string]interface{}{
+				"driver_location":    map[string]float64{"latitude": -6.175392, "longitude": 106.827153},
+				"passenger_location": map[string]float64{"latitude": -6.175400, "longitude": 106.827160},
+				"pickup_code":        "1234",
+			})
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("rideID")
+			c.SetParamValues(rideID)
+
+			err := handler.StartRide(c)
+
+			// A wrong or expired code is the driver's to correct, not a server failure
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusBadRequest, recorder.Code)
+			assert.Contains(t, recorder.Body.String(), rejection.Error())
+		})
+	}
+}
+
+func TestRidesHandler_StartRide_AlreadyStarted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	// Auto-start moved the ride on between the usecase reading and updating it
+	rideID := uuid.New().String()
+	mockRideUC.EXPECT().
+		StartRide(gomock.Any(), gomock.Any()).
+		Return(nil, fmt.Errorf("failed to update ride status to ongoing: %w", rides.ErrRideStatusChanged))
+
+	e := echo.New()
+	reqBody, _ := json.Marshal(map[string]interface{}{
+		"driver_location":    map[string]float64{"latitude": -6.175392, "longitude": 106.827153},
+		"passenger_location": map[string]float64{"latitude": -6.175400, "longitude": 106.827160},
+	})
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(rideID)
+
+	assert.NoError(t, handler.StartRide(c))
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestRidesHandler_GetPickupCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		ucErr      error
+		wantStatus int
+	}{
+		{name: "code returned", wantStatus: http.StatusOK},
+		{name: "unknown ride", ucErr: rides.ErrRideNotFound, wantStatus: http.StatusNotFound},
+		{name: "someone else's ride", ucErr: rides.ErrNotRidePassenger, wantStatus: http.StatusForbidden},
+		{name: "ride already started", ucErr: rides.ErrRideNotAwaitingPickup, wantStatus: http.StatusConflict},
+		{name: "pickup codes disabled", ucErr: rides.ErrPickupCodesDisabled, wantStatus: http.StatusConflict},
+		{name: "failure", ucErr: errors.New("redis down"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRideUC := mocks.NewMockRideUC(ctrl)
+			handler := NewRidesHandler(mockRideUC)
+
+			rideID := uuid.New().String()
+			passengerID := uuid.New().String()
+			mockRideUC.EXPECT().
+				GetPickupCode(gomock.Any(), models.PickupCodeRequest{RideID: rideID, PassengerID: passengerID}).
+				DoAndReturn(func(_ context.Context, req models.PickupCodeRequest) (*models.PickupCodeResponse, error) {
+					if tt.ucErr != nil {
+						return nil, tt.ucErr
+					}
+					return &models.PickupCodeResponse{RideID: req.RideID, PickupCode: "0427"}, nil
+				})
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(map[string]string{"passenger_id": passengerID})
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("rideID")
+			c.SetParamValues(rideID)
+
+			err := handler.GetPickupCode(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, recorder.Code)
+			if tt.ucErr == nil {
+				assert.Contains(t, recorder.Body.String(), `"pickup_code":"0427"`)
+			}
+		})
+	}
+}
+
+func TestRidesHandler_GetPickupCode_InvalidPassenger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	handler := NewRidesHandler(mocks.NewMockRideUC(ctrl))
+
+	e := echo.New()
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"passenger_id":"nope"}`))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(uuid.New().String())
+
+	err := handler.GetPickupCode(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
 func TestRidesHandler_RideArrived_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -253,6 +501,40 @@ func TestRidesHandler_RideArrived_Success(t *testing.T) {
 	assert.Equal(t, http.StatusOK, recorder.Code)
 }
 
+func TestRidesHandler_RideArrived_PromoRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	rideID := uuid.New().String()
+	req := models.RideArrivalReq{
+		RideID:           rideID,
+		AdjustmentFactor: 1.0,
+		PromoCode:        "LEBARAN",
+	}
+
+	mockRideUC.EXPECT().
+		RideArrived(gomock.Any(), req).
+		Return(nil, fmt.Errorf("%w: LEBARAN", rides.ErrPromoExpired)).
+		Times(1)
+
+	e := echo.New()
+	reqBody, _ := json.Marshal(req)
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(rideID)
+
+	err := handler.RideArrived(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
 func TestRidesHandler_RideArrived_MissingRideID(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -294,6 +576,68 @@ func TestRidesHandler_RideArrived_InvalidRequestBody(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, recorder.Code)
 }
 
+func TestRidesHandler_RideArrived_ValidationErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	rideID := uuid.New().String()
+
+	for _, factor := range []float64{-0.1, 1.5} {
+		e := echo.New()
+		reqBody, _ := json.Marshal(map[string]float64{"adjustment_factor": factor})
+		request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(request, recorder)
+		c.SetParamNames("rideID")
+		c.SetParamValues(rideID)
+
+		// The usecase is never reached
+		err := handler.RideArrived(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+
+		var response utils.ErrorResponse
+		assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+		assert.Equal(t, map[string]string{"adjustment_factor": "must be between 0 and 1"}, response.Fields)
+	}
+}
+
+func TestRidesHandler_RideArrived_AdjustmentFactorBounds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	rideID := uuid.New().String()
+
+	for _, factor := range []float64{0, 1} {
+		// The ride ID comes from the path, so the body does not need to repeat it
+		mockRideUC.EXPECT().
+			RideArrived(gomock.Any(), models.RideArrivalReq{RideID: rideID, AdjustmentFactor: factor}).
+			Return(&models.PaymentRequest{RideID: rideID}, nil)
+
+		e := echo.New()
+		reqBody, _ := json.Marshal(map[string]float64{"adjustment_factor": factor})
+		request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+		request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+		recorder := httptest.NewRecorder()
+		c := e.NewContext(request, recorder)
+		c.SetParamNames("rideID")
+		c.SetParamValues(rideID)
+
+		err := handler.RideArrived(c)
+
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	}
+}
+
 func TestRidesHandler_RideArrived_UseCaseError(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -327,6 +671,189 @@ func TestRidesHandler_RideArrived_UseCaseError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 }
 
+func TestRidesHandler_AddSurcharge(t *testing.T) {
+	driverID := uuid.New().String()
+
+	testCases := []struct {
+		name           string
+		body           map[string]string
+		ucErr          error
+		expectCall     bool
+		expectedStatus int
+		expectedFields map[string]string
+	}{
+		{
+			name:           "Success",
+			body:           map[string]string{"driver_id": driverID, "type": "toll"},
+			expectCall:     true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing driver and type",
+			body:           map[string]string{},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: map[string]string{"driver_id": "is required", "type": "is required"},
+		},
+		{
+			name:           "Malformed driver ID",
+			body:           map[string]string{"driver_id": "driver-1", "type": "toll"},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: map[string]string{"driver_id": "must be a valid UUID"},
+		},
+		{
+			name:           "Usecase rejects surcharge",
+			body:           map[string]string{"driver_id": driverID, "type": "parking"},
+			ucErr:          errors.New("unknown surcharge type: parking"),
+			expectCall:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRideUC := mocks.NewMockRideUC(ctrl)
+			handler := NewRidesHandler(mockRideUC)
+
+			rideID := uuid.New().String()
+			if tc.expectCall {
+				mockRideUC.EXPECT().
+					AddSurcharge(gomock.Any(), models.SurchargeRequest{RideID: rideID, DriverID: tc.body["driver_id"], Type: tc.body["type"]}).
+					Return(&models.BillingLedger{Category: models.BillingCategorySurcharge, Description: tc.body["type"], Cost: 10000}, tc.ucErr)
+			}
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(tc.body)
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("rideID")
+			c.SetParamValues(rideID)
+
+			err := handler.AddSurcharge(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedFields != nil {
+				var response utils.ErrorResponse
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedFields, response.Fields)
+			}
+		})
+	}
+}
+
+func TestRidesHandler_DriverArrived(t *testing.T) {
+	location := map[string]float64{"latitude": -6.175392, "longitude": 106.827153}
+	driverID := uuid.New().String()
+
+	testCases := []struct {
+		name           string
+		body           map[string]interface{}
+		ucErr          error
+		expectCall     bool
+		expectedStatus int
+		expectedFields map[string]string
+	}{
+		{
+			name:           "Success",
+			body:           map[string]interface{}{"driver_id": driverID, "driver_location": location},
+			expectCall:     true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Missing driver and location",
+			body:           map[string]interface{}{},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: map[string]string{"driver_id": "is required", "driver_location": "is required"},
+		},
+		{
+			name:           "Malformed driver ID",
+			body:           map[string]interface{}{"driver_id": "driver-1", "driver_location": location},
+			expectedStatus: http.StatusBadRequest,
+			expectedFields: map[string]string{"driver_id": "must be a valid UUID"},
+		},
+		{
+			name:           "Ride not found",
+			body:           map[string]interface{}{"driver_id": driverID, "driver_location": location},
+			ucErr:          fmt.Errorf("failed to get ride: %w", rides.ErrRideNotFound),
+			expectCall:     true,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Not the ride's driver",
+			body:           map[string]interface{}{"driver_id": driverID, "driver_location": location},
+			ucErr:          fmt.Errorf("%w: user %s", rides.ErrNotRideDriver, driverID),
+			expectCall:     true,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Ride already started",
+			body:           map[string]interface{}{"driver_id": driverID, "driver_location": location},
+			ucErr:          fmt.Errorf("%w: status ONGOING", rides.ErrRideNotAwaitingPickup),
+			expectCall:     true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Driver away from pickup",
+			body:           map[string]interface{}{"driver_id": driverID, "driver_location": location},
+			ucErr:          fmt.Errorf("%w (250.00 meters)", rides.ErrDriverTooFarFromPickup),
+			expectCall:     true,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Repository failure",
+			body:           map[string]interface{}{"driver_id": driverID, "driver_location": location},
+			ucErr:          errors.New("connection refused"),
+			expectCall:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRideUC := mocks.NewMockRideUC(ctrl)
+			handler := NewRidesHandler(mockRideUC)
+
+			rideID := uuid.New().String()
+			if tc.expectCall {
+				mockRideUC.EXPECT().
+					DriverArrived(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, req models.DriverArrivedRequest) (*models.Ride, error) {
+						assert.Equal(t, rideID, req.RideID)
+						assert.Equal(t, driverID, req.DriverID)
+						return &models.Ride{Status: models.RideStatusDriverArrived}, tc.ucErr
+					})
+			}
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(tc.body)
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("rideID")
+			c.SetParamValues(rideID)
+
+			err := handler.DriverArrived(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedStatus, recorder.Code)
+			if tc.expectedFields != nil {
+				var response utils.ErrorResponse
+				assert.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+				assert.Equal(t, tc.expectedFields, response.Fields)
+			}
+		})
+	}
+}
+
 func TestRidesHandler_ProcessPayment_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -462,4 +989,175 @@ func TestRidesHandler_ProcessPayment_UseCaseError(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
-}
\ No newline at end of file
+}
+func TestRidesHandler_CancelRide_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	rideID := uuid.New().String()
+	userID := uuid.New().String()
+	req := models.RideCancelRequest{
+		RideID: rideID,
+		UserID: userID,
+		Reason: "driver not moving",
+	}
+
+	mockRideUC.EXPECT().
+		CancelRide(gomock.Any(), req).
+		Return(&models.RideCancellation{Role: models.CancelledByPassenger, Fee: 2000}, nil).
+		Times(1)
+
+	e := echo.New()
+	reqBody, _ := json.Marshal(models.RideCancelRequest{UserID: userID, Reason: "driver not moving"})
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(rideID)
+
+	err := handler.CancelRide(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRidesHandler_CancelRide_MissingUserID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	e := echo.New()
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer([]byte(`{}`)))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(uuid.New().String())
+
+	err := handler.CancelRide(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestRidesHandler_CancelRide_ReasonTooLong(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	e := echo.New()
+	reqBody, _ := json.Marshal(models.RideCancelRequest{UserID: uuid.New().String(), Reason: strings.Repeat("a", 256)})
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(uuid.New().String())
+
+	err := handler.CancelRide(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Contains(t, recorder.Body.String(), "must be at most 255 characters")
+}
+
+func TestRidesHandler_CancelRide_SanitizesReason(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	rideID := uuid.New().String()
+	userID := uuid.New().String()
+	mockRideUC.EXPECT().
+		CancelRide(gomock.Any(), models.RideCancelRequest{RideID: rideID, UserID: userID, Reason: "driver not moving"}).
+		Return(&models.RideCancellation{Role: models.CancelledByPassenger}, nil)
+
+	e := echo.New()
+	reqBody, _ := json.Marshal(models.RideCancelRequest{UserID: userID, Reason: "driver\x00 not\nmoving "})
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(rideID)
+
+	err := handler.CancelRide(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestRidesHandler_CancelRide_UseCaseError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRideUC := mocks.NewMockRideUC(ctrl)
+	handler := NewRidesHandler(mockRideUC)
+
+	mockRideUC.EXPECT().
+		CancelRide(gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("database unavailable")).
+		Times(1)
+
+	e := echo.New()
+	reqBody, _ := json.Marshal(models.RideCancelRequest{UserID: uuid.New().String()})
+	request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+	request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	recorder := httptest.NewRecorder()
+	c := e.NewContext(request, recorder)
+	c.SetParamNames("rideID")
+	c.SetParamValues(uuid.New().String())
+
+	err := handler.CancelRide(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
+
+func TestRidesHandler_CancelRide_MapsErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		expectedCode int
+	}{
+		{name: "unknown ride", err: fmt.Errorf("failed to get ride: %w", rides.ErrRideNotFound), expectedCode: http.StatusNotFound},
+		{name: "user not on the ride", err: rides.ErrNotRideParticipant, expectedCode: http.StatusForbidden},
+		{name: "ride already started", err: fmt.Errorf("%w: cannot cancel ride with status: ONGOING", rides.ErrRideNotCancellable), expectedCode: http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRideUC := mocks.NewMockRideUC(ctrl)
+			handler := NewRidesHandler(mockRideUC)
+
+			mockRideUC.EXPECT().CancelRide(gomock.Any(), gomock.Any()).Return(nil, tt.err)
+
+			e := echo.New()
+			reqBody, _ := json.Marshal(models.RideCancelRequest{UserID: uuid.New().String()})
+			request := httptest.NewRequest(http.MethodPost, "/", bytes.NewBuffer(reqBody))
+			request.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			recorder := httptest.NewRecorder()
+			c := e.NewContext(request, recorder)
+			c.SetParamNames("rideID")
+			c.SetParamValues(uuid.New().String())
+
+			err := handler.CancelRide(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedCode, recorder.Code)
+		})
+	}
+}
@@ -3,13 +3,14 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"math"
 
 	"github.com/google/uuid"
 	"github.com/nats-io/nats.go"
 	"github.com/nats-io/nats.go/jetstream"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	pkgcontext "github.com/piresc/nebengjek/internal/pkg/context"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
@@ -100,8 +101,8 @@ func (h *RidesHandler) InitNATSConsumers() error {
 
 // handleMatchAcceptedJS processes match accepted events from JetStream
 func (h *RidesHandler) handleMatchAcceptedJS(msg jetstream.Msg) error {
-	// Create background transaction for NATS message processing
-	txn := h.nrApp.StartTransaction("NATS.Rides.HandleMatchAccepted")
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Rides.HandleMatchAccepted", msg)
 	defer txn.End()
 
 	// Add message attributes
@@ -126,8 +127,8 @@ func (h *RidesHandler) handleMatchAcceptedJS(msg jetstream.Msg) error {
 
 // handleLocationAggregateJS processes location aggregate events from JetStream
 func (h *RidesHandler) handleLocationAggregateJS(msg jetstream.Msg) error {
-	// Create background transaction for NATS message processing
-	txn := h.nrApp.StartTransaction("NATS.Rides.HandleLocationAggregate")
+	// Start transaction for NATS message processing, continuing the publisher's trace
+	txn := natspkg.StartConsumerTransaction(h.nrApp, "NATS.Rides.HandleLocationAggregate", msg)
 	defer txn.End()
 
 	// Add message attributes
@@ -170,10 +171,13 @@ func (h *RidesHandler) handleMatchAccepted(ctx context.Context, msg []byte) erro
 		nrpkg.AddTransactionAttribute(txn, "passenger.id", matchProposal.PassengerID)
 	}
 
+	ctx = pkgcontext.WithCorrelationID(ctx, matchProposal.CorrelationID)
+
 	logger.InfoCtx(ctx, "Successfully parsed match accepted event, creating ride",
 		logger.String("match_id", matchProposal.ID),
 		logger.String("driver_id", matchProposal.DriverID),
-		logger.String("passenger_id", matchProposal.PassengerID))
+		logger.String("passenger_id", matchProposal.PassengerID),
+		logger.String("correlation_id", matchProposal.CorrelationID))
 
 	// Create a ride from the match proposal
 	if err := h.ridesUC.CreateRide(ctx, matchProposal); err != nil {
@@ -208,6 +212,21 @@ func (h *RidesHandler) handleLocationAggregate(ctx context.Context, msg []byte)
 		logger.String("ride_id", update.RideID),
 		logger.Float64("distance_km", update.Distance))
 
+	// Driver movement while heading to the pickup point refreshes the passenger's ETA
+	driverLocation := models.Location{Latitude: update.Latitude, Longitude: update.Longitude}
+	if err := h.ridesUC.RefreshPickupETA(ctx, update.RideID, driverLocation); err != nil {
+		logger.WarnCtx(ctx, "Failed to refresh pickup ETA",
+			logger.String("ride_id", update.RideID),
+			logger.ErrorField(err))
+	}
+
+	// A driver staying at the pickup point may start the ride without an explicit request
+	if err := h.ridesUC.AutoStartRide(ctx, update.RideID, driverLocation); err != nil {
+		logger.WarnCtx(ctx, "Failed to auto-start ride",
+			logger.String("ride_id", update.RideID),
+			logger.ErrorField(err))
+	}
+
 	// Only process if distance is >= minimum configured distance
 	if update.Distance >= h.cfg.Rides.MinDistanceKm {
 		// Convert ride ID to UUID
@@ -219,23 +238,25 @@ func (h *RidesHandler) handleLocationAggregate(ctx context.Context, msg []byte)
 			return fmt.Errorf("invalid ride ID: %w", err)
 		}
 
-		cost := int(math.Round(update.Distance * h.cfg.Pricing.RatePerKm))
-
 		// Add billing attributes to transaction
 		if txn := nrpkg.FromContext(ctx); txn != nil {
-			nrpkg.AddTransactionAttribute(txn, "billing.cost", cost)
 			nrpkg.AddTransactionAttribute(txn, "billing.processed", true)
 		}
 
-		// Create billing entry
+		// Create billing entry; the usecase prices it at the ride's regional rate
 		entry := &models.BillingLedger{
 			RideID:   rideUUID,
 			Distance: update.Distance,
-			Cost:     cost,
 		}
 
 		// Store billing entry and update total cost
 		if err := h.ridesUC.ProcessBillingUpdate(ctx, update.RideID, entry); err != nil {
+			if errors.Is(err, rides.ErrLedgerFinalized) {
+				// The fare is already charged, so redelivery would be refused again
+				logger.WarnCtx(ctx, "Dropping location aggregate for finalized ledger",
+					logger.String("ride_id", update.RideID))
+				return nil
+			}
 			logger.ErrorCtx(ctx, "Failed to process billing update",
 				logger.String("ride_id", update.RideID),
 				logger.ErrorField(err))
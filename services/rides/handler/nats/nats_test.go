@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
@@ -11,6 +12,7 @@ import (
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
+	"github.com/piresc/nebengjek/services/rides"
 	"github.com/piresc/nebengjek/services/rides/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -164,13 +166,14 @@ func TestRidesHandler_handleLocationAggregate_Success(t *testing.T) {
 		Distance: 2.5, // Above minimum distance
 	}
 
-	expectedCost := int(2.5 * cfg.Pricing.RatePerKm) // Use configured rate instead of hardcoded
+	// The usecase prices the entry at the ride's regional rate
 	expectedEntry := &models.BillingLedger{
 		RideID:   rideID,
 		Distance: 2.5,
-		Cost:     expectedCost,
 	}
 
+	mockRidesUC.EXPECT().RefreshPickupETA(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
+	mockRidesUC.EXPECT().AutoStartRide(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
 	mockRidesUC.EXPECT().ProcessBillingUpdate(gomock.Any(), rideID.String(), expectedEntry).Return(nil)
 
 	// Act
@@ -183,6 +186,42 @@ func TestRidesHandler_handleLocationAggregate_Success(t *testing.T) {
 	require.NoError(t, err)
 }
 
+// TestRidesHandler_handleLocationAggregate_FinalizedLedgerAcked tests that late aggregates for a charged ride are not retried
+func TestRidesHandler_handleLocationAggregate_FinalizedLedgerAcked(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRidesUC := mocks.NewMockRideUC(ctrl)
+	cfg := &models.Config{
+		Rides: models.RidesConfig{
+			MinDistanceKm: 1.0,
+		},
+	}
+
+	handler := NewRidesHandler(mockRidesUC, nil, cfg, &newrelic.Application{})
+
+	rideID := uuid.New()
+	locationAggregate := models.LocationAggregate{
+		RideID:   rideID.String(),
+		Distance: 2.5,
+	}
+
+	mockRidesUC.EXPECT().RefreshPickupETA(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
+	mockRidesUC.EXPECT().AutoStartRide(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
+	mockRidesUC.EXPECT().ProcessBillingUpdate(gomock.Any(), rideID.String(), gomock.Any()).
+		Return(fmt.Errorf("%w: %s", rides.ErrLedgerFinalized, rideID))
+
+	// Act
+	locationData, err := json.Marshal(locationAggregate)
+	require.NoError(t, err)
+
+	err = handler.handleLocationAggregate(context.Background(), locationData)
+
+	// Assert
+	require.NoError(t, err)
+}
+
 // TestRidesHandler_handleLocationAggregate_BelowMinDistance tests skipping processing when distance is below minimum
 func TestRidesHandler_handleLocationAggregate_BelowMinDistance(t *testing.T) {
 	// Arrange
@@ -205,6 +244,9 @@ func TestRidesHandler_handleLocationAggregate_BelowMinDistance(t *testing.T) {
 		Distance: 1.5, // Below minimum distance
 	}
 
+	// Movement below the billing minimum still refreshes the pickup ETA
+	mockRidesUC.EXPECT().RefreshPickupETA(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
+	mockRidesUC.EXPECT().AutoStartRide(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
 	// No expectation on ProcessBillingUpdate since it should be skipped
 
 	// Act
@@ -262,6 +304,8 @@ func TestRidesHandler_handleLocationAggregate_InvalidRideID(t *testing.T) {
 		RideID:   "invalid-uuid",
 		Distance: 2.5,
 	}
+	mockRidesUC.EXPECT().RefreshPickupETA(gomock.Any(), "invalid-uuid", gomock.Any()).Return(errors.New("invalid ride ID format"))
+	mockRidesUC.EXPECT().AutoStartRide(gomock.Any(), "invalid-uuid", gomock.Any()).Return(errors.New("invalid ride ID format"))
 
 	// Act
 	locationData, err := json.Marshal(locationAggregate)
@@ -299,14 +343,15 @@ func TestRidesHandler_handleLocationAggregate_ProcessBillingError(t *testing.T)
 		Distance: 2.5,
 	}
 
-	expectedCost := int(2.5 * cfg.Pricing.RatePerKm) // Use configured rate instead of hardcoded
+	// The usecase prices the entry at the ride's regional rate
 	expectedEntry := &models.BillingLedger{
 		RideID:   rideID,
 		Distance: 2.5,
-		Cost:     expectedCost,
 	}
 
 	expectedError := errors.New("billing update failed")
+	mockRidesUC.EXPECT().RefreshPickupETA(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
+	mockRidesUC.EXPECT().AutoStartRide(gomock.Any(), rideID.String(), gomock.Any()).Return(nil)
 	mockRidesUC.EXPECT().ProcessBillingUpdate(gomock.Any(), rideID.String(), expectedEntry).Return(expectedError)
 
 	// Act
@@ -319,3 +364,48 @@ func TestRidesHandler_handleLocationAggregate_ProcessBillingError(t *testing.T)
 	require.Error(t, err)
 	assert.Equal(t, expectedError, err)
 }
+
+// TestRidesHandler_handleLocationAggregate_PickupETA tests the driver's position is passed on to refresh the pickup ETA
+func TestRidesHandler_handleLocationAggregate_PickupETA(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRidesUC := mocks.NewMockRideUC(ctrl)
+	cfg := &models.Config{
+		Rides: models.RidesConfig{
+			MinDistanceKm: 1.0,
+		},
+	}
+
+	mockNRApp := &newrelic.Application{}
+	handler := NewRidesHandler(mockRidesUC, nil, cfg, mockNRApp)
+
+	rideID := uuid.New()
+	locationAggregate := models.LocationAggregate{
+		RideID:    rideID.String(),
+		Distance:  0.05,
+		Latitude:  -6.175392,
+		Longitude: 106.827153,
+	}
+
+	// A failed refresh is logged and must not fail the aggregate
+	mockRidesUC.EXPECT().RefreshPickupETA(gomock.Any(), rideID.String(), models.Location{
+		Latitude:  -6.175392,
+		Longitude: 106.827153,
+	}).Return(errors.New("publish failed"))
+	// The same position is checked for an automatic start, whose failure is logged too
+	mockRidesUC.EXPECT().AutoStartRide(gomock.Any(), rideID.String(), models.Location{
+		Latitude:  -6.175392,
+		Longitude: 106.827153,
+	}).Return(errors.New("publish failed"))
+
+	// Act
+	locationData, err := json.Marshal(locationAggregate)
+	require.NoError(t, err)
+
+	err = handler.handleLocationAggregate(context.Background(), locationData)
+
+	// Assert
+	require.NoError(t, err)
+}
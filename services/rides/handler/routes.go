@@ -3,6 +3,7 @@ package handler
 import (
 	"github.com/labstack/echo/v4"
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/piresc/nebengjek/internal/pkg/featureflag"
 	"github.com/piresc/nebengjek/internal/pkg/middleware"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
@@ -15,6 +16,7 @@ import (
 type Handler struct {
 	ridesHTTP *httpHandler.RidesHandler
 	ridesNATS *natsHandler.RidesHandler
+	flagsHTTP *featureflag.Handler
 	cfg       *models.Config
 }
 
@@ -24,10 +26,12 @@ func NewHandler(
 	natsClient *natspkg.Client,
 	cfg *models.Config,
 	nrApp *newrelic.Application,
+	flags *featureflag.Flags,
 ) *Handler {
 	return &Handler{
 		ridesHTTP: httpHandler.NewRidesHandler(ridesUC),
 		ridesNATS: natsHandler.NewRidesHandler(ridesUC, natsClient, cfg, nrApp),
+		flagsHTTP: featureflag.NewHandler(flags),
 		cfg:       cfg,
 	}
 }
@@ -39,9 +43,18 @@ func (h *Handler) RegisterRoutes(e *echo.Echo, Middleware *middleware.Middleware
 
 	// Internal rides endpoints
 	internalRidesGroup := internal.Group("/rides")
+	internalRidesGroup.POST("/:rideID/driver-arrived", h.ridesHTTP.DriverArrived)
 	internalRidesGroup.POST("/:rideID/start", h.ridesHTTP.StartRide)
+	internalRidesGroup.POST("/:rideID/pickup-code", h.ridesHTTP.GetPickupCode)
+	internalRidesGroup.POST("/:rideID/surcharges", h.ridesHTTP.AddSurcharge)
 	internalRidesGroup.POST("/:rideID/arrive", h.ridesHTTP.RideArrived)
 	internalRidesGroup.POST("/:rideID/payment", h.ridesHTTP.ProcessPayment)
+	internalRidesGroup.POST("/:rideID/cancel", h.ridesHTTP.CancelRide)
+
+	// Admin routes for rolling out new behaviour gradually (admin API key required)
+	admin := e.Group("/admin", Middleware.APIKeyHandler("admin"))
+	admin.GET("/feature-flags/:flag", h.flagsHTTP.Get)
+	admin.PUT("/feature-flags/:flag", h.flagsHTTP.Set)
 }
 
 // InitNATSConsumers initializes all NATS consumers
@@ -35,6 +35,77 @@ func (m *MockRideGW) EXPECT() *MockRideGWMockRecorder {
 	return m.recorder
 }
 
+// GetRideLocation mocks base method.
+func (m *MockRideGW) GetRideLocation(arg0 context.Context, arg1 string) (*models.Location, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRideLocation", arg0, arg1)
+	ret0, _ := ret[0].(*models.Location)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRideLocation indicates an expected call of GetRideLocation.
+func (mr *MockRideGWMockRecorder) GetRideLocation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRideLocation", reflect.TypeOf((*MockRideGW)(nil).GetRideLocation), arg0, arg1)
+}
+
+// PublishOutboxEvent mocks base method.
+func (m *MockRideGW) PublishOutboxEvent(arg0 context.Context, arg1 *models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishOutboxEvent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishOutboxEvent indicates an expected call of PublishOutboxEvent.
+func (mr *MockRideGWMockRecorder) PublishOutboxEvent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishOutboxEvent", reflect.TypeOf((*MockRideGW)(nil).PublishOutboxEvent), arg0, arg1)
+}
+
+// PublishPaymentRequest mocks base method.
+func (m *MockRideGW) PublishPaymentRequest(arg0 context.Context, arg1 *models.PaymentRequest) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishPaymentRequest", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishPaymentRequest indicates an expected call of PublishPaymentRequest.
+func (mr *MockRideGWMockRecorder) PublishPaymentRequest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishPaymentRequest", reflect.TypeOf((*MockRideGW)(nil).PublishPaymentRequest), arg0, arg1)
+}
+
+// PublishRideBilling mocks base method.
+func (m *MockRideGW) PublishRideBilling(arg0 context.Context, arg1 *models.RideBillingUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishRideBilling", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishRideBilling indicates an expected call of PublishRideBilling.
+func (mr *MockRideGWMockRecorder) PublishRideBilling(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishRideBilling", reflect.TypeOf((*MockRideGW)(nil).PublishRideBilling), arg0, arg1)
+}
+
+// PublishRideCancelled mocks base method.
+func (m *MockRideGW) PublishRideCancelled(arg0 context.Context, arg1 models.RideCancelled) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishRideCancelled", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishRideCancelled indicates an expected call of PublishRideCancelled.
+func (mr *MockRideGWMockRecorder) PublishRideCancelled(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishRideCancelled", reflect.TypeOf((*MockRideGW)(nil).PublishRideCancelled), arg0, arg1)
+}
+
 // PublishRideCompleted mocks base method.
 func (m *MockRideGW) PublishRideCompleted(arg0 context.Context, arg1 models.RideComplete) error {
 	m.ctrl.T.Helper()
@@ -63,6 +134,20 @@ func (mr *MockRideGWMockRecorder) PublishRidePickup(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishRidePickup", reflect.TypeOf((*MockRideGW)(nil).PublishRidePickup), arg0, arg1)
 }
 
+// PublishRidePickupETA mocks base method.
+func (m *MockRideGW) PublishRidePickupETA(arg0 context.Context, arg1 *models.RidePickupETAEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishRidePickupETA", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishRidePickupETA indicates an expected call of PublishRidePickupETA.
+func (mr *MockRideGWMockRecorder) PublishRidePickupETA(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishRidePickupETA", reflect.TypeOf((*MockRideGW)(nil).PublishRidePickupETA), arg0, arg1)
+}
+
 // PublishRideStarted mocks base method.
 func (m *MockRideGW) PublishRideStarted(arg0 context.Context, arg1 *models.Ride) error {
 	m.ctrl.T.Helper()
@@ -7,8 +7,10 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
 	models "github.com/piresc/nebengjek/internal/pkg/models"
 )
 
@@ -49,6 +51,35 @@ func (mr *MockRideRepoMockRecorder) AddBillingEntry(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddBillingEntry", reflect.TypeOf((*MockRideRepo)(nil).AddBillingEntry), arg0, arg1)
 }
 
+// CancelRide mocks base method.
+func (m *MockRideRepo) CancelRide(arg0 context.Context, arg1 *models.RideCancellation, arg2 *models.OutboxEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelRide", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CancelRide indicates an expected call of CancelRide.
+func (mr *MockRideRepoMockRecorder) CancelRide(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelRide", reflect.TypeOf((*MockRideRepo)(nil).CancelRide), arg0, arg1, arg2)
+}
+
+// ClaimPendingOutboxEvents mocks base method.
+func (m *MockRideRepo) ClaimPendingOutboxEvents(arg0 context.Context, arg1 int) ([]*models.OutboxEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimPendingOutboxEvents", arg0, arg1)
+	ret0, _ := ret[0].([]*models.OutboxEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimPendingOutboxEvents indicates an expected call of ClaimPendingOutboxEvents.
+func (mr *MockRideRepoMockRecorder) ClaimPendingOutboxEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimPendingOutboxEvents", reflect.TypeOf((*MockRideRepo)(nil).ClaimPendingOutboxEvents), arg0, arg1)
+}
+
 // CompleteRide mocks base method.
 func (m *MockRideRepo) CompleteRide(arg0 context.Context, arg1 *models.Ride) error {
 	m.ctrl.T.Helper()
@@ -64,17 +95,17 @@ func (mr *MockRideRepoMockRecorder) CompleteRide(arg0, arg1 interface{}) *gomock
 }
 
 // CreatePayment mocks base method.
-func (m *MockRideRepo) CreatePayment(arg0 context.Context, arg1 *models.Payment) error {
+func (m *MockRideRepo) CreatePayment(arg0 context.Context, arg1 *models.Payment, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreatePayment", arg0, arg1)
+	ret := m.ctrl.Call(m, "CreatePayment", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // CreatePayment indicates an expected call of CreatePayment.
-func (mr *MockRideRepoMockRecorder) CreatePayment(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockRideRepoMockRecorder) CreatePayment(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockRideRepo)(nil).CreatePayment), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePayment", reflect.TypeOf((*MockRideRepo)(nil).CreatePayment), arg0, arg1, arg2)
 }
 
 // CreateRide mocks base method.
@@ -92,6 +123,50 @@ func (mr *MockRideRepoMockRecorder) CreateRide(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRide", reflect.TypeOf((*MockRideRepo)(nil).CreateRide), arg0)
 }
 
+// CreateRideWithBilling mocks base method.
+func (m *MockRideRepo) CreateRideWithBilling(arg0 context.Context, arg1 *models.Ride, arg2 *models.RideFare, arg3 *models.OutboxEvent) (*models.Ride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRideWithBilling", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.Ride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateRideWithBilling indicates an expected call of CreateRideWithBilling.
+func (mr *MockRideRepoMockRecorder) CreateRideWithBilling(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRideWithBilling", reflect.TypeOf((*MockRideRepo)(nil).CreateRideWithBilling), arg0, arg1, arg2, arg3)
+}
+
+// DeletePickupCode mocks base method.
+func (m *MockRideRepo) DeletePickupCode(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeletePickupCode", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeletePickupCode indicates an expected call of DeletePickupCode.
+func (mr *MockRideRepoMockRecorder) DeletePickupCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeletePickupCode", reflect.TypeOf((*MockRideRepo)(nil).DeletePickupCode), arg0, arg1)
+}
+
+// GetBillingEntries mocks base method.
+func (m *MockRideRepo) GetBillingEntries(arg0 context.Context, arg1 string) ([]*models.BillingLedger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBillingEntries", arg0, arg1)
+	ret0, _ := ret[0].([]*models.BillingLedger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBillingEntries indicates an expected call of GetBillingEntries.
+func (mr *MockRideRepoMockRecorder) GetBillingEntries(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBillingEntries", reflect.TypeOf((*MockRideRepo)(nil).GetBillingEntries), arg0, arg1)
+}
+
 // GetBillingLedgerSum mocks base method.
 func (m *MockRideRepo) GetBillingLedgerSum(arg0 context.Context, arg1 string) (int, error) {
 	m.ctrl.T.Helper()
@@ -107,6 +182,21 @@ func (mr *MockRideRepoMockRecorder) GetBillingLedgerSum(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBillingLedgerSum", reflect.TypeOf((*MockRideRepo)(nil).GetBillingLedgerSum), arg0, arg1)
 }
 
+// GetPaymentAuditTrail mocks base method.
+func (m *MockRideRepo) GetPaymentAuditTrail(arg0 context.Context, arg1 string) ([]*models.PaymentAudit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPaymentAuditTrail", arg0, arg1)
+	ret0, _ := ret[0].([]*models.PaymentAudit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPaymentAuditTrail indicates an expected call of GetPaymentAuditTrail.
+func (mr *MockRideRepoMockRecorder) GetPaymentAuditTrail(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentAuditTrail", reflect.TypeOf((*MockRideRepo)(nil).GetPaymentAuditTrail), arg0, arg1)
+}
+
 // GetPaymentByRideID mocks base method.
 func (m *MockRideRepo) GetPaymentByRideID(arg0 context.Context, arg1 string) (*models.Payment, error) {
 	m.ctrl.T.Helper()
@@ -122,6 +212,36 @@ func (mr *MockRideRepoMockRecorder) GetPaymentByRideID(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPaymentByRideID", reflect.TypeOf((*MockRideRepo)(nil).GetPaymentByRideID), arg0, arg1)
 }
 
+// GetPickupCode mocks base method.
+func (m *MockRideRepo) GetPickupCode(arg0 context.Context, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPickupCode", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPickupCode indicates an expected call of GetPickupCode.
+func (mr *MockRideRepoMockRecorder) GetPickupCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPickupCode", reflect.TypeOf((*MockRideRepo)(nil).GetPickupCode), arg0, arg1)
+}
+
+// GetPromo mocks base method.
+func (m *MockRideRepo) GetPromo(arg0 context.Context, arg1 string) (*models.Promo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPromo", arg0, arg1)
+	ret0, _ := ret[0].(*models.Promo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPromo indicates an expected call of GetPromo.
+func (mr *MockRideRepoMockRecorder) GetPromo(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPromo", reflect.TypeOf((*MockRideRepo)(nil).GetPromo), arg0, arg1)
+}
+
 // GetRide mocks base method.
 func (m *MockRideRepo) GetRide(arg0 context.Context, arg1 string) (*models.Ride, error) {
 	m.ctrl.T.Helper()
@@ -137,40 +257,199 @@ func (mr *MockRideRepoMockRecorder) GetRide(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRide", reflect.TypeOf((*MockRideRepo)(nil).GetRide), arg0, arg1)
 }
 
+// GetRideFare mocks base method.
+func (m *MockRideRepo) GetRideFare(arg0 context.Context, arg1 string) (*models.RideFare, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRideFare", arg0, arg1)
+	ret0, _ := ret[0].(*models.RideFare)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRideFare indicates an expected call of GetRideFare.
+func (mr *MockRideRepoMockRecorder) GetRideFare(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRideFare", reflect.TypeOf((*MockRideRepo)(nil).GetRideFare), arg0, arg1)
+}
+
+// ListBillingEntries mocks base method.
+func (m *MockRideRepo) ListBillingEntries(arg0 context.Context, arg1 string, arg2 models.BillingCategory) ([]*models.BillingLedger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListBillingEntries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.BillingLedger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListBillingEntries indicates an expected call of ListBillingEntries.
+func (mr *MockRideRepoMockRecorder) ListBillingEntries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListBillingEntries", reflect.TypeOf((*MockRideRepo)(nil).ListBillingEntries), arg0, arg1, arg2)
+}
+
+// ListOverdueRides mocks base method.
+func (m *MockRideRepo) ListOverdueRides(arg0 context.Context, arg1 time.Time, arg2 int) ([]*models.Ride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOverdueRides", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*models.Ride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOverdueRides indicates an expected call of ListOverdueRides.
+func (mr *MockRideRepoMockRecorder) ListOverdueRides(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOverdueRides", reflect.TypeOf((*MockRideRepo)(nil).ListOverdueRides), arg0, arg1, arg2)
+}
+
+// MarkDriverArrived mocks base method.
+func (m *MockRideRepo) MarkDriverArrived(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkDriverArrived", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkDriverArrived indicates an expected call of MarkDriverArrived.
+func (mr *MockRideRepoMockRecorder) MarkDriverArrived(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkDriverArrived", reflect.TypeOf((*MockRideRepo)(nil).MarkDriverArrived), arg0, arg1, arg2)
+}
+
+// MarkOutboxEventFailed mocks base method.
+func (m *MockRideRepo) MarkOutboxEventFailed(arg0 context.Context, arg1 uuid.UUID, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventFailed", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventFailed indicates an expected call of MarkOutboxEventFailed.
+func (mr *MockRideRepoMockRecorder) MarkOutboxEventFailed(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventFailed", reflect.TypeOf((*MockRideRepo)(nil).MarkOutboxEventFailed), arg0, arg1, arg2)
+}
+
+// MarkOutboxEventSent mocks base method.
+func (m *MockRideRepo) MarkOutboxEventSent(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkOutboxEventSent", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkOutboxEventSent indicates an expected call of MarkOutboxEventSent.
+func (mr *MockRideRepoMockRecorder) MarkOutboxEventSent(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOutboxEventSent", reflect.TypeOf((*MockRideRepo)(nil).MarkOutboxEventSent), arg0, arg1)
+}
+
+// RecordPickupCodeFailure mocks base method.
+func (m *MockRideRepo) RecordPickupCodeFailure(arg0 context.Context, arg1 string, arg2 time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordPickupCodeFailure", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordPickupCodeFailure indicates an expected call of RecordPickupCodeFailure.
+func (mr *MockRideRepoMockRecorder) RecordPickupCodeFailure(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordPickupCodeFailure", reflect.TypeOf((*MockRideRepo)(nil).RecordPickupCodeFailure), arg0, arg1, arg2)
+}
+
+// RedeemPromo mocks base method.
+func (m *MockRideRepo) RedeemPromo(arg0 context.Context, arg1 string, arg2 *models.BillingLedger) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemPromo", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RedeemPromo indicates an expected call of RedeemPromo.
+func (mr *MockRideRepoMockRecorder) RedeemPromo(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemPromo", reflect.TypeOf((*MockRideRepo)(nil).RedeemPromo), arg0, arg1, arg2)
+}
+
+// StorePickupCode mocks base method.
+func (m *MockRideRepo) StorePickupCode(arg0 context.Context, arg1, arg2 string, arg3 time.Duration) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorePickupCode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StorePickupCode indicates an expected call of StorePickupCode.
+func (mr *MockRideRepoMockRecorder) StorePickupCode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorePickupCode", reflect.TypeOf((*MockRideRepo)(nil).StorePickupCode), arg0, arg1, arg2, arg3)
+}
+
+// UpdateColocatedSince mocks base method.
+func (m *MockRideRepo) UpdateColocatedSince(arg0 context.Context, arg1 string, arg2 *time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateColocatedSince", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateColocatedSince indicates an expected call of UpdateColocatedSince.
+func (mr *MockRideRepoMockRecorder) UpdateColocatedSince(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateColocatedSince", reflect.TypeOf((*MockRideRepo)(nil).UpdateColocatedSince), arg0, arg1, arg2)
+}
+
 // UpdatePaymentStatus mocks base method.
-func (m *MockRideRepo) UpdatePaymentStatus(arg0 context.Context, arg1 string, arg2 models.PaymentStatus) error {
+func (m *MockRideRepo) UpdatePaymentStatus(arg0 context.Context, arg1 *models.Payment, arg2 models.PaymentStatus, arg3 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePaymentStatus", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdatePaymentStatus", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePaymentStatus indicates an expected call of UpdatePaymentStatus.
-func (mr *MockRideRepoMockRecorder) UpdatePaymentStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockRideRepoMockRecorder) UpdatePaymentStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatus", reflect.TypeOf((*MockRideRepo)(nil).UpdatePaymentStatus), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePaymentStatus", reflect.TypeOf((*MockRideRepo)(nil).UpdatePaymentStatus), arg0, arg1, arg2, arg3)
+}
+
+// UpdatePickupETA mocks base method.
+func (m *MockRideRepo) UpdatePickupETA(arg0 context.Context, arg1 string, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdatePickupETA", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdatePickupETA indicates an expected call of UpdatePickupETA.
+func (mr *MockRideRepoMockRecorder) UpdatePickupETA(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePickupETA", reflect.TypeOf((*MockRideRepo)(nil).UpdatePickupETA), arg0, arg1, arg2)
 }
 
 // UpdateRideStatus mocks base method.
-func (m *MockRideRepo) UpdateRideStatus(arg0 context.Context, arg1 string, arg2 models.RideStatus) error {
+func (m *MockRideRepo) UpdateRideStatus(arg0 context.Context, arg1 string, arg2, arg3 models.RideStatus) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateRideStatus", arg0, arg1, arg2)
+	ret := m.ctrl.Call(m, "UpdateRideStatus", arg0, arg1, arg2, arg3)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdateRideStatus indicates an expected call of UpdateRideStatus.
-func (mr *MockRideRepoMockRecorder) UpdateRideStatus(arg0, arg1, arg2 interface{}) *gomock.Call {
+func (mr *MockRideRepoMockRecorder) UpdateRideStatus(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRideStatus", reflect.TypeOf((*MockRideRepo)(nil).UpdateRideStatus), arg0, arg1, arg2)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRideStatus", reflect.TypeOf((*MockRideRepo)(nil).UpdateRideStatus), arg0, arg1, arg2, arg3)
 }
 
 // UpdateTotalCost mocks base method.
-func (m *MockRideRepo) UpdateTotalCost(arg0 context.Context, arg1 string, arg2 int) error {
+func (m *MockRideRepo) UpdateTotalCost(arg0 context.Context, arg1 string, arg2 int) (int, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "UpdateTotalCost", arg0, arg1, arg2)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // UpdateTotalCost indicates an expected call of UpdateTotalCost.
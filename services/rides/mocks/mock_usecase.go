@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/piresc/nebengjek/internal/pkg/models"
@@ -35,6 +36,65 @@ func (m *MockRideUC) EXPECT() *MockRideUCMockRecorder {
 	return m.recorder
 }
 
+// AddSurcharge mocks base method.
+func (m *MockRideUC) AddSurcharge(arg0 context.Context, arg1 models.SurchargeRequest) (*models.BillingLedger, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddSurcharge", arg0, arg1)
+	ret0, _ := ret[0].(*models.BillingLedger)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddSurcharge indicates an expected call of AddSurcharge.
+func (mr *MockRideUCMockRecorder) AddSurcharge(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddSurcharge", reflect.TypeOf((*MockRideUC)(nil).AddSurcharge), arg0, arg1)
+}
+
+// AutoStartRide mocks base method.
+func (m *MockRideUC) AutoStartRide(arg0 context.Context, arg1 string, arg2 models.Location) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AutoStartRide", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AutoStartRide indicates an expected call of AutoStartRide.
+func (mr *MockRideUCMockRecorder) AutoStartRide(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AutoStartRide", reflect.TypeOf((*MockRideUC)(nil).AutoStartRide), arg0, arg1, arg2)
+}
+
+// CancelRide mocks base method.
+func (m *MockRideUC) CancelRide(arg0 context.Context, arg1 models.RideCancelRequest) (*models.RideCancellation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelRide", arg0, arg1)
+	ret0, _ := ret[0].(*models.RideCancellation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelRide indicates an expected call of CancelRide.
+func (mr *MockRideUCMockRecorder) CancelRide(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelRide", reflect.TypeOf((*MockRideUC)(nil).CancelRide), arg0, arg1)
+}
+
+// CompleteOverdueRides mocks base method.
+func (m *MockRideUC) CompleteOverdueRides(arg0 context.Context, arg1 time.Time, arg2 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CompleteOverdueRides", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CompleteOverdueRides indicates an expected call of CompleteOverdueRides.
+func (mr *MockRideUCMockRecorder) CompleteOverdueRides(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CompleteOverdueRides", reflect.TypeOf((*MockRideUC)(nil).CompleteOverdueRides), arg0, arg1, arg2)
+}
+
 // CreateRide mocks base method.
 func (m *MockRideUC) CreateRide(arg0 context.Context, arg1 models.MatchProposal) error {
 	m.ctrl.T.Helper()
@@ -49,6 +109,36 @@ func (mr *MockRideUCMockRecorder) CreateRide(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRide", reflect.TypeOf((*MockRideUC)(nil).CreateRide), arg0, arg1)
 }
 
+// DriverArrived mocks base method.
+func (m *MockRideUC) DriverArrived(arg0 context.Context, arg1 models.DriverArrivedRequest) (*models.Ride, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DriverArrived", arg0, arg1)
+	ret0, _ := ret[0].(*models.Ride)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DriverArrived indicates an expected call of DriverArrived.
+func (mr *MockRideUCMockRecorder) DriverArrived(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DriverArrived", reflect.TypeOf((*MockRideUC)(nil).DriverArrived), arg0, arg1)
+}
+
+// GetPickupCode mocks base method.
+func (m *MockRideUC) GetPickupCode(arg0 context.Context, arg1 models.PickupCodeRequest) (*models.PickupCodeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPickupCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.PickupCodeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPickupCode indicates an expected call of GetPickupCode.
+func (mr *MockRideUCMockRecorder) GetPickupCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPickupCode", reflect.TypeOf((*MockRideUC)(nil).GetPickupCode), arg0, arg1)
+}
+
 // ProcessBillingUpdate mocks base method.
 func (m *MockRideUC) ProcessBillingUpdate(arg0 context.Context, arg1 string, arg2 *models.BillingLedger) error {
 	m.ctrl.T.Helper()
@@ -78,6 +168,47 @@ func (mr *MockRideUCMockRecorder) ProcessPayment(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPayment", reflect.TypeOf((*MockRideUC)(nil).ProcessPayment), arg0, arg1)
 }
 
+// RefreshPickupETA mocks base method.
+func (m *MockRideUC) RefreshPickupETA(arg0 context.Context, arg1 string, arg2 models.Location) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshPickupETA", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RefreshPickupETA indicates an expected call of RefreshPickupETA.
+func (mr *MockRideUCMockRecorder) RefreshPickupETA(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshPickupETA", reflect.TypeOf((*MockRideUC)(nil).RefreshPickupETA), arg0, arg1, arg2)
+}
+
+// RelayOutboxEvents mocks base method.
+func (m *MockRideUC) RelayOutboxEvents(arg0 context.Context, arg1 int) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RelayOutboxEvents", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RelayOutboxEvents indicates an expected call of RelayOutboxEvents.
+func (mr *MockRideUCMockRecorder) RelayOutboxEvents(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RelayOutboxEvents", reflect.TypeOf((*MockRideUC)(nil).RelayOutboxEvents), arg0, arg1)
+}
+
+// ReloadConfig mocks base method.
+func (m *MockRideUC) ReloadConfig(arg0 *models.Config) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "ReloadConfig", arg0)
+}
+
+// ReloadConfig indicates an expected call of ReloadConfig.
+func (mr *MockRideUCMockRecorder) ReloadConfig(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReloadConfig", reflect.TypeOf((*MockRideUC)(nil).ReloadConfig), arg0)
+}
+
 // RideArrived mocks base method.
 func (m *MockRideUC) RideArrived(arg0 context.Context, arg1 models.RideArrivalReq) (*models.PaymentRequest, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +224,30 @@ func (mr *MockRideUCMockRecorder) RideArrived(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RideArrived", reflect.TypeOf((*MockRideUC)(nil).RideArrived), arg0, arg1)
 }
 
+// RunMaxDurationSweep mocks base method.
+func (m *MockRideUC) RunMaxDurationSweep(arg0 context.Context, arg1 time.Duration) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunMaxDurationSweep", arg0, arg1)
+}
+
+// RunMaxDurationSweep indicates an expected call of RunMaxDurationSweep.
+func (mr *MockRideUCMockRecorder) RunMaxDurationSweep(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunMaxDurationSweep", reflect.TypeOf((*MockRideUC)(nil).RunMaxDurationSweep), arg0, arg1)
+}
+
+// RunOutboxRelay mocks base method.
+func (m *MockRideUC) RunOutboxRelay(arg0 context.Context, arg1 time.Duration, arg2 int) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "RunOutboxRelay", arg0, arg1, arg2)
+}
+
+// RunOutboxRelay indicates an expected call of RunOutboxRelay.
+func (mr *MockRideUCMockRecorder) RunOutboxRelay(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RunOutboxRelay", reflect.TypeOf((*MockRideUC)(nil).RunOutboxRelay), arg0, arg1, arg2)
+}
+
 // StartRide mocks base method.
 func (m *MockRideUC) StartRide(arg0 context.Context, arg1 models.RideStartRequest) (*models.Ride, error) {
 	m.ctrl.T.Helper()
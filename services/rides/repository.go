@@ -2,7 +2,9 @@ package rides
 
 import (
 	"context"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
@@ -12,12 +14,39 @@ import (
 type RideRepo interface {
 	CreateRide(ride *models.Ride) (*models.Ride, error)
 	AddBillingEntry(ctx context.Context, entry *models.BillingLedger) error
-	UpdateTotalCost(ctx context.Context, rideID string, additionalCost int) error
+	UpdateTotalCost(ctx context.Context, rideID string, additionalCost int) (int, error)
 	GetRide(ctx context.Context, rideID string) (*models.Ride, error)
 	CompleteRide(ctx context.Context, ride *models.Ride) error
+	CancelRide(ctx context.Context, cancellation *models.RideCancellation, event *models.OutboxEvent) error
 	GetBillingLedgerSum(ctx context.Context, rideID string) (int, error)
-	CreatePayment(ctx context.Context, payment *models.Payment) error
-	UpdateRideStatus(ctx context.Context, rideID string, status models.RideStatus) error
+	ListBillingEntries(ctx context.Context, rideID string, category models.BillingCategory) ([]*models.BillingLedger, error)
+	GetBillingEntries(ctx context.Context, rideID string) ([]*models.BillingLedger, error)
+	GetRideFare(ctx context.Context, rideID string) (*models.RideFare, error)
+	CreatePayment(ctx context.Context, payment *models.Payment, actor string) error
+	UpdateRideStatus(ctx context.Context, rideID string, from, status models.RideStatus) error
+	ListOverdueRides(ctx context.Context, startedBefore time.Time, limit int) ([]*models.Ride, error)
+	UpdatePickupETA(ctx context.Context, rideID string, etaSeconds int) error
+	UpdateColocatedSince(ctx context.Context, rideID string, since *time.Time) error
+	MarkDriverArrived(ctx context.Context, rideID string, arrivedAt time.Time) error
 	GetPaymentByRideID(ctx context.Context, rideID string) (*models.Payment, error)
-	UpdatePaymentStatus(ctx context.Context, paymentID string, status models.PaymentStatus) error
+	UpdatePaymentStatus(ctx context.Context, payment *models.Payment, status models.PaymentStatus, actor string) error
+	GetPaymentAuditTrail(ctx context.Context, rideID string) ([]*models.PaymentAudit, error)
+
+	// Pickup code operations
+	StorePickupCode(ctx context.Context, rideID, code string, ttl time.Duration) error
+	GetPickupCode(ctx context.Context, rideID string) (string, error)
+	DeletePickupCode(ctx context.Context, rideID string) error
+	RecordPickupCodeFailure(ctx context.Context, rideID string, ttl time.Duration) (int, error)
+
+	// Promo operations
+	GetPromo(ctx context.Context, code string) (*models.Promo, error)
+	RedeemPromo(ctx context.Context, code string, entry *models.BillingLedger) error
+
+	// Ride creation together with its fare and pickup event
+	CreateRideWithBilling(ctx context.Context, ride *models.Ride, fare *models.RideFare, event *models.OutboxEvent) (*models.Ride, error)
+
+	// Outbox operations
+	ClaimPendingOutboxEvents(ctx context.Context, limit int) ([]*models.OutboxEvent, error)
+	MarkOutboxEventSent(ctx context.Context, eventID uuid.UUID) error
+	MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, publishErr string) error
 }
@@ -0,0 +1,200 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// CreateRideWithBilling creates a ride, its fare and its outbox event in a single transaction,
+// so a failure part way through never leaves a ride that cannot be billed
+func (r *RideRepo) CreateRideWithBilling(ctx context.Context, ride *models.Ride, fare *models.RideFare, event *models.OutboxEvent) (*models.Ride, error) {
+	if ride.RideID == uuid.Nil {
+		ride.RideID = uuid.New()
+	}
+	if ride.CreatedAt.IsZero() {
+		now := time.Now()
+		ride.CreatedAt = now
+		ride.UpdatedAt = now
+	}
+	if event.EventID == uuid.Nil {
+		event.EventID = uuid.New()
+	}
+	event.AggregateID = ride.RideID
+	event.Status = models.OutboxStatusPending
+	fare.RideID = ride.RideID
+	fare.CreatedAt = ride.CreatedAt
+
+	// Begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO rides (
+			ride_id, match_id, driver_id, passenger_id, status, total_cost,
+			pickup_latitude, pickup_longitude, pickup_eta_seconds, payment_method, created_at, updated_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+		)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		ride.RideID,
+		ride.MatchID,
+		ride.DriverID,
+		ride.PassengerID,
+		ride.Status,
+		ride.TotalCost,
+		ride.PickupLatitude,
+		ride.PickupLongitude,
+		ride.PickupETASeconds,
+		ride.PaymentMethod,
+		ride.CreatedAt,
+		ride.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	query = `
+		INSERT INTO ride_fares (
+			ride_id, region, rate_per_km, estimated_fare, estimated_distance_km, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		fare.RideID,
+		fare.Region,
+		fare.RatePerKm,
+		fare.EstimatedFare,
+		fare.EstimatedDistanceKm,
+		fare.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert ride fare: %w", err)
+	}
+
+	query = `
+		INSERT INTO outbox_events (
+			event_id, aggregate_id, subject, payload, status, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+	`
+	_, err = tx.ExecContext(ctx, query,
+		event.EventID,
+		event.AggregateID,
+		event.Subject,
+		event.Payload,
+		event.Status,
+		ride.CreatedAt,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+
+	// Commit transaction
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Info("Created ride with fare and outbox event",
+		logger.String("rideID", ride.RideID.String()),
+		logger.String("region", fare.Region),
+		logger.String("event_id", event.EventID.String()),
+		logger.String("subject", event.Subject))
+	return ride, nil
+}
+
+// outboxClaimLease is how long a relay holds the events it claimed before another may take them over
+const outboxClaimLease = time.Minute
+
+// ClaimPendingOutboxEvents claims the unpublished events of up to limit rides for this relay and
+// returns them oldest first. A ride is claimed as a whole through its oldest pending event: rides whose
+// oldest event is locked or leased by another relay are skipped, so one ride's events are never in
+// flight on two relays and go out in the order they were written. A lease left by a relay that died
+// lapses after outboxClaimLease.
+func (r *RideRepo) ClaimPendingOutboxEvents(ctx context.Context, limit int) ([]*models.OutboxEvent, error) {
+	query := `
+		WITH heads AS (
+			SELECT DISTINCT ON (aggregate_id) event_id
+			FROM outbox_events
+			WHERE status = $1
+			ORDER BY aggregate_id, created_at ASC
+		), claimable AS (
+			SELECT o.aggregate_id
+			FROM outbox_events o
+			JOIN heads ON heads.event_id = o.event_id
+			WHERE o.status = $1 AND (o.claimed_until IS NULL OR o.claimed_until < NOW())
+			ORDER BY o.created_at ASC
+			LIMIT $2
+			FOR UPDATE OF o SKIP LOCKED
+		)
+		UPDATE outbox_events o
+		SET claimed_until = NOW() + make_interval(secs => $3)
+		FROM claimable
+		WHERE o.aggregate_id = claimable.aggregate_id AND o.status = $1
+		RETURNING o.event_id, o.aggregate_id, o.subject, o.payload, o.status, o.attempts,
+			COALESCE(o.last_error, '') AS last_error, o.created_at, o.sent_at
+	`
+
+	var events []*models.OutboxEvent
+	if err := r.db.SelectContext(ctx, &events, query, models.OutboxStatusPending, limit, outboxClaimLease.Seconds()); err != nil {
+		return nil, fmt.Errorf("failed to claim pending outbox events: %w", err)
+	}
+
+	// RETURNING has no order, and events of one ride must go out in the order they were written
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.Before(events[j].CreatedAt)
+	})
+	return events, nil
+}
+
+// MarkOutboxEventSent marks an outbox event as published
+func (r *RideRepo) MarkOutboxEventSent(ctx context.Context, eventID uuid.UUID) error {
+	query := `
+		UPDATE outbox_events
+		SET status = $1,
+			attempts = attempts + 1,
+			last_error = NULL,
+			sent_at = NOW()
+		WHERE event_id = $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, models.OutboxStatusSent, eventID); err != nil {
+		return fmt.Errorf("failed to mark outbox event sent: %w", err)
+	}
+	return nil
+}
+
+// MarkOutboxEventFailed records a failed publish attempt, leaving the event pending and releasing
+// the claim on its ride's pending events so the next relay run retries them in order
+func (r *RideRepo) MarkOutboxEventFailed(ctx context.Context, eventID uuid.UUID, publishErr string) error {
+	query := `
+		WITH failed AS (
+			UPDATE outbox_events
+			SET attempts = attempts + 1,
+				last_error = $1,
+				claimed_until = NULL
+			WHERE event_id = $2
+			RETURNING aggregate_id
+		)
+		UPDATE outbox_events o
+		SET claimed_until = NULL
+		FROM failed
+		WHERE o.aggregate_id = failed.aggregate_id AND o.status = $3 AND o.event_id <> $2
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, publishErr, eventID, models.OutboxStatusPending); err != nil {
+		return fmt.Errorf("failed to record outbox publish failure: %w", err)
+	}
+	return nil
+}
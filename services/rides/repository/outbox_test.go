@@ -0,0 +1,147 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateRideWithBilling_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	r := &models.Ride{RideID: uuid.New(), MatchID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New(), Status: models.RideStatusDriverPickup}
+	fare := &models.RideFare{Region: "jakarta", RatePerKm: 3500, EstimatedFare: 17500, EstimatedDistanceKm: 5}
+	event := &models.OutboxEvent{EventID: uuid.New(), Subject: constants.SubjectRidePickup, Payload: []byte(`{}`)}
+
+	// The ride, its fare and its pickup event are committed together
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO rides")).
+		WithArgs(r.RideID, r.MatchID, r.DriverID, r.PassengerID, r.Status, r.TotalCost,
+			r.PickupLatitude, r.PickupLongitude, r.PickupETASeconds, r.PaymentMethod, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ride_fares")).
+		WithArgs(r.RideID, "jakarta", 3500.0, 17500, 5.0, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_events")).
+		WithArgs(event.EventID, r.RideID, constants.SubjectRidePickup, event.Payload, models.OutboxStatusPending, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	created, err := repo.CreateRideWithBilling(context.Background(), r, fare, event)
+	assert.NoError(t, err)
+	assert.Equal(t, r.RideID, created.RideID)
+	assert.Equal(t, r.RideID, fare.RideID)
+	assert.Equal(t, r.RideID, event.AggregateID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateRideWithBilling_FareInsertRollsBack(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	r := &models.Ride{MatchID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New(), Status: models.RideStatusDriverPickup}
+	fare := &models.RideFare{Region: "default", RatePerKm: 3000}
+	event := &models.OutboxEvent{Subject: constants.SubjectRidePickup, Payload: []byte(`{}`)}
+
+	// The ride insert is undone rather than leaving a ride without a fare
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO rides")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ride_fares")).
+		WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+
+	created, err := repo.CreateRideWithBilling(context.Background(), r, fare, event)
+	assert.Error(t, err)
+	assert.Nil(t, created)
+	assert.Contains(t, err.Error(), "failed to insert ride fare")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateRideWithBilling_OutboxInsertRollsBack(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	r := &models.Ride{MatchID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New(), Status: models.RideStatusDriverPickup}
+	fare := &models.RideFare{Region: "default", RatePerKm: 3000}
+	event := &models.OutboxEvent{Subject: constants.SubjectRidePickup, Payload: []byte(`{}`)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO rides")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ride_fares")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_events")).
+		WillReturnError(errors.New("insert failed"))
+	mock.ExpectRollback()
+
+	created, err := repo.CreateRideWithBilling(context.Background(), r, fare, event)
+	assert.Error(t, err)
+	assert.Nil(t, created)
+	assert.Contains(t, err.Error(), "failed to insert outbox event")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClaimPendingOutboxEvents_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	// Claimed rows come back in no particular order
+	older, newer := uuid.New(), uuid.New()
+	now := time.Now()
+	rows := sqlmock.NewRows([]string{"event_id", "aggregate_id", "subject", "payload", "status", "attempts", "last_error", "created_at", "sent_at"}).
+		AddRow(newer, uuid.New(), constants.SubjectRideCancelled, []byte(`{}`), models.OutboxStatusPending, 0, "", now, nil).
+		AddRow(older, uuid.New(), constants.SubjectRidePickup, []byte(`{}`), models.OutboxStatusPending, 1, "timeout", now.Add(-time.Second), nil)
+
+	// Rides whose oldest event another relay holds are skipped rather than published twice
+	mock.ExpectQuery(regexp.QuoteMeta("FOR UPDATE OF o SKIP LOCKED")).
+		WithArgs(models.OutboxStatusPending, 50, time.Minute.Seconds()).
+		WillReturnRows(rows)
+
+	events, err := repo.ClaimPendingOutboxEvents(context.Background(), 50)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+	assert.Equal(t, older, events[0].EventID)
+	assert.Equal(t, 1, events[0].Attempts)
+	assert.Nil(t, events[0].SentAt)
+	assert.Equal(t, newer, events[1].EventID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkOutboxEventSent_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	eventID := uuid.New()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events")).
+		WithArgs(models.OutboxStatusSent, eventID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.MarkOutboxEventSent(context.Background(), eventID)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkOutboxEventFailed_Error(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	eventID := uuid.New()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE outbox_events")).
+		WithArgs("nats unavailable", eventID, models.OutboxStatusPending).
+		WillReturnError(errors.New("db down"))
+
+	err := repo.MarkOutboxEventFailed(context.Background(), eventID, "nats unavailable")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to record outbox publish failure")
+}
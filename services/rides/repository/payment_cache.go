@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// defaultPaymentCacheTTL is used when no payment cache TTL is configured
+const defaultPaymentCacheTTL = 30 * time.Second
+
+// paymentCacheTTL returns how long a payment record stays cached
+func (r *RideRepo) paymentCacheTTL() time.Duration {
+	if r.cfg != nil && r.cfg.Rides.PaymentCacheTTLSecs > 0 {
+		return time.Duration(r.cfg.Rides.PaymentCacheTTLSecs) * time.Second
+	}
+	return defaultPaymentCacheTTL
+}
+
+// getCachedPayment returns the cached payment of a ride, or nil on a miss. Cache errors are
+// logged and treated as a miss so payments are still read from the database.
+func (r *RideRepo) getCachedPayment(ctx context.Context, rideID string) *models.Payment {
+	if r.redisClient == nil {
+		return nil
+	}
+
+	data, err := r.redisClient.Get(ctx, fmt.Sprintf(constants.KeyRidePayment, rideID))
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Warn("Failed to read cached payment",
+				logger.String("ride_id", rideID),
+				logger.ErrorField(err))
+		}
+		return nil
+	}
+
+	var payment models.Payment
+	if err := json.Unmarshal([]byte(data), &payment); err != nil {
+		logger.Warn("Failed to decode cached payment",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+		return nil
+	}
+	return &payment
+}
+
+// cachePayment stores a payment read from the database for later reads of the same ride
+func (r *RideRepo) cachePayment(ctx context.Context, payment *models.Payment) {
+	if r.redisClient == nil {
+		return
+	}
+
+	data, err := json.Marshal(payment)
+	if err != nil {
+		return
+	}
+	rideID := payment.RideID.String()
+	if err := r.redisClient.Set(ctx, fmt.Sprintf(constants.KeyRidePayment, rideID), data, r.paymentCacheTTL()); err != nil {
+		logger.Warn("Failed to cache payment",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+	}
+}
+
+// invalidateCachedPayment drops the cached payment of a ride after it was written, so the next
+// read sees the new status
+func (r *RideRepo) invalidateCachedPayment(ctx context.Context, rideID string) {
+	if r.redisClient == nil {
+		return
+	}
+
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyRidePayment, rideID)); err != nil {
+		logger.Warn("Failed to invalidate cached payment",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+	}
+}
@@ -0,0 +1,146 @@
+package repository_test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/database"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupMockRedis(t *testing.T) (*database.RedisClient, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	return &database.RedisClient{Client: redis.NewClient(&redis.Options{Addr: mr.Addr()})}, mr
+}
+
+// expectPaymentQuery expects one database read of a ride's payment
+func expectPaymentQuery(mock sqlmock.Sqlmock, payment *models.Payment) {
+	rows := sqlmock.NewRows([]string{"payment_id", "ride_id", "adjusted_cost", "admin_fee", "driver_payout", "status", "created_at"}).
+		AddRow(payment.PaymentID, payment.RideID, payment.AdjustedCost, payment.AdminFee, payment.DriverPayout, payment.Status, payment.CreatedAt)
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT payment_id, ride_id, adjusted_cost, admin_fee, driver_payout, status, created_at")).
+		WithArgs(payment.RideID).
+		WillReturnRows(rows)
+}
+
+func newPendingPayment() *models.Payment {
+	return &models.Payment{
+		PaymentID:    uuid.New(),
+		RideID:       uuid.New(),
+		AdjustedCost: 8000,
+		AdminFee:     400,
+		DriverPayout: 7600,
+		Status:       models.PaymentStatusPending,
+		CreatedAt:    time.Now().UTC().Truncate(time.Second),
+	}
+}
+
+func TestGetPaymentByRideID_CacheMissThenHit(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, mr := setupMockRedis(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	payment := newPendingPayment()
+	rideID := payment.RideID.String()
+
+	// Only the first read goes to the database
+	expectPaymentQuery(mock, payment)
+
+	first, err := repo.GetPaymentByRideID(ctx, rideID)
+	require.NoError(t, err)
+	assert.True(t, mr.Exists("rides:payment:"+rideID))
+
+	second, err := repo.GetPaymentByRideID(ctx, rideID)
+	require.NoError(t, err)
+	assert.Equal(t, first, second)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPaymentByRideID_CacheExpires(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, mr := setupMockRedis(t)
+	repo := repository.NewRideRepository(&models.Config{Rides: models.RidesConfig{PaymentCacheTTLSecs: 10}}, db, redisClient)
+	ctx := context.Background()
+
+	payment := newPendingPayment()
+	expectPaymentQuery(mock, payment)
+	expectPaymentQuery(mock, payment)
+
+	_, err := repo.GetPaymentByRideID(ctx, payment.RideID.String())
+	require.NoError(t, err)
+
+	mr.FastForward(10 * time.Second)
+	_, err = repo.GetPaymentByRideID(ctx, payment.RideID.String())
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePaymentStatus_InvalidatesCachedPayment(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, mr := setupMockRedis(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	payment := newPendingPayment()
+	rideID := payment.RideID.String()
+
+	expectPaymentQuery(mock, payment)
+	cached, err := repo.GetPaymentByRideID(ctx, rideID)
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE payments")).
+		WithArgs(models.PaymentStatusAccepted, payment.PaymentID, models.PaymentStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO payment_audit")).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.UpdatePaymentStatus(ctx, cached, models.PaymentStatusAccepted, "passenger:p1"))
+	assert.False(t, mr.Exists("rides:payment:"+rideID))
+
+	// The next read sees the new status from the database
+	accepted := *payment
+	accepted.Status = models.PaymentStatusAccepted
+	expectPaymentQuery(mock, &accepted)
+
+	fresh, err := repo.GetPaymentByRideID(ctx, rideID)
+	require.NoError(t, err)
+	assert.Equal(t, models.PaymentStatusAccepted, fresh.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePaymentStatus_StaleStatusInvalidatesCachedPayment(t *testing.T) {
+	db, mock := setupMockDB(t)
+	redisClient, mr := setupMockRedis(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, redisClient)
+	ctx := context.Background()
+
+	payment := newPendingPayment()
+	rideID := payment.RideID.String()
+
+	expectPaymentQuery(mock, payment)
+	cached, err := repo.GetPaymentByRideID(ctx, rideID)
+	require.NoError(t, err)
+
+	// Another attempt already moved the payment on, so the cached status is stale
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE payments")).
+		WithArgs(models.PaymentStatusAccepted, payment.PaymentID, models.PaymentStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err = repo.UpdatePaymentStatus(ctx, cached, models.PaymentStatusAccepted, "passenger:p1")
+	assert.Error(t, err)
+	assert.False(t, mr.Exists("rides:payment:"+rideID))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
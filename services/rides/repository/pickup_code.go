@@ -0,0 +1,63 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/services/rides"
+)
+
+// StorePickupCode stores the code the driver must enter to start a ride, for ttl, clearing the
+// wrong attempts made against the code it replaces
+func (r *RideRepo) StorePickupCode(ctx context.Context, rideID, code string, ttl time.Duration) error {
+	if err := r.redisClient.Set(ctx, fmt.Sprintf(constants.KeyRidePickupCode, rideID), code, ttl); err != nil {
+		return fmt.Errorf("failed to store pickup code: %w", err)
+	}
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyRidePickupCodeAttempts, rideID)); err != nil {
+		return fmt.Errorf("failed to reset pickup code attempts: %w", err)
+	}
+	return nil
+}
+
+// GetPickupCode returns a ride's pickup code, or rides.ErrPickupCodeExpired once it has expired
+func (r *RideRepo) GetPickupCode(ctx context.Context, rideID string) (string, error) {
+	code, err := r.redisClient.Get(ctx, fmt.Sprintf(constants.KeyRidePickupCode, rideID))
+	if errors.Is(err, redis.Nil) {
+		return "", fmt.Errorf("%w: %s", rides.ErrPickupCodeExpired, rideID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get pickup code: %w", err)
+	}
+	return code, nil
+}
+
+// DeletePickupCode removes a ride's pickup code, once used or invalidated, along with its wrong attempts
+func (r *RideRepo) DeletePickupCode(ctx context.Context, rideID string) error {
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyRidePickupCode, rideID)); err != nil {
+		return fmt.Errorf("failed to delete pickup code: %w", err)
+	}
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyRidePickupCodeAttempts, rideID)); err != nil {
+		return fmt.Errorf("failed to delete pickup code attempts: %w", err)
+	}
+	return nil
+}
+
+// RecordPickupCodeFailure counts a wrong pickup code entered for a ride and returns the failures so
+// far. The counter expires after ttl, like the code it guards.
+func (r *RideRepo) RecordPickupCodeFailure(ctx context.Context, rideID string, ttl time.Duration) (int, error) {
+	key := fmt.Sprintf(constants.KeyRidePickupCodeAttempts, rideID)
+	failures, err := r.redisClient.Incr(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record pickup code failure: %w", err)
+	}
+	if failures == 1 {
+		if err := r.redisClient.Expire(ctx, key, ttl); err != nil {
+			return 0, fmt.Errorf("failed to expire pickup code attempts: %w", err)
+		}
+	}
+	return int(failures), nil
+}
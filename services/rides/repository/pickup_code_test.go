@@ -0,0 +1,68 @@
+package repository_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+	"github.com/piresc/nebengjek/services/rides/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPickupCode_StoreGetDelete(t *testing.T) {
+	redisClient, mr := setupMockRedis(t)
+	repo := repository.NewRideRepository(&models.Config{}, nil, redisClient)
+	ctx := context.Background()
+
+	require.NoError(t, repo.StorePickupCode(ctx, "ride-1", "0427", time.Minute))
+	assert.Equal(t, time.Minute, mr.TTL("rides:pickup-code:ride-1"))
+
+	code, err := repo.GetPickupCode(ctx, "ride-1")
+	require.NoError(t, err)
+	assert.Equal(t, "0427", code)
+
+	require.NoError(t, repo.DeletePickupCode(ctx, "ride-1"))
+	_, err = repo.GetPickupCode(ctx, "ride-1")
+	assert.ErrorIs(t, err, rides.ErrPickupCodeExpired)
+}
+
+func TestPickupCode_Expired(t *testing.T) {
+	redisClient, mr := setupMockRedis(t)
+	repo := repository.NewRideRepository(&models.Config{}, nil, redisClient)
+	ctx := context.Background()
+
+	require.NoError(t, repo.StorePickupCode(ctx, "ride-1", "0427", time.Minute))
+	mr.FastForward(2 * time.Minute)
+
+	_, err := repo.GetPickupCode(ctx, "ride-1")
+	assert.ErrorIs(t, err, rides.ErrPickupCodeExpired)
+}
+
+func TestPickupCode_RecordFailure(t *testing.T) {
+	redisClient, mr := setupMockRedis(t)
+	repo := repository.NewRideRepository(&models.Config{}, nil, redisClient)
+	ctx := context.Background()
+
+	require.NoError(t, repo.StorePickupCode(ctx, "ride-1", "0427", time.Minute))
+
+	failures, err := repo.RecordPickupCodeFailure(ctx, "ride-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+	failures, err = repo.RecordPickupCodeFailure(ctx, "ride-1", time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, failures)
+	assert.Equal(t, time.Minute, mr.TTL("rides:pickup-code-attempts:ride-1"))
+
+	// A reissued code starts without failures
+	require.NoError(t, repo.StorePickupCode(ctx, "ride-1", "9051", time.Minute))
+	assert.False(t, mr.Exists("rides:pickup-code-attempts:ride-1"))
+
+	// So does a deleted one
+	_, err = repo.RecordPickupCodeFailure(ctx, "ride-1", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, repo.DeletePickupCode(ctx, "ride-1"))
+	assert.False(t, mr.Exists("rides:pickup-code-attempts:ride-1"))
+}
@@ -2,27 +2,34 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
 )
 
 type RideRepo struct {
-	cfg *models.Config
-	db  *sqlx.DB
+	cfg         *models.Config
+	db          *sqlx.DB
+	redisClient *database.RedisClient // Caches payment records; nil reads every payment from the database
 }
 
 func NewRideRepository(
 	cfg *models.Config,
 	db *sqlx.DB,
+	redisClient *database.RedisClient,
 ) *RideRepo {
 	return &RideRepo{
-		cfg: cfg,
-		db:  db,
+		cfg:         cfg,
+		db:          db,
+		redisClient: redisClient,
 	}
 }
 
@@ -43,9 +50,10 @@ func (r *RideRepo) CreateRide(ride *models.Ride) (*models.Ride, error) {
 	// Insert the ride into the database
 	query := `
 		INSERT INTO rides (
-			ride_id, match_id, driver_id, passenger_id, status, total_cost, created_at, updated_at
+			ride_id, match_id, driver_id, passenger_id, status, total_cost,
+			pickup_latitude, pickup_longitude, pickup_eta_seconds, payment_method, created_at, updated_at
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
 		) RETURNING ride_id
 	`
 
@@ -58,6 +66,10 @@ func (r *RideRepo) CreateRide(ride *models.Ride) (*models.Ride, error) {
 		ride.PassengerID,
 		ride.Status,
 		ride.TotalCost,
+		ride.PickupLatitude,
+		ride.PickupLongitude,
+		ride.PickupETASeconds,
+		ride.PaymentMethod,
 		ride.CreatedAt,
 		ride.UpdatedAt,
 	)
@@ -71,25 +83,50 @@ func (r *RideRepo) CreateRide(ride *models.Ride) (*models.Ride, error) {
 	return ride, nil
 }
 
-// AddBillingEntry adds a new entry to the billing ledger
+// AddBillingEntry adds a new entry to the billing ledger, or returns rides.ErrLedgerFinalized once
+// the ride's fare has been charged. The ride row is share-locked so a completion can't slip in between.
 func (r *RideRepo) AddBillingEntry(ctx context.Context, entry *models.BillingLedger) error {
+	lockQuery := `SELECT ledger_finalized FROM rides WHERE ride_id = $1 FOR SHARE`
+
 	query := `
 		INSERT INTO billing_ledger (
-			entry_id, ride_id, distance, cost, created_at
+			entry_id, ride_id, category, description, distance, cost, created_at
 		) VALUES (
-			$1, $2, $3, $4, $5
+			$1, $2, $3, $4, $5, $6, $7
 		)
 	`
 
 	if entry.EntryID == uuid.Nil {
 		entry.EntryID = uuid.New()
 	}
+	if entry.Category == "" {
+		entry.Category = models.BillingCategoryDistance
+	}
 
-	_, err := r.db.ExecContext(
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var finalized bool
+	if err := tx.QueryRowContext(ctx, lockQuery, entry.RideID).Scan(&finalized); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("ride not found: %s", entry.RideID)
+		}
+		return fmt.Errorf("failed to lock ride ledger: %w", err)
+	}
+	if finalized {
+		return fmt.Errorf("%w: %s", rides.ErrLedgerFinalized, entry.RideID)
+	}
+
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		entry.EntryID,
 		entry.RideID,
+		entry.Category,
+		entry.Description,
 		entry.Distance,
 		entry.Cost,
 		time.Now(),
@@ -99,33 +136,32 @@ func (r *RideRepo) AddBillingEntry(ctx context.Context, entry *models.BillingLed
 		return fmt.Errorf("failed to insert billing entry: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
 	return nil
 }
 
-// UpdateTotalCost updates the total cost of a ride
-func (r *RideRepo) UpdateTotalCost(ctx context.Context, rideID string, additionalCost int) error {
+// UpdateTotalCost adds to the total cost of a ride and returns the new total
+func (r *RideRepo) UpdateTotalCost(ctx context.Context, rideID string, additionalCost int) (int, error) {
 	query := `
 		UPDATE rides 
 		SET total_cost = total_cost + $1,
 			updated_at = NOW()
 		WHERE ride_id = $2
+		RETURNING total_cost
 	`
 
-	result, err := r.db.ExecContext(ctx, query, additionalCost, rideID)
-	if err != nil {
-		return fmt.Errorf("failed to update ride total cost: %w", err)
-	}
-
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get affected rows: %w", err)
-	}
-
-	if rows == 0 {
-		return fmt.Errorf("ride not found: %s", rideID)
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, additionalCost, rideID).Scan(&total); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("ride not found: %s", rideID)
+		}
+		return 0, fmt.Errorf("failed to update ride total cost: %w", err)
 	}
 
-	return nil
+	return total, nil
 }
 
 // GetRide gets a ride by ID
@@ -143,12 +179,17 @@ func (r *RideRepo) GetRide(ctx context.Context, rideID string) (*models.Ride, er
 	}
 
 	query := `
-		SELECT ride_id, match_id, driver_id, passenger_id, status, total_cost, created_at, updated_at
+		SELECT ride_id, match_id, driver_id, passenger_id, status, total_cost,
+			pickup_latitude, pickup_longitude, pickup_eta_seconds, payment_method, colocated_since,
+			driver_arrived_at, started_at, ledger_finalized, final_total, created_at, updated_at
 		FROM rides
 		WHERE ride_id = $1
 	`
 
 	err = r.db.GetContext(ctx, &ride, query, rideIDUUID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", rides.ErrRideNotFound, rideID)
+	}
 	if err != nil {
 		logger.Error("Failed to get ride from database",
 			logger.String("ride_id", rideID),
@@ -166,16 +207,24 @@ func (r *RideRepo) GetRide(ctx context.Context, rideID string) (*models.Ride, er
 	return &ride, nil
 }
 
-// CompleteRide marks a ride as completed
+// CompleteRide marks a ride as completed, locks its billing ledger and stores its final total
 func (r *RideRepo) CompleteRide(ctx context.Context, ride *models.Ride) error {
+	// A ride is only completed once its payment has been accepted, checked in the same statement
 	query := `
 		UPDATE rides 
 		SET status = $1,
+			ledger_finalized = TRUE,
+			final_total = $5,
 			updated_at = NOW()
 		WHERE ride_id = $2
+			AND EXISTS (
+				SELECT 1 FROM payments
+				WHERE payments.ride_id = $2 AND payments.status IN ($3, $4)
+			)
 	`
 
-	result, err := r.db.ExecContext(ctx, query, models.RideStatusCompleted, ride.RideID)
+	result, err := r.db.ExecContext(ctx, query, models.RideStatusCompleted, ride.RideID,
+		models.PaymentStatusAccepted, models.PaymentStatusProcessed, ride.FinalTotal)
 	if err != nil {
 		return fmt.Errorf("failed to complete ride: %w", err)
 	}
@@ -186,18 +235,126 @@ func (r *RideRepo) CompleteRide(ctx context.Context, ride *models.Ride) error {
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("ride not found: %s", ride.RideID)
+		return r.completeRideRefusal(ctx, ride.RideID)
 	}
 
 	return nil
 }
 
-// GetBillingLedgerSum gets the sum of all costs in the billing ledger for a ride
+// completeRideRefusal explains why CompleteRide updated nothing: either the ride's payment is
+// not yet accepted or the ride does not exist
+func (r *RideRepo) completeRideRefusal(ctx context.Context, rideID uuid.UUID) error {
+	var status models.PaymentStatus
+	err := r.db.QueryRowContext(ctx, `SELECT status FROM payments WHERE ride_id = $1`, rideID).Scan(&status)
+	if err == nil {
+		return fmt.Errorf("cannot complete ride %s with payment status %s", rideID, status)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("ride not found or has no payment: %s", rideID)
+	}
+	return fmt.Errorf("failed to check payment for ride %s: %w", rideID, err)
+}
+
+// CancelRide marks a ride that has not started as cancelled and records the cancellation and its
+// outbox event in the same transaction. Only rides still pending, awaiting pickup or waiting for the
+// passenger can be cancelled.
+func (r *RideRepo) CancelRide(ctx context.Context, cancellation *models.RideCancellation, event *models.OutboxEvent) error {
+	updateQuery := `
+		UPDATE rides
+		SET status = $1,
+			updated_at = NOW()
+		WHERE ride_id = $2 AND status IN ($3, $4, $5)
+	`
+
+	insertQuery := `
+		INSERT INTO ride_cancellations (
+			cancellation_id, ride_id, cancelled_by, role, fee, reason, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+	`
+
+	outboxQuery := `
+		INSERT INTO outbox_events (
+			event_id, aggregate_id, subject, payload, status, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6
+		)
+	`
+
+	if cancellation.CancellationID == uuid.Nil {
+		cancellation.CancellationID = uuid.New()
+	}
+	if event.EventID == uuid.Nil {
+		event.EventID = uuid.New()
+	}
+	event.AggregateID = cancellation.RideID
+	event.Status = models.OutboxStatusPending
+
+	// Begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, updateQuery, models.RideStatusCancelled, cancellation.RideID,
+		models.RideStatusPending, models.RideStatusDriverPickup, models.RideStatusDriverArrived)
+	if err != nil {
+		return fmt.Errorf("failed to cancel ride: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", rides.ErrRideNotCancellable, cancellation.RideID)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		insertQuery,
+		cancellation.CancellationID,
+		cancellation.RideID,
+		cancellation.CancelledBy,
+		cancellation.Role,
+		cancellation.Fee,
+		cancellation.Reason,
+		cancellation.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record ride cancellation: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, outboxQuery,
+		event.EventID,
+		event.AggregateID,
+		event.Subject,
+		event.Payload,
+		event.Status,
+		cancellation.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox event: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetBillingLedgerSum gets the sum of all costs in the billing ledger for a ride. Promo discounts
+// are left out so the sum is always the fare before any discount.
 func (r *RideRepo) GetBillingLedgerSum(ctx context.Context, rideID string) (int, error) {
 	query := `
 		SELECT SUM(cost) 
 		FROM billing_ledger 
-		WHERE ride_id = $1
+		WHERE ride_id = $1 AND category <> 'DISCOUNT'
 	`
 
 	var totalCost int
@@ -209,8 +366,149 @@ func (r *RideRepo) GetBillingLedgerSum(ctx context.Context, rideID string) (int,
 	return totalCost, nil
 }
 
-// CreatePayment creates a payment record for a ride
-func (r *RideRepo) CreatePayment(ctx context.Context, payment *models.Payment) error {
+// ListBillingEntries returns a ride's billing ledger entries of one category, oldest first
+func (r *RideRepo) ListBillingEntries(ctx context.Context, rideID string, category models.BillingCategory) ([]*models.BillingLedger, error) {
+	query := `
+		SELECT entry_id, ride_id, category, description, distance, cost, created_at
+		FROM billing_ledger
+		WHERE ride_id = $1 AND category = $2
+		ORDER BY created_at
+	`
+
+	entries := []*models.BillingLedger{}
+	if err := r.db.SelectContext(ctx, &entries, query, rideID, category); err != nil {
+		return nil, fmt.Errorf("failed to list billing entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetBillingEntries returns every billing ledger entry of a ride in the order they were recorded,
+// itemizing the fare segments, surcharges and discounts that make up its cost. A ride without
+// entries yields an empty slice.
+func (r *RideRepo) GetBillingEntries(ctx context.Context, rideID string) ([]*models.BillingLedger, error) {
+	query := `
+		SELECT entry_id, ride_id, category, description, distance, cost, created_at
+		FROM billing_ledger
+		WHERE ride_id = $1
+		ORDER BY created_at, entry_id
+	`
+
+	entries := []*models.BillingLedger{}
+	if err := r.db.SelectContext(ctx, &entries, query, rideID); err != nil {
+		return nil, fmt.Errorf("failed to get billing entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// GetRideFare returns the fare terms and trip estimate a ride was accepted under. Rides created
+// before fares were recorded yield an error wrapping sql.ErrNoRows.
+func (r *RideRepo) GetRideFare(ctx context.Context, rideID string) (*models.RideFare, error) {
+	query := `
+		SELECT ride_id, region, rate_per_km, estimated_fare, estimated_distance_km, created_at
+		FROM ride_fares
+		WHERE ride_id = $1
+	`
+
+	var fare models.RideFare
+	if err := r.db.GetContext(ctx, &fare, query, rideID); err != nil {
+		return nil, fmt.Errorf("failed to get ride fare: %w", err)
+	}
+
+	return &fare, nil
+}
+
+// GetPromo returns a promo by its code, or rides.ErrPromoNotFound
+func (r *RideRepo) GetPromo(ctx context.Context, code string) (*models.Promo, error) {
+	query := `
+		SELECT code, discount_type, discount_value, max_uses, used_count, expires_at, created_at
+		FROM promos
+		WHERE code = $1
+	`
+
+	var promo models.Promo
+	err := r.db.GetContext(ctx, &promo, query, code)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", rides.ErrPromoNotFound, code)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo: %w", err)
+	}
+
+	return &promo, nil
+}
+
+// RedeemPromo counts one use of a promo and records its discount ledger entry in the same
+// transaction. The use is only counted while the promo is unexpired and under its usage limit,
+// so concurrent redemptions can never exceed the limit.
+func (r *RideRepo) RedeemPromo(ctx context.Context, code string, entry *models.BillingLedger) error {
+	redeemQuery := `
+		UPDATE promos
+		SET used_count = used_count + 1
+		WHERE code = $1
+			AND (max_uses = 0 OR used_count < max_uses)
+			AND (expires_at IS NULL OR expires_at > NOW())
+	`
+
+	entryQuery := `
+		INSERT INTO billing_ledger (
+			entry_id, ride_id, category, description, distance, cost, created_at
+		) VALUES (
+			$1, $2, $3, $4, $5, $6, $7
+		)
+	`
+
+	if entry.EntryID == uuid.Nil {
+		entry.EntryID = uuid.New()
+	}
+	entry.Category = models.BillingCategoryDiscount
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, redeemQuery, code)
+	if err != nil {
+		return fmt.Errorf("failed to redeem promo: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", rides.ErrPromoExhausted, code)
+	}
+
+	_, err = tx.ExecContext(
+		ctx,
+		entryQuery,
+		entry.EntryID,
+		entry.RideID,
+		entry.Category,
+		entry.Description,
+		entry.Distance,
+		entry.Cost,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add discount entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// CreatePayment creates a payment record for a ride, audits its initial status and finalizes the
+// ride's billing ledger at the charged fare
+func (r *RideRepo) CreatePayment(ctx context.Context, payment *models.Payment, actor string) error {
 	query := `
 		INSERT INTO payments (
 			payment_id, ride_id, adjusted_cost, admin_fee, driver_payout, status, created_at
@@ -223,7 +521,14 @@ func (r *RideRepo) CreatePayment(ctx context.Context, payment *models.Payment) e
 		payment.PaymentID = uuid.New()
 	}
 
-	_, err := r.db.ExecContext(
+	// Begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(
 		ctx,
 		query,
 		payment.PaymentID,
@@ -239,23 +544,44 @@ func (r *RideRepo) CreatePayment(ctx context.Context, payment *models.Payment) e
 		return fmt.Errorf("failed to create payment: %w", err)
 	}
 
+	if err := insertPaymentAudit(ctx, tx, payment, "", payment.Status, actor); err != nil {
+		return err
+	}
+
+	// The fare is charged, so later location updates must not keep billing the ride while the
+	// passenger pays. Updating the row also waits out any billing entry being added under its share lock.
+	freezeQuery := `UPDATE rides SET ledger_finalized = TRUE, updated_at = NOW() WHERE ride_id = $1`
+	if _, err := tx.ExecContext(ctx, freezeQuery, payment.RideID); err != nil {
+		return fmt.Errorf("failed to finalize billing ledger: %w", err)
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.invalidateCachedPayment(ctx, payment.RideID.String())
 	return nil
 }
 
-// UpdateRideStatus updates the status of a ride
-func (r *RideRepo) UpdateRideStatus(ctx context.Context, rideID string, status models.RideStatus) error {
+// UpdateRideStatus moves a ride from status from to status. The first move to ongoing records when the
+// ride started. A ride no longer in status from, such as one started or cancelled concurrently, is left
+// alone and rides.ErrRideStatusChanged is returned.
+func (r *RideRepo) UpdateRideStatus(ctx context.Context, rideID string, from, status models.RideStatus) error {
 	logger.Info("Updating ride status",
 		logger.String("ride_id", rideID),
+		logger.String("from_status", string(from)),
 		logger.String("new_status", string(status)))
 
 	query := `
 		UPDATE rides
 		SET status = $1,
+			started_at = CASE WHEN $1 = 'ONGOING' THEN COALESCE(started_at, NOW()) ELSE started_at END,
 			updated_at = NOW()
-		WHERE ride_id = $2
+		WHERE ride_id = $2 AND status = $3
 	`
 
-	result, err := r.db.ExecContext(ctx, query, status, rideID)
+	result, err := r.db.ExecContext(ctx, query, status, rideID, from)
 	if err != nil {
 		logger.Error("Failed to update ride status in database",
 			logger.String("ride_id", rideID),
@@ -273,10 +599,11 @@ func (r *RideRepo) UpdateRideStatus(ctx context.Context, rideID string, status m
 	}
 
 	if rows == 0 {
-		logger.Error("No rows affected - ride not found for status update",
+		logger.Warn("No rows affected - ride missing or no longer in the expected status",
 			logger.String("ride_id", rideID),
+			logger.String("from_status", string(from)),
 			logger.String("new_status", string(status)))
-		return fmt.Errorf("ride not found: %s", rideID)
+		return fmt.Errorf("%w: ride %s is no longer %s", rides.ErrRideStatusChanged, rideID, from)
 	}
 
 	logger.Info("Successfully updated ride status",
@@ -287,7 +614,111 @@ func (r *RideRepo) UpdateRideStatus(ctx context.Context, rideID string, status m
 	return nil
 }
 
-// GetPaymentByRideID retrieves payment information for a specific ride
+// ListOverdueRides returns up to limit ongoing rides that started at or before startedBefore and have
+// no payment yet. Rides started before start times were recorded fall back to their last update.
+func (r *RideRepo) ListOverdueRides(ctx context.Context, startedBefore time.Time, limit int) ([]*models.Ride, error) {
+	query := `
+		SELECT ride_id, match_id, driver_id, passenger_id, status, total_cost,
+			pickup_latitude, pickup_longitude, pickup_eta_seconds, payment_method, colocated_since,
+			driver_arrived_at, started_at, ledger_finalized, final_total, created_at, updated_at
+		FROM rides
+		WHERE status = $1
+			AND COALESCE(started_at, updated_at) <= $2
+			AND NOT EXISTS (SELECT 1 FROM payments WHERE payments.ride_id = rides.ride_id)
+		ORDER BY COALESCE(started_at, updated_at)
+		LIMIT $3
+	`
+
+	var overdue []*models.Ride
+	if err := r.db.SelectContext(ctx, &overdue, query, models.RideStatusOngoing, startedBefore, limit); err != nil {
+		return nil, fmt.Errorf("failed to list overdue rides: %w", err)
+	}
+	return overdue, nil
+}
+
+// UpdatePickupETA stores a recomputed pickup ETA while the driver is still on the way
+func (r *RideRepo) UpdatePickupETA(ctx context.Context, rideID string, etaSeconds int) error {
+	query := `
+		UPDATE rides
+		SET pickup_eta_seconds = $1,
+			updated_at = NOW()
+		WHERE ride_id = $2 AND status = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, etaSeconds, rideID, models.RideStatusDriverPickup)
+	if err != nil {
+		return fmt.Errorf("failed to update pickup ETA: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("ride not awaiting pickup: %s", rideID)
+	}
+
+	return nil
+}
+
+// UpdateColocatedSince records when the driver was first seen at the pickup point, or clears it
+// when the driver moved away again. Only rides still awaiting pickup are updated.
+func (r *RideRepo) UpdateColocatedSince(ctx context.Context, rideID string, since *time.Time) error {
+	query := `
+		UPDATE rides
+		SET colocated_since = $1,
+			updated_at = NOW()
+		WHERE ride_id = $2 AND status = $3
+	`
+
+	result, err := r.db.ExecContext(ctx, query, since, rideID, models.RideStatusDriverPickup)
+	if err != nil {
+		return fmt.Errorf("failed to update co-location time: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("ride not awaiting pickup: %s", rideID)
+	}
+
+	return nil
+}
+
+// MarkDriverArrived moves a ride awaiting pickup to waiting for the passenger and records when
+// the driver arrived. Only rides still awaiting pickup are updated.
+func (r *RideRepo) MarkDriverArrived(ctx context.Context, rideID string, arrivedAt time.Time) error {
+	query := `
+		UPDATE rides
+		SET status = $1,
+			driver_arrived_at = $2,
+			updated_at = NOW()
+		WHERE ride_id = $3 AND status = $4
+	`
+
+	result, err := r.db.ExecContext(ctx, query, models.RideStatusDriverArrived, arrivedAt, rideID, models.RideStatusDriverPickup)
+	if err != nil {
+		return fmt.Errorf("failed to mark driver arrived: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("ride not awaiting pickup: %s", rideID)
+	}
+
+	return nil
+}
+
+// GetPaymentByRideID retrieves payment information for a specific ride. Records are served from
+// a short-lived cache when possible so retried payment attempts do not each hit the database.
 func (r *RideRepo) GetPaymentByRideID(ctx context.Context, rideID string) (*models.Payment, error) {
 	var payment models.Payment
 	rideIDUUID, err := uuid.Parse(rideID)
@@ -295,6 +726,10 @@ func (r *RideRepo) GetPaymentByRideID(ctx context.Context, rideID string) (*mode
 		return nil, fmt.Errorf("invalid ride ID format: %w", err)
 	}
 
+	if cached := r.getCachedPayment(ctx, rideID); cached != nil {
+		return cached, nil
+	}
+
 	query := `
 		SELECT payment_id, ride_id, adjusted_cost, admin_fee, driver_payout, status, created_at
 		FROM payments
@@ -306,26 +741,104 @@ func (r *RideRepo) GetPaymentByRideID(ctx context.Context, rideID string) (*mode
 		return nil, fmt.Errorf("failed to get payment for ride %s: %w", rideID, err)
 	}
 
+	r.cachePayment(ctx, &payment)
 	return &payment, nil
 }
 
-// UpdatePaymentStatus updates the status of a payment
-func (r *RideRepo) UpdatePaymentStatus(ctx context.Context, paymentID string, status models.PaymentStatus) error {
+// UpdatePaymentStatus moves a payment from its current status to the given one and audits
+// the transition in the same transaction. The update only applies if the payment still has
+// the status the caller read, so concurrent transitions cannot both be recorded.
+func (r *RideRepo) UpdatePaymentStatus(ctx context.Context, payment *models.Payment, status models.PaymentStatus, actor string) error {
 	query := `
 		UPDATE payments
 		SET status = $1
-		WHERE payment_id = $2
+		WHERE payment_id = $2 AND status = $3
 	`
 
-	paymentUUID, err := uuid.Parse(paymentID)
+	// Begin transaction
+	tx, err := r.db.BeginTxx(ctx, nil)
 	if err != nil {
-		return fmt.Errorf("invalid payment ID format: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback()
 
-	_, err = r.db.ExecContext(ctx, query, status, paymentUUID)
+	result, err := tx.ExecContext(ctx, query, status, payment.PaymentID, payment.Status)
 	if err != nil {
 		return fmt.Errorf("failed to update payment status: %w", err)
 	}
 
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		// The caller read a stale status, possibly from the cache, so the next read goes to the database
+		r.invalidateCachedPayment(ctx, payment.RideID.String())
+		return fmt.Errorf("payment %s is no longer %s", payment.PaymentID, payment.Status)
+	}
+
+	if err := insertPaymentAudit(ctx, tx, payment, payment.Status, status, actor); err != nil {
+		return err
+	}
+
+	// Commit transaction
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	r.invalidateCachedPayment(ctx, payment.RideID.String())
+	return nil
+}
+
+// GetPaymentAuditTrail returns every payment status transition of a ride, oldest first
+func (r *RideRepo) GetPaymentAuditTrail(ctx context.Context, rideID string) ([]*models.PaymentAudit, error) {
+	rideIDUUID, err := uuid.Parse(rideID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ride ID format: %w", err)
+	}
+
+	query := `
+		SELECT audit_id, payment_id, ride_id, COALESCE(from_status, '') AS from_status,
+			to_status, actor, amount, created_at
+		FROM payment_audit
+		WHERE ride_id = $1
+		ORDER BY created_at ASC
+	`
+
+	audits := []*models.PaymentAudit{}
+	if err := r.db.SelectContext(ctx, &audits, query, rideIDUUID); err != nil {
+		return nil, fmt.Errorf("failed to get payment audit trail for ride %s: %w", rideID, err)
+	}
+
+	return audits, nil
+}
+
+// insertPaymentAudit records a payment status transition within the caller's transaction
+func insertPaymentAudit(ctx context.Context, tx *sqlx.Tx, payment *models.Payment, from, to models.PaymentStatus, actor string) error {
+	query := `
+		INSERT INTO payment_audit (
+			audit_id, payment_id, ride_id, from_status, to_status, actor, amount, created_at
+		) VALUES (
+			$1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8
+		)
+	`
+
+	_, err := tx.ExecContext(
+		ctx,
+		query,
+		uuid.New(),
+		payment.PaymentID,
+		payment.RideID,
+		string(from),
+		to,
+		actor,
+		payment.AdjustedCost,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to write payment audit: %w", err)
+	}
+
 	return nil
 }
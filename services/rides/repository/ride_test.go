@@ -2,6 +2,7 @@ package repository_test
 
 import (
 	"context"
+	"database/sql"
 	"regexp"
 	"testing"
 	"time"
@@ -9,9 +10,12 @@ import (
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
 	"github.com/piresc/nebengjek/services/rides/repository"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func setupMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
@@ -23,7 +27,7 @@ func setupMockDB(t *testing.T) (*sqlx.DB, sqlmock.Sqlmock) {
 
 func TestCreateRide_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := uuid.New()
 	matchID := uuid.New()
@@ -31,7 +35,8 @@ func TestCreateRide_Success(t *testing.T) {
 
 	// Expect insert
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO rides")).
-		WithArgs(r.RideID, r.MatchID, r.DriverID, r.PassengerID, r.Status, r.TotalCost, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(r.RideID, r.MatchID, r.DriverID, r.PassengerID, r.Status, r.TotalCost,
+			r.PickupLatitude, r.PickupLongitude, r.PickupETASeconds, r.PaymentMethod, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	created, err := repo.CreateRide(r)
@@ -42,22 +47,22 @@ func TestCreateRide_Success(t *testing.T) {
 
 func TestUpdateTotalCost_NoRows(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := "abc"
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE rides")).
 		WithArgs(100, rideID).
-		WillReturnResult(sqlmock.NewResult(0, 0))
+		WillReturnRows(sqlmock.NewRows([]string{"total_cost"}))
 
-	err := repo.UpdateTotalCost(context.Background(), rideID, 100)
+	_, err := repo.UpdateTotalCost(context.Background(), rideID, 100)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "ride not found")
 }
 
 func TestGetRide_Error(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT ride_id, driver_id, passenger_id")).
 		WithArgs("id").
@@ -69,22 +74,43 @@ func TestGetRide_Error(t *testing.T) {
 
 func TestCompleteRide_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
-	ride := &models.Ride{RideID: uuid.New()}
+	ride := &models.Ride{RideID: uuid.New(), FinalTotal: 27500}
 
-	// Expect update marking ride as completed
+	// Expect update marking ride as completed and its ledger finalized once its payment is accepted
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
-		WithArgs(models.RideStatusCompleted, ride.RideID).
+		WithArgs(models.RideStatusCompleted, ride.RideID, models.PaymentStatusAccepted, models.PaymentStatusProcessed, ride.FinalTotal).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	err := repo.CompleteRide(context.Background(), ride)
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCompleteRide_PendingPayment(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	ride := &models.Ride{RideID: uuid.New()}
+
+	// The payment guard leaves the ride untouched
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(models.RideStatusCompleted, ride.RideID, models.PaymentStatusAccepted, models.PaymentStatusProcessed, ride.FinalTotal).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM payments")).
+		WithArgs(ride.RideID).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow(models.PaymentStatusPending))
+
+	err := repo.CompleteRide(context.Background(), ride)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "payment status PENDING")
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestGetBillingLedgerSum_Sum(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	mock.ExpectQuery(regexp.QuoteMeta("SELECT SUM(cost)")).
 		WithArgs("id").
@@ -97,82 +123,273 @@ func TestGetBillingLedgerSum_Sum(t *testing.T) {
 
 func TestCreatePayment_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	pay := &models.Payment{PaymentID: uuid.New(), RideID: uuid.New(), AdjustedCost: 1000, AdminFee: 50, DriverPayout: 950, Status: models.PaymentStatusPending}
 
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO payments")).
 		WithArgs(pay.PaymentID, pay.RideID, pay.AdjustedCost, pay.AdminFee, pay.DriverPayout, pay.Status, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO payment_audit")).
+		WithArgs(sqlmock.AnyArg(), pay.PaymentID, pay.RideID, "", models.PaymentStatusPending, "driver:d1", pay.AdjustedCost, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// Charging the fare stops the ride from being billed further while the passenger pays
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides SET ledger_finalized = TRUE")).
+		WithArgs(pay.RideID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
-	err := repo.CreatePayment(context.Background(), pay)
+	err := repo.CreatePayment(context.Background(), pay, "driver:d1")
 	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestAddBillingEntry_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	entry := &models.BillingLedger{EntryID: uuid.New(), RideID: uuid.New(), Distance: 2.5, Cost: 7500}
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT ledger_finalized FROM rides")).
+		WithArgs(entry.RideID).
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_finalized"}).AddRow(false))
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO billing_ledger")).
-		WithArgs(entry.EntryID, entry.RideID, entry.Distance, entry.Cost, sqlmock.AnyArg()).
+		WithArgs(entry.EntryID, entry.RideID, models.BillingCategoryDistance, "", entry.Distance, entry.Cost, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
 
 	err := repo.AddBillingEntry(context.Background(), entry)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestAddBillingEntry_Surcharge(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	entry := &models.BillingLedger{
+		EntryID:     uuid.New(),
+		RideID:      uuid.New(),
+		Category:    models.BillingCategorySurcharge,
+		Description: "toll",
+		Cost:        10000,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT ledger_finalized FROM rides")).
+		WithArgs(entry.RideID).
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_finalized"}).AddRow(false))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO billing_ledger")).
+		WithArgs(entry.EntryID, entry.RideID, models.BillingCategorySurcharge, "toll", 0.0, 10000, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.AddBillingEntry(context.Background(), entry)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListBillingEntries(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New()
+	rows := sqlmock.NewRows([]string{"entry_id", "ride_id", "category", "description", "distance", "cost", "created_at"}).
+		AddRow(uuid.New(), rideID, models.BillingCategorySurcharge, "toll", 0.0, 10000, time.Now()).
+		AddRow(uuid.New(), rideID, models.BillingCategorySurcharge, "airport", 0.0, 15000, time.Now())
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM billing_ledger")).
+		WithArgs(rideID.String(), models.BillingCategorySurcharge).
+		WillReturnRows(rows)
+
+	entries, err := repo.ListBillingEntries(context.Background(), rideID.String(), models.BillingCategorySurcharge)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "toll", entries[0].Description)
+	assert.Equal(t, 15000, entries[1].Cost)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBillingEntries(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New()
+	startedAt := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	firstID, secondID, tollID, promoID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	rows := sqlmock.NewRows([]string{"entry_id", "ride_id", "category", "description", "distance", "cost", "created_at"}).
+		AddRow(firstID, rideID, models.BillingCategoryDistance, "", 1.5, 4500, startedAt).
+		AddRow(secondID, rideID, models.BillingCategoryDistance, "", 2.0, 6000, startedAt.Add(time.Minute)).
+		AddRow(tollID, rideID, models.BillingCategorySurcharge, "toll", 0.0, 10000, startedAt.Add(2*time.Minute)).
+		AddRow(promoID, rideID, models.BillingCategoryDiscount, "HEMAT10", 0.0, -2000, startedAt.Add(3*time.Minute))
+
+	mock.ExpectQuery(regexp.QuoteMeta("ORDER BY created_at, entry_id")).
+		WithArgs(rideID.String()).
+		WillReturnRows(rows)
+
+	entries, err := repo.GetBillingEntries(context.Background(), rideID.String())
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	assert.Equal(t, firstID, entries[0].EntryID)
+	assert.Equal(t, rideID, entries[0].RideID)
+	assert.Equal(t, models.BillingCategoryDistance, entries[0].Category)
+	assert.Equal(t, 1.5, entries[0].Distance)
+	assert.Equal(t, 4500, entries[0].Cost)
+	assert.Equal(t, startedAt, entries[0].CreatedAt)
+
+	assert.Equal(t, secondID, entries[1].EntryID)
+	assert.Equal(t, startedAt.Add(time.Minute), entries[1].CreatedAt)
+
+	assert.Equal(t, models.BillingCategorySurcharge, entries[2].Category)
+	assert.Equal(t, "toll", entries[2].Description)
+
+	assert.Equal(t, models.BillingCategoryDiscount, entries[3].Category)
+	assert.Equal(t, "HEMAT10", entries[3].Description)
+	assert.Equal(t, -2000, entries[3].Cost)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBillingEntries_NoEntries(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+	mock.ExpectQuery(regexp.QuoteMeta("FROM billing_ledger")).
+		WithArgs(rideID).
+		WillReturnRows(sqlmock.NewRows([]string{"entry_id", "ride_id", "category", "description", "distance", "cost", "created_at"}))
+
+	entries, err := repo.GetBillingEntries(context.Background(), rideID)
+	require.NoError(t, err)
+	assert.NotNil(t, entries)
+	assert.Empty(t, entries)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRideFare(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New()
+	createdAt := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"ride_id", "region", "rate_per_km", "estimated_fare", "estimated_distance_km", "created_at"}).
+		AddRow(rideID, "jakarta", 3500.0, 17500, 5.0, createdAt)
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ride_fares")).
+		WithArgs(rideID.String()).
+		WillReturnRows(rows)
+
+	fare, err := repo.GetRideFare(context.Background(), rideID.String())
+	require.NoError(t, err)
+	assert.Equal(t, &models.RideFare{
+		RideID:              rideID,
+		Region:              "jakarta",
+		RatePerKm:           3500,
+		EstimatedFare:       17500,
+		EstimatedDistanceKm: 5,
+		CreatedAt:           createdAt,
+	}, fare)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetRideFare_NotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM ride_fares")).
+		WillReturnError(sql.ErrNoRows)
+
+	fare, err := repo.GetRideFare(context.Background(), uuid.New().String())
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.Nil(t, fare)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetBillingEntries_Error(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM billing_ledger")).
+		WillReturnError(assert.AnError)
+
+	_, err := repo.GetBillingEntries(context.Background(), uuid.New().String())
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get billing entries")
+}
+
 func TestAddBillingEntry_Error(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	entry := &models.BillingLedger{RideID: uuid.New(), Distance: 2.5, Cost: 7500}
 
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT ledger_finalized FROM rides")).
+		WithArgs(entry.RideID).
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_finalized"}).AddRow(false))
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO billing_ledger")).
 		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
 
 	err := repo.AddBillingEntry(context.Background(), entry)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to insert billing entry")
 }
 
+func TestAddBillingEntry_LedgerFinalized(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	entry := &models.BillingLedger{RideID: uuid.New(), Distance: 2.5, Cost: 7500}
+
+	// The ride completed, so nothing is written to its ledger
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT ledger_finalized FROM rides")).
+		WithArgs(entry.RideID).
+		WillReturnRows(sqlmock.NewRows([]string{"ledger_finalized"}).AddRow(true))
+	mock.ExpectRollback()
+
+	err := repo.AddBillingEntry(context.Background(), entry)
+	assert.ErrorIs(t, err, rides.ErrLedgerFinalized)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateRideStatus_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := uuid.New().String()
 	status := models.RideStatusOngoing
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
-		WithArgs(status, rideID).
+	mock.ExpectExec(regexp.QuoteMeta("WHERE ride_id = $2 AND status = $3")).
+		WithArgs(status, rideID, models.RideStatusDriverPickup).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err := repo.UpdateRideStatus(context.Background(), rideID, status)
+	err := repo.UpdateRideStatus(context.Background(), rideID, models.RideStatusDriverPickup, status)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUpdateRideStatus_NotFound(t *testing.T) {
+func TestUpdateRideStatus_StatusChanged(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := uuid.New().String()
 	status := models.RideStatusOngoing
 
+	// The ride was started by someone else after it was read
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
-		WithArgs(status, rideID).
+		WithArgs(status, rideID, models.RideStatusDriverPickup).
 		WillReturnResult(sqlmock.NewResult(0, 0))
 
-	err := repo.UpdateRideStatus(context.Background(), rideID, status)
-	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "ride not found")
+	err := repo.UpdateRideStatus(context.Background(), rideID, models.RideStatusDriverPickup, status)
+	assert.ErrorIs(t, err, rides.ErrRideStatusChanged)
 }
 
 func TestGetPaymentByRideID_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := uuid.New().String()
 	paymentID := uuid.New()
@@ -197,7 +414,7 @@ func TestGetPaymentByRideID_Success(t *testing.T) {
 
 func TestGetPaymentByRideID_NotFound(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := uuid.New().String()
 	rideUUID := uuid.MustParse(rideID)
@@ -212,38 +429,112 @@ func TestGetPaymentByRideID_NotFound(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to get payment for ride")
 }
 
-func TestUpdatePaymentStatus_Success(t *testing.T) {
+func TestUpdatePaymentStatus_WritesAudit(t *testing.T) {
+	tests := []struct {
+		name string
+		to   models.PaymentStatus
+	}{
+		{name: "pending to accepted", to: models.PaymentStatusAccepted},
+		{name: "pending to rejected", to: models.PaymentStatusRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock := setupMockDB(t)
+			repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+			payment := &models.Payment{PaymentID: uuid.New(), RideID: uuid.New(), AdjustedCost: 8000, Status: models.PaymentStatusPending}
+			actor := "passenger:" + uuid.New().String()
+
+			mock.ExpectBegin()
+			mock.ExpectExec(regexp.QuoteMeta("UPDATE payments")).
+				WithArgs(tt.to, payment.PaymentID, models.PaymentStatusPending).
+				WillReturnResult(sqlmock.NewResult(0, 1))
+			mock.ExpectExec(regexp.QuoteMeta("INSERT INTO payment_audit")).
+				WithArgs(sqlmock.AnyArg(), payment.PaymentID, payment.RideID, string(models.PaymentStatusPending), tt.to, actor, 8000, sqlmock.AnyArg()).
+				WillReturnResult(sqlmock.NewResult(1, 1))
+			mock.ExpectCommit()
+
+			err := repo.UpdatePaymentStatus(context.Background(), payment, tt.to, actor)
+			assert.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestUpdatePaymentStatus_AlreadyTransitioned(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
-	paymentID := uuid.New().String()
-	paymentUUID := uuid.MustParse(paymentID)
-	status := models.PaymentStatusAccepted
+	payment := &models.Payment{PaymentID: uuid.New(), RideID: uuid.New(), AdjustedCost: 8000, Status: models.PaymentStatusPending}
 
+	// No audit row is written when the payment has already left the expected status
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE payments")).
-		WithArgs(status, paymentUUID).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WithArgs(models.PaymentStatusAccepted, payment.PaymentID, models.PaymentStatusPending).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
 
-	err := repo.UpdatePaymentStatus(context.Background(), paymentID, status)
-	assert.NoError(t, err)
+	err := repo.UpdatePaymentStatus(context.Background(), payment, models.PaymentStatusAccepted, "passenger:p1")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "is no longer PENDING")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUpdatePaymentStatus_InvalidID(t *testing.T) {
-	db, _ := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+func TestUpdatePaymentStatus_AuditFailureRollsBack(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
-	invalidPaymentID := "invalid-uuid"
-	status := models.PaymentStatusAccepted
+	payment := &models.Payment{PaymentID: uuid.New(), RideID: uuid.New(), AdjustedCost: 8000, Status: models.PaymentStatusPending}
 
-	err := repo.UpdatePaymentStatus(context.Background(), invalidPaymentID, status)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE payments")).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO payment_audit")).
+		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
+
+	err := repo.UpdatePaymentStatus(context.Background(), payment, models.PaymentStatusAccepted, "passenger:p1")
 	assert.Error(t, err)
-	assert.Contains(t, err.Error(), "invalid payment ID format")
+	assert.Contains(t, err.Error(), "failed to write payment audit")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPaymentAuditTrail_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New()
+	paymentID := uuid.New()
+	rows := sqlmock.NewRows([]string{"audit_id", "payment_id", "ride_id", "from_status", "to_status", "actor", "amount", "created_at"}).
+		AddRow(uuid.New(), paymentID, rideID, "", models.PaymentStatusPending, "driver:d1", 8000, time.Now()).
+		AddRow(uuid.New(), paymentID, rideID, models.PaymentStatusPending, models.PaymentStatusAccepted, "passenger:p1", 8000, time.Now())
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM payment_audit")).
+		WithArgs(rideID).
+		WillReturnRows(rows)
+
+	audits, err := repo.GetPaymentAuditTrail(context.Background(), rideID.String())
+	assert.NoError(t, err)
+	assert.Len(t, audits, 2)
+	assert.Equal(t, models.PaymentStatus(""), audits[0].FromStatus)
+	assert.Equal(t, models.PaymentStatusPending, audits[1].FromStatus)
+	assert.Equal(t, models.PaymentStatusAccepted, audits[1].ToStatus)
+	assert.Equal(t, "passenger:p1", audits[1].Actor)
+}
+
+func TestGetPaymentAuditTrail_InvalidID(t *testing.T) {
+	db, _ := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	_, err := repo.GetPaymentAuditTrail(context.Background(), "invalid-uuid")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ride ID format")
 }
 
 func TestGetRide_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := uuid.New().String()
 	rideUUID := uuid.MustParse(rideID)
@@ -273,29 +564,33 @@ func TestGetRide_Success(t *testing.T) {
 
 func TestUpdateTotalCost_Success(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := uuid.New().String()
 	additionalCost := 500
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+	mock.ExpectQuery(regexp.QuoteMeta("UPDATE rides")).
 		WithArgs(additionalCost, rideID).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnRows(sqlmock.NewRows([]string{"total_cost"}).AddRow(2500))
 
-	err := repo.UpdateTotalCost(context.Background(), rideID, additionalCost)
+	total, err := repo.UpdateTotalCost(context.Background(), rideID, additionalCost)
 	assert.NoError(t, err)
+	assert.Equal(t, 2500, total)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
 func TestCompleteRide_NotFound(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	ride := &models.Ride{RideID: uuid.New()}
 
 	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
-		WithArgs(models.RideStatusCompleted, ride.RideID).
+		WithArgs(models.RideStatusCompleted, ride.RideID, models.PaymentStatusAccepted, models.PaymentStatusProcessed, ride.FinalTotal).
 		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(regexp.QuoteMeta("SELECT status FROM payments")).
+		WithArgs(ride.RideID).
+		WillReturnError(sql.ErrNoRows)
 
 	err := repo.CompleteRide(context.Background(), ride)
 	assert.Error(t, err)
@@ -304,7 +599,7 @@ func TestCompleteRide_NotFound(t *testing.T) {
 
 func TestGetBillingLedgerSum_NoEntries(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	rideID := "test-ride-id"
 
@@ -319,21 +614,23 @@ func TestGetBillingLedgerSum_NoEntries(t *testing.T) {
 
 func TestCreatePayment_Error(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	payment := &models.Payment{RideID: uuid.New(), AdjustedCost: 1000, AdminFee: 50, DriverPayout: 950}
 
+	mock.ExpectBegin()
 	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO payments")).
 		WillReturnError(assert.AnError)
+	mock.ExpectRollback()
 
-	err := repo.CreatePayment(context.Background(), payment)
+	err := repo.CreatePayment(context.Background(), payment, "driver:d1")
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to create payment")
 }
 
 func TestCreateRide_Error(t *testing.T) {
 	db, mock := setupMockDB(t)
-	repo := repository.NewRideRepository(&models.Config{}, db)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
 
 	ride := &models.Ride{MatchID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New(), Status: models.RideStatusPending}
 
@@ -344,3 +641,234 @@ func TestCreateRide_Error(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, created)
 }
+
+func TestUpdatePickupETA_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(240, rideID, models.RideStatusDriverPickup).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdatePickupETA(context.Background(), rideID, 240)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePickupETA_NotAwaitingPickup(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(240, rideID, models.RideStatusDriverPickup).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdatePickupETA(context.Background(), rideID, 240)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ride not awaiting pickup")
+}
+
+func TestUpdateColocatedSince_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+	since := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(&since, rideID, models.RideStatusDriverPickup).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.UpdateColocatedSince(context.Background(), rideID, &since)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateColocatedSince_NotAwaitingPickup(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(nil, rideID, models.RideStatusDriverPickup).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.UpdateColocatedSince(context.Background(), rideID, nil)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ride not awaiting pickup")
+}
+
+func TestCancelRide_RecordsCancellation(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	cancellation := &models.RideCancellation{
+		CancellationID: uuid.New(),
+		RideID:         uuid.New(),
+		CancelledBy:    uuid.New(),
+		Role:           models.CancelledByDriver,
+		Fee:            5000,
+		Reason:         "vehicle broke down",
+	}
+	event := &models.OutboxEvent{EventID: uuid.New(), Subject: constants.SubjectRideCancelled, Payload: []byte(`{}`)}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(models.RideStatusCancelled, cancellation.RideID, models.RideStatusPending, models.RideStatusDriverPickup, models.RideStatusDriverArrived).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO ride_cancellations")).
+		WithArgs(cancellation.CancellationID, cancellation.RideID, cancellation.CancelledBy,
+			models.CancelledByDriver, 5000, "vehicle broke down", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	// The cancelled event is stored with the cancellation so the relay can publish it later
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO outbox_events")).
+		WithArgs(event.EventID, cancellation.RideID, constants.SubjectRideCancelled, event.Payload, models.OutboxStatusPending, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.CancelRide(context.Background(), cancellation, event)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCancelRide_AlreadyStarted(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	cancellation := &models.RideCancellation{RideID: uuid.New(), CancelledBy: uuid.New(), Role: models.CancelledByPassenger}
+
+	// No cancellation is recorded once the ride has left the pickup phase
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.CancelRide(context.Background(), cancellation, &models.OutboxEvent{})
+	assert.ErrorIs(t, err, rides.ErrRideNotCancellable)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedeemPromo_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	entry := &models.BillingLedger{EntryID: uuid.New(), RideID: uuid.New(), Description: "HEMAT10", Cost: -2800}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE promos")).
+		WithArgs("HEMAT10").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta("INSERT INTO billing_ledger")).
+		WithArgs(entry.EntryID, entry.RideID, models.BillingCategoryDiscount, "HEMAT10", 0.0, -2800, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err := repo.RedeemPromo(context.Background(), "HEMAT10", entry)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedeemPromo_UsageLimitReached(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	entry := &models.BillingLedger{RideID: uuid.New(), Description: "PERTAMA", Cost: -5000}
+
+	// The guarded update matches nothing, so no discount is recorded
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE promos")).
+		WithArgs("PERTAMA").
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	err := repo.RedeemPromo(context.Background(), "PERTAMA", entry)
+	assert.ErrorIs(t, err, rides.ErrPromoExhausted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPromo_NotFound(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	mock.ExpectQuery(regexp.QuoteMeta("FROM promos")).
+		WithArgs("NOPE").
+		WillReturnError(sql.ErrNoRows)
+
+	promo, err := repo.GetPromo(context.Background(), "NOPE")
+	assert.Nil(t, promo)
+	assert.ErrorIs(t, err, rides.ErrPromoNotFound)
+}
+
+func TestMarkDriverArrived_Success(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+	arrivedAt := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(models.RideStatusDriverArrived, arrivedAt, rideID, models.RideStatusDriverPickup).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err := repo.MarkDriverArrived(context.Background(), rideID, arrivedAt)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMarkDriverArrived_NotAwaitingPickup(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+	arrivedAt := time.Now()
+
+	mock.ExpectExec(regexp.QuoteMeta("UPDATE rides")).
+		WithArgs(models.RideStatusDriverArrived, arrivedAt, rideID, models.RideStatusDriverPickup).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.MarkDriverArrived(context.Background(), rideID, arrivedAt)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "ride not awaiting pickup")
+}
+
+func TestListOverdueRides_ReturnsOngoingRidesWithoutPayment(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	cutoff := time.Now().Add(-4 * time.Hour)
+	rideID := uuid.New()
+	startedAt := cutoff.Add(-time.Minute)
+
+	rows := sqlmock.NewRows([]string{"ride_id", "status", "started_at"}).
+		AddRow(rideID, models.RideStatusOngoing, startedAt)
+	mock.ExpectQuery(regexp.QuoteMeta("COALESCE(started_at, updated_at) <= $2")).
+		WithArgs(models.RideStatusOngoing, cutoff, 50).
+		WillReturnRows(rows)
+
+	overdue, err := repo.ListOverdueRides(context.Background(), cutoff, 50)
+
+	require.NoError(t, err)
+	require.Len(t, overdue, 1)
+	assert.Equal(t, rideID, overdue[0].RideID)
+	require.NotNil(t, overdue[0].StartedAt)
+	assert.True(t, startedAt.Equal(*overdue[0].StartedAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateRideStatus_RecordsStartTime(t *testing.T) {
+	db, mock := setupMockDB(t)
+	repo := repository.NewRideRepository(&models.Config{}, db, nil)
+
+	rideID := uuid.New().String()
+	mock.ExpectExec(regexp.QuoteMeta("started_at = CASE WHEN $1 = 'ONGOING' THEN COALESCE(started_at, NOW())")).
+		WithArgs(models.RideStatusOngoing, rideID, models.RideStatusDriverPickup).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	require.NoError(t, repo.UpdateRideStatus(context.Background(), rideID, models.RideStatusDriverPickup, models.RideStatusOngoing))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
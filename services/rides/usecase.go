@@ -2,17 +2,79 @@ package rides
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
+// ErrPromoNotFound is returned when a promo code does not exist
+var ErrPromoNotFound = errors.New("promo code not found")
+
+// ErrPromoExpired is returned when a promo code is past its expiry
+var ErrPromoExpired = errors.New("promo code has expired")
+
+// ErrPromoExhausted is returned when a promo code has reached its usage limit
+var ErrPromoExhausted = errors.New("promo code has reached its usage limit")
+
+// ErrLedgerFinalized is returned when a billing entry is added to a ride whose fare was already charged
+var ErrLedgerFinalized = errors.New("billing ledger is finalized")
+
+// ErrInvalidPickupCode is returned when a ride is started without the passenger's pickup code
+var ErrInvalidPickupCode = errors.New("invalid pickup code")
+
+// ErrPickupCodeExpired is returned when a ride is started after its pickup code expired
+var ErrPickupCodeExpired = errors.New("pickup code has expired")
+
+// ErrPickupCodeLocked is returned when too many wrong pickup codes were entered and the code was invalidated
+var ErrPickupCodeLocked = errors.New("too many wrong pickup codes, the passenger must reissue the code")
+
+// ErrRideNotFound is returned when a ride does not exist
+var ErrRideNotFound = errors.New("ride not found")
+
+// ErrNotRideParticipant is returned when a user acts on a ride they are neither driving nor riding
+var ErrNotRideParticipant = errors.New("user is not part of this ride")
+
+// ErrRideNotCancellable is returned when a ride is cancelled after the trip started or once it ended
+var ErrRideNotCancellable = errors.New("ride can no longer be cancelled")
+
+// ErrNotRidePassenger is returned when a user acts as the passenger of a ride they are not on
+var ErrNotRidePassenger = errors.New("user is not the passenger of this ride")
+
+// ErrRideNotAwaitingPickup is returned when a pickup-only action targets a ride past or before pickup
+var ErrRideNotAwaitingPickup = errors.New("ride is not awaiting pickup")
+
+// ErrNotRideDriver is returned when a user acts as the driver of a ride they are not driving
+var ErrNotRideDriver = errors.New("user is not the driver of this ride")
+
+// ErrDriverTooFarFromPickup is returned when the driver reports arriving while away from the pickup point
+var ErrDriverTooFarFromPickup = errors.New("driver is too far from the pickup point")
+
+// ErrRideStatusChanged is returned when a ride's status changed between reading and updating it,
+// such as a ride auto-started while its driver started it by hand
+var ErrRideStatusChanged = errors.New("ride status changed")
+
+// ErrPickupCodesDisabled is returned when a pickup code is requested while pickup codes are switched off
+var ErrPickupCodesDisabled = errors.New("pickup codes are not enabled")
+
 // RideUC defines the interface for ride business logic
 //
 //go:generate mockgen -destination=mocks/mock_usecase.go -package=mocks github.com/piresc/nebengjek/services/rides RideUC
 type RideUC interface {
 	CreateRide(ctx context.Context, mp models.MatchProposal) error
 	ProcessBillingUpdate(ctx context.Context, rideID string, entry *models.BillingLedger) error
+	AddSurcharge(ctx context.Context, req models.SurchargeRequest) (*models.BillingLedger, error)
+	RefreshPickupETA(ctx context.Context, rideID string, driverLocation models.Location) error
+	AutoStartRide(ctx context.Context, rideID string, driverLocation models.Location) error
+	DriverArrived(ctx context.Context, req models.DriverArrivedRequest) (*models.Ride, error)
 	StartRide(ctx context.Context, req models.RideStartRequest) (*models.Ride, error)
+	GetPickupCode(ctx context.Context, req models.PickupCodeRequest) (*models.PickupCodeResponse, error)
 	RideArrived(ctx context.Context, req models.RideArrivalReq) (*models.PaymentRequest, error)
 	ProcessPayment(ctx context.Context, req models.PaymentProccessRequest) (*models.Payment, error)
+	CancelRide(ctx context.Context, req models.RideCancelRequest) (*models.RideCancellation, error)
+	RelayOutboxEvents(ctx context.Context, limit int) (int, error)
+	RunOutboxRelay(ctx context.Context, interval time.Duration, batchSize int)
+	CompleteOverdueRides(ctx context.Context, now time.Time, limit int) (int, error)
+	RunMaxDurationSweep(ctx context.Context, interval time.Duration)
+	ReloadConfig(reloaded *models.Config)
 }
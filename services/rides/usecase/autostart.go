@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/featureflag"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/rides"
+)
+
+const (
+	// defaultAutoStartGraceMeters is used when no auto-start grace distance is configured
+	defaultAutoStartGraceMeters = 30.0
+	// defaultAutoStartGrace is used when no auto-start grace time is configured
+	defaultAutoStartGrace = 30 * time.Second
+)
+
+// autoStartGraceMeters returns the configured co-location distance, falling back to the default
+func (uc *rideUC) autoStartGraceMeters() float64 {
+	if meters := uc.config().Rides.AutoStartGraceMeters; meters > 0 {
+		return meters
+	}
+	return defaultAutoStartGraceMeters
+}
+
+// autoStartGrace returns how long the driver must stay co-located, falling back to the default
+func (uc *rideUC) autoStartGrace() time.Duration {
+	if secs := uc.config().Rides.AutoStartGraceSecs; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultAutoStartGrace
+}
+
+// AutoStartRide starts a ride awaiting pickup, or whose driver already reported arriving, once the driver's location updates keep them within
+// the grace distance of the pickup point for the grace time. The pickup point stands in for the
+// passenger, who waits there. Moving away resets the grace time. Does nothing unless auto-start is
+// enabled, or while pickup codes are required, since the driver has to enter the passenger's code
+// to start the ride. The auto_start feature flag, once set, overrides the config either way.
+func (uc *rideUC) AutoStartRide(ctx context.Context, rideID string, driverLocation models.Location) error {
+	if !uc.flags.EnabledOr(ctx, featureflag.AutoStart, uc.config().Rides.AutoStartEnabled) {
+		return nil
+	}
+	if uc.config().Rides.PickupCodeEnabled {
+		return nil
+	}
+
+	ride, err := uc.getAggregateRide(ctx, rideID)
+	if err != nil {
+		return fmt.Errorf("failed to get ride: %w", err)
+	}
+
+	if ride.Status != models.RideStatusDriverPickup && ride.Status != models.RideStatusDriverArrived {
+		return nil
+	}
+
+	if ride.PickupLatitude == 0 && ride.PickupLongitude == 0 {
+		// Rides created before pickup points were recorded have nothing to measure against
+		return nil
+	}
+
+	distanceMeters := utils.CalculateDistance(
+		utils.GeoPoint{Latitude: driverLocation.Latitude, Longitude: driverLocation.Longitude},
+		utils.GeoPoint{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude},
+	) * 1000
+
+	if distanceMeters > uc.autoStartGraceMeters() {
+		if ride.ColocatedSince == nil {
+			return nil
+		}
+		if err := uc.ridesRepo.UpdateColocatedSince(ctx, rideID, nil); err != nil {
+			return fmt.Errorf("failed to reset co-location: %w", err)
+		}
+		logger.Info("Driver left the pickup point before the ride started",
+			logger.String("ride_id", rideID),
+			logger.Float64("distance_meters", distanceMeters))
+		return nil
+	}
+
+	now := time.Now()
+	if ride.ColocatedSince == nil {
+		if err := uc.ridesRepo.UpdateColocatedSince(ctx, rideID, &now); err != nil {
+			return fmt.Errorf("failed to record co-location: %w", err)
+		}
+		return nil
+	}
+
+	if now.Sub(*ride.ColocatedSince) < uc.autoStartGrace() {
+		return nil
+	}
+
+	// The driver may start the ride by hand meanwhile, so only the status read above is moved on
+	from := ride.Status
+	if err := uc.ridesRepo.UpdateRideStatus(ctx, rideID, from, models.RideStatusOngoing); err != nil {
+		if errors.Is(err, rides.ErrRideStatusChanged) {
+			logger.Info("Ride was started or cancelled before it could start automatically",
+				logger.String("ride_id", rideID))
+			return nil
+		}
+		return fmt.Errorf("failed to update ride status to ongoing: %w", err)
+	}
+	wasWaiting := from == models.RideStatusDriverArrived
+	ride.Status = models.RideStatusOngoing
+
+	// As with a manual start, a failed charge is logged for reconciliation rather than undone
+	if wasWaiting {
+		if err := uc.recordWaitingFee(ctx, ride, now); err != nil {
+			logger.Error("Failed to charge waiting fee",
+				logger.String("ride_id", rideID),
+				logger.ErrorField(err))
+		}
+	}
+
+	if err := uc.ridesGW.PublishRideStarted(ctx, ride); err != nil {
+		return fmt.Errorf("failed to publish ride started: %w", err)
+	}
+
+	logger.Info("Ride started automatically - Driver stayed at the pickup point",
+		logger.String("ride_id", rideID),
+		logger.Float64("distance_meters", distanceMeters),
+		logger.Duration("colocated_for", now.Sub(*ride.ColocatedSince)))
+	return nil
+}
@@ -0,0 +1,261 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/featureflag"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// autoStartConfig enables auto-start within 30 meters after 30 seconds
+func autoStartConfig() *models.Config {
+	return &models.Config{Rides: models.RidesConfig{
+		AutoStartEnabled:     true,
+		AutoStartGraceMeters: 30,
+		AutoStartGraceSecs:   30,
+	}}
+}
+
+// expectColocationTracking serves ride from the repository and stores co-location updates on it
+func expectColocationTracking(repo *mocks.MockRideRepo, ride *models.Ride) {
+	rideID := ride.RideID.String()
+	repo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil).AnyTimes()
+	repo.EXPECT().UpdateColocatedSince(gomock.Any(), rideID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, since *time.Time) error {
+			ride.ColocatedSince = since
+			return nil
+		}).AnyTimes()
+}
+
+func TestAutoStartRide_ConvergingDriverStartsRide(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(autoStartConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newPickupRide(720)
+	rideID := ride.RideID.String()
+	expectColocationTracking(mockRepo, ride)
+
+	// The driver approaches: ~2km, ~200m, then ~10m from the pickup point
+	for _, lat := range []float64{-6.193392, -6.177192, -6.175482} {
+		err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: lat, Longitude: 106.827153})
+		require.NoError(t, err)
+	}
+	require.NotNil(t, ride.ColocatedSince)
+	assert.Equal(t, models.RideStatusDriverPickup, ride.Status)
+
+	// Still co-located before the grace time has passed
+	err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: -6.175437, Longitude: 106.827153})
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusDriverPickup, ride.Status)
+
+	// Once the driver has waited for the grace time the ride starts
+	colocatedSince := time.Now().Add(-31 * time.Second)
+	ride.ColocatedSince = &colocatedSince
+
+	mockRepo.EXPECT().UpdateRideStatus(gomock.Any(), rideID, models.RideStatusDriverPickup, models.RideStatusOngoing).Return(nil)
+	mockGW.EXPECT().PublishRideStarted(gomock.Any(), ride).
+		DoAndReturn(func(_ context.Context, started *models.Ride) error {
+			assert.Equal(t, models.RideStatusOngoing, started.Status)
+			return nil
+		})
+
+	err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: -6.175437, Longitude: 106.827153})
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusOngoing, ride.Status)
+
+	// Later updates leave the started ride alone
+	err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: -6.175437, Longitude: 106.827153})
+	assert.NoError(t, err)
+}
+
+func TestAutoStartRide_DriverArrivedStartsRideAndChargesWaiting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := autoStartConfig()
+	cfg.Rides.WaitingGraceSecs = 180
+	cfg.Rides.WaitingFeePerMinute = 500
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	// The driver reported arriving four and a half minutes ago and has been at the pickup point since
+	ride := newPickupRide(0)
+	ride.Status = models.RideStatusDriverArrived
+	arrivedAt := time.Now().Add(-4*time.Minute - 30*time.Second)
+	ride.DriverArrivedAt = &arrivedAt
+	colocatedSince := time.Now().Add(-time.Minute)
+	ride.ColocatedSince = &colocatedSince
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().UpdateRideStatus(gomock.Any(), rideID, models.RideStatusDriverArrived, models.RideStatusOngoing).Return(nil)
+	mockRepo.EXPECT().AddBillingEntry(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry *models.BillingLedger) error {
+			assert.Equal(t, models.SurchargeTypeWaiting, entry.Description)
+			assert.Equal(t, 1000, entry.Cost)
+			return nil
+		})
+	mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 1000).Return(1000, nil)
+	mockGW.EXPECT().PublishRideStarted(gomock.Any(), ride).Return(nil)
+
+	err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: -6.175437, Longitude: 106.827153})
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusOngoing, ride.Status)
+}
+
+func TestAutoStartRide_LosesRaceToManualStart(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(autoStartConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newPickupRide(0)
+	colocatedSince := time.Now().Add(-time.Minute)
+	ride.ColocatedSince = &colocatedSince
+	rideID := ride.RideID.String()
+
+	// The driver started the ride by hand after it was read, so it is neither updated nor announced again
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().
+		UpdateRideStatus(gomock.Any(), rideID, models.RideStatusDriverPickup, models.RideStatusOngoing).
+		Return(fmt.Errorf("%w: ride %s is no longer PICKUP", rides.ErrRideStatusChanged, rideID))
+
+	err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: -6.175437, Longitude: 106.827153})
+	assert.NoError(t, err)
+}
+
+func TestAutoStartRide_DivergingDriverDoesNotStartRide(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(autoStartConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newPickupRide(0)
+	rideID := ride.RideID.String()
+	expectColocationTracking(mockRepo, ride)
+
+	// The driver passes by the pickup point, ~10m away
+	err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: -6.175482, Longitude: 106.827153})
+	require.NoError(t, err)
+	require.NotNil(t, ride.ColocatedSince)
+
+	// and drives on after the grace time would have passed, ~300m then ~1km away
+	colocatedSince := time.Now().Add(-time.Minute)
+	ride.ColocatedSince = &colocatedSince
+	for _, lat := range []float64{-6.178092, -6.184392} {
+		err = uc.AutoStartRide(context.Background(), rideID, models.Location{Latitude: lat, Longitude: 106.827153})
+		require.NoError(t, err)
+	}
+
+	// No status update or ride started event expected
+	assert.Nil(t, ride.ColocatedSince)
+	assert.Equal(t, models.RideStatusDriverPickup, ride.Status)
+}
+
+func TestAutoStartRide_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	cfg := autoStartConfig()
+	cfg.Rides.AutoStartEnabled = false
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newPickupRide(0)
+
+	// No repository or gateway calls expected
+	err = uc.AutoStartRide(context.Background(), ride.RideID.String(),
+		models.Location{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude})
+	assert.NoError(t, err)
+}
+
+// flagStore keeps feature flags in memory
+type flagStore map[string]string
+
+func (s flagStore) Get(ctx context.Context, key string) (string, error) {
+	value, ok := s[key]
+	if !ok {
+		return "", redis.Nil
+	}
+	return value, nil
+}
+
+func (s flagStore) Set(ctx context.Context, key string, value interface{}, expiration time.Duration) error {
+	s[key] = value.(string)
+	return nil
+}
+
+func TestAutoStartRide_GatedByFeatureFlag(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := autoStartConfig()
+	cfg.Rides.AutoStartEnabled = false
+	flags := featureflag.New(flagStore{}, "rides", time.Minute)
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, flags)
+	require.NoError(t, err)
+
+	ride := newPickupRide(720)
+	rideID := ride.RideID.String()
+	colocated := models.Location{Latitude: -6.175437, Longitude: 106.827153}
+
+	// With the flag off the ride is never looked up
+	require.NoError(t, uc.AutoStartRide(context.Background(), rideID, colocated))
+
+	// Switching the flag on starts tracking co-location
+	require.NoError(t, flags.Set(context.Background(), featureflag.AutoStart, true))
+	expectColocationTracking(mockRepo, ride)
+
+	require.NoError(t, uc.AutoStartRide(context.Background(), rideID, colocated))
+	assert.NotNil(t, ride.ColocatedSince)
+}
+
+func TestAutoStartRide_FeatureFlagOverridesConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cfg := autoStartConfig()
+	store := flagStore{}
+	flags := featureflag.New(store, "rides", time.Minute)
+	require.NoError(t, flags.Set(context.Background(), featureflag.AutoStart, false))
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, flags)
+	require.NoError(t, err)
+
+	ride := newPickupRide(720)
+
+	// Auto-start is on in the config, but the flag switches it off, so the ride is never looked up
+	err = uc.AutoStartRide(context.Background(), ride.RideID.String(),
+		models.Location{Latitude: -6.175437, Longitude: 106.827153})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,107 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+)
+
+// defaultCancellationGrace is used when no cancellation grace window is configured
+const defaultCancellationGrace = 2 * time.Minute
+
+// cancellationGrace returns how long after acceptance a ride can be cancelled without a charge
+func (uc *rideUC) cancellationGrace() time.Duration {
+	if secs := uc.config().Rides.CancellationGraceSecs; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultCancellationGrace
+}
+
+// cancellationFee returns the charge for a cancellation by role at now. Drivers who cancel late
+// are penalised and passengers who cancel late pay the configured fee, which may be zero.
+func (uc *rideUC) cancellationFee(ride *models.Ride, role string, now time.Time) int {
+	if now.Sub(ride.CreatedAt) <= uc.cancellationGrace() {
+		return 0
+	}
+	if role == models.CancelledByDriver {
+		return uc.config().Rides.DriverCancellationPenalty
+	}
+	return uc.config().Rides.PassengerCancellationFee
+}
+
+// CancelRide cancels a ride before the trip starts on behalf of its driver or passenger,
+// charging whoever cancels after the grace window and releasing both users for rematching
+func (uc *rideUC) CancelRide(ctx context.Context, req models.RideCancelRequest) (*models.RideCancellation, error) {
+	ride, err := uc.ridesRepo.GetRide(ctx, req.RideID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ride: %w", err)
+	}
+
+	userID, err := uuid.Parse(req.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID format: %w", err)
+	}
+
+	var role string
+	switch userID {
+	case ride.DriverID:
+		role = models.CancelledByDriver
+	case ride.PassengerID:
+		role = models.CancelledByPassenger
+	default:
+		return nil, fmt.Errorf("%w: user %s, ride %s", rides.ErrNotRideParticipant, req.UserID, req.RideID)
+	}
+
+	if ride.Status != models.RideStatusPending && ride.Status != models.RideStatusDriverPickup &&
+		ride.Status != models.RideStatusDriverArrived {
+		return nil, fmt.Errorf("%w: cannot cancel ride with status: %s", rides.ErrRideNotCancellable, ride.Status)
+	}
+
+	now := time.Now()
+	cancellation := &models.RideCancellation{
+		CancellationID: uuid.New(),
+		RideID:         ride.RideID,
+		CancelledBy:    userID,
+		Role:           role,
+		Fee:            uc.cancellationFee(ride, role, now),
+		Reason:         req.Reason,
+		CreatedAt:      now,
+	}
+
+	// Store the cancelled event alongside the cancellation so both users are released even if
+	// the publish fails
+	cancelledRide := *ride
+	cancelledRide.Status = models.RideStatusCancelled
+	event, err := newRideCancelledOutboxEvent(models.RideCancelled{
+		Ride:         cancelledRide,
+		Cancellation: *cancellation,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.ridesRepo.CancelRide(ctx, cancellation, event); err != nil {
+		return nil, fmt.Errorf("failed to cancel ride: %w", err)
+	}
+
+	logger.Info("Ride cancelled",
+		logger.String("ride_id", req.RideID),
+		logger.String("cancelled_by", role),
+		logger.Int("fee", cancellation.Fee))
+
+	// Releases the ride locks so both users can be matched again. A failed publish leaves the
+	// event pending; the outbox relay retries it
+	if err := uc.publishOutboxEvent(ctx, event); err != nil {
+		logger.Warn("Ride cancelled event left in outbox for relay",
+			logger.String("ride_id", req.RideID),
+			logger.String("event_id", event.EventID.String()),
+			logger.ErrorField(err))
+	}
+
+	return cancellation, nil
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cancellationConfig charges late cancellations after a two minute grace window
+func cancellationConfig() *models.Config {
+	return &models.Config{
+		Rides: models.RidesConfig{
+			CancellationGraceSecs:     120,
+			DriverCancellationPenalty: 5000,
+			PassengerCancellationFee:  2000,
+		},
+	}
+}
+
+// newAcceptedRide returns a ride awaiting pickup that was accepted age ago
+func newAcceptedRide(age time.Duration) *models.Ride {
+	return &models.Ride{
+		RideID:      uuid.New(),
+		MatchID:     uuid.New(),
+		DriverID:    uuid.New(),
+		PassengerID: uuid.New(),
+		Status:      models.RideStatusDriverPickup,
+		CreatedAt:   time.Now().Add(-age),
+	}
+}
+
+func TestCancelRide_Fees(t *testing.T) {
+	tests := []struct {
+		name        string
+		age         time.Duration
+		byDriver    bool
+		expectedFee int
+	}{
+		{name: "driver cancels after grace window is penalised", age: 10 * time.Minute, byDriver: true, expectedFee: 5000},
+		{name: "passenger cancels after grace window pays fee", age: 10 * time.Minute, byDriver: false, expectedFee: 2000},
+		{name: "driver cancels within grace window", age: 30 * time.Second, byDriver: true, expectedFee: 0},
+		{name: "passenger cancels within grace window", age: 30 * time.Second, byDriver: false, expectedFee: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			mockGW := mocks.NewMockRideGW(ctrl)
+			uc, err := NewRideUC(cancellationConfig(), mockRepo, mockGW, nil)
+			require.NoError(t, err)
+
+			ride := newAcceptedRide(tt.age)
+			rideID := ride.RideID.String()
+			userID, role := ride.PassengerID, models.CancelledByPassenger
+			if tt.byDriver {
+				userID, role = ride.DriverID, models.CancelledByDriver
+			}
+
+			mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+			// Both users are released for rematching whoever cancels, through an event stored
+			// with the cancellation
+			mockRepo.EXPECT().CancelRide(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, c *models.RideCancellation, event *models.OutboxEvent) error {
+					assert.Equal(t, ride.RideID, c.RideID)
+					assert.Equal(t, userID, c.CancelledBy)
+					assert.Equal(t, role, c.Role)
+					assert.Equal(t, tt.expectedFee, c.Fee)
+
+					assert.Equal(t, constants.SubjectRideCancelled, event.Subject)
+					var cancelled models.RideCancelled
+					require.NoError(t, json.Unmarshal(event.Payload, &cancelled))
+					assert.Equal(t, models.RideStatusCancelled, cancelled.Ride.Status)
+					assert.Equal(t, ride.DriverID, cancelled.Ride.DriverID)
+					assert.Equal(t, ride.PassengerID, cancelled.Ride.PassengerID)
+					assert.Equal(t, tt.expectedFee, cancelled.Cancellation.Fee)
+					return nil
+				})
+			mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+			mockRepo.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Any()).Return(nil)
+
+			cancellation, err := uc.CancelRide(context.Background(), models.RideCancelRequest{
+				RideID: rideID,
+				UserID: userID.String(),
+				Reason: "changed plans",
+			})
+			require.NoError(t, err)
+			assert.Equal(t, role, cancellation.Role)
+			assert.Equal(t, tt.expectedFee, cancellation.Fee)
+			assert.Equal(t, "changed plans", cancellation.Reason)
+		})
+	}
+}
+
+func TestCancelRide_PassengerFeeDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	cfg := cancellationConfig()
+	cfg.Rides.PassengerCancellationFee = 0
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newAcceptedRide(10 * time.Minute)
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), ride.RideID.String()).Return(ride, nil)
+	mockRepo.EXPECT().CancelRide(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Any()).Return(nil)
+
+	cancellation, err := uc.CancelRide(context.Background(), models.RideCancelRequest{
+		RideID: ride.RideID.String(),
+		UserID: ride.PassengerID.String(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 0, cancellation.Fee)
+}
+
+func TestCancelRide_NotParticipant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(cancellationConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newAcceptedRide(10 * time.Minute)
+	mockRepo.EXPECT().GetRide(gomock.Any(), ride.RideID.String()).Return(ride, nil)
+
+	_, err = uc.CancelRide(context.Background(), models.RideCancelRequest{
+		RideID: ride.RideID.String(),
+		UserID: uuid.New().String(),
+	})
+	assert.ErrorIs(t, err, rides.ErrNotRideParticipant)
+}
+
+func TestCancelRide_AlreadyStarted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(cancellationConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newAcceptedRide(10 * time.Minute)
+	ride.Status = models.RideStatusOngoing
+	mockRepo.EXPECT().GetRide(gomock.Any(), ride.RideID.String()).Return(ride, nil)
+
+	_, err = uc.CancelRide(context.Background(), models.RideCancelRequest{
+		RideID: ride.RideID.String(),
+		UserID: ride.DriverID.String(),
+	})
+	assert.ErrorIs(t, err, rides.ErrRideNotCancellable)
+	assert.Contains(t, err.Error(), "cannot cancel ride with status: ONGOING")
+}
+
+func TestCancelRide_PublishFailureLeavesEventForRelay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(cancellationConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newAcceptedRide(10 * time.Minute)
+	mockRepo.EXPECT().GetRide(gomock.Any(), ride.RideID.String()).Return(ride, nil)
+	var stored *models.OutboxEvent
+	mockRepo.EXPECT().CancelRide(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ *models.RideCancellation, event *models.OutboxEvent) error {
+			stored = event
+			return nil
+		})
+	mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), gomock.Any()).Return(assert.AnError)
+	// The event stays pending so the relay releases both users later
+	mockRepo.EXPECT().MarkOutboxEventFailed(gomock.Any(), gomock.Any(), assert.AnError.Error()).
+		DoAndReturn(func(_ context.Context, eventID uuid.UUID, _ string) error {
+			assert.Equal(t, stored.EventID, eventID)
+			return nil
+		})
+
+	cancellation, err := uc.CancelRide(context.Background(), models.RideCancelRequest{
+		RideID: ride.RideID.String(),
+		UserID: ride.DriverID.String(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 5000, cancellation.Fee)
+}
@@ -0,0 +1,46 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// compareToEstimate sets out how a settled fare differs from the estimate the ride was accepted
+// with. The driver's adjustment only applies to the distance fare, so it shows up in the distance delta.
+func compareToEstimate(fare *models.RideFare, breakdown models.FareBreakdown) *models.FareEstimate {
+	estimate := &models.FareEstimate{
+		EstimatedFare:       fare.EstimatedFare,
+		EstimatedDistanceKm: fare.EstimatedDistanceKm,
+		ActualFare:          breakdown.DistanceCost + breakdown.SurchargeCost - breakdown.Discount,
+		DistanceDelta:       breakdown.DistanceCost - fare.EstimatedFare,
+		Discount:            breakdown.Discount,
+	}
+	for _, surcharge := range breakdown.Surcharges {
+		if surcharge.Type == models.SurchargeTypeWaiting {
+			estimate.WaitingFee += surcharge.Amount
+		} else {
+			estimate.OtherSurcharges += surcharge.Amount
+		}
+	}
+	estimate.Delta = estimate.ActualFare - estimate.EstimatedFare
+	return estimate
+}
+
+// attachEstimate adds the comparison with the ride's estimate to its fare breakdown. Rides accepted
+// without a destination have no estimate. The comparison is informational, so a failed lookup is
+// logged rather than holding up the payment.
+func (uc *rideUC) attachEstimate(ctx context.Context, rideID string, breakdown *models.FareBreakdown) {
+	fare, err := uc.ridesRepo.GetRideFare(ctx, rideID)
+	if err != nil {
+		logger.Warn("Failed to get ride fare estimate",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+		return
+	}
+	if fare.EstimatedFare <= 0 {
+		return
+	}
+	breakdown.Estimate = compareToEstimate(fare, *breakdown)
+}
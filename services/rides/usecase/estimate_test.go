@@ -0,0 +1,185 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectNoEstimate returns the fare of a ride accepted without a destination
+func expectNoEstimate(mockRepo *mocks.MockRideRepo, rideID string) {
+	mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(&models.RideFare{}, nil)
+}
+
+func TestCompareToEstimate(t *testing.T) {
+	fare := &models.RideFare{EstimatedFare: 20000, EstimatedDistanceKm: 5.7}
+
+	t.Run("Longer trip with waiting, a toll and a promo", func(t *testing.T) {
+		estimate := compareToEstimate(fare, models.FareBreakdown{
+			DistanceCost:  24000,
+			SurchargeCost: 13000,
+			Surcharges: []models.SurchargeItem{
+				{Type: models.SurchargeTypeWaiting, Amount: 3000},
+				{Type: "toll", Amount: 10000},
+			},
+			PromoCode: "HEMAT10",
+			Discount:  3700,
+		})
+
+		assert.Equal(t, &models.FareEstimate{
+			EstimatedFare:       20000,
+			EstimatedDistanceKm: 5.7,
+			ActualFare:          33300,
+			Delta:               13300,
+			DistanceDelta:       4000,
+			WaitingFee:          3000,
+			OtherSurcharges:     10000,
+			Discount:            3700,
+		}, estimate)
+		assert.Equal(t, estimate.Delta,
+			estimate.DistanceDelta+estimate.WaitingFee+estimate.OtherSurcharges-estimate.Discount)
+	})
+
+	t.Run("Driver's adjustment brings the fare under the estimate", func(t *testing.T) {
+		estimate := compareToEstimate(fare, models.FareBreakdown{DistanceCost: 16000})
+
+		assert.Equal(t, 16000, estimate.ActualFare)
+		assert.Equal(t, -4000, estimate.Delta)
+		assert.Equal(t, -4000, estimate.DistanceDelta)
+		assert.Zero(t, estimate.WaitingFee)
+		assert.Zero(t, estimate.OtherSurcharges)
+	})
+}
+
+func TestRideArrived_ComparesToEstimate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc, err := NewRideUC(surchargeConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	ride := newOngoingRide()
+	rideID := ride.RideID.String()
+
+	// 20000 of distance fare, a 3000 waiting fee and a 10000 toll
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetBillingLedgerSum(gomock.Any(), rideID).Return(33000, nil)
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{
+			{RideID: ride.RideID, Category: models.BillingCategorySurcharge, Description: models.SurchargeTypeWaiting, Cost: 3000},
+			{RideID: ride.RideID, Category: models.BillingCategorySurcharge, Description: "toll", Cost: 10000},
+		}, nil)
+	mockRepo.EXPECT().
+		GetRideFare(gomock.Any(), rideID).
+		Return(&models.RideFare{RideID: ride.RideID, EstimatedFare: 17500, EstimatedDistanceKm: 5}, nil)
+	mockRepo.EXPECT().CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	paymentRequest, err := uc.RideArrived(context.Background(), models.RideArrivalReq{RideID: rideID, AdjustmentFactor: 0.9})
+	require.NoError(t, err)
+
+	// The distance fare of 18000 after the driver's 0.9 runs 500 over the estimate
+	assert.Equal(t, 31000, paymentRequest.TotalCost)
+	assert.Equal(t, &models.FareEstimate{
+		EstimatedFare:       17500,
+		EstimatedDistanceKm: 5,
+		ActualFare:          31000,
+		Delta:               13500,
+		DistanceDelta:       500,
+		WaitingFee:          3000,
+		OtherSurcharges:     10000,
+	}, paymentRequest.Breakdown.Estimate)
+}
+
+func TestRideArrived_EstimateLookupFailureDoesNotBlockPayment(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc, err := NewRideUC(surchargeConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	ride := newOngoingRide()
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetBillingLedgerSum(gomock.Any(), rideID).Return(20000, nil)
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{}, nil)
+	mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(nil, errors.New("connection reset"))
+	mockRepo.EXPECT().CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+	paymentRequest, err := uc.RideArrived(context.Background(), models.RideArrivalReq{RideID: rideID, AdjustmentFactor: 1})
+	require.NoError(t, err)
+
+	assert.Equal(t, 20000, paymentRequest.TotalCost)
+	assert.Nil(t, paymentRequest.Breakdown.Estimate)
+}
+
+func TestProcessPayment_CompletionCarriesEstimate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(surchargeConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newOngoingRide()
+	rideID := ride.RideID.String()
+	payment := &models.Payment{RideID: ride.RideID, AdjustedCost: 26000, Status: models.PaymentStatusPending}
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetPaymentByRideID(gomock.Any(), rideID).Return(payment, nil)
+	mockRepo.EXPECT().UpdatePaymentStatus(gomock.Any(), payment, models.PaymentStatusAccepted, gomock.Any()).Return(nil)
+
+	// The 26000 the passenger pays is a 3000 waiting fee and 25000 of distance fare less a 2000 promo
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{
+			{RideID: ride.RideID, Category: models.BillingCategorySurcharge, Description: models.SurchargeTypeWaiting, Cost: 3000},
+		}, nil)
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategoryDiscount).
+		Return([]*models.BillingLedger{
+			{RideID: ride.RideID, Category: models.BillingCategoryDiscount, Description: "HEMAT", Cost: -2000},
+		}, nil)
+	mockRepo.EXPECT().
+		GetRideFare(gomock.Any(), rideID).
+		Return(&models.RideFare{RideID: ride.RideID, EstimatedFare: 20000, EstimatedDistanceKm: 5.7}, nil)
+	mockRepo.EXPECT().CompleteRide(gomock.Any(), ride).Return(nil)
+
+	var completed models.RideComplete
+	mockGW.EXPECT().
+		PublishRideCompleted(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, rc models.RideComplete) error {
+			completed = rc
+			return nil
+		})
+
+	_, err = uc.ProcessPayment(context.Background(), models.PaymentProccessRequest{
+		RideID: rideID, TotalCost: 26000, Status: models.PaymentStatusAccepted,
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, completed.Breakdown)
+	assert.Equal(t, 25000, completed.Breakdown.DistanceCost)
+	assert.Equal(t, "HEMAT", completed.Breakdown.PromoCode)
+	assert.Equal(t, &models.FareEstimate{
+		EstimatedFare:       20000,
+		EstimatedDistanceKm: 5.7,
+		ActualFare:          26000,
+		Delta:               6000,
+		DistanceDelta:       5000,
+		WaitingFee:          3000,
+		Discount:            2000,
+	}, completed.Breakdown.Estimate)
+}
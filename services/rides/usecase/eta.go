@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+const (
+	// defaultAverageSpeedKmh is used when no average driver speed is configured
+	defaultAverageSpeedKmh = 25.0
+	// defaultETARefreshThreshold is used when no ETA refresh threshold is configured
+	defaultETARefreshThreshold = 60 * time.Second
+)
+
+// ETAEstimator estimates how long a driver needs to cover a distance in kilometers
+type ETAEstimator func(distanceKm float64) time.Duration
+
+// AverageSpeedEstimator returns an ETAEstimator assuming the driver travels at a constant speed
+func AverageSpeedEstimator(speedKmh float64) ETAEstimator {
+	return func(distanceKm float64) time.Duration {
+		if distanceKm <= 0 || speedKmh <= 0 {
+			return 0
+		}
+		return time.Duration(distanceKm / speedKmh * float64(time.Hour))
+	}
+}
+
+// estimatePickupETA returns the seconds until the driver reaches the pickup point,
+// or zero when the driver is already within the pickup tolerance
+func estimatePickupETA(distanceKm, arrivalRadiusKm float64, estimate ETAEstimator) int {
+	if distanceKm <= arrivalRadiusKm {
+		return 0
+	}
+	return int(math.Ceil(estimate(distanceKm).Seconds()))
+}
+
+// pickupETASeconds estimates the driver's arrival at the pickup point from their current location
+func (uc *rideUC) pickupETASeconds(driver, pickup models.Location) int {
+	distanceKm := utils.CalculateDistance(
+		utils.GeoPoint{Latitude: driver.Latitude, Longitude: driver.Longitude},
+		utils.GeoPoint{Latitude: pickup.Latitude, Longitude: pickup.Longitude},
+	)
+	return estimatePickupETA(distanceKm, uc.maxPickupDistanceMeters()/1000, uc.etaEstimator())
+}
+
+// etaEstimator returns an estimator for the configured average speed, falling back to the default
+func (uc *rideUC) etaEstimator() ETAEstimator {
+	speed := uc.config().Rides.AverageSpeedKmh
+	if speed <= 0 {
+		speed = defaultAverageSpeedKmh
+	}
+	return AverageSpeedEstimator(speed)
+}
+
+// etaRefreshThreshold returns the minimum ETA change worth pushing, falling back to the default
+func (uc *rideUC) etaRefreshThreshold() int {
+	if secs := uc.config().Rides.ETARefreshThresholdSecs; secs > 0 {
+		return secs
+	}
+	return int(defaultETARefreshThreshold.Seconds())
+}
+
+// RefreshPickupETA recomputes the pickup ETA from the driver's latest location and pushes it
+// to the passenger when it moved by at least the refresh threshold or the driver has arrived.
+// Rides that are not awaiting pickup are ignored.
+func (uc *rideUC) RefreshPickupETA(ctx context.Context, rideID string, driverLocation models.Location) error {
+	ride, err := uc.getAggregateRide(ctx, rideID)
+	if err != nil {
+		return fmt.Errorf("failed to get ride: %w", err)
+	}
+
+	if ride.Status != models.RideStatusDriverPickup {
+		return nil
+	}
+
+	pickup := models.Location{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude}
+	if pickup.Latitude == 0 && pickup.Longitude == 0 {
+		// Rides created before pickup points were recorded have nothing to measure against
+		return nil
+	}
+
+	eta := uc.pickupETASeconds(driverLocation, pickup)
+	change := eta - ride.PickupETASeconds
+	if change < 0 {
+		change = -change
+	}
+	if change == 0 || (eta > 0 && change < uc.etaRefreshThreshold()) {
+		return nil
+	}
+
+	if err := uc.ridesRepo.UpdatePickupETA(ctx, rideID, eta); err != nil {
+		return fmt.Errorf("failed to update pickup ETA: %w", err)
+	}
+
+	event := &models.RidePickupETAEvent{
+		RideID:           rideID,
+		DriverID:         ride.DriverID.String(),
+		PassengerID:      ride.PassengerID.String(),
+		PickupETASeconds: eta,
+		DriverLocation:   driverLocation,
+		Timestamp:        time.Now(),
+	}
+	if err := uc.ridesGW.PublishRidePickupETA(ctx, event); err != nil {
+		return fmt.Errorf("failed to publish pickup ETA: %w", err)
+	}
+
+	logger.Info("Refreshed pickup ETA",
+		logger.String("ride_id", rideID),
+		logger.Int("previous_eta_seconds", ride.PickupETASeconds),
+		logger.Int("eta_seconds", eta))
+	return nil
+}
@@ -0,0 +1,210 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEstimatePickupETA(t *testing.T) {
+	tests := []struct {
+		name            string
+		distanceKm      float64
+		speedKmh        float64
+		arrivalRadiusKm float64
+		expected        int
+	}{
+		{
+			name:            "Driver already at pickup",
+			distanceKm:      0,
+			speedKmh:        30,
+			arrivalRadiusKm: 0.1,
+			expected:        0,
+		},
+		{
+			name:            "Driver within pickup tolerance",
+			distanceKm:      0.08,
+			speedKmh:        30,
+			arrivalRadiusKm: 0.1,
+			expected:        0,
+		},
+		{
+			name:            "Five kilometers at thirty km/h",
+			distanceKm:      5,
+			speedKmh:        30,
+			arrivalRadiusKm: 0.1,
+			expected:        600,
+		},
+		{
+			name:            "Partial seconds round up",
+			distanceKm:      1,
+			speedKmh:        25,
+			arrivalRadiusKm: 0.1,
+			expected:        144,
+		},
+		{
+			name:            "Slower speed gives a longer ETA",
+			distanceKm:      5,
+			speedKmh:        15,
+			arrivalRadiusKm: 0.1,
+			expected:        1200,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eta := estimatePickupETA(tt.distanceKm, tt.arrivalRadiusKm, AverageSpeedEstimator(tt.speedKmh))
+			assert.Equal(t, tt.expected, eta)
+		})
+	}
+}
+
+func TestAverageSpeedEstimator_InvalidSpeed(t *testing.T) {
+	assert.Equal(t, time.Duration(0), AverageSpeedEstimator(0)(5))
+	assert.Equal(t, time.Duration(0), AverageSpeedEstimator(30)(-1))
+}
+
+func TestPickupETASeconds_UsesConfiguredSpeed(t *testing.T) {
+	pickup := models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	driver := models.Location{Latitude: -6.220392, Longitude: 106.827153} // ~5km south
+
+	slow := &rideUC{cfg: &models.Config{Rides: models.RidesConfig{AverageSpeedKmh: 15}}}
+	fast := &rideUC{cfg: &models.Config{Rides: models.RidesConfig{AverageSpeedKmh: 45}}}
+	unset := &rideUC{cfg: &models.Config{}}
+
+	assert.InDelta(t, 1200, slow.pickupETASeconds(driver, pickup), 5)
+	assert.InDelta(t, 400, fast.pickupETASeconds(driver, pickup), 5)
+	assert.InDelta(t, 720, unset.pickupETASeconds(driver, pickup), 5) // default 25 km/h
+	assert.Equal(t, 0, unset.pickupETASeconds(pickup, pickup))
+}
+
+func newPickupRide(etaSeconds int) *models.Ride {
+	return &models.Ride{
+		RideID:           uuid.New(),
+		DriverID:         uuid.New(),
+		PassengerID:      uuid.New(),
+		Status:           models.RideStatusDriverPickup,
+		PickupLatitude:   -6.175392,
+		PickupLongitude:  106.827153,
+		PickupETASeconds: etaSeconds,
+	}
+}
+
+func TestRefreshPickupETA_SignificantChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newPickupRide(720)
+	rideID := ride.RideID.String()
+	driver := models.Location{Latitude: -6.193392, Longitude: 106.827153} // ~2km away
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().UpdatePickupETA(gomock.Any(), rideID, gomock.Any()).Return(nil)
+	mockGW.EXPECT().PublishRidePickupETA(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event *models.RidePickupETAEvent) error {
+			assert.Equal(t, rideID, event.RideID)
+			assert.Equal(t, ride.PassengerID.String(), event.PassengerID)
+			assert.InDelta(t, 288, event.PickupETASeconds, 5)
+			assert.Equal(t, driver, event.DriverLocation)
+			return nil
+		})
+
+	err = uc.RefreshPickupETA(context.Background(), rideID, driver)
+	assert.NoError(t, err)
+}
+
+func TestRefreshPickupETA_DriverArrived(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	// Even a change below the threshold is pushed once the driver reaches the pickup point
+	ride := newPickupRide(20)
+	rideID := ride.RideID.String()
+	driver := models.Location{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude}
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().UpdatePickupETA(gomock.Any(), rideID, 0).Return(nil)
+	mockGW.EXPECT().PublishRidePickupETA(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event *models.RidePickupETAEvent) error {
+			assert.Equal(t, 0, event.PickupETASeconds)
+			return nil
+		})
+
+	err = uc.RefreshPickupETA(context.Background(), rideID, driver)
+	assert.NoError(t, err)
+}
+
+func TestRefreshPickupETA_InsignificantChange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	cfg := &models.Config{Rides: models.RidesConfig{ETARefreshThresholdSecs: 60}}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newPickupRide(300)
+	rideID := ride.RideID.String()
+	driver := models.Location{Latitude: -6.193392, Longitude: 106.827153} // ~288s away
+
+	// No update or publish expected
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+
+	err = uc.RefreshPickupETA(context.Background(), rideID, driver)
+	assert.NoError(t, err)
+}
+
+func TestRefreshPickupETA_IgnoresRideNotAwaitingPickup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newPickupRide(720)
+	ride.Status = models.RideStatusOngoing
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+
+	err = uc.RefreshPickupETA(context.Background(), rideID, models.Location{Latitude: -6.2, Longitude: 106.8})
+	assert.NoError(t, err)
+}
+
+func TestRefreshPickupETA_GetRideError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	rideID := uuid.New().String()
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(nil, errors.New("db down"))
+
+	err = uc.RefreshPickupETA(context.Background(), rideID, models.Location{Latitude: -6.2, Longitude: 106.8})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get ride")
+}
@@ -0,0 +1,49 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+)
+
+// expectRideCompletion sets up the calls made when the passenger accepts payment for an ongoing ride:
+// the pending payment is accepted, the ride is completed and the completion is published.
+// The ride and payment are updated in place.
+func expectRideCompletion(repo *mocks.MockRideRepo, gw *mocks.MockRideGW, ride *models.Ride, payment *models.Payment) {
+	rideID := ride.RideID.String()
+
+	repo.EXPECT().
+		GetRide(gomock.Any(), rideID).
+		Return(ride, nil)
+
+	repo.EXPECT().
+		GetPaymentByRideID(gomock.Any(), rideID).
+		Return(payment, nil)
+
+	repo.EXPECT().
+		UpdatePaymentStatus(gomock.Any(), payment, models.PaymentStatusAccepted, gomock.Any()).
+		DoAndReturn(func(_ context.Context, p *models.Payment, status models.PaymentStatus, _ string) error {
+			p.Status = status
+			return nil
+		})
+
+	// The completion event carries the fare breakdown rebuilt from the ledger
+	repo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, gomock.Any()).
+		Return([]*models.BillingLedger{}, nil).
+		Times(2)
+
+	repo.EXPECT().
+		GetRideFare(gomock.Any(), rideID).
+		Return(&models.RideFare{RideID: ride.RideID}, nil)
+
+	repo.EXPECT().
+		CompleteRide(gomock.Any(), ride).
+		Return(nil)
+
+	gw.EXPECT().
+		PublishRideCompleted(gomock.Any(), gomock.Any()).
+		Return(nil)
+}
@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	// defaultMaxRideDuration is used when no maximum ride duration is configured
+	defaultMaxRideDuration = 4 * time.Hour
+	// defaultMaxDurationSweepInterval is how often overdue rides are looked for when no interval is given
+	defaultMaxDurationSweepInterval = time.Minute
+	// maxDurationSweepBatchSize is the most overdue rides completed per run
+	maxDurationSweepBatchSize = 100
+	// maxDurationActor records the sweep as the actor of auto-completed payments in the audit log
+	maxDurationActor = "system:max-duration"
+)
+
+// maxRideDuration returns how long a ride may stay ongoing, falling back to the default
+func (uc *rideUC) maxRideDuration() time.Duration {
+	if mins := uc.config().Rides.MaxRideDurationMins; mins > 0 {
+		return time.Duration(mins) * time.Minute
+	}
+	return defaultMaxRideDuration
+}
+
+// settleOverdueRide settles an overdue ride like an arrival without adjustment: the billed ledger
+// total is charged. Cash rides are accepted and completed, since the driver collects the fare; any
+// other ride gets a pending payment and the passenger is sent the payment request, so the ride only
+// completes once they pay.
+func (uc *rideUC) settleOverdueRide(ctx context.Context, ride *models.Ride) (*models.Payment, error) {
+	rideID := ride.RideID.String()
+
+	totalCost, err := uc.ridesRepo.GetBillingLedgerSum(ctx, rideID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
+	}
+
+	surcharges, err := uc.ridesRepo.ListBillingEntries(ctx, rideID, models.BillingCategorySurcharge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list surcharges: %w", err)
+	}
+
+	breakdown := fareBreakdown(totalCost, surcharges, 1)
+	adjustedCost := breakdown.DistanceCost + breakdown.SurchargeCost
+	uc.attachEstimate(ctx, rideID, &breakdown)
+	adminFee, driverPayout := uc.splitPayment(adjustedCost)
+
+	isCash := ride.PaymentMethod == models.PaymentMethodCash
+	status := models.PaymentStatusPending
+	if isCash {
+		status = models.PaymentStatusAccepted
+	}
+
+	payment := &models.Payment{
+		PaymentID:    uuid.New(),
+		RideID:       ride.RideID,
+		AdjustedCost: adjustedCost,
+		AdminFee:     adminFee,
+		DriverPayout: driverPayout,
+		Status:       status,
+		CreatedAt:    time.Now(),
+	}
+
+	if err := uc.ridesRepo.CreatePayment(ctx, payment, maxDurationActor); err != nil {
+		return nil, fmt.Errorf("failed to create payment record: %w", err)
+	}
+
+	if isCash {
+		if err := uc.completeRide(ctx, ride, payment, &breakdown); err != nil {
+			return nil, err
+		}
+		return payment, nil
+	}
+
+	// The payment is recorded, so a failed publish is logged rather than retried: the ride is no
+	// longer overdue and the passenger can still be asked to pay through the arrival flow
+	if err := uc.ridesGW.PublishPaymentRequest(ctx, uc.qrisPaymentRequest(ride, adjustedCost, breakdown)); err != nil {
+		logger.Warn("Failed to publish payment request for overdue ride",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+	}
+	return payment, nil
+}
+
+// CompleteOverdueRides settles up to limit ongoing rides that have run past the maximum ride
+// duration at now, returning how many were settled. Rides already awaiting their payment are left
+// to the passenger.
+func (uc *rideUC) CompleteOverdueRides(ctx context.Context, now time.Time, limit int) (int, error) {
+	if limit <= 0 {
+		limit = maxDurationSweepBatchSize
+	}
+
+	overdue, err := uc.ridesRepo.ListOverdueRides(ctx, now.Add(-uc.maxRideDuration()), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	completed := 0
+	for _, ride := range overdue {
+		payment, err := uc.settleOverdueRide(ctx, ride)
+		if err != nil {
+			logger.Error("Failed to settle overdue ride",
+				logger.String("ride_id", ride.RideID.String()),
+				logger.ErrorField(err))
+			continue
+		}
+
+		logger.Warn("Ride settled after exceeding the maximum ride duration",
+			logger.String("ride_id", ride.RideID.String()),
+			logger.String("payment_status", string(payment.Status)),
+			logger.Int("total_cost", payment.AdjustedCost),
+			logger.Duration("max_ride_duration", uc.maxRideDuration()))
+		completed++
+	}
+	return completed, nil
+}
+
+// RunMaxDurationSweep periodically completes rides past the maximum ride duration until ctx is cancelled
+func (uc *rideUC) RunMaxDurationSweep(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultMaxDurationSweepInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Max ride duration sweep stopped")
+			return
+		case now := <-ticker.C:
+			if _, err := uc.CompleteOverdueRides(ctx, now, maxDurationSweepBatchSize); err != nil {
+				logger.Error("Max ride duration sweep run failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// maxDurationConfig completes rides after two hours with a 5% admin fee
+func maxDurationConfig() *models.Config {
+	return &models.Config{
+		Rides:   models.RidesConfig{MaxRideDurationMins: 120},
+		Pricing: models.PricingConfig{AdminFeePercent: 5.0},
+	}
+}
+
+func TestCompleteOverdueRides_CompletesCashRide(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(maxDurationConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	startedAt := now.Add(-3 * time.Hour)
+	ride := &models.Ride{
+		RideID:        uuid.New(),
+		DriverID:      uuid.New(),
+		PassengerID:   uuid.New(),
+		Status:        models.RideStatusOngoing,
+		PaymentMethod: models.PaymentMethodCash,
+		StartedAt:     &startedAt,
+	}
+	rideID := ride.RideID.String()
+
+	// The driver collected the cash, so the fare is accepted and the ride completed
+	mockRepo.EXPECT().
+		ListOverdueRides(gomock.Any(), now.Add(-2*time.Hour), 10).
+		Return([]*models.Ride{ride}, nil)
+	mockRepo.EXPECT().GetBillingLedgerSum(gomock.Any(), rideID).Return(42000, nil)
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{{Cost: 2000, Description: "toll"}}, nil)
+	expectNoEstimate(mockRepo, rideID)
+
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			CreatePayment(gomock.Any(), gomock.Any(), maxDurationActor).
+			DoAndReturn(func(_ context.Context, payment *models.Payment, _ string) error {
+				assert.Equal(t, models.PaymentStatusAccepted, payment.Status)
+				assert.Equal(t, 42000, payment.AdjustedCost)
+				assert.Equal(t, 2100, payment.AdminFee)
+				assert.Equal(t, 39900, payment.DriverPayout)
+				return nil
+			}),
+		mockRepo.EXPECT().CompleteRide(gomock.Any(), ride).Return(nil),
+		mockGW.EXPECT().
+			PublishRideCompleted(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, rc models.RideComplete) error {
+				assert.Equal(t, models.RideStatusCompleted, rc.Ride.Status)
+				assert.Equal(t, 42000, rc.Payment.AdjustedCost)
+				return nil
+			}),
+	)
+
+	completed, err := uc.CompleteOverdueRides(context.Background(), now, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, completed)
+	assert.Equal(t, models.RideStatusCompleted, ride.Status)
+}
+
+func TestCompleteOverdueRides_RequestsPaymentForQRISRide(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(maxDurationConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	ride := &models.Ride{
+		RideID:        uuid.New(),
+		DriverID:      uuid.New(),
+		PassengerID:   uuid.New(),
+		Status:        models.RideStatusOngoing,
+		PaymentMethod: models.PaymentMethodQRIS,
+	}
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().
+		ListOverdueRides(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]*models.Ride{ride}, nil)
+	mockRepo.EXPECT().GetBillingLedgerSum(gomock.Any(), rideID).Return(42000, nil)
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return(nil, nil)
+	expectNoEstimate(mockRepo, rideID)
+
+	// An unpaid fare is never marked as paid: the passenger is asked to pay and the ride waits for them
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			CreatePayment(gomock.Any(), gomock.Any(), maxDurationActor).
+			DoAndReturn(func(_ context.Context, payment *models.Payment, _ string) error {
+				assert.Equal(t, models.PaymentStatusPending, payment.Status)
+				assert.Equal(t, 42000, payment.AdjustedCost)
+				return nil
+			}),
+		mockGW.EXPECT().
+			PublishPaymentRequest(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, req *models.PaymentRequest) error {
+				assert.Equal(t, rideID, req.RideID)
+				assert.Equal(t, ride.PassengerID.String(), req.PassengerID)
+				assert.Equal(t, 42000, req.TotalCost)
+				assert.Equal(t, models.PaymentMethodQRIS, req.PaymentMethod)
+				assert.NotEmpty(t, req.QRCodeURL)
+				return nil
+			}),
+	)
+
+	settled, err := uc.CompleteOverdueRides(context.Background(), now, 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, settled)
+	assert.Equal(t, models.RideStatusOngoing, ride.Status)
+}
+
+func TestCompleteOverdueRides_DefaultsMaxDuration(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	now := time.Now()
+	mockRepo.EXPECT().
+		ListOverdueRides(gomock.Any(), now.Add(-defaultMaxRideDuration), maxDurationSweepBatchSize).
+		Return(nil, nil)
+
+	completed, err := uc.CompleteOverdueRides(context.Background(), now, 0)
+
+	require.NoError(t, err)
+	assert.Zero(t, completed)
+}
+
+func TestCompleteOverdueRides_SkipsRideThatFailsToSettle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc, err := NewRideUC(maxDurationConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	failing := &models.Ride{RideID: uuid.New(), Status: models.RideStatusOngoing}
+
+	mockRepo.EXPECT().
+		ListOverdueRides(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]*models.Ride{failing}, nil)
+	mockRepo.EXPECT().
+		GetBillingLedgerSum(gomock.Any(), failing.RideID.String()).
+		Return(0, errors.New("db down"))
+
+	completed, err := uc.CompleteOverdueRides(context.Background(), time.Now(), 10)
+
+	require.NoError(t, err)
+	assert.Zero(t, completed)
+	assert.Equal(t, models.RideStatusOngoing, failing.Status)
+}
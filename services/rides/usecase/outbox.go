@@ -0,0 +1,153 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	// defaultOutboxRelayInterval is used when no relay interval is configured
+	defaultOutboxRelayInterval = 5 * time.Second
+	// defaultOutboxBatchSize is used when no relay batch size is configured
+	defaultOutboxBatchSize = 100
+)
+
+// newRidePickupOutboxEvent builds the outbox event announcing a ride is waiting for pickup.
+// The correlation ID of the accepted match is kept since the event is published later by the relay.
+// The event only says whether a pickup code is required: it is stored and fanned out to every
+// subscriber, so the code itself is fetched for the passenger by the users service.
+func newRidePickupOutboxEvent(ride *models.Ride, correlationID string, pickupCodeRequired bool) (*models.OutboxEvent, error) {
+	payload, err := json.Marshal(models.RideResp{
+		RideID:             ride.RideID.String(),
+		MatchID:            ride.MatchID.String(),
+		DriverID:           ride.DriverID.String(),
+		PassengerID:        ride.PassengerID.String(),
+		Status:             string(ride.Status),
+		TotalCost:          ride.TotalCost,
+		PickupETASeconds:   ride.PickupETASeconds,
+		CreatedAt:          ride.CreatedAt,
+		UpdatedAt:          ride.UpdatedAt,
+		CorrelationID:      correlationID,
+		PickupCodeRequired: pickupCodeRequired,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ride pickup event: %w", err)
+	}
+
+	return &models.OutboxEvent{
+		EventID:     uuid.New(),
+		AggregateID: ride.RideID,
+		Subject:     constants.SubjectRidePickup,
+		Payload:     payload,
+		Status:      models.OutboxStatusPending,
+		CreatedAt:   ride.CreatedAt,
+	}, nil
+}
+
+// newRideCancelledOutboxEvent builds the outbox event announcing a ride was cancelled, so both users
+// are still released for rematching when the cancellation is published later by the relay
+func newRideCancelledOutboxEvent(cancelled models.RideCancelled) (*models.OutboxEvent, error) {
+	payload, err := json.Marshal(cancelled)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ride cancelled event: %w", err)
+	}
+
+	return &models.OutboxEvent{
+		EventID:     uuid.New(),
+		AggregateID: cancelled.Ride.RideID,
+		Subject:     constants.SubjectRideCancelled,
+		Payload:     payload,
+		Status:      models.OutboxStatusPending,
+		CreatedAt:   cancelled.Cancellation.CreatedAt,
+	}, nil
+}
+
+// publishOutboxEvent publishes an outbox event and records the outcome
+func (uc *rideUC) publishOutboxEvent(ctx context.Context, event *models.OutboxEvent) error {
+	if err := uc.ridesGW.PublishOutboxEvent(ctx, event); err != nil {
+		if markErr := uc.ridesRepo.MarkOutboxEventFailed(ctx, event.EventID, err.Error()); markErr != nil {
+			logger.Error("Failed to record outbox publish failure",
+				logger.String("event_id", event.EventID.String()),
+				logger.ErrorField(markErr))
+		}
+		return err
+	}
+
+	if err := uc.ridesRepo.MarkOutboxEventSent(ctx, event.EventID); err != nil {
+		// The event was delivered; a later relay will republish it and JetStream drops the duplicate
+		logger.Error("Failed to mark outbox event sent",
+			logger.String("event_id", event.EventID.String()),
+			logger.ErrorField(err))
+	}
+	return nil
+}
+
+// RelayOutboxEvents publishes the pending outbox events of up to limit rides and returns how many
+// were sent. Rides are claimed first, so relays running on several instances don't publish the same
+// ride's events. Once an event fails, the ride's later events are left for the next run so they
+// never go out ahead of it.
+func (uc *rideUC) RelayOutboxEvents(ctx context.Context, limit int) (int, error) {
+	if limit <= 0 {
+		limit = defaultOutboxBatchSize
+	}
+
+	events, err := uc.ridesRepo.ClaimPendingOutboxEvents(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	blocked := make(map[uuid.UUID]bool)
+	for _, event := range events {
+		if blocked[event.AggregateID] {
+			continue
+		}
+		if err := uc.publishOutboxEvent(ctx, event); err != nil {
+			logger.Warn("Failed to relay outbox event",
+				logger.String("event_id", event.EventID.String()),
+				logger.String("aggregate_id", event.AggregateID.String()),
+				logger.String("subject", event.Subject),
+				logger.Int("attempts", event.Attempts+1),
+				logger.ErrorField(err))
+			blocked[event.AggregateID] = true
+			continue
+		}
+		sent++
+	}
+
+	if len(events) > 0 {
+		logger.Info("Relayed outbox events",
+			logger.Int("pending", len(events)),
+			logger.Int("sent", sent))
+	}
+	return sent, nil
+}
+
+// RunOutboxRelay periodically relays pending outbox events until ctx is cancelled
+func (uc *rideUC) RunOutboxRelay(ctx context.Context, interval time.Duration, batchSize int) {
+	if interval <= 0 {
+		interval = defaultOutboxRelayInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Outbox relay stopped")
+			return
+		case <-ticker.C:
+			if _, err := uc.RelayOutboxEvents(ctx, batchSize); err != nil {
+				logger.Error("Outbox relay run failed", logger.ErrorField(err))
+			}
+		}
+	}
+}
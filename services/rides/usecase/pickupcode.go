@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+)
+
+const (
+	// pickupCodeDigits is the length of the numeric code the passenger reads out to the driver
+	pickupCodeDigits = 4
+	// defaultPickupCodeTTL is used when no pickup code lifetime is configured
+	defaultPickupCodeTTL = time.Hour
+	// defaultPickupCodeMaxAttempts is used when no limit on wrong pickup codes is configured
+	defaultPickupCodeMaxAttempts = 5
+)
+
+// pickupCodeTTL returns how long a pickup code stays valid, falling back to the default
+func (uc *rideUC) pickupCodeTTL() time.Duration {
+	if secs := uc.config().Rides.PickupCodeTTLSecs; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultPickupCodeTTL
+}
+
+// pickupCodeMaxAttempts returns how many wrong codes invalidate a pickup code, falling back to the default
+func (uc *rideUC) pickupCodeMaxAttempts() int {
+	if attempts := uc.config().Rides.PickupCodeMaxAttempts; attempts > 0 {
+		return attempts
+	}
+	return defaultPickupCodeMaxAttempts
+}
+
+// issuePickupCode generates and stores the code the driver must enter to start a ride
+func (uc *rideUC) issuePickupCode(ctx context.Context, rideID string) (string, error) {
+	limit := big.NewInt(1)
+	for i := 0; i < pickupCodeDigits; i++ {
+		limit.Mul(limit, big.NewInt(10))
+	}
+	n, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate pickup code: %w", err)
+	}
+	code := fmt.Sprintf("%0*d", pickupCodeDigits, n.Int64())
+
+	if err := uc.ridesRepo.StorePickupCode(ctx, rideID, code, uc.pickupCodeTTL()); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// verifyPickupCode checks the code the driver entered against the one issued for the ride. Wrong
+// codes are counted so the 4-digit space can't be guessed through: once they run out the code is
+// invalidated and rides.ErrPickupCodeLocked is returned until the passenger reissues it.
+func (uc *rideUC) verifyPickupCode(ctx context.Context, rideID, code string) error {
+	expected, err := uc.ridesRepo.GetPickupCode(ctx, rideID)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(code), []byte(expected)) == 1 {
+		return nil
+	}
+
+	failures, err := uc.ridesRepo.RecordPickupCodeFailure(ctx, rideID, uc.pickupCodeTTL())
+	if err != nil {
+		return err
+	}
+	logger.Warn("Ride start refused with a wrong pickup code",
+		logger.String("ride_id", rideID),
+		logger.Int("failures", failures))
+	if failures >= uc.pickupCodeMaxAttempts() {
+		if err := uc.ridesRepo.DeletePickupCode(ctx, rideID); err != nil {
+			return err
+		}
+		return rides.ErrPickupCodeLocked
+	}
+	return rides.ErrInvalidPickupCode
+}
+
+// GetPickupCode returns the pickup code of a ride awaiting pickup to its passenger, issuing a new one
+// when the code expired or was invalidated after wrong attempts. Passengers who missed the ride_pickup
+// event, or whose code ran out, get a code the driver can enter this way.
+func (uc *rideUC) GetPickupCode(ctx context.Context, req models.PickupCodeRequest) (*models.PickupCodeResponse, error) {
+	if !uc.config().Rides.PickupCodeEnabled {
+		return nil, rides.ErrPickupCodesDisabled
+	}
+
+	ride, err := uc.ridesRepo.GetRide(ctx, req.RideID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ride: %w", err)
+	}
+	if ride.PassengerID.String() != req.PassengerID {
+		return nil, rides.ErrNotRidePassenger
+	}
+	if ride.Status != models.RideStatusDriverPickup && ride.Status != models.RideStatusDriverArrived {
+		return nil, fmt.Errorf("%w: status %s", rides.ErrRideNotAwaitingPickup, ride.Status)
+	}
+
+	code, err := uc.ridesRepo.GetPickupCode(ctx, req.RideID)
+	if errors.Is(err, rides.ErrPickupCodeExpired) {
+		code, err = uc.issuePickupCode(ctx, req.RideID)
+		if err == nil {
+			logger.Info("Reissued pickup code",
+				logger.String("ride_id", req.RideID))
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PickupCodeResponse{RideID: req.RideID, PickupCode: code}, nil
+}
+
+// clearPickupCode drops a used pickup code. It expires on its own, so a failure is only logged.
+func (uc *rideUC) clearPickupCode(ctx context.Context, rideID string) {
+	if err := uc.ridesRepo.DeletePickupCode(ctx, rideID); err != nil {
+		logger.Warn("Failed to delete used pickup code",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+	}
+}
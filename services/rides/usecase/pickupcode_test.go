@@ -0,0 +1,267 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func pickupCodeConfig() *models.Config {
+	return &models.Config{Rides: models.RidesConfig{PickupCodeEnabled: true, PickupCodeTTLSecs: 600}}
+}
+
+func pickupCodeStartRequest(rideID, code string) models.RideStartRequest {
+	return models.RideStartRequest{
+		RideID:            rideID,
+		DriverLocation:    &models.Location{Latitude: -6.175392, Longitude: 106.827153},
+		PassengerLocation: &models.Location{Latitude: -6.175400, Longitude: 106.827160},
+		PickupCode:        code,
+	}
+}
+
+func TestCreateRide_IssuesPickupCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(pickupCodeConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	var storedRideID, storedCode string
+	mockRepo.EXPECT().
+		StorePickupCode(gomock.Any(), gomock.Any(), gomock.Any(), 10*time.Minute).
+		DoAndReturn(func(_ context.Context, rideID, code string, _ time.Duration) error {
+			storedRideID, storedCode = rideID, code
+			return nil
+		})
+
+	mockRepo.EXPECT().
+		CreateRideWithBilling(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ride *models.Ride, _ *models.RideFare, event *models.OutboxEvent) (*models.Ride, error) {
+			// The code is tied to the ride, and its pickup event only says one is required
+			assert.Equal(t, ride.RideID.String(), storedRideID)
+			var payload models.RideResp
+			require.NoError(t, json.Unmarshal(event.Payload, &payload))
+			assert.True(t, payload.PickupCodeRequired)
+			assert.Empty(t, payload.PickupCode)
+			return ride, nil
+		})
+	mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Any()).Return(nil)
+
+	err = uc.CreateRide(context.Background(), models.MatchProposal{
+		ID:           uuid.New().String(),
+		DriverID:     uuid.New().String(),
+		PassengerID:  uuid.New().String(),
+		UserLocation: models.Location{Latitude: -6.175392, Longitude: 106.827153},
+	})
+
+	require.NoError(t, err)
+	assert.Regexp(t, `^\d{4}$`, storedCode)
+}
+
+func TestCreateRide_PickupCodeStoreFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(pickupCodeConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	// Without a stored code the ride could never be started, so it isn't created
+	mockRepo.EXPECT().
+		StorePickupCode(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("redis down"))
+
+	err = uc.CreateRide(context.Background(), models.MatchProposal{
+		ID:          uuid.New().String(),
+		DriverID:    uuid.New().String(),
+		PassengerID: uuid.New().String(),
+	})
+
+	assert.Error(t, err)
+}
+
+func TestStartRide_PickupCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		code       string
+		storedCode string
+		lookupErr  error
+		failures   int // Wrong codes counted so far, including this one
+		wantErr    error
+	}{
+		{name: "correct code starts the ride", code: "0427", storedCode: "0427"},
+		{name: "wrong code is rejected", code: "1234", storedCode: "0427", failures: 1, wantErr: rides.ErrInvalidPickupCode},
+		{name: "missing code is rejected", code: "", storedCode: "0427", failures: 1, wantErr: rides.ErrInvalidPickupCode},
+		{name: "expired code is rejected", code: "0427", lookupErr: rides.ErrPickupCodeExpired, wantErr: rides.ErrPickupCodeExpired},
+		{name: "last allowed wrong code invalidates the code", code: "1234", storedCode: "0427", failures: 5, wantErr: rides.ErrPickupCodeLocked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			mockGW := mocks.NewMockRideGW(ctrl)
+			uc, err := NewRideUC(pickupCodeConfig(), mockRepo, mockGW, nil)
+			require.NoError(t, err)
+
+			rideID := uuid.New().String()
+			mockRepo.EXPECT().
+				GetRide(gomock.Any(), rideID).
+				Return(&models.Ride{RideID: uuid.MustParse(rideID), Status: models.RideStatusDriverPickup}, nil)
+			mockRepo.EXPECT().
+				GetPickupCode(gomock.Any(), rideID).
+				Return(tt.storedCode, tt.lookupErr)
+			if tt.failures > 0 {
+				mockRepo.EXPECT().
+					RecordPickupCodeFailure(gomock.Any(), rideID, 10*time.Minute).
+					Return(tt.failures, nil)
+			}
+			if tt.wantErr == rides.ErrPickupCodeLocked {
+				mockRepo.EXPECT().DeletePickupCode(gomock.Any(), rideID).Return(nil)
+			}
+
+			if tt.wantErr == nil {
+				// A used code is dropped so it can't start the ride again
+				mockRepo.EXPECT().UpdateRideStatus(gomock.Any(), rideID, gomock.Any(), models.RideStatusOngoing).Return(nil)
+				mockRepo.EXPECT().DeletePickupCode(gomock.Any(), rideID).Return(nil)
+			}
+
+			ride, err := uc.StartRide(context.Background(), pickupCodeStartRequest(rideID, tt.code))
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, models.RideStatusOngoing, ride.Status)
+		})
+	}
+}
+
+func TestStartRide_PickupCodeDisabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	// No code is looked up when the check is switched off
+	rideID := uuid.New().String()
+	mockRepo.EXPECT().
+		GetRide(gomock.Any(), rideID).
+		Return(&models.Ride{RideID: uuid.MustParse(rideID), Status: models.RideStatusDriverPickup}, nil)
+	mockRepo.EXPECT().UpdateRideStatus(gomock.Any(), rideID, gomock.Any(), models.RideStatusOngoing).Return(nil)
+
+	_, err = uc.StartRide(context.Background(), pickupCodeStartRequest(rideID, ""))
+
+	assert.NoError(t, err)
+}
+
+func TestAutoStartRide_SkippedWithPickupCodes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	cfg := pickupCodeConfig()
+	cfg.Rides.AutoStartEnabled = true
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	// The driver has to enter the code, so co-location alone never starts the ride
+	err = uc.AutoStartRide(context.Background(), uuid.New().String(), models.Location{Latitude: -6.175392, Longitude: 106.827153})
+
+	assert.NoError(t, err)
+}
+
+func TestGetPickupCode(t *testing.T) {
+	passengerID := uuid.New()
+
+	tests := []struct {
+		name      string
+		status    models.RideStatus
+		passenger uuid.UUID
+		stored    string
+		lookupErr error
+		wantCode  string
+		reissue   bool
+		wantErr   error
+	}{
+		{name: "pending code is returned", status: models.RideStatusDriverPickup, passenger: passengerID, stored: "0427", wantCode: "0427"},
+		{name: "expired code is reissued", status: models.RideStatusDriverArrived, passenger: passengerID, lookupErr: rides.ErrPickupCodeExpired, reissue: true},
+		{name: "other users are refused", status: models.RideStatusDriverPickup, passenger: uuid.New(), wantErr: rides.ErrNotRidePassenger},
+		{name: "started rides are refused", status: models.RideStatusOngoing, passenger: passengerID, wantErr: rides.ErrRideNotAwaitingPickup},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			uc, err := NewRideUC(pickupCodeConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+			require.NoError(t, err)
+
+			rideID := uuid.New().String()
+			mockRepo.EXPECT().
+				GetRide(gomock.Any(), rideID).
+				Return(&models.Ride{RideID: uuid.MustParse(rideID), PassengerID: tt.passenger, Status: tt.status}, nil)
+			if tt.wantErr == nil {
+				mockRepo.EXPECT().GetPickupCode(gomock.Any(), rideID).Return(tt.stored, tt.lookupErr)
+			}
+			var reissued string
+			if tt.reissue {
+				mockRepo.EXPECT().
+					StorePickupCode(gomock.Any(), rideID, gomock.Any(), 10*time.Minute).
+					DoAndReturn(func(_ context.Context, _, code string, _ time.Duration) error {
+						reissued = code
+						return nil
+					})
+			}
+
+			resp, err := uc.GetPickupCode(context.Background(), models.PickupCodeRequest{RideID: rideID, PassengerID: passengerID.String()})
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			if tt.reissue {
+				assert.Regexp(t, `^\d{4}$`, reissued)
+				assert.Equal(t, reissued, resp.PickupCode)
+			} else {
+				assert.Equal(t, tt.wantCode, resp.PickupCode)
+			}
+		})
+	}
+}
+
+func TestGetPickupCode_Disabled(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc, err := NewRideUC(&models.Config{}, mocks.NewMockRideRepo(ctrl), mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	_, err = uc.GetPickupCode(context.Background(), models.PickupCodeRequest{RideID: uuid.New().String(), PassengerID: uuid.New().String()})
+
+	assert.ErrorIs(t, err, rides.ErrPickupCodesDisabled)
+}
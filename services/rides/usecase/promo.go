@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+)
+
+// validatePromo rejects a promo that has expired or already reached its usage limit
+func validatePromo(promo *models.Promo, now time.Time) error {
+	if promo.ExpiresAt != nil && !now.Before(*promo.ExpiresAt) {
+		return fmt.Errorf("%w: %s", rides.ErrPromoExpired, promo.Code)
+	}
+	if promo.MaxUses > 0 && promo.UsedCount >= promo.MaxUses {
+		return fmt.Errorf("%w: %s", rides.ErrPromoExhausted, promo.Code)
+	}
+	return nil
+}
+
+// promoDiscount returns how much a promo takes off a fare, never more than the fare itself
+func promoDiscount(promo *models.Promo, fare int) int {
+	var discount int
+	switch promo.Type {
+	case models.PromoTypePercentage:
+		discount = fare * promo.Value / 100
+	case models.PromoTypeFlat:
+		discount = promo.Value
+	}
+
+	if discount < 0 {
+		return 0
+	}
+	if discount > fare {
+		return fare
+	}
+	return discount
+}
+
+// applyPromo redeems a promo code against a ride's fare and records the discount on the breakdown.
+// A ride keeps the discount it was first given, so a retried arrival does not redeem the code again.
+func (uc *rideUC) applyPromo(ctx context.Context, rideID uuid.UUID, code string, breakdown *models.FareBreakdown) error {
+	code = strings.ToUpper(strings.TrimSpace(code))
+
+	redeemed, err := uc.ridesRepo.ListBillingEntries(ctx, rideID.String(), models.BillingCategoryDiscount)
+	if err != nil {
+		return fmt.Errorf("failed to list discounts: %w", err)
+	}
+	if len(redeemed) > 0 {
+		breakdown.PromoCode = redeemed[0].Description
+		breakdown.Discount = -redeemed[0].Cost
+		return nil
+	}
+
+	promo, err := uc.ridesRepo.GetPromo(ctx, code)
+	if err != nil {
+		return fmt.Errorf("failed to get promo: %w", err)
+	}
+
+	if err := validatePromo(promo, time.Now()); err != nil {
+		return err
+	}
+
+	discount := promoDiscount(promo, breakdown.DistanceCost+breakdown.SurchargeCost)
+	if discount == 0 {
+		return nil
+	}
+
+	entry := &models.BillingLedger{
+		EntryID:     uuid.New(),
+		RideID:      rideID,
+		Category:    models.BillingCategoryDiscount,
+		Description: promo.Code,
+		Cost:        -discount,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := uc.ridesRepo.RedeemPromo(ctx, promo.Code, entry); err != nil {
+		return fmt.Errorf("failed to redeem promo: %w", err)
+	}
+
+	breakdown.PromoCode = promo.Code
+	breakdown.Discount = discount
+
+	logger.Info("Applied promo to ride",
+		logger.String("ride_id", rideID.String()),
+		logger.String("promo_code", promo.Code),
+		logger.Int("discount", discount))
+	return nil
+}
@@ -0,0 +1,191 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// expectArrivalFare sets up a ride whose ledger holds 20000 of distance fare and a 10000 toll
+func expectArrivalFare(mockRepo *mocks.MockRideRepo, ride *models.Ride) {
+	rideID := ride.RideID.String()
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetBillingLedgerSum(gomock.Any(), rideID).Return(30000, nil)
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{
+			{RideID: ride.RideID, Category: models.BillingCategorySurcharge, Description: "toll", Cost: 10000},
+		}, nil)
+}
+
+func TestRideArrived_PercentagePromo(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(surchargeConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newOngoingRide()
+	rideID := ride.RideID.String()
+	expiresAt := time.Now().Add(24 * time.Hour)
+
+	expectArrivalFare(mockRepo, ride)
+	mockRepo.EXPECT().ListBillingEntries(gomock.Any(), rideID, models.BillingCategoryDiscount).Return([]*models.BillingLedger{}, nil)
+	mockRepo.EXPECT().GetPromo(gomock.Any(), "HEMAT10").Return(&models.Promo{
+		Code: "HEMAT10", Type: models.PromoTypePercentage, Value: 10, MaxUses: 100, UsedCount: 5, ExpiresAt: &expiresAt,
+	}, nil)
+
+	// 10% off the 28000 fare (18000 distance after the driver's 0.9 plus the 10000 toll)
+	mockRepo.EXPECT().
+		RedeemPromo(gomock.Any(), "HEMAT10", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ string, entry *models.BillingLedger) error {
+			assert.Equal(t, ride.RideID, entry.RideID)
+			assert.Equal(t, models.BillingCategoryDiscount, entry.Category)
+			assert.Equal(t, "HEMAT10", entry.Description)
+			assert.Equal(t, -2800, entry.Cost)
+			return nil
+		})
+
+	expectNoEstimate(mockRepo, rideID)
+
+	// The admin fee is taken from the discounted fare
+	mockRepo.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, payment *models.Payment, _ string) error {
+			assert.Equal(t, 25200, payment.AdjustedCost)
+			assert.Equal(t, 1260, payment.AdminFee)
+			assert.Equal(t, 23940, payment.DriverPayout)
+			return nil
+		})
+
+	paymentRequest, err := uc.RideArrived(context.Background(), models.RideArrivalReq{
+		RideID: rideID, AdjustmentFactor: 0.9, PromoCode: " hemat10 ",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 25200, paymentRequest.TotalCost)
+	assert.Equal(t, "HEMAT10", paymentRequest.Breakdown.PromoCode)
+	assert.Equal(t, 2800, paymentRequest.Breakdown.Discount)
+}
+
+func TestRideArrived_ExpiredPromoRejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(surchargeConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newOngoingRide()
+	rideID := ride.RideID.String()
+	expiredAt := time.Now().Add(-time.Hour)
+
+	expectArrivalFare(mockRepo, ride)
+	mockRepo.EXPECT().ListBillingEntries(gomock.Any(), rideID, models.BillingCategoryDiscount).Return([]*models.BillingLedger{}, nil)
+	mockRepo.EXPECT().GetPromo(gomock.Any(), "LEBARAN").Return(&models.Promo{
+		Code: "LEBARAN", Type: models.PromoTypeFlat, Value: 5000, ExpiresAt: &expiredAt,
+	}, nil)
+
+	// Neither the promo nor a payment is recorded
+	_, err = uc.RideArrived(context.Background(), models.RideArrivalReq{
+		RideID: rideID, AdjustmentFactor: 1, PromoCode: "LEBARAN",
+	})
+	assert.ErrorIs(t, err, rides.ErrPromoExpired)
+}
+
+func TestRideArrived_PromoUsageLimit(t *testing.T) {
+	t.Run("Promo already at its limit is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockRideRepo(ctrl)
+		uc, err := NewRideUC(surchargeConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+		require.NoError(t, err)
+
+		ride := newOngoingRide()
+		rideID := ride.RideID.String()
+
+		expectArrivalFare(mockRepo, ride)
+		mockRepo.EXPECT().ListBillingEntries(gomock.Any(), rideID, models.BillingCategoryDiscount).Return([]*models.BillingLedger{}, nil)
+		mockRepo.EXPECT().GetPromo(gomock.Any(), "PERTAMA").Return(&models.Promo{
+			Code: "PERTAMA", Type: models.PromoTypeFlat, Value: 5000, MaxUses: 1, UsedCount: 1,
+		}, nil)
+
+		_, err = uc.RideArrived(context.Background(), models.RideArrivalReq{
+			RideID: rideID, AdjustmentFactor: 1, PromoCode: "PERTAMA",
+		})
+		assert.ErrorIs(t, err, rides.ErrPromoExhausted)
+	})
+
+	t.Run("Promo used up by a concurrent ride is rejected", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockRideRepo(ctrl)
+		uc, err := NewRideUC(surchargeConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+		require.NoError(t, err)
+
+		ride := newOngoingRide()
+		rideID := ride.RideID.String()
+
+		expectArrivalFare(mockRepo, ride)
+		mockRepo.EXPECT().ListBillingEntries(gomock.Any(), rideID, models.BillingCategoryDiscount).Return([]*models.BillingLedger{}, nil)
+		mockRepo.EXPECT().GetPromo(gomock.Any(), "PERTAMA").Return(&models.Promo{
+			Code: "PERTAMA", Type: models.PromoTypeFlat, Value: 5000, MaxUses: 1, UsedCount: 0,
+		}, nil)
+		mockRepo.EXPECT().RedeemPromo(gomock.Any(), "PERTAMA", gomock.Any()).Return(rides.ErrPromoExhausted)
+
+		_, err = uc.RideArrived(context.Background(), models.RideArrivalReq{
+			RideID: rideID, AdjustmentFactor: 1, PromoCode: "PERTAMA",
+		})
+		assert.ErrorIs(t, err, rides.ErrPromoExhausted)
+	})
+
+	t.Run("Retried arrival keeps its discount without redeeming again", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockRideRepo(ctrl)
+		uc, err := NewRideUC(surchargeConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+		require.NoError(t, err)
+
+		ride := newOngoingRide()
+		rideID := ride.RideID.String()
+
+		expectArrivalFare(mockRepo, ride)
+		mockRepo.EXPECT().
+			ListBillingEntries(gomock.Any(), rideID, models.BillingCategoryDiscount).
+			Return([]*models.BillingLedger{
+				{RideID: ride.RideID, Category: models.BillingCategoryDiscount, Description: "PERTAMA", Cost: -5000},
+			}, nil)
+		expectNoEstimate(mockRepo, rideID)
+		mockRepo.EXPECT().CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+		paymentRequest, err := uc.RideArrived(context.Background(), models.RideArrivalReq{
+			RideID: rideID, AdjustmentFactor: 1, PromoCode: "PERTAMA",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, 25000, paymentRequest.TotalCost)
+		assert.Equal(t, 5000, paymentRequest.Breakdown.Discount)
+	})
+}
+
+func TestPromoDiscount(t *testing.T) {
+	percentage := &models.Promo{Type: models.PromoTypePercentage, Value: 15}
+	assert.Equal(t, 1500, promoDiscount(percentage, 10000))
+
+	// A flat promo never takes more than the fare
+	flat := &models.Promo{Type: models.PromoTypeFlat, Value: 20000}
+	assert.Equal(t, 5000, promoDiscount(flat, 20000/4))
+	assert.Equal(t, 0, promoDiscount(&models.Promo{Type: "UNKNOWN", Value: 10}, 10000))
+}
@@ -0,0 +1,53 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+// defaultStartLocationMaxAge is used when no maximum age is configured for recorded positions
+const defaultStartLocationMaxAge = time.Minute
+
+// startLocationMaxAge returns how old the driver's recorded position may be to start a ride
+func (uc *rideUC) startLocationMaxAge() time.Duration {
+	if secs := uc.config().Rides.StartLocationMaxAgeSecs; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultStartLocationMaxAge
+}
+
+// startLocations returns the driver and pickup positions a ride start is verified with. By default
+// they come from the request. With server-side verification the driver's position is the latest one
+// the location service recorded for the ride, and the pickup point must be the one stored with the
+// ride, so a client can't claim to be at the pickup point.
+func (uc *rideUC) startLocations(ctx context.Context, ride *models.Ride, req models.RideStartRequest) (utils.GeoPoint, utils.GeoPoint, error) {
+	if !uc.config().Rides.VerifyStartWithServerLocation {
+		driverLoc := utils.GeoPoint{
+			Latitude:  req.DriverLocation.Latitude,
+			Longitude: req.DriverLocation.Longitude,
+		}
+		return driverLoc, uc.pickupPoint(ride, req), nil
+	}
+
+	if ride.PickupLatitude == 0 && ride.PickupLongitude == 0 {
+		return utils.GeoPoint{}, utils.GeoPoint{}, fmt.Errorf("ride %s has no recorded pickup point to verify the start against", req.RideID)
+	}
+	pickup := utils.GeoPoint{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude}
+
+	recorded, err := uc.ridesGW.GetRideLocation(ctx, req.RideID)
+	if err != nil {
+		return utils.GeoPoint{}, utils.GeoPoint{}, fmt.Errorf("no recorded driver location to verify the start against: %w", err)
+	}
+	// The recorded timestamp is when the location service received the position, so a device
+	// reporting a wrong clock can't make an old position look fresh
+	if age := time.Since(recorded.Timestamp); age > uc.startLocationMaxAge() {
+		return utils.GeoPoint{}, utils.GeoPoint{}, fmt.Errorf("recorded driver location is too old to verify the start (%s)", age.Round(time.Second))
+	}
+
+	driverLoc := utils.GeoPoint{Latitude: recorded.Latitude, Longitude: recorded.Longitude}
+	return driverLoc, pickup, nil
+}
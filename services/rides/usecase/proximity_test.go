@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	// testPickup matches the pickup point of newPickupRide
+	testPickup = models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	// testNearPickup is a few meters from the pickup point
+	testNearPickup = models.Location{Latitude: -6.175400, Longitude: 106.827160}
+	// testFarFromPickup is about 2 km from the pickup point
+	testFarFromPickup = models.Location{Latitude: -6.193392, Longitude: 106.827153}
+)
+
+func TestStartRide_ProximitySource(t *testing.T) {
+	tests := []struct {
+		name            string
+		serverSide      bool
+		requestLocation models.Location
+		recorded        *models.Location
+		recordedErr     error
+		wantErr         string
+	}{
+		{
+			name:            "Request location near pickup is trusted by default",
+			requestLocation: testNearPickup,
+		},
+		{
+			name:            "Request location far from pickup is rejected by default",
+			requestLocation: testFarFromPickup,
+			wantErr:         "driver is too far from passenger",
+		},
+		{
+			name:            "Spoofed request location is caught by the recorded position",
+			serverSide:      true,
+			requestLocation: testNearPickup,
+			recorded:        &models.Location{Latitude: testFarFromPickup.Latitude, Longitude: testFarFromPickup.Longitude, Timestamp: time.Now()},
+			wantErr:         "driver is too far from passenger",
+		},
+		{
+			name:            "Stale request location is overridden by the recorded position",
+			serverSide:      true,
+			requestLocation: testFarFromPickup,
+			recorded:        &models.Location{Latitude: testNearPickup.Latitude, Longitude: testNearPickup.Longitude, Timestamp: time.Now()},
+		},
+		{
+			name:            "Recorded position too old to trust",
+			serverSide:      true,
+			requestLocation: testNearPickup,
+			recorded:        &models.Location{Latitude: testNearPickup.Latitude, Longitude: testNearPickup.Longitude, Timestamp: time.Now().Add(-5 * time.Minute)},
+			wantErr:         "too old",
+		},
+		{
+			name:            "No recorded position",
+			serverSide:      true,
+			requestLocation: testNearPickup,
+			recordedErr:     errors.New("HTTP error 404"),
+			wantErr:         "no recorded driver location",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			mockGW := mocks.NewMockRideGW(ctrl)
+			cfg := &models.Config{Rides: models.RidesConfig{VerifyStartWithServerLocation: tt.serverSide}}
+			uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+			require.NoError(t, err)
+
+			ride := newPickupRide(0)
+			rideID := ride.RideID.String()
+
+			mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+			if tt.serverSide {
+				mockGW.EXPECT().GetRideLocation(gomock.Any(), rideID).Return(tt.recorded, tt.recordedErr)
+			}
+			if tt.wantErr == "" {
+				mockRepo.EXPECT().UpdateRideStatus(gomock.Any(), rideID, gomock.Any(), models.RideStatusOngoing).Return(nil)
+			}
+
+			requestLocation := tt.requestLocation
+			_, err = uc.StartRide(context.Background(), models.RideStartRequest{
+				RideID:            rideID,
+				DriverLocation:    &requestLocation,
+				PassengerLocation: &testPickup,
+			})
+
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.Equal(t, models.RideStatusDriverPickup, ride.Status)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, models.RideStatusOngoing, ride.Status)
+		})
+	}
+}
+
+func TestStartRide_ServerSideNeedsStoredPickup(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	cfg := &models.Config{Rides: models.RidesConfig{VerifyStartWithServerLocation: true}}
+	uc, err := NewRideUC(cfg, mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	// Without a stored pickup point only the request could say where the passenger is
+	ride := newPickupRide(0)
+	ride.PickupLatitude, ride.PickupLongitude = 0, 0
+	rideID := ride.RideID.String()
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+
+	_, err = uc.StartRide(context.Background(), models.RideStartRequest{
+		RideID:            rideID,
+		DriverLocation:    &testNearPickup,
+		PassengerLocation: &testPickup,
+	})
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no recorded pickup point")
+}
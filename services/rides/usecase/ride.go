@@ -2,22 +2,38 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/featureflag"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/pkg/region"
 	"github.com/piresc/nebengjek/internal/utils"
 	"github.com/piresc/nebengjek/services/rides"
 )
 
+// defaultMaxPickupDistanceMeters is used when no pickup tolerance is configured
+const defaultMaxPickupDistanceMeters = 100.0
+
 // RideUC implements the rides.RideUseCase interface
 type rideUC struct {
 	cfg       *models.Config
 	ridesRepo rides.RideRepo
 	ridesGW   rides.RideGW
+	// flags gates behaviour being rolled out gradually; nil leaves every flag off
+	flags *featureflag.Flags
+
+	// reloaded holds the config with tunables applied by the last reload, nil until the first one
+	reloaded atomic.Pointer[models.Config]
+	// rideCache serves ongoing rides to location aggregates without reading them each time
+	rideCache rideCache
 }
 
 // NewRideUC creates a new ride use case
@@ -25,14 +41,36 @@ func NewRideUC(
 	cfg *models.Config,
 	rideRepo rides.RideRepo,
 	rideGW rides.RideGW,
+	flags *featureflag.Flags,
 ) (rides.RideUC, error) {
 	return &rideUC{
 		cfg:       cfg,
 		ridesRepo: rideRepo,
 		ridesGW:   rideGW,
+		flags:     flags,
 	}, nil
 }
 
+// config returns the settings in effect, including tunables reloaded at runtime
+func (uc *rideUC) config() *models.Config {
+	if reloaded := uc.reloaded.Load(); reloaded != nil {
+		return reloaded
+	}
+	return uc.cfg
+}
+
+// ReloadConfig applies the tunable settings of a freshly loaded config, such as fares, the admin
+// fee and surcharges, to subsequent operations
+func (uc *rideUC) ReloadConfig(reloaded *models.Config) {
+	uc.reloaded.Store(uc.cfg.WithTunables(reloaded))
+
+	logger.Info("Reloaded ride pricing settings",
+		logger.Float64("rate_per_km", reloaded.Pricing.RatePerKm),
+		logger.Float64("admin_fee_percent", reloaded.Pricing.AdminFeePercent),
+		logger.Int("regions", len(reloaded.Regions)),
+		logger.Int("surcharges", len(reloaded.Surcharges)))
+}
+
 // CreateRide creates a new ride from a confirmed match
 func (uc *rideUC) CreateRide(ctx context.Context, mp models.MatchProposal) error {
 	logger.Info("Creating ride from match proposal",
@@ -57,22 +95,63 @@ func (uc *rideUC) CreateRide(ctx context.Context, mp models.MatchProposal) error
 	}
 
 	// Create a new ride from the match proposal
+	now := time.Now()
 	ride := &models.Ride{
+		RideID:      uuid.New(),
 		MatchID:     matchID,
 		DriverID:    driverID,
 		PassengerID: passengerID,
 		Status:      models.RideStatusDriverPickup, // Set initial status to driver pickup
 		TotalCost:   0,                             // This will be calculated later
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	ride.PaymentMethod = models.PaymentMethodQRIS
+	if mp.PaymentMethod == models.PaymentMethodCash {
+		ride.PaymentMethod = models.PaymentMethodCash
+	}
+
+	// The passenger's location at match time is the pickup point the ETA is measured against
+	ride.PickupLatitude = mp.UserLocation.Latitude
+	ride.PickupLongitude = mp.UserLocation.Longitude
+	if mp.DriverLocation.Latitude != 0 || mp.DriverLocation.Longitude != 0 {
+		ride.PickupETASeconds = uc.pickupETASeconds(mp.DriverLocation, mp.UserLocation)
+	}
+
+	// The passenger reads the pickup code out to the driver, who needs it to start the ride. It is
+	// stored before the ride so a failed store leaves the match to be redelivered.
+	pickupCodeRequired := uc.config().Rides.PickupCodeEnabled
+	if pickupCodeRequired {
+		if _, err := uc.issuePickupCode(ctx, ride.RideID.String()); err != nil {
+			return err
+		}
+	}
+
+	// Store the pickup event alongside the ride so it survives a failed publish
+	event, err := newRidePickupOutboxEvent(ride, mp.CorrelationID, pickupCodeRequired)
+	if err != nil {
+		return err
+	}
+
+	// Record the fare the ride was accepted under together with the ride itself
+	pickupRegion := region.Resolve(uc.config(), mp.UserLocation)
+	fare := &models.RideFare{
+		Region:              pickupRegion.Name,
+		RatePerKm:           pickupRegion.RatePerKm,
+		EstimatedFare:       mp.EstimatedFare,
+		EstimatedDistanceKm: mp.EstimatedDistanceKm,
 	}
 
 	logger.Info("Creating ride in database",
 		logger.String("match_id", ride.MatchID.String()),
 		logger.String("driver_id", ride.DriverID.String()),
 		logger.String("passenger_id", ride.PassengerID.String()),
-		logger.String("status", string(ride.Status)))
+		logger.String("status", string(ride.Status)),
+		logger.Int("pickup_eta_seconds", ride.PickupETASeconds))
 
 	// Delegate to repository
-	createdRide, err := uc.ridesRepo.CreateRide(ride)
+	createdRide, err := uc.ridesRepo.CreateRideWithBilling(ctx, ride, fare, event)
 	if err != nil {
 		// Check if this is a duplicate match_id constraint violation
 		if strings.Contains(err.Error(), "rides_match_id_unique") ||
@@ -99,14 +178,14 @@ func (uc *rideUC) CreateRide(ctx context.Context, mp models.MatchProposal) error
 		logger.String("passenger_id", createdRide.PassengerID.String()),
 		logger.String("status", string(createdRide.Status)))
 
-	err = uc.ridesGW.PublishRidePickup(context.Background(), createdRide)
-	if err != nil {
-		logger.Error("Failed to publish ride pickup event to NATS",
+	// A failed publish leaves the event pending; the outbox relay retries it. The ride is already
+	// committed, so the publish keeps the request's trace but not its cancellation.
+	if err := uc.publishOutboxEvent(context.WithoutCancel(ctx), event); err != nil {
+		logger.Warn("Ride pickup event left in outbox for relay",
 			logger.String("ride_id", createdRide.RideID.String()),
-			logger.String("driver_id", createdRide.DriverID.String()),
-			logger.String("passenger_id", createdRide.PassengerID.String()),
+			logger.String("event_id", event.EventID.String()),
 			logger.ErrorField(err))
-		return err
+		return nil
 	}
 
 	logger.Info("Successfully created ride and published pickup event",
@@ -120,11 +199,16 @@ func (uc *rideUC) CreateRide(ctx context.Context, mp models.MatchProposal) error
 func (uc *rideUC) ProcessBillingUpdate(ctx context.Context, rideID string, entry *models.BillingLedger) error {
 
 	// Get current ride to verify it exists and is active
-	ride, err := uc.ridesRepo.GetRide(ctx, rideID)
+	ride, err := uc.getAggregateRide(ctx, rideID)
 	if err != nil {
 		return fmt.Errorf("failed to get ride: %w", err)
 	}
 
+	// Late entries for a completed ride would change a fare that has already been paid
+	if ride.LedgerFinalized {
+		return fmt.Errorf("%w: %s", rides.ErrLedgerFinalized, rideID)
+	}
+
 	if ride.Status != models.RideStatusOngoing {
 		return fmt.Errorf("cannot update billing for non-active ride")
 	}
@@ -136,27 +220,57 @@ func (uc *rideUC) ProcessBillingUpdate(ctx context.Context, rideID string, entry
 	}
 	entry.RideID = rideUUID
 
+	// Fares follow the rate the ride was accepted under
+	entry.Cost, err = uc.billingCost(ctx, ride, entry.Distance)
+	if err != nil {
+		return err
+	}
+
 	// Add billing entry
 	if err := uc.ridesRepo.AddBillingEntry(ctx, entry); err != nil {
+		if errors.Is(err, rides.ErrLedgerFinalized) {
+			// The ride was paid for since it was cached
+			uc.rideCache.forget(rideID)
+		}
 		return fmt.Errorf("failed to add billing entry: %w", err)
 	}
 
 	// Update total cost
-	if err := uc.ridesRepo.UpdateTotalCost(ctx, rideID, entry.Cost); err != nil {
+	totalCost, err := uc.ridesRepo.UpdateTotalCost(ctx, rideID, entry.Cost)
+	if err != nil {
 		logger.Warn("Failed to update total cost for ride",
 			logger.String("ride_id", rideID),
 			logger.ErrorField(err))
 		return fmt.Errorf("failed to update total cost: %w", err)
 	}
+	uc.rideCache.setTotalCost(rideID, totalCost)
 
 	logger.Info("Updated billing for ride",
 		logger.String("ride_id", rideID),
 		logger.Int("cost", entry.Cost),
 		logger.Float64("distance", entry.Distance))
+
+	// The entry is recorded, so a failed publish is logged rather than retried: redelivering the
+	// update would bill the distance twice, and the next update carries the new total anyway
+	update := &models.RideBillingUpdate{
+		RideID:      rideID,
+		DriverID:    ride.DriverID.String(),
+		PassengerID: ride.PassengerID.String(),
+		Distance:    entry.Distance,
+		Cost:        entry.Cost,
+		TotalCost:   totalCost,
+		Timestamp:   time.Now(),
+	}
+	if err := uc.ridesGW.PublishRideBilling(ctx, update); err != nil {
+		logger.Warn("Failed to publish ride billing update",
+			logger.String("ride_id", rideID),
+			logger.ErrorField(err))
+	}
 	return nil
 }
 
-// StartRide updates a ride from driver_pickup to ongoing status
+// StartRide updates a ride awaiting pickup, or whose driver is waiting at the pickup point, to
+// ongoing status. Time the driver waited past the grace time is charged as a waiting fee.
 func (uc *rideUC) StartRide(ctx context.Context, req models.RideStartRequest) (*models.Ride, error) {
 	logger.Info("Starting ride request",
 		logger.String("ride_id", req.RideID),
@@ -178,7 +292,7 @@ func (uc *rideUC) StartRide(ctx context.Context, req models.RideStartRequest) (*
 		logger.String("driver_id", ride.DriverID.String()),
 		logger.String("passenger_id", ride.PassengerID.String()))
 
-	if ride.Status != models.RideStatusDriverPickup {
+	if ride.Status != models.RideStatusDriverPickup && ride.Status != models.RideStatusDriverArrived {
 		logger.Error("Cannot start ride - invalid status",
 			logger.String("ride_id", req.RideID),
 			logger.String("current_status", string(ride.Status)),
@@ -187,50 +301,132 @@ func (uc *rideUC) StartRide(ctx context.Context, req models.RideStartRequest) (*
 		return &models.Ride{}, err
 	}
 
-	// Calculate distance using Haversine formula
-	driverLoc := utils.GeoPoint{
-		Latitude:  req.DriverLocation.Latitude,
-		Longitude: req.DriverLocation.Longitude,
+	pickupCodeEnabled := uc.config().Rides.PickupCodeEnabled
+	if pickupCodeEnabled {
+		if err := uc.verifyPickupCode(ctx, req.RideID, req.PickupCode); err != nil {
+			return &models.Ride{}, err
+		}
 	}
-	passLoc := utils.GeoPoint{
-		Latitude:  req.PassengerLocation.Latitude,
-		Longitude: req.PassengerLocation.Longitude,
+
+	driverLoc, passLoc, err := uc.startLocations(ctx, ride, req)
+	if err != nil {
+		logger.Error("Cannot verify driver proximity",
+			logger.String("ride_id", req.RideID),
+			logger.ErrorField(err))
+		return &models.Ride{}, err
 	}
 
-	// Verify driver is close to passenger (within 100 meters)
+	// Verify driver is close enough to passenger to start the trip
 	distanceKm := utils.CalculateDistance(driverLoc, passLoc)
 	distanceMeters := distanceKm * 1000
+	maxDistanceMeters := uc.maxPickupDistanceMeters()
 
 	logger.Info("Calculated distance between driver and passenger",
 		logger.String("ride_id", req.RideID),
 		logger.Float64("distance_meters", distanceMeters),
-		logger.Float64("max_allowed_meters", 100))
+		logger.Float64("max_allowed_meters", maxDistanceMeters))
 
-	// Check if driver is close enough to passenger (within 100 meters)
-	if distanceMeters > 100 {
+	if distanceMeters > maxDistanceMeters {
 		logger.Error("Driver too far from passenger",
 			logger.String("ride_id", req.RideID),
 			logger.Float64("distance_meters", distanceMeters),
-			logger.Float64("max_allowed_meters", 100),
-			logger.Any("driver_location", req.DriverLocation),
-			logger.Any("passenger_location", req.PassengerLocation))
+			logger.Float64("max_allowed_meters", maxDistanceMeters),
+			logger.Any("driver_location", driverLoc),
+			logger.Any("pickup_location", passLoc))
 		err := fmt.Errorf("driver is too far from passenger (%.2f meters)", distanceMeters)
 		return &models.Ride{}, err
 	}
 
 	// Update ride status to ongoing
-	ride.Status = models.RideStatusOngoing
-	if err := uc.ridesRepo.UpdateRideStatus(ctx, ride.RideID.String(), models.RideStatusOngoing); err != nil {
+	// Auto-start may have started the ride meanwhile, so only the status read above is moved on
+	wasWaiting := ride.Status == models.RideStatusDriverArrived
+	if err := uc.ridesRepo.UpdateRideStatus(ctx, ride.RideID.String(), ride.Status, models.RideStatusOngoing); err != nil {
 		return &models.Ride{}, fmt.Errorf("failed to update ride status to ongoing: %w", err)
 	}
+	ride.Status = models.RideStatusOngoing
+
+	if pickupCodeEnabled {
+		uc.clearPickupCode(ctx, req.RideID)
+	}
+
+	// The ride has already started, so a failed charge is logged for reconciliation rather than undone
+	if wasWaiting {
+		if err := uc.recordWaitingFee(ctx, ride, time.Now()); err != nil {
+			logger.Error("Failed to charge waiting fee",
+				logger.String("ride_id", req.RideID),
+				logger.ErrorField(err))
+		}
+	}
 
 	logger.Info("Ride started - Driver picked up passenger",
 		logger.String("ride_id", req.RideID))
 	return ride, nil
 }
 
+// pickupPoint returns where the driver must be to start the trip. The ride's stored pickup point
+// carries the passenger's latest position before acceptance, which is fresher than the location
+// the driver's app had when the match was proposed; the request location is used for rides without one.
+func (uc *rideUC) pickupPoint(ride *models.Ride, req models.RideStartRequest) utils.GeoPoint {
+	if ride.PickupLatitude != 0 || ride.PickupLongitude != 0 {
+		return utils.GeoPoint{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude}
+	}
+	return utils.GeoPoint{
+		Latitude:  req.PassengerLocation.Latitude,
+		Longitude: req.PassengerLocation.Longitude,
+	}
+}
+
+// billingCost prices distanceKm at the rate recorded in the ride's fare when it was accepted, so a
+// pricing change mid-ride does not alter its fare. Rides created before fares were recorded are
+// priced at the rate of the region containing their pickup point.
+func (uc *rideUC) billingCost(ctx context.Context, ride *models.Ride, distanceKm float64) (int, error) {
+	ratePerKm, err := uc.rideRatePerKm(ctx, ride)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(distanceKm * ratePerKm)), nil
+}
+
+// rideRatePerKm returns the per-km rate snapshotted in the ride's fare, falling back to the current
+// rate of its pickup region for rides without one
+func (uc *rideUC) rideRatePerKm(ctx context.Context, ride *models.Ride) (float64, error) {
+	fare, err := uc.ridesRepo.GetRideFare(ctx, ride.RideID.String())
+	switch {
+	case err == nil && fare.RatePerKm > 0:
+		return fare.RatePerKm, nil
+	case err != nil && !errors.Is(err, sql.ErrNoRows):
+		return 0, fmt.Errorf("failed to get ride fare: %w", err)
+	}
+
+	pickup := models.Location{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude}
+	return region.Resolve(uc.config(), pickup).RatePerKm, nil
+}
+
+// maxPickupDistanceMeters returns the configured pickup tolerance, falling back to the default
+func (uc *rideUC) maxPickupDistanceMeters() float64 {
+	if meters := uc.config().Rides.MaxPickupDistanceMeters; meters > 0 {
+		return meters
+	}
+	return defaultMaxPickupDistanceMeters
+}
+
+// splitPayment divides the adjusted cost into the admin fee and driver payout
+func (uc *rideUC) splitPayment(adjustedCost int) (int, int) {
+	return uc.config().Pricing.SplitFare(adjustedCost)
+}
+
+// paymentActor identifies the user behind a payment status transition in the audit log
+func paymentActor(role string, userID uuid.UUID) string {
+	return fmt.Sprintf("%s:%s", role, userID.String())
+}
+
 // RideArrived handles when a ride arrives at the destination but before payment processing
 func (uc *rideUC) RideArrived(ctx context.Context, req models.RideArrivalReq) (*models.PaymentRequest, error) {
+	// An adjustment outside 0-1 would overcharge or refund the passenger, so it is rejected rather than guessed
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid arrival request: %w", err)
+	}
+
 	// Get current ride to verify it exists and is active
 	ride, err := uc.ridesRepo.GetRide(ctx, req.RideID)
 	if err != nil {
@@ -248,17 +444,31 @@ func (uc *rideUC) RideArrived(ctx context.Context, req models.RideArrivalReq) (*
 		return nil, fmt.Errorf("failed to calculate total cost: %w", err)
 	}
 
-	// Validate adjustment factor
-	if req.AdjustmentFactor < 0 || req.AdjustmentFactor > 1.0 {
-		req.AdjustmentFactor = 1.0 // Reset to 100% if invalid
+	surcharges, err := uc.ridesRepo.ListBillingEntries(ctx, req.RideID, models.BillingCategorySurcharge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list surcharges: %w", err)
 	}
 
 	// Calculate adjusted cost
-	adjustedCost := int(float64(totalCost) * req.AdjustmentFactor)
+	breakdown := fareBreakdown(totalCost, surcharges, req.AdjustmentFactor)
+
+	// A promo discounts the fare before the admin fee is taken
+	if req.PromoCode != "" {
+		if err := uc.applyPromo(ctx, ride.RideID, req.PromoCode, &breakdown); err != nil {
+			return nil, err
+		}
+	}
+	adjustedCost := breakdown.DistanceCost + breakdown.SurchargeCost - breakdown.Discount
+	uc.attachEstimate(ctx, req.RideID, &breakdown)
 
-	adminFeePercent := uc.cfg.Pricing.AdminFeePercent / 100.0 // Convert percentage to decimal
-	adminFee := int(float64(adjustedCost) * adminFeePercent)
-	driverPayout := adjustedCost - adminFee
+	adminFee, driverPayout := uc.splitPayment(adjustedCost)
+
+	// Cash is collected by the driver on arrival, so there is nothing left for the passenger to confirm
+	isCash := ride.PaymentMethod == models.PaymentMethodCash
+	status := models.PaymentStatusPending
+	if isCash {
+		status = models.PaymentStatusAccepted
+	}
 
 	// Create payment record
 	payment := &models.Payment{
@@ -267,35 +477,58 @@ func (uc *rideUC) RideArrived(ctx context.Context, req models.RideArrivalReq) (*
 		AdjustedCost: adjustedCost,
 		AdminFee:     adminFee,
 		DriverPayout: driverPayout,
-		Status:       models.PaymentStatusPending,
+		Status:       status,
 		CreatedAt:    time.Now(),
 	}
 
 	// Save payment record
-	if err := uc.ridesRepo.CreatePayment(ctx, payment); err != nil {
+	if err := uc.ridesRepo.CreatePayment(ctx, payment, paymentActor("driver", ride.DriverID)); err != nil {
 		return nil, fmt.Errorf("failed to create payment record: %w", err)
 	}
 
-	// Generate QR code URL for payment processing
-	qrCodeURL := fmt.Sprintf("%s?ride_id=%s&amount=%d&passenger_id=%s",
-		uc.cfg.Payment.QRCodeBaseURL, req.RideID, adjustedCost, ride.PassengerID.String())
+	if isCash {
+		if err := uc.completeRide(ctx, ride, payment, &breakdown); err != nil {
+			return nil, err
+		}
 
-	// Create payment request
-	paymentRequest := &models.PaymentRequest{
-		RideID:      req.RideID,
-		PassengerID: ride.PassengerID.String(),
-		TotalCost:   adjustedCost,
-		QRCodeURL:   qrCodeURL,
+		logger.Info("Cash ride settled at destination",
+			logger.String("ride_id", req.RideID),
+			logger.Int("total_cost", adjustedCost))
+
+		return &models.PaymentRequest{
+			RideID:        req.RideID,
+			PassengerID:   ride.PassengerID.String(),
+			TotalCost:     adjustedCost,
+			PaymentMethod: models.PaymentMethodCash,
+			Breakdown:     breakdown,
+		}, nil
 	}
 
+	paymentRequest := uc.qrisPaymentRequest(ride, adjustedCost, breakdown)
+
 	logger.Info("Ride arrived at destination",
 		logger.String("ride_id", req.RideID),
 		logger.Int("total_cost", adjustedCost),
-		logger.String("qr_code_url", qrCodeURL))
+		logger.String("qr_code_url", paymentRequest.QRCodeURL))
 
 	return paymentRequest, nil
 }
 
+// qrisPaymentRequest builds the request asking the passenger to pay a ride's fare by QR code
+func (uc *rideUC) qrisPaymentRequest(ride *models.Ride, adjustedCost int, breakdown models.FareBreakdown) *models.PaymentRequest {
+	qrCodeURL := fmt.Sprintf("%s?ride_id=%s&amount=%d&passenger_id=%s",
+		uc.config().Payment.QRCodeBaseURL, ride.RideID.String(), adjustedCost, ride.PassengerID.String())
+
+	return &models.PaymentRequest{
+		RideID:        ride.RideID.String(),
+		PassengerID:   ride.PassengerID.String(),
+		TotalCost:     adjustedCost,
+		QRCodeURL:     qrCodeURL,
+		PaymentMethod: models.PaymentMethodQRIS,
+		Breakdown:     breakdown,
+	}
+}
+
 // ProcessPayment processes the payment for a completed ride
 func (uc *rideUC) ProcessPayment(ctx context.Context, req models.PaymentProccessRequest) (*models.Payment, error) {
 	// Get current ride to verify it exists and is active
@@ -327,33 +560,52 @@ func (uc *rideUC) ProcessPayment(ctx context.Context, req models.PaymentProccess
 	}
 
 	// Update payment status
-	payment.Status = req.Status
-	err = uc.ridesRepo.UpdatePaymentStatus(ctx, payment.PaymentID.String(), req.Status)
+	err = uc.ridesRepo.UpdatePaymentStatus(ctx, payment, req.Status, paymentActor("passenger", ride.PassengerID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to update payment status: %w", err)
 	}
+	payment.Status = req.Status
 
 	// Payment status needs to be accepted for ride to be completed
 	if req.Status == models.PaymentStatusAccepted {
-		// Mark ride as completed
-		ride.Status = models.RideStatusCompleted
-		if err := uc.ridesRepo.CompleteRide(ctx, ride); err != nil {
-			return nil, fmt.Errorf("failed to mark ride as completed: %w", err)
-		}
-
-		// Create ride complete data for the event
-		var rideComplete = models.RideComplete{
-			Ride:    *ride,
-			Payment: *payment,
-		}
-
-		// Publish payment processed event
-		if err := uc.ridesGW.PublishRideCompleted(ctx, rideComplete); err != nil {
-			// Log but don't fail the transaction
-			logger.Warn("Failed to publish ride completed event",
+		// The breakdown only enriches the completion event, so the ride completes without it
+		breakdown, err := uc.settledBreakdown(ctx, ride, payment)
+		if err != nil {
+			logger.Warn("Failed to rebuild fare breakdown",
+				logger.String("ride_id", req.RideID),
 				logger.ErrorField(err))
 		}
+		if err := uc.completeRide(ctx, ride, payment, breakdown); err != nil {
+			return nil, err
+		}
 	}
 
 	return payment, nil
 }
+
+// completeRide marks a ride with an accepted payment as completed and publishes the completion,
+// with the fare breakdown when one is known
+func (uc *rideUC) completeRide(ctx context.Context, ride *models.Ride, payment *models.Payment, breakdown *models.FareBreakdown) error {
+	// Mark ride as completed, locking its ledger at the fare the passenger paid
+	ride.Status = models.RideStatusCompleted
+	ride.LedgerFinalized = true
+	ride.FinalTotal = payment.AdjustedCost
+	if err := uc.ridesRepo.CompleteRide(ctx, ride); err != nil {
+		return fmt.Errorf("failed to mark ride as completed: %w", err)
+	}
+
+	// Create ride complete data for the event
+	var rideComplete = models.RideComplete{
+		Ride:      *ride,
+		Payment:   *payment,
+		Breakdown: breakdown,
+	}
+
+	// Publish payment processed event
+	if err := uc.ridesGW.PublishRideCompleted(ctx, rideComplete); err != nil {
+		// Log but don't fail the transaction
+		logger.Warn("Failed to publish ride completed event",
+			logger.ErrorField(err))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// defaultRideCacheTTL is used when no ride cache TTL is configured
+const defaultRideCacheTTL = 30 * time.Second
+
+// cachedRide is a ride kept between location aggregates until it expires
+type cachedRide struct {
+	ride      models.Ride
+	expiresAt time.Time
+}
+
+// rideCache keeps ongoing rides in memory so the steady stream of location aggregates for a
+// ride doesn't read it from the database every time. Only ongoing rides with an open ledger are
+// kept: the only way out of that state is payment, which finalizes the ledger, and billing
+// entries for a finalized ledger are refused by the database, at which point the ride is dropped.
+type rideCache struct {
+	mu    sync.Mutex
+	rides map[string]cachedRide
+}
+
+// get returns a copy of the cached ride, or nil when it is missing or expired
+func (c *rideCache) get(rideID string, now time.Time) *models.Ride {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.rides[rideID]
+	if !ok {
+		return nil
+	}
+	if !now.Before(cached.expiresAt) {
+		delete(c.rides, rideID)
+		return nil
+	}
+	ride := cached.ride
+	return &ride
+}
+
+// put stores a copy of ride, dropping any other entries that have expired
+func (c *rideCache) put(ride *models.Ride, now time.Time, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.rides == nil {
+		c.rides = make(map[string]cachedRide)
+	}
+	for id, cached := range c.rides {
+		if !now.Before(cached.expiresAt) {
+			delete(c.rides, id)
+		}
+	}
+	c.rides[ride.RideID.String()] = cachedRide{ride: *ride, expiresAt: now.Add(ttl)}
+}
+
+// setTotalCost records a ride's new total cost, keeping its expiry
+func (c *rideCache) setTotalCost(rideID string, total int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.rides[rideID]; ok {
+		cached.ride.TotalCost = total
+		c.rides[rideID] = cached
+	}
+}
+
+// forget drops a cached ride
+func (c *rideCache) forget(rideID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	delete(c.rides, rideID)
+}
+
+// rideCacheTTL returns how long an ongoing ride stays cached, falling back to the default
+func (uc *rideUC) rideCacheTTL() time.Duration {
+	if secs := uc.config().Rides.RideCacheTTLSecs; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultRideCacheTTL
+}
+
+// getAggregateRide returns the ride a location aggregate refers to, served from the ride cache
+// while the ride is ongoing
+func (uc *rideUC) getAggregateRide(ctx context.Context, rideID string) (*models.Ride, error) {
+	now := time.Now()
+	if ride := uc.rideCache.get(rideID, now); ride != nil {
+		return ride, nil
+	}
+
+	ride, err := uc.ridesRepo.GetRide(ctx, rideID)
+	if err != nil {
+		return nil, err
+	}
+	if ride.Status == models.RideStatusOngoing && !ride.LedgerFinalized {
+		uc.rideCache.put(ride, now, uc.rideCacheTTL())
+	}
+	return ride, nil
+}
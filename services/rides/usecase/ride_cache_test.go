@@ -0,0 +1,120 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessBillingUpdate_ReadsOngoingRideOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{RideID: uuid.New(), Status: models.RideStatusOngoing, TotalCost: 10000}
+	rideID := ride.RideID.String()
+
+	// Later aggregates are served from the cache, with the total kept up to date
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil).Times(1)
+	mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(&models.RideFare{RatePerKm: 3000}, nil).Times(3)
+	mockRepo.EXPECT().AddBillingEntry(gomock.Any(), gomock.Any()).Return(nil).Times(3)
+	gomock.InOrder(
+		mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 3000).Return(13000, nil),
+		mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 3000).Return(16000, nil),
+		mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 3000).Return(19000, nil),
+	)
+
+	var totals []int
+	mockGW.EXPECT().
+		PublishRideBilling(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, update *models.RideBillingUpdate) error {
+			totals = append(totals, update.TotalCost)
+			return nil
+		}).
+		Times(3)
+
+	for i := 0; i < 3; i++ {
+		err := uc.ProcessBillingUpdate(context.Background(), rideID, &models.BillingLedger{Distance: 1})
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, []int{13000, 16000, 19000}, totals)
+}
+
+func TestProcessBillingUpdate_FinalizedLedgerDropsCachedRide(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{RideID: uuid.New(), Status: models.RideStatusOngoing}
+	rideID := ride.RideID.String()
+	paid := *ride
+	paid.Status = models.RideStatusCompleted
+	paid.LedgerFinalized = true
+
+	gomock.InOrder(
+		mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil),
+		mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(&paid, nil),
+	)
+	mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(&models.RideFare{RatePerKm: 3000}, nil)
+
+	// The ride was paid for after it was cached, so the database refuses the entry
+	mockRepo.EXPECT().
+		AddBillingEntry(gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("%w: %s", rides.ErrLedgerFinalized, rideID))
+
+	err = uc.ProcessBillingUpdate(context.Background(), rideID, &models.BillingLedger{Distance: 1})
+	assert.ErrorIs(t, err, rides.ErrLedgerFinalized)
+
+	// The next aggregate reads the ride again and sees it finalized
+	err = uc.ProcessBillingUpdate(context.Background(), rideID, &models.BillingLedger{Distance: 1})
+	assert.ErrorIs(t, err, rides.ErrLedgerFinalized)
+}
+
+func TestRideCache_OnlyKeepsOngoingRides(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc := &rideUC{cfg: &models.Config{}, ridesRepo: mockRepo}
+
+	ride := &models.Ride{RideID: uuid.New(), Status: models.RideStatusDriverPickup}
+	rideID := ride.RideID.String()
+
+	// Rides awaiting pickup change with every ETA refresh, so each aggregate reads them
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil).Times(2)
+
+	for i := 0; i < 2; i++ {
+		got, err := uc.getAggregateRide(context.Background(), rideID)
+		require.NoError(t, err)
+		assert.Equal(t, ride.Status, got.Status)
+	}
+}
+
+func TestRideCache_Expires(t *testing.T) {
+	var cache rideCache
+	ride := &models.Ride{RideID: uuid.New(), Status: models.RideStatusOngoing}
+	now := time.Now()
+
+	cache.put(ride, now, time.Minute)
+
+	assert.NotNil(t, cache.get(ride.RideID.String(), now.Add(59*time.Second)))
+	assert.Nil(t, cache.get(ride.RideID.String(), now.Add(time.Minute)))
+}
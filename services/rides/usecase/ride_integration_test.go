@@ -8,8 +8,11 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/testutil"
+	"github.com/piresc/nebengjek/services/rides"
 	"github.com/piresc/nebengjek/services/rides/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // Integration tests for ride usecase
@@ -26,7 +29,7 @@ func TestRideUC_CreateRide_Success(t *testing.T) {
 		},
 	}
 
-	uc, _ := NewRideUC(cfg, mockRepo, mockGW)
+	uc, _ := NewRideUC(cfg, mockRepo, mockGW, nil)
 
 	// Test data
 	matchProposal := models.MatchProposal{
@@ -50,11 +53,15 @@ func TestRideUC_CreateRide_Success(t *testing.T) {
 
 	// Mock expectations
 	mockRepo.EXPECT().
-		CreateRide(gomock.Any()).
+		CreateRideWithBilling(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(&models.Ride{}, nil)
 
 	mockGW.EXPECT().
-		PublishRidePickup(gomock.Any(), gomock.Any()).
+		PublishOutboxEvent(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	mockRepo.EXPECT().
+		MarkOutboxEventSent(gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	// Act
@@ -77,7 +84,7 @@ func TestRideUC_StartRide_Success(t *testing.T) {
 		},
 	}
 
-	uc, _ := NewRideUC(cfg, mockRepo, mockGW)
+	uc, _ := NewRideUC(cfg, mockRepo, mockGW, nil)
 
 	// Test data
 	rideID := uuid.New()
@@ -104,7 +111,7 @@ func TestRideUC_StartRide_Success(t *testing.T) {
 		}, nil)
 
 	mockRepo.EXPECT().
-		UpdateRideStatus(gomock.Any(), rideID.String(), models.RideStatusOngoing).
+		UpdateRideStatus(gomock.Any(), rideID.String(), gomock.Any(), models.RideStatusOngoing).
 		Return(nil)
 
 	// Act
@@ -133,7 +140,7 @@ func TestRideUC_RideArrived_Success(t *testing.T) {
 		},
 	}
 
-	uc, _ := NewRideUC(cfg, mockRepo, mockGW)
+	uc, _ := NewRideUC(cfg, mockRepo, mockGW, nil)
 
 	// Test data
 	rideID := uuid.New()
@@ -163,7 +170,13 @@ func TestRideUC_RideArrived_Success(t *testing.T) {
 		Return(15000, nil)
 
 	mockRepo.EXPECT().
-		CreatePayment(gomock.Any(), gomock.Any()).
+		ListBillingEntries(gomock.Any(), rideID.String(), models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{}, nil)
+
+	expectNoEstimate(mockRepo, rideID.String())
+
+	mockRepo.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	// Act
@@ -190,54 +203,18 @@ func TestRideUC_ProcessPayment_Success(t *testing.T) {
 		},
 	}
 
-	uc, _ := NewRideUC(cfg, mockRepo, mockGW)
+	uc, _ := NewRideUC(cfg, mockRepo, mockGW, nil)
 
 	// Test data
-	rideID := uuid.New()
+	ride := testutil.NewOngoingRide(uuid.New().String(), uuid.New().String())
+	pending := testutil.NewPendingPayment(ride, 25000)
 	paymentReq := models.PaymentProccessRequest{
-		RideID:    rideID.String(),
+		RideID:    ride.RideID.String(),
 		TotalCost: 25000,
 		Status:    models.PaymentStatusAccepted,
 	}
 
-	expectedPayment := &models.Payment{
-		PaymentID:    uuid.New(),
-		RideID:       rideID,
-		AdjustedCost: 25000,
-		AdminFee:     2500,
-		DriverPayout: 22500,
-		Status:       models.PaymentStatusAccepted,
-		CreatedAt:    time.Now(),
-	}
-
-	// Mock expectations
-	mockRepo.EXPECT().
-		GetRide(gomock.Any(), rideID.String()).
-		Return(&models.Ride{
-			RideID: rideID,
-			Status: models.RideStatusOngoing,
-		}, nil)
-
-	mockRepo.EXPECT().
-		GetPaymentByRideID(gomock.Any(), rideID.String()).
-		Return(&models.Payment{
-			PaymentID:    expectedPayment.PaymentID,
-			RideID:       rideID,
-			AdjustedCost: 25000,
-			Status:       models.PaymentStatusPending,
-		}, nil)
-
-	mockRepo.EXPECT().
-		UpdatePaymentStatus(gomock.Any(), expectedPayment.PaymentID.String(), models.PaymentStatusAccepted).
-		Return(nil)
-
-	mockRepo.EXPECT().
-		CompleteRide(gomock.Any(), gomock.Any()).
-		Return(nil)
-
-	mockGW.EXPECT().
-		PublishRideCompleted(gomock.Any(), gomock.Any()).
-		Return(nil)
+	expectRideCompletion(mockRepo, mockGW, ride, pending)
 
 	// Act
 	payment, err := uc.ProcessPayment(context.Background(), paymentReq)
@@ -249,6 +226,64 @@ func TestRideUC_ProcessPayment_Success(t *testing.T) {
 	assert.Equal(t, 25000, payment.AdjustedCost)
 }
 
+func TestRideUC_ProcessPayment_DuringMatchMaintenance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	// Maintenance only stops new matching; rides already under way can still be paid for
+	cfg := &models.Config{Match: models.MatchConfig{MaintenanceMode: true}}
+	uc, _ := NewRideUC(cfg, mockRepo, mockGW, nil)
+
+	ride := testutil.NewOngoingRide(uuid.New().String(), uuid.New().String())
+	payment := testutil.NewPendingPayment(ride, 8000)
+	expectRideCompletion(mockRepo, mockGW, ride, payment)
+
+	result, err := uc.ProcessPayment(context.Background(), models.PaymentProccessRequest{
+		RideID:    ride.RideID.String(),
+		TotalCost: 8000,
+		Status:    models.PaymentStatusAccepted,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.PaymentStatusAccepted, result.Status)
+}
+
+func TestRideUC_ProcessBillingUpdate_AfterCompletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, _ := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+
+	ride := testutil.NewOngoingRide(uuid.New().String(), uuid.New().String())
+	payment := testutil.NewPendingPayment(ride, 25000)
+	expectRideCompletion(mockRepo, mockGW, ride, payment)
+
+	_, err := uc.ProcessPayment(context.Background(), models.PaymentProccessRequest{
+		RideID:    ride.RideID.String(),
+		TotalCost: 25000,
+		Status:    models.PaymentStatusAccepted,
+	})
+	require.NoError(t, err)
+
+	// Completion locks the ledger at the fare the passenger paid
+	assert.True(t, ride.LedgerFinalized)
+	assert.Equal(t, payment.AdjustedCost, ride.FinalTotal)
+
+	// A location update that arrives late is refused without touching the ledger
+	mockRepo.EXPECT().
+		GetRide(gomock.Any(), ride.RideID.String()).
+		Return(ride, nil)
+
+	err = uc.ProcessBillingUpdate(context.Background(), ride.RideID.String(), &models.BillingLedger{Distance: 1.2})
+
+	assert.ErrorIs(t, err, rides.ErrLedgerFinalized)
+}
+
 func TestRideUC_ProcessBillingUpdate_Success(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -260,9 +295,12 @@ func TestRideUC_ProcessBillingUpdate_Success(t *testing.T) {
 		Rides: models.RidesConfig{
 			MinDistanceKm: 0.5,
 		},
+		Pricing: models.PricingConfig{
+			RatePerKm: 3000,
+		},
 	}
 
-	uc, _ := NewRideUC(cfg, mockRepo, mockGW)
+	uc, _ := NewRideUC(cfg, mockRepo, mockGW, nil)
 
 	// Test data
 	rideID := uuid.New().String()
@@ -270,7 +308,6 @@ func TestRideUC_ProcessBillingUpdate_Success(t *testing.T) {
 		EntryID:   uuid.New(),
 		RideID:    uuid.MustParse(rideID),
 		Distance:  5.2,
-		Cost:      15000,
 		CreatedAt: time.Now(),
 	}
 
@@ -282,12 +319,20 @@ func TestRideUC_ProcessBillingUpdate_Success(t *testing.T) {
 			Status: models.RideStatusOngoing,
 		}, nil)
 
+	mockRepo.EXPECT().
+		GetRideFare(gomock.Any(), rideID).
+		Return(&models.RideFare{RideID: uuid.MustParse(rideID), RatePerKm: 3000}, nil)
+
 	mockRepo.EXPECT().
 		AddBillingEntry(gomock.Any(), billingEntry).
 		Return(nil)
 
 	mockRepo.EXPECT().
-		UpdateTotalCost(gomock.Any(), rideID, billingEntry.Cost).
+		UpdateTotalCost(gomock.Any(), rideID, 15600).
+		Return(15600, nil)
+
+	mockGW.EXPECT().
+		PublishRideBilling(gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	// Act
@@ -2,11 +2,15 @@ package usecase
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/piresc/nebengjek/services/rides/mocks"
 	"github.com/stretchr/testify/assert"
@@ -22,7 +26,7 @@ func TestCreateRide_Success(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	driverID := uuid.New().String()
@@ -41,24 +45,50 @@ func TestCreateRide_Success(t *testing.T) {
 			Latitude:  -6.185392,
 			Longitude: 106.837153,
 		},
-		MatchStatus: models.MatchStatusAccepted,
+		MatchStatus:         models.MatchStatusAccepted,
+		CorrelationID:       "req-abc-123",
+		EstimatedDistanceKm: 4.2,
+		EstimatedFare:       12600,
 	}
 
 	// Set up expectations
 	mockRepo.EXPECT().
-		CreateRide(gomock.Any()).
-		DoAndReturn(func(ride *models.Ride) (*models.Ride, error) {
+		CreateRideWithBilling(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ride *models.Ride, fare *models.RideFare, event *models.OutboxEvent) (*models.Ride, error) {
 			assert.Equal(t, uuid.MustParse(matchID), ride.MatchID)
+			// Outside every configured region the ride is billed at the global rate
+			assert.Equal(t, "default", fare.Region)
+			assert.Equal(t, cfg.Pricing.RatePerKm, fare.RatePerKm)
+			// The proposal's estimate is kept to compare with the fare charged on arrival
+			assert.Equal(t, 12600, fare.EstimatedFare)
+			assert.Equal(t, 4.2, fare.EstimatedDistanceKm)
 			assert.Equal(t, uuid.MustParse(driverID), ride.DriverID)
 			assert.Equal(t, uuid.MustParse(passengerID), ride.PassengerID)
-
-			// Add ride ID to simulate DB creation
-			ride.RideID = uuid.New()
+			// Proposals without a payment method are paid by QR code
+			assert.Equal(t, models.PaymentMethodQRIS, ride.PaymentMethod)
+			assert.Equal(t, constants.SubjectRidePickup, event.Subject)
+			assert.Equal(t, ride.RideID, event.AggregateID)
+			assert.Equal(t, models.OutboxStatusPending, event.Status)
+
+			var payload models.RideResp
+			require.NoError(t, json.Unmarshal(event.Payload, &payload))
+			assert.Equal(t, ride.RideID.String(), payload.RideID)
+			assert.Equal(t, "req-abc-123", payload.CorrelationID)
+
+			// The pickup point and driver ETA travel with the ride and its pickup event
+			assert.Equal(t, -6.175392, ride.PickupLatitude)
+			assert.Equal(t, 106.827153, ride.PickupLongitude)
+			assert.Greater(t, ride.PickupETASeconds, 0)
+			assert.Equal(t, ride.PickupETASeconds, payload.PickupETASeconds)
 			return ride, nil
 		})
 
 	mockGW.EXPECT().
-		PublishRidePickup(gomock.Any(), gomock.Any()).
+		PublishOutboxEvent(gomock.Any(), gomock.Any()).
+		Return(nil)
+
+	mockRepo.EXPECT().
+		MarkOutboxEventSent(gomock.Any(), gomock.Any()).
 		Return(nil)
 
 	// Act
@@ -77,7 +107,7 @@ func TestCreateRide_RepositoryError(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	driverID := uuid.New().String()
@@ -95,7 +125,7 @@ func TestCreateRide_RepositoryError(t *testing.T) {
 
 	// Set up expectations
 	mockRepo.EXPECT().
-		CreateRide(gomock.Any()).
+		CreateRideWithBilling(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 		Return(nil, expectedError)
 
 	// Act
@@ -106,6 +136,44 @@ func TestCreateRide_RepositoryError(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 }
 
+func TestCreateRide_PublishKeepsRequestContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	type traceKey struct{}
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), traceKey{}, "trace-1"))
+
+	// The request ends once the ride is committed, before the event is published
+	mockRepo.EXPECT().
+		CreateRideWithBilling(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ride *models.Ride, _ *models.RideFare, _ *models.OutboxEvent) (*models.Ride, error) {
+			cancel()
+			return ride, nil
+		})
+	mockGW.EXPECT().
+		PublishOutboxEvent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(publishCtx context.Context, _ *models.OutboxEvent) error {
+			assert.Equal(t, "trace-1", publishCtx.Value(traceKey{}))
+			assert.NoError(t, publishCtx.Err())
+			return nil
+		})
+	mockRepo.EXPECT().MarkOutboxEventSent(gomock.Any(), gomock.Any()).Return(nil)
+
+	err = uc.CreateRide(ctx, models.MatchProposal{
+		ID:          uuid.New().String(),
+		DriverID:    uuid.New().String(),
+		PassengerID: uuid.New().String(),
+		MatchStatus: models.MatchStatusAccepted,
+	})
+
+	require.NoError(t, err)
+}
+
 func TestCreateRide_PublishError(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -115,7 +183,7 @@ func TestCreateRide_PublishError(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	driverID := uuid.New().String()
@@ -132,23 +200,144 @@ func TestCreateRide_PublishError(t *testing.T) {
 	expectedError := errors.New("publish error")
 
 	// Set up expectations
+	var storedEvent *models.OutboxEvent
 	mockRepo.EXPECT().
-		CreateRide(gomock.Any()).
-		DoAndReturn(func(ride *models.Ride) (*models.Ride, error) {
-			ride.RideID = uuid.New()
+		CreateRideWithBilling(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ride *models.Ride, _ *models.RideFare, event *models.OutboxEvent) (*models.Ride, error) {
+			storedEvent = event
 			return ride, nil
 		})
 
 	mockGW.EXPECT().
-		PublishRidePickup(gomock.Any(), gomock.Any()).
+		PublishOutboxEvent(gomock.Any(), gomock.Any()).
 		Return(expectedError)
 
+	mockRepo.EXPECT().
+		MarkOutboxEventFailed(gomock.Any(), gomock.Any(), expectedError.Error()).
+		DoAndReturn(func(_ context.Context, eventID uuid.UUID, _ string) error {
+			assert.Equal(t, storedEvent.EventID, eventID)
+			return nil
+		})
+
 	// Act
 	err = uc.CreateRide(context.Background(), matchProposal)
 
+	// Assert: the ride is stored and the event stays in the outbox for the relay
+	assert.NoError(t, err)
+}
+
+func TestRelayOutboxEvents_Success(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	cfg := &models.Config{}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	events := []*models.OutboxEvent{
+		{EventID: uuid.New(), Subject: constants.SubjectRidePickup, Payload: []byte(`{}`)},
+		{EventID: uuid.New(), Subject: constants.SubjectRidePickup, Payload: []byte(`{}`)},
+	}
+
+	mockRepo.EXPECT().ClaimPendingOutboxEvents(gomock.Any(), 10).Return(events, nil)
+	for _, event := range events {
+		mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), event).Return(nil)
+		mockRepo.EXPECT().MarkOutboxEventSent(gomock.Any(), event.EventID).Return(nil)
+	}
+
+	// Act
+	sent, err := uc.RelayOutboxEvents(context.Background(), 10)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, sent)
+}
+
+func TestRelayOutboxEvents_PublishFailureKeepsEventPending(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	cfg := &models.Config{}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	failed := &models.OutboxEvent{EventID: uuid.New(), AggregateID: uuid.New(), Subject: constants.SubjectRidePickup}
+	delivered := &models.OutboxEvent{EventID: uuid.New(), AggregateID: uuid.New(), Subject: constants.SubjectRidePickup}
+
+	// A zero limit falls back to the default batch size
+	mockRepo.EXPECT().
+		ClaimPendingOutboxEvents(gomock.Any(), defaultOutboxBatchSize).
+		Return([]*models.OutboxEvent{failed, delivered}, nil)
+	mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), failed).Return(errors.New("nats unavailable"))
+	mockRepo.EXPECT().MarkOutboxEventFailed(gomock.Any(), failed.EventID, "nats unavailable").Return(nil)
+	mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), delivered).Return(nil)
+	mockRepo.EXPECT().MarkOutboxEventSent(gomock.Any(), delivered.EventID).Return(nil)
+
+	// Act
+	sent, err := uc.RelayOutboxEvents(context.Background(), 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestRelayOutboxEvents_PublishFailureHoldsBackRideEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	rideID := uuid.New()
+	pickup := &models.OutboxEvent{EventID: uuid.New(), AggregateID: rideID, Subject: constants.SubjectRidePickup}
+	cancelled := &models.OutboxEvent{EventID: uuid.New(), AggregateID: rideID, Subject: constants.SubjectRideCancelled}
+	other := &models.OutboxEvent{EventID: uuid.New(), AggregateID: uuid.New(), Subject: constants.SubjectRidePickup}
+
+	mockRepo.EXPECT().
+		ClaimPendingOutboxEvents(gomock.Any(), 10).
+		Return([]*models.OutboxEvent{pickup, other, cancelled}, nil)
+	mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), pickup).Return(errors.New("nats unavailable"))
+	mockRepo.EXPECT().MarkOutboxEventFailed(gomock.Any(), pickup.EventID, "nats unavailable").Return(nil)
+	mockGW.EXPECT().PublishOutboxEvent(gomock.Any(), other).Return(nil)
+	mockRepo.EXPECT().MarkOutboxEventSent(gomock.Any(), other.EventID).Return(nil)
+
+	// The cancellation must not reach subscribers ahead of the pickup it follows, so it waits
+	sent, err := uc.RelayOutboxEvents(context.Background(), 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+}
+
+func TestRelayOutboxEvents_ListError(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	cfg := &models.Config{}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	mockRepo.EXPECT().ClaimPendingOutboxEvents(gomock.Any(), 5).Return(nil, errors.New("database error"))
+
+	// Act
+	sent, err := uc.RelayOutboxEvents(context.Background(), 5)
+
 	// Assert
 	assert.Error(t, err)
-	assert.Equal(t, expectedError, err)
+	assert.Equal(t, 0, sent)
 }
 
 func TestProcessBillingUpdate_Success(t *testing.T) {
@@ -159,8 +348,8 @@ func TestProcessBillingUpdate_Success(t *testing.T) {
 	mockRepo := mocks.NewMockRideRepo(ctrl)
 	mockGW := mocks.NewMockRideGW(ctrl)
 
-	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	cfg := &models.Config{Pricing: models.PricingConfig{RatePerKm: 3000}}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -183,13 +372,27 @@ func TestProcessBillingUpdate_Success(t *testing.T) {
 		GetRide(gomock.Any(), rideID).
 		Return(ride, nil)
 
+	mockRepo.EXPECT().
+		GetRideFare(gomock.Any(), rideID).
+		Return(&models.RideFare{RideID: rideUUID, RatePerKm: 3000}, nil)
+
 	mockRepo.EXPECT().
 		AddBillingEntry(gomock.Any(), entry).
 		Return(nil)
 
 	mockRepo.EXPECT().
 		UpdateTotalCost(gomock.Any(), rideID, entry.Cost).
-		Return(nil)
+		Return(17500, nil)
+
+	// Participants are sent the new entry with the ride's running total
+	mockGW.EXPECT().
+		PublishRideBilling(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, update *models.RideBillingUpdate) error {
+			assert.Equal(t, rideID, update.RideID)
+			assert.Equal(t, 7500, update.Cost)
+			assert.Equal(t, 17500, update.TotalCost)
+			return nil
+		})
 
 	// Act
 	err = uc.ProcessBillingUpdate(context.Background(), rideID, entry)
@@ -198,6 +401,137 @@ func TestProcessBillingUpdate_Success(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestProcessBillingUpdate_PublishFailureKeepsEntry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	uc, err := NewRideUC(&models.Config{Pricing: models.PricingConfig{RatePerKm: 3000}}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{RideID: uuid.New(), Status: models.RideStatusOngoing}
+	rideID := ride.RideID.String()
+	entry := &models.BillingLedger{Distance: 1.0}
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(&models.RideFare{RideID: ride.RideID, RatePerKm: 3000}, nil)
+	mockRepo.EXPECT().AddBillingEntry(gomock.Any(), entry).Return(nil)
+	mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 3000).Return(3000, nil)
+	mockGW.EXPECT().PublishRideBilling(gomock.Any(), gomock.Any()).Return(errors.New("nats down"))
+
+	// The entry is recorded, so the update must not be redelivered and billed again
+	err = uc.ProcessBillingUpdate(context.Background(), rideID, entry)
+	assert.NoError(t, err)
+}
+
+func TestProcessBillingUpdate_RegionalRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	cfg := &models.Config{
+		Pricing: models.PricingConfig{RatePerKm: 3000},
+		Regions: []models.RegionConfig{
+			{Name: "jakarta", GeohashPrefix: "qqgu", RatePerKm: 3500},
+			{Name: "bogor", GeohashPrefix: "qqgf", RatePerKm: 2500},
+		},
+	}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	// Rides without a recorded fare are priced by the region they were picked up in
+	for _, tc := range []struct {
+		pickup       models.Location
+		expectedCost int
+	}{
+		{pickup: models.Location{Latitude: -6.2088, Longitude: 106.8456}, expectedCost: 7000},
+		{pickup: models.Location{Latitude: -6.5971, Longitude: 106.8060}, expectedCost: 5000},
+		{pickup: models.Location{Latitude: -7.7956, Longitude: 110.3695}, expectedCost: 6000},
+	} {
+		ride := &models.Ride{
+			RideID:          uuid.New(),
+			Status:          models.RideStatusOngoing,
+			PickupLatitude:  tc.pickup.Latitude,
+			PickupLongitude: tc.pickup.Longitude,
+		}
+		rideID := ride.RideID.String()
+		entry := &models.BillingLedger{Distance: 2.0}
+
+		mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+		mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(nil, fmt.Errorf("failed to get ride fare: %w", sql.ErrNoRows))
+		mockRepo.EXPECT().AddBillingEntry(gomock.Any(), entry).Return(nil)
+		mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, tc.expectedCost).Return(tc.expectedCost, nil)
+		mockGW.EXPECT().PublishRideBilling(gomock.Any(), gomock.Any()).Return(nil)
+
+		err = uc.ProcessBillingUpdate(context.Background(), rideID, entry)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expectedCost, entry.Cost)
+	}
+}
+
+func TestProcessBillingUpdate_SnapshottedRate(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	// Jakarta's rate was raised after the ride was accepted at 3500 per km
+	cfg := &models.Config{
+		Pricing: models.PricingConfig{RatePerKm: 3000},
+		Regions: []models.RegionConfig{
+			{Name: "jakarta", GeohashPrefix: "qqgu", RatePerKm: 4500},
+		},
+	}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{
+		RideID:          uuid.New(),
+		Status:          models.RideStatusOngoing,
+		PickupLatitude:  -6.2088,
+		PickupLongitude: 106.8456,
+	}
+	rideID := ride.RideID.String()
+	entry := &models.BillingLedger{Distance: 2.0}
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(&models.RideFare{RideID: ride.RideID, Region: "jakarta", RatePerKm: 3500}, nil)
+	mockRepo.EXPECT().AddBillingEntry(gomock.Any(), entry).Return(nil)
+	mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 7000).Return(7000, nil)
+	mockGW.EXPECT().PublishRideBilling(gomock.Any(), gomock.Any()).Return(nil)
+
+	err = uc.ProcessBillingUpdate(context.Background(), rideID, entry)
+	assert.NoError(t, err)
+	assert.Equal(t, 7000, entry.Cost)
+}
+
+func TestProcessBillingUpdate_FareLookupError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	uc, err := NewRideUC(&models.Config{Pricing: models.PricingConfig{RatePerKm: 3000}}, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{RideID: uuid.New(), Status: models.RideStatusOngoing}
+	rideID := ride.RideID.String()
+
+	// The entry is redelivered rather than priced at a rate the ride was not accepted under
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetRideFare(gomock.Any(), rideID).Return(nil, errors.New("connection reset"))
+
+	err = uc.ProcessBillingUpdate(context.Background(), rideID, &models.BillingLedger{Distance: 2.0})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to get ride fare")
+}
+
 func TestProcessBillingUpdate_GetRideError(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -207,7 +541,7 @@ func TestProcessBillingUpdate_GetRideError(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -243,7 +577,7 @@ func TestProcessBillingUpdate_InvalidRideStatus(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -283,7 +617,7 @@ func TestStartRide_Success(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -312,7 +646,7 @@ func TestStartRide_Success(t *testing.T) {
 		Return(ride, nil)
 
 	mockRepo.EXPECT().
-		UpdateRideStatus(gomock.Any(), rideID, models.RideStatusOngoing).
+		UpdateRideStatus(gomock.Any(), rideID, gomock.Any(), models.RideStatusOngoing).
 		Return(nil)
 
 	// Act
@@ -333,7 +667,7 @@ func TestStartRide_DriverTooFar(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -370,6 +704,129 @@ func TestStartRide_DriverTooFar(t *testing.T) {
 	assert.Equal(t, models.Ride{}, *result)
 }
 
+func TestStartRide_ConfigurablePickupDistance(t *testing.T) {
+	// Driver and passenger roughly 150 meters apart
+	driverLocation := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	passengerLocation := &models.Location{Latitude: -6.176740, Longitude: 106.827153}
+
+	tests := []struct {
+		name              string
+		maxDistanceMeters float64
+		expectStarted     bool
+	}{
+		{name: "default threshold rejects", maxDistanceMeters: 0, expectStarted: false},
+		{name: "tight threshold rejects", maxDistanceMeters: 50, expectStarted: false},
+		{name: "relaxed threshold accepts", maxDistanceMeters: 200, expectStarted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			mockGW := mocks.NewMockRideGW(ctrl)
+
+			cfg := &models.Config{
+				Rides: models.RidesConfig{MaxPickupDistanceMeters: tt.maxDistanceMeters},
+			}
+			uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+			require.NoError(t, err)
+
+			rideID := uuid.New().String()
+			ride := &models.Ride{
+				RideID: uuid.MustParse(rideID),
+				Status: models.RideStatusDriverPickup,
+			}
+
+			mockRepo.EXPECT().
+				GetRide(gomock.Any(), rideID).
+				Return(ride, nil)
+
+			if tt.expectStarted {
+				mockRepo.EXPECT().
+					UpdateRideStatus(gomock.Any(), rideID, gomock.Any(), models.RideStatusOngoing).
+					Return(nil)
+			}
+
+			result, err := uc.StartRide(context.Background(), models.RideStartRequest{
+				RideID:            rideID,
+				DriverLocation:    driverLocation,
+				PassengerLocation: passengerLocation,
+			})
+
+			if tt.expectStarted {
+				assert.NoError(t, err)
+				assert.Equal(t, models.RideStatusOngoing, result.Status)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "driver is too far from passenger")
+			}
+		})
+	}
+}
+
+func TestStartRide_UsesUpdatedPickupLocation(t *testing.T) {
+	// The passenger walked about 300 meters after the proposal went out; the ride's pickup
+	// point holds the updated position while the driver's app still reports the original one
+	staleLocation := &models.Location{Latitude: -6.175392, Longitude: 106.827153}
+	updatedPickup := models.Location{Latitude: -6.178090, Longitude: 106.827153}
+
+	tests := []struct {
+		name           string
+		driverLocation *models.Location
+		expectStarted  bool
+	}{
+		{name: "driver at stale location rejected", driverLocation: staleLocation, expectStarted: false},
+		{name: "driver at updated pickup accepted", driverLocation: &models.Location{Latitude: -6.178100, Longitude: 106.827160}, expectStarted: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			mockGW := mocks.NewMockRideGW(ctrl)
+
+			uc, err := NewRideUC(&models.Config{}, mockRepo, mockGW, nil)
+			require.NoError(t, err)
+
+			rideID := uuid.New().String()
+			ride := &models.Ride{
+				RideID:          uuid.MustParse(rideID),
+				Status:          models.RideStatusDriverPickup,
+				PickupLatitude:  updatedPickup.Latitude,
+				PickupLongitude: updatedPickup.Longitude,
+			}
+
+			mockRepo.EXPECT().
+				GetRide(gomock.Any(), rideID).
+				Return(ride, nil)
+
+			if tt.expectStarted {
+				mockRepo.EXPECT().
+					UpdateRideStatus(gomock.Any(), rideID, gomock.Any(), models.RideStatusOngoing).
+					Return(nil)
+			}
+
+			result, err := uc.StartRide(context.Background(), models.RideStartRequest{
+				RideID:            rideID,
+				DriverLocation:    tt.driverLocation,
+				PassengerLocation: staleLocation,
+			})
+
+			if tt.expectStarted {
+				assert.NoError(t, err)
+				assert.Equal(t, models.RideStatusOngoing, result.Status)
+			} else {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "driver is too far from passenger")
+			}
+		})
+	}
+}
+
 func TestStartRide_InvalidStatus(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -379,7 +836,7 @@ func TestStartRide_InvalidStatus(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -429,7 +886,7 @@ func TestRideArrived_Success(t *testing.T) {
 			AdminFeePercent: 5.0, // 5% admin fee
 		},
 	}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -465,8 +922,14 @@ func TestRideArrived_Success(t *testing.T) {
 		Return(totalCost, nil)
 
 	mockRepo.EXPECT().
-		CreatePayment(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(_ context.Context, payment *models.Payment) error {
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{}, nil)
+
+	expectNoEstimate(mockRepo, rideID)
+
+	mockRepo.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, payment *models.Payment, _ string) error {
 			assert.Equal(t, rideUUID, payment.RideID)
 			assert.Equal(t, adjustedCost, payment.AdjustedCost)
 			assert.Equal(t, adminFee, payment.AdminFee)
@@ -486,6 +949,132 @@ func TestRideArrived_Success(t *testing.T) {
 	assert.Equal(t, adjustedCost, paymentRequest.TotalCost)
 }
 
+func TestRideArrived_CashSettlesImmediately(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	cfg := &models.Config{
+		Payment: models.PaymentConfig{QRCodeBaseURL: "https://pay.example.com/qr"},
+		Pricing: models.PricingConfig{AdminFeePercent: 5.0},
+	}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{
+		RideID:        uuid.New(),
+		DriverID:      uuid.New(),
+		PassengerID:   uuid.New(),
+		Status:        models.RideStatusOngoing,
+		PaymentMethod: models.PaymentMethodCash,
+	}
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().
+		GetRide(gomock.Any(), rideID).
+		Return(ride, nil)
+
+	mockRepo.EXPECT().
+		GetBillingLedgerSum(gomock.Any(), rideID).
+		Return(10000, nil)
+
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{}, nil)
+
+	expectNoEstimate(mockRepo, rideID)
+
+	// The payment is recorded as already accepted and the ride completes in the same call
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			CreatePayment(gomock.Any(), gomock.Any(), "driver:"+ride.DriverID.String()).
+			DoAndReturn(func(_ context.Context, payment *models.Payment, _ string) error {
+				assert.Equal(t, models.PaymentStatusAccepted, payment.Status)
+				assert.Equal(t, 10000, payment.AdjustedCost)
+				return nil
+			}),
+		mockRepo.EXPECT().
+			CompleteRide(gomock.Any(), ride).
+			Return(nil),
+		mockGW.EXPECT().
+			PublishRideCompleted(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, rc models.RideComplete) error {
+				assert.Equal(t, models.RideStatusCompleted, rc.Ride.Status)
+				assert.Equal(t, models.PaymentStatusAccepted, rc.Payment.Status)
+				return nil
+			}),
+	)
+
+	// Act
+	paymentRequest, err := uc.RideArrived(context.Background(), models.RideArrivalReq{RideID: rideID, AdjustmentFactor: 1.0})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusCompleted, ride.Status)
+	assert.Equal(t, models.PaymentMethodCash, paymentRequest.PaymentMethod)
+	assert.Equal(t, 10000, paymentRequest.TotalCost)
+	assert.Empty(t, paymentRequest.QRCodeURL)
+}
+
+func TestRideArrived_NonCashAwaitsPayment(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+
+	cfg := &models.Config{
+		Payment: models.PaymentConfig{QRCodeBaseURL: "https://pay.example.com/qr"},
+		Pricing: models.PricingConfig{AdminFeePercent: 5.0},
+	}
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{
+		RideID:        uuid.New(),
+		DriverID:      uuid.New(),
+		PassengerID:   uuid.New(),
+		Status:        models.RideStatusOngoing,
+		PaymentMethod: models.PaymentMethodQRIS,
+	}
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().
+		GetRide(gomock.Any(), rideID).
+		Return(ride, nil)
+
+	mockRepo.EXPECT().
+		GetBillingLedgerSum(gomock.Any(), rideID).
+		Return(10000, nil)
+
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{}, nil)
+
+	expectNoEstimate(mockRepo, rideID)
+
+	// Only the pending payment is created; completion waits for ProcessPayment
+	mockRepo.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, payment *models.Payment, _ string) error {
+			assert.Equal(t, models.PaymentStatusPending, payment.Status)
+			return nil
+		})
+
+	// Act
+	paymentRequest, err := uc.RideArrived(context.Background(), models.RideArrivalReq{RideID: rideID, AdjustmentFactor: 1.0})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusOngoing, ride.Status)
+	assert.Equal(t, models.PaymentMethodQRIS, paymentRequest.PaymentMethod)
+	assert.Contains(t, paymentRequest.QRCodeURL, "https://pay.example.com/qr?ride_id="+rideID)
+}
+
 func TestRideArrived_InvalidStatus(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -495,7 +1084,7 @@ func TestRideArrived_InvalidStatus(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -534,7 +1123,7 @@ func TestProcessPayment_Success(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -570,13 +1159,27 @@ func TestProcessPayment_Success(t *testing.T) {
 		Return(payment, nil)
 
 	mockRepo.EXPECT().
-		UpdatePaymentStatus(gomock.Any(), paymentID.String(), models.PaymentStatusAccepted).
-		Return(nil)
+		UpdatePaymentStatus(gomock.Any(), gomock.Any(), models.PaymentStatusAccepted, "passenger:"+ride.PassengerID.String()).
+		DoAndReturn(func(_ context.Context, current *models.Payment, _ models.PaymentStatus, _ string) error {
+			// The transition is recorded from the status the payment had when it was read
+			assert.Equal(t, paymentID, current.PaymentID)
+			assert.Equal(t, models.PaymentStatusPending, current.Status)
+			return nil
+		})
+
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, gomock.Any()).
+		Return([]*models.BillingLedger{}, nil).
+		Times(2)
+
+	expectNoEstimate(mockRepo, rideID)
 
 	mockRepo.EXPECT().
 		CompleteRide(gomock.Any(), gomock.Any()).
 		DoAndReturn(func(_ context.Context, updatedRide *models.Ride) error {
 			assert.Equal(t, models.RideStatusCompleted, updatedRide.Status)
+			assert.True(t, updatedRide.LedgerFinalized)
+			assert.Equal(t, totalCost, updatedRide.FinalTotal)
 			return nil
 		})
 
@@ -602,7 +1205,7 @@ func TestProcessPayment_TotalCostMismatch(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -646,69 +1249,36 @@ func TestProcessPayment_TotalCostMismatch(t *testing.T) {
 }
 
 func TestRideArrived_InvalidAdjustmentFactor(t *testing.T) {
-	// Arrange
-	ctrl := gomock.NewController(t)
-	defer ctrl.Finish()
-
-	mockRepo := mocks.NewMockRideRepo(ctrl)
-	mockGW := mocks.NewMockRideGW(ctrl)
-
-	cfg := &models.Config{
-		Pricing: models.PricingConfig{
-			AdminFeePercent: 5.0, // 5% admin fee
-		},
-	}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
-	require.NoError(t, err)
-
-	rideID := uuid.New().String()
-	rideUUID := uuid.MustParse(rideID)
-	passengerID := uuid.New()
-	totalCost := 10000
-
-	req := models.RideArrivalReq{
-		RideID:           rideID,
-		AdjustmentFactor: 1.5, // Invalid - should be reset to 1.0
-	}
-
-	ride := &models.Ride{
-		RideID:      rideUUID,
-		PassengerID: passengerID,
-		Status:      models.RideStatusOngoing,
+	for _, factor := range []float64{-0.1, 1.5} {
+		// Arrange
+		ctrl := gomock.NewController(t)
+
+		mockRepo := mocks.NewMockRideRepo(ctrl)
+		mockGW := mocks.NewMockRideGW(ctrl)
+
+		cfg := &models.Config{
+			Pricing: models.PricingConfig{
+				AdminFeePercent: 5.0, // 5% admin fee
+			},
+		}
+		uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
+		require.NoError(t, err)
+
+		req := models.RideArrivalReq{
+			RideID:           uuid.New().String(),
+			AdjustmentFactor: factor,
+		}
+
+		// Act - no repository calls expected, the request is rejected up front
+		paymentRequest, err := uc.RideArrived(context.Background(), req)
+
+		// Assert
+		var fieldErr *models.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "adjustment_factor", fieldErr.Field)
+		assert.Nil(t, paymentRequest)
+		ctrl.Finish()
 	}
-
-	// Expected values with adjustment factor reset to 1.0
-	adjustedCost := totalCost
-	adminFeePercent := 5.0 / 100.0 // Use same default as config
-	adminFee := int(float64(adjustedCost) * adminFeePercent)
-	driverPayout := adjustedCost - adminFee
-
-	// Set up expectations
-	mockRepo.EXPECT().
-		GetRide(gomock.Any(), rideID).
-		Return(ride, nil)
-
-	mockRepo.EXPECT().
-		GetBillingLedgerSum(gomock.Any(), rideID).
-		Return(totalCost, nil)
-
-	mockRepo.EXPECT().
-		CreatePayment(gomock.Any(), gomock.Any()).
-		DoAndReturn(func(_ context.Context, payment *models.Payment) error {
-			assert.Equal(t, rideUUID, payment.RideID)
-			assert.Equal(t, adjustedCost, payment.AdjustedCost)
-			assert.Equal(t, adminFee, payment.AdminFee)
-			assert.Equal(t, driverPayout, payment.DriverPayout)
-			return nil
-		})
-
-	// Act
-	paymentRequest, err := uc.RideArrived(context.Background(), req)
-
-	// Assert
-	assert.NoError(t, err)
-	assert.NotNil(t, paymentRequest)
-	assert.Equal(t, totalCost, paymentRequest.TotalCost) // Should be reset to full cost
 }
 
 func TestProcessPayment_InvalidStatus(t *testing.T) {
@@ -720,7 +1290,7 @@ func TestProcessPayment_InvalidStatus(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -760,7 +1330,7 @@ func TestProcessPayment_PaymentAlreadyProcessed(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -812,7 +1382,7 @@ func TestProcessPayment_RejectedPayment(t *testing.T) {
 	mockGW := mocks.NewMockRideGW(ctrl)
 
 	cfg := &models.Config{}
-	uc, err := NewRideUC(cfg, mockRepo, mockGW)
+	uc, err := NewRideUC(cfg, mockRepo, mockGW, nil)
 	require.NoError(t, err)
 
 	rideID := uuid.New().String()
@@ -848,8 +1418,13 @@ func TestProcessPayment_RejectedPayment(t *testing.T) {
 		Return(payment, nil)
 
 	mockRepo.EXPECT().
-		UpdatePaymentStatus(gomock.Any(), paymentID.String(), models.PaymentStatusRejected).
-		Return(nil)
+		UpdatePaymentStatus(gomock.Any(), gomock.Any(), models.PaymentStatusRejected, "passenger:"+ride.PassengerID.String()).
+		DoAndReturn(func(_ context.Context, current *models.Payment, _ models.PaymentStatus, _ string) error {
+			// The transition is recorded from the status the payment had when it was read
+			assert.Equal(t, paymentID, current.PaymentID)
+			assert.Equal(t, models.PaymentStatusPending, current.Status)
+			return nil
+		})
 
 	// Note: No CompleteRide or PublishRideCompleted calls for rejected payment
 
@@ -861,3 +1436,53 @@ func TestProcessPayment_RejectedPayment(t *testing.T) {
 	assert.NotNil(t, result)
 	assert.Equal(t, models.PaymentStatusRejected, result.Status)
 }
+
+func TestSplitPayment_RoundingModes(t *testing.T) {
+	tests := []struct {
+		name        string
+		rounding    string
+		cost        int
+		expectedFee int
+	}{
+		{name: "truncate drops fraction", rounding: models.RoundingModeTruncate, cost: 1010, expectedFee: 50},
+		{name: "empty mode truncates", rounding: "", cost: 1019, expectedFee: 50},
+		{name: "round half up rounds down below half", rounding: models.RoundingModeRoundHalfUp, cost: 1009, expectedFee: 50},
+		{name: "round half up rounds up at half", rounding: models.RoundingModeRoundHalfUp, cost: 1010, expectedFee: 51},
+		{name: "ceil rounds up any fraction", rounding: models.RoundingModeCeil, cost: 1001, expectedFee: 51},
+		{name: "ceil keeps exact multiples", rounding: models.RoundingModeCeil, cost: 1100, expectedFee: 55},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &rideUC{cfg: &models.Config{
+				Pricing: models.PricingConfig{AdminFeePercent: 5.0, AdminFeeRounding: tt.rounding},
+			}}
+
+			adminFee, driverPayout := uc.splitPayment(tt.cost)
+
+			assert.Equal(t, tt.expectedFee, adminFee)
+			assert.Equal(t, tt.cost-tt.expectedFee, driverPayout)
+		})
+	}
+}
+
+func TestSplitPayment_SumsToAdjustedCost(t *testing.T) {
+	modes := []string{models.RoundingModeTruncate, models.RoundingModeRoundHalfUp, models.RoundingModeCeil}
+	percents := []float64{0, 5.0, 7.5, 12.3, 100}
+
+	for _, mode := range modes {
+		for _, percent := range percents {
+			uc := &rideUC{cfg: &models.Config{
+				Pricing: models.PricingConfig{AdminFeePercent: percent, AdminFeeRounding: mode},
+			}}
+
+			for cost := 0; cost <= 50000; cost += 37 {
+				adminFee, driverPayout := uc.splitPayment(cost)
+
+				require.Equal(t, cost, adminFee+driverPayout, "mode=%s percent=%v cost=%d", mode, percent, cost)
+				require.GreaterOrEqual(t, adminFee, 0)
+				require.GreaterOrEqual(t, driverPayout, 0)
+			}
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// surchargeAmount returns the flat amount of an approved surcharge type
+func (uc *rideUC) surchargeAmount(surchargeType string) (int, bool) {
+	for _, surcharge := range uc.config().Surcharges {
+		if strings.EqualFold(surcharge.Name, surchargeType) {
+			return surcharge.Amount, true
+		}
+	}
+	return 0, false
+}
+
+// AddSurcharge records an approved flat surcharge, such as a toll, requested by the driver of an
+// ongoing ride. The amount comes from configuration rather than the driver.
+func (uc *rideUC) AddSurcharge(ctx context.Context, req models.SurchargeRequest) (*models.BillingLedger, error) {
+	amount, ok := uc.surchargeAmount(req.Type)
+	if !ok {
+		return nil, fmt.Errorf("unknown surcharge type: %s", req.Type)
+	}
+
+	ride, err := uc.ridesRepo.GetRide(ctx, req.RideID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ride: %w", err)
+	}
+
+	if ride.DriverID.String() != req.DriverID {
+		return nil, fmt.Errorf("user %s is not the driver of ride %s", req.DriverID, req.RideID)
+	}
+
+	if ride.Status != models.RideStatusOngoing {
+		return nil, fmt.Errorf("cannot add surcharge to ride with status: %s", ride.Status)
+	}
+
+	entry := &models.BillingLedger{
+		EntryID:     uuid.New(),
+		RideID:      ride.RideID,
+		Category:    models.BillingCategorySurcharge,
+		Description: strings.ToLower(req.Type),
+		Cost:        amount,
+		CreatedAt:   time.Now(),
+	}
+
+	if err := uc.ridesRepo.AddBillingEntry(ctx, entry); err != nil {
+		return nil, fmt.Errorf("failed to add surcharge: %w", err)
+	}
+
+	if _, err := uc.ridesRepo.UpdateTotalCost(ctx, req.RideID, amount); err != nil {
+		return nil, fmt.Errorf("failed to update total cost: %w", err)
+	}
+
+	logger.Info("Added surcharge to ride",
+		logger.String("ride_id", req.RideID),
+		logger.String("type", entry.Description),
+		logger.Int("amount", amount))
+	return entry, nil
+}
+
+// fareBreakdown splits a ride's ledger total into the adjusted distance fare and its surcharges.
+// The driver's adjustment only discounts the distance fare; surcharges such as tolls are passed on in full.
+func fareBreakdown(totalCost int, surcharges []*models.BillingLedger, adjustmentFactor float64) models.FareBreakdown {
+	breakdown := models.FareBreakdown{}
+	for _, surcharge := range surcharges {
+		breakdown.SurchargeCost += surcharge.Cost
+		breakdown.Surcharges = append(breakdown.Surcharges, models.SurchargeItem{
+			Type:   surcharge.Description,
+			Amount: surcharge.Cost,
+		})
+	}
+	breakdown.DistanceCost = int(float64(totalCost-breakdown.SurchargeCost) * adjustmentFactor)
+	return breakdown
+}
+
+// settledBreakdown rebuilds the breakdown of a ride's accepted payment from its surcharge and discount
+// entries, for completions that happen after the arrival that priced the fare
+func (uc *rideUC) settledBreakdown(ctx context.Context, ride *models.Ride, payment *models.Payment) (*models.FareBreakdown, error) {
+	rideID := ride.RideID.String()
+	surcharges, err := uc.ridesRepo.ListBillingEntries(ctx, rideID, models.BillingCategorySurcharge)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list surcharges: %w", err)
+	}
+	discounts, err := uc.ridesRepo.ListBillingEntries(ctx, rideID, models.BillingCategoryDiscount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list discounts: %w", err)
+	}
+
+	breakdown := fareBreakdown(0, surcharges, 1)
+	if len(discounts) > 0 {
+		breakdown.PromoCode = discounts[0].Description
+		breakdown.Discount = -discounts[0].Cost
+	}
+	breakdown.DistanceCost = payment.AdjustedCost - breakdown.SurchargeCost + breakdown.Discount
+	uc.attachEstimate(ctx, rideID, &breakdown)
+	return &breakdown, nil
+}
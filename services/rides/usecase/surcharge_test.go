@@ -0,0 +1,193 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// surchargeConfig approves a 10000 toll and a 15000 airport surcharge
+func surchargeConfig() *models.Config {
+	return &models.Config{
+		Payment: models.PaymentConfig{QRCodeBaseURL: "https://pay.example.com/qr"},
+		Pricing: models.PricingConfig{AdminFeePercent: 5.0},
+		Surcharges: []models.SurchargeConfig{
+			{Name: "toll", Amount: 10000},
+			{Name: "airport", Amount: 15000},
+		},
+	}
+}
+
+func newOngoingRide() *models.Ride {
+	return &models.Ride{
+		RideID:        uuid.New(),
+		DriverID:      uuid.New(),
+		PassengerID:   uuid.New(),
+		Status:        models.RideStatusOngoing,
+		PaymentMethod: models.PaymentMethodQRIS,
+	}
+}
+
+func TestAddSurcharge_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(surchargeConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newOngoingRide()
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().
+		AddBillingEntry(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry *models.BillingLedger) error {
+			assert.Equal(t, ride.RideID, entry.RideID)
+			assert.Equal(t, models.BillingCategorySurcharge, entry.Category)
+			assert.Equal(t, "toll", entry.Description)
+			assert.Equal(t, 10000, entry.Cost)
+			assert.Zero(t, entry.Distance)
+			return nil
+		})
+	mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 10000).Return(10000, nil)
+
+	entry, err := uc.AddSurcharge(context.Background(), models.SurchargeRequest{
+		RideID:   rideID,
+		DriverID: ride.DriverID.String(),
+		Type:     "TOLL",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 10000, entry.Cost)
+}
+
+func TestAddSurcharge_Rejected(t *testing.T) {
+	tests := []struct {
+		name          string
+		surchargeType string
+		status        models.RideStatus
+		otherDriver   bool
+		expectGetRide bool
+		expectedError string
+	}{
+		{
+			name:          "Unapproved surcharge type",
+			surchargeType: "parking",
+			status:        models.RideStatusOngoing,
+			expectedError: "unknown surcharge type",
+		},
+		{
+			name:          "Not the ride's driver",
+			surchargeType: "toll",
+			status:        models.RideStatusOngoing,
+			otherDriver:   true,
+			expectGetRide: true,
+			expectedError: "is not the driver of ride",
+		},
+		{
+			name:          "Ride not ongoing",
+			surchargeType: "toll",
+			status:        models.RideStatusDriverPickup,
+			expectGetRide: true,
+			expectedError: "cannot add surcharge",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			mockGW := mocks.NewMockRideGW(ctrl)
+			uc, err := NewRideUC(surchargeConfig(), mockRepo, mockGW, nil)
+			require.NoError(t, err)
+
+			ride := newOngoingRide()
+			ride.Status = tt.status
+			driverID := ride.DriverID.String()
+			if tt.otherDriver {
+				driverID = uuid.New().String()
+			}
+
+			// Nothing is written to the ledger
+			if tt.expectGetRide {
+				mockRepo.EXPECT().GetRide(gomock.Any(), ride.RideID.String()).Return(ride, nil)
+			}
+
+			entry, err := uc.AddSurcharge(context.Background(), models.SurchargeRequest{
+				RideID:   ride.RideID.String(),
+				DriverID: driverID,
+				Type:     tt.surchargeType,
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Nil(t, entry)
+		})
+	}
+}
+
+func TestRideArrived_IncludesSurcharges(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	mockGW := mocks.NewMockRideGW(ctrl)
+	uc, err := NewRideUC(surchargeConfig(), mockRepo, mockGW, nil)
+	require.NoError(t, err)
+
+	ride := newOngoingRide()
+	rideID := ride.RideID.String()
+
+	// 15000 of distance fare plus a 10000 toll
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().GetBillingLedgerSum(gomock.Any(), rideID).Return(25000, nil)
+	mockRepo.EXPECT().
+		ListBillingEntries(gomock.Any(), rideID, models.BillingCategorySurcharge).
+		Return([]*models.BillingLedger{
+			{RideID: ride.RideID, Category: models.BillingCategorySurcharge, Description: "toll", Cost: 10000},
+		}, nil)
+	expectNoEstimate(mockRepo, rideID)
+
+	// The driver's discount applies to the distance fare only: 15000 * 0.8 + 10000
+	mockRepo.EXPECT().
+		CreatePayment(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, payment *models.Payment, _ string) error {
+			assert.Equal(t, 22000, payment.AdjustedCost)
+			assert.Equal(t, 1100, payment.AdminFee)
+			assert.Equal(t, 20900, payment.DriverPayout)
+			return nil
+		})
+
+	paymentRequest, err := uc.RideArrived(context.Background(), models.RideArrivalReq{RideID: rideID, AdjustmentFactor: 0.8})
+	require.NoError(t, err)
+
+	assert.Equal(t, 22000, paymentRequest.TotalCost)
+	assert.Equal(t, models.FareBreakdown{
+		DistanceCost:  12000,
+		SurchargeCost: 10000,
+		Surcharges:    []models.SurchargeItem{{Type: "toll", Amount: 10000}},
+	}, paymentRequest.Breakdown)
+}
+
+func TestFareBreakdown(t *testing.T) {
+	surcharges := []*models.BillingLedger{
+		{Category: models.BillingCategorySurcharge, Description: "toll", Cost: 10000},
+		{Category: models.BillingCategorySurcharge, Description: "airport", Cost: 15000},
+	}
+
+	breakdown := fareBreakdown(45000, surcharges, 0.5)
+	assert.Equal(t, 10000, breakdown.DistanceCost)
+	assert.Equal(t, 25000, breakdown.SurchargeCost)
+	assert.Len(t, breakdown.Surcharges, 2)
+
+	// Without surcharges the whole ledger is distance fare
+	assert.Equal(t, models.FareBreakdown{DistanceCost: 9000}, fareBreakdown(10000, nil, 0.9))
+}
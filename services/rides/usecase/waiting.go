@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/rides"
+)
+
+// defaultWaitingGrace is used when no free waiting time is configured
+const defaultWaitingGrace = 3 * time.Minute
+
+// waitingGrace returns how long the driver waits at the pickup point before the waiting fee starts
+func (uc *rideUC) waitingGrace() time.Duration {
+	if secs := uc.config().Rides.WaitingGraceSecs; secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	return defaultWaitingGrace
+}
+
+// waitingFee returns the charge for waiting from arrivedAt until startedAt. Each started minute
+// past the grace time costs the configured per-minute fee.
+func (uc *rideUC) waitingFee(arrivedAt, startedAt time.Time) int {
+	feePerMinute := uc.config().Rides.WaitingFeePerMinute
+	if feePerMinute <= 0 {
+		return 0
+	}
+
+	billable := startedAt.Sub(arrivedAt) - uc.waitingGrace()
+	if billable <= 0 {
+		return 0
+	}
+	minutes := int((billable + time.Minute - 1) / time.Minute)
+	return minutes * feePerMinute
+}
+
+// DriverArrived records that the driver of a ride awaiting pickup has reached the pickup point
+// and is waiting for the passenger. The waiting fee is measured from this point.
+func (uc *rideUC) DriverArrived(ctx context.Context, req models.DriverArrivedRequest) (*models.Ride, error) {
+	ride, err := uc.ridesRepo.GetRide(ctx, req.RideID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ride: %w", err)
+	}
+
+	if ride.DriverID.String() != req.DriverID {
+		return nil, fmt.Errorf("%w: user %s, ride %s", rides.ErrNotRideDriver, req.DriverID, req.RideID)
+	}
+
+	if ride.Status != models.RideStatusDriverPickup {
+		return nil, fmt.Errorf("%w: cannot mark driver arrived for ride with status: %s", rides.ErrRideNotAwaitingPickup, ride.Status)
+	}
+
+	// Rides created before pickup points were recorded cannot be checked
+	if ride.PickupLatitude != 0 || ride.PickupLongitude != 0 {
+		distanceMeters := utils.CalculateDistance(
+			utils.GeoPoint{Latitude: req.DriverLocation.Latitude, Longitude: req.DriverLocation.Longitude},
+			utils.GeoPoint{Latitude: ride.PickupLatitude, Longitude: ride.PickupLongitude},
+		) * 1000
+		if distanceMeters > uc.maxPickupDistanceMeters() {
+			return nil, fmt.Errorf("%w (%.2f meters)", rides.ErrDriverTooFarFromPickup, distanceMeters)
+		}
+	}
+
+	now := time.Now()
+	if err := uc.ridesRepo.MarkDriverArrived(ctx, req.RideID, now); err != nil {
+		return nil, fmt.Errorf("failed to mark driver arrived: %w", err)
+	}
+	ride.Status = models.RideStatusDriverArrived
+	ride.DriverArrivedAt = &now
+
+	logger.Info("Driver arrived at pickup point",
+		logger.String("ride_id", req.RideID),
+		logger.String("driver_id", req.DriverID))
+	return ride, nil
+}
+
+// recordWaitingFee adds the fee for the time the driver waited at the pickup point to the ride's
+// ledger as a surcharge, so it is passed on in full like tolls. Nothing is recorded within the grace time.
+func (uc *rideUC) recordWaitingFee(ctx context.Context, ride *models.Ride, startedAt time.Time) error {
+	if ride.DriverArrivedAt == nil {
+		return nil
+	}
+
+	fee := uc.waitingFee(*ride.DriverArrivedAt, startedAt)
+	if fee == 0 {
+		return nil
+	}
+
+	entry := &models.BillingLedger{
+		EntryID:     uuid.New(),
+		RideID:      ride.RideID,
+		Category:    models.BillingCategorySurcharge,
+		Description: models.SurchargeTypeWaiting,
+		Cost:        fee,
+		CreatedAt:   startedAt,
+	}
+	if err := uc.ridesRepo.AddBillingEntry(ctx, entry); err != nil {
+		return fmt.Errorf("failed to add waiting fee: %w", err)
+	}
+	if _, err := uc.ridesRepo.UpdateTotalCost(ctx, ride.RideID.String(), fee); err != nil {
+		return fmt.Errorf("failed to update total cost: %w", err)
+	}
+
+	logger.Info("Charged waiting fee for ride",
+		logger.String("ride_id", ride.RideID.String()),
+		logger.Duration("waited", startedAt.Sub(*ride.DriverArrivedAt)),
+		logger.Int("fee", fee))
+	return nil
+}
@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/rides/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitingConfig charges 500 per started minute after three free minutes
+func waitingConfig() *models.Config {
+	return &models.Config{Rides: models.RidesConfig{
+		WaitingGraceSecs:    180,
+		WaitingFeePerMinute: 500,
+	}}
+}
+
+func TestDriverArrived_MarksRideWaiting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc, err := NewRideUC(waitingConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	ride := &models.Ride{
+		RideID:          uuid.New(),
+		DriverID:        uuid.New(),
+		Status:          models.RideStatusDriverPickup,
+		PickupLatitude:  -6.175392,
+		PickupLongitude: 106.827153,
+	}
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().MarkDriverArrived(gomock.Any(), rideID, gomock.Any()).Return(nil)
+
+	arrived, err := uc.DriverArrived(context.Background(), models.DriverArrivedRequest{
+		RideID:         rideID,
+		DriverID:       ride.DriverID.String(),
+		DriverLocation: &models.Location{Latitude: -6.175400, Longitude: 106.827160},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusDriverArrived, arrived.Status)
+	assert.NotNil(t, arrived.DriverArrivedAt)
+}
+
+func TestDriverArrived_Rejected(t *testing.T) {
+	driverID := uuid.New()
+	nearby := &models.Location{Latitude: -6.175400, Longitude: 106.827160}
+
+	tests := []struct {
+		name     string
+		status   models.RideStatus
+		driverID string
+		location *models.Location
+		wantErr  string
+	}{
+		{name: "not the driver", status: models.RideStatusDriverPickup, driverID: uuid.New().String(), location: nearby, wantErr: "is not the driver"},
+		{name: "already started", status: models.RideStatusOngoing, driverID: driverID.String(), location: nearby, wantErr: "cannot mark driver arrived"},
+		{name: "away from pickup", status: models.RideStatusDriverPickup, driverID: driverID.String(), location: &models.Location{Latitude: -6.200000, Longitude: 106.816666}, wantErr: "too far"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockRideRepo(ctrl)
+			uc, err := NewRideUC(waitingConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+			require.NoError(t, err)
+
+			ride := &models.Ride{
+				RideID:          uuid.New(),
+				DriverID:        driverID,
+				Status:          tt.status,
+				PickupLatitude:  -6.175392,
+				PickupLongitude: 106.827153,
+			}
+			mockRepo.EXPECT().GetRide(gomock.Any(), ride.RideID.String()).Return(ride, nil)
+
+			// MarkDriverArrived is not expected
+			_, err = uc.DriverArrived(context.Background(), models.DriverArrivedRequest{
+				RideID:         ride.RideID.String(),
+				DriverID:       tt.driverID,
+				DriverLocation: tt.location,
+			})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestWaitingFee(t *testing.T) {
+	arrivedAt := time.Now()
+
+	tests := []struct {
+		name   string
+		cfg    *models.Config
+		waited time.Duration
+		want   int
+	}{
+		{name: "within grace", cfg: waitingConfig(), waited: 3 * time.Minute, want: 0},
+		{name: "part of a minute past grace", cfg: waitingConfig(), waited: 3*time.Minute + time.Second, want: 500},
+		{name: "several minutes past grace", cfg: waitingConfig(), waited: 7*time.Minute + 30*time.Second, want: 2500},
+		{name: "fee disabled", cfg: &models.Config{Rides: models.RidesConfig{WaitingGraceSecs: 180}}, waited: time.Hour, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			uc := &rideUC{cfg: tt.cfg}
+			assert.Equal(t, tt.want, uc.waitingFee(arrivedAt, arrivedAt.Add(tt.waited)))
+		})
+	}
+}
+
+func TestStartRide_FromDriverArrivedChargesWaitingFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc, err := NewRideUC(waitingConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	// The passenger kept the driver waiting five and a half minutes, two and a half past the grace time
+	arrivedAt := time.Now().Add(-5*time.Minute - 30*time.Second)
+	ride := &models.Ride{
+		RideID:          uuid.New(),
+		Status:          models.RideStatusDriverArrived,
+		PickupLatitude:  -6.175392,
+		PickupLongitude: 106.827153,
+		DriverArrivedAt: &arrivedAt,
+	}
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().UpdateRideStatus(gomock.Any(), rideID, models.RideStatusDriverArrived, models.RideStatusOngoing).Return(nil)
+	mockRepo.EXPECT().
+		AddBillingEntry(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, entry *models.BillingLedger) error {
+			assert.Equal(t, models.BillingCategorySurcharge, entry.Category)
+			assert.Equal(t, models.SurchargeTypeWaiting, entry.Description)
+			assert.Equal(t, 1500, entry.Cost)
+			return nil
+		})
+	mockRepo.EXPECT().UpdateTotalCost(gomock.Any(), rideID, 1500).Return(1500, nil)
+
+	started, err := uc.StartRide(context.Background(), models.RideStartRequest{
+		RideID:         rideID,
+		DriverLocation: &models.Location{Latitude: -6.175400, Longitude: 106.827160},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusOngoing, started.Status)
+}
+
+func TestStartRide_FromDriverArrivedWithinGrace(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockRideRepo(ctrl)
+	uc, err := NewRideUC(waitingConfig(), mockRepo, mocks.NewMockRideGW(ctrl), nil)
+	require.NoError(t, err)
+
+	arrivedAt := time.Now().Add(-time.Minute)
+	ride := &models.Ride{
+		RideID:          uuid.New(),
+		Status:          models.RideStatusDriverArrived,
+		PickupLatitude:  -6.175392,
+		PickupLongitude: 106.827153,
+		DriverArrivedAt: &arrivedAt,
+	}
+	rideID := ride.RideID.String()
+
+	mockRepo.EXPECT().GetRide(gomock.Any(), rideID).Return(ride, nil)
+	mockRepo.EXPECT().UpdateRideStatus(gomock.Any(), rideID, models.RideStatusDriverArrived, models.RideStatusOngoing).Return(nil)
+
+	// No waiting fee is recorded
+	started, err := uc.StartRide(context.Background(), models.RideStartRequest{
+		RideID:         rideID,
+		DriverLocation: &models.Location{Latitude: -6.175400, Longitude: 106.827160},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, models.RideStatusOngoing, started.Status)
+}
@@ -11,11 +11,31 @@ func (g *UserGW) MatchConfirm(ctx context.Context, req *models.MatchConfirmReque
 	return g.httpGateway.MatchConfirm(ctx, req)
 }
 
+// GetDriverMatches implements the UserGW interface method for driver match history
+func (g *UserGW) GetDriverMatches(ctx context.Context, driverID string, limit, offset int) (*models.MatchHistory, error) {
+	return g.httpGateway.GetDriverMatches(ctx, driverID, limit, offset)
+}
+
+// GetDriverCancellationStats implements the UserGW interface method for a driver's cancellation rate
+func (g *UserGW) GetDriverCancellationStats(ctx context.Context, driverID string) (*models.DriverCancellationStats, error) {
+	return g.httpGateway.GetDriverCancellationStats(ctx, driverID)
+}
+
+// GetActiveRideID implements the UserGW interface method for a user's current ride
+func (g *UserGW) GetActiveRideID(ctx context.Context, userID, role string) (string, error) {
+	return g.httpGateway.GetActiveRideID(ctx, userID, role)
+}
+
 // StartRide implements the UserGW interface method for starting a trip
 func (g *UserGW) StartRide(ctx context.Context, req *models.RideStartRequest) (*models.Ride, error) {
 	return g.httpGateway.StartRide(ctx, req)
 }
 
+// GetPickupCode implements the UserGW interface method for fetching a ride's pickup code
+func (g *UserGW) GetPickupCode(ctx context.Context, req *models.PickupCodeRequest) (*models.PickupCodeResponse, error) {
+	return g.httpGateway.GetPickupCode(ctx, req)
+}
+
 // ProcessPayment implements the UserGW interface method for processing payment
 func (g *UserGW) ProcessPayment(ctx context.Context, req *models.PaymentProccessRequest) (*models.Payment, error) {
 	return g.httpGateway.ProcessPayment(ctx, req)
@@ -45,3 +65,13 @@ func (g *UserGW) PublishLocationUpdate(ctx context.Context, locationEvent *model
 func (g *UserGW) PublishRideStart(ctx context.Context, event *models.RideStartTripEvent) error {
 	return g.natsGateway.PublishRideStart(ctx, event)
 }
+
+// PublishAnnouncement forwards to the NATS gateway implementation
+func (g *UserGW) PublishAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	return g.natsGateway.PublishAnnouncement(ctx, announcement)
+}
+
+// SendOTP forwards to the configured OTP sender
+func (g *UserGW) SendOTP(ctx context.Context, msisdn, code string) error {
+	return g.otpSender.SendOTP(ctx, msisdn, code)
+}
@@ -3,6 +3,7 @@ package gateaway_http
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"time"
 
 	httpclient "github.com/piresc/nebengjek/internal/pkg/http"
@@ -60,3 +61,61 @@ func (g *HTTPGateway) MatchConfirm(ctx context.Context, req *models.MatchConfirm
 	}
 	return &matchProposal, nil
 }
+
+// GetDriverMatches retrieves a page of a driver's match history from the match service
+func (g *HTTPGateway) GetDriverMatches(ctx context.Context, driverID string, limit, offset int) (*models.MatchHistory, error) {
+	endpoint := fmt.Sprintf("/internal/drivers/%s/matches?limit=%d&offset=%d", driverID, limit, offset)
+
+	// Start APM segment if tracer is available
+	var endSegment func()
+	if g.matchClient.tracer != nil {
+		ctx, endSegment = g.matchClient.tracer.StartSegment(ctx, "External/match-service/driver-matches")
+		defer endSegment()
+	}
+
+	var history models.MatchHistory
+	err := g.matchClient.client.GetJSON(ctx, endpoint, &history)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver matches: %w", err)
+	}
+	return &history, nil
+}
+
+// GetActiveRideID asks the match service which ride a user is currently on in the given role,
+// returning an empty string when there is none
+func (g *HTTPGateway) GetActiveRideID(ctx context.Context, userID, role string) (string, error) {
+	endpoint := fmt.Sprintf("/internal/users/%s/active-ride?role=%s", userID, url.QueryEscape(role))
+
+	// Start APM segment if tracer is available
+	var endSegment func()
+	if g.matchClient.tracer != nil {
+		ctx, endSegment = g.matchClient.tracer.StartSegment(ctx, "External/match-service/active-ride")
+		defer endSegment()
+	}
+
+	var activeRide models.ActiveRide
+	err := g.matchClient.client.GetJSON(ctx, endpoint, &activeRide)
+	if err != nil {
+		return "", fmt.Errorf("failed to get active ride: %w", err)
+	}
+	return activeRide.RideID, nil
+}
+
+// GetDriverCancellationStats retrieves a driver's cancellation rate and any suspension from the match service
+func (g *HTTPGateway) GetDriverCancellationStats(ctx context.Context, driverID string) (*models.DriverCancellationStats, error) {
+	endpoint := fmt.Sprintf("/internal/drivers/%s/cancellation-stats", driverID)
+
+	// Start APM segment if tracer is available
+	var endSegment func()
+	if g.matchClient.tracer != nil {
+		ctx, endSegment = g.matchClient.tracer.StartSegment(ctx, "External/match-service/driver-cancellation-stats")
+		defer endSegment()
+	}
+
+	var stats models.DriverCancellationStats
+	err := g.matchClient.client.GetJSON(ctx, endpoint, &stats)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get driver cancellation stats: %w", err)
+	}
+	return &stats, nil
+}
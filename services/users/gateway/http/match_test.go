@@ -260,3 +260,23 @@ func TestNewHTTPGateway(t *testing.T) {
 	assert.NotNil(t, gateway.matchClient)
 	assert.NotNil(t, gateway.rideClient)
 }
+
+func TestHTTPGateway_GetActiveRideID(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodGet, r.Method)
+		assert.Equal(t, "/internal/users/user-1/active-ride", r.URL.Path)
+		assert.Equal(t, "passenger", r.URL.Query().Get("role"))
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    models.ActiveRide{UserID: "user-1", Role: "passenger", RideID: "ride-1"},
+		})
+	}))
+	defer server.Close()
+
+	gateway := NewHTTPGateway(server.URL, "", &models.APIKeyConfig{MatchService: "test-api-key"}, nil)
+	rideID, err := gateway.GetActiveRideID(context.Background(), "user-1", "passenger")
+
+	require.NoError(t, err)
+	assert.Equal(t, "ride-1", rideID)
+}
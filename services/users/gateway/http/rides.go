@@ -47,6 +47,25 @@ func (g *HTTPGateway) StartRide(ctx context.Context, req *models.RideStartReques
 	return &ride, nil
 }
 
+// GetPickupCode asks the ride service for a ride's pickup code on behalf of its passenger
+func (g *HTTPGateway) GetPickupCode(ctx context.Context, req *models.PickupCodeRequest) (*models.PickupCodeResponse, error) {
+	endpoint := fmt.Sprintf("/internal/rides/%s/pickup-code", req.RideID)
+
+	// Start APM segment if tracer is available
+	var endSegment func()
+	if g.rideClient.tracer != nil {
+		ctx, endSegment = g.rideClient.tracer.StartSegment(ctx, "External/rides-service/pickup-code")
+		defer endSegment()
+	}
+
+	var resp models.PickupCodeResponse
+	err := g.rideClient.client.PostJSON(ctx, endpoint, req, &resp)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pickup code: %w", err)
+	}
+	return &resp, nil
+}
+
 // RideArrived sends a ride arrival notification to the ride service with simplified retry
 func (g *HTTPGateway) RideArrived(ctx context.Context, req *models.RideArrivalReq) (*models.PaymentRequest, error) {
 	endpoint := fmt.Sprintf("/internal/rides/%s/arrive", req.RideID)
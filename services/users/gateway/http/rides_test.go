@@ -663,3 +663,29 @@ func TestHTTPGateway_RequestBodyValidation(t *testing.T) {
 		assert.Equal(t, models.PaymentStatusProcessed, result.Status)
 	})
 }
+
+func TestHTTPGateway_GetPickupCode(t *testing.T) {
+	rideID := uuid.New().String()
+	passengerID := uuid.New().String()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/internal/rides/"+rideID+"/pickup-code", r.URL.Path)
+
+		var req models.PickupCodeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, passengerID, req.PassengerID)
+
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success": true,
+			"data":    models.PickupCodeResponse{RideID: rideID, PickupCode: "0427"},
+		})
+	}))
+	defer server.Close()
+
+	gateway := NewHTTPGateway("", server.URL, &models.APIKeyConfig{RidesService: "test-api-key"}, nil)
+	resp, err := gateway.GetPickupCode(context.Background(), &models.PickupCodeRequest{RideID: rideID, PassengerID: passengerID})
+
+	require.NoError(t, err)
+	assert.Equal(t, "0427", resp.PickupCode)
+}
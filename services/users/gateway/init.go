@@ -13,12 +13,15 @@ import (
 type UserGW struct {
 	natsGateway *gateway_nats.NATSGateway
 	httpGateway *gateaway_http.HTTPGateway
+	otpSender   users.OTPSender
 }
 
-// NewUserGW creates a new gateway instance with NATS and HTTP clients with API key authentication
-func NewUserGW(natsClient *natspkg.Client, matchServiceURL string, rideServiceURL string, config *models.APIKeyConfig, tracer observability.Tracer) users.UserGW {
+// NewUserGW creates a new gateway instance with NATS and HTTP clients with API key authentication.
+// OTPs are delivered through otpSender.
+func NewUserGW(natsClient *natspkg.Client, matchServiceURL string, rideServiceURL string, config *models.APIKeyConfig, tracer observability.Tracer, otpSender users.OTPSender) users.UserGW {
 	return &UserGW{
 		natsGateway: gateway_nats.NewNATSGateway(natsClient),
 		httpGateway: gateaway_http.NewHTTPGateway(matchServiceURL, rideServiceURL, config, tracer),
+		otpSender:   otpSender,
 	}
 }
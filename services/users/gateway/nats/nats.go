@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/piresc/nebengjek/internal/pkg/constants"
+	pkgcontext "github.com/piresc/nebengjek/internal/pkg/context"
 	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
@@ -36,6 +38,7 @@ func (g *NATSGateway) PublishBeaconEvent(ctx context.Context, event *models.Beac
 		Subject: constants.SubjectUserBeacon,
 		Data:    data,
 		MsgID:   fmt.Sprintf("beacon-%s-%d", event.UserID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 10 * time.Second,
 	}
 
@@ -55,6 +58,14 @@ func (g *NATSGateway) PublishBeaconEvent(ctx context.Context, event *models.Beac
 
 // PublishFinderEvent publishes a finder event to JetStream with delivery guarantees
 func (g *NATSGateway) PublishFinderEvent(ctx context.Context, event *models.FinderEvent) error {
+	// A passenger's search starts the matching flow, so it gets a correlation ID even outside an HTTP request
+	if event.CorrelationID == "" {
+		event.CorrelationID = pkgcontext.GetCorrelationID(ctx)
+	}
+	if event.CorrelationID == "" {
+		event.CorrelationID = uuid.New().String()
+	}
+
 	data, err := json.Marshal(event)
 	if err != nil {
 		return fmt.Errorf("failed to marshal finder event: %w", err)
@@ -65,6 +76,7 @@ func (g *NATSGateway) PublishFinderEvent(ctx context.Context, event *models.Find
 		Subject: constants.SubjectUserFinder,
 		Data:    data,
 		MsgID:   fmt.Sprintf("finder-%s-%d", event.UserID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 10 * time.Second,
 	}
 
@@ -77,7 +89,8 @@ func (g *NATSGateway) PublishFinderEvent(ctx context.Context, event *models.Find
 
 	logger.InfoCtx(ctx, "Successfully published finder event to JetStream",
 		logger.String("user_id", event.UserID),
-		logger.Bool("is_active", event.IsActive))
+		logger.Bool("is_active", event.IsActive),
+		logger.String("correlation_id", event.CorrelationID))
 
 	return nil
 }
@@ -94,6 +107,7 @@ func (g *NATSGateway) PublishRideStart(ctx context.Context, event *models.RideSt
 		Subject: constants.SubjectRideStarted,
 		Data:    data,
 		MsgID:   fmt.Sprintf("ride-start-%s-%d", event.RideID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 10 * time.Second,
 	}
 
@@ -122,6 +136,7 @@ func (g *NATSGateway) PublishLocationUpdate(ctx context.Context, locationEvent *
 		Subject: constants.SubjectLocationUpdate,
 		Data:    data,
 		MsgID:   fmt.Sprintf("location-%s-%d", locationEvent.RideID, time.Now().UnixNano()),
+		Headers: natspkg.TraceHeaders(ctx),
 		Timeout: 5 * time.Second, // Shorter timeout for location updates
 	}
 
@@ -139,3 +154,26 @@ func (g *NATSGateway) PublishLocationUpdate(ctx context.Context, locationEvent *
 
 	return nil
 }
+
+// PublishAnnouncement publishes a system announcement on core NATS. Announcements only reach users
+// who are connected, so there is nothing to persist, and every users service instance subscribes
+// to relay them to its own connections.
+func (g *NATSGateway) PublishAnnouncement(ctx context.Context, announcement *models.Announcement) error {
+	data, err := json.Marshal(announcement)
+	if err != nil {
+		return fmt.Errorf("failed to marshal announcement: %w", err)
+	}
+
+	if err := g.client.GetConn().Publish(constants.SubjectSystemAnnouncement, data); err != nil {
+		logger.ErrorCtx(ctx, "Failed to publish announcement",
+			logger.String("announcement_id", announcement.ID),
+			logger.Err(err))
+		return fmt.Errorf("failed to publish announcement: %w", err)
+	}
+
+	logger.InfoCtx(ctx, "Published announcement",
+		logger.String("announcement_id", announcement.ID),
+		logger.Strings("roles", announcement.Roles))
+
+	return nil
+}
@@ -0,0 +1,23 @@
+package gateway
+
+import (
+	"context"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+)
+
+// LogOTPSender skips delivery and only logs that an OTP was issued, for local development without an SMS provider.
+// The code itself is never logged; developers read it from Redis.
+type LogOTPSender struct{}
+
+// NewLogOTPSender creates an OTP sender that only logs that a code was issued
+func NewLogOTPSender() *LogOTPSender {
+	return &LogOTPSender{}
+}
+
+// SendOTP logs that a code was issued for the given phone number
+func (s *LogOTPSender) SendOTP(ctx context.Context, msisdn, code string) error {
+	logger.Info("Generated OTP without delivering it",
+		logger.MSISDN(msisdn))
+	return nil
+}
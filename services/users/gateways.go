@@ -6,6 +6,11 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
+// OTPSender delivers a login code to a phone number, e.g. over SMS
+type OTPSender interface {
+	SendOTP(ctx context.Context, msisdn, code string) error
+}
+
 //go:generate mockgen -destination=mocks/mock_gateway.go -package=mocks github.com/piresc/nebengjek/services/users UserGW
 
 // UserGW defines the user gateaways interface
@@ -15,10 +20,18 @@ type UserGW interface {
 	PublishFinderEvent(ctx context.Context, finderevent *models.FinderEvent) error
 	PublishLocationUpdate(ctx context.Context, locationEvent *models.LocationUpdate) error
 	PublishRideStart(ctx context.Context, startTripEvent *models.RideStartTripEvent) error
+	PublishAnnouncement(ctx context.Context, announcement *models.Announcement) error
 
 	// HTTP Gateway
 	MatchConfirm(ctx context.Context, req *models.MatchConfirmRequest) (*models.MatchProposal, error)
+	GetDriverMatches(ctx context.Context, driverID string, limit, offset int) (*models.MatchHistory, error)
+	GetDriverCancellationStats(ctx context.Context, driverID string) (*models.DriverCancellationStats, error)
+	GetActiveRideID(ctx context.Context, userID, role string) (string, error)
 	StartRide(ctx context.Context, req *models.RideStartRequest) (*models.Ride, error)
+	GetPickupCode(ctx context.Context, req *models.PickupCodeRequest) (*models.PickupCodeResponse, error)
 	RideArrived(ctx context.Context, event *models.RideArrivalReq) (*models.PaymentRequest, error)
 	ProcessPayment(ctx context.Context, paymentReq *models.PaymentProccessRequest) (*models.Payment, error)
+
+	// OTP delivery
+	OTPSender
 }
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/labstack/echo/v4"
@@ -21,7 +22,7 @@ func NewAuthHandler(userUC users.UserUC) *AuthHandler {
 	}
 }
 
-// GenerateOTP handles OTP generation requests via SMS
+// GenerateOTP handles OTP requests, sending a login code to the phone number
 func (h *AuthHandler) GenerateOTP(c echo.Context) error {
 	var request models.LoginRequest
 	if err := c.Bind(&request); err != nil {
@@ -35,6 +36,9 @@ func (h *AuthHandler) GenerateOTP(c echo.Context) error {
 
 	// Generate and send OTP via SMS
 	if err := h.userUC.GenerateOTP(c.Request().Context(), request.MSISDN); err != nil {
+		if errors.Is(err, users.ErrOTPRateLimited) {
+			return utils.ErrorResponseHandler(c, http.StatusTooManyRequests, "Too many OTP requests, please try again later")
+		}
 		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, err.Error())
 	}
 
@@ -56,6 +60,9 @@ func (h *AuthHandler) VerifyOTP(c echo.Context) error {
 	// Verify OTP and generate JWT token
 	response, err := h.userUC.VerifyOTP(c.Request().Context(), request.MSISDN, request.OTP)
 	if err != nil {
+		if errors.Is(err, users.ErrOTPAttemptsExceeded) {
+			return utils.ErrorResponseHandler(c, http.StatusTooManyRequests, "Too many failed attempts, please request a new OTP")
+		}
 		return utils.UnauthorizedResponse(c, "Invalid OTP")
 	}
 
@@ -12,6 +12,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 	"github.com/piresc/nebengjek/services/users/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -153,6 +154,39 @@ func TestGenerateOTP_UseCaseError(t *testing.T) {
 	assert.Equal(t, float64(http.StatusInternalServerError), response["code"])
 }
 
+func TestGenerateOTP_RateLimited(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	authHandler := NewAuthHandler(mockUserUC)
+
+	e := echo.New()
+	requestBody := `{"msisdn": "+6281234567890"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/otp/request", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	mockUserUC.EXPECT().
+		GenerateOTP(gomock.Any(), "+6281234567890").
+		Return(users.ErrOTPRateLimited)
+
+	// Act
+	err := authHandler.GenerateOTP(c)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, false, response["success"])
+	assert.Equal(t, float64(http.StatusTooManyRequests), response["code"])
+}
+
 func TestVerifyOTP_Success(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -327,3 +361,27 @@ func TestVerifyOTP_UseCaseError(t *testing.T) {
 	assert.Equal(t, "Invalid OTP", response["error"])
 	assert.Equal(t, float64(http.StatusUnauthorized), response["code"])
 }
+
+func TestVerifyOTP_AttemptsExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	authHandler := NewAuthHandler(mockUserUC)
+
+	e := echo.New()
+	requestBody := `{"msisdn": "+6281234567890", "otp": "1234"}`
+	req := httptest.NewRequest(http.MethodPost, "/auth/otp/verify", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	mockUserUC.EXPECT().
+		VerifyOTP(gomock.Any(), "+6281234567890", "1234").
+		Return(nil, users.ErrOTPAttemptsExceeded)
+
+	err := authHandler.VerifyOTP(c)
+
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+}
@@ -0,0 +1,124 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/converter"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
+	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/users"
+)
+
+// favoriteError maps a favorite location failure to its response
+func favoriteError(c echo.Context, err error, fallback string) error {
+	var fieldErr *models.FieldError
+	switch {
+	case errors.As(err, &fieldErr):
+		return utils.ValidationErrorResponse(c, map[string]string{fieldErr.Field: fieldErr.Message})
+	case errors.Is(err, users.ErrFavoriteNotFound):
+		return utils.NotFoundResponse(c, "Favorite location not found")
+	case errors.Is(err, users.ErrFavoriteLimitReached):
+		return utils.ErrorResponseHandler(c, http.StatusConflict, "Favorite location limit reached")
+	default:
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, fallback)
+	}
+}
+
+// CreateFavoriteLocation saves a favorite location for the authenticated user
+func (h *UserHandler) CreateFavoriteLocation(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "CreateFavoriteLocation")
+
+	var req models.FavoriteLocationRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request payload")
+	}
+
+	userID := fmt.Sprintf("%v", c.Get("user_id"))
+	nrpkg.AddTransactionAttribute(txn, "user.id", userID)
+
+	favorite, err := h.userUC.CreateFavoriteLocation(c.Request().Context(), userID, &req)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return favoriteError(c, err, "Failed to create favorite location")
+	}
+
+	return utils.SuccessResponse(c, http.StatusCreated, "Favorite location created successfully", favorite)
+}
+
+// ListFavoriteLocations returns the authenticated user's favorite locations
+func (h *UserHandler) ListFavoriteLocations(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "ListFavoriteLocations")
+
+	userID := fmt.Sprintf("%v", c.Get("user_id"))
+	nrpkg.AddTransactionAttribute(txn, "user.id", userID)
+
+	favorites, err := h.userUC.ListFavoriteLocations(c.Request().Context(), userID)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return favoriteError(c, err, "Failed to list favorite locations")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Favorite locations retrieved successfully", favorites)
+}
+
+// UpdateFavoriteLocation replaces one of the authenticated user's favorite locations
+func (h *UserHandler) UpdateFavoriteLocation(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "UpdateFavoriteLocation")
+
+	favoriteID := c.Param("id")
+	if _, err := converter.ParseUUID(favoriteID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid favorite location ID")
+	}
+
+	var req models.FavoriteLocationRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request payload")
+	}
+
+	userID := fmt.Sprintf("%v", c.Get("user_id"))
+	nrpkg.AddTransactionAttribute(txn, "user.id", userID)
+	nrpkg.AddTransactionAttribute(txn, "favorite.id", favoriteID)
+
+	favorite, err := h.userUC.UpdateFavoriteLocation(c.Request().Context(), userID, favoriteID, &req)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return favoriteError(c, err, "Failed to update favorite location")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Favorite location updated successfully", favorite)
+}
+
+// DeleteFavoriteLocation removes one of the authenticated user's favorite locations
+func (h *UserHandler) DeleteFavoriteLocation(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "DeleteFavoriteLocation")
+
+	favoriteID := c.Param("id")
+	if _, err := converter.ParseUUID(favoriteID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid favorite location ID")
+	}
+
+	userID := fmt.Sprintf("%v", c.Get("user_id"))
+	nrpkg.AddTransactionAttribute(txn, "user.id", userID)
+	nrpkg.AddTransactionAttribute(txn, "favorite.id", favoriteID)
+
+	if err := h.userUC.DeleteFavoriteLocation(c.Request().Context(), userID, favoriteID); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return favoriteError(c, err, "Failed to delete favorite location")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Favorite location deleted successfully", nil)
+}
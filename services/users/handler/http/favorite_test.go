@@ -0,0 +1,172 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newFavoriteContext builds a request context authenticated as userID
+func newFavoriteContext(method, target, body, userID string) (echo.Context, *httptest.ResponseRecorder) {
+	e := echo.New()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.Set("user_id", userID)
+	return c, rec
+}
+
+func TestCreateFavoriteLocation(t *testing.T) {
+	tests := []struct {
+		name           string
+		ucErr          error
+		expectedStatus int
+	}{
+		{name: "Created", expectedStatus: http.StatusCreated},
+		{name: "Cap reached", ucErr: users.ErrFavoriteLimitReached, expectedStatus: http.StatusConflict},
+		{name: "Invalid coordinates", ucErr: &models.FieldError{Field: "location", Message: "latitude must be between -90 and 90"}, expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			userID := uuid.New().String()
+			mockUserUC.EXPECT().
+				CreateFavoriteLocation(gomock.Any(), userID, gomock.Any()).
+				DoAndReturn(func(_ interface{}, _ string, req *models.FavoriteLocationRequest) (*models.FavoriteLocation, error) {
+					assert.Equal(t, "Home", req.Label)
+					assert.Equal(t, -6.2088, req.Latitude)
+					if tt.ucErr != nil {
+						return nil, tt.ucErr
+					}
+					return &models.FavoriteLocation{ID: uuid.New(), Label: req.Label, Latitude: req.Latitude, Longitude: req.Longitude}, nil
+				})
+
+			c, rec := newFavoriteContext(http.MethodPost, "/favorites",
+				`{"label":"Home","latitude":-6.2088,"longitude":106.8456}`, userID)
+
+			err := userHandler.CreateFavoriteLocation(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestListFavoriteLocations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	userHandler := NewUserHandler(mockUserUC)
+
+	userID := uuid.New().String()
+	mockUserUC.EXPECT().
+		ListFavoriteLocations(gomock.Any(), userID).
+		Return([]*models.FavoriteLocation{{Label: "Home"}, {Label: "Work"}}, nil)
+
+	c, rec := newFavoriteContext(http.MethodGet, "/favorites", "", userID)
+
+	err := userHandler.ListFavoriteLocations(c)
+
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	data, ok := response["data"].([]interface{})
+	require.True(t, ok)
+	assert.Len(t, data, 2)
+}
+
+func TestUpdateFavoriteLocation(t *testing.T) {
+	tests := []struct {
+		name           string
+		favoriteID     string
+		expectCall     bool
+		ucErr          error
+		expectedStatus int
+	}{
+		{name: "Updated", favoriteID: uuid.New().String(), expectCall: true, expectedStatus: http.StatusOK},
+		{name: "Not found", favoriteID: uuid.New().String(), expectCall: true, ucErr: users.ErrFavoriteNotFound, expectedStatus: http.StatusNotFound},
+		{name: "Invalid ID", favoriteID: "home", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			userID := uuid.New().String()
+			if tt.expectCall {
+				mockUserUC.EXPECT().
+					UpdateFavoriteLocation(gomock.Any(), userID, tt.favoriteID, gomock.Any()).
+					Return(&models.FavoriteLocation{Label: "Office"}, tt.ucErr)
+			}
+
+			c, rec := newFavoriteContext(http.MethodPut, "/favorites/"+tt.favoriteID,
+				`{"label":"Office","latitude":-6.2,"longitude":106.8}`, userID)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.favoriteID)
+
+			err := userHandler.UpdateFavoriteLocation(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestDeleteFavoriteLocation(t *testing.T) {
+	tests := []struct {
+		name           string
+		ucErr          error
+		expectedStatus int
+	}{
+		{name: "Deleted", expectedStatus: http.StatusOK},
+		{name: "Another user's favorite", ucErr: users.ErrFavoriteNotFound, expectedStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			userID := uuid.New().String()
+			favoriteID := uuid.New().String()
+			mockUserUC.EXPECT().DeleteFavoriteLocation(gomock.Any(), userID, favoriteID).Return(tt.ucErr)
+
+			c, rec := newFavoriteContext(http.MethodDelete, "/favorites/"+favoriteID, "", userID)
+			c.SetParamNames("id")
+			c.SetParamValues(favoriteID)
+
+			err := userHandler.DeleteFavoriteLocation(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
@@ -1,9 +1,15 @@
 package http
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/converter"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
 	"github.com/piresc/nebengjek/internal/utils"
@@ -42,6 +48,13 @@ func (h *UserHandler) CreateUser(c echo.Context) error {
 	err := h.userUC.RegisterUser(c.Request().Context(), &user)
 	if err != nil {
 		nrpkg.NoticeTransactionError(txn, err)
+		var fieldErr *models.FieldError
+		if errors.As(err, &fieldErr) {
+			return utils.ValidationErrorResponse(c, map[string]string{fieldErr.Field: fieldErr.Message})
+		}
+		if errors.Is(err, users.ErrAlreadyExists) {
+			return utils.ErrorResponseHandler(c, http.StatusConflict, "Phone number already registered")
+		}
 		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to create user")
 	}
 
@@ -55,7 +68,7 @@ func (h *UserHandler) GetUser(c echo.Context) error {
 	nrpkg.SetTransactionName(txn, "GetUser")
 
 	userID := c.Param("id")
-	if userID == "" {
+	if _, err := converter.ParseUUID(userID); err != nil {
 		return utils.BadRequestResponse(c, "Invalid user ID")
 	}
 
@@ -88,8 +101,231 @@ func (h *UserHandler) RegisterDriver(c echo.Context) error {
 	err := h.userUC.RegisterDriver(c.Request().Context(), &user)
 	if err != nil {
 		nrpkg.NoticeTransactionError(txn, err)
+		var fieldErr *models.FieldError
+		if errors.As(err, &fieldErr) {
+			return utils.ValidationErrorResponse(c, map[string]string{fieldErr.Field: fieldErr.Message})
+		}
 		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to register driver")
 	}
 
 	return utils.SuccessResponse(c, http.StatusCreated, "Driver registered successfully", user)
 }
+
+// GetDriverMatches handles retrieval of the authenticated driver's match history
+func (h *UserHandler) GetDriverMatches(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "GetDriverMatches")
+
+	driverID := c.Param("id")
+	if _, err := converter.ParseUUID(driverID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid driver ID")
+	}
+
+	// Drivers may only view their own match history
+	if fmt.Sprintf("%v", c.Get("user_id")) != driverID {
+		return utils.ForbiddenResponse(c, "Cannot view another driver's matches")
+	}
+
+	limit, offset, err := utils.ParsePagination(c)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "driver.id", driverID)
+
+	history, err := h.userUC.GetDriverMatches(c.Request().Context(), driverID, limit, offset)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to retrieve driver matches")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver matches retrieved successfully", history)
+}
+
+// ListUsers returns a filtered, paginated list of users for admins
+func (h *UserHandler) ListUsers(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "ListUsers")
+
+	limit, offset, err := utils.ParsePagination(c)
+	if err != nil {
+		return utils.BadRequestResponse(c, err.Error())
+	}
+
+	filter := models.UserFilter{
+		Role:   c.QueryParam("role"),
+		Search: c.QueryParam("search"),
+		Limit:  limit,
+		Offset: offset,
+	}
+
+	if activeStr := c.QueryParam("is_active"); activeStr != "" {
+		active, err := strconv.ParseBool(activeStr)
+		if err != nil {
+			return utils.BadRequestResponse(c, "invalid is_active")
+		}
+		filter.IsActive = &active
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "filter.role", filter.Role)
+
+	list, err := h.userUC.ListUsers(c.Request().Context(), filter)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Failed to list users: "+err.Error())
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Users retrieved successfully", list)
+}
+
+// GetDriverDocuments returns a driver's profile and submitted documents for admin review
+func (h *UserHandler) GetDriverDocuments(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "GetDriverDocuments")
+
+	driverID := c.Param("id")
+	if _, err := converter.ParseUUID(driverID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid driver ID")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "driver.id", driverID)
+
+	user, err := h.userUC.GetUserByID(c.Request().Context(), driverID)
+	if err != nil {
+		if errors.Is(err, users.ErrUserNotFound) {
+			return utils.NotFoundResponse(c, "Driver not found")
+		}
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to retrieve driver")
+	}
+
+	if user.Role != "driver" || user.DriverInfo == nil {
+		return utils.NotFoundResponse(c, "Driver not found")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver retrieved successfully", user)
+}
+
+// VerifyDriver handles the admin decision after reviewing a driver's documents
+func (h *UserHandler) VerifyDriver(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "VerifyDriver")
+
+	driverID := c.Param("id")
+	if _, err := converter.ParseUUID(driverID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid driver ID")
+	}
+
+	var req models.DriverVerificationRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request payload")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "driver.id", driverID)
+	nrpkg.AddTransactionAttribute(txn, "driver.verified", req.Verified)
+
+	user, err := h.userUC.VerifyDriver(c.Request().Context(), driverID, req.Verified)
+	if err != nil {
+		switch {
+		case errors.Is(err, users.ErrUserNotFound), errors.Is(err, users.ErrDriverNotFound):
+			return utils.NotFoundResponse(c, "Driver not found")
+		case errors.Is(err, users.ErrDriverDocumentsMissing):
+			return utils.BadRequestResponse(c, "Driver has not submitted any documents")
+		default:
+			nrpkg.NoticeTransactionError(txn, err)
+			return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to verify driver")
+		}
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver verification updated successfully", user)
+}
+
+// GetDriverOnlineTime reports how long a driver was online on a day, defaulting to today.
+// Days run midnight to midnight in the server's time zone.
+func (h *UserHandler) GetDriverOnlineTime(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "GetDriverOnlineTime")
+
+	driverID := c.Param("id")
+	if _, err := converter.ParseUUID(driverID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid driver ID")
+	}
+
+	day := time.Now()
+	if raw := c.QueryParam("date"); raw != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", raw, time.Local)
+		if err != nil {
+			return utils.BadRequestResponse(c, "Invalid date, expected YYYY-MM-DD")
+		}
+		day = parsed
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "driver.id", driverID)
+
+	summary, err := h.userUC.GetDriverOnlineTime(c.Request().Context(), driverID, day)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to retrieve driver online time")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Driver online time retrieved successfully", summary)
+}
+
+// Announce broadcasts an announcement to connected users, optionally limited to some roles.
+// Delivery happens in the background, so the response only confirms it was published.
+func (h *UserHandler) Announce(c echo.Context) error {
+	// Get transaction from Echo context using centralized package
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "Announce")
+
+	var req models.AnnouncementRequest
+	if err := c.Bind(&req); err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.BadRequestResponse(c, "Invalid request payload")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "announcement.roles", strings.Join(req.Roles, ","))
+
+	announcement, err := h.userUC.Announce(c.Request().Context(), &req)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		var fieldErr *models.FieldError
+		if errors.As(err, &fieldErr) {
+			return utils.ValidationErrorResponse(c, map[string]string{fieldErr.Field: fieldErr.Message})
+		}
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to publish announcement")
+	}
+
+	return utils.SuccessResponse(c, http.StatusAccepted, "Announcement published successfully", announcement)
+}
+
+// GetPickupCode returns the pickup code of the authenticated passenger's active ride, reissuing it
+// when it expired or was invalidated. Passengers who missed the ride_pickup event use this.
+func (h *UserHandler) GetPickupCode(c echo.Context) error {
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "GetPickupCode")
+
+	rideID := c.Param("id")
+	if _, err := converter.ParseUUID(rideID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid ride ID")
+	}
+	userID := fmt.Sprintf("%v", c.Get("user_id"))
+	role := fmt.Sprintf("%v", c.Get("role"))
+
+	resp, err := h.userUC.GetPickupCode(c.Request().Context(), userID, role, rideID)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		if errors.Is(err, users.ErrNotRideParticipant) {
+			return utils.ForbiddenResponse(c, "Only the passenger of this active ride can get its pickup code")
+		}
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to get pickup code")
+	}
+
+	return utils.SuccessResponse(c, http.StatusOK, "Pickup code retrieved successfully", resp)
+}
@@ -3,15 +3,18 @@ package http
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 	"github.com/piresc/nebengjek/services/users/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -147,6 +150,84 @@ func TestCreateUser_UseCaseError(t *testing.T) {
 	assert.Equal(t, float64(http.StatusInternalServerError), response["code"])
 }
 
+func TestCreateUser_AlreadyExists(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	userHandler := NewUserHandler(mockUserUC)
+
+	// Setup Echo context
+	e := echo.New()
+	requestBody := `{
+		"fullname": "John Doe",
+		"msisdn": "+6281234567890",
+		"role": "passenger"
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Mock usecase to report the phone number is taken
+	mockUserUC.EXPECT().
+		RegisterUser(gomock.Any(), gomock.Any()).
+		Return(users.ErrAlreadyExists)
+
+	// Act
+	err := userHandler.CreateUser(c)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+
+	// Verify response body
+	var response map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, false, response["success"])
+	assert.Equal(t, "Phone number already registered", response["error"])
+}
+
+func TestCreateUser_InvalidFullName(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	userHandler := NewUserHandler(mockUserUC)
+
+	// Setup Echo context
+	e := echo.New()
+	requestBody := `{
+		"fullname": "John Doe",
+		"msisdn": "+6281234567890"
+	}`
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(requestBody))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Mock usecase to reject the full name
+	mockUserUC.EXPECT().
+		RegisterUser(gomock.Any(), gomock.Any()).
+		Return(&models.FieldError{Field: "fullname", Message: "must be at most 100 characters"})
+
+	// Act
+	err := userHandler.CreateUser(c)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	// Verify response body
+	var response map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"fullname": "must be at most 100 characters"}, response["fields"])
+}
+
 func TestGetUser_Success(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -233,6 +314,34 @@ func TestGetUser_MissingID(t *testing.T) {
 	assert.Equal(t, float64(http.StatusBadRequest), response["code"])
 }
 
+func TestGetUser_MalformedID(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	userHandler := NewUserHandler(mockUserUC)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/users/user-123", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues("user-123")
+
+	// Act
+	err := userHandler.GetUser(c)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "Invalid user ID", response["error"])
+}
+
 func TestGetUser_UseCaseError(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -415,3 +524,433 @@ func TestRegisterDriver_UseCaseError(t *testing.T) {
 	assert.Equal(t, "Failed to register driver", response["error"])
 	assert.Equal(t, float64(http.StatusInternalServerError), response["code"])
 }
+
+func TestListUsers_Filters(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	userHandler := NewUserHandler(mockUserUC)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/users?role=driver&is_active=false&search=jane&limit=5&offset=10", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	mockUserUC.EXPECT().
+		ListUsers(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ interface{}, filter models.UserFilter) (*models.UserList, error) {
+			assert.Equal(t, "driver", filter.Role)
+			assert.Equal(t, "jane", filter.Search)
+			assert.NotNil(t, filter.IsActive)
+			assert.False(t, *filter.IsActive)
+			assert.Equal(t, 5, filter.Limit)
+			assert.Equal(t, 10, filter.Offset)
+			return &models.UserList{Users: []*models.User{}, Total: 12, Limit: 5, Offset: 10}, nil
+		})
+
+	// Act
+	err := userHandler.ListUsers(c)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	var response map[string]interface{}
+	err = json.Unmarshal(rec.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	data, ok := response["data"].(map[string]interface{})
+	assert.True(t, ok)
+	assert.Equal(t, float64(12), data["total"])
+}
+
+func TestListUsers_InvalidActiveFilter(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	userHandler := NewUserHandler(mockUserUC)
+
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/users?is_active=maybe", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+
+	// Act
+	err := userHandler.ListUsers(c)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestGetDriverOnlineTime(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		expectCall     bool
+		expectedDate   string
+		expectedStatus int
+	}{
+		{
+			name:           "Requested day",
+			query:          "?date=2026-01-02",
+			expectCall:     true,
+			expectedDate:   "2026-01-02",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Defaults to today",
+			expectCall:     true,
+			expectedDate:   time.Now().Format("2006-01-02"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid date",
+			query:          "?date=02-01-2026",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			driverID := uuid.New().String()
+			if tt.expectCall {
+				mockUserUC.EXPECT().
+					GetDriverOnlineTime(gomock.Any(), driverID, gomock.Any()).
+					DoAndReturn(func(_ interface{}, _ string, day time.Time) (*models.DriverOnlineSummary, error) {
+						assert.Equal(t, tt.expectedDate, day.Format("2006-01-02"))
+						return &models.DriverOnlineSummary{DriverID: driverID, Date: tt.expectedDate, OnlineSeconds: 9000, Sessions: 2}, nil
+					})
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/drivers/"+driverID+"/online-time"+tt.query, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(driverID)
+
+			// Act
+			err := userHandler.GetDriverOnlineTime(c)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestGetDriverDocuments(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           *models.User
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "Driver found",
+			user:           &models.User{Role: "driver", DriverInfo: &models.Driver{}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "User is not a driver",
+			user:           &models.User{Role: "passenger"},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "No such user",
+			err:            users.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Lookup failure",
+			err:            errors.New("database down"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			driverID := uuid.New().String()
+			mockUserUC.EXPECT().GetUserByID(gomock.Any(), driverID).Return(tt.user, tt.err)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/admin/drivers/"+driverID, nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(driverID)
+
+			// Act
+			err := userHandler.GetDriverDocuments(c)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestVerifyDriver(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{
+			name:           "Verified",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "No such user",
+			err:            users.ErrUserNotFound,
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "User is not a driver",
+			err:            fmt.Errorf("%w: user is not registered as a driver", users.ErrDriverNotFound),
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "No documents submitted",
+			err:            users.ErrDriverDocumentsMissing,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Update failure",
+			err:            errors.New("database down"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			driverID := uuid.New().String()
+			var user *models.User
+			if tt.err == nil {
+				user = &models.User{Role: "driver", DriverInfo: &models.Driver{Verified: true}}
+			}
+			mockUserUC.EXPECT().VerifyDriver(gomock.Any(), driverID, true).Return(user, tt.err)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/admin/drivers/"+driverID+"/verify", strings.NewReader(`{"verified":true}`))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(driverID)
+
+			// Act
+			err := userHandler.VerifyDriver(c)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestGetDriverMatches(t *testing.T) {
+	driverID := uuid.New().String()
+
+	tests := []struct {
+		name           string
+		pathID         string
+		callerID       string
+		expectCall     bool
+		expectedStatus int
+	}{
+		{
+			name:           "Own history",
+			pathID:         driverID,
+			callerID:       driverID,
+			expectCall:     true,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Malformed driver ID",
+			pathID:         "not-a-uuid",
+			callerID:       "not-a-uuid",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Another driver's history",
+			pathID:         driverID,
+			callerID:       uuid.New().String(),
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			if tt.expectCall {
+				mockUserUC.EXPECT().
+					GetDriverMatches(gomock.Any(), tt.pathID, 20, 0).
+					Return(&models.MatchHistory{Matches: []*models.Match{}, Limit: 20}, nil)
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/drivers/"+tt.pathID+"/matches", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(tt.pathID)
+			c.Set("user_id", tt.callerID)
+
+			// Act
+			err := userHandler.GetDriverMatches(c)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestAnnounce(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		ucErr          error
+		expectCall     bool
+		expectedStatus int
+	}{
+		{
+			name:           "Published",
+			body:           `{"message":"Service resuming","roles":["driver"]}`,
+			expectCall:     true,
+			expectedStatus: http.StatusAccepted,
+		},
+		{
+			name:           "Invalid role",
+			body:           `{"message":"Service resuming","roles":["admin"]}`,
+			ucErr:          &models.FieldError{Field: "roles", Message: "must be one of driver, passenger"},
+			expectCall:     true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Publish failure",
+			body:           `{"message":"Service resuming"}`,
+			ucErr:          errors.New("nats: connection closed"),
+			expectCall:     true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+		{
+			name:           "Invalid payload",
+			body:           `{"message":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			if tt.expectCall {
+				mockUserUC.EXPECT().
+					Announce(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ interface{}, req *models.AnnouncementRequest) (*models.Announcement, error) {
+						assert.Equal(t, "Service resuming", req.Message)
+						if tt.ucErr != nil {
+							return nil, tt.ucErr
+						}
+						return &models.Announcement{ID: uuid.New().String(), Message: req.Message, Roles: req.Roles}, nil
+					})
+			}
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/admin/announcements", strings.NewReader(tt.body))
+			req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			// Act
+			err := userHandler.Announce(c)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+		})
+	}
+}
+
+func TestGetPickupCode(t *testing.T) {
+	tests := []struct {
+		name       string
+		ucErr      error
+		wantStatus int
+	}{
+		{name: "passenger gets the code", wantStatus: http.StatusOK},
+		{name: "not the ride's passenger", ucErr: users.ErrNotRideParticipant, wantStatus: http.StatusForbidden},
+		{name: "rides service down", ucErr: errors.New("connection refused"), wantStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			userHandler := NewUserHandler(mockUserUC)
+
+			userID := uuid.New().String()
+			rideID := uuid.New().String()
+			mockUserUC.EXPECT().
+				GetPickupCode(gomock.Any(), userID, "passenger", rideID).
+				Return(&models.PickupCodeResponse{RideID: rideID, PickupCode: "0427"}, tt.ucErr)
+
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodPost, "/rides/"+rideID+"/pickup-code", nil)
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+			c.SetParamNames("id")
+			c.SetParamValues(rideID)
+			c.Set("user_id", userID)
+			c.Set("role", "passenger")
+
+			err := userHandler.GetPickupCode(c)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, rec.Code)
+			if tt.ucErr == nil {
+				assert.Contains(t, rec.Body.String(), `"pickup_code":"0427"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// initAnnouncementSubscription subscribes to system announcements. This is a plain subscription
+// rather than a shared JetStream consumer, so every users service instance receives each
+// announcement and relays it to its own connections.
+func (h *NatsHandler) initAnnouncementSubscription() error {
+	sub, err := h.natsClient.Subscribe(constants.SubjectSystemAnnouncement, h.handleAnnouncementMsg)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to announcements: %w", err)
+	}
+	h.subs = append(h.subs, sub)
+	return nil
+}
+
+// handleAnnouncementMsg handles announcements from the core NATS subscription
+func (h *NatsHandler) handleAnnouncementMsg(msg *nats.Msg) {
+	if err := h.handleAnnouncement(msg.Data); err != nil {
+		logger.Error("Failed to handle announcement",
+			logger.String("subject", msg.Subject),
+			logger.ErrorField(err))
+	}
+}
+
+// handleAnnouncement broadcasts an announcement to the connected users it is meant for
+func (h *NatsHandler) handleAnnouncement(data []byte) error {
+	var announcement models.Announcement
+	if err := json.Unmarshal(data, &announcement); err != nil {
+		return fmt.Errorf("failed to unmarshal announcement: %w", err)
+	}
+
+	recipients := h.echoWSHandler.Broadcast(constants.EventAnnouncement, announcement, announcement.Roles...)
+
+	logger.Info("Broadcasting announcement",
+		logger.String("announcement_id", announcement.ID),
+		logger.Strings("roles", announcement.Roles),
+		logger.Int("recipients", recipients))
+	return nil
+}
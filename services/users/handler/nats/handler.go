@@ -5,12 +5,16 @@ import (
 
 	"github.com/nats-io/nats.go"
 	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
+	"github.com/piresc/nebengjek/services/users"
+	"github.com/piresc/nebengjek/services/users/handler/sse"
 	"github.com/piresc/nebengjek/services/users/handler/websocket"
 )
 
 // Handler handles NATS events for the user service
 type NatsHandler struct {
 	echoWSHandler *websocket.EchoWebSocketHandler
+	rideEvents    *sse.RideEventHandler
+	userUC        users.UserUC
 	natsClient    *natspkg.Client
 	subs          []*nats.Subscription
 }
@@ -18,10 +22,14 @@ type NatsHandler struct {
 // NewNatsHandler creates a new NATS handler
 func NewNatsHandler(
 	echoWSHandler *websocket.EchoWebSocketHandler,
+	rideEvents *sse.RideEventHandler,
+	userUC users.UserUC,
 	natsClient *natspkg.Client,
 ) *NatsHandler {
 	return &NatsHandler{
 		echoWSHandler: echoWSHandler,
+		rideEvents:    rideEvents,
+		userUC:        userUC,
 		natsClient:    natsClient,
 	}
 }
@@ -38,5 +46,15 @@ func (h *NatsHandler) InitConsumers() error {
 		return fmt.Errorf("failed to initialize ride consumers: %w", err)
 	}
 
+	// Initialize location-related consumers
+	if err := h.initLocationConsumers(); err != nil {
+		return fmt.Errorf("failed to initialize location consumers: %w", err)
+	}
+
+	// Subscribe to system announcements
+	if err := h.initAnnouncementSubscription(); err != nil {
+		return fmt.Errorf("failed to initialize announcement subscription: %w", err)
+	}
+
 	return nil
 }
@@ -0,0 +1,52 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	natspkg "github.com/piresc/nebengjek/internal/pkg/nats"
+)
+
+// initLocationConsumers initializes JetStream consumers for location events
+func (h *NatsHandler) initLocationConsumers() error {
+	consumerConfigs := natspkg.DefaultConsumerConfigs()
+
+	driverEvictedConfig := consumerConfigs["location_driver_evicted_users"]
+	if err := h.natsClient.CreateConsumer(driverEvictedConfig); err != nil {
+		logger.Error("Failed to create driver evicted consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to create driver evicted consumer: %w", err)
+	}
+
+	if err := h.natsClient.ConsumeMessages("LOCATION_STREAM", "location_driver_evicted_users", h.handleDriverEvictedEventJS); err != nil {
+		logger.Error("Failed to start consuming driver evicted events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming driver evicted events: %w", err)
+	}
+
+	return nil
+}
+
+// handleDriverEvictedEventJS processes driver evictions from JetStream
+func (h *NatsHandler) handleDriverEvictedEventJS(msg jetstream.Msg) error {
+	if err := h.handleDriverEvictedEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling driver evicted event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil
+}
+
+// handleDriverEvictedEvent closes the online session of a driver evicted from the pool
+func (h *NatsHandler) handleDriverEvictedEvent(msg []byte) error {
+	var eviction models.DriverEviction
+	if err := json.Unmarshal(msg, &eviction); err != nil {
+		return fmt.Errorf("failed to unmarshal driver eviction: %w", err)
+	}
+
+	return h.userUC.EndEvictedDriverSession(context.Background(), &eviction)
+}
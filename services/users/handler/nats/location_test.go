@@ -0,0 +1,41 @@
+package nats
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleDriverEvictedEvent_EndsOnlineSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	h := NewNatsHandler(nil, nil, mockUC, nil)
+
+	// A driver whose app died never turns the beacon off, so the eviction closes their session
+	eviction := models.DriverEviction{
+		DriverID:   uuid.New().String(),
+		LastSeenAt: time.Date(2025, 1, 8, 10, 0, 0, 0, time.UTC),
+	}
+	data, _ := json.Marshal(eviction)
+
+	mockUC.EXPECT().EndEvictedDriverSession(gomock.Any(), &eviction).Return(nil)
+
+	assert.NoError(t, h.handleDriverEvictedEvent(data))
+}
+
+func TestHandleDriverEvictedEvent_InvalidJSON(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := NewNatsHandler(nil, nil, mocks.NewMockUserUC(ctrl), nil)
+
+	assert.Error(t, h.handleDriverEvictedEvent([]byte(`{invalid`)))
+}
@@ -76,6 +76,78 @@ func (h *NatsHandler) initMatchConsumers() error {
 		return fmt.Errorf("failed to start consuming match rejected events: %w", err)
 	}
 
+	// Create match no drivers consumer
+	matchNoDriversConfig := consumerConfigs["match_no_drivers_users"]
+	logger.Info("Creating match no drivers consumer for users service",
+		logger.String("stream", matchNoDriversConfig.StreamName),
+		logger.String("consumer", matchNoDriversConfig.ConsumerName))
+
+	if err := h.natsClient.CreateConsumer(matchNoDriversConfig); err != nil {
+		logger.Error("Failed to create match no drivers consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to create match no drivers consumer: %w", err)
+	}
+
+	// Start consuming match no drivers events
+	if err := h.natsClient.ConsumeMessages("MATCH_STREAM", "match_no_drivers_users", h.handleNoDriversEventJS); err != nil {
+		logger.Error("Failed to start consuming match no drivers events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming match no drivers events: %w", err)
+	}
+
+	// Create match maintenance consumer
+	matchMaintenanceConfig := consumerConfigs["match_maintenance_users"]
+	logger.Info("Creating match maintenance consumer for users service",
+		logger.String("stream", matchMaintenanceConfig.StreamName),
+		logger.String("consumer", matchMaintenanceConfig.ConsumerName))
+
+	if err := h.natsClient.CreateConsumer(matchMaintenanceConfig); err != nil {
+		logger.Error("Failed to create match maintenance consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to create match maintenance consumer: %w", err)
+	}
+
+	// Start consuming match maintenance events
+	if err := h.natsClient.ConsumeMessages("MATCH_STREAM", "match_maintenance_users", h.handleMatchMaintenanceEventJS); err != nil {
+		logger.Error("Failed to start consuming match maintenance events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming match maintenance events: %w", err)
+	}
+
+	// Create match acceptances consumer
+	matchAcceptancesConfig := consumerConfigs["match_acceptances_users"]
+	logger.Info("Creating match acceptances consumer for users service",
+		logger.String("stream", matchAcceptancesConfig.StreamName),
+		logger.String("consumer", matchAcceptancesConfig.ConsumerName))
+
+	if err := h.natsClient.CreateConsumer(matchAcceptancesConfig); err != nil {
+		logger.Error("Failed to create match acceptances consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to create match acceptances consumer: %w", err)
+	}
+
+	// Start consuming match acceptances events
+	if err := h.natsClient.ConsumeMessages("MATCH_STREAM", "match_acceptances_users", h.handleDriverAcceptancesEventJS); err != nil {
+		logger.Error("Failed to start consuming match acceptances events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming match acceptances events: %w", err)
+	}
+
+	// Create pending proposals consumer
+	matchProposalsConfig := consumerConfigs["match_proposals_users"]
+	if err := h.natsClient.CreateConsumer(matchProposalsConfig); err != nil {
+		logger.Error("Failed to create match proposals consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to create match proposals consumer: %w", err)
+	}
+
+	// Start consuming pending proposals events
+	if err := h.natsClient.ConsumeMessages("MATCH_STREAM", "match_proposals_users", h.handlePendingProposalsEventJS); err != nil {
+		logger.Error("Failed to start consuming match proposals events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming match proposals events: %w", err)
+	}
+
 	logger.Info("Successfully initialized JetStream consumers for match events in users service")
 	return nil
 }
@@ -123,6 +195,55 @@ func (h *NatsHandler) handleMatchRejectedEventJS(msg jetstream.Msg) error {
 	return nil // Success - message will be ACKed automatically
 }
 
+// handleNoDriversEventJS processes no drivers found events from JetStream
+func (h *NatsHandler) handleNoDriversEventJS(msg jetstream.Msg) error {
+	logger.InfoCtx(context.Background(), "Received no drivers found event from JetStream",
+		logger.String("subject", msg.Subject()))
+
+	if err := h.handleNoDriversEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling no drivers found event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
+// handleMatchMaintenanceEventJS processes match maintenance events from JetStream
+func (h *NatsHandler) handleMatchMaintenanceEventJS(msg jetstream.Msg) error {
+	logger.InfoCtx(context.Background(), "Received match maintenance event from JetStream",
+		logger.String("subject", msg.Subject()))
+
+	if err := h.handleMatchMaintenanceEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling match maintenance event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
+// handleDriverAcceptancesEventJS processes driver acceptances events from JetStream
+func (h *NatsHandler) handleDriverAcceptancesEventJS(msg jetstream.Msg) error {
+	logger.InfoCtx(context.Background(), "Received driver acceptances event from JetStream",
+		logger.String("subject", msg.Subject()))
+
+	if err := h.handleDriverAcceptancesEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling driver acceptances event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
+// handlePendingProposalsEventJS processes pending proposals events from JetStream
+func (h *NatsHandler) handlePendingProposalsEventJS(msg jetstream.Msg) error {
+	if err := h.handlePendingProposalsEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling pending proposals event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
 // handleMatchEvent processes match events
 func (h *NatsHandler) handleMatchEvent(msg []byte) error {
 	var event models.MatchProposal
@@ -130,6 +251,10 @@ func (h *NatsHandler) handleMatchEvent(msg []byte) error {
 		return fmt.Errorf("failed to unmarshal match event: %w", err)
 	}
 
+	logger.Info("Received match found event",
+		logger.String("match_id", event.ID),
+		logger.String("correlation_id", event.CorrelationID))
+
 	// Notify both driver and passenger
 	h.echoWSHandler.NotifyClient(event.DriverID, constants.SubjectMatchFound, event)
 	h.echoWSHandler.NotifyClient(event.PassengerID, constants.SubjectMatchFound, event)
@@ -143,6 +268,10 @@ func (h *NatsHandler) handleMatchAccEvent(msg []byte) error {
 		return fmt.Errorf("failed to unmarshal match event: %w", err)
 	}
 
+	logger.Info("Received match accepted event",
+		logger.String("match_id", event.ID),
+		logger.String("correlation_id", event.CorrelationID))
+
 	// Notify both driver and passenger
 	h.echoWSHandler.NotifyClient(event.DriverID, constants.SubjectMatchAccepted, event)
 	h.echoWSHandler.NotifyClient(event.PassengerID, constants.SubjectMatchAccepted, event)
@@ -160,3 +289,51 @@ func (h *NatsHandler) handleMatchRejectedEvent(msg []byte) error {
 	h.echoWSHandler.NotifyClient(event.DriverID, constants.EventMatchRejected, event)
 	return nil
 }
+
+// handleNoDriversEvent processes no drivers found events
+func (h *NatsHandler) handleNoDriversEvent(msg []byte) error {
+	var event models.NoDriversFoundEvent
+	if err := json.Unmarshal(msg, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal no drivers found event: %w", err)
+	}
+
+	// Only the searching passenger cares about this
+	h.echoWSHandler.NotifyClient(event.PassengerID, constants.EventMatchNoDrivers, event)
+	return nil
+}
+
+// handleMatchMaintenanceEvent processes match maintenance events
+func (h *NatsHandler) handleMatchMaintenanceEvent(msg []byte) error {
+	var event models.MatchMaintenanceEvent
+	if err := json.Unmarshal(msg, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal match maintenance event: %w", err)
+	}
+
+	// Only the refused driver or passenger is told
+	h.echoWSHandler.NotifyClient(event.UserID, constants.EventMatchMaintenance, event)
+	return nil
+}
+
+// handleDriverAcceptancesEvent processes driver acceptances events
+func (h *NatsHandler) handleDriverAcceptancesEvent(msg []byte) error {
+	var event models.DriverAcceptancesEvent
+	if err := json.Unmarshal(msg, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal driver acceptances event: %w", err)
+	}
+
+	// The passenger picks one of the drivers by confirming that match
+	h.echoWSHandler.NotifyClient(event.PassengerID, constants.EventMatchAcceptances, event)
+	return nil
+}
+
+// handlePendingProposalsEvent processes pending proposals events
+func (h *NatsHandler) handlePendingProposalsEvent(msg []byte) error {
+	var event models.PendingProposalsEvent
+	if err := json.Unmarshal(msg, &event); err != nil {
+		return fmt.Errorf("failed to unmarshal pending proposals event: %w", err)
+	}
+
+	// Replaces the distances and ETAs the passenger was shown with the drivers' current ones
+	h.echoWSHandler.NotifyClient(event.PassengerID, constants.EventMatchProposals, event)
+	return nil
+}
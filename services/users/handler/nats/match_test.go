@@ -3,12 +3,20 @@ package nats
 import (
 	"encoding/json"
 	"fmt"
+	"net/http/httptest"
 	"testing"
+	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users/handler/sse"
+	"github.com/piresc/nebengjek/services/users/handler/websocket"
+	"github.com/piresc/nebengjek/services/users/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // MockNotification represents a notification sent to a client
@@ -240,3 +248,47 @@ func TestMatchHandleMatchRejectedEvent_InvalidJSON(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to unmarshal match rejected event")
 	assert.Len(t, mockWS.GetNotifications(), 0)
 }
+
+func TestHandleMatchMaintenanceEvent_NotifiesUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	wsHandler := websocket.NewEchoWebSocketHandler(mockUC)
+	h := NewNatsHandler(wsHandler, sse.NewRideEventHandler(mockUC), mockUC, nil)
+
+	e := echo.New()
+	e.GET("/ws", wsHandler.HandleWebSocket, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", c.QueryParam("user_id"))
+			c.Set("role", c.QueryParam("role"))
+			return next(c)
+		}
+	})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	driverID := uuid.New().String()
+	driver := dialWSClient(t, server.URL, driverID, "driver")
+	defer driver.Close()
+
+	event := models.MatchMaintenanceEvent{UserID: driverID, Role: "driver", Timestamp: time.Now()}
+	data, _ := json.Marshal(event)
+	require.NoError(t, h.handleMatchMaintenanceEvent(data))
+
+	msg := receiveWSMessage(t, driver)
+	assert.Equal(t, constants.EventMatchMaintenance, msg.Event)
+	var got models.MatchMaintenanceEvent
+	require.NoError(t, json.Unmarshal(msg.Data, &got))
+	assert.Equal(t, driverID, got.UserID)
+	assert.Equal(t, "driver", got.Role)
+}
+
+func TestHandleMatchMaintenanceEvent_InvalidJSON(t *testing.T) {
+	h := &NatsHandler{}
+
+	err := h.handleMatchMaintenanceEvent([]byte(`invalid json`))
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to unmarshal match maintenance event")
+}
@@ -45,6 +45,21 @@ func (h *NatsHandler) initRideConsumers() error {
 		return fmt.Errorf("failed to start consuming ride pickup events: %w", err)
 	}
 
+	// Create ride pickup ETA consumer - RECREATE to ensure DeliverNewPolicy is applied
+	ridePickupETAConfig := consumerConfigs["ride_pickup_eta_users"]
+	if err := h.natsClient.RecreateConsumer(ridePickupETAConfig); err != nil {
+		logger.Error("Failed to recreate ride pickup ETA consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to recreate ride pickup ETA consumer: %w", err)
+	}
+
+	// Start consuming ride pickup ETA events
+	if err := h.natsClient.ConsumeMessages("RIDE_STREAM", "ride_pickup_eta_users", h.handleRidePickupETAEventJS); err != nil {
+		logger.Error("Failed to start consuming ride pickup ETA events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming ride pickup ETA events: %w", err)
+	}
+
 	// Create ride started consumer - RECREATE to ensure DeliverNewPolicy is applied
 	rideStartedConfig := consumerConfigs["ride_started_users"]
 	logger.Info("Recreating ride started consumer for users service with DeliverNewPolicy",
@@ -65,6 +80,20 @@ func (h *NatsHandler) initRideConsumers() error {
 		return fmt.Errorf("failed to start consuming ride started events: %w", err)
 	}
 
+	// Payment requests for rides settled by the rides service, e.g. past the maximum ride duration
+	rideArrivedConfig := consumerConfigs["ride_arrived_users"]
+	if err := h.natsClient.RecreateConsumer(rideArrivedConfig); err != nil {
+		logger.Error("Failed to recreate ride arrived consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to recreate ride arrived consumer: %w", err)
+	}
+
+	if err := h.natsClient.ConsumeMessages("RIDE_STREAM", "ride_arrived_users", h.handleRideArrivedEventJS); err != nil {
+		logger.Error("Failed to start consuming ride arrived events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming ride arrived events: %w", err)
+	}
+
 	// Create ride completed consumer - RECREATE to ensure DeliverNewPolicy is applied
 	rideCompletedConfig := consumerConfigs["ride_completed_users"]
 	logger.Info("Recreating ride completed consumer for users service with DeliverNewPolicy",
@@ -85,6 +114,34 @@ func (h *NatsHandler) initRideConsumers() error {
 		return fmt.Errorf("failed to start consuming ride completed events: %w", err)
 	}
 
+	// Cancellations end the ride for both participants and close its event streams
+	rideCancelledConfig := consumerConfigs["ride_cancelled_users"]
+	if err := h.natsClient.RecreateConsumer(rideCancelledConfig); err != nil {
+		logger.Error("Failed to recreate ride cancelled consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to recreate ride cancelled consumer: %w", err)
+	}
+
+	if err := h.natsClient.ConsumeMessages("RIDE_STREAM", "ride_cancelled_users", h.handleRideCancelledEventJS); err != nil {
+		logger.Error("Failed to start consuming ride cancelled events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming ride cancelled events: %w", err)
+	}
+
+	// Billing updates of ongoing rides, streamed to clients following the ride
+	rideBillingConfig := consumerConfigs["ride_billing_users"]
+	if err := h.natsClient.RecreateConsumer(rideBillingConfig); err != nil {
+		logger.Error("Failed to recreate ride billing consumer for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to recreate ride billing consumer: %w", err)
+	}
+
+	if err := h.natsClient.ConsumeMessages("RIDE_STREAM", "ride_billing_users", h.handleRideBillingEventJS); err != nil {
+		logger.Error("Failed to start consuming ride billing events for users service",
+			logger.ErrorField(err))
+		return fmt.Errorf("failed to start consuming ride billing events: %w", err)
+	}
+
 	logger.Info("Successfully initialized JetStream consumers for ride events")
 	return nil
 }
@@ -104,6 +161,16 @@ func (h *NatsHandler) handleRidePickupEventJS(msg jetstream.Msg) error {
 	return nil // Success - message will be ACKed automatically
 }
 
+// handleRidePickupETAEventJS processes ride pickup ETA events from JetStream
+func (h *NatsHandler) handleRidePickupETAEventJS(msg jetstream.Msg) error {
+	if err := h.handleRidePickupETAEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling ride pickup ETA event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
 // handleRideStartEventJS processes ride start events from JetStream
 func (h *NatsHandler) handleRideStartEventJS(msg jetstream.Msg) error {
 	logger.InfoCtx(context.Background(), "Received ride start event from JetStream",
@@ -117,6 +184,16 @@ func (h *NatsHandler) handleRideStartEventJS(msg jetstream.Msg) error {
 	return nil // Success - message will be ACKed automatically
 }
 
+// handleRideArrivedEventJS processes payment requests from JetStream
+func (h *NatsHandler) handleRideArrivedEventJS(msg jetstream.Msg) error {
+	if err := h.handleRideArrivedEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling ride arrived event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
 // handleRideCompletedEventJS processes ride completed events from JetStream
 func (h *NatsHandler) handleRideCompletedEventJS(msg jetstream.Msg) error {
 	// DIAGNOSTIC: Log message metadata to understand replay behavior
@@ -137,6 +214,26 @@ func (h *NatsHandler) handleRideCompletedEventJS(msg jetstream.Msg) error {
 	return nil // Success - message will be ACKed automatically
 }
 
+// handleRideCancelledEventJS processes ride cancelled events from JetStream
+func (h *NatsHandler) handleRideCancelledEventJS(msg jetstream.Msg) error {
+	if err := h.handleRideCancelledEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling ride cancelled event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
+// handleRideBillingEventJS processes ride billing updates from JetStream
+func (h *NatsHandler) handleRideBillingEventJS(msg jetstream.Msg) error {
+	if err := h.handleRideBillingEvent(msg.Data()); err != nil {
+		logger.ErrorCtx(context.Background(), "Error handling ride billing event", logger.Err(err))
+		return err // Return error to trigger NAK and retry
+	}
+
+	return nil // Success - message will be ACKed automatically
+}
+
 // handleMatchAcceptedEvent processes match accepted events from NATS
 func (h *NatsHandler) handleMatchAcceptedEvent(msg []byte) error {
 	var matchProposal models.MatchProposal
@@ -174,22 +271,52 @@ func (h *NatsHandler) handleRidePickupEvent(msg []byte) error {
 		logger.String("ride_id", ridePickup.RideID),
 		logger.String("driver_id", ridePickup.DriverID),
 		logger.String("passenger_id", ridePickup.PassengerID),
-		logger.String("status", ridePickup.Status))
+		logger.String("status", ridePickup.Status),
+		logger.String("correlation_id", ridePickup.CorrelationID))
 
 	logger.InfoCtx(context.Background(), "Sending WebSocket notifications for ride pickup",
 		logger.String("driver_id", ridePickup.DriverID),
 		logger.String("passenger_id", ridePickup.PassengerID),
 		logger.String("event_type", constants.EventRidePickup))
 
+	// The event never carries the pickup code, so it is fetched for the passenger's notification
+	// alone. Without it the passenger can still ask for the code through the pickup code endpoint.
+	ridePickup.PickupCode = ""
+	passengerPickup := ridePickup
+	if ridePickup.PickupCodeRequired {
+		resp, err := h.userUC.GetRidePickupCode(context.Background(), ridePickup.PassengerID, ridePickup.RideID)
+		if err != nil {
+			logger.WarnCtx(context.Background(), "Failed to fetch pickup code for passenger",
+				logger.String("ride_id", ridePickup.RideID),
+				logger.String("passenger_id", ridePickup.PassengerID),
+				logger.ErrorField(err))
+		} else {
+			passengerPickup.PickupCode = resp.PickupCode
+		}
+	}
+
 	// Notify both driver and passenger with correct WebSocket event type
 	h.echoWSHandler.NotifyClient(ridePickup.DriverID, constants.EventRidePickup, ridePickup)
-	h.echoWSHandler.NotifyClient(ridePickup.PassengerID, constants.EventRidePickup, ridePickup)
+	h.echoWSHandler.NotifyClient(ridePickup.PassengerID, constants.EventRidePickup, passengerPickup)
+	h.rideEvents.Publish(ridePickup.RideID, constants.EventRidePickup, ridePickup)
 
 	logger.InfoCtx(context.Background(), "Successfully processed ride pickup event and sent WebSocket notifications",
 		logger.String("ride_id", ridePickup.RideID))
 	return nil
 }
 
+// handleRidePickupETAEvent forwards a refreshed pickup ETA to the waiting passenger
+func (h *NatsHandler) handleRidePickupETAEvent(msg []byte) error {
+	var etaEvent models.RidePickupETAEvent
+	if err := json.Unmarshal(msg, &etaEvent); err != nil {
+		return fmt.Errorf("failed to unmarshal ride pickup ETA event: %w", err)
+	}
+
+	h.echoWSHandler.NotifyClient(etaEvent.PassengerID, constants.EventRidePickupETA, etaEvent)
+	h.rideEvents.Publish(etaEvent.RideID, constants.EventRidePickupETA, etaEvent)
+	return nil
+}
+
 // handleMatchAcceptedEvent processes match accepted events from NATS
 func (h *NatsHandler) handleRideStartEvent(msg []byte) error {
 	var rideStarted models.RideResp
@@ -200,16 +327,35 @@ func (h *NatsHandler) handleRideStartEvent(msg []byte) error {
 	logger.InfoCtx(context.Background(), "Received ride started event",
 		logger.String("ride_id", rideStarted.RideID),
 		logger.String("driver_id", rideStarted.DriverID),
-		logger.String("passenger_id", rideStarted.PassengerID))
+		logger.String("passenger_id", rideStarted.PassengerID),
+		logger.String("correlation_id", rideStarted.CorrelationID))
 
 	// Notify both driver and passenger that their match is confirmed and they're locked
 	// Use a specific event type for match acceptance notification
 	h.echoWSHandler.NotifyClient(rideStarted.DriverID, constants.EventRideStarted, rideStarted)
 	h.echoWSHandler.NotifyClient(rideStarted.PassengerID, constants.EventRideStarted, rideStarted)
+	h.rideEvents.Publish(rideStarted.RideID, constants.EventRideStarted, rideStarted)
 
 	return nil
 }
 
+// handleRideArrivedEvent forwards a payment request to the passenger who has to pay
+func (h *NatsHandler) handleRideArrivedEvent(msg []byte) error {
+	var paymentReq models.PaymentRequest
+	if err := json.Unmarshal(msg, &paymentReq); err != nil {
+		return fmt.Errorf("failed to unmarshal ride arrived event: %w", err)
+	}
+
+	logger.InfoCtx(context.Background(), "Received payment request",
+		logger.String("ride_id", paymentReq.RideID),
+		logger.String("passenger_id", paymentReq.PassengerID),
+		logger.Int("total_cost", paymentReq.TotalCost))
+
+	h.echoWSHandler.NotifyClient(paymentReq.PassengerID, constants.EventPaymentRequest, paymentReq)
+	h.rideEvents.Publish(paymentReq.RideID, constants.EventPaymentRequest, paymentReq)
+	return nil
+}
+
 // handleRideCompletedEvent processes ride completed events
 func (h *NatsHandler) handleRideCompletedEvent(msg []byte) error {
 	var rideComplete models.RideComplete
@@ -225,6 +371,36 @@ func (h *NatsHandler) handleRideCompletedEvent(msg []byte) error {
 	// Notify driver and passenger about the ride completion
 	h.echoWSHandler.NotifyClient(rideComplete.Ride.DriverID.String(), constants.EventRideCompleted, rideComplete)
 	h.echoWSHandler.NotifyClient(rideComplete.Ride.PassengerID.String(), constants.EventRideCompleted, rideComplete)
+	h.rideEvents.Publish(rideComplete.Ride.RideID.String(), constants.EventRideCompleted, rideComplete)
+
+	return nil
+}
+
+// handleRideCancelledEvent tells both participants the ride was cancelled and ends its event streams
+func (h *NatsHandler) handleRideCancelledEvent(msg []byte) error {
+	var cancelled models.RideCancelled
+	if err := json.Unmarshal(msg, &cancelled); err != nil {
+		return fmt.Errorf("failed to unmarshal ride cancelled event: %w", err)
+	}
+
+	logger.InfoCtx(context.Background(), "Received ride cancelled event",
+		logger.String("ride_id", cancelled.Ride.RideID.String()),
+		logger.String("cancelled_by", cancelled.Cancellation.Role))
+
+	h.echoWSHandler.NotifyClient(cancelled.Ride.DriverID.String(), constants.EventRideCancelled, cancelled)
+	h.echoWSHandler.NotifyClient(cancelled.Ride.PassengerID.String(), constants.EventRideCancelled, cancelled)
+	h.rideEvents.Publish(cancelled.Ride.RideID.String(), constants.EventRideCancelled, cancelled)
+
+	return nil
+}
+
+// handleRideBillingEvent streams a billing update to the clients following the ride
+func (h *NatsHandler) handleRideBillingEvent(msg []byte) error {
+	var update models.RideBillingUpdate
+	if err := json.Unmarshal(msg, &update); err != nil {
+		return fmt.Errorf("failed to unmarshal ride billing event: %w", err)
+	}
 
+	h.rideEvents.Publish(update.RideID, constants.EventRideBilling, update)
 	return nil
 }
@@ -0,0 +1,254 @@
+package nats
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users/handler/sse"
+	"github.com/piresc/nebengjek/services/users/handler/websocket"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	xws "golang.org/x/net/websocket"
+)
+
+func TestRideEvents_StreamedOverSSE(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ride := models.Ride{RideID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New()}
+	rideID := ride.RideID.String()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	mockUC.EXPECT().CheckRideParticipant(gomock.Any(), ride.PassengerID.String(), "passenger", rideID).Return(nil)
+	mockUC.EXPECT().
+		GetRidePickupCode(gomock.Any(), ride.PassengerID.String(), rideID).
+		Return(&models.PickupCodeResponse{RideID: rideID, PickupCode: "4821"}, nil)
+
+	rideEvents := sse.NewRideEventHandler(mockUC)
+	h := NewNatsHandler(websocket.NewEchoWebSocketHandler(mockUC), rideEvents, mockUC, nil)
+
+	e := echo.New()
+	e.GET("/rides/:id/events", rideEvents.StreamRideEvents, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", ride.PassengerID.String())
+			c.Set("role", "passenger")
+			return next(c)
+		}
+	})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/rides/" + rideID + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	rideResp := models.RideResp{RideID: rideID, DriverID: ride.DriverID.String(), PassengerID: ride.PassengerID.String(), PickupCodeRequired: true}
+	pickup, _ := json.Marshal(rideResp)
+	eta, _ := json.Marshal(models.RidePickupETAEvent{RideID: rideID, PassengerID: ride.PassengerID.String(), PickupETASeconds: 120})
+	completed, _ := json.Marshal(models.RideComplete{Ride: ride})
+
+	// Deliver events the way the NATS consumers do
+	require.NoError(t, h.handleRidePickupEvent(pickup))
+	require.NoError(t, h.handleRidePickupETAEvent(eta))
+	require.NoError(t, h.handleRideStartEvent(pickup))
+	require.NoError(t, h.handleRideCompletedEvent(completed))
+
+	var events []string
+	var etaPayload models.RidePickupETAEvent
+	var pickupPayload models.RideResp
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			events = append(events, strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: ") && events[len(events)-1] == constants.EventRidePickupETA:
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &etaPayload))
+		case strings.HasPrefix(line, "data: ") && events[len(events)-1] == constants.EventRidePickup:
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &pickupPayload))
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	// The body ends once the ride completes
+	assert.Equal(t, []string{
+		constants.EventRidePickup,
+		constants.EventRidePickupETA,
+		constants.EventRideStarted,
+		constants.EventRideCompleted,
+	}, events)
+	assert.Equal(t, 120, etaPayload.PickupETASeconds)
+	// The stream is open to the driver too, so it never carries the passenger's pickup code
+	assert.Equal(t, rideID, pickupPayload.RideID)
+	assert.Empty(t, pickupPayload.PickupCode)
+}
+
+func TestRideEvents_StreamClosesOnCancellation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	ride := models.Ride{RideID: uuid.New(), DriverID: uuid.New(), PassengerID: uuid.New()}
+	rideID := ride.RideID.String()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	mockUC.EXPECT().CheckRideParticipant(gomock.Any(), ride.DriverID.String(), "driver", rideID).Return(nil)
+
+	rideEvents := sse.NewRideEventHandler(mockUC)
+	h := NewNatsHandler(websocket.NewEchoWebSocketHandler(mockUC), rideEvents, mockUC, nil)
+
+	e := echo.New()
+	e.GET("/rides/:id/events", rideEvents.StreamRideEvents, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", ride.DriverID.String())
+			c.Set("role", "driver")
+			return next(c)
+		}
+	})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/rides/" + rideID + "/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	billing, _ := json.Marshal(models.RideBillingUpdate{RideID: rideID, Distance: 1.2, Cost: 3600, TotalCost: 3600})
+	payment, _ := json.Marshal(models.PaymentRequest{RideID: rideID, PassengerID: ride.PassengerID.String(), TotalCost: 3600})
+	cancelled, _ := json.Marshal(models.RideCancelled{Ride: ride, Cancellation: models.RideCancellation{RideID: ride.RideID, Role: "passenger"}})
+
+	require.NoError(t, h.handleRideBillingEvent(billing))
+	require.NoError(t, h.handleRideArrivedEvent(payment))
+	require.NoError(t, h.handleRideCancelledEvent(cancelled))
+
+	var events []string
+	var billingPayload models.RideBillingUpdate
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "event: "):
+			events = append(events, strings.TrimPrefix(line, "event: "))
+		case strings.HasPrefix(line, "data: ") && events[len(events)-1] == constants.EventRideBilling:
+			require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &billingPayload))
+		}
+	}
+	require.NoError(t, scanner.Err())
+
+	// The body ends once the ride is cancelled
+	assert.Equal(t, []string{
+		constants.EventRideBilling,
+		constants.EventPaymentRequest,
+		constants.EventRideCancelled,
+	}, events)
+	assert.Equal(t, 3600, billingPayload.TotalCost)
+}
+
+func TestRidePickup_CodeOnlyReachesPassenger(t *testing.T) {
+	tests := []struct {
+		name     string
+		codeErr  error
+		wantCode string
+	}{
+		{name: "passenger gets the code", wantCode: "4821"},
+		{name: "passenger notified without the code when it can't be fetched", codeErr: errors.New("rides service down")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			rideID := uuid.New().String()
+			driverID := uuid.New().String()
+			passengerID := uuid.New().String()
+
+			mockUC := mocks.NewMockUserUC(ctrl)
+			var codeResp *models.PickupCodeResponse
+			if tt.codeErr == nil {
+				codeResp = &models.PickupCodeResponse{RideID: rideID, PickupCode: tt.wantCode}
+			}
+			mockUC.EXPECT().GetRidePickupCode(gomock.Any(), passengerID, rideID).Return(codeResp, tt.codeErr)
+
+			wsHandler := websocket.NewEchoWebSocketHandler(mockUC)
+			h := NewNatsHandler(wsHandler, sse.NewRideEventHandler(mockUC), mockUC, nil)
+
+			e := echo.New()
+			e.GET("/ws", wsHandler.HandleWebSocket, func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					c.Set("user_id", c.QueryParam("user_id"))
+					c.Set("role", c.QueryParam("role"))
+					return next(c)
+				}
+			})
+			server := httptest.NewServer(e)
+			defer server.Close()
+
+			driver := dialWSClient(t, server.URL, driverID, "driver")
+			defer driver.Close()
+			passenger := dialWSClient(t, server.URL, passengerID, "passenger")
+			defer passenger.Close()
+
+			pickup, _ := json.Marshal(models.RideResp{RideID: rideID, DriverID: driverID, PassengerID: passengerID, PickupCodeRequired: true})
+			require.NoError(t, h.handleRidePickupEvent(pickup))
+
+			driverPickup := receiveRidePickup(t, driver)
+			assert.Empty(t, driverPickup.PickupCode)
+			assert.True(t, driverPickup.PickupCodeRequired)
+
+			passengerPickup := receiveRidePickup(t, passenger)
+			assert.Equal(t, tt.wantCode, passengerPickup.PickupCode)
+			assert.True(t, passengerPickup.PickupCodeRequired)
+		})
+	}
+}
+
+// dialWSClient connects a user's WebSocket and waits until the handler has registered it, which
+// it shows by answering a malformed frame
+func dialWSClient(t *testing.T, serverURL, userID, role string) *xws.Conn {
+	t.Helper()
+
+	wsURL := "ws" + strings.TrimPrefix(serverURL, "http") + "/ws?user_id=" + userID + "&role=" + role
+	conn, err := xws.Dial(wsURL, "", "http://localhost/")
+	require.NoError(t, err)
+
+	require.NoError(t, xws.Message.Send(conn, "not json"))
+	var reply models.WSMessage
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, xws.JSON.Receive(conn, &reply))
+	return conn
+}
+
+// receiveWSMessage reads the next frame from conn
+func receiveWSMessage(t *testing.T, conn *xws.Conn) models.WSMessage {
+	t.Helper()
+
+	var msg models.WSMessage
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(2*time.Second)))
+	require.NoError(t, xws.JSON.Receive(conn, &msg))
+	return msg
+}
+
+// receiveRidePickup reads the next frame from conn and returns its ride pickup payload
+func receiveRidePickup(t *testing.T, conn *xws.Conn) models.RideResp {
+	t.Helper()
+
+	msg := receiveWSMessage(t, conn)
+	require.Equal(t, constants.EventRidePickup, msg.Event)
+
+	var ridePickup models.RideResp
+	require.NoError(t, json.Unmarshal(msg.Data, &ridePickup))
+	return ridePickup
+}
@@ -6,10 +6,12 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
+	jwtpkg "github.com/piresc/nebengjek/internal/pkg/jwt"
 	"github.com/piresc/nebengjek/internal/pkg/middleware"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/piresc/nebengjek/services/users/handler/http"
 	"github.com/piresc/nebengjek/services/users/handler/nats"
+	"github.com/piresc/nebengjek/services/users/handler/sse"
 	"github.com/piresc/nebengjek/services/users/handler/websocket"
 )
 
@@ -18,6 +20,7 @@ type Handler struct {
 	userHandler   *http.UserHandler
 	authHandler   *http.AuthHandler
 	echoWSHandler *websocket.EchoWebSocketHandler
+	rideEvents    *sse.RideEventHandler
 	natsHandler   *nats.NatsHandler
 	cfg           *models.Config
 }
@@ -27,6 +30,7 @@ func NewHandler(
 	userHandler *http.UserHandler,
 	authHandler *http.AuthHandler,
 	echoWSHandler *websocket.EchoWebSocketHandler,
+	rideEvents *sse.RideEventHandler,
 	natsHandler *nats.NatsHandler,
 	cfg *models.Config,
 ) *Handler {
@@ -35,6 +39,7 @@ func NewHandler(
 		userHandler:   userHandler,
 		authHandler:   authHandler,
 		echoWSHandler: echoWSHandler,
+		rideEvents:    rideEvents,
 		natsHandler:   natsHandler,
 		cfg:           cfg,
 	}
@@ -43,24 +48,21 @@ func NewHandler(
 // GetJWTMiddleware returns the configured JWT middleware for HTTP requests
 func (h *Handler) GetJWTMiddleware() echo.MiddlewareFunc {
 	return echojwt.WithConfig(echojwt.Config{
-		SigningKey: []byte(h.cfg.JWT.Secret),
+		// Validate against the keyset so tokens signed with the previous secret survive a rotation
+		ParseTokenFunc: func(c echo.Context, auth string) (interface{}, error) {
+			return jwtpkg.ParseToken(auth, h.cfg.JWT)
+		},
 		SuccessHandler: func(c echo.Context) {
-			// Parse the token directly from Authorization header to avoid type conflicts
-			authHeader := c.Request().Header.Get("Authorization")
-			if authHeader != "" && len(authHeader) > 7 && authHeader[:7] == "Bearer " {
-				tokenString := authHeader[7:]
-				token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-					return []byte(h.cfg.JWT.Secret), nil
-				})
-				if err == nil && token.Valid {
-					if claims, ok := token.Claims.(jwt.MapClaims); ok {
-						if userID, exists := claims["user_id"]; exists {
-							c.Set("user_id", userID)
-						}
-						if role, exists := claims["role"]; exists {
-							c.Set("role", role)
-						}
-					}
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok {
+				return
+			}
+			if claims, ok := token.Claims.(jwt.MapClaims); ok {
+				if userID, exists := claims["user_id"]; exists {
+					c.Set("user_id", userID)
+				}
+				if role, exists := claims["role"]; exists {
+					c.Set("role", role)
 				}
 			}
 		},
@@ -82,9 +84,7 @@ func (h *Handler) GetWebSocketJWTMiddleware() echo.MiddlewareFunc {
 			}
 
 			tokenString := authHeader[7:]
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				return []byte(h.cfg.JWT.Secret), nil
-			})
+			token, err := jwtpkg.ParseToken(tokenString, h.cfg.JWT)
 
 			if err != nil || !token.Valid {
 				return echo.NewHTTPError(401, "Invalid token")
@@ -116,9 +116,13 @@ func (h *Handler) GetWebSocketJWTMiddleware() echo.MiddlewareFunc {
 
 // RegisterRoutes registers all protocol handlers and their routes
 func (h *Handler) RegisterRoutes(e *echo.Echo, Middleware *middleware.Middleware) {
+	// CORS for browser clients, applied ahead of auth so preflight requests are answered
+	e.Use(middleware.CORS(h.cfg.CORS))
+
 	// Public routes (no authentication required)
 	authGroup := e.Group("/auth")
-	authGroup.POST("/otp/generate", h.authHandler.GenerateOTP)
+	authGroup.POST("/otp/request", h.authHandler.GenerateOTP)
+	authGroup.POST("/otp/generate", h.authHandler.GenerateOTP) // Kept for existing clients
 	authGroup.POST("/otp/verify", h.authHandler.VerifyOTP)
 
 	// Protected routes with JWT middleware (user-facing)
@@ -129,9 +133,32 @@ func (h *Handler) RegisterRoutes(e *echo.Echo, Middleware *middleware.Middleware
 	userGroup.POST("", h.userHandler.CreateUser)
 	userGroup.GET("/:id", h.userHandler.GetUser)
 
+	// Favorite locations of the authenticated user
+	favoriteGroup := protected.Group("/favorites")
+	favoriteGroup.GET("", h.userHandler.ListFavoriteLocations)
+	favoriteGroup.POST("", h.userHandler.CreateFavoriteLocation)
+	favoriteGroup.PUT("/:id", h.userHandler.UpdateFavoriteLocation)
+	favoriteGroup.DELETE("/:id", h.userHandler.DeleteFavoriteLocation)
+
 	// Driver routes
 	driverGroup := protected.Group("/drivers")
 	driverGroup.POST("/register", h.userHandler.RegisterDriver)
+	driverGroup.GET("/:id/matches", h.userHandler.GetDriverMatches)
+
+	// Ride event stream, a Server-Sent Events alternative to the WebSocket notifications
+	protected.GET("/rides/:id/events", h.rideEvents.StreamRideEvents)
+
+	// Passengers fetch, or get a reissued, pickup code of their active ride
+	protected.POST("/rides/:id/pickup-code", h.userHandler.GetPickupCode)
+
+	// Admin routes (admin API key required)
+	adminGroup := e.Group("/admin", Middleware.APIKeyHandler("admin"))
+	adminGroup.GET("/users", h.userHandler.ListUsers)
+	adminGroup.GET("/drivers/:id", h.userHandler.GetDriverDocuments)
+	adminGroup.POST("/drivers/:id/verify", h.userHandler.VerifyDriver)
+	adminGroup.GET("/drivers/:id/online-time", h.userHandler.GetDriverOnlineTime)
+	adminGroup.GET("/users/:id/presence", h.echoWSHandler.GetUserPresence)
+	adminGroup.POST("/announcements", h.userHandler.Announce)
 
 	// WebSocket routes - use custom WebSocket JWT middleware
 	wsGroup := e.Group("/ws", h.GetWebSocketJWTMiddleware())
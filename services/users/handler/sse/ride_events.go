@@ -0,0 +1,196 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/users"
+)
+
+const (
+	// subscriberBuffer is how many events a slow client may fall behind before events are dropped
+	subscriberBuffer = 16
+	// defaultHeartbeatInterval keeps idle streams open through proxies that close silent connections
+	defaultHeartbeatInterval = 15 * time.Second
+	// streamWriteTimeout bounds each write to a stream, replacing the server's write timeout
+	streamWriteTimeout = 10 * time.Second
+)
+
+// rideEvent is a single ride lifecycle event queued for a stream
+type rideEvent struct {
+	name string
+	data []byte
+}
+
+// RideEventHandler streams ride lifecycle events to clients over Server-Sent Events.
+// It receives the same NATS-driven events that are pushed to WebSocket clients.
+type RideEventHandler struct {
+	userUC            users.UserUC
+	heartbeatInterval time.Duration
+
+	mu          sync.Mutex
+	subscribers map[string]map[chan rideEvent]struct{} // ride_id -> open streams
+}
+
+// NewRideEventHandler creates a new SSE ride event handler
+func NewRideEventHandler(userUC users.UserUC) *RideEventHandler {
+	return &RideEventHandler{
+		userUC:            userUC,
+		heartbeatInterval: defaultHeartbeatInterval,
+		subscribers:       make(map[string]map[chan rideEvent]struct{}),
+	}
+}
+
+// Publish forwards an event to every stream open on the ride.
+// A ride completion or cancellation is the last event of a ride, so its streams are closed after it is delivered.
+func (h *RideEventHandler) Publish(rideID string, event string, data interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	streams := h.subscribers[rideID]
+	if len(streams) == 0 {
+		return
+	}
+
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("Error marshaling ride event data",
+			logger.String("ride_id", rideID),
+			logger.String("event", event),
+			logger.ErrorField(err))
+		return
+	}
+
+	for ch := range streams {
+		// Never block the NATS consumer on a slow client
+		select {
+		case ch <- rideEvent{name: event, data: rawData}:
+		default:
+			logger.Warn("Dropping ride event for slow SSE client",
+				logger.String("ride_id", rideID),
+				logger.String("event", event))
+		}
+	}
+
+	if event == constants.EventRideCompleted || event == constants.EventRideCancelled {
+		for ch := range streams {
+			close(ch)
+		}
+		delete(h.subscribers, rideID)
+	}
+}
+
+// subscribe registers a new stream for the ride
+func (h *RideEventHandler) subscribe(rideID string) chan rideEvent {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	ch := make(chan rideEvent, subscriberBuffer)
+	if h.subscribers[rideID] == nil {
+		h.subscribers[rideID] = make(map[chan rideEvent]struct{})
+	}
+	h.subscribers[rideID][ch] = struct{}{}
+	return ch
+}
+
+// unsubscribe removes a stream whose client went away; streams closed by the ride ending are already gone
+func (h *RideEventHandler) unsubscribe(rideID string, ch chan rideEvent) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	streams, ok := h.subscribers[rideID]
+	if !ok {
+		return
+	}
+	if _, ok := streams[ch]; !ok {
+		return
+	}
+	delete(streams, ch)
+	if len(streams) == 0 {
+		delete(h.subscribers, rideID)
+	}
+}
+
+// StreamRideEvents streams the lifecycle events of a ride the caller participates in
+func (h *RideEventHandler) StreamRideEvents(c echo.Context) error {
+	userIDRaw := c.Get("user_id")
+	roleRaw := c.Get("role")
+	if userIDRaw == nil || roleRaw == nil {
+		return utils.UnauthorizedResponse(c, "Missing user credentials in token")
+	}
+	userID := fmt.Sprintf("%v", userIDRaw)
+	role := fmt.Sprintf("%v", roleRaw)
+
+	rideID := c.Param("id")
+	if rideID == "" {
+		return utils.BadRequestResponse(c, "Ride ID is required")
+	}
+
+	if err := h.userUC.CheckRideParticipant(c.Request().Context(), userID, role, rideID); err != nil {
+		if errors.Is(err, users.ErrNotRideParticipant) {
+			return utils.ForbiddenResponse(c, "You are not a participant of this ride")
+		}
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to verify ride participant")
+	}
+
+	ch := h.subscribe(rideID)
+	defer h.unsubscribe(rideID, ch)
+
+	res := c.Response()
+	res.Header().Set(echo.HeaderContentType, "text/event-stream")
+	res.Header().Set(echo.HeaderCacheControl, "no-cache")
+	res.Header().Set(echo.HeaderConnection, "keep-alive")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	res.Flush()
+
+	// The server's read and write timeouts suit plain requests and would cut the stream off, so the read
+	// deadline is cleared and each write gets its own. Writers without deadline support are left as they are.
+	rc := http.NewResponseController(res)
+	_ = rc.SetReadDeadline(time.Time{})
+	write := func(format string, args ...interface{}) error {
+		_ = rc.SetWriteDeadline(time.Now().Add(streamWriteTimeout))
+		if _, err := fmt.Fprintf(res, format, args...); err != nil {
+			return err
+		}
+		res.Flush()
+		return nil
+	}
+
+	logger.Info("SSE client subscribed to ride events",
+		logger.String("user_id", userID),
+		logger.String("ride_id", rideID))
+
+	heartbeat := time.NewTicker(h.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-c.Request().Context().Done():
+			logger.Info("SSE client disconnected",
+				logger.String("user_id", userID),
+				logger.String("ride_id", rideID))
+			return nil
+		case <-heartbeat.C:
+			if err := write(": keep-alive\n\n"); err != nil {
+				return nil
+			}
+		case event, ok := <-ch:
+			if !ok {
+				// The ride completed or was cancelled and the stream has nothing more to deliver
+				return nil
+			}
+			if err := write("event: %s\ndata: %s\n\n", event.name, event.data); err != nil {
+				return nil
+			}
+		}
+	}
+}
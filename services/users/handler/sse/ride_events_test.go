@@ -0,0 +1,209 @@
+package sse
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/services/users"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newStreamServer serves the ride event stream as an authenticated passenger
+func newStreamServer(h *RideEventHandler, userID string) *httptest.Server {
+	e := echo.New()
+	e.GET("/rides/:id/events", h.StreamRideEvents, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", userID)
+			c.Set("role", "passenger")
+			return next(c)
+		}
+	})
+	return httptest.NewServer(e)
+}
+
+// subscriberCount returns the number of open streams on a ride
+func (h *RideEventHandler) subscriberCount(rideID string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subscribers[rideID])
+}
+
+func TestStreamRideEvents_DeliversEventsUntilCompletion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	mockUC.EXPECT().CheckRideParticipant(gomock.Any(), "passenger-1", "passenger", "ride-1").Return(nil)
+
+	h := NewRideEventHandler(mockUC)
+	server := newStreamServer(h, "passenger-1")
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/rides/ride-1/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	// The stream is subscribed before its headers are sent, so these events reach it
+	h.Publish("ride-2", constants.EventRideStarted, map[string]string{"ride_id": "ride-2"})
+	h.Publish("ride-1", constants.EventRideStarted, map[string]string{"ride_id": "ride-1"})
+	h.Publish("ride-1", constants.EventRideCompleted, map[string]string{"ride_id": "ride-1"})
+
+	var lines []string
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	require.NoError(t, scanner.Err())
+
+	// Only the ride's own events are streamed, and the stream ends after completion
+	assert.Equal(t, []string{
+		"event: ride_started",
+		`data: {"ride_id":"ride-1"}`,
+		"",
+		"event: ride_completed",
+		`data: {"ride_id":"ride-1"}`,
+		"",
+	}, lines)
+	assert.Equal(t, 0, h.subscriberCount("ride-1"))
+}
+
+func TestStreamRideEvents_ClientDisconnect(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	mockUC.EXPECT().CheckRideParticipant(gomock.Any(), gomock.Any(), gomock.Any(), "ride-1").Return(nil)
+
+	h := NewRideEventHandler(mockUC)
+	server := newStreamServer(h, "passenger-1")
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/rides/ride-1/events", nil)
+	require.NoError(t, err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, 1, h.subscriberCount("ride-1"))
+
+	cancel()
+
+	// The stream is released once the server notices the client went away
+	assert.Eventually(t, func() bool {
+		return h.subscriberCount("ride-1") == 0
+	}, time.Second, 10*time.Millisecond)
+
+	// Later events for the ride are not delivered anywhere
+	h.Publish("ride-1", constants.EventRideStarted, map[string]string{"ride_id": "ride-1"})
+}
+
+func TestStreamRideEvents_Heartbeat(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	mockUC.EXPECT().CheckRideParticipant(gomock.Any(), gomock.Any(), gomock.Any(), "ride-1").Return(nil)
+
+	h := NewRideEventHandler(mockUC)
+	h.heartbeatInterval = 10 * time.Millisecond
+	server := newStreamServer(h, "passenger-1")
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/rides/ride-1/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": keep-alive", strings.TrimSpace(line))
+}
+
+func TestStreamRideEvents_Rejected(t *testing.T) {
+	tests := []struct {
+		name           string
+		checkErr       error
+		expectedStatus int
+	}{
+		{
+			name:           "Not a participant",
+			checkErr:       users.ErrNotRideParticipant,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Participant lookup fails",
+			checkErr:       errors.New("redis down"),
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUC := mocks.NewMockUserUC(ctrl)
+			mockUC.EXPECT().CheckRideParticipant(gomock.Any(), "passenger-1", "passenger", "ride-1").Return(tt.checkErr)
+
+			h := NewRideEventHandler(mockUC)
+			server := newStreamServer(h, "passenger-1")
+			defer server.Close()
+
+			resp, err := http.Get(server.URL + "/rides/ride-1/events")
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.expectedStatus, resp.StatusCode)
+			assert.Equal(t, 0, h.subscriberCount("ride-1"))
+		})
+	}
+}
+
+func TestStreamRideEvents_OutlivesServerTimeouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUC := mocks.NewMockUserUC(ctrl)
+	mockUC.EXPECT().CheckRideParticipant(gomock.Any(), "passenger-1", "passenger", "ride-1").Return(nil)
+
+	h := NewRideEventHandler(mockUC)
+	e := echo.New()
+	e.GET("/rides/:id/events", h.StreamRideEvents, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", "passenger-1")
+			c.Set("role", "passenger")
+			return next(c)
+		}
+	})
+	server := httptest.NewUnstartedServer(e)
+	server.Config.ReadTimeout = 200 * time.Millisecond
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/rides/ride-1/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	// Well past both server timeouts the stream still delivers
+	time.Sleep(500 * time.Millisecond)
+	h.Publish("ride-1", constants.EventRideCompleted, map[string]string{"ride_id": "ride-1"})
+
+	scanner := bufio.NewScanner(resp.Body)
+	require.True(t, scanner.Scan())
+	assert.Equal(t, "event: ride_completed", scanner.Text())
+}
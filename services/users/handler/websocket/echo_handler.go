@@ -3,10 +3,14 @@ package websocket
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/constants"
@@ -16,21 +20,52 @@ import (
 	"golang.org/x/net/websocket"
 )
 
+// maxChatMessageLength caps a single chat message relayed between ride participants
+const maxChatMessageLength = 500
+
+const (
+	// broadcastWorkers bounds how many connections a broadcast writes to at once
+	broadcastWorkers = 16
+	// broadcastWriteTimeout bounds how long a slow connection can hold up a broadcast worker
+	broadcastWriteTimeout = 5 * time.Second
+	// readIdleTimeout closes connections that send no frame for this long
+	readIdleTimeout = 10 * time.Minute
+)
+
+// wsClient is a connected user and the role they authenticated with
+type wsClient struct {
+	conn *websocket.Conn
+	role string
+}
+
+// RideEventPublisher forwards ride lifecycle events to the clients following a ride without a
+// WebSocket, such as the SSE ride event streams
+type RideEventPublisher interface {
+	Publish(rideID string, event string, data interface{})
+}
+
 // EchoWebSocketHandler handles websocket connections using Echo's native support
 type EchoWebSocketHandler struct {
-	userUC  users.UserUC
-	clients map[string]*websocket.Conn
-	mu      sync.RWMutex
+	userUC     users.UserUC
+	rideEvents RideEventPublisher
+	clients    map[string]wsClient
+	mu         sync.RWMutex
 }
 
 // NewEchoWebSocketHandler creates a new Echo-based websocket handler
 func NewEchoWebSocketHandler(userUC users.UserUC) *EchoWebSocketHandler {
 	return &EchoWebSocketHandler{
 		userUC:  userUC,
-		clients: make(map[string]*websocket.Conn),
+		clients: make(map[string]wsClient),
 	}
 }
 
+// SetRideEventPublisher forwards the ride events produced by WebSocket commands, such as the
+// payment request raised when the driver arrives, to publisher as well
+func (h *EchoWebSocketHandler) SetRideEventPublisher(publisher RideEventPublisher) {
+	h.rideEvents = publisher
+}
+
 // HandleWebSocket handles websocket connections using Echo's native websocket support
 func (h *EchoWebSocketHandler) HandleWebSocket(c echo.Context) error {
 	// Extract user info from JWT token (already validated by middleware)
@@ -58,9 +93,13 @@ func (h *EchoWebSocketHandler) HandleWebSocket(c echo.Context) error {
 		Handler: func(ws *websocket.Conn) {
 			defer ws.Close()
 
+			// The server's read and write timeouts must not cut the socket off after the upgrade. Writes run
+			// without a deadline, and the read deadline is pushed back per frame so silent sockets still close.
+			ws.SetWriteDeadline(time.Time{})
+
 			// Register client
-			h.addClient(userID, ws)
-			defer h.removeClient(userID)
+			h.addClient(userID, role, ws)
+			defer h.removeClient(userID, ws)
 
 			logger.Info("WebSocket client connected",
 				logger.String("user_id", userID),
@@ -68,8 +107,9 @@ func (h *EchoWebSocketHandler) HandleWebSocket(c echo.Context) error {
 
 			// Message handling loop
 			for {
-				var msg models.WSMessage
-				if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				var frame []byte
+				ws.SetReadDeadline(time.Now().Add(readIdleTimeout))
+				if err := websocket.Message.Receive(ws, &frame); err != nil {
 					if err == io.EOF {
 						logger.Info("WebSocket client disconnected",
 							logger.String("user_id", userID))
@@ -81,7 +121,14 @@ func (h *EchoWebSocketHandler) HandleWebSocket(c echo.Context) error {
 					break
 				}
 
-				if err := h.handleMessage(userID, role, ws, &msg); err != nil {
+				// A malformed frame is reported to the client without dropping the connection
+				var msg models.WSMessage
+				if err := json.Unmarshal(frame, &msg); err != nil {
+					h.sendError(ws, userID, errors.New("message must be a JSON object with event and data"), constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
+					continue
+				}
+
+				if err := h.dispatchMessage(userID, role, ws, &msg); err != nil {
 					logger.Error("Error handling message",
 						logger.String("user_id", userID),
 						logger.String("event", msg.Event),
@@ -101,23 +148,26 @@ func (h *EchoWebSocketHandler) HandleWebSocket(c echo.Context) error {
 }
 
 // addClient safely adds a client to the manager
-func (h *EchoWebSocketHandler) addClient(userID string, ws *websocket.Conn) {
+func (h *EchoWebSocketHandler) addClient(userID, role string, ws *websocket.Conn) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	h.clients[userID] = ws
+	h.clients[userID] = wsClient{conn: ws, role: role}
 }
 
-// removeClient safely removes a client from the manager
-func (h *EchoWebSocketHandler) removeClient(userID string) {
+// removeClient safely removes a client from the manager. Only ws itself is removed, so a read loop
+// ending after its user reconnected leaves the newer connection registered.
+func (h *EchoWebSocketHandler) removeClient(userID string, ws *websocket.Conn) {
 	h.mu.Lock()
 	defer h.mu.Unlock()
-	delete(h.clients, userID)
+	if h.clients[userID].conn == ws {
+		delete(h.clients, userID)
+	}
 }
 
 // NotifyClient sends a notification to a specific client
 func (h *EchoWebSocketHandler) NotifyClient(userID string, event string, data interface{}) {
 	h.mu.RLock()
-	ws, exists := h.clients[userID]
+	client, exists := h.clients[userID]
 	h.mu.RUnlock()
 
 	if !exists {
@@ -138,7 +188,7 @@ func (h *EchoWebSocketHandler) NotifyClient(userID string, event string, data in
 		Data:  rawData,
 	}
 
-	if err := websocket.JSON.Send(ws, response); err != nil {
+	if err := websocket.JSON.Send(client.conn, response); err != nil {
 		logger.Warn("Error sending message to client",
 			logger.String("user_id", userID),
 			logger.String("event", event),
@@ -146,6 +196,78 @@ func (h *EchoWebSocketHandler) NotifyClient(userID string, event string, data in
 	}
 }
 
+// Broadcast sends an event to every connected client, or only to clients with one of the given
+// roles. It returns how many clients the event is sent to and writes to them in the background,
+// so a large fan-out never blocks the caller.
+func (h *EchoWebSocketHandler) Broadcast(event string, data interface{}, roles ...string) int {
+	rawData, err := json.Marshal(data)
+	if err != nil {
+		logger.Error("Error marshaling broadcast data",
+			logger.String("event", event),
+			logger.ErrorField(err))
+		return 0
+	}
+	frame, err := json.Marshal(models.WSMessage{Event: event, Data: rawData})
+	if err != nil {
+		logger.Error("Error marshaling broadcast message",
+			logger.String("event", event),
+			logger.ErrorField(err))
+		return 0
+	}
+
+	h.mu.RLock()
+	recipients := make(map[string]*websocket.Conn, len(h.clients))
+	for userID, client := range h.clients {
+		if len(roles) == 0 || slices.Contains(roles, client.role) {
+			recipients[userID] = client.conn
+		}
+	}
+	h.mu.RUnlock()
+
+	go h.deliverBroadcast(event, string(frame), recipients)
+	return len(recipients)
+}
+
+// deliverBroadcast writes a broadcast frame to the recipients with a bounded number of workers.
+// Each write has a deadline so a stalled connection only delays the clients behind it.
+func (h *EchoWebSocketHandler) deliverBroadcast(event, frame string, recipients map[string]*websocket.Conn) {
+	type recipient struct {
+		userID string
+		conn   *websocket.Conn
+	}
+	queue := make(chan recipient)
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	for i := 0; i < min(broadcastWorkers, len(recipients)); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for r := range queue {
+				r.conn.SetWriteDeadline(time.Now().Add(broadcastWriteTimeout))
+				err := websocket.Message.Send(r.conn, frame)
+				r.conn.SetWriteDeadline(time.Time{})
+				if err != nil {
+					failed.Add(1)
+					logger.Warn("Error sending broadcast to client",
+						logger.String("user_id", r.userID),
+						logger.String("event", event),
+						logger.ErrorField(err))
+				}
+			}
+		}()
+	}
+	for userID, conn := range recipients {
+		queue <- recipient{userID: userID, conn: conn}
+	}
+	close(queue)
+	wg.Wait()
+
+	logger.Info("Broadcast delivered",
+		logger.String("event", event),
+		logger.Int("recipients", len(recipients)),
+		logger.Int64("failed", failed.Load()))
+}
+
 // sendError sends an error message to the client
 func (h *EchoWebSocketHandler) sendError(ws *websocket.Conn, userID string, err error, code string, severity constants.ErrorSeverity) {
 	// Always log detailed error server-side
@@ -172,9 +294,10 @@ func (h *EchoWebSocketHandler) sendError(ws *websocket.Conn, userID string, err
 		message = "Operation failed"
 	}
 
+	errorData, _ := json.Marshal(models.WSErrorMessage{Code: code, Message: message})
 	errorResponse := models.WSMessage{
 		Event: constants.EventError,
-		Data:  json.RawMessage(fmt.Sprintf(`{"code":"%s","message":"%s"}`, code, message)),
+		Data:  errorData,
 	}
 
 	if err := websocket.JSON.Send(ws, errorResponse); err != nil {
@@ -198,6 +321,28 @@ func (h *EchoWebSocketHandler) getSeverityString(severity constants.ErrorSeverit
 	}
 }
 
+// dispatchMessage handles a message, recovering from handler panics so one bad command cannot end the read loop
+func (h *EchoWebSocketHandler) dispatchMessage(userID, role string, ws *websocket.Conn, msg *models.WSMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			h.sendError(ws, userID, fmt.Errorf("panic handling %s: %v", msg.Event, r), constants.ErrorSystemUnavailable, constants.ErrorSeverityServer)
+			err = nil
+		}
+	}()
+	return h.handleMessage(userID, role, ws, msg)
+}
+
+// decodeCommand unmarshals a command payload and validates it, returning an error suitable for the client
+func decodeCommand(data json.RawMessage, cmd models.WSCommand) error {
+	if len(data) == 0 || string(data) == "null" {
+		return errors.New("data is required")
+	}
+	if err := json.Unmarshal(data, cmd); err != nil {
+		return fmt.Errorf("invalid data: %w", err)
+	}
+	return cmd.Validate()
+}
+
 // handleMessage processes incoming WebSocket messages with preserved business logic
 func (h *EchoWebSocketHandler) handleMessage(userID, role string, ws *websocket.Conn, msg *models.WSMessage) error {
 	switch msg.Event {
@@ -215,6 +360,10 @@ func (h *EchoWebSocketHandler) handleMessage(userID, role string, ws *websocket.
 		return h.handleRideArrived(userID, ws, msg.Data)
 	case constants.EventPaymentProcessed:
 		return h.handleProcessPayment(userID, ws, msg.Data)
+	case constants.EventChatMessage:
+		return h.handleChatMessage(userID, role, ws, msg.Data)
+	case constants.EventChatHistory:
+		return h.handleChatHistory(userID, role, ws, msg.Data)
 	default:
 		unknownEventErr := fmt.Errorf("unknown event type: %s", msg.Event)
 		h.sendError(ws, userID, unknownEventErr, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
@@ -227,7 +376,7 @@ func (h *EchoWebSocketHandler) handleMessage(userID, role string, ws *websocket.
 // handleBeaconUpdate processes beacon status updates
 func (h *EchoWebSocketHandler) handleBeaconUpdate(userID string, ws *websocket.Conn, data json.RawMessage) error {
 	var req models.BeaconRequest
-	if err := json.Unmarshal(data, &req); err != nil {
+	if err := decodeCommand(data, &req); err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
 		return nil
 	}
@@ -249,7 +398,7 @@ func (h *EchoWebSocketHandler) handleBeaconUpdate(userID string, ws *websocket.C
 // handleFinderUpdate processes finder status updates
 func (h *EchoWebSocketHandler) handleFinderUpdate(userID string, ws *websocket.Conn, data json.RawMessage) error {
 	var req models.FinderRequest
-	if err := json.Unmarshal(data, &req); err != nil {
+	if err := decodeCommand(data, &req); err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
 		return nil
 	}
@@ -271,7 +420,7 @@ func (h *EchoWebSocketHandler) handleFinderUpdate(userID string, ws *websocket.C
 // handleMatchConfirmation processes match confirmation with dual notification
 func (h *EchoWebSocketHandler) handleMatchConfirmation(userID string, ws *websocket.Conn, data json.RawMessage) error {
 	var req models.MatchConfirmRequest
-	if err := json.Unmarshal(data, &req); err != nil {
+	if err := decodeCommand(data, &req); err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
 		return nil
 	}
@@ -295,7 +444,7 @@ func (h *EchoWebSocketHandler) handleMatchConfirmation(userID string, ws *websoc
 // handleLocationUpdate processes location updates with timestamp addition
 func (h *EchoWebSocketHandler) handleLocationUpdate(userID string, ws *websocket.Conn, data json.RawMessage) error {
 	var req models.LocationUpdate
-	if err := json.Unmarshal(data, &req); err != nil {
+	if err := decodeCommand(data, &req); err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
 		return nil
 	}
@@ -316,7 +465,7 @@ func (h *EchoWebSocketHandler) handleLocationUpdate(userID string, ws *websocket
 // handleRideStart processes ride start with dual notification
 func (h *EchoWebSocketHandler) handleRideStart(userID string, ws *websocket.Conn, data json.RawMessage) error {
 	var req models.RideStartRequest
-	if err := json.Unmarshal(data, &req); err != nil {
+	if err := decodeCommand(data, &req); err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
 		return nil
 	}
@@ -337,7 +486,7 @@ func (h *EchoWebSocketHandler) handleRideStart(userID string, ws *websocket.Conn
 // handleRideArrived processes ride arrival with event type transformation
 func (h *EchoWebSocketHandler) handleRideArrived(userID string, ws *websocket.Conn, data json.RawMessage) error {
 	var req models.RideArrivalReq
-	if err := json.Unmarshal(data, &req); err != nil {
+	if err := decodeCommand(data, &req); err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
 		return nil
 	}
@@ -350,6 +499,9 @@ func (h *EchoWebSocketHandler) handleRideArrived(userID string, ws *websocket.Co
 
 	// Critical: Event type transformation (arrival → payment request)
 	h.NotifyClient(paymentReq.PassengerID, constants.EventPaymentRequest, paymentReq)
+	if h.rideEvents != nil {
+		h.rideEvents.Publish(paymentReq.RideID, constants.EventPaymentRequest, paymentReq)
+	}
 
 	return nil
 }
@@ -357,18 +509,11 @@ func (h *EchoWebSocketHandler) handleRideArrived(userID string, ws *websocket.Co
 // handleProcessPayment processes payment with status validation
 func (h *EchoWebSocketHandler) handleProcessPayment(userID string, ws *websocket.Conn, data json.RawMessage) error {
 	var req models.PaymentProccessRequest
-	if err := json.Unmarshal(data, &req); err != nil {
+	if err := decodeCommand(data, &req); err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
 		return nil
 	}
 
-	// Critical: Payment status validation
-	if req.Status != models.PaymentStatusAccepted && req.Status != models.PaymentStatusRejected {
-		validationErr := fmt.Errorf("invalid payment status: %s", req.Status)
-		h.sendError(ws, userID, validationErr, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
-		return nil
-	}
-
 	payment, err := h.userUC.ProcessPayment(context.Background(), &req)
 	if err != nil {
 		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityServer)
@@ -384,3 +529,69 @@ func (h *EchoWebSocketHandler) handleProcessPayment(userID string, ws *websocket
 
 	return websocket.JSON.Send(ws, response)
 }
+
+// handleChatMessage relays a chat message to the other participant of the sender's active ride
+func (h *EchoWebSocketHandler) handleChatMessage(userID, role string, ws *websocket.Conn, data json.RawMessage) error {
+	var req models.ChatMessage
+	if err := json.Unmarshal(data, &req); err != nil {
+		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
+		return nil
+	}
+
+	// Critical: Chat message validation; the limit counts characters, not bytes
+	message, err := models.SanitizeText("message", req.Message, maxChatMessageLength)
+	if err != nil {
+		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
+		return nil
+	}
+	req.Message = message
+	if req.RideID == "" || req.RecipientID == "" || req.Message == "" {
+		validationErr := fmt.Errorf("ride_id, recipient_id and message are required")
+		h.sendError(ws, userID, validationErr, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
+		return nil
+	}
+
+	msg, err := h.userUC.SendChatMessage(context.Background(), userID, role, &req)
+	if err != nil {
+		h.sendChatError(ws, userID, err)
+		return nil
+	}
+
+	// Deliver to the recipient and acknowledge to the sender with the stored timestamp
+	h.NotifyClient(msg.RecipientID, constants.EventChatMessage, msg)
+	h.NotifyClient(userID, constants.EventChatMessage, msg)
+
+	return nil
+}
+
+// handleChatHistory sends the recent chat messages of the user's active ride
+func (h *EchoWebSocketHandler) handleChatHistory(userID, role string, ws *websocket.Conn, data json.RawMessage) error {
+	var req models.ChatHistoryRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		h.sendError(ws, userID, err, constants.ErrorInvalidFormat, constants.ErrorSeverityClient)
+		return nil
+	}
+
+	messages, err := h.userUC.GetChatHistory(context.Background(), userID, role, req.RideID)
+	if err != nil {
+		h.sendChatError(ws, userID, err)
+		return nil
+	}
+
+	historyData, _ := json.Marshal(messages)
+	response := models.WSMessage{
+		Event: constants.EventChatHistory,
+		Data:  historyData,
+	}
+
+	return websocket.JSON.Send(ws, response)
+}
+
+// sendChatError reports non-participants as access denied and hides other failures
+func (h *EchoWebSocketHandler) sendChatError(ws *websocket.Conn, userID string, err error) {
+	if errors.Is(err, users.ErrNotRideParticipant) {
+		h.sendError(ws, userID, err, constants.ErrorAccessDenied, constants.ErrorSeveritySecurity)
+		return
+	}
+	h.sendError(ws, userID, err, constants.ErrorSystemUnavailable, constants.ErrorSeverityServer)
+}
@@ -1,17 +1,21 @@
 package websocket
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/labstack/echo/v4"
 	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 	"github.com/piresc/nebengjek/services/users/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -136,7 +140,7 @@ func TestEchoWebSocketHandler_AddAndRemoveClient(t *testing.T) {
 	defer ws.Close()
 
 	// Act - Add client
-	handler.addClient(userID, ws)
+	handler.addClient(userID, "passenger", ws)
 
 	// Assert - Client added
 	handler.mu.RLock()
@@ -145,7 +149,7 @@ func TestEchoWebSocketHandler_AddAndRemoveClient(t *testing.T) {
 	assert.True(t, exists)
 
 	// Act - Remove client
-	handler.removeClient(userID)
+	handler.removeClient(userID, ws)
 
 	// Assert - Client removed
 	handler.mu.RLock()
@@ -154,6 +158,88 @@ func TestEchoWebSocketHandler_AddAndRemoveClient(t *testing.T) {
 	assert.False(t, exists)
 }
 
+func TestEchoWebSocketHandler_RemoveClient_KeepsNewerConnection(t *testing.T) {
+	handler := NewEchoWebSocketHandler(nil)
+	userID := uuid.New().String()
+
+	oldWS, _ := dialRecordingClient(t)
+	newWS, received := dialRecordingClient(t)
+
+	// The user reconnects before the old read loop has finished
+	handler.addClient(userID, "passenger", oldWS)
+	handler.addClient(userID, "passenger", newWS)
+	handler.removeClient(userID, oldWS)
+
+	handler.NotifyClient(userID, constants.EventMatchConfirm, map[string]string{"match_id": "456"})
+
+	select {
+	case msg := <-received:
+		assert.Equal(t, constants.EventMatchConfirm, msg.Event)
+	case <-time.After(2 * time.Second):
+		t.Fatal("notification did not reach the newer connection")
+	}
+}
+
+func TestEchoWebSocketHandler_ConcurrentRegistryAccess(t *testing.T) {
+	handler := NewEchoWebSocketHandler(nil)
+
+	// Connections register, receive notifications and disconnect at once, as read loops
+	// and NATS consumers do under load; run with -race to check the registry is guarded
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+		var frame []byte
+		for websocket.Message.Receive(ws, &frame) == nil {
+		}
+	}))
+	defer server.Close()
+
+	const users = 8
+	conns := make([]*websocket.Conn, users)
+	for i := range conns {
+		ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", "http://localhost/")
+		require.NoError(t, err)
+		defer ws.Close()
+		conns[i] = ws
+	}
+	userIDs := make([]string, users)
+	for i := range userIDs {
+		userIDs[i] = uuid.New().String()
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < users; i++ {
+		userID, ws := userIDs[i], conns[i]
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				handler.addClient(userID, "passenger", ws)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				handler.NotifyClient(userID, constants.EventMatchConfirm, map[string]int{"seq": j})
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				handler.removeClient(userID, ws)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for i, userID := range userIDs {
+		handler.removeClient(userID, conns[i])
+	}
+
+	handler.mu.RLock()
+	defer handler.mu.RUnlock()
+	assert.Empty(t, handler.clients)
+}
+
 func TestEchoWebSocketHandler_NotifyClient_Success(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -201,6 +287,70 @@ func TestEchoWebSocketHandler_NotifyClient_ClientNotFound(t *testing.T) {
 	})
 }
 
+func TestEchoWebSocketHandler_Broadcast_ReachesAllClients(t *testing.T) {
+	handler := NewEchoWebSocketHandler(nil)
+
+	// More clients than broadcast workers, so every worker handles several connections
+	const clients = 3 * broadcastWorkers
+	inboxes := make([]<-chan models.WSMessage, clients)
+	for i := range inboxes {
+		ws, inbox := dialRecordingClient(t)
+		role := "passenger"
+		if i%2 == 0 {
+			role = "driver"
+		}
+		handler.addClient(uuid.New().String(), role, ws)
+		inboxes[i] = inbox
+	}
+
+	announcement := models.Announcement{ID: uuid.New().String(), Message: "Service resuming"}
+	recipients := handler.Broadcast(constants.EventAnnouncement, announcement)
+
+	assert.Equal(t, clients, recipients)
+	for _, inbox := range inboxes {
+		select {
+		case msg := <-inbox:
+			assert.Equal(t, constants.EventAnnouncement, msg.Event)
+			var received models.Announcement
+			require.NoError(t, json.Unmarshal(msg.Data, &received))
+			assert.Equal(t, announcement.ID, received.ID)
+			assert.Equal(t, "Service resuming", received.Message)
+		case <-time.After(2 * time.Second):
+			t.Fatal("announcement did not reach every client")
+		}
+	}
+}
+
+func TestEchoWebSocketHandler_Broadcast_RoleFilter(t *testing.T) {
+	handler := NewEchoWebSocketHandler(nil)
+
+	driverWS, driverInbox := dialRecordingClient(t)
+	passengerWS, passengerInbox := dialRecordingClient(t)
+	handler.addClient(uuid.New().String(), "driver", driverWS)
+	handler.addClient(uuid.New().String(), "passenger", passengerWS)
+
+	recipients := handler.Broadcast(constants.EventAnnouncement, models.Announcement{Message: "Drivers only"}, "driver")
+
+	assert.Equal(t, 1, recipients)
+	select {
+	case msg := <-driverInbox:
+		assert.Equal(t, constants.EventAnnouncement, msg.Event)
+	case <-time.After(2 * time.Second):
+		t.Fatal("announcement did not reach the driver")
+	}
+	select {
+	case <-passengerInbox:
+		t.Fatal("announcement delivered to a passenger outside the role filter")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEchoWebSocketHandler_Broadcast_NoClients(t *testing.T) {
+	handler := NewEchoWebSocketHandler(nil)
+
+	assert.Zero(t, handler.Broadcast(constants.EventAnnouncement, models.Announcement{Message: "Anyone there?"}))
+}
+
 func TestEchoWebSocketHandler_HandleMessage_LocationUpdate(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -224,9 +374,11 @@ func TestEchoWebSocketHandler_HandleMessage_LocationUpdate(t *testing.T) {
 	defer ws.Close()
 
 	data := map[string]interface{}{
-		"latitude":  -6.2088,
-		"longitude": 106.8456,
-		"is_active": true,
+		"ride_id": uuid.New().String(),
+		"location": map[string]interface{}{
+			"latitude":  -6.2088,
+			"longitude": 106.8456,
+		},
 	}
 	dataBytes, _ := json.Marshal(data)
 	msg := &models.WSMessage{
@@ -269,9 +421,16 @@ func TestEchoWebSocketHandler_HandleMessage_FinderStatusUpdate(t *testing.T) {
 	defer ws.Close()
 
 	data := map[string]interface{}{
-		"latitude":   -6.2088,
-		"longitude":  106.8456,
-		"is_finding": true,
+		"msisdn":    "+6281234567890",
+		"is_active": true,
+		"location": map[string]interface{}{
+			"latitude":  -6.2088,
+			"longitude": 106.8456,
+		},
+		"target_location": map[string]interface{}{
+			"latitude":  -6.1751,
+			"longitude": 106.8650,
+		},
 	}
 	dataBytes, _ := json.Marshal(data)
 	msg := &models.WSMessage{
@@ -326,4 +485,486 @@ func TestEchoWebSocketHandler_HandleMessage_UnknownEvent(t *testing.T) {
 
 	// Assert - handleMessage returns nil for unknown events (doesn't break connection)
 	assert.NoError(t, err)
-}
\ No newline at end of file
+}
+// dialRecordingClient opens a websocket whose server side forwards every received message to the returned channel
+func dialRecordingClient(t *testing.T) (*websocket.Conn, <-chan models.WSMessage) {
+	received := make(chan models.WSMessage, 10)
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		defer ws.Close()
+		for {
+			var msg models.WSMessage
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+			received <- msg
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http")
+	ws, err := websocket.Dial(wsURL, "", "http://localhost/")
+	require.NoError(t, err)
+	t.Cleanup(func() { ws.Close() })
+
+	return ws, received
+}
+
+func TestEchoWebSocketHandler_HandleMessage_ChatDeliveredToPassenger(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	handler := NewEchoWebSocketHandler(mockUserUC)
+
+	driverID := uuid.New().String()
+	passengerID := uuid.New().String()
+	otherID := uuid.New().String()
+	rideID := uuid.New().String()
+
+	driverWS, driverInbox := dialRecordingClient(t)
+	passengerWS, passengerInbox := dialRecordingClient(t)
+	otherWS, otherInbox := dialRecordingClient(t)
+	handler.addClient(driverID, "driver", driverWS)
+	handler.addClient(passengerID, "passenger", passengerWS)
+	handler.addClient(otherID, "passenger", otherWS)
+
+	dataBytes, _ := json.Marshal(models.ChatMessage{
+		RideID:      rideID,
+		RecipientID: passengerID,
+		Message:     " I'm at the lobby ",
+	})
+	msg := &models.WSMessage{
+		Event: constants.EventChatMessage,
+		Data:  json.RawMessage(dataBytes),
+	}
+
+	mockUserUC.EXPECT().
+		SendChatMessage(gomock.Any(), driverID, "driver", gomock.Any()).
+		DoAndReturn(func(_ interface{}, senderID, _ string, req *models.ChatMessage) (*models.ChatMessage, error) {
+			assert.Equal(t, "I'm at the lobby", req.Message)
+			req.SenderID = senderID
+			return req, nil
+		})
+
+	// Act
+	err := handler.handleMessage(driverID, "driver", driverWS, msg)
+
+	// Assert
+	assert.NoError(t, err)
+
+	for _, inbox := range []<-chan models.WSMessage{passengerInbox, driverInbox} {
+		select {
+		case delivered := <-inbox:
+			assert.Equal(t, constants.EventChatMessage, delivered.Event)
+			var chat models.ChatMessage
+			require.NoError(t, json.Unmarshal(delivered.Data, &chat))
+			assert.Equal(t, driverID, chat.SenderID)
+			assert.Equal(t, passengerID, chat.RecipientID)
+			assert.Equal(t, "I'm at the lobby", chat.Message)
+		case <-time.After(time.Second):
+			t.Fatal("chat message was not delivered")
+		}
+	}
+
+	select {
+	case <-otherInbox:
+		t.Fatal("chat message delivered to a user outside the ride")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEchoWebSocketHandler_HandleMessage_ChatRejectedForNonParticipant(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	handler := NewEchoWebSocketHandler(mockUserUC)
+
+	senderID := uuid.New().String()
+	passengerID := uuid.New().String()
+
+	senderWS, senderInbox := dialRecordingClient(t)
+	passengerWS, passengerInbox := dialRecordingClient(t)
+	handler.addClient(senderID, "driver", senderWS)
+	handler.addClient(passengerID, "passenger", passengerWS)
+
+	dataBytes, _ := json.Marshal(models.ChatMessage{
+		RideID:      uuid.New().String(),
+		RecipientID: passengerID,
+		Message:     "hello",
+	})
+	msg := &models.WSMessage{
+		Event: constants.EventChatMessage,
+		Data:  json.RawMessage(dataBytes),
+	}
+
+	mockUserUC.EXPECT().
+		SendChatMessage(gomock.Any(), senderID, "driver", gomock.Any()).
+		Return(nil, users.ErrNotRideParticipant)
+
+	// Act
+	err := handler.handleMessage(senderID, "driver", senderWS, msg)
+
+	// Assert
+	assert.NoError(t, err)
+
+	select {
+	case resp := <-senderInbox:
+		assert.Equal(t, constants.EventError, resp.Event)
+		var wsErr models.WSErrorMessage
+		require.NoError(t, json.Unmarshal(resp.Data, &wsErr))
+		assert.Equal(t, constants.ErrorAccessDenied, wsErr.Code)
+	case <-time.After(time.Second):
+		t.Fatal("sender did not receive an error")
+	}
+
+	select {
+	case <-passengerInbox:
+		t.Fatal("rejected chat message was delivered")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestEchoWebSocketHandler_HandleMessage_ChatEmptyMessage(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	handler := NewEchoWebSocketHandler(mockUserUC)
+
+	senderID := uuid.New().String()
+	senderWS, senderInbox := dialRecordingClient(t)
+
+	dataBytes, _ := json.Marshal(models.ChatMessage{
+		RideID:      uuid.New().String(),
+		RecipientID: uuid.New().String(),
+		Message:     "   ",
+	})
+	msg := &models.WSMessage{
+		Event: constants.EventChatMessage,
+		Data:  json.RawMessage(dataBytes),
+	}
+
+	// No usecase call expected for invalid messages
+
+	// Act
+	err := handler.handleMessage(senderID, "passenger", senderWS, msg)
+
+	// Assert
+	assert.NoError(t, err)
+	select {
+	case resp := <-senderInbox:
+		assert.Equal(t, constants.EventError, resp.Event)
+	case <-time.After(time.Second):
+		t.Fatal("sender did not receive a validation error")
+	}
+}
+
+func TestEchoWebSocketHandler_HandleMessage_ChatLimitCountsCharacters(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	handler := NewEchoWebSocketHandler(mockUserUC)
+
+	senderID := uuid.New().String()
+	senderWS, _ := dialRecordingClient(t)
+
+	// Multi-byte characters take the message past the limit in bytes but not in characters
+	message := strings.Repeat("é", maxChatMessageLength)
+	dataBytes, _ := json.Marshal(models.ChatMessage{
+		RideID:      uuid.New().String(),
+		RecipientID: uuid.New().String(),
+		Message:     message,
+	})
+	msg := &models.WSMessage{
+		Event: constants.EventChatMessage,
+		Data:  json.RawMessage(dataBytes),
+	}
+
+	mockUserUC.EXPECT().
+		SendChatMessage(gomock.Any(), senderID, "passenger", gomock.Any()).
+		DoAndReturn(func(_ interface{}, senderID, _ string, req *models.ChatMessage) (*models.ChatMessage, error) {
+			assert.Equal(t, message, req.Message)
+			return req, nil
+		})
+
+	// Act
+	err := handler.handleMessage(senderID, "passenger", senderWS, msg)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+// recordingRideEvents records the ride events forwarded to it
+type recordingRideEvents struct {
+	events []string
+}
+
+func (r *recordingRideEvents) Publish(rideID string, event string, data interface{}) {
+	r.events = append(r.events, rideID+":"+event)
+}
+
+func TestEchoWebSocketHandler_HandleMessage_RideArrivedForwardsPaymentRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	handler := NewEchoWebSocketHandler(mockUserUC)
+	rideEvents := &recordingRideEvents{}
+	handler.SetRideEventPublisher(rideEvents)
+
+	driverID := uuid.New().String()
+	driverWS, _ := dialRecordingClient(t)
+	rideID := uuid.New().String()
+
+	mockUserUC.EXPECT().RideArrived(gomock.Any(), gomock.Any()).
+		Return(&models.PaymentRequest{RideID: rideID, PassengerID: uuid.New().String(), TotalCost: 15000}, nil)
+
+	err := handler.handleMessage(driverID, "driver", driverWS, &models.WSMessage{
+		Event: constants.EventRideArrived,
+		Data:  json.RawMessage(`{"ride_id":"` + rideID + `","adjustment_factor":1}`),
+	})
+
+	// Clients following the ride over SSE see the payment request too
+	assert.NoError(t, err)
+	assert.Equal(t, []string{rideID + ":" + constants.EventPaymentRequest}, rideEvents.events)
+}
+
+func TestEchoWebSocketHandler_HandleMessage_ValidCommandsDispatch(t *testing.T) {
+	driverID := uuid.New()
+	passengerID := uuid.New()
+	rideID := uuid.New().String()
+
+	tests := []struct {
+		name      string
+		event     string
+		data      string
+		mockSetup func(*mocks.MockUserUC)
+	}{
+		{
+			name:  "beacon",
+			event: constants.EventBeaconUpdate,
+			data:  `{"msisdn":"+6281234567890","is_active":true,"latitude":-6.2088,"longitude":106.8456}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().UpdateBeaconStatus(gomock.Any(), &models.BeaconRequest{
+					MSISDN: "+6281234567890", IsActive: true, Latitude: -6.2088, Longitude: 106.8456,
+				}).Return(nil)
+			},
+		},
+		{
+			name:  "beacon off without location",
+			event: constants.EventBeaconUpdate,
+			data:  `{"msisdn":"+6281234567890","is_active":false}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().UpdateBeaconStatus(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "finder",
+			event: constants.EventFinderUpdate,
+			data:  `{"msisdn":"+6281234567890","is_active":true,"location":{"latitude":-6.2088,"longitude":106.8456},"target_location":{"latitude":-6.1751,"longitude":106.865}}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().UpdateFinderStatus(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "match confirm",
+			event: constants.EventMatchConfirm,
+			data:  `{"match_id":"match-1","status":"ACCEPTED"}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().ConfirmMatch(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, req *models.MatchConfirmRequest) (*models.MatchProposal, error) {
+						assert.Equal(t, "match-1", req.ID)
+						return &models.MatchProposal{ID: req.ID, DriverID: driverID.String(), PassengerID: passengerID.String()}, nil
+					})
+			},
+		},
+		{
+			name:  "location",
+			event: constants.EventLocationUpdate,
+			data:  `{"ride_id":"` + rideID + `","location":{"latitude":-6.2088,"longitude":106.8456}}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().UpdateUserLocation(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:  "ride start",
+			event: constants.EventRideStarted,
+			data:  `{"ride_id":"` + rideID + `","driver_location":{"latitude":-6.2088,"longitude":106.8456},"passenger_location":{"latitude":-6.2089,"longitude":106.8457}}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().RideStart(gomock.Any(), gomock.Any()).
+					Return(&models.Ride{DriverID: driverID, PassengerID: passengerID}, nil)
+			},
+		},
+		{
+			name:  "ride arrived",
+			event: constants.EventRideArrived,
+			data:  `{"ride_id":"` + rideID + `","adjustment_factor":0.9}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().RideArrived(gomock.Any(), &models.RideArrivalReq{RideID: rideID, AdjustmentFactor: 0.9}).
+					Return(&models.PaymentRequest{RideID: rideID, PassengerID: passengerID.String()}, nil)
+			},
+		},
+		{
+			name:  "payment",
+			event: constants.EventPaymentProcessed,
+			data:  `{"ride_id":"` + rideID + `","total_cost":15000,"status":"ACCEPTED"}`,
+			mockSetup: func(m *mocks.MockUserUC) {
+				m.EXPECT().ProcessPayment(gomock.Any(), gomock.Any()).Return(&models.Payment{}, nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			tt.mockSetup(mockUserUC)
+			handler := NewEchoWebSocketHandler(mockUserUC)
+
+			ws, _ := dialRecordingClient(t)
+			msg := &models.WSMessage{Event: tt.event, Data: json.RawMessage(tt.data)}
+
+			err := handler.handleMessage(driverID.String(), "driver", ws, msg)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestEchoWebSocketHandler_HandleMessage_MalformedCommandsRejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   string
+		data    string
+		message string
+	}{
+		{"beacon missing msisdn", constants.EventBeaconUpdate, `{"is_active":true,"latitude":-6.2,"longitude":106.8}`, "msisdn is required"},
+		{"beacon active without location", constants.EventBeaconUpdate, `{"msisdn":"+6281234567890","is_active":true}`, "location is required"},
+		{"finder missing target", constants.EventFinderUpdate, `{"msisdn":"+6281234567890","is_active":true,"location":{"latitude":-6.2,"longitude":106.8}}`, "target_location is required"},
+		{"finder latitude out of range", constants.EventFinderUpdate, `{"msisdn":"+6281234567890","is_active":true,"location":{"latitude":-96.2,"longitude":106.8},"target_location":{"latitude":-6.1,"longitude":106.8}}`, "location latitude must be between -90 and 90"},
+		{"confirm missing match id", constants.EventMatchConfirm, `{"status":"ACCEPTED"}`, "match_id is required"},
+		{"confirm invalid status", constants.EventMatchConfirm, `{"match_id":"match-1","status":"MAYBE"}`, "invalid match status: MAYBE"},
+		{"location missing ride id", constants.EventLocationUpdate, `{"location":{"latitude":-6.2,"longitude":106.8}}`, "ride_id is required"},
+		{"ride start missing passenger location", constants.EventRideStarted, `{"ride_id":"ride-1","driver_location":{"latitude":-6.2,"longitude":106.8}}`, "passenger_location is required"},
+		{"arrival adjustment out of range", constants.EventRideArrived, `{"ride_id":"ride-1","adjustment_factor":1.5}`, "adjustment_factor must be between 0 and 1"},
+		{"payment invalid status", constants.EventPaymentProcessed, `{"ride_id":"ride-1","status":"PAID"}`, "invalid payment status: PAID"},
+		{"missing data", constants.EventMatchConfirm, ``, "data is required"},
+		{"wrong field type", constants.EventBeaconUpdate, `{"msisdn":42}`, "invalid data"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// No usecase calls are expected for malformed commands
+			mockUserUC := mocks.NewMockUserUC(ctrl)
+			handler := NewEchoWebSocketHandler(mockUserUC)
+
+			ws, received := dialRecordingClient(t)
+			msg := &models.WSMessage{Event: tt.event, Data: json.RawMessage(tt.data)}
+
+			err := handler.handleMessage(uuid.New().String(), "driver", ws, msg)
+			assert.NoError(t, err)
+
+			select {
+			case reply := <-received:
+				assert.Equal(t, constants.EventError, reply.Event)
+				var payload models.WSErrorMessage
+				require.NoError(t, json.Unmarshal(reply.Data, &payload))
+				assert.Equal(t, constants.ErrorInvalidFormat, payload.Code)
+				assert.Contains(t, payload.Message, tt.message)
+			case <-time.After(time.Second):
+				t.Fatal("expected an error message")
+			}
+		})
+	}
+}
+
+func TestEchoWebSocketHandler_HandleWebSocket_MalformedFrameKeepsConnection(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	handler := NewEchoWebSocketHandler(mockUserUC)
+
+	userID := uuid.New().String()
+	e := echo.New()
+	e.GET("/ws", handler.HandleWebSocket, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", userID)
+			c.Set("role", "driver")
+			return next(c)
+		}
+	})
+	server := httptest.NewServer(e)
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", "http://localhost/")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	receiveError := func() models.WSErrorMessage {
+		require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+		var reply models.WSMessage
+		require.NoError(t, websocket.JSON.Receive(ws, &reply))
+		assert.Equal(t, constants.EventError, reply.Event)
+		var payload models.WSErrorMessage
+		require.NoError(t, json.Unmarshal(reply.Data, &payload))
+		return payload
+	}
+
+	// Not JSON at all
+	require.NoError(t, websocket.Message.Send(ws, "not json"))
+	assert.Equal(t, constants.ErrorInvalidFormat, receiveError().Code)
+
+	// The connection is still served afterwards
+	require.NoError(t, websocket.JSON.Send(ws, models.WSMessage{
+		Event: constants.EventMatchConfirm,
+		Data:  json.RawMessage(`{"status":"ACCEPTED"}`),
+	}))
+	assert.Equal(t, "match_id is required", receiveError().Message)
+}
+
+func TestEchoWebSocketHandler_HandleWebSocket_OutlivesServerTimeouts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockUserUC := mocks.NewMockUserUC(ctrl)
+	handler := NewEchoWebSocketHandler(mockUserUC)
+
+	userID := uuid.New().String()
+	e := echo.New()
+	e.GET("/ws", handler.HandleWebSocket, func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			c.Set("user_id", userID)
+			c.Set("role", "driver")
+			return next(c)
+		}
+	})
+	server := httptest.NewUnstartedServer(e)
+	server.Config.ReadTimeout = 200 * time.Millisecond
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/ws", "", "http://localhost/")
+	require.NoError(t, err)
+	defer ws.Close()
+
+	// Well past both server timeouts the socket still reads and writes
+	time.Sleep(500 * time.Millisecond)
+	require.NoError(t, websocket.Message.Send(ws, "not json"))
+
+	require.NoError(t, ws.SetReadDeadline(time.Now().Add(time.Second)))
+	var reply models.WSMessage
+	require.NoError(t, websocket.JSON.Receive(ws, &reply))
+	assert.Equal(t, constants.EventError, reply.Event)
+}
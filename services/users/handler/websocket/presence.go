@@ -0,0 +1,41 @@
+package websocket
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/converter"
+	nrpkg "github.com/piresc/nebengjek/internal/pkg/newrelic"
+	"github.com/piresc/nebengjek/internal/utils"
+)
+
+// connectedRole returns the role a user's live connection authenticated with, if they have one
+func (h *EchoWebSocketHandler) connectedRole(userID string) (string, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	client, exists := h.clients[userID]
+	return client.role, exists
+}
+
+// GetUserPresence handles support lookups of whether a user is connected, in a matching pool or
+// on a ride. Connections are only known to the instance holding them.
+func (h *EchoWebSocketHandler) GetUserPresence(c echo.Context) error {
+	txn := nrpkg.FromEchoContext(c)
+	nrpkg.SetTransactionName(txn, "GetUserPresence")
+
+	userID := c.Param("id")
+	if _, err := converter.ParseUUID(userID); err != nil {
+		return utils.BadRequestResponse(c, "Invalid user ID")
+	}
+
+	nrpkg.AddTransactionAttribute(txn, "user.id", userID)
+
+	presence, err := h.userUC.GetUserPresence(c.Request().Context(), userID)
+	if err != nil {
+		nrpkg.NoticeTransactionError(txn, err)
+		return utils.ErrorResponseHandler(c, http.StatusInternalServerError, "Failed to retrieve user presence")
+	}
+	presence.ConnectedRole, presence.Connected = h.connectedRole(userID)
+
+	return utils.SuccessResponse(c, http.StatusOK, "User presence retrieved successfully", presence)
+}
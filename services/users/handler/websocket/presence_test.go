@@ -0,0 +1,119 @@
+package websocket
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// getPresence calls the presence endpoint for userID, decoding the presence on success
+func getPresence(t *testing.T, handler *EchoWebSocketHandler, userID string) (*httptest.ResponseRecorder, models.UserPresence) {
+	e := echo.New()
+	req := httptest.NewRequest(http.MethodGet, "/admin/users/"+userID+"/presence", nil)
+	rec := httptest.NewRecorder()
+	c := e.NewContext(req, rec)
+	c.SetParamNames("id")
+	c.SetParamValues(userID)
+
+	require.NoError(t, handler.GetUserPresence(c))
+
+	var response struct {
+		Data models.UserPresence `json:"data"`
+	}
+	if rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	}
+	return rec, response.Data
+}
+
+func TestEchoWebSocketHandler_GetUserPresence(t *testing.T) {
+	t.Run("Connected driver on a ride", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserUC := mocks.NewMockUserUC(ctrl)
+		handler := NewEchoWebSocketHandler(mockUserUC)
+
+		userID := uuid.New().String()
+		ws, _ := dialRecordingClient(t)
+		handler.addClient(userID, "driver", ws)
+
+		mockUserUC.EXPECT().GetUserPresence(gomock.Any(), userID).Return(&models.UserPresence{
+			UserID:         userID,
+			OnlineAsDriver: true,
+			ActiveRideID:   "ride-1",
+			ActiveRideRole: "driver",
+		}, nil)
+
+		rec, presence := getPresence(t, handler, userID)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.True(t, presence.Connected)
+		assert.Equal(t, "driver", presence.ConnectedRole)
+		assert.True(t, presence.OnlineAsDriver)
+		assert.Equal(t, "ride-1", presence.ActiveRideID)
+	})
+
+	t.Run("Disconnected passenger still searching", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserUC := mocks.NewMockUserUC(ctrl)
+		handler := NewEchoWebSocketHandler(mockUserUC)
+
+		// The passenger was connected but dropped off
+		userID := uuid.New().String()
+		ws, _ := dialRecordingClient(t)
+		handler.addClient(userID, "passenger", ws)
+		handler.removeClient(userID, ws)
+
+		mockUserUC.EXPECT().GetUserPresence(gomock.Any(), userID).Return(&models.UserPresence{
+			UserID:            userID,
+			OnlineAsPassenger: true,
+		}, nil)
+
+		rec, presence := getPresence(t, handler, userID)
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.False(t, presence.Connected)
+		assert.Empty(t, presence.ConnectedRole)
+		assert.True(t, presence.OnlineAsPassenger)
+		assert.Empty(t, presence.ActiveRideID)
+	})
+
+	t.Run("Invalid user ID", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		handler := NewEchoWebSocketHandler(mocks.NewMockUserUC(ctrl))
+
+		rec, _ := getPresence(t, handler, "not-a-uuid")
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Lookup failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockUserUC := mocks.NewMockUserUC(ctrl)
+		handler := NewEchoWebSocketHandler(mockUserUC)
+
+		userID := uuid.New().String()
+		mockUserUC.EXPECT().GetUserPresence(gomock.Any(), userID).Return(nil, errors.New("redis down"))
+
+		rec, _ := getPresence(t, handler, userID)
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	})
+}
@@ -35,6 +35,66 @@ func (m *MockUserGW) EXPECT() *MockUserGWMockRecorder {
 	return m.recorder
 }
 
+// GetActiveRideID mocks base method.
+func (m *MockUserGW) GetActiveRideID(arg0 context.Context, arg1, arg2 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActiveRideID", arg0, arg1, arg2)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActiveRideID indicates an expected call of GetActiveRideID.
+func (mr *MockUserGWMockRecorder) GetActiveRideID(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActiveRideID", reflect.TypeOf((*MockUserGW)(nil).GetActiveRideID), arg0, arg1, arg2)
+}
+
+// GetDriverCancellationStats mocks base method.
+func (m *MockUserGW) GetDriverCancellationStats(arg0 context.Context, arg1 string) (*models.DriverCancellationStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverCancellationStats", arg0, arg1)
+	ret0, _ := ret[0].(*models.DriverCancellationStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverCancellationStats indicates an expected call of GetDriverCancellationStats.
+func (mr *MockUserGWMockRecorder) GetDriverCancellationStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverCancellationStats", reflect.TypeOf((*MockUserGW)(nil).GetDriverCancellationStats), arg0, arg1)
+}
+
+// GetDriverMatches mocks base method.
+func (m *MockUserGW) GetDriverMatches(arg0 context.Context, arg1 string, arg2, arg3 int) (*models.MatchHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverMatches", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.MatchHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverMatches indicates an expected call of GetDriverMatches.
+func (mr *MockUserGWMockRecorder) GetDriverMatches(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverMatches", reflect.TypeOf((*MockUserGW)(nil).GetDriverMatches), arg0, arg1, arg2, arg3)
+}
+
+// GetPickupCode mocks base method.
+func (m *MockUserGW) GetPickupCode(arg0 context.Context, arg1 *models.PickupCodeRequest) (*models.PickupCodeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPickupCode", arg0, arg1)
+	ret0, _ := ret[0].(*models.PickupCodeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPickupCode indicates an expected call of GetPickupCode.
+func (mr *MockUserGWMockRecorder) GetPickupCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPickupCode", reflect.TypeOf((*MockUserGW)(nil).GetPickupCode), arg0, arg1)
+}
+
 // MatchConfirm mocks base method.
 func (m *MockUserGW) MatchConfirm(arg0 context.Context, arg1 *models.MatchConfirmRequest) (*models.MatchProposal, error) {
 	m.ctrl.T.Helper()
@@ -65,6 +125,20 @@ func (mr *MockUserGWMockRecorder) ProcessPayment(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProcessPayment", reflect.TypeOf((*MockUserGW)(nil).ProcessPayment), arg0, arg1)
 }
 
+// PublishAnnouncement mocks base method.
+func (m *MockUserGW) PublishAnnouncement(arg0 context.Context, arg1 *models.Announcement) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PublishAnnouncement", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PublishAnnouncement indicates an expected call of PublishAnnouncement.
+func (mr *MockUserGWMockRecorder) PublishAnnouncement(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PublishAnnouncement", reflect.TypeOf((*MockUserGW)(nil).PublishAnnouncement), arg0, arg1)
+}
+
 // PublishBeaconEvent mocks base method.
 func (m *MockUserGW) PublishBeaconEvent(arg0 context.Context, arg1 *models.BeaconEvent) error {
 	m.ctrl.T.Helper()
@@ -136,6 +210,20 @@ func (mr *MockUserGWMockRecorder) RideArrived(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RideArrived", reflect.TypeOf((*MockUserGW)(nil).RideArrived), arg0, arg1)
 }
 
+// SendOTP mocks base method.
+func (m *MockUserGW) SendOTP(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendOTP", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendOTP indicates an expected call of SendOTP.
+func (mr *MockUserGWMockRecorder) SendOTP(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOTP", reflect.TypeOf((*MockUserGW)(nil).SendOTP), arg0, arg1, arg2)
+}
+
 // StartRide mocks base method.
 func (m *MockUserGW) StartRide(arg0 context.Context, arg1 *models.RideStartRequest) (*models.Ride, error) {
 	m.ctrl.T.Helper()
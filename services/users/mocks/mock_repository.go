@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/piresc/nebengjek/internal/pkg/models"
@@ -35,6 +36,20 @@ func (m *MockUserRepo) EXPECT() *MockUserRepoMockRecorder {
 	return m.recorder
 }
 
+// CreateFavoriteLocation mocks base method.
+func (m *MockUserRepo) CreateFavoriteLocation(arg0 context.Context, arg1 *models.FavoriteLocation, arg2 int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFavoriteLocation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateFavoriteLocation indicates an expected call of CreateFavoriteLocation.
+func (mr *MockUserRepoMockRecorder) CreateFavoriteLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFavoriteLocation", reflect.TypeOf((*MockUserRepo)(nil).CreateFavoriteLocation), arg0, arg1, arg2)
+}
+
 // CreateOTP mocks base method.
 func (m *MockUserRepo) CreateOTP(arg0 context.Context, arg1 *models.OTP) error {
 	m.ctrl.T.Helper()
@@ -63,6 +78,65 @@ func (mr *MockUserRepoMockRecorder) CreateUser(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockUserRepo)(nil).CreateUser), arg0, arg1)
 }
 
+// DeleteFavoriteLocation mocks base method.
+func (m *MockUserRepo) DeleteFavoriteLocation(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFavoriteLocation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFavoriteLocation indicates an expected call of DeleteFavoriteLocation.
+func (mr *MockUserRepoMockRecorder) DeleteFavoriteLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFavoriteLocation", reflect.TypeOf((*MockUserRepo)(nil).DeleteFavoriteLocation), arg0, arg1, arg2)
+}
+
+// EndOnlineSession mocks base method.
+func (m *MockUserRepo) EndOnlineSession(arg0 context.Context, arg1 string, arg2 time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndOnlineSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EndOnlineSession indicates an expected call of EndOnlineSession.
+func (mr *MockUserRepoMockRecorder) EndOnlineSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndOnlineSession", reflect.TypeOf((*MockUserRepo)(nil).EndOnlineSession), arg0, arg1, arg2)
+}
+
+// GetChatHistory mocks base method.
+func (m *MockUserRepo) GetChatHistory(arg0 context.Context, arg1 string) ([]*models.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatHistory", arg0, arg1)
+	ret0, _ := ret[0].([]*models.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatHistory indicates an expected call of GetChatHistory.
+func (mr *MockUserRepoMockRecorder) GetChatHistory(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatHistory", reflect.TypeOf((*MockUserRepo)(nil).GetChatHistory), arg0, arg1)
+}
+
+// GetFavoriteLocation mocks base method.
+func (m *MockUserRepo) GetFavoriteLocation(arg0 context.Context, arg1, arg2 string) (*models.FavoriteLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFavoriteLocation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.FavoriteLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFavoriteLocation indicates an expected call of GetFavoriteLocation.
+func (mr *MockUserRepoMockRecorder) GetFavoriteLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFavoriteLocation", reflect.TypeOf((*MockUserRepo)(nil).GetFavoriteLocation), arg0, arg1, arg2)
+}
+
 // GetOTP mocks base method.
 func (m *MockUserRepo) GetOTP(arg0 context.Context, arg1, arg2 string) (*models.OTP, error) {
 	m.ctrl.T.Helper()
@@ -108,6 +182,81 @@ func (mr *MockUserRepoMockRecorder) GetUserByMSISDN(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByMSISDN", reflect.TypeOf((*MockUserRepo)(nil).GetUserByMSISDN), arg0, arg1)
 }
 
+// InvalidateOTP mocks base method.
+func (m *MockUserRepo) InvalidateOTP(arg0 context.Context, arg1 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InvalidateOTP", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InvalidateOTP indicates an expected call of InvalidateOTP.
+func (mr *MockUserRepoMockRecorder) InvalidateOTP(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InvalidateOTP", reflect.TypeOf((*MockUserRepo)(nil).InvalidateOTP), arg0, arg1)
+}
+
+// IsInMatchingPool mocks base method.
+func (m *MockUserRepo) IsInMatchingPool(arg0 context.Context, arg1, arg2 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsInMatchingPool", arg0, arg1, arg2)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsInMatchingPool indicates an expected call of IsInMatchingPool.
+func (mr *MockUserRepoMockRecorder) IsInMatchingPool(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsInMatchingPool", reflect.TypeOf((*MockUserRepo)(nil).IsInMatchingPool), arg0, arg1, arg2)
+}
+
+// ListFavoriteLocations mocks base method.
+func (m *MockUserRepo) ListFavoriteLocations(arg0 context.Context, arg1 string) ([]*models.FavoriteLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFavoriteLocations", arg0, arg1)
+	ret0, _ := ret[0].([]*models.FavoriteLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFavoriteLocations indicates an expected call of ListFavoriteLocations.
+func (mr *MockUserRepoMockRecorder) ListFavoriteLocations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFavoriteLocations", reflect.TypeOf((*MockUserRepo)(nil).ListFavoriteLocations), arg0, arg1)
+}
+
+// ListOnlineSessions mocks base method.
+func (m *MockUserRepo) ListOnlineSessions(arg0 context.Context, arg1 string, arg2, arg3 time.Time) ([]*models.DriverOnlineSession, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListOnlineSessions", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*models.DriverOnlineSession)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListOnlineSessions indicates an expected call of ListOnlineSessions.
+func (mr *MockUserRepoMockRecorder) ListOnlineSessions(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListOnlineSessions", reflect.TypeOf((*MockUserRepo)(nil).ListOnlineSessions), arg0, arg1, arg2, arg3)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserRepo) ListUsers(arg0 context.Context, arg1 models.UserFilter) ([]*models.User, int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].([]*models.User)
+	ret1, _ := ret[1].(int)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserRepoMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserRepo)(nil).ListUsers), arg0, arg1)
+}
+
 // MarkOTPVerified mocks base method.
 func (m *MockUserRepo) MarkOTPVerified(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -122,6 +271,92 @@ func (mr *MockUserRepoMockRecorder) MarkOTPVerified(arg0, arg1, arg2 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkOTPVerified", reflect.TypeOf((*MockUserRepo)(nil).MarkOTPVerified), arg0, arg1, arg2)
 }
 
+// RecordOTPFailure mocks base method.
+func (m *MockUserRepo) RecordOTPFailure(arg0 context.Context, arg1 string) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordOTPFailure", arg0, arg1)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordOTPFailure indicates an expected call of RecordOTPFailure.
+func (mr *MockUserRepoMockRecorder) RecordOTPFailure(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOTPFailure", reflect.TypeOf((*MockUserRepo)(nil).RecordOTPFailure), arg0, arg1)
+}
+
+// RecordOTPRequest mocks base method.
+func (m *MockUserRepo) RecordOTPRequest(arg0 context.Context, arg1, arg2 string, arg3 time.Time, arg4 time.Duration) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordOTPRequest", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RecordOTPRequest indicates an expected call of RecordOTPRequest.
+func (mr *MockUserRepoMockRecorder) RecordOTPRequest(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordOTPRequest", reflect.TypeOf((*MockUserRepo)(nil).RecordOTPRequest), arg0, arg1, arg2, arg3, arg4)
+}
+
+// SaveChatMessage mocks base method.
+func (m *MockUserRepo) SaveChatMessage(arg0 context.Context, arg1 *models.ChatMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveChatMessage", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveChatMessage indicates an expected call of SaveChatMessage.
+func (mr *MockUserRepoMockRecorder) SaveChatMessage(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveChatMessage", reflect.TypeOf((*MockUserRepo)(nil).SaveChatMessage), arg0, arg1)
+}
+
+// StartOnlineSession mocks base method.
+func (m *MockUserRepo) StartOnlineSession(arg0 context.Context, arg1 string, arg2 time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StartOnlineSession", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StartOnlineSession indicates an expected call of StartOnlineSession.
+func (mr *MockUserRepoMockRecorder) StartOnlineSession(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StartOnlineSession", reflect.TypeOf((*MockUserRepo)(nil).StartOnlineSession), arg0, arg1, arg2)
+}
+
+// UpdateDriverVerification mocks base method.
+func (m *MockUserRepo) UpdateDriverVerification(arg0 context.Context, arg1 string, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateDriverVerification", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateDriverVerification indicates an expected call of UpdateDriverVerification.
+func (mr *MockUserRepoMockRecorder) UpdateDriverVerification(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateDriverVerification", reflect.TypeOf((*MockUserRepo)(nil).UpdateDriverVerification), arg0, arg1, arg2)
+}
+
+// UpdateFavoriteLocation mocks base method.
+func (m *MockUserRepo) UpdateFavoriteLocation(arg0 context.Context, arg1 *models.FavoriteLocation) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFavoriteLocation", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateFavoriteLocation indicates an expected call of UpdateFavoriteLocation.
+func (mr *MockUserRepoMockRecorder) UpdateFavoriteLocation(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFavoriteLocation", reflect.TypeOf((*MockUserRepo)(nil).UpdateFavoriteLocation), arg0, arg1)
+}
+
 // UpdateToDriver mocks base method.
 func (m *MockUserRepo) UpdateToDriver(arg0 context.Context, arg1 *models.User) error {
 	m.ctrl.T.Helper()
@@ -7,6 +7,7 @@ package mocks
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	models "github.com/piresc/nebengjek/internal/pkg/models"
@@ -35,6 +36,35 @@ func (m *MockUserUC) EXPECT() *MockUserUCMockRecorder {
 	return m.recorder
 }
 
+// Announce mocks base method.
+func (m *MockUserUC) Announce(arg0 context.Context, arg1 *models.AnnouncementRequest) (*models.Announcement, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Announce", arg0, arg1)
+	ret0, _ := ret[0].(*models.Announcement)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Announce indicates an expected call of Announce.
+func (mr *MockUserUCMockRecorder) Announce(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Announce", reflect.TypeOf((*MockUserUC)(nil).Announce), arg0, arg1)
+}
+
+// CheckRideParticipant mocks base method.
+func (m *MockUserUC) CheckRideParticipant(arg0 context.Context, arg1, arg2, arg3 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRideParticipant", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CheckRideParticipant indicates an expected call of CheckRideParticipant.
+func (mr *MockUserUCMockRecorder) CheckRideParticipant(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRideParticipant", reflect.TypeOf((*MockUserUC)(nil).CheckRideParticipant), arg0, arg1, arg2, arg3)
+}
+
 // ConfirmMatch mocks base method.
 func (m *MockUserUC) ConfirmMatch(arg0 context.Context, arg1 *models.MatchConfirmRequest) (*models.MatchProposal, error) {
 	m.ctrl.T.Helper()
@@ -50,6 +80,49 @@ func (mr *MockUserUCMockRecorder) ConfirmMatch(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ConfirmMatch", reflect.TypeOf((*MockUserUC)(nil).ConfirmMatch), arg0, arg1)
 }
 
+// CreateFavoriteLocation mocks base method.
+func (m *MockUserUC) CreateFavoriteLocation(arg0 context.Context, arg1 string, arg2 *models.FavoriteLocationRequest) (*models.FavoriteLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFavoriteLocation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.FavoriteLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFavoriteLocation indicates an expected call of CreateFavoriteLocation.
+func (mr *MockUserUCMockRecorder) CreateFavoriteLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFavoriteLocation", reflect.TypeOf((*MockUserUC)(nil).CreateFavoriteLocation), arg0, arg1, arg2)
+}
+
+// DeleteFavoriteLocation mocks base method.
+func (m *MockUserUC) DeleteFavoriteLocation(arg0 context.Context, arg1, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteFavoriteLocation", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteFavoriteLocation indicates an expected call of DeleteFavoriteLocation.
+func (mr *MockUserUCMockRecorder) DeleteFavoriteLocation(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteFavoriteLocation", reflect.TypeOf((*MockUserUC)(nil).DeleteFavoriteLocation), arg0, arg1, arg2)
+}
+
+// EndEvictedDriverSession mocks base method.
+func (m *MockUserUC) EndEvictedDriverSession(arg0 context.Context, arg1 *models.DriverEviction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EndEvictedDriverSession", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// EndEvictedDriverSession indicates an expected call of EndEvictedDriverSession.
+func (mr *MockUserUCMockRecorder) EndEvictedDriverSession(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EndEvictedDriverSession", reflect.TypeOf((*MockUserUC)(nil).EndEvictedDriverSession), arg0, arg1)
+}
+
 // GenerateOTP mocks base method.
 func (m *MockUserUC) GenerateOTP(arg0 context.Context, arg1 string) error {
 	m.ctrl.T.Helper()
@@ -64,6 +137,81 @@ func (mr *MockUserUCMockRecorder) GenerateOTP(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GenerateOTP", reflect.TypeOf((*MockUserUC)(nil).GenerateOTP), arg0, arg1)
 }
 
+// GetChatHistory mocks base method.
+func (m *MockUserUC) GetChatHistory(arg0 context.Context, arg1, arg2, arg3 string) ([]*models.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetChatHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*models.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetChatHistory indicates an expected call of GetChatHistory.
+func (mr *MockUserUCMockRecorder) GetChatHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetChatHistory", reflect.TypeOf((*MockUserUC)(nil).GetChatHistory), arg0, arg1, arg2, arg3)
+}
+
+// GetDriverMatches mocks base method.
+func (m *MockUserUC) GetDriverMatches(arg0 context.Context, arg1 string, arg2, arg3 int) (*models.MatchHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverMatches", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.MatchHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverMatches indicates an expected call of GetDriverMatches.
+func (mr *MockUserUCMockRecorder) GetDriverMatches(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverMatches", reflect.TypeOf((*MockUserUC)(nil).GetDriverMatches), arg0, arg1, arg2, arg3)
+}
+
+// GetDriverOnlineTime mocks base method.
+func (m *MockUserUC) GetDriverOnlineTime(arg0 context.Context, arg1 string, arg2 time.Time) (*models.DriverOnlineSummary, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetDriverOnlineTime", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.DriverOnlineSummary)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetDriverOnlineTime indicates an expected call of GetDriverOnlineTime.
+func (mr *MockUserUCMockRecorder) GetDriverOnlineTime(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetDriverOnlineTime", reflect.TypeOf((*MockUserUC)(nil).GetDriverOnlineTime), arg0, arg1, arg2)
+}
+
+// GetPickupCode mocks base method.
+func (m *MockUserUC) GetPickupCode(arg0 context.Context, arg1, arg2, arg3 string) (*models.PickupCodeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPickupCode", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.PickupCodeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPickupCode indicates an expected call of GetPickupCode.
+func (mr *MockUserUCMockRecorder) GetPickupCode(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPickupCode", reflect.TypeOf((*MockUserUC)(nil).GetPickupCode), arg0, arg1, arg2, arg3)
+}
+
+// GetRidePickupCode mocks base method.
+func (m *MockUserUC) GetRidePickupCode(arg0 context.Context, arg1, arg2 string) (*models.PickupCodeResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRidePickupCode", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.PickupCodeResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRidePickupCode indicates an expected call of GetRidePickupCode.
+func (mr *MockUserUCMockRecorder) GetRidePickupCode(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRidePickupCode", reflect.TypeOf((*MockUserUC)(nil).GetRidePickupCode), arg0, arg1, arg2)
+}
+
 // GetUserByID mocks base method.
 func (m *MockUserUC) GetUserByID(arg0 context.Context, arg1 string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -79,6 +227,51 @@ func (mr *MockUserUCMockRecorder) GetUserByID(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByID", reflect.TypeOf((*MockUserUC)(nil).GetUserByID), arg0, arg1)
 }
 
+// GetUserPresence mocks base method.
+func (m *MockUserUC) GetUserPresence(arg0 context.Context, arg1 string) (*models.UserPresence, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserPresence", arg0, arg1)
+	ret0, _ := ret[0].(*models.UserPresence)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserPresence indicates an expected call of GetUserPresence.
+func (mr *MockUserUCMockRecorder) GetUserPresence(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserPresence", reflect.TypeOf((*MockUserUC)(nil).GetUserPresence), arg0, arg1)
+}
+
+// ListFavoriteLocations mocks base method.
+func (m *MockUserUC) ListFavoriteLocations(arg0 context.Context, arg1 string) ([]*models.FavoriteLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFavoriteLocations", arg0, arg1)
+	ret0, _ := ret[0].([]*models.FavoriteLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFavoriteLocations indicates an expected call of ListFavoriteLocations.
+func (mr *MockUserUCMockRecorder) ListFavoriteLocations(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFavoriteLocations", reflect.TypeOf((*MockUserUC)(nil).ListFavoriteLocations), arg0, arg1)
+}
+
+// ListUsers mocks base method.
+func (m *MockUserUC) ListUsers(arg0 context.Context, arg1 models.UserFilter) (*models.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListUsers", arg0, arg1)
+	ret0, _ := ret[0].(*models.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListUsers indicates an expected call of ListUsers.
+func (mr *MockUserUCMockRecorder) ListUsers(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListUsers", reflect.TypeOf((*MockUserUC)(nil).ListUsers), arg0, arg1)
+}
+
 // ProcessPayment mocks base method.
 func (m *MockUserUC) ProcessPayment(arg0 context.Context, arg1 *models.PaymentProccessRequest) (*models.Payment, error) {
 	m.ctrl.T.Helper()
@@ -152,6 +345,21 @@ func (mr *MockUserUCMockRecorder) RideStart(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RideStart", reflect.TypeOf((*MockUserUC)(nil).RideStart), arg0, arg1)
 }
 
+// SendChatMessage mocks base method.
+func (m *MockUserUC) SendChatMessage(arg0 context.Context, arg1, arg2 string, arg3 *models.ChatMessage) (*models.ChatMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendChatMessage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.ChatMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SendChatMessage indicates an expected call of SendChatMessage.
+func (mr *MockUserUCMockRecorder) SendChatMessage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendChatMessage", reflect.TypeOf((*MockUserUC)(nil).SendChatMessage), arg0, arg1, arg2, arg3)
+}
+
 // UpdateBeaconStatus mocks base method.
 func (m *MockUserUC) UpdateBeaconStatus(arg0 context.Context, arg1 *models.BeaconRequest) error {
 	m.ctrl.T.Helper()
@@ -166,6 +374,21 @@ func (mr *MockUserUCMockRecorder) UpdateBeaconStatus(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBeaconStatus", reflect.TypeOf((*MockUserUC)(nil).UpdateBeaconStatus), arg0, arg1)
 }
 
+// UpdateFavoriteLocation mocks base method.
+func (m *MockUserUC) UpdateFavoriteLocation(arg0 context.Context, arg1, arg2 string, arg3 *models.FavoriteLocationRequest) (*models.FavoriteLocation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFavoriteLocation", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*models.FavoriteLocation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFavoriteLocation indicates an expected call of UpdateFavoriteLocation.
+func (mr *MockUserUCMockRecorder) UpdateFavoriteLocation(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFavoriteLocation", reflect.TypeOf((*MockUserUC)(nil).UpdateFavoriteLocation), arg0, arg1, arg2, arg3)
+}
+
 // UpdateFinderStatus mocks base method.
 func (m *MockUserUC) UpdateFinderStatus(arg0 context.Context, arg1 *models.FinderRequest) error {
 	m.ctrl.T.Helper()
@@ -194,6 +417,21 @@ func (mr *MockUserUCMockRecorder) UpdateUserLocation(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateUserLocation", reflect.TypeOf((*MockUserUC)(nil).UpdateUserLocation), arg0, arg1)
 }
 
+// VerifyDriver mocks base method.
+func (m *MockUserUC) VerifyDriver(arg0 context.Context, arg1 string, arg2 bool) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyDriver", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyDriver indicates an expected call of VerifyDriver.
+func (mr *MockUserUCMockRecorder) VerifyDriver(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyDriver", reflect.TypeOf((*MockUserUC)(nil).VerifyDriver), arg0, arg1, arg2)
+}
+
 // VerifyOTP mocks base method.
 func (m *MockUserUC) VerifyOTP(arg0 context.Context, arg1, arg2 string) (*models.AuthResponse, error) {
 	m.ctrl.T.Helper()
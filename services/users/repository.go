@@ -2,6 +2,7 @@ package users
 
 import (
 	"context"
+	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
@@ -15,8 +16,28 @@ type UserRepo interface {
 	GetUserByID(ctx context.Context, id string) (*models.User, error)
 	GetUserByMSISDN(ctx context.Context, msisdn string) (*models.User, error)
 	UpdateToDriver(ctx context.Context, user *models.User) error
+	UpdateDriverVerification(ctx context.Context, userID string, verified bool) error
+	ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error)
+	// Ride chat
+	SaveChatMessage(ctx context.Context, msg *models.ChatMessage) error
+	GetChatHistory(ctx context.Context, rideID string) ([]*models.ChatMessage, error)
+	// Presence in the matching pools
+	IsInMatchingPool(ctx context.Context, userID, role string) (bool, error)
+	// Driver online sessions
+	StartOnlineSession(ctx context.Context, driverID string, at time.Time) error
+	EndOnlineSession(ctx context.Context, driverID string, at time.Time) (bool, error)
+	ListOnlineSessions(ctx context.Context, driverID string, from, to time.Time) ([]*models.DriverOnlineSession, error)
+	// Favorite locations
+	CreateFavoriteLocation(ctx context.Context, favorite *models.FavoriteLocation, limit int) error
+	ListFavoriteLocations(ctx context.Context, userID string) ([]*models.FavoriteLocation, error)
+	GetFavoriteLocation(ctx context.Context, userID, favoriteID string) (*models.FavoriteLocation, error)
+	UpdateFavoriteLocation(ctx context.Context, favorite *models.FavoriteLocation) error
+	DeleteFavoriteLocation(ctx context.Context, userID, favoriteID string) error
 	// OTP management
 	CreateOTP(ctx context.Context, otp *models.OTP) error
 	GetOTP(ctx context.Context, msisdn, code string) (*models.OTP, error)
 	MarkOTPVerified(ctx context.Context, msisdn string, code string) error
+	RecordOTPRequest(ctx context.Context, msisdn, requestID string, at time.Time, window time.Duration) (int, error)
+	RecordOTPFailure(ctx context.Context, msisdn string) (int, error)
+	InvalidateOTP(ctx context.Context, msisdn string) error
 }
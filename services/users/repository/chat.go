@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+const (
+	// chatHistoryLimit is the number of recent messages kept per ride
+	chatHistoryLimit = 50
+	// chatHistoryTTL keeps chat history around for as long as an active ride can last
+	chatHistoryTTL = 24 * time.Hour
+)
+
+// SaveChatMessage appends a message to the ride's chat history, keeping only the most recent ones
+func (r *UserRepo) SaveChatMessage(ctx context.Context, msg *models.ChatMessage) error {
+	msgJSON, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal chat message: %w", err)
+	}
+
+	key := fmt.Sprintf(constants.KeyRideChat, msg.RideID)
+	if err := r.redisClient.RPush(ctx, key, string(msgJSON)); err != nil {
+		return fmt.Errorf("failed to store chat message: %w", err)
+	}
+	if err := r.redisClient.LTrim(ctx, key, -chatHistoryLimit, -1); err != nil {
+		return fmt.Errorf("failed to trim chat history: %w", err)
+	}
+	if err := r.redisClient.Expire(ctx, key, chatHistoryTTL); err != nil {
+		return fmt.Errorf("failed to set chat history expiry: %w", err)
+	}
+
+	return nil
+}
+
+// GetChatHistory returns the recent chat messages of a ride, oldest first
+func (r *UserRepo) GetChatHistory(ctx context.Context, rideID string) ([]*models.ChatMessage, error) {
+	key := fmt.Sprintf(constants.KeyRideChat, rideID)
+	entries, err := r.redisClient.LRange(ctx, key, 0, -1)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chat history: %w", err)
+	}
+
+	messages := make([]*models.ChatMessage, 0, len(entries))
+	for _, entry := range entries {
+		var msg models.ChatMessage
+		if err := json.Unmarshal([]byte(entry), &msg); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal chat message: %w", err)
+		}
+		messages = append(messages, &msg)
+	}
+
+	return messages, nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+func TestSaveChatMessage_KeepsRecentHistory(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	for i := 0; i < chatHistoryLimit+5; i++ {
+		err := repo.SaveChatMessage(ctx, &models.ChatMessage{
+			RideID:   "ride-1",
+			SenderID: "driver-1",
+			Message:  fmt.Sprintf("message %d", i),
+			SentAt:   time.Now(),
+		})
+		require.NoError(t, err)
+	}
+
+	key := fmt.Sprintf(constants.KeyRideChat, "ride-1")
+	assert.Equal(t, chatHistoryTTL, mr.TTL(key))
+
+	messages, err := repo.GetChatHistory(ctx, "ride-1")
+	require.NoError(t, err)
+	require.Len(t, messages, chatHistoryLimit)
+	assert.Equal(t, "message 5", messages[0].Message)
+	assert.Equal(t, fmt.Sprintf("message %d", chatHistoryLimit+4), messages[len(messages)-1].Message)
+}
+
+func TestGetChatHistory_Empty(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	defer mr.Close()
+
+	messages, err := repo.GetChatHistory(context.Background(), "ride-unknown")
+	assert.NoError(t, err)
+	assert.Empty(t, messages)
+}
@@ -8,9 +8,11 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	_ "github.com/newrelic/go-agent/v3/integrations/nrpq"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 )
 
 // getDriverInfo retrieves driver information for a user
@@ -55,12 +57,13 @@ func (r *UserRepo) UpdateToDriver(ctx context.Context, user *models.User) error
 		"user_id":       user.ID,
 		"vehicle_type":  user.DriverInfo.VehicleType,
 		"vehicle_plate": user.DriverInfo.VehiclePlate,
+		"documents":     pq.StringArray(user.DriverInfo.Documents),
 	}
 
 	query = `
 			INSERT INTO drivers (
-				user_id, vehicle_type, vehicle_plate
-			) VALUES (:user_id, :vehicle_type, :vehicle_plate)
+				user_id, vehicle_type, vehicle_plate, documents
+			) VALUES (:user_id, :vehicle_type, :vehicle_plate, :documents)
 		`
 	_, err = tx.NamedExecContext(ctx, query, driverData)
 	if err != nil {
@@ -71,17 +74,59 @@ func (r *UserRepo) UpdateToDriver(ctx context.Context, user *models.User) error
 	if err = tx.Commit(); err != nil {
 		return fmt.Errorf("failed to commit transaction: %w", err)
 	}
+	r.invalidateCachedUser(ctx, user.ID.String())
 	return nil
 }
 
-// GetUserByID retrieves a user by ID
+// UpdateDriverVerification sets the verification status of a driver after document review
+func (r *UserRepo) UpdateDriverVerification(ctx context.Context, userID string, verified bool) error {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	var verifiedAt *time.Time
+	if verified {
+		now := time.Now()
+		verifiedAt = &now
+	}
+
+	query := `
+		UPDATE drivers
+		SET verified = $1, verified_at = $2
+		WHERE user_id = $3
+	`
+
+	result, err := r.db.ExecContext(dbCtx, query, verified, verifiedAt, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update driver verification: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return fmt.Errorf("%w: %s", users.ErrDriverNotFound, userID)
+	}
+
+	r.invalidateCachedUser(dbCtx, userID)
+	return nil
+}
+
+// GetUserByID retrieves a user by ID, served from a short-lived cache when possible since it is
+// called on most requests
 func (r *UserRepo) GetUserByID(ctx context.Context, id string) (*models.User, error) {
 	txn := newrelic.FromContext(ctx)
 	dbCtx := newrelic.NewContext(ctx, txn)
 
+	if cached := r.getCachedUser(dbCtx, id); cached != nil {
+		return cached, nil
+	}
+
 	user, err := r.getUserByField(dbCtx, "id", id)
 	if err != nil {
 		return nil, err
 	}
+	r.cacheUser(dbCtx, user)
 	return user, nil
 }
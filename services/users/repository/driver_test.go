@@ -239,6 +239,76 @@ func TestUpdateToDriver(t *testing.T) {
 	}
 }
 
+func TestUpdateDriverVerification(t *testing.T) {
+	testCases := []struct {
+		name       string
+		userID     string
+		verified   bool
+		mockSetup  func(mock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, err error)
+	}{
+		{
+			name:     "Verify Driver",
+			userID:   "550e8400-e29b-41d4-a716-446655440001",
+			verified: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("^UPDATE drivers").
+					WithArgs(true, sqlmock.AnyArg(), "550e8400-e29b-41d4-a716-446655440001").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:     "Revoke Verification",
+			userID:   "550e8400-e29b-41d4-a716-446655440001",
+			verified: false,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("^UPDATE drivers").
+					WithArgs(false, nil, "550e8400-e29b-41d4-a716-446655440001").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.NoError(t, err)
+			},
+		},
+		{
+			name:     "Driver Not Found",
+			userID:   "550e8400-e29b-41d4-a716-446655440099",
+			verified: true,
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec("^UPDATE drivers").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), "driver not found")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			repo, mock, cleanup := setupUserRepoTest(t)
+			defer cleanup()
+
+			// Apply mocks
+			tc.mockSetup(mock)
+
+			// Execute
+			err := repo.UpdateDriverVerification(context.Background(), tc.userID, tc.verified)
+
+			// Assert
+			tc.assertFunc(t, err)
+
+			// Verify all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
 func TestGetUserByID(t *testing.T) {
 	testCases := []struct {
 		name       string
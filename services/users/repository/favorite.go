@@ -0,0 +1,132 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
+)
+
+// CreateFavoriteLocation saves a favorite location unless its user already has limit of them,
+// in which case users.ErrFavoriteLimitReached is returned. The count is checked in the same statement.
+func (r *UserRepo) CreateFavoriteLocation(ctx context.Context, favorite *models.FavoriteLocation, limit int) error {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `
+		INSERT INTO favorite_locations (favorite_id, user_id, label, latitude, longitude, created_at, updated_at)
+		SELECT $1, $2, $3, $4, $5, $6, $6
+		WHERE (SELECT COUNT(*) FROM favorite_locations WHERE user_id = $2) < $7
+	`
+
+	result, err := r.db.ExecContext(dbCtx, query, favorite.ID, favorite.UserID, favorite.Label,
+		favorite.Latitude, favorite.Longitude, favorite.CreatedAt, limit)
+	if err != nil {
+		return fmt.Errorf("failed to create favorite location: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return users.ErrFavoriteLimitReached
+	}
+	return nil
+}
+
+// ListFavoriteLocations returns a user's favorite locations, oldest first
+func (r *UserRepo) ListFavoriteLocations(ctx context.Context, userID string) ([]*models.FavoriteLocation, error) {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `
+		SELECT favorite_id, user_id, label, latitude, longitude, created_at, updated_at
+		FROM favorite_locations
+		WHERE user_id = $1
+		ORDER BY created_at
+	`
+
+	favorites := []*models.FavoriteLocation{}
+	if err := r.db.SelectContext(dbCtx, &favorites, query, userID); err != nil {
+		return nil, fmt.Errorf("failed to list favorite locations: %w", err)
+	}
+	return favorites, nil
+}
+
+// GetFavoriteLocation returns one of a user's favorite locations, or users.ErrFavoriteNotFound
+func (r *UserRepo) GetFavoriteLocation(ctx context.Context, userID, favoriteID string) (*models.FavoriteLocation, error) {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `
+		SELECT favorite_id, user_id, label, latitude, longitude, created_at, updated_at
+		FROM favorite_locations
+		WHERE favorite_id = $1 AND user_id = $2
+	`
+
+	var favorite models.FavoriteLocation
+	if err := r.db.GetContext(dbCtx, &favorite, query, favoriteID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, users.ErrFavoriteNotFound
+		}
+		return nil, fmt.Errorf("failed to get favorite location: %w", err)
+	}
+	return &favorite, nil
+}
+
+// UpdateFavoriteLocation replaces the label and coordinates of a user's favorite location
+func (r *UserRepo) UpdateFavoriteLocation(ctx context.Context, favorite *models.FavoriteLocation) error {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `
+		UPDATE favorite_locations
+		SET label = $1, latitude = $2, longitude = $3, updated_at = $4
+		WHERE favorite_id = $5 AND user_id = $6
+	`
+
+	result, err := r.db.ExecContext(dbCtx, query, favorite.Label, favorite.Latitude, favorite.Longitude,
+		favorite.UpdatedAt, favorite.ID, favorite.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to update favorite location: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return users.ErrFavoriteNotFound
+	}
+	return nil
+}
+
+// DeleteFavoriteLocation removes one of a user's favorite locations
+func (r *UserRepo) DeleteFavoriteLocation(ctx context.Context, userID, favoriteID string) error {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `DELETE FROM favorite_locations WHERE favorite_id = $1 AND user_id = $2`
+
+	result, err := r.db.ExecContext(dbCtx, query, favoriteID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete favorite location: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rows == 0 {
+		return users.ErrFavoriteNotFound
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var favoriteColumns = []string{"favorite_id", "user_id", "label", "latitude", "longitude", "created_at", "updated_at"}
+
+func newFavorite() *models.FavoriteLocation {
+	at := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+	return &models.FavoriteLocation{
+		ID:        uuid.New(),
+		UserID:    uuid.New(),
+		Label:     "Home",
+		Latitude:  -6.2088,
+		Longitude: 106.8456,
+		CreatedAt: at,
+		UpdatedAt: at,
+	}
+}
+
+func TestCreateFavoriteLocation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		rowsAffected int64
+		expectedErr  error
+	}{
+		{name: "Saved below the cap", rowsAffected: 1},
+		{name: "Cap reached", rowsAffected: 0, expectedErr: users.ErrFavoriteLimitReached},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock, cleanup := setupUserRepoTest(t)
+			defer cleanup()
+
+			favorite := newFavorite()
+
+			// The cap is checked in the insert itself
+			mock.ExpectExec("^INSERT INTO favorite_locations .* WHERE \\(SELECT COUNT\\(\\*\\) FROM favorite_locations WHERE user_id = \\$2\\) < \\$7").
+				WithArgs(favorite.ID, favorite.UserID, "Home", favorite.Latitude, favorite.Longitude, favorite.CreatedAt, 10).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			err := repo.CreateFavoriteLocation(context.Background(), favorite, 10)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListFavoriteLocations(t *testing.T) {
+	repo, mock, cleanup := setupUserRepoTest(t)
+	defer cleanup()
+
+	home := newFavorite()
+	work := newFavorite()
+	work.UserID = home.UserID
+	work.Label = "Work"
+
+	mock.ExpectQuery("^SELECT favorite_id, user_id, label, latitude, longitude, created_at, updated_at FROM favorite_locations WHERE user_id = \\$1 ORDER BY created_at").
+		WithArgs(home.UserID.String()).
+		WillReturnRows(sqlmock.NewRows(favoriteColumns).
+			AddRow(home.ID, home.UserID, home.Label, home.Latitude, home.Longitude, home.CreatedAt, home.UpdatedAt).
+			AddRow(work.ID, work.UserID, work.Label, work.Latitude, work.Longitude, work.CreatedAt, work.UpdatedAt))
+
+	favorites, err := repo.ListFavoriteLocations(context.Background(), home.UserID.String())
+
+	require.NoError(t, err)
+	require.Len(t, favorites, 2)
+	assert.Equal(t, "Home", favorites[0].Label)
+	assert.Equal(t, "Work", favorites[1].Label)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFavoriteLocation_NotFound(t *testing.T) {
+	repo, mock, cleanup := setupUserRepoTest(t)
+	defer cleanup()
+
+	favorite := newFavorite()
+
+	// Another user's favorite is not found either, since the query is scoped to the owner
+	mock.ExpectQuery("^SELECT favorite_id, .* WHERE favorite_id = \\$1 AND user_id = \\$2").
+		WithArgs(favorite.ID.String(), favorite.UserID.String()).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetFavoriteLocation(context.Background(), favorite.UserID.String(), favorite.ID.String())
+
+	assert.ErrorIs(t, err, users.ErrFavoriteNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateFavoriteLocation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		rowsAffected int64
+		expectedErr  error
+	}{
+		{name: "Updated", rowsAffected: 1},
+		{name: "Not found", rowsAffected: 0, expectedErr: users.ErrFavoriteNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock, cleanup := setupUserRepoTest(t)
+			defer cleanup()
+
+			favorite := newFavorite()
+			favorite.Label = "Office"
+
+			mock.ExpectExec("^UPDATE favorite_locations SET label = \\$1, latitude = \\$2, longitude = \\$3, updated_at = \\$4 WHERE favorite_id = \\$5 AND user_id = \\$6").
+				WithArgs("Office", favorite.Latitude, favorite.Longitude, favorite.UpdatedAt, favorite.ID, favorite.UserID).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			err := repo.UpdateFavoriteLocation(context.Background(), favorite)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDeleteFavoriteLocation(t *testing.T) {
+	testCases := []struct {
+		name         string
+		rowsAffected int64
+		expectedErr  error
+	}{
+		{name: "Deleted", rowsAffected: 1},
+		{name: "Not found", rowsAffected: 0, expectedErr: users.ErrFavoriteNotFound},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock, cleanup := setupUserRepoTest(t)
+			defer cleanup()
+
+			userID := uuid.New().String()
+			favoriteID := uuid.New().String()
+
+			mock.ExpectExec("^DELETE FROM favorite_locations WHERE favorite_id = \\$1 AND user_id = \\$2").
+				WithArgs(favoriteID, userID).
+				WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+
+			err := repo.DeleteFavoriteLocation(context.Background(), userID, favoriteID)
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
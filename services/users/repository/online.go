@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// StartOnlineSession opens an online session for the driver. A driver who is already online keeps
+// their open session, so repeated online beacons do not restart it.
+func (r *UserRepo) StartOnlineSession(ctx context.Context, driverID string, at time.Time) error {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `
+		INSERT INTO driver_online_sessions (driver_id, started_at)
+		VALUES ($1, $2)
+		ON CONFLICT (driver_id) WHERE ended_at IS NULL DO NOTHING
+	`
+
+	if _, err := r.db.ExecContext(dbCtx, query, driverID, at); err != nil {
+		return fmt.Errorf("failed to start online session: %w", err)
+	}
+	return nil
+}
+
+// EndOnlineSession closes the driver's open online session, reporting whether one was open
+func (r *UserRepo) EndOnlineSession(ctx context.Context, driverID string, at time.Time) (bool, error) {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `
+		UPDATE driver_online_sessions
+		SET ended_at = $1
+		WHERE driver_id = $2 AND ended_at IS NULL
+	`
+
+	result, err := r.db.ExecContext(dbCtx, query, at, driverID)
+	if err != nil {
+		return false, fmt.Errorf("failed to end online session: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	return rows > 0, nil
+}
+
+// ListOnlineSessions returns the driver's online sessions overlapping [from, to), oldest first
+func (r *UserRepo) ListOnlineSessions(ctx context.Context, driverID string, from, to time.Time) ([]*models.DriverOnlineSession, error) {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	query := `
+		SELECT session_id, driver_id, started_at, ended_at
+		FROM driver_online_sessions
+		WHERE driver_id = $1 AND started_at < $2 AND (ended_at IS NULL OR ended_at > $3)
+		ORDER BY started_at
+	`
+
+	sessions := []*models.DriverOnlineSession{}
+	if err := r.db.SelectContext(dbCtx, &sessions, query, driverID, to, from); err != nil {
+		return nil, fmt.Errorf("failed to list online sessions: %w", err)
+	}
+	return sessions, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartOnlineSession(t *testing.T) {
+	repo, mock, cleanup := setupUserRepoTest(t)
+	defer cleanup()
+
+	driverID := "550e8400-e29b-41d4-a716-446655440001"
+	at := time.Date(2026, 1, 2, 8, 0, 0, 0, time.UTC)
+
+	// An already open session is kept rather than duplicated
+	mock.ExpectExec("^INSERT INTO driver_online_sessions .* ON CONFLICT \\(driver_id\\) WHERE ended_at IS NULL DO NOTHING").
+		WithArgs(driverID, at).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err := repo.StartOnlineSession(context.Background(), driverID, at)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEndOnlineSession(t *testing.T) {
+	testCases := []struct {
+		name          string
+		rowsAffected  int64
+		execErr       error
+		expectedEnded bool
+		expectErr     bool
+	}{
+		{name: "Open session closed", rowsAffected: 1, expectedEnded: true},
+		{name: "No open session", rowsAffected: 0, expectedEnded: false},
+		{name: "Database error", execErr: errors.New("connection reset"), expectErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo, mock, cleanup := setupUserRepoTest(t)
+			defer cleanup()
+
+			driverID := "550e8400-e29b-41d4-a716-446655440001"
+			at := time.Date(2026, 1, 2, 17, 0, 0, 0, time.UTC)
+
+			exec := mock.ExpectExec("^UPDATE driver_online_sessions").WithArgs(at, driverID)
+			if tc.execErr != nil {
+				exec.WillReturnError(tc.execErr)
+			} else {
+				exec.WillReturnResult(sqlmock.NewResult(0, tc.rowsAffected))
+			}
+
+			ended, err := repo.EndOnlineSession(context.Background(), driverID, at)
+			if tc.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tc.expectedEnded, ended)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestListOnlineSessions(t *testing.T) {
+	repo, mock, cleanup := setupUserRepoTest(t)
+	defer cleanup()
+
+	driverID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
+	from := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	endedAt := from.Add(10 * time.Hour)
+
+	rows := sqlmock.NewRows([]string{"session_id", "driver_id", "started_at", "ended_at"}).
+		AddRow(uuid.New(), driverID, from.Add(8*time.Hour), endedAt).
+		AddRow(uuid.New(), driverID, from.Add(20*time.Hour), nil)
+	mock.ExpectQuery("^SELECT session_id, driver_id, started_at, ended_at FROM driver_online_sessions").
+		WithArgs(driverID.String(), to, from).
+		WillReturnRows(rows)
+
+	sessions, err := repo.ListOnlineSessions(context.Background(), driverID.String(), from, to)
+	require.NoError(t, err)
+	require.Len(t, sessions, 2)
+	assert.Equal(t, endedAt, *sessions[0].EndedAt)
+	assert.Nil(t, sessions[1].EndedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,16 +3,20 @@ package repository
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 )
 
 const otpExpirationTime = 5 * time.Minute
 
-// CreateOTP creates a new OTP record in Redis
+// CreateOTP creates a new OTP record in Redis, resetting the failed attempts of the code it replaces
 func (r *UserRepo) CreateOTP(ctx context.Context, otp *models.OTP) error {
 	// Convert OTP to JSON
 	otpJSON, err := json.Marshal(otp)
@@ -25,16 +29,22 @@ func (r *UserRepo) CreateOTP(ctx context.Context, otp *models.OTP) error {
 	if err := r.redisClient.Set(ctx, key, string(otpJSON), otpExpirationTime); err != nil {
 		return fmt.Errorf("failed to store OTP in Redis: %w", err)
 	}
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyUserOTPAttempts, otp.MSISDN)); err != nil {
+		return fmt.Errorf("failed to reset OTP attempts: %w", err)
+	}
 
 	return nil
 }
 
-// GetOTP retrieves an OTP record from Redis
+// GetOTP retrieves an OTP record from Redis, or users.ErrOTPExpired when none is pending
 func (r *UserRepo) GetOTP(ctx context.Context, msisdn, code string) (*models.OTP, error) {
 	key := fmt.Sprintf(constants.KeyUserOTP, msisdn)
 	otpJSON, err := r.redisClient.Get(ctx, key)
 	if err != nil {
-		return nil, fmt.Errorf("OTP not found or expired")
+		if errors.Is(err, redis.Nil) {
+			return nil, users.ErrOTPExpired
+		}
+		return nil, fmt.Errorf("failed to get OTP: %w", err)
 	}
 
 	var otp models.OTP
@@ -47,10 +57,54 @@ func (r *UserRepo) GetOTP(ctx context.Context, msisdn, code string) (*models.OTP
 
 // MarkOTPVerified marks an OTP as verified and deletes it from Redis
 func (r *UserRepo) MarkOTPVerified(ctx context.Context, msisdn string, code string) error {
+	return r.InvalidateOTP(ctx, msisdn)
+}
+
+// InvalidateOTP deletes the pending OTP of a phone number along with its failed attempts
+func (r *UserRepo) InvalidateOTP(ctx context.Context, msisdn string) error {
 	key := fmt.Sprintf(constants.KeyUserOTP, msisdn)
-	err := r.redisClient.Delete(ctx, key)
-	if err != nil {
+	if err := r.redisClient.Delete(ctx, key); err != nil {
 		return fmt.Errorf("failed to delete OTP: %w", err)
 	}
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyUserOTPAttempts, msisdn)); err != nil {
+		return fmt.Errorf("failed to delete OTP attempts: %w", err)
+	}
 	return nil
 }
+
+// RecordOTPFailure counts a wrong code submitted for the pending OTP and returns the failures so far.
+// The counter lives no longer than the OTP itself.
+func (r *UserRepo) RecordOTPFailure(ctx context.Context, msisdn string) (int, error) {
+	key := fmt.Sprintf(constants.KeyUserOTPAttempts, msisdn)
+	failures, err := r.redisClient.Incr(ctx, key)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record OTP failure: %w", err)
+	}
+	if failures == 1 {
+		if err := r.redisClient.Expire(ctx, key, otpExpirationTime); err != nil {
+			return 0, fmt.Errorf("failed to expire OTP attempts: %w", err)
+		}
+	}
+	return int(failures), nil
+}
+
+// RecordOTPRequest adds an OTP request to the phone number's rolling window, dropping entries
+// older than the window, and returns how many requests the window now holds
+func (r *UserRepo) RecordOTPRequest(ctx context.Context, msisdn, requestID string, at time.Time, window time.Duration) (int, error) {
+	key := fmt.Sprintf(constants.KeyUserOTPRequests, msisdn)
+	if err := r.redisClient.ZAdd(ctx, key, float64(at.Unix()), requestID); err != nil {
+		return 0, fmt.Errorf("failed to record OTP request: %w", err)
+	}
+	cutoff := strconv.FormatInt(at.Add(-window).Unix(), 10)
+	if err := r.redisClient.ZRemRangeByScore(ctx, key, "-inf", "("+cutoff); err != nil {
+		return 0, fmt.Errorf("failed to trim OTP requests: %w", err)
+	}
+	if err := r.redisClient.Expire(ctx, key, window); err != nil {
+		return 0, fmt.Errorf("failed to expire OTP requests: %w", err)
+	}
+	count, err := r.redisClient.ZCount(ctx, key, "-inf", "+inf")
+	if err != nil {
+		return 0, fmt.Errorf("failed to count OTP requests: %w", err)
+	}
+	return int(count), nil
+}
@@ -15,6 +15,7 @@ import (
 	"github.com/piresc/nebengjek/internal/pkg/constants"
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 )
 
 // setupMiniredis creates a new miniredis server and returns a Redis client connected to it
@@ -178,6 +179,99 @@ func TestGetOTP(t *testing.T) {
 	}
 }
 
+func TestGetOTP_Expired(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	defer mr.Close()
+
+	otp := models.OTP{MSISDN: "628123456789", Code: "123456"}
+	require.NoError(t, repo.CreateOTP(context.Background(), &otp))
+
+	mr.FastForward(otpExpirationTime + time.Second)
+
+	got, err := repo.GetOTP(context.Background(), otp.MSISDN, otp.Code)
+	assert.Nil(t, got)
+	assert.ErrorIs(t, err, users.ErrOTPExpired)
+}
+
+func TestRecordOTPRequest(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	msisdn := "628123456789"
+	now := time.Now()
+
+	count, err := repo.RecordOTPRequest(ctx, msisdn, "req-1", now.Add(-2*time.Minute), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// The first request fell out of the window
+	count, err = repo.RecordOTPRequest(ctx, msisdn, "req-2", now, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	count, err = repo.RecordOTPRequest(ctx, msisdn, "req-3", now, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	// Other numbers have their own window
+	count, err = repo.RecordOTPRequest(ctx, "628123456790", "req-4", now, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	key := fmt.Sprintf(constants.KeyUserOTPRequests, msisdn)
+	assert.True(t, mr.TTL(key) > 0)
+}
+
+func TestRecordOTPRequest_RedisError(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	mr.Close()
+
+	_, err := repo.RecordOTPRequest(context.Background(), "628123456789", "req-1", time.Now(), time.Minute)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to record OTP request")
+}
+
+func TestRecordOTPFailure(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	msisdn := "628123456789"
+
+	failures, err := repo.RecordOTPFailure(ctx, msisdn)
+	require.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	failures, err = repo.RecordOTPFailure(ctx, msisdn)
+	require.NoError(t, err)
+	assert.Equal(t, 2, failures)
+
+	key := fmt.Sprintf(constants.KeyUserOTPAttempts, msisdn)
+	assert.True(t, mr.TTL(key) > 0)
+	assert.True(t, mr.TTL(key) <= otpExpirationTime)
+
+	// A fresh code starts with a clean slate
+	require.NoError(t, repo.CreateOTP(ctx, &models.OTP{MSISDN: msisdn, Code: "123456"}))
+	assert.False(t, mr.Exists(key))
+}
+
+func TestInvalidateOTP(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	defer mr.Close()
+
+	ctx := context.Background()
+	msisdn := "628123456789"
+	require.NoError(t, repo.CreateOTP(ctx, &models.OTP{MSISDN: msisdn, Code: "123456"}))
+	_, err := repo.RecordOTPFailure(ctx, msisdn)
+	require.NoError(t, err)
+
+	require.NoError(t, repo.InvalidateOTP(ctx, msisdn))
+
+	assert.False(t, mr.Exists(fmt.Sprintf(constants.KeyUserOTP, msisdn)))
+	assert.False(t, mr.Exists(fmt.Sprintf(constants.KeyUserOTPAttempts, msisdn)))
+}
+
 func TestMarkOTPVerified(t *testing.T) {
 	testCases := []struct {
 		name      string
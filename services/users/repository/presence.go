@@ -0,0 +1,23 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+)
+
+// IsInMatchingPool reports whether the user is in the available pool for the role, kept by the
+// location service while a driver's beacon or a passenger's finder is on
+func (r *UserRepo) IsInMatchingPool(ctx context.Context, userID, role string) (bool, error) {
+	key := constants.KeyAvailablePassengers
+	if role == "driver" {
+		key = constants.KeyAvailableDrivers
+	}
+
+	inPool, err := r.redisClient.SIsMember(ctx, key, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to check %s pool: %w", role, err)
+	}
+	return inPool, nil
+}
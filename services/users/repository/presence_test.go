@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+)
+
+func TestIsInMatchingPool(t *testing.T) {
+	repo, mr := setupOTPRepoTest(t)
+	defer mr.Close()
+
+	_, err := mr.SAdd(constants.KeyAvailableDrivers, "driver-1")
+	require.NoError(t, err)
+
+	inPool, err := repo.IsInMatchingPool(context.Background(), "driver-1", "driver")
+	assert.NoError(t, err)
+	assert.True(t, inPool)
+
+	// Drivers and passengers have separate pools
+	inPool, err = repo.IsInMatchingPool(context.Background(), "driver-1", "passenger")
+	assert.NoError(t, err)
+	assert.False(t, inPool)
+}
@@ -5,21 +5,32 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	_ "github.com/newrelic/go-agent/v3/integrations/nrpq"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 )
 
+// uniqueViolation is the Postgres error code for a unique constraint violation
+const uniqueViolation = "23505"
+
+// likeEscaper escapes LIKE wildcards and the backslash, Postgres's default LIKE escape character,
+// so search input only matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
 // GetUserByMSISDN retrieves a user by MSISDN
 func (r *UserRepo) GetUserByMSISDN(ctx context.Context, msisdn string) (*models.User, error) {
 	txn := newrelic.FromContext(ctx)
 	dbCtx := newrelic.NewContext(ctx, txn)
 
 	query := `
-		SELECT id, msisdn, fullname, role, created_at, updated_at, is_active
+		SELECT id, msisdn, fullname, role, created_at, updated_at, is_active,
+			gender, driver_gender_preference
 		FROM users
 		WHERE msisdn = $1
 	`
@@ -33,11 +44,13 @@ func (r *UserRepo) GetUserByMSISDN(ctx context.Context, msisdn string) (*models.
 		&user.CreatedAt,
 		&user.UpdatedAt,
 		&user.IsActive,
+		&user.Gender,
+		&user.DriverGenderPreference,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, users.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
@@ -72,12 +85,16 @@ func (r *UserRepo) CreateUser(ctx context.Context, user *models.User) error {
 	// Insert user
 	query := `
 		INSERT INTO users (id, msisdn, fullname, role,
-			created_at, updated_at, is_active
+			created_at, updated_at, is_active, gender, driver_gender_preference
 		) VALUES (:id, :msisdn, :fullname, :role,
-			:created_at, :updated_at, :is_active)
+			:created_at, :updated_at, :is_active, :gender, :driver_gender_preference)
 	`
 	_, err = tx.NamedExecContext(ctx, query, user)
 	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+			return users.ErrAlreadyExists
+		}
 		return fmt.Errorf("failed to insert user: %w", err)
 	}
 
@@ -89,6 +106,54 @@ func (r *UserRepo) CreateUser(ctx context.Context, user *models.User) error {
 	return nil
 }
 
+// ListUsers returns a page of users matching the filter and the total number of matching users
+func (r *UserRepo) ListUsers(ctx context.Context, filter models.UserFilter) ([]*models.User, int, error) {
+	txn := newrelic.FromContext(ctx)
+	dbCtx := newrelic.NewContext(ctx, txn)
+
+	// Filter values are always bound as parameters; only placeholders are formatted into the query
+	var conditions []string
+	var args []interface{}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		conditions = append(conditions, fmt.Sprintf("role = $%d", len(args)))
+	}
+	if filter.IsActive != nil {
+		args = append(args, *filter.IsActive)
+		conditions = append(conditions, fmt.Sprintf("is_active = $%d", len(args)))
+	}
+	if filter.Search != "" {
+		args = append(args, "%"+likeEscaper.Replace(filter.Search)+"%")
+		conditions = append(conditions, fmt.Sprintf("(fullname ILIKE $%d OR msisdn ILIKE $%d)", len(args), len(args)))
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM users %s`, where)
+	if err := r.db.GetContext(dbCtx, &total, countQuery, args...); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, msisdn, fullname, role, created_at, updated_at, is_active
+		FROM users
+		%s
+		ORDER BY created_at DESC
+		LIMIT $%d OFFSET $%d
+	`, where, len(args)+1, len(args)+2)
+
+	users := []*models.User{}
+	if err := r.db.SelectContext(dbCtx, &users, query, append(args, filter.Limit, filter.Offset)...); err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, total, nil
+}
+
 // getUserByField is a helper function to get a user by a specific field
 func (r *UserRepo) getUserByField(ctx context.Context, field, value string) (*models.User, error) {
 	txn := newrelic.FromContext(ctx)
@@ -102,7 +167,7 @@ func (r *UserRepo) getUserByField(ctx context.Context, field, value string) (*mo
 	err := r.db.GetContext(dbCtx, &user, query, value)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("user not found")
+			return nil, users.ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user: %w", err)
 	}
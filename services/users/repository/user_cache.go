@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// defaultUserCacheTTL is used when no user cache TTL is configured
+const defaultUserCacheTTL = 5 * time.Minute
+
+// userCacheTTL returns how long a user record stays cached
+func (r *UserRepo) userCacheTTL() time.Duration {
+	if r.cfg != nil && r.cfg.Users.UserCacheTTLSecs > 0 {
+		return time.Duration(r.cfg.Users.UserCacheTTLSecs) * time.Second
+	}
+	return defaultUserCacheTTL
+}
+
+// getCachedUser returns the cached user with the given ID, or nil on a miss. Cache errors are
+// logged and treated as a miss so users are still read from the database.
+func (r *UserRepo) getCachedUser(ctx context.Context, userID string) *models.User {
+	if r.redisClient == nil {
+		return nil
+	}
+
+	data, err := r.redisClient.Get(ctx, fmt.Sprintf(constants.KeyUserRecord, userID))
+	if err != nil {
+		if !errors.Is(err, redis.Nil) {
+			logger.Warn("Failed to read cached user",
+				logger.String("user_id", userID),
+				logger.ErrorField(err))
+		}
+		return nil
+	}
+
+	var user models.User
+	if err := json.Unmarshal([]byte(data), &user); err != nil {
+		logger.Warn("Failed to decode cached user",
+			logger.String("user_id", userID),
+			logger.ErrorField(err))
+		return nil
+	}
+	return &user
+}
+
+// cacheUser stores a user read from the database, driver info included, for later lookups by ID
+func (r *UserRepo) cacheUser(ctx context.Context, user *models.User) {
+	if r.redisClient == nil {
+		return
+	}
+
+	data, err := json.Marshal(user)
+	if err != nil {
+		return
+	}
+	userID := user.ID.String()
+	if err := r.redisClient.Set(ctx, fmt.Sprintf(constants.KeyUserRecord, userID), data, r.userCacheTTL()); err != nil {
+		logger.Warn("Failed to cache user",
+			logger.String("user_id", userID),
+			logger.ErrorField(err))
+	}
+}
+
+// invalidateCachedUser drops the cached user after the user or their driver info was written,
+// so the next lookup sees the change
+func (r *UserRepo) invalidateCachedUser(ctx context.Context, userID string) {
+	if r.redisClient == nil {
+		return
+	}
+
+	if err := r.redisClient.Delete(ctx, fmt.Sprintf(constants.KeyUserRecord, userID)); err != nil {
+		logger.Warn("Failed to invalidate cached user",
+			logger.String("user_id", userID),
+			logger.ErrorField(err))
+	}
+}
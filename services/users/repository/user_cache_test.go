@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/piresc/nebengjek/internal/pkg/constants"
+	"github.com/piresc/nebengjek/internal/pkg/database"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// expectDriverLookup expects one database read of a driver and their driver info
+func expectDriverLookup(mock sqlmock.Sqlmock, userID uuid.UUID, verified bool) {
+	mock.ExpectQuery("^SELECT \\* FROM users WHERE").
+		WithArgs(userID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active"}).
+			AddRow(userID, "628123456789", "John Doe", "driver", time.Now(), time.Now(), true))
+	mock.ExpectQuery("^SELECT \\* FROM drivers WHERE user_id").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "vehicle_type", "vehicle_plate", "verified"}).
+			AddRow(userID, "motorcycle", "B 1234 ABC", verified))
+}
+
+// setupUserCacheTest returns a repository whose Redis is the returned miniredis
+func setupUserCacheTest(t *testing.T) (*UserRepo, sqlmock.Sqlmock, *miniredis.Miniredis) {
+	repo, mock, cleanup := setupUserRepoTest(t)
+	t.Cleanup(cleanup)
+
+	mr, client := setupMiniredis(t)
+	t.Cleanup(mr.Close)
+	repo.redisClient = &database.RedisClient{Client: client}
+	return repo, mock, mr
+}
+
+func TestGetUserByID_CacheMissThenHit(t *testing.T) {
+	repo, mock, mr := setupUserCacheTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// Only the first lookup goes to the database
+	expectDriverLookup(mock, userID, false)
+
+	first, err := repo.GetUserByID(ctx, userID.String())
+	require.NoError(t, err)
+	assert.True(t, mr.Exists(fmt.Sprintf(constants.KeyUserRecord, userID)))
+	assert.True(t, mr.TTL(fmt.Sprintf(constants.KeyUserRecord, userID)) > 0)
+
+	second, err := repo.GetUserByID(ctx, userID.String())
+	require.NoError(t, err)
+	assert.Equal(t, userID, second.ID)
+	assert.Equal(t, first.FullName, second.FullName)
+	require.NotNil(t, second.DriverInfo, "Driver info is cached with the user")
+	assert.Equal(t, "B 1234 ABC", second.DriverInfo.VehiclePlate)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetUserByID_NotFoundIsNotCached(t *testing.T) {
+	repo, mock, mr := setupUserCacheTest(t)
+	userID := uuid.New().String()
+
+	mock.ExpectQuery("^SELECT \\* FROM users WHERE").
+		WithArgs(userID).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetUserByID(context.Background(), userID)
+
+	assert.Error(t, err)
+	assert.False(t, mr.Exists(fmt.Sprintf(constants.KeyUserRecord, userID)))
+}
+
+func TestGetUserByID_RedisDownFallsBackToDatabase(t *testing.T) {
+	repo, mock, mr := setupUserCacheTest(t)
+	userID := uuid.New()
+	mr.SetError("redis down")
+
+	expectDriverLookup(mock, userID, false)
+
+	user, err := repo.GetUserByID(context.Background(), userID.String())
+
+	require.NoError(t, err)
+	assert.Equal(t, userID, user.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateDriverVerification_InvalidatesCachedUser(t *testing.T) {
+	repo, mock, mr := setupUserCacheTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	expectDriverLookup(mock, userID, false)
+	_, err := repo.GetUserByID(ctx, userID.String())
+	require.NoError(t, err)
+
+	mock.ExpectExec("^UPDATE drivers").
+		WithArgs(true, sqlmock.AnyArg(), userID.String()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	require.NoError(t, repo.UpdateDriverVerification(ctx, userID.String(), true))
+	assert.False(t, mr.Exists(fmt.Sprintf(constants.KeyUserRecord, userID)))
+
+	// The next lookup reads the verified driver from the database
+	expectDriverLookup(mock, userID, true)
+	user, err := repo.GetUserByID(ctx, userID.String())
+	require.NoError(t, err)
+	assert.True(t, user.DriverInfo.Verified)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateToDriver_InvalidatesCachedUser(t *testing.T) {
+	repo, mock, mr := setupUserCacheTest(t)
+	ctx := context.Background()
+	userID := uuid.New()
+
+	// A passenger is looked up and cached
+	mock.ExpectQuery("^SELECT \\* FROM users WHERE").
+		WithArgs(userID.String()).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active"}).
+			AddRow(userID, "628123456789", "John Doe", "passenger", time.Now(), time.Now(), true))
+	passenger, err := repo.GetUserByID(ctx, userID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "passenger", passenger.Role)
+
+	mock.ExpectBegin()
+	mock.ExpectExec("^UPDATE users").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("^INSERT INTO drivers").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	require.NoError(t, repo.UpdateToDriver(ctx, &models.User{
+		ID:         userID,
+		Role:       "driver",
+		DriverInfo: &models.Driver{VehicleType: "motorcycle", VehiclePlate: "B 1234 ABC"},
+	}))
+	assert.False(t, mr.Exists(fmt.Sprintf(constants.KeyUserRecord, userID)))
+
+	// The next lookup sees the new role and driver info
+	expectDriverLookup(mock, userID, false)
+	driver, err := repo.GetUserByID(ctx, userID.String())
+	require.NoError(t, err)
+	assert.Equal(t, "driver", driver.Role)
+	assert.NotNil(t, driver.DriverInfo)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
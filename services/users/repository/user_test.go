@@ -4,17 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"regexp"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/piresc/nebengjek/internal/pkg/database"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 )
 
 func setupUserRepoTest(t *testing.T) (*UserRepo, sqlmock.Sqlmock, func()) {
@@ -25,8 +28,9 @@ func setupUserRepoTest(t *testing.T) (*UserRepo, sqlmock.Sqlmock, func()) {
 	// Create sqlx DB with mock
 	sqlxDB := sqlx.NewDb(mockDB, "sqlmock")
 	
-	// Create a mock Redis client (nil for now as we're not testing Redis operations in user.go)
-	redisClient := &database.RedisClient{}
+	// Redis backs the user cache; each test starts with it empty
+	mr, client := setupMiniredis(t)
+	redisClient := &database.RedisClient{Client: client}
 
 	// Create repo with mocks
 	repo := &UserRepo{
@@ -38,6 +42,7 @@ func setupUserRepoTest(t *testing.T) (*UserRepo, sqlmock.Sqlmock, func()) {
 	// Return cleanup function
 	cleanup := func() {
 		sqlxDB.Close()
+		mr.Close()
 	}
 
 	return repo, mock, cleanup
@@ -56,8 +61,9 @@ func TestGetUserByMSISDN(t *testing.T) {
 			msisdn: "+628123456789",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440000")
-				rows := sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active"}).
-					AddRow(userID, "+628123456789", "John Doe", "user", time.Now(), time.Now(), true)
+				rows := sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active",
+					"gender", "driver_gender_preference"}).
+					AddRow(userID, "+628123456789", "John Doe", "user", time.Now(), time.Now(), true, "female", "female")
 				mock.ExpectQuery("^SELECT (.+) FROM users WHERE msisdn").
 					WithArgs("+628123456789").
 					WillReturnRows(rows)
@@ -69,6 +75,8 @@ func TestGetUserByMSISDN(t *testing.T) {
 				assert.Equal(t, "John Doe", user.FullName)
 				assert.Equal(t, "user", user.Role)
 				assert.True(t, user.IsActive)
+				assert.Equal(t, "female", user.Gender)
+				assert.Equal(t, "female", user.DriverGenderPreference)
 				assert.Nil(t, user.DriverInfo)
 			},
 		},
@@ -77,8 +85,9 @@ func TestGetUserByMSISDN(t *testing.T) {
 			msisdn: "+628123456790",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440001")
-				rows := sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active"}).
-					AddRow(userID, "+628123456790", "Jane Driver", "driver", time.Now(), time.Now(), true)
+				rows := sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active",
+					"gender", "driver_gender_preference"}).
+					AddRow(userID, "+628123456790", "Jane Driver", "driver", time.Now(), time.Now(), true, "", "")
 				mock.ExpectQuery("^SELECT (.+) FROM users WHERE msisdn").
 					WithArgs("+628123456790").
 					WillReturnRows(rows)
@@ -134,8 +143,9 @@ func TestGetUserByMSISDN(t *testing.T) {
 			msisdn: "+628123456791",
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				userID := uuid.MustParse("550e8400-e29b-41d4-a716-446655440002")
-				rows := sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active"}).
-					AddRow(userID, "+628123456791", "Error Driver", "driver", time.Now(), time.Now(), true)
+				rows := sqlmock.NewRows([]string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active",
+					"gender", "driver_gender_preference"}).
+					AddRow(userID, "+628123456791", "Error Driver", "driver", time.Now(), time.Now(), true, "", "")
 				mock.ExpectQuery("^SELECT (.+) FROM users WHERE msisdn").
 					WithArgs("+628123456791").
 					WillReturnRows(rows)
@@ -234,6 +244,24 @@ func TestCreateUser(t *testing.T) {
 				assert.Contains(t, err.Error(), "failed to insert user")
 			},
 		},
+		{
+			name: "Duplicate MSISDN",
+			user: models.User{
+				MSISDN:   "+628123456789",
+				FullName: "John Doe",
+				Role:     "user",
+				IsActive: true,
+			},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec("^INSERT INTO users").
+					WillReturnError(&pq.Error{Code: "23505", Constraint: "users_msisdn_key"})
+				mock.ExpectRollback()
+			},
+			assertFunc: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, users.ErrAlreadyExists)
+			},
+		},
 		{
 			name: "Commit Error",
 			user: models.User{
@@ -385,4 +413,122 @@ func TestGetUserByField(t *testing.T) {
 			assert.NoError(t, mock.ExpectationsWereMet())
 		})
 	}
-}
\ No newline at end of file
+}
+func TestListUsers(t *testing.T) {
+	userColumns := []string{"id", "msisdn", "fullname", "role", "created_at", "updated_at", "is_active"}
+	active := true
+
+	testCases := []struct {
+		name       string
+		filter     models.UserFilter
+		mockSetup  func(mock sqlmock.Sqlmock)
+		assertFunc func(t *testing.T, users []*models.User, total int, err error)
+	}{
+		{
+			name:   "Filter by role",
+			filter: models.UserFilter{Role: "driver", Limit: 20},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users WHERE role = $1")).
+					WithArgs("driver").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+				mock.ExpectQuery(regexp.QuoteMeta("WHERE role = $1")).
+					WithArgs("driver", 20, 0).
+					WillReturnRows(sqlmock.NewRows(userColumns).
+						AddRow(uuid.New(), "+628123456790", "Jane Driver", "driver", time.Now(), time.Now(), true))
+			},
+			assertFunc: func(t *testing.T, users []*models.User, total int, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, total)
+				require.Len(t, users, 1)
+				assert.Equal(t, "driver", users[0].Role)
+			},
+		},
+		{
+			name:   "Filter by active status and search",
+			filter: models.UserFilter{IsActive: &active, Search: "jane", Limit: 10, Offset: 10},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users WHERE is_active = $1 AND (fullname ILIKE $2 OR msisdn ILIKE $2)")).
+					WithArgs(true, "%jane%").
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(11))
+				mock.ExpectQuery(regexp.QuoteMeta("LIMIT $3 OFFSET $4")).
+					WithArgs(true, "%jane%", 10, 10).
+					WillReturnRows(sqlmock.NewRows(userColumns).
+						AddRow(uuid.New(), "+628123456791", "Jane Passenger", "passenger", time.Now(), time.Now(), true))
+			},
+			assertFunc: func(t *testing.T, users []*models.User, total int, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 11, total)
+				require.Len(t, users, 1)
+				assert.True(t, users[0].IsActive)
+			},
+		},
+		{
+			name:   "Search wildcards match literally",
+			filter: models.UserFilter{Search: `50%_off\`, Limit: 20},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users WHERE (fullname ILIKE $1 OR msisdn ILIKE $1)")).
+					WithArgs(`%50\%\_off\\%`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+				mock.ExpectQuery(regexp.QuoteMeta("LIMIT $2 OFFSET $3")).
+					WithArgs(`%50\%\_off\\%`, 20, 0).
+					WillReturnRows(sqlmock.NewRows(userColumns))
+			},
+			assertFunc: func(t *testing.T, users []*models.User, total int, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 0, total)
+				assert.Empty(t, users)
+			},
+		},
+		{
+			name:   "Total counts all pages when page is empty",
+			filter: models.UserFilter{Limit: 20, Offset: 40},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users")).
+					WithArgs().
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(25))
+				mock.ExpectQuery(regexp.QuoteMeta("LIMIT $1 OFFSET $2")).
+					WithArgs(20, 40).
+					WillReturnRows(sqlmock.NewRows(userColumns))
+			},
+			assertFunc: func(t *testing.T, users []*models.User, total int, err error) {
+				assert.NoError(t, err)
+				assert.Equal(t, 25, total)
+				assert.NotNil(t, users)
+				assert.Empty(t, users)
+			},
+		},
+		{
+			name:   "Count error",
+			filter: models.UserFilter{Limit: 20},
+			mockSetup: func(mock sqlmock.Sqlmock) {
+				mock.ExpectQuery(regexp.QuoteMeta("SELECT COUNT(*) FROM users")).
+					WillReturnError(errors.New("db down"))
+			},
+			assertFunc: func(t *testing.T, users []*models.User, total int, err error) {
+				assert.Error(t, err)
+				assert.Nil(t, users)
+				assert.Contains(t, err.Error(), "failed to count users")
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Setup
+			repo, mock, cleanup := setupUserRepoTest(t)
+			defer cleanup()
+
+			// Apply mocks
+			tc.mockSetup(mock)
+
+			// Execute
+			users, total, err := repo.ListUsers(context.Background(), tc.filter)
+
+			// Assert
+			tc.assertFunc(t, users, total, err)
+
+			// Verify all expectations were met
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -2,16 +2,52 @@ package users
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
+// ErrNotRideParticipant is returned when a user acts on a ride they are not actively part of
+var ErrNotRideParticipant = errors.New("user is not a participant of this active ride")
+
+// ErrAlreadyExists is returned when registering a phone number that already belongs to a user
+var ErrAlreadyExists = errors.New("phone number already registered")
+
+// ErrUserNotFound is returned when no user has the requested ID or phone number
+var ErrUserNotFound = errors.New("user not found")
+
+// ErrDriverNotFound is returned when a user is not registered as a driver
+var ErrDriverNotFound = errors.New("driver not found")
+
+// ErrDriverDocumentsMissing is returned when verifying a driver who has not submitted any documents
+var ErrDriverDocumentsMissing = errors.New("driver has not submitted any documents")
+
+// ErrFavoriteNotFound is returned when a favorite location does not exist or belongs to another user
+var ErrFavoriteNotFound = errors.New("favorite location not found")
+
+// ErrFavoriteLimitReached is returned when a user already saved the most favorite locations allowed
+var ErrFavoriteLimitReached = errors.New("favorite location limit reached")
+
+// ErrOTPRateLimited is returned when a phone number requested more OTPs than the window allows
+var ErrOTPRateLimited = errors.New("too many OTP requests")
+
+// ErrOTPExpired is returned when no OTP is pending for a phone number, either never requested or expired
+var ErrOTPExpired = errors.New("OTP not found or expired")
+
+// ErrOTPMismatch is returned when the submitted code differs from the pending OTP
+var ErrOTPMismatch = errors.New("invalid OTP code")
+
+// ErrOTPAttemptsExceeded is returned when too many wrong codes were submitted and the pending OTP was invalidated
+var ErrOTPAttemptsExceeded = errors.New("too many failed OTP attempts")
+
 //go:generate mockgen -destination=mocks/mock_usecase.go -package=mocks github.com/piresc/nebengjek/services/users UserUC
 
 // UserUsecase represents the user usecase interface
 type UserUC interface {
 	RegisterUser(ctx context.Context, user *models.User) error
 	GetUserByID(ctx context.Context, id string) (*models.User, error)
+	ListUsers(ctx context.Context, filter models.UserFilter) (*models.UserList, error)
 
 	// handle OTP
 	GenerateOTP(ctx context.Context, msisdn string) error
@@ -19,6 +55,13 @@ type UserUC interface {
 
 	// register driver
 	RegisterDriver(ctx context.Context, user *models.User) error
+	VerifyDriver(ctx context.Context, driverID string, verified bool) (*models.User, error)
+	GetDriverOnlineTime(ctx context.Context, driverID string, day time.Time) (*models.DriverOnlineSummary, error)
+	EndEvictedDriverSession(ctx context.Context, eviction *models.DriverEviction) error
+	GetUserPresence(ctx context.Context, userID string) (*models.UserPresence, error)
+
+	// broadcast system announcements
+	Announce(ctx context.Context, req *models.AnnouncementRequest) (*models.Announcement, error)
 
 	// handle match
 	UpdateBeaconStatus(ctx context.Context, beaconReq *models.BeaconRequest) error
@@ -26,12 +69,26 @@ type UserUC interface {
 
 	// handle match confirmation
 	ConfirmMatch(ctx context.Context, mp *models.MatchConfirmRequest) (*models.MatchProposal, error)
+	GetDriverMatches(ctx context.Context, driverID string, limit, offset int) (*models.MatchHistory, error)
+
+	// handle ride chat
+	SendChatMessage(ctx context.Context, senderID, role string, msg *models.ChatMessage) (*models.ChatMessage, error)
+	GetChatHistory(ctx context.Context, userID, role, rideID string) ([]*models.ChatMessage, error)
 
 	// handle location
 	UpdateUserLocation(ctx context.Context, location *models.LocationUpdate) error
 
+	// handle favorite locations
+	CreateFavoriteLocation(ctx context.Context, userID string, req *models.FavoriteLocationRequest) (*models.FavoriteLocation, error)
+	ListFavoriteLocations(ctx context.Context, userID string) ([]*models.FavoriteLocation, error)
+	UpdateFavoriteLocation(ctx context.Context, userID, favoriteID string, req *models.FavoriteLocationRequest) (*models.FavoriteLocation, error)
+	DeleteFavoriteLocation(ctx context.Context, userID, favoriteID string) error
+
 	// handle ride events
 	RideStart(ctx context.Context, event *models.RideStartRequest) (*models.Ride, error)
 	RideArrived(ctx context.Context, req *models.RideArrivalReq) (*models.PaymentRequest, error)
 	ProcessPayment(ctx context.Context, paymentReq *models.PaymentProccessRequest) (*models.Payment, error)
+	CheckRideParticipant(ctx context.Context, userID, role, rideID string) error
+	GetPickupCode(ctx context.Context, userID, role, rideID string) (*models.PickupCodeResponse, error)
+	GetRidePickupCode(ctx context.Context, passengerID, rideID string) (*models.PickupCodeResponse, error)
 }
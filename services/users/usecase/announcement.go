@@ -0,0 +1,28 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// Announce publishes an operator announcement for every users service instance to broadcast to
+// its connected users, limited to the requested roles if any
+func (uc *UserUC) Announce(ctx context.Context, req *models.AnnouncementRequest) (*models.Announcement, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	announcement := &models.Announcement{
+		ID:        uuid.New().String(),
+		Message:   req.Message,
+		Roles:     req.Roles,
+		CreatedAt: time.Now(),
+	}
+	if err := uc.UserGW.PublishAnnouncement(ctx, announcement); err != nil {
+		return nil, err
+	}
+	return announcement, nil
+}
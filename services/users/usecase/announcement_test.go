@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnnounce_Publishes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mocks.NewMockUserRepo(ctrl), mockGW, &models.Config{})
+
+	var published *models.Announcement
+	mockGW.EXPECT().PublishAnnouncement(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, announcement *models.Announcement) error {
+			published = announcement
+			return nil
+		})
+
+	announcement, err := uc.Announce(context.Background(), &models.AnnouncementRequest{
+		Message: "  Service resuming\n",
+		Roles:   []string{"driver"},
+	})
+
+	require.NoError(t, err)
+	assert.Same(t, published, announcement)
+	assert.NotEmpty(t, announcement.ID)
+	assert.Equal(t, "Service resuming", announcement.Message)
+	assert.Equal(t, []string{"driver"}, announcement.Roles)
+	assert.False(t, announcement.CreatedAt.IsZero())
+}
+
+func TestAnnounce_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   models.AnnouncementRequest
+		field string
+	}{
+		{name: "Empty message", req: models.AnnouncementRequest{Message: " \n "}, field: "message"},
+		{name: "Unknown role", req: models.AnnouncementRequest{Message: "Hello", Roles: []string{"admin"}}, field: "roles"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			// Nothing is published for an invalid request
+			uc := NewUserUC(mocks.NewMockUserRepo(ctrl), mocks.NewMockUserGW(ctrl), &models.Config{})
+
+			_, err := uc.Announce(context.Background(), &tt.req)
+
+			var fieldErr *models.FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			assert.Equal(t, tt.field, fieldErr.Field)
+		})
+	}
+}
+
+func TestAnnounce_PublishError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mocks.NewMockUserRepo(ctrl), mockGW, &models.Config{})
+	mockGW.EXPECT().PublishAnnouncement(gomock.Any(), gomock.Any()).Return(errors.New("nats: connection closed"))
+
+	announcement, err := uc.Announce(context.Background(), &models.AnnouncementRequest{Message: "Service resuming"})
+
+	assert.Error(t, err)
+	assert.Nil(t, announcement)
+}
@@ -7,12 +7,45 @@ import (
 
 	"github.com/google/uuid"
 	jwtpkg "github.com/piresc/nebengjek/internal/pkg/jwt"
-	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/users"
 )
 
-// GenerateOTP generates a new OTP for the given MSISDN
+// otpCodeLength is the number of digits in a login OTP
+const otpCodeLength = 6
+
+// Defaults for the per-MSISDN OTP request limit when none is configured
+const (
+	defaultOTPRequestLimit  = 5
+	defaultOTPRequestWindow = time.Minute
+)
+
+// defaultOTPMaxAttempts is how many wrong codes invalidate the pending OTP when none is configured
+const defaultOTPMaxAttempts = 5
+
+// otpMaxAttempts returns how many wrong codes are accepted before the pending OTP is invalidated
+func (u *UserUC) otpMaxAttempts() int {
+	if u.cfg != nil && u.cfg.Users.OTPMaxAttempts > 0 {
+		return u.cfg.Users.OTPMaxAttempts
+	}
+	return defaultOTPMaxAttempts
+}
+
+// otpRequestLimit returns how many OTPs a phone number can request per window, falling back to the defaults
+func (u *UserUC) otpRequestLimit() (int, time.Duration) {
+	limit, window := defaultOTPRequestLimit, defaultOTPRequestWindow
+	if u.cfg != nil && u.cfg.Users.OTPRequestLimit > 0 {
+		limit = u.cfg.Users.OTPRequestLimit
+	}
+	if u.cfg != nil && u.cfg.Users.OTPRequestWindowSecs > 0 {
+		window = time.Duration(u.cfg.Users.OTPRequestWindowSecs) * time.Second
+	}
+	return limit, window
+}
+
+// GenerateOTP generates a new OTP for the given MSISDN and hands it to the OTP sender.
+// Returns users.ErrOTPRateLimited once the phone number used up its requests for the window.
 func (u *UserUC) GenerateOTP(ctx context.Context, msisdn string) error {
 	// Validate MSISDN format and check if it's a Telkomsel number
 	isValid, formattedMSISDN, err := utils.ValidateMSISDN(msisdn)
@@ -20,31 +53,43 @@ func (u *UserUC) GenerateOTP(ctx context.Context, msisdn string) error {
 		return fmt.Errorf("invalid MSISDN format or not a Telkomsel number")
 	}
 
-	// Generate dummy OTP using the last 4 digits of the MSISDN
-	code := utils.GenerateDummyOTP(formattedMSISDN)
+	// Rejected requests count too, so hammering the endpoint doesn't reopen the window
+	otpID := uuid.New().String()
+	limit, window := u.otpRequestLimit()
+	requests, err := u.userRepo.RecordOTPRequest(ctx, formattedMSISDN, otpID, time.Now(), window)
+	if err != nil {
+		return fmt.Errorf("failed to check OTP rate limit: %w", err)
+	}
+	if requests > limit {
+		return users.ErrOTPRateLimited
+	}
+
+	code, err := utils.GenerateRandomDigits(otpCodeLength)
+	if err != nil {
+		return fmt.Errorf("failed to generate OTP: %w", err)
+	}
 
 	// Create OTP record
 	otp := &models.OTP{
-		ID:     uuid.New().String(),
+		ID:     otpID,
 		MSISDN: formattedMSISDN,
 		Code:   code,
 	}
 
-	// Save OTP to database
+	// Save OTP to database, replacing any code still pending
 	if err := u.userRepo.CreateOTP(ctx, otp); err != nil {
 		return fmt.Errorf("failed to create OTP: %w", err)
 	}
 
-	// In a real implementation, we would integrate with Telkomsel's SMS API
-	// For now, we'll just log it
-	logger.Info("Generated OTP",
-		logger.String("msisdn", formattedMSISDN),
-		logger.String("otp_code", code))
+	if err := u.UserGW.SendOTP(ctx, formattedMSISDN, code); err != nil {
+		return fmt.Errorf("failed to send OTP: %w", err)
+	}
 
 	return nil
 }
 
-// VerifyOTP verifies the OTP for the given MSISDN
+// VerifyOTP verifies the OTP for the given MSISDN. Each wrong code counts against the pending OTP,
+// which is invalidated with users.ErrOTPAttemptsExceeded once the attempts run out.
 func (u *UserUC) VerifyOTP(ctx context.Context, msisdn, code string) (*models.AuthResponse, error) {
 	// Validate MSISDN format
 	isValid, formattedMSISDN, err := utils.ValidateMSISDN(msisdn)
@@ -58,10 +103,20 @@ func (u *UserUC) VerifyOTP(ctx context.Context, msisdn, code string) (*models.Au
 		return nil, fmt.Errorf("invalid OTP: %w", err)
 	}
 	if otp == nil {
-		return nil, fmt.Errorf("OTP not found or expired")
+		return nil, users.ErrOTPExpired
 	}
 	if otp.Code != code {
-		return nil, fmt.Errorf("invalid OTP code")
+		failures, err := u.userRepo.RecordOTPFailure(ctx, formattedMSISDN)
+		if err != nil {
+			return nil, fmt.Errorf("failed to record OTP failure: %w", err)
+		}
+		if failures >= u.otpMaxAttempts() {
+			if err := u.userRepo.InvalidateOTP(ctx, formattedMSISDN); err != nil {
+				return nil, fmt.Errorf("failed to invalidate OTP: %w", err)
+			}
+			return nil, users.ErrOTPAttemptsExceeded
+		}
+		return nil, users.ErrOTPMismatch
 	}
 
 	// Get or create user
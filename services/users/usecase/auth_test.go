@@ -9,6 +9,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 	"github.com/piresc/nebengjek/services/users/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -26,11 +27,22 @@ func TestGenerateOTP_Success(t *testing.T) {
 	formattedMSISDN := "6281234567890" // Corrected: Added trailing zero to match implementation
 
 	// Expectations
+	var sentCode string
+	mockRepo.EXPECT().
+		RecordOTPRequest(gomock.Any(), formattedMSISDN, gomock.Any(), gomock.Any(), time.Minute).
+		Return(1, nil)
 	mockRepo.EXPECT().
 		CreateOTP(gomock.Any(), gomock.Any()).
 		DoAndReturn(func(ctx context.Context, otp *models.OTP) error {
 			assert.Equal(t, formattedMSISDN, otp.MSISDN, "MSISDN should be formatted")
-			// Just to make the test pass - the implementation will use the last 4 digits
+			assert.Len(t, otp.Code, 6)
+			sentCode = otp.Code
+			return nil
+		})
+	mockGW.EXPECT().
+		SendOTP(gomock.Any(), formattedMSISDN, gomock.Any()).
+		DoAndReturn(func(ctx context.Context, msisdn, code string) error {
+			assert.Equal(t, sentCode, code, "The stored code should be the one sent")
 			return nil
 		})
 
@@ -88,6 +100,9 @@ func TestGenerateOTP_CreateOTPError(t *testing.T) {
 	expectedError := errors.New("database connection error")
 
 	// Expectations
+	mockRepo.EXPECT().
+		RecordOTPRequest(gomock.Any(), formattedMSISDN, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(1, nil)
 	mockRepo.EXPECT().
 		CreateOTP(gomock.Any(), gomock.Any()).
 		DoAndReturn(func(ctx context.Context, otp *models.OTP) error {
@@ -107,6 +122,62 @@ func TestGenerateOTP_CreateOTPError(t *testing.T) {
 	assert.Contains(t, err.Error(), "failed to create OTP")
 }
 
+func TestGenerateOTP_RateLimited(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	msisdn := "081234567890"
+	formattedMSISDN := "6281234567890"
+
+	// Third request within a window that allows two: no code is stored or sent
+	mockRepo.EXPECT().
+		RecordOTPRequest(gomock.Any(), formattedMSISDN, gomock.Any(), gomock.Any(), 30*time.Second).
+		Return(3, nil)
+
+	cfg := &models.Config{
+		Users: models.UsersConfig{OTPRequestLimit: 2, OTPRequestWindowSecs: 30},
+	}
+	uc := NewUserUC(mockRepo, mockGW, cfg)
+
+	// Act
+	err := uc.GenerateOTP(context.Background(), msisdn)
+
+	// Assert
+	assert.ErrorIs(t, err, users.ErrOTPRateLimited)
+}
+
+func TestGenerateOTP_SendError(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	formattedMSISDN := "6281234567890"
+
+	mockRepo.EXPECT().
+		RecordOTPRequest(gomock.Any(), formattedMSISDN, gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(1, nil)
+	mockRepo.EXPECT().CreateOTP(gomock.Any(), gomock.Any()).Return(nil)
+	mockGW.EXPECT().
+		SendOTP(gomock.Any(), formattedMSISDN, gomock.Any()).
+		Return(errors.New("sms provider down"))
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	// Act
+	err := uc.GenerateOTP(context.Background(), "081234567890")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to send OTP")
+}
+
 func TestVerifyOTP_Success_ExistingUser(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -340,6 +411,9 @@ func TestVerifyOTP_OTPCodeMismatch(t *testing.T) {
 	mockRepo.EXPECT().
 		GetOTP(gomock.Any(), formattedMSISDN, code).
 		Return(otp, nil)
+	mockRepo.EXPECT().
+		RecordOTPFailure(gomock.Any(), formattedMSISDN).
+		Return(1, nil)
 
 	// Create usecase with mocked dependencies
 	cfg := &models.Config{}
@@ -352,6 +426,71 @@ func TestVerifyOTP_OTPCodeMismatch(t *testing.T) {
 	assert.Error(t, err)
 	assert.Nil(t, response)
 	assert.Contains(t, err.Error(), "invalid OTP code")
+	assert.ErrorIs(t, err, users.ErrOTPMismatch)
+}
+
+func TestVerifyOTP_AttemptsExceeded(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	msisdn := "081234567890"
+	formattedMSISDN := "6281234567890"
+	otp := &models.OTP{
+		ID:     uuid.New().String(),
+		MSISDN: formattedMSISDN,
+		Code:   "5678",
+	}
+
+	// The third wrong code invalidates the pending OTP
+	mockRepo.EXPECT().
+		GetOTP(gomock.Any(), formattedMSISDN, "1234").
+		Return(otp, nil)
+	mockRepo.EXPECT().
+		RecordOTPFailure(gomock.Any(), formattedMSISDN).
+		Return(3, nil)
+	mockRepo.EXPECT().
+		InvalidateOTP(gomock.Any(), formattedMSISDN).
+		Return(nil)
+
+	cfg := &models.Config{Users: models.UsersConfig{OTPMaxAttempts: 3}}
+	uc := NewUserUC(mockRepo, mockGW, cfg)
+
+	// Act
+	response, err := uc.VerifyOTP(context.Background(), msisdn, "1234")
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, users.ErrOTPAttemptsExceeded)
+}
+
+func TestVerifyOTP_Expired(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	formattedMSISDN := "6281234567890"
+	code := "123456"
+
+	// The OTP's TTL ran out, so nothing is pending for the number
+	mockRepo.EXPECT().
+		GetOTP(gomock.Any(), formattedMSISDN, code).
+		Return(nil, users.ErrOTPExpired)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	// Act
+	response, err := uc.VerifyOTP(context.Background(), "081234567890", code)
+
+	// Assert
+	assert.Nil(t, response)
+	assert.ErrorIs(t, err, users.ErrOTPExpired)
 }
 
 func TestVerifyOTP_CreateUserError(t *testing.T) {
@@ -2,8 +2,10 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/piresc/nebengjek/internal/pkg/logger"
 	"github.com/piresc/nebengjek/internal/pkg/models"
 )
 
@@ -15,16 +17,36 @@ func (uc *UserUC) UpdateBeaconStatus(ctx context.Context, beaconReq *models.Beac
 		return err
 	}
 
-	// Create and publish beacon event
+	// Only verified drivers may enter the available driver pool
+	if beaconReq.IsActive && (user.DriverInfo == nil || !user.DriverInfo.Verified) {
+		return fmt.Errorf("driver is not verified")
+	}
+
+	// Create and publish beacon event; the location is as fresh as the request reporting it
+	now := time.Now()
 	beaconEvent := &models.BeaconEvent{
 		UserID:   user.ID.String(),
 		IsActive: beaconReq.IsActive,
 		Location: models.Location{
 			Latitude:  beaconReq.Latitude,
 			Longitude: beaconReq.Longitude,
+			Timestamp: now,
 		},
-		Timestamp: time.Now(),
+		Timestamp:     now,
+		AcceptingNext: beaconReq.AcceptingNext,
+		Gender:        user.Gender,
+	}
+
+	if err := uc.UserGW.PublishBeaconEvent(ctx, beaconEvent); err != nil {
+		return err
 	}
 
-	return uc.UserGW.PublishBeaconEvent(ctx, beaconEvent)
+	// Online time is reporting data, so failing to record it must not undo the beacon
+	if err := uc.recordOnlineTransition(ctx, beaconEvent); err != nil {
+		logger.Warn("Failed to record driver online time",
+			logger.String("driver_id", beaconEvent.UserID),
+			logger.Bool("is_active", beaconEvent.IsActive),
+			logger.ErrorField(err))
+	}
+	return nil
 }
@@ -42,10 +42,23 @@ func TestUpdateBeaconStatus_Success(t *testing.T) {
 		MSISDN:   "+628123456789",
 		FullName: "Test User",
 		Role:     "driver",
+		DriverInfo: &models.Driver{
+			VehicleType:  "motorcycle",
+			VehiclePlate: "B 1234 ABC",
+			Verified:     true,
+		},
 	}
 
 	mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), "+628123456789").Return(expectedUser, nil)
-	mockGW.EXPECT().PublishBeaconEvent(gomock.Any(), gomock.Any()).Return(nil)
+	mockGW.EXPECT().
+		PublishBeaconEvent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event *models.BeaconEvent) error {
+			// The match service judges staleness by the location's timestamp
+			assert.False(t, event.Location.Timestamp.IsZero())
+			assert.Equal(t, event.Timestamp, event.Location.Timestamp)
+			return nil
+		})
+	mockRepo.EXPECT().StartOnlineSession(gomock.Any(), expectedUser.ID.String(), gomock.Any()).Return(nil)
 
 	// Act
 	err := uc.UpdateBeaconStatus(context.Background(), request)
@@ -84,6 +97,11 @@ func TestUpdateBeaconStatus_GatewayError(t *testing.T) {
 		MSISDN:   "+628123456789",
 		FullName: "Test User",
 		Role:     "driver",
+		DriverInfo: &models.Driver{
+			VehicleType:  "motorcycle",
+			VehiclePlate: "B 1234 ABC",
+			Verified:     true,
+		},
 	}
 
 	expectedError := errors.New("gateway error")
@@ -169,6 +187,7 @@ func TestUpdateBeaconStatus_DeactivateBeacon(t *testing.T) {
 
 	mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), "+628123456789").Return(expectedUser, nil)
 	mockGW.EXPECT().PublishBeaconEvent(gomock.Any(), gomock.Any()).Return(nil)
+	mockRepo.EXPECT().EndOnlineSession(gomock.Any(), expectedUser.ID.String(), gomock.Any()).Return(true, nil)
 
 	// Act
 	err := uc.UpdateBeaconStatus(context.Background(), request)
@@ -176,3 +195,57 @@ func TestUpdateBeaconStatus_DeactivateBeacon(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 }
+
+func TestUpdateBeaconStatus_UnverifiedDriverNotAddedToPool(t *testing.T) {
+	tests := []struct {
+		name       string
+		driverInfo *models.Driver
+	}{
+		{
+			name: "unverified driver",
+			driverInfo: &models.Driver{
+				VehicleType:  "motorcycle",
+				VehiclePlate: "B 1234 ABC",
+				Documents:    []string{"https://docs.nebengjek.com/sim.jpg"},
+				Verified:     false,
+			},
+		},
+		{
+			name:       "missing driver info",
+			driverInfo: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockUserRepo(ctrl)
+			mockGW := mocks.NewMockUserGW(ctrl)
+
+			uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+			user := &models.User{
+				ID:         uuid.New(),
+				MSISDN:     "+628123456789",
+				Role:       "driver",
+				DriverInfo: tt.driverInfo,
+			}
+
+			mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), "+628123456789").Return(user, nil)
+			// The beacon must never be published, so the driver never reaches the pool
+			mockGW.EXPECT().PublishBeaconEvent(gomock.Any(), gomock.Any()).Times(0)
+
+			err := uc.UpdateBeaconStatus(context.Background(), &models.BeaconRequest{
+				MSISDN:    "+628123456789",
+				IsActive:  true,
+				Latitude:  -6.2088,
+				Longitude: 106.8456,
+			})
+
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), "driver is not verified")
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
+)
+
+// SendChatMessage validates that sender and recipient share the same active ride and records the message
+func (u *UserUC) SendChatMessage(ctx context.Context, senderID, role string, msg *models.ChatMessage) (*models.ChatMessage, error) {
+	if err := u.checkRideParticipant(ctx, senderID, role, msg.RideID); err != nil {
+		return nil, err
+	}
+
+	// The recipient must hold the opposite role on the same ride
+	recipientRole := "driver"
+	if role == "driver" {
+		recipientRole = "passenger"
+	}
+	if err := u.checkRideParticipant(ctx, msg.RecipientID, recipientRole, msg.RideID); err != nil {
+		return nil, err
+	}
+
+	msg.SenderID = senderID
+	msg.SentAt = time.Now()
+
+	// History only helps reconnecting clients, so a storage failure must not block delivery
+	if err := u.userRepo.SaveChatMessage(ctx, msg); err != nil {
+		logger.Warn("Failed to store chat message",
+			logger.String("ride_id", msg.RideID),
+			logger.String("sender_id", senderID),
+			logger.ErrorField(err))
+	}
+
+	return msg, nil
+}
+
+// GetChatHistory returns the recent chat messages of the user's active ride
+func (u *UserUC) GetChatHistory(ctx context.Context, userID, role, rideID string) ([]*models.ChatMessage, error) {
+	if err := u.checkRideParticipant(ctx, userID, role, rideID); err != nil {
+		return nil, err
+	}
+
+	return u.userRepo.GetChatHistory(ctx, rideID)
+}
+
+// checkRideParticipant ensures the user is currently on the given ride
+func (u *UserUC) checkRideParticipant(ctx context.Context, userID, role, rideID string) error {
+	activeRideID, err := u.UserGW.GetActiveRideID(ctx, userID, role)
+	if err != nil {
+		return err
+	}
+	if activeRideID == "" || activeRideID != rideID {
+		return users.ErrNotRideParticipant
+	}
+	return nil
+}
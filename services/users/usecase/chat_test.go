@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSendChatMessage_DriverToPassenger(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	driverID := uuid.New().String()
+	passengerID := uuid.New().String()
+	rideID := uuid.New().String()
+
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), driverID, "driver").Return(rideID, nil)
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), passengerID, "passenger").Return(rideID, nil)
+	mockRepo.EXPECT().SaveChatMessage(gomock.Any(), gomock.Any()).Return(nil)
+
+	// Act
+	msg, err := uc.SendChatMessage(context.Background(), driverID, "driver", &models.ChatMessage{
+		RideID:      rideID,
+		RecipientID: passengerID,
+		Message:     "On my way",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, driverID, msg.SenderID)
+	assert.Equal(t, passengerID, msg.RecipientID)
+	assert.False(t, msg.SentAt.IsZero())
+}
+
+func TestSendChatMessage_SenderNotOnRide(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	senderID := uuid.New().String()
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), senderID, "driver").Return(uuid.New().String(), nil)
+
+	// Act
+	msg, err := uc.SendChatMessage(context.Background(), senderID, "driver", &models.ChatMessage{
+		RideID:      uuid.New().String(),
+		RecipientID: uuid.New().String(),
+		Message:     "hello",
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, users.ErrNotRideParticipant)
+	assert.Nil(t, msg)
+}
+
+func TestSendChatMessage_RecipientNotOnRide(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	passengerID := uuid.New().String()
+	strangerID := uuid.New().String()
+	rideID := uuid.New().String()
+
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), passengerID, "passenger").Return(rideID, nil)
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), strangerID, "driver").Return("", nil)
+
+	// Act
+	_, err := uc.SendChatMessage(context.Background(), passengerID, "passenger", &models.ChatMessage{
+		RideID:      rideID,
+		RecipientID: strangerID,
+		Message:     "hello",
+	})
+
+	// Assert
+	assert.ErrorIs(t, err, users.ErrNotRideParticipant)
+}
+
+func TestSendChatMessage_StoreFailureStillDelivers(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	driverID := uuid.New().String()
+	passengerID := uuid.New().String()
+	rideID := uuid.New().String()
+
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), driverID, "driver").Return(rideID, nil)
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), passengerID, "passenger").Return(rideID, nil)
+	mockRepo.EXPECT().SaveChatMessage(gomock.Any(), gomock.Any()).Return(errors.New("redis down"))
+
+	// Act
+	msg, err := uc.SendChatMessage(context.Background(), driverID, "driver", &models.ChatMessage{
+		RideID:      rideID,
+		RecipientID: passengerID,
+		Message:     "On my way",
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.NotNil(t, msg)
+}
+
+func TestGetChatHistory_NonParticipant(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	userID := uuid.New().String()
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), userID, "passenger").Return("", nil)
+
+	// Act
+	messages, err := uc.GetChatHistory(context.Background(), userID, "passenger", uuid.New().String())
+
+	// Assert
+	assert.ErrorIs(t, err, users.ErrNotRideParticipant)
+	assert.Nil(t, messages)
+}
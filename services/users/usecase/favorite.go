@@ -0,0 +1,97 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// defaultMaxFavoriteLocations is used when no favorite location limit is configured
+const defaultMaxFavoriteLocations = 10
+
+// maxFavoriteLocations returns how many favorite locations a user can save, falling back to the default
+func (u *UserUC) maxFavoriteLocations() int {
+	if u.cfg != nil && u.cfg.Users.MaxFavoriteLocations > 0 {
+		return u.cfg.Users.MaxFavoriteLocations
+	}
+	return defaultMaxFavoriteLocations
+}
+
+// CreateFavoriteLocation saves a place the user can later search towards, up to the configured limit
+func (u *UserUC) CreateFavoriteLocation(ctx context.Context, userID string, req *models.FavoriteLocationRequest) (*models.FavoriteLocation, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	userUUID, err := uuid.Parse(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	favorite := &models.FavoriteLocation{
+		ID:        uuid.New(),
+		UserID:    userUUID,
+		Label:     strings.TrimSpace(req.Label),
+		Latitude:  req.Latitude,
+		Longitude: req.Longitude,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	if err := u.userRepo.CreateFavoriteLocation(ctx, favorite, u.maxFavoriteLocations()); err != nil {
+		return nil, err
+	}
+	return favorite, nil
+}
+
+// ListFavoriteLocations returns the user's favorite locations
+func (u *UserUC) ListFavoriteLocations(ctx context.Context, userID string) ([]*models.FavoriteLocation, error) {
+	return u.userRepo.ListFavoriteLocations(ctx, userID)
+}
+
+// UpdateFavoriteLocation replaces the label and coordinates of one of the user's favorite locations
+func (u *UserUC) UpdateFavoriteLocation(ctx context.Context, userID, favoriteID string, req *models.FavoriteLocationRequest) (*models.FavoriteLocation, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+
+	favorite, err := u.userRepo.GetFavoriteLocation(ctx, userID, favoriteID)
+	if err != nil {
+		return nil, err
+	}
+
+	favorite.Label = strings.TrimSpace(req.Label)
+	favorite.Latitude = req.Latitude
+	favorite.Longitude = req.Longitude
+	favorite.UpdatedAt = time.Now()
+
+	if err := u.userRepo.UpdateFavoriteLocation(ctx, favorite); err != nil {
+		return nil, err
+	}
+	return favorite, nil
+}
+
+// DeleteFavoriteLocation removes one of the user's favorite locations
+func (u *UserUC) DeleteFavoriteLocation(ctx context.Context, userID, favoriteID string) error {
+	return u.userRepo.DeleteFavoriteLocation(ctx, userID, favoriteID)
+}
+
+// resolveTargetFavorite fills in a finder request's target location from the passenger's favorite
+func (u *UserUC) resolveTargetFavorite(ctx context.Context, userID string, finderReq *models.FinderRequest) error {
+	if finderReq.TargetFavoriteID == "" {
+		return nil
+	}
+
+	favorite, err := u.userRepo.GetFavoriteLocation(ctx, userID, finderReq.TargetFavoriteID)
+	if err != nil {
+		return fmt.Errorf("failed to get target favorite: %w", err)
+	}
+
+	finderReq.TargetLocation = favorite.Location()
+	return nil
+}
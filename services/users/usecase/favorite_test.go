@@ -0,0 +1,162 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFavoriteLocation_AppliesConfiguredCap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	cfg := &models.Config{Users: models.UsersConfig{MaxFavoriteLocations: 3}}
+	uc := NewUserUC(mockRepo, mocks.NewMockUserGW(ctrl), cfg)
+
+	userID := uuid.New()
+	mockRepo.EXPECT().
+		CreateFavoriteLocation(gomock.Any(), gomock.Any(), 3).
+		DoAndReturn(func(_ context.Context, favorite *models.FavoriteLocation, _ int) error {
+			assert.Equal(t, userID, favorite.UserID)
+			assert.Equal(t, "Home", favorite.Label)
+			return nil
+		})
+
+	favorite, err := uc.CreateFavoriteLocation(context.Background(), userID.String(), &models.FavoriteLocationRequest{
+		Label:     "  Home ",
+		Latitude:  -6.2088,
+		Longitude: 106.8456,
+	})
+
+	require.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, favorite.ID)
+}
+
+func TestCreateFavoriteLocation_CapReached(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	uc := NewUserUC(mockRepo, mocks.NewMockUserGW(ctrl), &models.Config{})
+
+	mockRepo.EXPECT().
+		CreateFavoriteLocation(gomock.Any(), gomock.Any(), defaultMaxFavoriteLocations).
+		Return(users.ErrFavoriteLimitReached)
+
+	_, err := uc.CreateFavoriteLocation(context.Background(), uuid.New().String(), &models.FavoriteLocationRequest{
+		Label:     "Gym",
+		Latitude:  -6.2088,
+		Longitude: 106.8456,
+	})
+
+	assert.ErrorIs(t, err, users.ErrFavoriteLimitReached)
+}
+
+func TestCreateFavoriteLocation_RejectsInvalidCoordinates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := NewUserUC(mocks.NewMockUserRepo(ctrl), mocks.NewMockUserGW(ctrl), &models.Config{})
+
+	_, err := uc.CreateFavoriteLocation(context.Background(), uuid.New().String(), &models.FavoriteLocationRequest{
+		Label:     "Home",
+		Latitude:  -95,
+		Longitude: 106.8456,
+	})
+
+	var fieldErr *models.FieldError
+	require.True(t, errors.As(err, &fieldErr))
+	assert.Equal(t, "location", fieldErr.Field)
+}
+
+func TestUpdateFavoriteLocation_ReplacesLabelAndCoordinates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	uc := NewUserUC(mockRepo, mocks.NewMockUserGW(ctrl), &models.Config{})
+
+	existing := &models.FavoriteLocation{ID: uuid.New(), UserID: uuid.New(), Label: "Work", Latitude: -6.1, Longitude: 106.7}
+	userID, favoriteID := existing.UserID.String(), existing.ID.String()
+
+	mockRepo.EXPECT().GetFavoriteLocation(gomock.Any(), userID, favoriteID).Return(existing, nil)
+	mockRepo.EXPECT().
+		UpdateFavoriteLocation(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, favorite *models.FavoriteLocation) error {
+			assert.Equal(t, "New office", favorite.Label)
+			assert.Equal(t, -6.2, favorite.Latitude)
+			assert.Equal(t, 106.8, favorite.Longitude)
+			return nil
+		})
+
+	favorite, err := uc.UpdateFavoriteLocation(context.Background(), userID, favoriteID, &models.FavoriteLocationRequest{
+		Label:     "New office",
+		Latitude:  -6.2,
+		Longitude: 106.8,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, existing.ID, favorite.ID)
+}
+
+func TestUpdateFinderStatus_FillsTargetFromFavorite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	user := &models.User{ID: uuid.New(), MSISDN: "+628123456789", Role: "passenger"}
+	home := &models.FavoriteLocation{ID: uuid.New(), UserID: user.ID, Label: "Home", Latitude: -6.1751, Longitude: 106.8650}
+
+	mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), user.MSISDN).Return(user, nil)
+	mockRepo.EXPECT().GetFavoriteLocation(gomock.Any(), user.ID.String(), home.ID.String()).Return(home, nil)
+	mockGW.EXPECT().
+		PublishFinderEvent(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event *models.FinderEvent) error {
+			assert.Equal(t, models.Location{Latitude: -6.1751, Longitude: 106.8650}, event.TargetLocation)
+			return nil
+		})
+
+	err := uc.UpdateFinderStatus(context.Background(), &models.FinderRequest{
+		MSISDN:           user.MSISDN,
+		IsActive:         true,
+		Location:         models.Location{Latitude: -6.2088, Longitude: 106.8456},
+		TargetFavoriteID: home.ID.String(),
+	})
+
+	assert.NoError(t, err)
+}
+
+func TestUpdateFinderStatus_UnknownFavorite(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	uc := NewUserUC(mockRepo, mocks.NewMockUserGW(ctrl), &models.Config{})
+
+	user := &models.User{ID: uuid.New(), MSISDN: "+628123456789", Role: "passenger"}
+	favoriteID := uuid.New().String()
+
+	mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), user.MSISDN).Return(user, nil)
+	mockRepo.EXPECT().GetFavoriteLocation(gomock.Any(), user.ID.String(), favoriteID).Return(nil, users.ErrFavoriteNotFound)
+
+	err := uc.UpdateFinderStatus(context.Background(), &models.FinderRequest{
+		MSISDN:           user.MSISDN,
+		IsActive:         true,
+		Location:         models.Location{Latitude: -6.2088, Longitude: 106.8456},
+		TargetFavoriteID: favoriteID,
+	})
+
+	assert.ErrorIs(t, err, users.ErrFavoriteNotFound)
+}
@@ -15,6 +15,24 @@ func (uc *UserUC) UpdateFinderStatus(ctx context.Context, finderReq *models.Find
 		return err
 	}
 
+	if finderReq.IsActive {
+		if err := uc.resolveTargetFavorite(ctx, user.ID.String(), finderReq); err != nil {
+			return err
+		}
+	}
+
+	// A preference given with the search applies to it alone; otherwise the profile's applies
+	driverGender := finderReq.DriverGender
+	if driverGender == "" {
+		driverGender = user.DriverGenderPreference
+	}
+	if err := models.ValidateGender("driver_gender", driverGender); err != nil {
+		return err
+	}
+	if err := models.ValidatePaymentMethod("payment_method", finderReq.PaymentMethod); err != nil {
+		return err
+	}
+
 	// Create and publish finder event
 	finderEvent := &models.FinderEvent{
 		UserID:         user.ID.String(),
@@ -22,6 +40,9 @@ func (uc *UserUC) UpdateFinderStatus(ctx context.Context, finderReq *models.Find
 		Location:       finderReq.Location,
 		TargetLocation: finderReq.TargetLocation,
 		Timestamp:      time.Now(),
+		ScheduledAt:    finderReq.ScheduledAt,
+		DriverGender:   driverGender,
+		PaymentMethod:  finderReq.PaymentMethod,
 	}
 
 	return uc.UserGW.PublishFinderEvent(ctx, finderEvent)
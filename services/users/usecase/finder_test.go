@@ -178,3 +178,107 @@ func TestUpdateFinderStatus_DeactivateFinder(t *testing.T) {
 	// Assert
 	assert.NoError(t, err)
 }
+
+func TestUpdateFinderStatus_DriverGenderPreference(t *testing.T) {
+	tests := []struct {
+		name       string
+		profile    string
+		requested  string
+		wantGender string
+	}{
+		{name: "No preference", wantGender: ""},
+		{name: "Profile preference", profile: models.GenderFemale, wantGender: models.GenderFemale},
+		{name: "Search overrides profile", profile: models.GenderFemale, requested: models.GenderMale, wantGender: models.GenderMale},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockUserRepo(ctrl)
+			mockGW := mocks.NewMockUserGW(ctrl)
+			uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+			mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), "+628123456789").Return(&models.User{
+				ID:                     uuid.New(),
+				MSISDN:                 "+628123456789",
+				Role:                   "passenger",
+				DriverGenderPreference: tt.profile,
+			}, nil)
+
+			var published *models.FinderEvent
+			mockGW.EXPECT().PublishFinderEvent(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, event *models.FinderEvent) error {
+					published = event
+					return nil
+				})
+
+			err := uc.UpdateFinderStatus(context.Background(), &models.FinderRequest{
+				MSISDN:         "+628123456789",
+				IsActive:       true,
+				Location:       models.Location{Latitude: -6.2088, Longitude: 106.8456},
+				TargetLocation: models.Location{Latitude: -6.1751, Longitude: 106.8650},
+				DriverGender:   tt.requested,
+			})
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantGender, published.DriverGender)
+		})
+	}
+}
+
+func TestUpdateFinderStatus_PaymentMethod(t *testing.T) {
+	tests := []struct {
+		name    string
+		method  models.PaymentMethod
+		wantErr bool
+	}{
+		{name: "Default", method: ""},
+		{name: "Cash", method: models.PaymentMethodCash},
+		{name: "QRIS", method: models.PaymentMethodQRIS},
+		{name: "Unknown method", method: "CHEQUE", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockUserRepo(ctrl)
+			mockGW := mocks.NewMockUserGW(ctrl)
+			uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+			mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), "+628123456789").Return(&models.User{
+				ID:     uuid.New(),
+				MSISDN: "+628123456789",
+				Role:   "passenger",
+			}, nil)
+
+			var published *models.FinderEvent
+			if !tt.wantErr {
+				mockGW.EXPECT().PublishFinderEvent(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, event *models.FinderEvent) error {
+						published = event
+						return nil
+					})
+			}
+
+			err := uc.UpdateFinderStatus(context.Background(), &models.FinderRequest{
+				MSISDN:         "+628123456789",
+				IsActive:       true,
+				Location:       models.Location{Latitude: -6.2088, Longitude: 106.8456},
+				TargetLocation: models.Location{Latitude: -6.1751, Longitude: 106.8650},
+				PaymentMethod:  tt.method,
+			})
+
+			if tt.wantErr {
+				var fieldErr *models.FieldError
+				assert.ErrorAs(t, err, &fieldErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.method, published.PaymentMethod)
+		})
+	}
+}
@@ -25,3 +25,17 @@ func (uc *UserUC) ConfirmMatch(ctx context.Context, mp *models.MatchConfirmReque
 	// Call the gateway to confirm the match
 	return uc.UserGW.MatchConfirm(ctx, mp)
 }
+
+// GetDriverMatches returns a page of the driver's match history
+func (uc *UserUC) GetDriverMatches(ctx context.Context, driverID string, limit, offset int) (*models.MatchHistory, error) {
+	user, err := uc.userRepo.GetUserByID(ctx, driverID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	if user.Role != "driver" {
+		return nil, fmt.Errorf("user is not registered as a driver")
+	}
+
+	return uc.UserGW.GetDriverMatches(ctx, driverID, limit, offset)
+}
@@ -0,0 +1,99 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/piresc/nebengjek/internal/pkg/logger"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// recordOnlineTransition opens or closes the driver's online session for a beacon toggle
+func (uc *UserUC) recordOnlineTransition(ctx context.Context, event *models.BeaconEvent) error {
+	if event.IsActive {
+		return uc.userRepo.StartOnlineSession(ctx, event.UserID, event.Timestamp)
+	}
+
+	ended, err := uc.userRepo.EndOnlineSession(ctx, event.UserID, event.Timestamp)
+	if err != nil {
+		return err
+	}
+	if !ended {
+		// Drivers who were never online, or whose online beacon was lost, have no time to close
+		logger.Info("Driver went offline without an open online session",
+			logger.String("driver_id", event.UserID))
+	}
+	return nil
+}
+
+// EndEvictedDriverSession closes the online session of a driver the location service evicted from
+// the pool after their beacons stopped. The session ends when the driver was last seen, not when
+// the sweep noticed.
+func (uc *UserUC) EndEvictedDriverSession(ctx context.Context, eviction *models.DriverEviction) error {
+	ended, err := uc.userRepo.EndOnlineSession(ctx, eviction.DriverID, eviction.LastSeenAt)
+	if err != nil {
+		return fmt.Errorf("failed to end online session: %w", err)
+	}
+	if ended {
+		logger.Info("Ended online session of evicted driver",
+			logger.String("driver_id", eviction.DriverID),
+			logger.Any("last_seen_at", eviction.LastSeenAt))
+	}
+	return nil
+}
+
+// GetDriverOnlineTime sums how long the driver was online on the given day. Sessions crossing
+// midnight only count their part within the day, and a session still open counts until now.
+func (uc *UserUC) GetDriverOnlineTime(ctx context.Context, driverID string, day time.Time) (*models.DriverOnlineSummary, error) {
+	from := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	to := from.AddDate(0, 0, 1)
+
+	sessions, err := uc.userRepo.ListOnlineSessions(ctx, driverID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list online sessions: %w", err)
+	}
+
+	summary := &models.DriverOnlineSummary{
+		DriverID:      driverID,
+		Date:          from.Format("2006-01-02"),
+		OnlineSeconds: int64(onlineDuration(sessions, from, to, time.Now()).Seconds()),
+		Sessions:      len(sessions),
+	}
+
+	// The cancellation rate is extra context, so the summary is still returned without it
+	stats, err := uc.UserGW.GetDriverCancellationStats(ctx, driverID)
+	if err != nil {
+		logger.Warn("Failed to get driver cancellation stats",
+			logger.String("driver_id", driverID),
+			logger.ErrorField(err))
+	} else {
+		summary.Cancellation = stats
+	}
+
+	return summary, nil
+}
+
+// onlineDuration returns the time covered by sessions within [from, to), counting open sessions until now
+func onlineDuration(sessions []*models.DriverOnlineSession, from, to, now time.Time) time.Duration {
+	var total time.Duration
+	for _, session := range sessions {
+		start := session.StartedAt
+		if start.Before(from) {
+			start = from
+		}
+
+		end := now
+		if session.EndedAt != nil {
+			end = *session.EndedAt
+		}
+		if end.After(to) {
+			end = to
+		}
+
+		if end.After(start) {
+			total += end.Sub(start)
+		}
+	}
+	return total
+}
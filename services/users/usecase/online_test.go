@@ -0,0 +1,219 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onlineSession returns a session between two times; a zero end leaves it open
+func onlineSession(startedAt, endedAt time.Time) *models.DriverOnlineSession {
+	session := &models.DriverOnlineSession{SessionID: uuid.New(), StartedAt: startedAt}
+	if !endedAt.IsZero() {
+		session.EndedAt = &endedAt
+	}
+	return session
+}
+
+func TestOnlineDuration(t *testing.T) {
+	from := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 1)
+	at := func(hour, minute int) time.Time {
+		return from.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)
+	}
+
+	tests := []struct {
+		name     string
+		sessions []*models.DriverOnlineSession
+		now      time.Time
+		expected time.Duration
+	}{
+		{
+			name:     "No sessions",
+			now:      at(12, 0),
+			expected: 0,
+		},
+		{
+			name: "Several on and off transitions",
+			sessions: []*models.DriverOnlineSession{
+				onlineSession(at(8, 0), at(10, 0)),
+				onlineSession(at(12, 30), at(13, 0)),
+				onlineSession(at(17, 15), at(19, 45)),
+			},
+			now:      at(23, 0),
+			expected: 5 * time.Hour,
+		},
+		{
+			name: "Still online counts until now",
+			sessions: []*models.DriverOnlineSession{
+				onlineSession(at(8, 0), at(10, 0)),
+				onlineSession(at(20, 0), time.Time{}),
+			},
+			now:      at(21, 15),
+			expected: 3*time.Hour + 15*time.Minute,
+		},
+		{
+			name: "Sessions crossing midnight only count their part of the day",
+			sessions: []*models.DriverOnlineSession{
+				onlineSession(at(-1, 0), at(1, 0)),
+				onlineSession(at(23, 30), at(26, 0)),
+			},
+			now:      at(30, 0),
+			expected: 90 * time.Minute,
+		},
+		{
+			name: "Open session on a past day counts until midnight",
+			sessions: []*models.DriverOnlineSession{
+				onlineSession(at(22, 0), time.Time{}),
+			},
+			now:      at(50, 0),
+			expected: 2 * time.Hour,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, onlineDuration(tt.sessions, from, to, tt.now))
+		})
+	}
+}
+
+func TestGetDriverOnlineTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	driverID := uuid.New().String()
+	day := time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC)
+	from := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	mockRepo.EXPECT().
+		ListOnlineSessions(gomock.Any(), driverID, from, from.AddDate(0, 0, 1)).
+		Return([]*models.DriverOnlineSession{
+			onlineSession(from.Add(8*time.Hour), from.Add(10*time.Hour)),
+			onlineSession(from.Add(13*time.Hour), from.Add(13*time.Hour+30*time.Minute)),
+		}, nil)
+	stats := &models.DriverCancellationStats{DriverID: driverID, WindowHours: 24, Accepted: 4, Cancelled: 1, CancellationRate: 0.25}
+	mockGW.EXPECT().GetDriverCancellationStats(gomock.Any(), driverID).Return(stats, nil)
+
+	summary, err := uc.GetDriverOnlineTime(context.Background(), driverID, day)
+	require.NoError(t, err)
+	assert.Equal(t, driverID, summary.DriverID)
+	assert.Equal(t, "2026-01-02", summary.Date)
+	assert.Equal(t, int64(9000), summary.OnlineSeconds)
+	assert.Equal(t, 2, summary.Sessions)
+	assert.Equal(t, stats, summary.Cancellation)
+}
+
+func TestGetDriverOnlineTime_CancellationStatsUnavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	driverID := uuid.New().String()
+	mockRepo.EXPECT().
+		ListOnlineSessions(gomock.Any(), driverID, gomock.Any(), gomock.Any()).
+		Return(nil, nil)
+	mockGW.EXPECT().
+		GetDriverCancellationStats(gomock.Any(), driverID).
+		Return(nil, errors.New("match service down"))
+
+	summary, err := uc.GetDriverOnlineTime(context.Background(), driverID, time.Now())
+	require.NoError(t, err)
+	assert.Nil(t, summary.Cancellation)
+}
+
+func TestGetDriverOnlineTime_RepositoryError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	mockRepo.EXPECT().
+		ListOnlineSessions(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(nil, errors.New("db down"))
+
+	summary, err := uc.GetDriverOnlineTime(context.Background(), uuid.New().String(), time.Now())
+	assert.Error(t, err)
+	assert.Nil(t, summary)
+}
+
+func TestEndEvictedDriverSession(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	// The session ends at the driver's last beacon rather than when the sweep evicted them
+	eviction := &models.DriverEviction{DriverID: uuid.New().String(), LastSeenAt: time.Now().Add(-3 * time.Minute)}
+	mockRepo.EXPECT().EndOnlineSession(gomock.Any(), eviction.DriverID, eviction.LastSeenAt).Return(true, nil)
+	require.NoError(t, uc.EndEvictedDriverSession(context.Background(), eviction))
+
+	// Failures are returned so the event is redelivered
+	mockRepo.EXPECT().EndOnlineSession(gomock.Any(), eviction.DriverID, eviction.LastSeenAt).Return(false, errors.New("db down"))
+	assert.Error(t, uc.EndEvictedDriverSession(context.Background(), eviction))
+}
+
+func TestUpdateBeaconStatus_OnlineTimeTracking(t *testing.T) {
+	driver := &models.User{
+		ID:         uuid.New(),
+		MSISDN:     "+628123456789",
+		Role:       "driver",
+		DriverInfo: &models.Driver{Verified: true},
+	}
+
+	t.Run("Offline without a prior online session", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepo(ctrl)
+		mockGW := mocks.NewMockUserGW(ctrl)
+		uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+		mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), driver.MSISDN).Return(driver, nil)
+		mockGW.EXPECT().PublishBeaconEvent(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().EndOnlineSession(gomock.Any(), driver.ID.String(), gomock.Any()).Return(false, nil)
+
+		err := uc.UpdateBeaconStatus(context.Background(), &models.BeaconRequest{MSISDN: driver.MSISDN, IsActive: false})
+		assert.NoError(t, err)
+	})
+
+	t.Run("Recording failure does not fail the beacon", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepo(ctrl)
+		mockGW := mocks.NewMockUserGW(ctrl)
+		uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+		mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), driver.MSISDN).Return(driver, nil)
+		mockGW.EXPECT().PublishBeaconEvent(gomock.Any(), gomock.Any()).Return(nil)
+		mockRepo.EXPECT().StartOnlineSession(gomock.Any(), driver.ID.String(), gomock.Any()).Return(errors.New("db down"))
+
+		err := uc.UpdateBeaconStatus(context.Background(), &models.BeaconRequest{
+			MSISDN:    driver.MSISDN,
+			IsActive:  true,
+			Latitude:  -6.2088,
+			Longitude: 106.8456,
+		})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,35 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/piresc/nebengjek/internal/pkg/models"
+)
+
+// GetUserPresence reports whether the user is in a matching pool and which ride they are on, if
+// any. WebSocket connections are tracked by the handler, which fills in Connected.
+func (uc *UserUC) GetUserPresence(ctx context.Context, userID string) (*models.UserPresence, error) {
+	presence := &models.UserPresence{UserID: userID}
+
+	var err error
+	if presence.OnlineAsDriver, err = uc.userRepo.IsInMatchingPool(ctx, userID, "driver"); err != nil {
+		return nil, err
+	}
+	if presence.OnlineAsPassenger, err = uc.userRepo.IsInMatchingPool(ctx, userID, "passenger"); err != nil {
+		return nil, err
+	}
+
+	for _, role := range []string{"driver", "passenger"} {
+		rideID, err := uc.UserGW.GetActiveRideID(ctx, userID, role)
+		if err != nil {
+			return nil, err
+		}
+		if rideID != "" {
+			presence.ActiveRideID = rideID
+			presence.ActiveRideRole = role
+			break
+		}
+	}
+
+	return presence, nil
+}
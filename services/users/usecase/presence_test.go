@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserPresence(t *testing.T) {
+	t.Run("Driver online and on a ride", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepo(ctrl)
+		mockGW := mocks.NewMockUserGW(ctrl)
+		uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+		mockRepo.EXPECT().IsInMatchingPool(gomock.Any(), "user-1", "driver").Return(true, nil)
+		mockRepo.EXPECT().IsInMatchingPool(gomock.Any(), "user-1", "passenger").Return(false, nil)
+		mockGW.EXPECT().GetActiveRideID(gomock.Any(), "user-1", "driver").Return("ride-1", nil)
+
+		presence, err := uc.GetUserPresence(context.Background(), "user-1")
+
+		require.NoError(t, err)
+		assert.Equal(t, &models.UserPresence{
+			UserID:         "user-1",
+			OnlineAsDriver: true,
+			ActiveRideID:   "ride-1",
+			ActiveRideRole: "driver",
+		}, presence)
+	})
+
+	t.Run("Passenger on a ride", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepo(ctrl)
+		mockGW := mocks.NewMockUserGW(ctrl)
+		uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+		mockRepo.EXPECT().IsInMatchingPool(gomock.Any(), "user-1", gomock.Any()).Return(false, nil).Times(2)
+		mockGW.EXPECT().GetActiveRideID(gomock.Any(), "user-1", "driver").Return("", nil)
+		mockGW.EXPECT().GetActiveRideID(gomock.Any(), "user-1", "passenger").Return("ride-2", nil)
+
+		presence, err := uc.GetUserPresence(context.Background(), "user-1")
+
+		require.NoError(t, err)
+		assert.False(t, presence.OnlineAsDriver)
+		assert.False(t, presence.OnlineAsPassenger)
+		assert.Equal(t, "ride-2", presence.ActiveRideID)
+		assert.Equal(t, "passenger", presence.ActiveRideRole)
+	})
+
+	t.Run("Pool lookup failure", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+
+		mockRepo := mocks.NewMockUserRepo(ctrl)
+		uc := NewUserUC(mockRepo, mocks.NewMockUserGW(ctrl), &models.Config{})
+
+		mockRepo.EXPECT().IsInMatchingPool(gomock.Any(), "user-1", "driver").Return(false, errors.New("redis down"))
+
+		_, err := uc.GetUserPresence(context.Background(), "user-1")
+
+		assert.Error(t, err)
+	})
+}
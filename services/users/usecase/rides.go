@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 )
 
 // RideArrived publishes a ride arrival event to NATS
@@ -36,6 +37,7 @@ func (u *UserUC) RideStart(ctx context.Context, event *models.RideStartRequest)
 		RideID:            event.RideID,
 		DriverLocation:    event.DriverLocation,
 		PassengerLocation: event.PassengerLocation,
+		PickupCode:        event.PickupCode,
 	}
 
 	// Make HTTP call to rides service
@@ -46,3 +48,32 @@ func (u *UserUC) RideStart(ctx context.Context, event *models.RideStartRequest)
 
 	return resp, nil
 }
+
+// GetPickupCode fetches the pickup code of the passenger's active ride, which the rides service
+// reissues when it expired. Drivers never get the code, since they have to be told it.
+func (u *UserUC) GetPickupCode(ctx context.Context, userID, role, rideID string) (*models.PickupCodeResponse, error) {
+	if role != "passenger" {
+		return nil, users.ErrNotRideParticipant
+	}
+	if err := u.checkRideParticipant(ctx, userID, role, rideID); err != nil {
+		return nil, err
+	}
+
+	return u.GetRidePickupCode(ctx, userID, rideID)
+}
+
+// GetRidePickupCode fetches a ride's pickup code for its passenger without checking the match
+// service's active ride, which the ride pickup event can arrive ahead of. The rides service still
+// refuses the code to anyone but the ride's passenger.
+func (u *UserUC) GetRidePickupCode(ctx context.Context, passengerID, rideID string) (*models.PickupCodeResponse, error) {
+	resp, err := u.UserGW.GetPickupCode(ctx, &models.PickupCodeRequest{RideID: rideID, PassengerID: passengerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pickup code via HTTP: %w", err)
+	}
+	return resp, nil
+}
+
+// CheckRideParticipant returns users.ErrNotRideParticipant unless the user is on the given active ride
+func (u *UserUC) CheckRideParticipant(ctx context.Context, userID, role, rideID string) error {
+	return u.checkRideParticipant(ctx, userID, role, rideID)
+}
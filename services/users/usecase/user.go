@@ -7,6 +7,7 @@ import (
 
 	"github.com/piresc/nebengjek/internal/pkg/models"
 	"github.com/piresc/nebengjek/internal/utils"
+	"github.com/piresc/nebengjek/services/users"
 )
 
 // RegisterUser registers a new user
@@ -92,6 +93,47 @@ func (u *UserUC) RegisterDriver(ctx context.Context, userDriver *models.User) er
 	return nil
 }
 
+// ListUsers returns a filtered page of users for admin review
+func (u *UserUC) ListUsers(ctx context.Context, filter models.UserFilter) (*models.UserList, error) {
+	if filter.Role != "" && filter.Role != "driver" && filter.Role != "passenger" {
+		return nil, fmt.Errorf("invalid role filter: %s", filter.Role)
+	}
+
+	users, total, err := u.userRepo.ListUsers(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserList{
+		Users:  users,
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}, nil
+}
+
+// VerifyDriver records the outcome of an admin review of a driver's submitted documents
+func (u *UserUC) VerifyDriver(ctx context.Context, driverID string, verified bool) (*models.User, error) {
+	user, err := u.userRepo.GetUserByID(ctx, driverID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role != "driver" || user.DriverInfo == nil {
+		return nil, fmt.Errorf("%w: user is not registered as a driver", users.ErrDriverNotFound)
+	}
+
+	if verified && len(user.DriverInfo.Documents) == 0 {
+		return nil, users.ErrDriverDocumentsMissing
+	}
+
+	if err := u.userRepo.UpdateDriverVerification(ctx, driverID, verified); err != nil {
+		return nil, err
+	}
+
+	return u.userRepo.GetUserByID(ctx, driverID)
+}
+
 func validateUserData(user *models.User) error {
 	if user == nil {
 		return errors.New("user cannot be nil")
@@ -100,7 +142,13 @@ func validateUserData(user *models.User) error {
 	if user.MSISDN == "" {
 		return errors.New("MSISDN is required")
 	}
-	return nil
+	if err := models.ValidateGender("gender", user.Gender); err != nil {
+		return err
+	}
+	if err := models.ValidateGender("driver_gender_preference", user.DriverGenderPreference); err != nil {
+		return err
+	}
+	return user.SanitizeFullName()
 }
 
 func validateDriverData(driver *models.Driver) error {
@@ -115,5 +163,12 @@ func validateDriverData(driver *models.Driver) error {
 	if driver.VehiclePlate == "" {
 		return errors.New("vehicle plate is required")
 	}
+
+	// Store plates in one canonical form so the same vehicle cannot register under variants
+	plate, err := utils.NormalizeVehiclePlate(driver.VehiclePlate)
+	if err != nil {
+		return err
+	}
+	driver.VehiclePlate = plate
 	return nil
 }
@@ -3,13 +3,16 @@ package usecase
 import (
 	"context"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
 	"github.com/piresc/nebengjek/internal/pkg/models"
+	"github.com/piresc/nebengjek/services/users"
 	"github.com/piresc/nebengjek/services/users/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRegisterUser_Success(t *testing.T) {
@@ -180,6 +183,107 @@ func TestRegisterUser_RepositoryError(t *testing.T) {
 	assert.Equal(t, expectedError, err)
 }
 
+func TestRegisterUser_AlreadyExists(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	user := &models.User{
+		MSISDN:   "+628123456789",
+		FullName: "Test User",
+		Role:     "passenger",
+	}
+
+	mockRepo.EXPECT().CreateUser(gomock.Any(), user).Return(users.ErrAlreadyExists)
+
+	// Act
+	err := uc.RegisterUser(context.Background(), user)
+
+	// Assert
+	assert.ErrorIs(t, err, users.ErrAlreadyExists)
+}
+
+func TestRegisterUser_SanitizesFullName(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	user := &models.User{
+		MSISDN:   "+628123456789",
+		FullName: " Test\x1b[2J\nUser\u200b ",
+	}
+
+	mockRepo.EXPECT().
+		CreateUser(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, stored *models.User) error {
+			assert.Equal(t, "Test[2J User", stored.FullName)
+			return nil
+		})
+
+	// Act
+	err := uc.RegisterUser(context.Background(), user)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestRegisterUser_FullNameTooLong(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	user := &models.User{
+		MSISDN:   "+628123456789",
+		FullName: strings.Repeat("a", 101),
+	}
+
+	// Act
+	err := uc.RegisterUser(context.Background(), user)
+
+	// Assert
+	var fieldErr *models.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "fullname", fieldErr.Field)
+}
+
+func TestRegisterUser_InvalidGender(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	uc := NewUserUC(mocks.NewMockUserRepo(ctrl), mocks.NewMockUserGW(ctrl), &models.Config{})
+
+	user := &models.User{
+		MSISDN:                 "+628123456789",
+		FullName:               "Test User",
+		Gender:                 models.GenderFemale,
+		DriverGenderPreference: "any",
+	}
+
+	// Act
+	err := uc.RegisterUser(context.Background(), user)
+
+	// Assert
+	var fieldErr *models.FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "driver_gender_preference", fieldErr.Field)
+}
+
 func TestGetUserByID_Success(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -306,6 +410,54 @@ func TestRegisterDriver_Success(t *testing.T) {
 	assert.Equal(t, userId, driverUser.ID)
 }
 
+func TestRegisterDriver_VehiclePlate(t *testing.T) {
+	tests := []struct {
+		name          string
+		plate         string
+		expectedPlate string
+		expectedError string
+	}{
+		{name: "Lowercase plate is normalized", plate: "b 1234 abc", expectedPlate: "B 1234 ABC"},
+		{name: "Unspaced plate is normalized", plate: " B1234ABC ", expectedPlate: "B 1234 ABC"},
+		{name: "Malformed plate is rejected", plate: "12345", expectedError: "invalid vehicle plate format"},
+		{name: "Missing plate is rejected", plate: "", expectedError: "vehicle plate is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockUserRepo(ctrl)
+			mockGW := mocks.NewMockUserGW(ctrl)
+			uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+			existingUser := &models.User{ID: uuid.New(), MSISDN: "628123456789", Role: "passenger", IsActive: true}
+			mockRepo.EXPECT().GetUserByMSISDN(gomock.Any(), "628123456789").Return(existingUser, nil)
+
+			if tt.expectedError == "" {
+				mockRepo.EXPECT().UpdateToDriver(gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, u *models.User) error {
+						assert.Equal(t, tt.expectedPlate, u.DriverInfo.VehiclePlate)
+						return nil
+					})
+			}
+
+			err := uc.RegisterDriver(context.Background(), &models.User{
+				MSISDN:     "+628123456789",
+				DriverInfo: &models.Driver{VehicleType: "car", VehiclePlate: tt.plate},
+			})
+
+			if tt.expectedError != "" {
+				assert.Error(t, err)
+				assert.Contains(t, err.Error(), tt.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
 func TestRegisterDriver_UserNotFound(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -696,6 +848,7 @@ func TestRideStart_Success(t *testing.T) {
 		RideID:            uuid.New().String(),
 		DriverLocation:    &models.Location{Latitude: -6.2088, Longitude: 106.8456},
 		PassengerLocation: &models.Location{Latitude: -6.2000, Longitude: 106.8500},
+		PickupCode:        "4821",
 	}
 
 	expectedRide := &models.Ride{
@@ -717,6 +870,49 @@ func TestRideStart_Success(t *testing.T) {
 	assert.Equal(t, models.RideStatusOngoing, ride.Status)
 }
 
+func TestGetPickupCode_Passenger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	passengerID := uuid.New().String()
+	rideID := uuid.New().String()
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), passengerID, "passenger").Return(rideID, nil)
+	mockGW.EXPECT().
+		GetPickupCode(gomock.Any(), &models.PickupCodeRequest{RideID: rideID, PassengerID: passengerID}).
+		Return(&models.PickupCodeResponse{RideID: rideID, PickupCode: "0427"}, nil)
+
+	resp, err := uc.GetPickupCode(context.Background(), passengerID, "passenger", rideID)
+
+	require.NoError(t, err)
+	assert.Equal(t, "0427", resp.PickupCode)
+}
+
+func TestGetPickupCode_Refused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	rideID := uuid.New().String()
+
+	// The driver has to be told the code, so never gets it
+	driverID := uuid.New().String()
+	_, err := uc.GetPickupCode(context.Background(), driverID, "driver", rideID)
+	assert.ErrorIs(t, err, users.ErrNotRideParticipant)
+
+	// Nor does a passenger of another ride
+	passengerID := uuid.New().String()
+	mockGW.EXPECT().GetActiveRideID(gomock.Any(), passengerID, "passenger").Return(uuid.New().String(), nil)
+	_, err = uc.GetPickupCode(context.Background(), passengerID, "passenger", rideID)
+	assert.ErrorIs(t, err, users.ErrNotRideParticipant)
+}
+
 func TestRideStart_GatewayError(t *testing.T) {
 	// Arrange
 	ctrl := gomock.NewController(t)
@@ -833,3 +1029,142 @@ func TestProcessPayment_GatewayError(t *testing.T) {
 	assert.Nil(t, payment)
 	assert.Contains(t, err.Error(), "failed to process payment")
 }
+
+func TestVerifyDriver_Success(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	driverID := uuid.New().String()
+	pending := &models.User{
+		ID:   uuid.MustParse(driverID),
+		Role: "driver",
+		DriverInfo: &models.Driver{
+			VehicleType:  "motorcycle",
+			VehiclePlate: "B 1234 ABC",
+			Documents:    []string{"https://docs.nebengjek.com/sim.jpg"},
+		},
+	}
+	verified := &models.User{
+		ID:   uuid.MustParse(driverID),
+		Role: "driver",
+		DriverInfo: &models.Driver{
+			VehicleType:  "motorcycle",
+			VehiclePlate: "B 1234 ABC",
+			Documents:    []string{"https://docs.nebengjek.com/sim.jpg"},
+			Verified:     true,
+		},
+	}
+
+	gomock.InOrder(
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), driverID).Return(pending, nil),
+		mockRepo.EXPECT().UpdateDriverVerification(gomock.Any(), driverID, true).Return(nil),
+		mockRepo.EXPECT().GetUserByID(gomock.Any(), driverID).Return(verified, nil),
+	)
+
+	// Act
+	result, err := uc.VerifyDriver(context.Background(), driverID, true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.True(t, result.DriverInfo.Verified)
+}
+
+func TestVerifyDriver_NoDocuments(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	driverID := uuid.New().String()
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), driverID).Return(&models.User{
+		ID:         uuid.MustParse(driverID),
+		Role:       "driver",
+		DriverInfo: &models.Driver{VehicleType: "car", VehiclePlate: "B 1234 ABC"},
+	}, nil)
+
+	// Act
+	result, err := uc.VerifyDriver(context.Background(), driverID, true)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "has not submitted any documents")
+}
+
+func TestVerifyDriver_NotADriver(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	userID := uuid.New().String()
+	mockRepo.EXPECT().GetUserByID(gomock.Any(), userID).Return(&models.User{
+		ID:   uuid.MustParse(userID),
+		Role: "passenger",
+	}, nil)
+
+	// Act
+	_, err := uc.VerifyDriver(context.Background(), userID, true)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "not registered as a driver")
+}
+
+func TestListUsers_Success(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	filter := models.UserFilter{Role: "driver", Limit: 20, Offset: 20}
+	users := []*models.User{{ID: uuid.New(), Role: "driver"}}
+	mockRepo.EXPECT().ListUsers(gomock.Any(), filter).Return(users, 21, nil)
+
+	// Act
+	result, err := uc.ListUsers(context.Background(), filter)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, users, result.Users)
+	assert.Equal(t, 21, result.Total)
+	assert.Equal(t, 20, result.Limit)
+	assert.Equal(t, 20, result.Offset)
+}
+
+func TestListUsers_InvalidRole(t *testing.T) {
+	// Arrange
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockUserRepo(ctrl)
+	mockGW := mocks.NewMockUserGW(ctrl)
+
+	uc := NewUserUC(mockRepo, mockGW, &models.Config{})
+
+	// Act
+	result, err := uc.ListUsers(context.Background(), models.UserFilter{Role: "admin"})
+
+	// Assert
+	assert.Error(t, err)
+	assert.Nil(t, result)
+	assert.Contains(t, err.Error(), "invalid role filter")
+}